@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"unwise-backend/config"
+	"unwise-backend/database"
+	"unwise-backend/services"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/e2e")
+
+// TestAPIFlows boots the real router against a fresh schema in the database at
+// TEST_DATABASE_URL and walks the main flows: sign up, create a group, add
+// expenses, check balances, settle up and export. Each response is compared
+// with its golden file in testdata/e2e once IDs, tokens and timestamps have
+// been replaced with stable placeholders. Run with -update to record them
+// after an intended change; without it a missing golden file fails the test.
+func TestAPIFlows(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db := newTestDatabase(t, databaseURL)
+	cfg := &config.Config{
+		Env:                       "test",
+		AuthProvider:              "local",
+		JWTSecret:                 "e2e-jwt-secret",
+		GeminiAPIKey:              "e2e-gemini-key",
+		SupabaseStorageBucket:     "receipts",
+		SupabaseGroupPhotosBucket: "group-photos",
+		SupabaseUserAvatarsBucket: "user-avatars",
+		AllowedOrigins:            []string{"*"},
+		PlaceholderClaimPolicy:    services.PlaceholderClaimPolicyOpen,
+		MaxBodySize:               1 << 20,
+		ExportSigningKey:          "e2e-export-key",
+	}
+	app, err := newServer(cfg, db, zap.NewNop())
+	if err != nil {
+		t.Fatalf("building server: %v", err)
+	}
+	ts := httptest.NewServer(app.router)
+	defer ts.Close()
+
+	c := &apiClient{t: t, baseURL: ts.URL, scrub: newScrubber()}
+
+	alice := c.register("01_register", "alice@example.com", "Alice")
+	bob := c.register("", "bob@example.com", "Bob")
+	carol := c.register("", "carol@example.com", "Carol")
+
+	var group struct {
+		ID string `json:"id"`
+	}
+	c.call("02_create_group", alice.token, http.MethodPost, "/api/groups", map[string]interface{}{
+		"name":          "Goa Trip",
+		"type":          "TRIP",
+		"member_emails": []string{"bob@example.com", "carol@example.com"},
+	}, http.StatusCreated, &group)
+	c.scrub.name(group.ID, "group")
+
+	c.call("03_expense_dinner", alice.token, http.MethodPost, "/api/expenses", map[string]interface{}{
+		"group_id":        group.ID,
+		"description":     "Beach dinner",
+		"total_amount":    90,
+		"split_method":    "EQUAL",
+		"type":            "EXPENSE",
+		"paid_by_user_id": alice.id,
+		"participant_ids": []string{alice.id, bob.id, carol.id},
+		"date":            "2024-05-01T00:00:00Z",
+	}, http.StatusCreated, nil)
+
+	c.call("04_expense_taxi", bob.token, http.MethodPost, "/api/expenses", map[string]interface{}{
+		"group_id":        group.ID,
+		"description":     "Airport taxi",
+		"total_amount":    60,
+		"split_method":    "EQUAL",
+		"type":            "EXPENSE",
+		"paid_by_user_id": bob.id,
+		"participant_ids": []string{bob.id, carol.id},
+		"date":            "2024-05-02T00:00:00Z",
+	}, http.StatusCreated, nil)
+
+	groupPath := "/api/groups/" + group.ID
+	c.call("05_balances", alice.token, http.MethodGet, groupPath+"/balances", nil, http.StatusOK, nil)
+	c.call("06_settlements", carol.token, http.MethodGet, groupPath+"/settlements", nil, http.StatusOK, nil)
+
+	c.call("07_settle", carol.token, http.MethodPost, groupPath+"/settle", map[string]interface{}{
+		"payer_id":    carol.id,
+		"receiver_id": alice.id,
+		"amount":      60,
+		"method":      "UPI",
+	}, http.StatusCreated, nil)
+
+	c.call("08_balances_settled", alice.token, http.MethodGet, groupPath+"/balances", nil, http.StatusOK, nil)
+
+	status, body := c.do(alice.token, http.MethodGet, groupPath+"/export", nil)
+	if status != http.StatusOK {
+		t.Fatalf("export: expected status %d, got %d: %s", http.StatusOK, status, body)
+	}
+	c.compare("09_export.csv", []byte(c.scrub.text(string(body))))
+}
+
+// newTestDatabase creates a schema of its own in the test database, applies
+// every migration to it and drops it again when the test ends, so runs never
+// see each other's data.
+func newTestDatabase(t *testing.T, databaseURL string) *database.DB {
+	t.Helper()
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("connecting to test database: %v", err)
+	}
+	t.Cleanup(admin.Close)
+
+	schema := fmt.Sprintf("e2e_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Logf("dropping schema %s: %v", schema, err)
+		}
+	})
+
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("parsing TEST_DATABASE_URL: %v", err)
+	}
+	poolConfig.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+	db, err := database.NewWithConfig(poolConfig)
+	if err != nil {
+		t.Fatalf("connecting to test schema: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	migrations, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.up.sql"))
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("finding migrations: %v", err)
+	}
+	sort.Strings(migrations)
+	for _, path := range migrations {
+		sql, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading %s: %v", path, err)
+		}
+		if _, err := db.Pool.Exec(ctx, string(sql)); err != nil {
+			t.Fatalf("applying %s: %v", filepath.Base(path), err)
+		}
+	}
+	return db
+}
+
+type testUser struct {
+	id    string
+	token string
+}
+
+type apiClient struct {
+	t       *testing.T
+	baseURL string
+	scrub   *scrubber
+}
+
+// register signs a user up and names their ID after them in golden files. The
+// response is only compared when golden is set.
+func (c *apiClient) register(golden, email, name string) testUser {
+	c.t.Helper()
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		User        struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	}
+	body := map[string]string{"email": email, "password": "correct-horse-battery", "name": name}
+	status, raw := c.do("", http.MethodPost, "/auth/register", body)
+	if status != http.StatusCreated {
+		c.t.Fatalf("registering %s: expected status %d, got %d: %s", email, http.StatusCreated, status, raw)
+	}
+	if err := json.Unmarshal(raw, &tokens); err != nil {
+		c.t.Fatalf("decoding registration of %s: %v", email, err)
+	}
+	c.scrub.name(tokens.User.ID, strings.ToLower(name))
+	if golden != "" {
+		c.compareJSON(golden, status, raw)
+	}
+	return testUser{id: tokens.User.ID, token: tokens.AccessToken}
+}
+
+// call makes a request, fails unless it returns wantStatus, compares the
+// response with its golden file and decodes it into out if out is not nil.
+func (c *apiClient) call(golden, token, method, path string, body interface{}, wantStatus int, out interface{}) {
+	c.t.Helper()
+	status, raw := c.do(token, method, path, body)
+	if status != wantStatus {
+		c.t.Fatalf("%s %s: expected status %d, got %d: %s", method, path, wantStatus, status, raw)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			c.t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+	}
+	c.compareJSON(golden, status, raw)
+}
+
+func (c *apiClient) do(token, method, path string, body interface{}) (int, []byte) {
+	c.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("encoding request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		c.t.Fatalf("building request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatalf("%s %s: reading response: %v", method, path, err)
+	}
+	return resp.StatusCode, raw
+}
+
+func (c *apiClient) compareJSON(golden string, status int, raw []byte) {
+	c.t.Helper()
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		c.t.Fatalf("%s: response is not JSON: %v: %s", golden, err, raw)
+	}
+	snapshot := map[string]interface{}{
+		"status": status,
+		"body":   c.scrub.value("", decoded),
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snapshot); err != nil {
+		c.t.Fatalf("%s: encoding snapshot: %v", golden, err)
+	}
+	c.compare(golden+".json", buf.Bytes())
+}
+
+func (c *apiClient) compare(name string, got []byte) {
+	c.t.Helper()
+	path := filepath.Join("testdata", "e2e", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			c.t.Fatalf("creating %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			c.t.Fatalf("writing %s: %v", path, err)
+		}
+		c.t.Logf("recorded %s", path)
+		return
+	}
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		c.t.Errorf("%s: missing golden; run with -update", path)
+		return
+	}
+	if err != nil {
+		c.t.Fatalf("reading %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		c.t.Errorf("%s does not match the response (run with -update if the change is intended)\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
+
+var (
+	uuidPattern      = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+)
+
+// unorderedLists are response lists that come back in database order, so
+// they are sorted by user before comparing.
+var unorderedLists = map[string]bool{"splits": true, "payers": true}
+
+// scrubber replaces values that change from run to run with placeholders:
+// known IDs with their name (<alice>, <group>), other IDs with <id-N> in
+// order of first appearance, and tokens and *_at timestamps with <token> and
+// <timestamp>.
+type scrubber struct {
+	ids  map[string]string
+	next int
+}
+
+func newScrubber() *scrubber {
+	return &scrubber{ids: make(map[string]string)}
+}
+
+func (s *scrubber) name(id, label string) {
+	s.ids[id] = "<" + label + ">"
+}
+
+func (s *scrubber) id(raw string) string {
+	if placeholder, ok := s.ids[raw]; ok {
+		return placeholder
+	}
+	s.next++
+	placeholder := fmt.Sprintf("<id-%d>", s.next)
+	s.ids[raw] = placeholder
+	return placeholder
+}
+
+func (s *scrubber) text(v string) string {
+	v = uuidPattern.ReplaceAllStringFunc(v, s.id)
+	return timestampPattern.ReplaceAllString(v, "<timestamp>")
+}
+
+func (s *scrubber) value(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v[k] = s.value(k, v[k])
+		}
+		return v
+	case []interface{}:
+		if unorderedLists[key] {
+			sort.SliceStable(v, func(i, j int) bool { return s.userKey(v[i]) < s.userKey(v[j]) })
+		}
+		for i := range v {
+			v[i] = s.value(key, v[i])
+		}
+		return v
+	case string:
+		switch {
+		case strings.HasSuffix(key, "token"):
+			return "<token>"
+		case strings.HasSuffix(key, "_at") && v != "":
+			return "<timestamp>"
+		}
+		return uuidPattern.ReplaceAllStringFunc(v, s.id)
+	}
+	return v
+}
+
+func (s *scrubber) userKey(item interface{}) string {
+	fields, _ := item.(map[string]interface{})
+	userID, _ := fields["user_id"].(string)
+	return s.ids[userID]
+}
@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // quiet hours resolve IANA zones; the alpine image ships none
 
 	"unwise-backend/config"
 	"unwise-backend/database"
@@ -15,6 +17,7 @@ import (
 	"unwise-backend/repository"
 	"unwise-backend/services"
 	"unwise-backend/storage"
+	"unwise-backend/supabase"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -43,34 +46,177 @@ func main() {
 	}
 	defer db.Close()
 
+	app, err := newServer(cfg, db, logger)
+	if err != nil {
+		logger.Fatal("Failed to build server", zap.Error(err))
+	}
+
+	srv := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: app.router,
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	for _, run := range app.workers {
+		go run(workerCtx)
+	}
+
+	go func() {
+		logger.Info("Server starting", zap.String("port", cfg.Port))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Server failed to start", zap.Error(err))
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("Shutting down server...")
+	stopWorkers()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Fatal("Server forced to shutdown", zap.Error(err))
+	}
+
+	logger.Info("Server exited")
+}
+
+// server is the wired-up API: its router and the background workers that run
+// alongside it. Tests build one against their own database.
+type server struct {
+	router  http.Handler
+	workers []func(context.Context)
+}
+
+func newServer(cfg *config.Config, db *database.DB, logger *zap.Logger) (*server, error) {
 	userRepo := repository.NewUserRepository(db)
 	groupRepo := repository.NewGroupRepository(db)
 	expenseRepo := repository.NewExpenseRepository(db)
 	friendRepo := repository.NewFriendRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
 	currencyRepo := repository.NewCurrencyRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	integrityRepo := repository.NewIntegrityRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	groupArchiveRepo := repository.NewGroupArchiveRepository(db)
+	reminderResponseRepo := repository.NewReminderResponseRepository(db)
+	readRepo := repository.NewReadRepository(db)
+	placeholderClaimRepo := repository.NewPlaceholderClaimRepository(db)
+	integrationRepo := repository.NewIntegrationRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+	splitPreferenceRepo := repository.NewSplitPreferenceRepository(db)
+	aiAuditRepo := repository.NewAIAuditRepository(db)
+	groupInviteRepo := repository.NewGroupInviteRepository(db)
+	balanceEventRepo := repository.NewBalanceEventRepository(db)
+	statsRepo := repository.NewStatsRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
+	balanceMetricsRepo := repository.NewBalanceMetricsRepository(db)
+	quotaRepo := repository.NewQuotaRepository(db)
+	expenseChangeRepo := repository.NewExpenseChangeRepository(db)
+	balanceAlertRepo := repository.NewBalanceAlertRepository(db)
+	standingRepaymentRepo := repository.NewStandingRepaymentRepository(db)
+	groupShareLinkRepo := repository.NewGroupShareLinkRepository(db)
+	announcementRepo := repository.NewAnnouncementRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	importRepo := repository.NewImportRepository(db)
 
+	jobService := services.NewJobService(jobRepo)
+	integrationService := services.NewIntegrationService(integrationRepo, groupRepo, expenseRepo, currencyRepo)
+	notificationService := services.NewNotificationService(notificationRepo, groupRepo, integrationService)
 	settlementService := services.NewSettlementService(expenseRepo, groupRepo)
-	groupService := services.NewGroupService(groupRepo, userRepo, expenseRepo, settlementService, db)
-	expenseService := services.NewExpenseService(expenseRepo, groupRepo, db)
-	userService := services.NewUserService(userRepo, expenseRepo, cfg.SupabaseURL, cfg.SupabaseServiceRoleKey)
-	dashboardService := services.NewDashboardService(userRepo, groupRepo, expenseRepo, userService)
+	balanceAlertService := services.NewBalanceAlertService(balanceAlertRepo, expenseRepo, notificationService)
+	quotaService := services.NewQuotaService(quotaRepo, services.QuotaLimits{
+		GroupsPerUser:    cfg.MaxGroupsPerUser,
+		MembersPerGroup:  cfg.MaxMembersPerGroup,
+		ExpensesPerGroup: cfg.MaxExpensesPerGroup,
+	})
+	switch cfg.PlaceholderClaimPolicy {
+	case services.PlaceholderClaimPolicyOpen, services.PlaceholderClaimPolicyMatch, services.PlaceholderClaimPolicyApproval:
+	default:
+		return nil, fmt.Errorf("unknown PLACEHOLDER_CLAIM_POLICY %q", cfg.PlaceholderClaimPolicy)
+	}
+	var authAdmin supabase.AdminClient
+	if cfg.SupabaseURL != "" && cfg.SupabaseServiceRoleKey != "" {
+		var err error
+		authAdmin, err = supabase.NewAdminClient(supabase.AdminConfig{
+			URL:            cfg.SupabaseURL,
+			ServiceRoleKey: cfg.SupabaseServiceRoleKey,
+			Timeout:        cfg.SupabaseAdminTimeout,
+			MaxRetries:     cfg.SupabaseAdminMaxRetries,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating Supabase admin client: %w", err)
+		}
+	} else {
+		logger.Warn("Supabase admin API not configured; auth metadata sync and auth user deletion are disabled")
+	}
+	userService := services.NewUserService(userRepo, expenseRepo, placeholderClaimRepo, groupRepo, groupInviteRepo, balanceEventRepo, settlementService, db, authAdmin, cfg.PlaceholderClaimPolicy, cfg.RequireVerifiedEmail)
+	groupService := services.NewGroupService(groupRepo, userRepo, expenseRepo, tagRepo, readRepo, activityRepo, groupInviteRepo, balanceEventRepo, groupArchiveRepo, reminderResponseRepo, settlementService, notificationService, balanceAlertService, quotaService, userService, db)
+	expenseService := services.NewExpenseService(expenseRepo, groupRepo, tagRepo, eventRepo, readRepo, activityRepo, splitPreferenceRepo, balanceEventRepo, expenseChangeRepo, notificationService, balanceAlertService, quotaService, userService, db, cfg.AdminUserIDs)
+	dashboardService := services.NewDashboardService(userRepo, groupRepo, expenseRepo, readRepo, groupArchiveRepo, userService)
 	friendService := services.NewFriendService(friendRepo, userRepo, groupRepo, expenseRepo, settlementService)
-	commentService := services.NewCommentService(commentRepo, expenseRepo, groupRepo)
+	commentService := services.NewCommentService(commentRepo, expenseRepo, groupRepo, notificationRepo, notificationService)
+	splitPreferenceService := services.NewSplitPreferenceService(splitPreferenceRepo, friendRepo, groupRepo, userRepo)
+	reminderService := services.NewReminderService(userRepo, groupRepo, notificationRepo, reminderResponseRepo, settlementService, notificationService)
+	integrityService := services.NewIntegrityService(integrityRepo, groupRepo, expenseRepo, balanceEventRepo)
+	tagService := services.NewTagService(tagRepo, groupRepo)
+	eventService := services.NewEventService(eventRepo, groupRepo)
+	readService := services.NewReadService(readRepo, expenseRepo, groupRepo)
+	forecastService := services.NewForecastService(groupRepo, expenseRepo, tagRepo, userRepo)
+	statsService := services.NewStatsService(groupRepo, statsRepo, userRepo)
+	balanceEventService := services.NewBalanceEventService(balanceEventRepo, groupRepo, expenseRepo)
 
-	explanationService, err := services.NewExplanationService(cfg.GeminiAPIKey, expenseRepo, groupRepo, userRepo)
+	aiAuditService := services.NewAIAuditService(aiAuditRepo, expenseRepo, groupRepo)
+	explanationService, err := services.NewExplanationService(cfg.GeminiAPIKey, expenseRepo, groupRepo, userRepo, aiAuditService)
 	if err != nil {
-		logger.Fatal("Failed to create explanation service", zap.Error(err))
+		return nil, fmt.Errorf("creating explanation service: %w", err)
 	}
 
-	receiptService, err := services.NewReceiptService(cfg.GeminiAPIKey)
+	ocrProviders, err := services.NewOCRProviders(services.OCRConfig{
+		Order:        cfg.OCRProviders,
+		GeminiAPIKey: cfg.GeminiAPIKey,
+		OpenAIAPIKey: cfg.OpenAIAPIKey,
+		OpenAIModel:  cfg.OpenAIOCRModel,
+	})
 	if err != nil {
-		logger.Fatal("Failed to create receipt service", zap.Error(err))
+		return nil, fmt.Errorf("creating receipt OCR providers: %w", err)
 	}
+	receiptService := services.NewReceiptService(ocrProviders, aiAuditService)
 
 	storageService := storage.NewSupabaseStorage(cfg.SupabaseStorageURL, cfg.SupabaseURL, cfg.SupabaseServiceRoleKey)
+	retentionService := services.NewRetentionService(retentionRepo, groupRepo, expenseRepo, activityRepo, balanceEventRepo, storageService, cfg.SupabaseStorageBucket, db)
+	balanceMetricsService := services.NewBalanceMetricsService(balanceMetricsRepo, db)
+	standingRepaymentService := services.NewStandingRepaymentService(standingRepaymentRepo, groupRepo, groupService, notificationService)
+	groupShareService := services.NewGroupShareService(groupShareLinkRepo, groupRepo, expenseRepo, activityRepo, db)
+	announcementService := services.NewAnnouncementService(announcementRepo)
 
-	authMiddleware := authmiddleware.NewAuthMiddleware(cfg.SupabaseJWTSecret, cfg.SupabaseURL)
+	var tokenVerifier authmiddleware.TokenVerifier
+	var authHandlers *handlers.AuthHandlers
+	var credentialRepo repository.CredentialRepository
+	switch cfg.AuthProvider {
+	case "local":
+		if cfg.JWTSecret == "" {
+			return nil, fmt.Errorf("JWT_SECRET is required when AUTH_PROVIDER=local")
+		}
+		credentialRepo = repository.NewCredentialRepository(db)
+		authService := services.NewLocalAuthService(userRepo, credentialRepo, db, cfg.JWTSecret)
+		authHandlers = handlers.NewAuthHandlers(authService)
+		tokenVerifier = authmiddleware.NewLocalVerifier(cfg.JWTSecret, services.LocalTokenIssuer)
+		logger.Info("Using local auth provider")
+	case "supabase":
+		tokenVerifier = authmiddleware.NewSupabaseVerifier(cfg.SupabaseJWTSecret, cfg.SupabaseURL)
+	default:
+		return nil, fmt.Errorf("unknown AUTH_PROVIDER %q", cfg.AuthProvider)
+	}
+	authMiddleware := authmiddleware.NewAuthMiddleware(tokenVerifier, userRepo)
+
+	exportSigner := services.NewExportSigner(cfg.ExportSigningKey)
+	currencyFormatService := services.NewCurrencyFormatService(currencyRepo)
 
 	h := handlers.NewHandlers(
 		groupService,
@@ -82,15 +228,36 @@ func main() {
 		explanationService,
 		friendService,
 		commentService,
+		quotaService,
+		exportSigner,
+		currencyFormatService,
 		storageService,
 		cfg.SupabaseStorageBucket,
 		cfg.SupabaseGroupPhotosBucket,
 		cfg.SupabaseUserAvatarsBucket,
 	)
 
-	importService := services.NewImportService(groupRepo, userRepo, expenseRepo, db)
+	importService := services.NewImportService(groupRepo, userRepo, expenseRepo, balanceEventRepo, importRepo, quotaService, db)
 	importHandlers := handlers.NewImportHandlers(importService)
+	exportHandlers := handlers.NewExportHandlers(exportSigner)
 	currencyHandlers := handlers.NewCurrencyHandlers(currencyRepo)
+	notificationHandlers := handlers.NewNotificationHandlers(notificationService, reminderService)
+	adminHandlers := handlers.NewAdminHandlers(integrityService, userService, aiAuditService, quotaService)
+	tagHandlers := handlers.NewTagHandlers(tagService)
+	eventHandlers := handlers.NewEventHandlers(eventService)
+	readHandlers := handlers.NewReadHandlers(readService)
+	integrationHandlers := handlers.NewIntegrationHandlers(integrationService)
+	splitPreferenceHandlers := handlers.NewSplitPreferenceHandlers(splitPreferenceService)
+	aiFeedbackHandlers := handlers.NewAIFeedbackHandlers(aiAuditService)
+	forecastHandlers := handlers.NewForecastHandlers(forecastService)
+	statsHandlers := handlers.NewStatsHandlers(statsService)
+	retentionHandlers := handlers.NewRetentionHandlers(retentionService)
+	balanceEventHandlers := handlers.NewBalanceEventHandlers(balanceEventService)
+	standingRepaymentHandlers := handlers.NewStandingRepaymentHandlers(standingRepaymentService)
+	shareHandlers := handlers.NewShareHandlers(groupShareService)
+	announcementHandlers := handlers.NewAnnouncementHandlers(announcementService)
+	jobHandlers := handlers.NewJobHandlers(jobService)
+	fixtureHandlers := handlers.NewFixtureHandlers(services.NewFixtureService(userRepo, credentialRepo, groupService, authAdmin, db))
 
 	r := chi.NewRouter()
 
@@ -98,9 +265,11 @@ func main() {
 	r.Use(middleware.RealIP)
 	r.Use(authmiddleware.ZapLogger(logger))
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
+	r.Use(authmiddleware.LatencyBudget(services.DefaultRequestTimeout))
+	r.Use(authmiddleware.QueryBudget(services.RequestQueryBudget, services.RepeatedQueryThreshold, cfg.ExposeQueryCount))
 	r.Use(authmiddleware.SecurityHeaders)
 	r.Use(authmiddleware.MaxBodySize(cfg.MaxBodySize))
+	r.Use(middleware.Compress(5, "application/json", "text/csv"))
 	if cfg.Env == "production" {
 		r.Use(authmiddleware.StrictTransportSecurity)
 	}
@@ -108,8 +277,8 @@ func main() {
 	corsOptions := cors.Options{
 		AllowedOrigins:   cfg.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-None-Match", authmiddleware.ConsistencyTokenHeader},
+		ExposedHeaders:   []string{"Link", "ETag", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Burst", "X-RateLimit-Remaining", handlers.ExportSignatureHeader, authmiddleware.QueryCountHeader, authmiddleware.ConsistencyTokenHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}
@@ -120,44 +289,78 @@ func main() {
 		w.Write([]byte("OK"))
 	})
 
+	r.Group(func(r chi.Router) {
+		r.Use(httprate.LimitByIP(services.ExportVerifyRateLimit, 1*time.Minute))
+		exportHandlers.RegisterRoutes(r)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(httprate.LimitByIP(services.ShareLinkRateLimit, 1*time.Minute))
+		shareHandlers.RegisterPublicRoutes(r)
+	})
+
+	if cfg.FixturesToken != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(httprate.LimitByIP(services.FixtureRateLimit, 1*time.Minute))
+			r.Use(authmiddleware.RequireStaticToken(handlers.FixturesTokenHeader, cfg.FixturesToken))
+			fixtureHandlers.RegisterRoutes(r)
+		})
+		logger.Warn("Staging fixtures endpoint enabled", zap.String("env", cfg.Env))
+	}
+
+	if authHandlers != nil {
+		r.Group(func(r chi.Router) {
+			r.Use(httprate.LimitByIP(services.AuthRateLimit, 1*time.Minute))
+			authHandlers.RegisterRoutes(r)
+		})
+	}
+
 	r.Route("/api", func(r chi.Router) {
 		r.Use(authMiddleware.Authenticate)
+		r.Use(authmiddleware.MembershipMemo)
+		r.Use(authmiddleware.Consistency(db))
 		r.Use(httprate.LimitByIP(services.GeneralRateLimit, 1*time.Minute))
 		r.Group(func(r chi.Router) {
+			r.Use(authmiddleware.RequireScope(authmiddleware.ScopeAI))
 			r.Use(httprate.LimitByIP(services.AIRateLimit, 1*time.Minute))
+			r.Use(authmiddleware.RouteTimeout("ai", services.AIRequestTimeout))
 			r.Post("/scan-receipt", h.ScanReceipt)
 			r.Post("/expenses/explain", h.ExplainTransaction)
 		})
 
 		h.RegisterRoutes(r)
 		importHandlers.RegisterRoutes(r)
+		notificationHandlers.RegisterRoutes(r)
+		tagHandlers.RegisterRoutes(r)
+		eventHandlers.RegisterRoutes(r)
+		readHandlers.RegisterRoutes(r)
+		integrationHandlers.RegisterRoutes(r)
+		splitPreferenceHandlers.RegisterRoutes(r)
+		aiFeedbackHandlers.RegisterRoutes(r)
+		forecastHandlers.RegisterRoutes(r)
+		statsHandlers.RegisterRoutes(r)
+		retentionHandlers.RegisterRoutes(r)
+		balanceEventHandlers.RegisterRoutes(r)
+		standingRepaymentHandlers.RegisterRoutes(r)
+		shareHandlers.RegisterRoutes(r)
+		announcementHandlers.RegisterRoutes(r)
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(authmiddleware.RequireAdmin(cfg.AdminUserIDs))
+			adminHandlers.RegisterRoutes(r)
+			announcementHandlers.RegisterAdminRoutes(r)
+			jobHandlers.RegisterAdminRoutes(r)
+		})
 		r.Get("/currencies", currencyHandlers.GetCurrencies)
 	})
 
-	srv := &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: r,
-	}
-
-	go func() {
-		logger.Info("Server starting", zap.String("port", cfg.Port))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Server failed to start", zap.Error(err))
-		}
-	}()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Info("Shutting down server...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown", zap.Error(err))
-	}
-
-	logger.Info("Server exited")
+	return &server{
+		router: r,
+		workers: []func(context.Context){
+			integrationService.RunDeliveryWorker,
+			retentionService.RunWorker,
+			balanceMetricsService.RunVerifier,
+			standingRepaymentService.RunWorker,
+			jobService.RunWorker,
+		},
+	}, nil
 }
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"unwise-backend/config"
+	"unwise-backend/database"
+	"unwise-backend/repository"
+	"unwise-backend/services"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = []command{
+	{"users", "List users (-placeholders, -search, -limit)", runUsers},
+	{"rebuild-balances", "Recompute a group's balances from the ledger and report drift (-group)", runRebuildBalances},
+	{"verify-balance-metrics", "Check the dashboard's pre-aggregated balances against expenses and repair drift", runVerifyBalanceMetrics},
+	{"import", "Import a Splitwise CSV into a group (-group, -user, -file, -map, -dry-run, -import-duplicates)", runImport},
+	{"purge-placeholders", "Delete unclaimed placeholders that belong to no group and no expense (-dry-run)", runPurgePlaceholders},
+	{"token", "Generate an access token for a user (-user, -ttl)", runToken},
+}
+
+type app struct {
+	cfg                   *config.Config
+	db                    *database.DB
+	userRepo              repository.UserRepository
+	integrityService      services.IntegrityService
+	importService         services.ImportService
+	balanceMetricsService services.BalanceMetricsService
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == os.Args[1] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	logger, _ := zap.NewDevelopment()
+	defer logger.Sync()
+	undo := zap.ReplaceGlobals(logger)
+	defer undo()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fatalf("loading config: %v", err)
+	}
+
+	db, err := database.New(cfg.DatabaseURL)
+	if err != nil {
+		fatalf("connecting to database: %v", err)
+	}
+	defer db.Close()
+
+	userRepo := repository.NewUserRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+	expenseRepo := repository.NewExpenseRepository(db)
+	balanceEventRepo := repository.NewBalanceEventRepository(db)
+	a := &app{
+		cfg:              cfg,
+		db:               db,
+		userRepo:         userRepo,
+		integrityService: services.NewIntegrityService(repository.NewIntegrityRepository(db), groupRepo, expenseRepo, balanceEventRepo),
+		// Operator imports are not subject to user quotas.
+		importService: services.NewImportService(groupRepo, userRepo, expenseRepo, balanceEventRepo, repository.NewImportRepository(db), services.NewQuotaService(repository.NewQuotaRepository(db), services.QuotaLimits{}), db),
+
+		balanceMetricsService: services.NewBalanceMetricsService(repository.NewBalanceMetricsRepository(db), db),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := cmd.run(ctx, a, os.Args[2:]); err != nil {
+		fatalf("%s: %v", cmd.name, err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: unwctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", c.name, c.summary)
+	}
+	w.Flush()
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'unwctl <command> -h' for command flags. Configuration is read from the environment / .env.")
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "unwctl: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func runUsers(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("users", flag.ExitOnError)
+	placeholders := fs.Bool("placeholders", false, "only list unclaimed placeholders")
+	search := fs.String("search", "", "filter by name or email (case-insensitive)")
+	limit := fs.Int("limit", 100, "maximum rows to print")
+	fs.Parse(args)
+
+	query := `SELECT id, COALESCE(email, ''), name, is_placeholder, claimed_by IS NOT NULL, created_at
+		FROM users
+		WHERE deleted_at IS NULL
+			AND ($1 = FALSE OR (is_placeholder = TRUE AND claimed_by IS NULL))
+			AND ($2 = '' OR name ILIKE '%' || $2 || '%' OR email ILIKE '%' || $2 || '%')
+		ORDER BY created_at
+		LIMIT $3`
+	rows, err := a.db.Pool.Query(ctx, query, *placeholders, *search, *limit)
+	if err != nil {
+		return fmt.Errorf("querying users: %w", err)
+	}
+	defer rows.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tKIND\tCREATED")
+	count := 0
+	for rows.Next() {
+		var id, email, name string
+		var isPlaceholder, claimed bool
+		var createdAt time.Time
+		if err := rows.Scan(&id, &email, &name, &isPlaceholder, &claimed, &createdAt); err != nil {
+			return fmt.Errorf("scanning user: %w", err)
+		}
+		kind := "user"
+		switch {
+		case isPlaceholder && claimed:
+			kind = "placeholder (claimed)"
+		case isPlaceholder:
+			kind = "placeholder"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", id, email, name, kind, createdAt.Format("2006-01-02"))
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	w.Flush()
+	fmt.Printf("\n%d user(s)\n", count)
+	return nil
+}
+
+func runVerifyBalanceMetrics(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("verify-balance-metrics", flag.ExitOnError)
+	fs.Parse(args)
+
+	repaired, err := a.balanceMetricsService.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d balance metric(s) repaired\n", repaired)
+	return nil
+}
+
+func runRebuildBalances(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("rebuild-balances", flag.ExitOnError)
+	groupID := fs.String("group", "", "group ID (required)")
+	asJSON := fs.Bool("json", false, "print the full result as JSON")
+	fs.Parse(args)
+	if *groupID == "" {
+		return fmt.Errorf("-group is required")
+	}
+
+	result, err := a.integrityService.RebuildGroupBalances(ctx, *groupID)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result)
+	}
+
+	fmt.Printf("Balances for %s (%s)\n\n", result.GroupName, result.GroupID)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MEMBER\tCURRENCY\tBALANCE")
+	for _, b := range result.Balances {
+		fmt.Fprintf(w, "%s (%s)\t%s\t%.2f\n", b.Name, b.UserID, b.Currency, b.Balance)
+	}
+	w.Flush()
+
+	currencies := make([]string, 0, len(result.CurrencyDrift))
+	for currency := range result.CurrencyDrift {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	fmt.Println()
+	for _, currency := range currencies {
+		status := "ok"
+		if drift := result.CurrencyDrift[currency]; drift > services.BalanceThreshold || drift < -services.BalanceThreshold {
+			status = "DRIFT"
+		}
+		fmt.Printf("%s net: %.2f %s\n", currency, result.CurrencyDrift[currency], status)
+	}
+
+	if len(result.UnbalancedExpenses) > 0 {
+		fmt.Printf("\n%d expense(s) whose payers or splits do not add up:\n", len(result.UnbalancedExpenses))
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "EXPENSE\tDESCRIPTION\tTOTAL\tPAID\tSPLIT")
+		for _, e := range result.UnbalancedExpenses {
+			fmt.Fprintf(w, "%s\t%s\t%.2f %s\t%.2f\t%.2f\n", e.ExpenseID, e.Description, e.TotalAmount, e.Currency, e.PaidTotal, e.SplitTotal)
+		}
+		w.Flush()
+	}
+
+	if len(result.LedgerMismatches) > 0 {
+		fmt.Printf("\n%d balance(s) that disagree with the balance event ledger:\n", len(result.LedgerMismatches))
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MEMBER\tCURRENCY\tLEDGER\tCOMPUTED\tDRIFT")
+		for _, m := range result.LedgerMismatches {
+			fmt.Fprintf(w, "%s (%s)\t%s\t%.2f\t%.2f\t%.2f\n", m.Name, m.UserID, m.Currency, m.LedgerBalance, m.ComputedBalance, m.Drift)
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+type mappingFlag map[string]*string
+
+func (m mappingFlag) String() string { return "" }
+
+func (m mappingFlag) Set(value string) error {
+	name, userID, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected \"CSV Name=user-id\" (leave the ID empty to create a placeholder)")
+	}
+	if userID = strings.TrimSpace(userID); userID == "" {
+		m[strings.TrimSpace(name)] = nil
+		return nil
+	}
+	m[strings.TrimSpace(name)] = &userID
+	return nil
+}
+
+func runImport(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	groupID := fs.String("group", "", "group ID (required)")
+	userID := fs.String("user", "", "member performing the import (required)")
+	path := fs.String("file", "", "Splitwise CSV export (required)")
+	dryRun := fs.Bool("dry-run", false, "only preview the import")
+	importDuplicates := fs.Bool("import-duplicates", false, "import rows an earlier import already brought in instead of skipping them")
+	mapping := mappingFlag{}
+	fs.Var(mapping, "map", "map a CSV member to a user, e.g. -map \"Alice=<user-id>\" (repeatable; unmapped members use the suggested mapping)")
+	fs.Parse(args)
+	if *groupID == "" || *userID == "" || *path == "" {
+		return fmt.Errorf("-group, -user and -file are required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	preview, err := a.importService.PreviewSplitwiseCSV(ctx, *groupID, *userID, file)
+	if err != nil {
+		return err
+	}
+	for _, member := range preview.CSVMembers {
+		if _, ok := mapping[member]; !ok {
+			mapping[member] = preview.SuggestedMappings[member]
+		}
+	}
+
+	fmt.Printf("%d expense(s), %d payment(s), total %.2f\n", preview.ExpenseCount, preview.PaymentCount, preview.TotalAmount)
+	if preview.DuplicateCount > 0 {
+		fmt.Printf("%d row(s) already imported\n", preview.DuplicateCount)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CSV MEMBER\tMAPPED TO")
+	for _, member := range preview.CSVMembers {
+		target := "(new placeholder)"
+		if id := mapping[member]; id != nil {
+			target = *id
+		}
+		fmt.Fprintf(w, "%s\t%s\n", member, target)
+	}
+	w.Flush()
+	if *dryRun {
+		return nil
+	}
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return err
+	}
+	result, err := a.importService.ImportSplitwiseCSV(ctx, *groupID, *userID, file, mapping, *importDuplicates)
+	if err != nil {
+		return err
+	}
+	return printJSON(result)
+}
+
+func runPurgePlaceholders(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("purge-placeholders", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "list the placeholders without deleting them")
+	fs.Parse(args)
+
+	result, err := a.integrityService.PurgeOrphanedPlaceholders(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range result.Placeholders {
+		fmt.Printf("%s  %s  (created %s)\n", p.ID, p.Name, p.CreatedAt.Format("2006-01-02"))
+	}
+	if result.DryRun {
+		fmt.Printf("\n%d orphaned placeholder(s) would be deleted\n", len(result.Placeholders))
+		return nil
+	}
+	fmt.Printf("\nDeleted %d of %d orphaned placeholder(s)\n", result.Deleted, len(result.Placeholders))
+	return nil
+}
+
+func runToken(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("token", flag.ExitOnError)
+	userID := fs.String("user", "", "user ID (required)")
+	ttl := fs.Duration("ttl", 24*time.Hour, "token lifetime")
+	fs.Parse(args)
+	if *userID == "" {
+		return fmt.Errorf("-user is required")
+	}
+
+	user, err := a.userRepo.GetByID(ctx, *userID)
+	if err != nil {
+		return fmt.Errorf("looking up user: %w", err)
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":   user.ID,
+		"email": user.Email,
+		"user_metadata": map[string]interface{}{
+			"full_name": user.Name,
+		},
+		"iat": now.Unix(),
+		"exp": now.Add(*ttl).Unix(),
+	}
+
+	var secret []byte
+	switch a.cfg.AuthProvider {
+	case "local":
+		if a.cfg.JWTSecret == "" {
+			return fmt.Errorf("JWT_SECRET is not set")
+		}
+		claims["typ"] = "access"
+		claims["iss"] = services.LocalTokenIssuer
+		secret = []byte(a.cfg.JWTSecret)
+	case "supabase":
+		if a.cfg.SupabaseJWTSecret == "" {
+			return fmt.Errorf("SUPABASE_JWT_SECRET is not set")
+		}
+		claims["iss"] = "supabase"
+		claims["aud"] = "authenticated"
+		claims["role"] = "authenticated"
+		secret = []byte(a.cfg.SupabaseJWTSecret)
+		if decoded, err := base64.StdEncoding.DecodeString(a.cfg.SupabaseJWTSecret); err == nil {
+			secret = decoded
+		}
+	default:
+		return fmt.Errorf("unknown AUTH_PROVIDER %q", a.cfg.AuthProvider)
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return fmt.Errorf("signing token: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Token for %s (%s), valid until %s\n", user.Name, user.ID, now.Add(*ttl).Format(time.RFC3339))
+	fmt.Println(token)
+	return nil
+}
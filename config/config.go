@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,17 +13,33 @@ import (
 type Config struct {
 	Port                      string
 	Env                       string
+	AuthProvider              string
+	JWTSecret                 string
 	DatabaseURL               string
 	SupabaseURL               string
 	SupabaseJWTSecret         string
 	SupabaseServiceRoleKey    string
 	GeminiAPIKey              string
+	OpenAIAPIKey              string
+	OpenAIOCRModel            string
+	OCRProviders              []string
 	SupabaseStorageBucket     string
 	SupabaseStorageURL        string
 	SupabaseGroupPhotosBucket string
 	SupabaseUserAvatarsBucket string
 	AllowedOrigins            []string
+	AdminUserIDs              []string
+	PlaceholderClaimPolicy    string
 	MaxBodySize               int64 
+	SupabaseAdminTimeout      time.Duration
+	SupabaseAdminMaxRetries   int
+	RequireVerifiedEmail      bool
+	ExportSigningKey          string
+	ExposeQueryCount          bool
+	MaxGroupsPerUser          int
+	MaxMembersPerGroup        int
+	MaxExpensesPerGroup       int
+	FixturesToken             string
 }
 
 func Load() (*Config, error) {
@@ -32,7 +49,7 @@ func Load() (*Config, error) {
 	origins := os.Getenv("ALLOWED_ORIGINS")
 	var allowedOrigins []string
 	if origins != "" {
-		allowedOrigins = splitOrigins(origins)
+		allowedOrigins = splitList(origins)
 	} else {
 		if env == "production" {
 			log.Println("[WARNING] ALLOWED_ORIGINS not set in production! Defaulting to '*' which is insecure.")
@@ -48,20 +65,81 @@ func Load() (*Config, error) {
 		}
 	}
 
+	adminTimeout := 10 * time.Second
+	if timeoutStr := os.Getenv("SUPABASE_ADMIN_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := time.ParseDuration(timeoutStr); err == nil {
+			adminTimeout = timeout
+		}
+	}
+
+	adminMaxRetries := 3
+	if retriesStr := os.Getenv("SUPABASE_ADMIN_MAX_RETRIES"); retriesStr != "" {
+		if retries, err := strconv.Atoi(retriesStr); err == nil {
+			adminMaxRetries = retries
+		}
+	}
+
+	// Local accounts have no verification flow, so only Supabase enforces it by default.
+	authProvider := getEnv("AUTH_PROVIDER", "supabase")
+	requireVerifiedEmail := authProvider == "supabase"
+	if requireStr := os.Getenv("REQUIRE_VERIFIED_EMAIL"); requireStr != "" {
+		if require, err := strconv.ParseBool(requireStr); err == nil {
+			requireVerifiedEmail = require
+		}
+	}
+
+	// Query counts are a debugging aid, so they're only sent in development
+	// unless asked for.
+	exposeQueryCount := env == "development"
+	if exposeStr := os.Getenv("EXPOSE_QUERY_COUNT"); exposeStr != "" {
+		if expose, err := strconv.ParseBool(exposeStr); err == nil {
+			exposeQueryCount = expose
+		}
+	}
+
+	// Soft quotas; 0 disables a limit.
+	maxGroupsPerUser := getEnvInt("MAX_GROUPS_PER_USER", 50)
+	maxMembersPerGroup := getEnvInt("MAX_MEMBERS_PER_GROUP", 100)
+	maxExpensesPerGroup := getEnvInt("MAX_EXPENSES_PER_GROUP", 5000)
+
+	// The fixtures endpoint mints accounts on demand, so it never runs in
+	// production, whatever the environment says.
+	fixturesToken := os.Getenv("FIXTURES_TOKEN")
+	if fixturesToken != "" && env == "production" {
+		log.Println("[WARNING] FIXTURES_TOKEN is ignored in production; the fixtures endpoint stays disabled.")
+		fixturesToken = ""
+	}
+
 	return &Config{
 		Port:                      getEnv("PORT", "8080"),
 		Env:                       env,
+		AuthProvider:              authProvider,
+		JWTSecret:                 getEnv("JWT_SECRET", ""),
 		DatabaseURL:               getEnv("DATABASE_URL", ""),
 		SupabaseURL:               getEnv("SUPABASE_URL", ""),
 		SupabaseJWTSecret:         getEnv("SUPABASE_JWT_SECRET", ""),
 		SupabaseServiceRoleKey:    getEnv("SUPABASE_SERVICE_ROLE_KEY", ""),
 		GeminiAPIKey:              getEnv("GEMINI_API_KEY", ""),
+		OpenAIAPIKey:              getEnv("OPENAI_API_KEY", ""),
+		OpenAIOCRModel:            getEnv("OPENAI_OCR_MODEL", ""),
+		OCRProviders:              splitList(getEnv("OCR_PROVIDERS", "gemini,openai")),
 		SupabaseStorageBucket:     getEnv("SUPABASE_STORAGE_BUCKET", "receipts"),
 		SupabaseStorageURL:        getEnv("SUPABASE_STORAGE_URL", ""),
 		SupabaseGroupPhotosBucket: getEnv("SUPABASE_GROUP_PHOTOS_BUCKET", "group-photos"),
 		SupabaseUserAvatarsBucket: getEnv("SUPABASE_USER_AVATARS_BUCKET", "user-avatars"),
 		AllowedOrigins:            allowedOrigins,
+		AdminUserIDs:              splitList(os.Getenv("ADMIN_USER_IDS")),
+		PlaceholderClaimPolicy:    strings.ToLower(getEnv("PLACEHOLDER_CLAIM_POLICY", "open")),
 		MaxBodySize:               maxBodySize,
+		SupabaseAdminTimeout:      adminTimeout,
+		SupabaseAdminMaxRetries:   adminMaxRetries,
+		RequireVerifiedEmail:      requireVerifiedEmail,
+		ExportSigningKey:          getEnv("EXPORT_SIGNING_KEY", ""),
+		ExposeQueryCount:          exposeQueryCount,
+		MaxGroupsPerUser:          maxGroupsPerUser,
+		MaxMembersPerGroup:        maxMembersPerGroup,
+		MaxExpensesPerGroup:       maxExpensesPerGroup,
+		FixturesToken:             fixturesToken,
 	}, nil
 }
 
@@ -73,7 +151,14 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
-func splitOrigins(origins string) []string {
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value >= 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func splitList(origins string) []string {
 	parts := strings.Split(origins, ",")
 	result := make([]string, 0, len(parts))
 	for _, part := range parts {
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type consistencyKey struct{}
+
+// ConsistencyToken marks a point just after a write: the primary's WAL
+// position and the time it was taken. A read that carries one must reflect
+// every write committed before it. Reads served from a replica need it to
+// have replayed up to LSN; cached results need to be newer than At.
+type ConsistencyToken struct {
+	LSN uint64
+	At  time.Time
+}
+
+// CurrentConsistencyToken returns a token covering everything committed so
+// far. Call it on the primary.
+func (db *DB) CurrentConsistencyToken(ctx context.Context) (ConsistencyToken, error) {
+	var lsn string
+	if err := db.Pool.QueryRow(ctx, `SELECT pg_current_wal_lsn()::TEXT`).Scan(&lsn); err != nil {
+		return ConsistencyToken{}, fmt.Errorf("getting current WAL position: %w", err)
+	}
+	parsed, err := parseLSN(lsn)
+	if err != nil {
+		return ConsistencyToken{}, err
+	}
+	return ConsistencyToken{LSN: parsed, At: time.Now()}, nil
+}
+
+// parseLSN reads Postgres' pg_lsn text form, e.g. "16/B374D848".
+func parseLSN(lsn string) (uint64, error) {
+	hi, lo, ok := strings.Cut(lsn, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid WAL position %q", lsn)
+	}
+	h, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid WAL position %q: %w", lsn, err)
+	}
+	l, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid WAL position %q: %w", lsn, err)
+	}
+	return h<<32 | l, nil
+}
+
+// String encodes the token for clients, who treat it as opaque.
+func (t ConsistencyToken) String() string {
+	return strconv.FormatUint(t.LSN, 16) + "." + strconv.FormatInt(t.At.UnixNano(), 36)
+}
+
+func ParseConsistencyToken(s string) (ConsistencyToken, error) {
+	lsn, at, ok := strings.Cut(s, ".")
+	if !ok {
+		return ConsistencyToken{}, fmt.Errorf("invalid consistency token")
+	}
+	parsedLSN, err := strconv.ParseUint(lsn, 16, 64)
+	if err != nil {
+		return ConsistencyToken{}, fmt.Errorf("invalid consistency token: %w", err)
+	}
+	nanos, err := strconv.ParseInt(at, 36, 64)
+	if err != nil || nanos <= 0 {
+		return ConsistencyToken{}, fmt.Errorf("invalid consistency token")
+	}
+	return ConsistencyToken{LSN: parsedLSN, At: time.Unix(0, nanos)}, nil
+}
+
+// WithMinConsistency returns a context whose reads must reflect token.
+func WithMinConsistency(ctx context.Context, token ConsistencyToken) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, token)
+}
+
+// MinConsistency returns the token the request's reads must reflect, if any.
+func MinConsistency(ctx context.Context) (ConsistencyToken, bool) {
+	token, ok := ctx.Value(consistencyKey{}).(ConsistencyToken)
+	return token, ok
+}
+
+// FreshEnough reports whether a result computed at computedAt may be served
+// to ctx's request: always, unless the request carries a token from a later
+// write.
+func FreshEnough(ctx context.Context, computedAt time.Time) bool {
+	token, ok := MinConsistency(ctx)
+	return !ok || !computedAt.Before(token.At)
+}
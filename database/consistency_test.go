@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseLSN(t *testing.T) {
+	tests := []struct {
+		lsn         string
+		expected    uint64
+		expectError bool
+	}{
+		{lsn: "0/0", expected: 0},
+		{lsn: "16/B374D848", expected: 0x16B374D848},
+		{lsn: "FFFFFFFF/FFFFFFFF", expected: 0xFFFFFFFFFFFFFFFF},
+		{lsn: "B374D848", expectError: true},
+		{lsn: "16/XYZ", expectError: true},
+		{lsn: "100000000/0", expectError: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseLSN(tt.lsn)
+		if (err != nil) != tt.expectError {
+			t.Errorf("parseLSN(%q) error = %v, expectError %v", tt.lsn, err, tt.expectError)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("parseLSN(%q) = %x, expected %x", tt.lsn, got, tt.expected)
+		}
+	}
+}
+
+func TestConsistencyTokenRoundTrip(t *testing.T) {
+	token := ConsistencyToken{LSN: 0x16B374D848, At: time.Unix(1718000000, 123456789)}
+	parsed, err := ParseConsistencyToken(token.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.LSN != token.LSN || !parsed.At.Equal(token.At) {
+		t.Errorf("ParseConsistencyToken(%q) = %+v, expected %+v", token.String(), parsed, token)
+	}
+
+	for _, invalid := range []string{"", "abc", "zz.abc", "16.", "16.-5"} {
+		if _, err := ParseConsistencyToken(invalid); err == nil {
+			t.Errorf("ParseConsistencyToken(%q) expected error", invalid)
+		}
+	}
+}
+
+func TestFreshEnough(t *testing.T) {
+	write := time.Unix(1718000000, 0)
+	ctx := WithMinConsistency(context.Background(), ConsistencyToken{At: write})
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		computedAt time.Time
+		expected   bool
+	}{
+		{name: "No token", ctx: context.Background(), computedAt: write.Add(-time.Hour), expected: true},
+		{name: "Computed before the write", ctx: ctx, computedAt: write.Add(-time.Second)},
+		{name: "Computed at the write", ctx: ctx, computedAt: write, expected: true},
+		{name: "Computed after the write", ctx: ctx, computedAt: write.Add(time.Second), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FreshEnough(tt.ctx, tt.computedAt); got != tt.expected {
+				t.Errorf("FreshEnough() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -18,13 +18,29 @@ type Querier interface {
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 }
 
+// TxRunner runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise. *DB is the real one; services that take a TxRunner can be
+// tested without a database.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(Querier) error) error
+}
+
 type DB struct {
 	Pool *pgxpool.Pool
 }
 
 func New(databaseURL string) (*DB, error) {
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing database URL: %w", err)
+	}
+	return NewWithConfig(config)
+}
+
+func NewWithConfig(config *pgxpool.Config) (*DB, error) {
 	zap.L().Info("Initializing database connection pool")
-	pool, err := pgxpool.New(context.Background(), databaseURL)
+	config.ConnConfig.Tracer = queryTracer{}
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		zap.L().Error("Failed to create connection pool", zap.Error(err))
 		return nil, fmt.Errorf("creating connection pool: %w", err)
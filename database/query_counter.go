@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type queryCounterKey struct{}
+
+// Counting each distinct statement makes one repeated per row of an earlier
+// result (an N+1) stand out.
+type QueryCounter struct {
+	mu         sync.Mutex
+	total      int
+	statements map[string]int
+}
+
+// A context that already has a counter keeps it.
+func WithQueryCounter(ctx context.Context) (context.Context, *QueryCounter) {
+	if counter := QueryCounterFrom(ctx); counter != nil {
+		return ctx, counter
+	}
+	counter := &QueryCounter{statements: make(map[string]int)}
+	return context.WithValue(ctx, queryCounterKey{}, counter), counter
+}
+
+func QueryCounterFrom(ctx context.Context) *QueryCounter {
+	counter, _ := ctx.Value(queryCounterKey{}).(*QueryCounter)
+	return counter
+}
+
+func (c *QueryCounter) record(sql string) {
+	key := strings.Join(strings.Fields(sql), " ")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total++
+	c.statements[key]++
+}
+
+func (c *QueryCounter) Total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+// Ties go to the statement that sorts first, so the result is stable.
+func (c *QueryCounter) MostRepeated() (string, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var statement string
+	var count int
+	for sql, n := range c.statements {
+		if n > count || n == count && sql < statement {
+			statement, count = sql, n
+		}
+	}
+	return statement, count
+}
+
+// Each query of a batch is counted too.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if counter := QueryCounterFrom(ctx); counter != nil {
+		counter.record(data.SQL)
+	}
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+func (queryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return ctx
+}
+
+func (queryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	if counter := QueryCounterFrom(ctx); counter != nil {
+		counter.record(data.SQL)
+	}
+}
+
+func (queryTracer) TraceBatchEnd(context.Context, *pgx.Conn, pgx.TraceBatchEndData) {}
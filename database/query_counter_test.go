@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQueryCounter(t *testing.T) {
+	ctx, counter := WithQueryCounter(context.Background())
+	if again, same := WithQueryCounter(ctx); again != ctx || same != counter {
+		t.Fatal("expected a context with a counter to keep it")
+	}
+
+	counter.record("SELECT * FROM groups WHERE id = $1")
+	for i := 0; i < 3; i++ {
+		counter.record("SELECT *\n\t  FROM receipt_items\n\t  WHERE expense_id = $1")
+	}
+	counter.record("SELECT * FROM receipt_items WHERE expense_id = $1")
+
+	if total := counter.Total(); total != 5 {
+		t.Errorf("expected 5 queries, got %d", total)
+	}
+	statement, repeats := counter.MostRepeated()
+	if statement != "SELECT * FROM receipt_items WHERE expense_id = $1" || repeats != 4 {
+		t.Errorf("expected the receipt item query 4 times, got %q %d times", statement, repeats)
+	}
+
+	if QueryCounterFrom(context.Background()) != nil {
+		t.Error("expected no counter on a plain context")
+	}
+}
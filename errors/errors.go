@@ -13,6 +13,8 @@ const (
 	CodeTokenInvalid            ErrorCode = "AUTH_003"
 	CodeInsufficientPermissions ErrorCode = "AUTH_004"
 	CodeNotGroupMember          ErrorCode = "AUTH_005"
+	CodeEmailNotVerified        ErrorCode = "AUTH_006"
+	CodeAccountDeleted          ErrorCode = "AUTH_007"
 
 	CodeInvalidRequest       ErrorCode = "VALIDATION_001"
 	CodeMissingRequiredField ErrorCode = "VALIDATION_002"
@@ -39,6 +41,7 @@ const (
 	CodeCannotDeleteWithDebts         ErrorCode = "BUSINESS_003"
 	CodeCannotRemoveMemberWithBalance ErrorCode = "BUSINESS_004"
 	CodeInvalidSettlement             ErrorCode = "BUSINESS_005"
+	CodeExpenseLimitExceeded          ErrorCode = "BUSINESS_006"
 
 	CodeDatabaseError       ErrorCode = "DATABASE_001"
 	CodeDatabaseConnection  ErrorCode = "DATABASE_002"
@@ -49,6 +52,14 @@ const (
 	CodeStorageError         ErrorCode = "EXTERNAL_002"
 	CodeAIServiceError       ErrorCode = "EXTERNAL_003"
 
+	CodeRateLimited ErrorCode = "RATE_LIMIT_001"
+
+	CodeGroupQuotaExceeded   ErrorCode = "QUOTA_001"
+	CodeMemberQuotaExceeded  ErrorCode = "QUOTA_002"
+	CodeExpenseQuotaExceeded ErrorCode = "QUOTA_003"
+
+	CodeRequestTimeout ErrorCode = "TIMEOUT_001"
+
 	CodeInternalError ErrorCode = "INTERNAL_001"
 )
 
@@ -63,6 +74,8 @@ const (
 	ErrorTypeUnprocessable
 	ErrorTypeInternal
 	ErrorTypeServiceUnavailable
+	ErrorTypeGatewayTimeout
+	ErrorTypeGone
 )
 
 type AppError struct {
@@ -71,6 +84,15 @@ type AppError struct {
 	Message string    `json:"message"`
 	Details string    `json:"details,omitempty"`
 	Err     error     `json:"-"`
+
+	// Key and Args identify the message in the translation catalog; errors
+	// built from free-form text have no key and are always rendered as-is.
+	Key  MessageKey    `json:"-"`
+	Args []interface{} `json:"-"`
+
+	// Data is machine-readable detail for clients, sent alongside the
+	// human-readable message.
+	Data interface{} `json:"-"`
 }
 
 func (e *AppError) Error() string {
@@ -101,6 +123,7 @@ func TokenExpired() *AppError {
 		Type:    ErrorTypeUnauthorized,
 		Code:    CodeTokenExpired,
 		Message: "Your session has expired. Please log in again.",
+		Key:     KeyTokenExpired,
 	}
 }
 
@@ -109,6 +132,25 @@ func TokenInvalid() *AppError {
 		Type:    ErrorTypeUnauthorized,
 		Code:    CodeTokenInvalid,
 		Message: "Invalid authentication token.",
+		Key:     KeyTokenInvalid,
+	}
+}
+
+func AccountDeleted() *AppError {
+	return &AppError{
+		Type:    ErrorTypeGone,
+		Code:    CodeAccountDeleted,
+		Message: "This account has been deleted.",
+		Key:     KeyAccountDeleted,
+	}
+}
+
+func InvalidCredentials() *AppError {
+	return &AppError{
+		Type:    ErrorTypeUnauthorized,
+		Code:    CodeUnauthorized,
+		Message: "Invalid email or password.",
+		Key:     KeyInvalidCredentials,
 	}
 }
 
@@ -117,6 +159,38 @@ func NotGroupMember() *AppError {
 		Type:    ErrorTypeForbidden,
 		Code:    CodeNotGroupMember,
 		Message: "You are not a member of this group.",
+		Key:     KeyNotGroupMember,
+	}
+}
+
+func EmailNotVerified(action string) *AppError {
+	return &AppError{
+		Type:    ErrorTypeForbidden,
+		Code:    CodeEmailNotVerified,
+		Message: "Please verify your email address first.",
+		Details: fmt.Sprintf("A verified email is required for %s.", action),
+		Key:     KeyEmailNotVerified,
+		Args:    []interface{}{action},
+	}
+}
+
+func ExpenseEditNotAllowed(policy string) *AppError {
+	return &AppError{
+		Type:    ErrorTypeForbidden,
+		Code:    CodeInsufficientPermissions,
+		Message: "You are not allowed to change this expense.",
+		Details: fmt.Sprintf("This group's expense edit policy is %s.", policy),
+		Key:     KeyExpenseEditNotAllowed,
+		Args:    []interface{}{policy},
+	}
+}
+
+func ExclusionResolveNotAllowed() *AppError {
+	return &AppError{
+		Type:    ErrorTypeForbidden,
+		Code:    CodeInsufficientPermissions,
+		Message: "Only the person who added this expense can resolve exclusion requests.",
+		Key:     KeyExclusionResolveNotAllowed,
 	}
 }
 
@@ -142,6 +216,8 @@ func MissingRequiredField(fieldName string) *AppError {
 		Type:    ErrorTypeBadRequest,
 		Code:    CodeMissingRequiredField,
 		Message: fmt.Sprintf("%s is required.", fieldName),
+		Key:     KeyMissingRequiredField,
+		Args:    []interface{}{fieldName},
 	}
 }
 
@@ -151,6 +227,18 @@ func InvalidFieldFormat(fieldName, expectedFormat string) *AppError {
 		Code:    CodeInvalidFieldFormat,
 		Message: fmt.Sprintf("Invalid format for %s.", fieldName),
 		Details: fmt.Sprintf("Expected format: %s", expectedFormat),
+		Key:     KeyInvalidFieldFormat,
+		Args:    []interface{}{fieldName, expectedFormat},
+	}
+}
+
+func InvalidEmail(email string) *AppError {
+	return &AppError{
+		Type:    ErrorTypeBadRequest,
+		Code:    CodeInvalidEmail,
+		Message: fmt.Sprintf("'%s' is not a valid email address.", email),
+		Key:     KeyInvalidEmail,
+		Args:    []interface{}{email},
 	}
 }
 
@@ -162,12 +250,43 @@ func InvalidAmount(message string) *AppError {
 	}
 }
 
-func AmountMismatch(splitTotal, expectedTotal float64, splitType string) *AppError {
-	return &AppError{
+// AmountEntry is one split or payer row of a request whose amounts do not add
+// up. Index is the row's position in the request. Expected and Delta are set
+// only when the split type fixes what the row should be.
+type AmountEntry struct {
+	Index      int      `json:"index"`
+	UserID     string   `json:"user_id"`
+	UserName   string   `json:"user_name,omitempty"`
+	Amount     float64  `json:"amount"`
+	Percentage *float64 `json:"percentage,omitempty"`
+	Expected   *float64 `json:"expected,omitempty"`
+	Delta      *float64 `json:"delta,omitempty"`
+	Mismatched bool     `json:"mismatched"`
+}
+
+// AmountMismatchData is the Data of an AmountMismatch error. Difference is
+// Total minus ExpectedTotal.
+type AmountMismatchData struct {
+	Kind            string        `json:"kind"`
+	Total           float64       `json:"total"`
+	ExpectedTotal   float64       `json:"expected_total"`
+	Difference      float64       `json:"difference"`
+	PercentageTotal *float64      `json:"percentage_total,omitempty"`
+	Entries         []AmountEntry `json:"entries"`
+}
+
+func AmountMismatch(splitTotal, expectedTotal float64, splitType string, data *AmountMismatchData) *AppError {
+	e := &AppError{
 		Type:    ErrorTypeBadRequest,
 		Code:    CodeAmountMismatch,
 		Message: fmt.Sprintf("Sum of %s amounts (%.2f) does not equal total amount (%.2f).", splitType, splitTotal, expectedTotal),
+		Key:     KeyAmountMismatch,
+		Args:    []interface{}{splitType, splitTotal, expectedTotal},
+	}
+	if data != nil {
+		e.Data = data
 	}
+	return e
 }
 
 func NotFound(resourceType string) *AppError {
@@ -175,6 +294,8 @@ func NotFound(resourceType string) *AppError {
 		Type:    ErrorTypeNotFound,
 		Code:    CodeNotFound,
 		Message: fmt.Sprintf("%s not found.", resourceType),
+		Key:     KeyNotFound,
+		Args:    []interface{}{resourceType},
 	}
 }
 
@@ -183,6 +304,7 @@ func UserNotFound() *AppError {
 		Type:    ErrorTypeNotFound,
 		Code:    CodeUserNotFound,
 		Message: "User not found.",
+		Key:     KeyUserNotFound,
 	}
 }
 
@@ -192,6 +314,8 @@ func UserNotFoundByEmail(email string) *AppError {
 		Code:    CodeUserNotFound,
 		Message: fmt.Sprintf("No user found with email '%s'.", email),
 		Details: "Please check the email address or ask them to sign up first.",
+		Key:     KeyUserNotFoundByEmail,
+		Args:    []interface{}{email},
 	}
 }
 
@@ -200,6 +324,7 @@ func GroupNotFound() *AppError {
 		Type:    ErrorTypeNotFound,
 		Code:    CodeGroupNotFound,
 		Message: "Group not found.",
+		Key:     KeyGroupNotFound,
 	}
 }
 
@@ -208,6 +333,7 @@ func ExpenseNotFound() *AppError {
 		Type:    ErrorTypeNotFound,
 		Code:    CodeExpenseNotFound,
 		Message: "Expense not found.",
+		Key:     KeyExpenseNotFound,
 	}
 }
 
@@ -216,6 +342,7 @@ func FriendNotFound() *AppError {
 		Type:    ErrorTypeNotFound,
 		Code:    CodeFriendNotFound,
 		Message: "Friend not found.",
+		Key:     KeyFriendNotFound,
 	}
 }
 
@@ -232,6 +359,8 @@ func DuplicateEntry(resourceType string) *AppError {
 		Type:    ErrorTypeConflict,
 		Code:    CodeDuplicateEntry,
 		Message: fmt.Sprintf("%s already exists.", resourceType),
+		Key:     KeyDuplicateEntry,
+		Args:    []interface{}{resourceType},
 	}
 }
 
@@ -240,6 +369,7 @@ func AlreadyMember() *AppError {
 		Type:    ErrorTypeConflict,
 		Code:    CodeAlreadyMember,
 		Message: "User is already a member of this group.",
+		Key:     KeyAlreadyMember,
 	}
 }
 
@@ -248,6 +378,7 @@ func AlreadyFriends() *AppError {
 		Type:    ErrorTypeConflict,
 		Code:    CodeAlreadyFriends,
 		Message: "You are already friends with this user.",
+		Key:     KeyAlreadyFriends,
 	}
 }
 
@@ -256,6 +387,8 @@ func CannotAddSelf(action string) *AppError {
 		Type:    ErrorTypeConflict,
 		Code:    CodeCannotSelfAction,
 		Message: fmt.Sprintf("You cannot %s yourself.", action),
+		Key:     KeyCannotSelfAction,
+		Args:    []interface{}{action},
 	}
 }
 
@@ -264,6 +397,43 @@ func CannotSettleToSelf() *AppError {
 		Type:    ErrorTypeBadRequest,
 		Code:    CodeInvalidSettlement,
 		Message: "Cannot settle payment to yourself.",
+		Key:     KeyCannotSettleToSelf,
+	}
+}
+
+func SettlementAlreadyReversed() *AppError {
+	return &AppError{
+		Type:    ErrorTypeConflict,
+		Code:    CodeInvalidSettlement,
+		Message: "This settlement has already been reversed.",
+		Key:     KeySettlementAlreadyReversed,
+	}
+}
+
+func SettlementNotReversible() *AppError {
+	return &AppError{
+		Type:    ErrorTypeBadRequest,
+		Code:    CodeInvalidSettlement,
+		Message: "Only settlements can be reversed, and a reversal cannot itself be reversed.",
+		Key:     KeySettlementNotReversible,
+	}
+}
+
+func SettlementReversalLocked() *AppError {
+	return &AppError{
+		Type:    ErrorTypeConflict,
+		Code:    CodeInvalidSettlement,
+		Message: "Reversed settlements and their reversals cannot be changed.",
+		Key:     KeySettlementReversalLocked,
+	}
+}
+
+func ExpenseSplitsLocked() *AppError {
+	return &AppError{
+		Type:    ErrorTypeConflict,
+		Code:    CodeBusinessError,
+		Message: "This expense's splits are locked. Unlock them before changing who shares it.",
+		Key:     KeyExpenseSplitsLocked,
 	}
 }
 
@@ -281,6 +451,7 @@ func CannotDeleteGroupWithDebts() *AppError {
 		Code:    CodeCannotDeleteWithDebts,
 		Message: "Cannot delete group while there are outstanding balances.",
 		Details: "Please settle all debts before deleting this group.",
+		Key:     KeyCannotDeleteGroupWithDebts,
 	}
 }
 
@@ -290,15 +461,73 @@ func CannotRemoveMemberWithBalance(balance float64) *AppError {
 		Code:    CodeCannotRemoveMemberWithBalance,
 		Message: fmt.Sprintf("Cannot remove member with outstanding balance of $%.2f.", balance),
 		Details: "This balance must be settled first.",
+		Key:     KeyCannotRemoveMemberWithBalance,
+		Args:    []interface{}{balance},
+	}
+}
+
+func ExpenseLimitExceeded(details string) *AppError {
+	return &AppError{
+		Type:    ErrorTypeUnprocessable,
+		Code:    CodeExpenseLimitExceeded,
+		Message: "This expense is above the group's limits. Resubmit with confirm_over_limit set to true if it is correct.",
+		Details: details,
+		Key:     KeyExpenseLimitExceeded,
 	}
 }
 
-func CannotDeleteAccountWithBalance() *AppError {
+func GroupQuotaExceeded(limit int) *AppError {
+	return &AppError{
+		Type:    ErrorTypeUnprocessable,
+		Code:    CodeGroupQuotaExceeded,
+		Message: fmt.Sprintf("You can be in at most %d groups.", limit),
+		Details: "Leave or delete a group you no longer use, or ask an administrator to raise your limit.",
+		Key:     KeyGroupQuotaExceeded,
+		Args:    []interface{}{limit},
+	}
+}
+
+func MemberQuotaExceeded(limit int) *AppError {
+	return &AppError{
+		Type:    ErrorTypeUnprocessable,
+		Code:    CodeMemberQuotaExceeded,
+		Message: fmt.Sprintf("A group can have at most %d members, including pending invites.", limit),
+		Key:     KeyMemberQuotaExceeded,
+		Args:    []interface{}{limit},
+	}
+}
+
+func ExpenseQuotaExceeded(limit int) *AppError {
+	return &AppError{
+		Type:    ErrorTypeUnprocessable,
+		Code:    CodeExpenseQuotaExceeded,
+		Message: fmt.Sprintf("A group can have at most %d expenses.", limit),
+		Details: "Start a new group for further expenses, or ask an administrator to raise your limit.",
+		Key:     KeyExpenseQuotaExceeded,
+		Args:    []interface{}{limit},
+	}
+}
+
+// CannotDeleteAccountWithBalance rejects an account deletion. outstanding
+// names the groups and amounts to settle; when empty the generic advice is
+// given instead.
+func CannotDeleteAccountWithBalance(outstanding string) *AppError {
+	if outstanding == "" {
+		return &AppError{
+			Type:    ErrorTypeUnprocessable,
+			Code:    CodeOutstandingBalance,
+			Message: "Cannot delete account while you have outstanding balances.",
+			Details: "Please settle all debts before deleting your account.",
+			Key:     KeyCannotDeleteAccountWithDebts,
+		}
+	}
 	return &AppError{
 		Type:    ErrorTypeUnprocessable,
 		Code:    CodeOutstandingBalance,
 		Message: "Cannot delete account while you have outstanding balances.",
-		Details: "Please settle all debts before deleting your account.",
+		Details: fmt.Sprintf("Settle these balances first: %s.", outstanding),
+		Key:     KeyAccountBalancesOutstanding,
+		Args:    []interface{}{outstanding},
 	}
 }
 
@@ -309,6 +538,7 @@ func DatabaseError(operation string, err error) *AppError {
 		Message: "A database error occurred. Please try again.",
 		Details: operation,
 		Err:     err,
+		Key:     KeyDatabaseError,
 	}
 }
 
@@ -319,6 +549,7 @@ func StorageError(operation string, err error) *AppError {
 		Message: "Failed to process file storage. Please try again.",
 		Details: operation,
 		Err:     err,
+		Key:     KeyStorageError,
 	}
 }
 
@@ -328,6 +559,18 @@ func AIServiceError(err error) *AppError {
 		Code:    CodeAIServiceError,
 		Message: "AI service is temporarily unavailable. Please try again later.",
 		Err:     err,
+		Key:     KeyAIServiceError,
+	}
+}
+
+// RequestTimeout is returned when a request runs past its route's latency
+// budget.
+func RequestTimeout() *AppError {
+	return &AppError{
+		Type:    ErrorTypeGatewayTimeout,
+		Code:    CodeRequestTimeout,
+		Message: "The request took too long to complete. Please try again.",
+		Key:     KeyRequestTimeout,
 	}
 }
 
@@ -337,6 +580,7 @@ func InternalError(err error) *AppError {
 		Code:    CodeInternalError,
 		Message: "An unexpected error occurred. Please try again.",
 		Err:     err,
+		Key:     KeyInternalError,
 	}
 }
 
@@ -374,6 +618,10 @@ func GetHTTPStatus(errType ErrorType) int {
 		return 422
 	case ErrorTypeServiceUnavailable:
 		return 503
+	case ErrorTypeGatewayTimeout:
+		return 504
+	case ErrorTypeGone:
+		return 410
 	default:
 		return 500
 	}
@@ -395,6 +643,16 @@ func IsDuplicateError(err error) bool {
 	return contains(errStr, "duplicate key") || contains(errStr, "unique constraint")
 }
 
+// IsForeignKeyError reports a foreign key violation, e.g. a row written for a
+// group or user deleted by a concurrent request.
+func IsForeignKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := err.Error()
+	return contains(errStr, "violates foreign key constraint") || contains(errStr, "SQLSTATE 23503")
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -0,0 +1,319 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type MessageKey string
+
+const (
+	KeyTokenExpired                  MessageKey = "token_expired"
+	KeyTokenInvalid                  MessageKey = "token_invalid"
+	KeyInvalidCredentials            MessageKey = "invalid_credentials"
+	KeyNotGroupMember                MessageKey = "not_group_member"
+	KeyEmailNotVerified              MessageKey = "email_not_verified"
+	KeyAccountDeleted                MessageKey = "account_deleted"
+	KeyExpenseEditNotAllowed         MessageKey = "expense_edit_not_allowed"
+	KeyExclusionResolveNotAllowed    MessageKey = "exclusion_resolve_not_allowed"
+	KeyMissingRequiredField          MessageKey = "missing_required_field"
+	KeyInvalidFieldFormat            MessageKey = "invalid_field_format"
+	KeyInvalidEmail                  MessageKey = "invalid_email"
+	KeyAmountMismatch                MessageKey = "amount_mismatch"
+	KeyNotFound                      MessageKey = "not_found"
+	KeyUserNotFound                  MessageKey = "user_not_found"
+	KeyUserNotFoundByEmail           MessageKey = "user_not_found_by_email"
+	KeyGroupNotFound                 MessageKey = "group_not_found"
+	KeyExpenseNotFound               MessageKey = "expense_not_found"
+	KeyFriendNotFound                MessageKey = "friend_not_found"
+	KeyDuplicateEntry                MessageKey = "duplicate_entry"
+	KeyAlreadyMember                 MessageKey = "already_member"
+	KeyAlreadyFriends                MessageKey = "already_friends"
+	KeyCannotSelfAction              MessageKey = "cannot_self_action"
+	KeyCannotSettleToSelf            MessageKey = "cannot_settle_to_self"
+	KeySettlementAlreadyReversed     MessageKey = "settlement_already_reversed"
+	KeySettlementNotReversible       MessageKey = "settlement_not_reversible"
+	KeySettlementReversalLocked      MessageKey = "settlement_reversal_locked"
+	KeyExpenseSplitsLocked           MessageKey = "expense_splits_locked"
+	KeyCannotDeleteGroupWithDebts    MessageKey = "cannot_delete_group_with_debts"
+	KeyCannotRemoveMemberWithBalance MessageKey = "cannot_remove_member_with_balance"
+	KeyExpenseLimitExceeded          MessageKey = "expense_limit_exceeded"
+	KeyGroupQuotaExceeded            MessageKey = "group_quota_exceeded"
+	KeyMemberQuotaExceeded           MessageKey = "member_quota_exceeded"
+	KeyExpenseQuotaExceeded          MessageKey = "expense_quota_exceeded"
+	KeyCannotDeleteAccountWithDebts  MessageKey = "cannot_delete_account_with_debts"
+	KeyAccountBalancesOutstanding    MessageKey = "account_balances_outstanding"
+	KeyDatabaseError                 MessageKey = "database_error"
+	KeyStorageError                  MessageKey = "storage_error"
+	KeyAIServiceError                MessageKey = "ai_service_error"
+	KeyRequestTimeout                MessageKey = "request_timeout"
+	KeyInternalError                 MessageKey = "internal_error"
+	KeyUnexpectedError               MessageKey = "unexpected_error"
+)
+
+const DefaultLanguage = "en"
+
+// UnexpectedErrorMessage is returned for errors that are not AppErrors.
+const UnexpectedErrorMessage = "An unexpected error occurred. Please try again later."
+
+type translation struct {
+	Message string
+	Details string
+}
+
+// catalog holds translations of keyed messages. English is not listed: the
+// constructors' Message and Details are the English text. Formats receive the
+// AppError's Args, so use explicit indexes (%[2]s) when a language reorders them.
+var catalog = map[string]map[MessageKey]translation{
+	"es": {
+		KeyTokenExpired:                  {Message: "Tu sesión ha caducado. Vuelve a iniciar sesión."},
+		KeyTokenInvalid:                  {Message: "Token de autenticación no válido."},
+		KeyInvalidCredentials:            {Message: "Correo electrónico o contraseña incorrectos."},
+		KeyNotGroupMember:                {Message: "No eres miembro de este grupo."},
+		KeyEmailNotVerified:              {Message: "Verifica primero tu dirección de correo electrónico.", Details: "Se necesita un correo verificado para %[1]s."},
+		KeyAccountDeleted:                {Message: "Esta cuenta ha sido eliminada."},
+		KeyExpenseEditNotAllowed:         {Message: "No tienes permiso para modificar este gasto.", Details: "La política de edición de gastos de este grupo es %[1]s."},
+		KeyExclusionResolveNotAllowed:    {Message: "Solo quien añadió este gasto puede resolver las solicitudes de exclusión."},
+		KeyMissingRequiredField:          {Message: "%[1]s es obligatorio."},
+		KeyInvalidFieldFormat:            {Message: "Formato no válido para %[1]s.", Details: "Formato esperado: %[2]s"},
+		KeyInvalidEmail:                  {Message: "'%[1]s' no es una dirección de correo válida."},
+		KeyAmountMismatch:                {Message: "La suma de los importes de %[1]s (%.2[2]f) no coincide con el importe total (%.2[3]f)."},
+		KeyNotFound:                      {Message: "%[1]s no encontrado."},
+		KeyUserNotFound:                  {Message: "Usuario no encontrado."},
+		KeyUserNotFoundByEmail:           {Message: "No hay ningún usuario con el correo '%[1]s'.", Details: "Comprueba la dirección o pídele que se registre primero."},
+		KeyGroupNotFound:                 {Message: "Grupo no encontrado."},
+		KeyExpenseNotFound:               {Message: "Gasto no encontrado."},
+		KeyFriendNotFound:                {Message: "Amigo no encontrado."},
+		KeyDuplicateEntry:                {Message: "%[1]s ya existe."},
+		KeyAlreadyMember:                 {Message: "El usuario ya es miembro de este grupo."},
+		KeyAlreadyFriends:                {Message: "Ya eres amigo de este usuario."},
+		KeyCannotSelfAction:              {Message: "No puedes hacer esto contigo mismo (%[1]s)."},
+		KeyCannotSettleToSelf:            {Message: "No puedes liquidar un pago contigo mismo."},
+		KeySettlementAlreadyReversed:     {Message: "Este pago ya ha sido revertido."},
+		KeySettlementNotReversible:       {Message: "Solo se pueden revertir pagos, y una reversión no puede revertirse."},
+		KeySettlementReversalLocked:      {Message: "Los pagos revertidos y sus reversiones no se pueden modificar."},
+		KeyExpenseSplitsLocked:           {Message: "El reparto de este gasto está bloqueado. Desbloquéalo antes de cambiar quién lo comparte."},
+		KeyCannotDeleteGroupWithDebts:    {Message: "No se puede eliminar el grupo mientras haya saldos pendientes.", Details: "Liquida todas las deudas antes de eliminar este grupo."},
+		KeyCannotRemoveMemberWithBalance: {Message: "No se puede quitar a un miembro con un saldo pendiente de %.2[1]f.", Details: "Este saldo debe liquidarse primero."},
+		KeyExpenseLimitExceeded:          {Message: "Este gasto supera los límites del grupo. Vuelve a enviarlo con confirm_over_limit en true si es correcto."},
+		KeyGroupQuotaExceeded:            {Message: "Puedes estar como máximo en %d grupos.", Details: "Sal o elimina un grupo que ya no uses, o pide a un administrador que aumente tu límite."},
+		KeyMemberQuotaExceeded:           {Message: "Un grupo puede tener como máximo %d miembros, incluidas las invitaciones pendientes."},
+		KeyExpenseQuotaExceeded:          {Message: "Un grupo puede tener como máximo %d gastos.", Details: "Crea un grupo nuevo para más gastos, o pide a un administrador que aumente tu límite."},
+		KeyCannotDeleteAccountWithDebts:  {Message: "No se puede eliminar la cuenta mientras tengas saldos pendientes.", Details: "Liquida todas las deudas antes de eliminar tu cuenta."},
+		KeyAccountBalancesOutstanding:    {Message: "No se puede eliminar la cuenta mientras tengas saldos pendientes.", Details: "Liquida estos saldos primero: %s."},
+		KeyDatabaseError:                 {Message: "Se produjo un error de base de datos. Inténtalo de nuevo."},
+		KeyStorageError:                  {Message: "No se pudo procesar el archivo. Inténtalo de nuevo."},
+		KeyAIServiceError:                {Message: "El servicio de IA no está disponible temporalmente. Inténtalo más tarde."},
+		KeyRequestTimeout:                {Message: "La solicitud tardó demasiado en completarse. Inténtalo de nuevo."},
+		KeyInternalError:                 {Message: "Se produjo un error inesperado. Inténtalo de nuevo."},
+		KeyUnexpectedError:               {Message: "Se produjo un error inesperado. Inténtalo más tarde."},
+	},
+	"fr": {
+		KeyTokenExpired:                  {Message: "Votre session a expiré. Veuillez vous reconnecter."},
+		KeyTokenInvalid:                  {Message: "Jeton d'authentification invalide."},
+		KeyInvalidCredentials:            {Message: "E-mail ou mot de passe incorrect."},
+		KeyNotGroupMember:                {Message: "Vous n'êtes pas membre de ce groupe."},
+		KeyEmailNotVerified:              {Message: "Veuillez d'abord vérifier votre adresse e-mail.", Details: "Une adresse e-mail vérifiée est requise pour %[1]s."},
+		KeyAccountDeleted:                {Message: "Ce compte a été supprimé."},
+		KeyExpenseEditNotAllowed:         {Message: "Vous n'êtes pas autorisé à modifier cette dépense.", Details: "La règle de modification des dépenses de ce groupe est %[1]s."},
+		KeyExclusionResolveNotAllowed:    {Message: "Seule la personne qui a ajouté cette dépense peut traiter les demandes d'exclusion."},
+		KeyMissingRequiredField:          {Message: "%[1]s est obligatoire."},
+		KeyInvalidFieldFormat:            {Message: "Format invalide pour %[1]s.", Details: "Format attendu : %[2]s"},
+		KeyInvalidEmail:                  {Message: "« %[1]s » n'est pas une adresse e-mail valide."},
+		KeyAmountMismatch:                {Message: "La somme des montants %[1]s (%.2[2]f) ne correspond pas au montant total (%.2[3]f)."},
+		KeyNotFound:                      {Message: "%[1]s introuvable."},
+		KeyUserNotFound:                  {Message: "Utilisateur introuvable."},
+		KeyUserNotFoundByEmail:           {Message: "Aucun utilisateur avec l'e-mail « %[1]s ».", Details: "Vérifiez l'adresse ou demandez-lui de s'inscrire d'abord."},
+		KeyGroupNotFound:                 {Message: "Groupe introuvable."},
+		KeyExpenseNotFound:               {Message: "Dépense introuvable."},
+		KeyFriendNotFound:                {Message: "Ami introuvable."},
+		KeyDuplicateEntry:                {Message: "%[1]s existe déjà."},
+		KeyAlreadyMember:                 {Message: "L'utilisateur est déjà membre de ce groupe."},
+		KeyAlreadyFriends:                {Message: "Vous êtes déjà ami avec cet utilisateur."},
+		KeyCannotSelfAction:              {Message: "Vous ne pouvez pas faire cela avec vous-même (%[1]s)."},
+		KeyCannotSettleToSelf:            {Message: "Impossible de vous régler un paiement à vous-même."},
+		KeySettlementAlreadyReversed:     {Message: "Ce remboursement a déjà été annulé."},
+		KeySettlementNotReversible:       {Message: "Seuls les remboursements peuvent être annulés, et une annulation ne peut pas elle-même être annulée."},
+		KeySettlementReversalLocked:      {Message: "Les remboursements annulés et leurs annulations ne peuvent pas être modifiés."},
+		KeyExpenseSplitsLocked:           {Message: "La répartition de cette dépense est verrouillée. Déverrouillez-la avant de changer qui la partage."},
+		KeyCannotDeleteGroupWithDebts:    {Message: "Impossible de supprimer le groupe tant qu'il reste des soldes.", Details: "Réglez toutes les dettes avant de supprimer ce groupe."},
+		KeyCannotRemoveMemberWithBalance: {Message: "Impossible de retirer un membre avec un solde de %.2[1]f.", Details: "Ce solde doit d'abord être réglé."},
+		KeyExpenseLimitExceeded:          {Message: "Cette dépense dépasse les limites du groupe. Renvoyez-la avec confirm_over_limit à true si elle est correcte."},
+		KeyGroupQuotaExceeded:            {Message: "Vous pouvez faire partie de %d groupes au maximum.", Details: "Quittez ou supprimez un groupe que vous n'utilisez plus, ou demandez à un administrateur d'augmenter votre limite."},
+		KeyMemberQuotaExceeded:           {Message: "Un groupe peut compter au maximum %d membres, invitations en attente comprises."},
+		KeyExpenseQuotaExceeded:          {Message: "Un groupe peut contenir au maximum %d dépenses.", Details: "Créez un nouveau groupe pour les dépenses suivantes, ou demandez à un administrateur d'augmenter votre limite."},
+		KeyCannotDeleteAccountWithDebts:  {Message: "Impossible de supprimer le compte tant que vous avez des soldes.", Details: "Réglez toutes les dettes avant de supprimer votre compte."},
+		KeyAccountBalancesOutstanding:    {Message: "Impossible de supprimer le compte tant que vous avez des soldes.", Details: "Réglez d'abord ces soldes : %s."},
+		KeyDatabaseError:                 {Message: "Une erreur de base de données s'est produite. Veuillez réessayer."},
+		KeyStorageError:                  {Message: "Le traitement du fichier a échoué. Veuillez réessayer."},
+		KeyAIServiceError:                {Message: "Le service d'IA est temporairement indisponible. Veuillez réessayer plus tard."},
+		KeyRequestTimeout:                {Message: "La requête a pris trop de temps. Veuillez réessayer."},
+		KeyInternalError:                 {Message: "Une erreur inattendue s'est produite. Veuillez réessayer."},
+		KeyUnexpectedError:               {Message: "Une erreur inattendue s'est produite. Veuillez réessayer plus tard."},
+	},
+	"de": {
+		KeyTokenExpired:                  {Message: "Deine Sitzung ist abgelaufen. Bitte melde dich erneut an."},
+		KeyTokenInvalid:                  {Message: "Ungültiges Authentifizierungstoken."},
+		KeyInvalidCredentials:            {Message: "E-Mail oder Passwort ist falsch."},
+		KeyNotGroupMember:                {Message: "Du bist kein Mitglied dieser Gruppe."},
+		KeyEmailNotVerified:              {Message: "Bitte bestätige zuerst deine E-Mail-Adresse.", Details: "Für %[1]s ist eine bestätigte E-Mail-Adresse erforderlich."},
+		KeyAccountDeleted:                {Message: "Dieses Konto wurde gelöscht."},
+		KeyExpenseEditNotAllowed:         {Message: "Du darfst diese Ausgabe nicht ändern.", Details: "Die Bearbeitungsregel für Ausgaben in dieser Gruppe ist %[1]s."},
+		KeyExclusionResolveNotAllowed:    {Message: "Nur wer diese Ausgabe hinzugefügt hat, kann Ausschlussanfragen bearbeiten."},
+		KeyMissingRequiredField:          {Message: "%[1]s ist erforderlich."},
+		KeyInvalidFieldFormat:            {Message: "Ungültiges Format für %[1]s.", Details: "Erwartetes Format: %[2]s"},
+		KeyInvalidEmail:                  {Message: "„%[1]s“ ist keine gültige E-Mail-Adresse."},
+		KeyAmountMismatch:                {Message: "Die Summe der %[1]s-Beträge (%.2[2]f) entspricht nicht dem Gesamtbetrag (%.2[3]f)."},
+		KeyNotFound:                      {Message: "%[1]s nicht gefunden."},
+		KeyUserNotFound:                  {Message: "Benutzer nicht gefunden."},
+		KeyUserNotFoundByEmail:           {Message: "Kein Benutzer mit der E-Mail „%[1]s“ gefunden.", Details: "Prüfe die Adresse oder bitte die Person, sich zuerst zu registrieren."},
+		KeyGroupNotFound:                 {Message: "Gruppe nicht gefunden."},
+		KeyExpenseNotFound:               {Message: "Ausgabe nicht gefunden."},
+		KeyFriendNotFound:                {Message: "Freund nicht gefunden."},
+		KeyDuplicateEntry:                {Message: "%[1]s existiert bereits."},
+		KeyAlreadyMember:                 {Message: "Der Benutzer ist bereits Mitglied dieser Gruppe."},
+		KeyAlreadyFriends:                {Message: "Ihr seid bereits befreundet."},
+		KeyCannotSelfAction:              {Message: "Das kannst du nicht mit dir selbst tun (%[1]s)."},
+		KeyCannotSettleToSelf:            {Message: "Du kannst keine Zahlung an dich selbst ausgleichen."},
+		KeySettlementAlreadyReversed:     {Message: "Dieser Ausgleich wurde bereits storniert."},
+		KeySettlementNotReversible:       {Message: "Nur Ausgleichszahlungen können storniert werden, und eine Stornierung kann nicht selbst storniert werden."},
+		KeySettlementReversalLocked:      {Message: "Stornierte Ausgleichszahlungen und ihre Stornierungen können nicht geändert werden."},
+		KeyExpenseSplitsLocked:           {Message: "Die Aufteilung dieser Ausgabe ist gesperrt. Entsperre sie, bevor du änderst, wer sie teilt."},
+		KeyCannotDeleteGroupWithDebts:    {Message: "Die Gruppe kann nicht gelöscht werden, solange offene Salden bestehen.", Details: "Bitte gleiche alle Schulden aus, bevor du diese Gruppe löschst."},
+		KeyCannotRemoveMemberWithBalance: {Message: "Ein Mitglied mit offenem Saldo von %.2[1]f kann nicht entfernt werden.", Details: "Dieser Saldo muss zuerst ausgeglichen werden."},
+		KeyExpenseLimitExceeded:          {Message: "Diese Ausgabe überschreitet die Limits der Gruppe. Sende sie mit confirm_over_limit auf true erneut, wenn sie korrekt ist."},
+		KeyGroupQuotaExceeded:            {Message: "Du kannst höchstens %d Gruppen angehören.", Details: "Verlasse oder lösche eine Gruppe, die du nicht mehr nutzt, oder bitte einen Administrator, dein Limit zu erhöhen."},
+		KeyMemberQuotaExceeded:           {Message: "Eine Gruppe kann höchstens %d Mitglieder haben, offene Einladungen eingeschlossen."},
+		KeyExpenseQuotaExceeded:          {Message: "Eine Gruppe kann höchstens %d Ausgaben haben.", Details: "Lege für weitere Ausgaben eine neue Gruppe an oder bitte einen Administrator, dein Limit zu erhöhen."},
+		KeyCannotDeleteAccountWithDebts:  {Message: "Das Konto kann nicht gelöscht werden, solange du offene Salden hast.", Details: "Bitte gleiche alle Schulden aus, bevor du dein Konto löschst."},
+		KeyAccountBalancesOutstanding:    {Message: "Das Konto kann nicht gelöscht werden, solange du offene Salden hast.", Details: "Gleiche zuerst diese Salden aus: %s."},
+		KeyDatabaseError:                 {Message: "Ein Datenbankfehler ist aufgetreten. Bitte versuche es erneut."},
+		KeyStorageError:                  {Message: "Die Datei konnte nicht verarbeitet werden. Bitte versuche es erneut."},
+		KeyAIServiceError:                {Message: "Der KI-Dienst ist vorübergehend nicht verfügbar. Bitte versuche es später erneut."},
+		KeyRequestTimeout:                {Message: "Die Anfrage hat zu lange gedauert. Bitte versuche es erneut."},
+		KeyInternalError:                 {Message: "Ein unerwarteter Fehler ist aufgetreten. Bitte versuche es erneut."},
+		KeyUnexpectedError:               {Message: "Ein unerwarteter Fehler ist aufgetreten. Bitte versuche es später erneut."},
+	},
+	"hi": {
+		KeyTokenExpired:                  {Message: "आपका सत्र समाप्त हो गया है। कृपया फिर से लॉग इन करें।"},
+		KeyTokenInvalid:                  {Message: "अमान्य प्रमाणीकरण टोकन।"},
+		KeyInvalidCredentials:            {Message: "ईमेल या पासवर्ड गलत है।"},
+		KeyNotGroupMember:                {Message: "आप इस समूह के सदस्य नहीं हैं।"},
+		KeyEmailNotVerified:              {Message: "कृपया पहले अपना ईमेल पता सत्यापित करें।", Details: "%[1]s के लिए सत्यापित ईमेल आवश्यक है।"},
+		KeyAccountDeleted:                {Message: "यह खाता हटा दिया गया है।"},
+		KeyExpenseEditNotAllowed:         {Message: "आपको इस खर्च को बदलने की अनुमति नहीं है।", Details: "इस समूह की खर्च संपादन नीति %[1]s है।"},
+		KeyExclusionResolveNotAllowed:    {Message: "केवल इस खर्च को जोड़ने वाला व्यक्ति ही बाहर करने के अनुरोधों का निपटारा कर सकता है।"},
+		KeyMissingRequiredField:          {Message: "%[1]s आवश्यक है।"},
+		KeyInvalidFieldFormat:            {Message: "%[1]s का प्रारूप अमान्य है।", Details: "अपेक्षित प्रारूप: %[2]s"},
+		KeyInvalidEmail:                  {Message: "'%[1]s' मान्य ईमेल पता नहीं है।"},
+		KeyAmountMismatch:                {Message: "%[1]s राशियों का योग (%.2[2]f) कुल राशि (%.2[3]f) के बराबर नहीं है।"},
+		KeyNotFound:                      {Message: "%[1]s नहीं मिला।"},
+		KeyUserNotFound:                  {Message: "उपयोगकर्ता नहीं मिला।"},
+		KeyUserNotFoundByEmail:           {Message: "ईमेल '%[1]s' वाला कोई उपयोगकर्ता नहीं मिला।", Details: "कृपया ईमेल पता जाँचें या उन्हें पहले साइन अप करने को कहें।"},
+		KeyGroupNotFound:                 {Message: "समूह नहीं मिला।"},
+		KeyExpenseNotFound:               {Message: "खर्च नहीं मिला।"},
+		KeyFriendNotFound:                {Message: "मित्र नहीं मिला।"},
+		KeyDuplicateEntry:                {Message: "%[1]s पहले से मौजूद है।"},
+		KeyAlreadyMember:                 {Message: "उपयोगकर्ता पहले से इस समूह का सदस्य है।"},
+		KeyAlreadyFriends:                {Message: "आप पहले से इस उपयोगकर्ता के मित्र हैं।"},
+		KeyCannotSelfAction:              {Message: "आप यह स्वयं के साथ नहीं कर सकते (%[1]s)।"},
+		KeyCannotSettleToSelf:            {Message: "आप स्वयं को भुगतान का निपटान नहीं कर सकते।"},
+		KeySettlementAlreadyReversed:     {Message: "इस निपटान को पहले ही उलटा जा चुका है।"},
+		KeySettlementNotReversible:       {Message: "केवल निपटान को उलटा जा सकता है, और किसी उलटाव को फिर से उलटा नहीं जा सकता।"},
+		KeySettlementReversalLocked:      {Message: "उलटे गए निपटान और उनके उलटाव बदले नहीं जा सकते।"},
+		KeyExpenseSplitsLocked:           {Message: "इस खर्च का बँटवारा लॉक है। इसे कौन साझा करता है यह बदलने से पहले इसे अनलॉक करें।"},
+		KeyCannotDeleteGroupWithDebts:    {Message: "बकाया शेष रहते समूह को हटाया नहीं जा सकता।", Details: "कृपया समूह हटाने से पहले सभी कर्ज़ चुकाएँ।"},
+		KeyCannotRemoveMemberWithBalance: {Message: "%.2[1]f के बकाया शेष वाले सदस्य को हटाया नहीं जा सकता।", Details: "पहले यह शेष चुकाना होगा।"},
+		KeyExpenseLimitExceeded:          {Message: "यह खर्च समूह की सीमा से अधिक है। यदि यह सही है तो confirm_over_limit को true करके फिर से भेजें।"},
+		KeyGroupQuotaExceeded:            {Message: "आप अधिकतम %d समूहों में हो सकते हैं।", Details: "कोई ऐसा समूह छोड़ें या हटाएँ जिसका अब उपयोग नहीं करते, या किसी व्यवस्थापक से अपनी सीमा बढ़ाने को कहें।"},
+		KeyMemberQuotaExceeded:           {Message: "एक समूह में लंबित आमंत्रणों सहित अधिकतम %d सदस्य हो सकते हैं।"},
+		KeyExpenseQuotaExceeded:          {Message: "एक समूह में अधिकतम %d खर्च हो सकते हैं।", Details: "आगे के खर्चों के लिए नया समूह बनाएँ, या किसी व्यवस्थापक से अपनी सीमा बढ़ाने को कहें।"},
+		KeyCannotDeleteAccountWithDebts:  {Message: "बकाया शेष रहते खाता हटाया नहीं जा सकता।", Details: "कृपया खाता हटाने से पहले सभी कर्ज़ चुकाएँ।"},
+		KeyAccountBalancesOutstanding:    {Message: "बकाया शेष रहते खाता हटाया नहीं जा सकता।", Details: "पहले ये शेष चुकाएँ: %s।"},
+		KeyDatabaseError:                 {Message: "डेटाबेस त्रुटि हुई। कृपया फिर से प्रयास करें।"},
+		KeyStorageError:                  {Message: "फ़ाइल संसाधित नहीं हो सकी। कृपया फिर से प्रयास करें।"},
+		KeyAIServiceError:                {Message: "AI सेवा अस्थायी रूप से अनुपलब्ध है। कृपया बाद में प्रयास करें।"},
+		KeyRequestTimeout:                {Message: "अनुरोध पूरा होने में बहुत अधिक समय लगा। कृपया पुनः प्रयास करें।"},
+		KeyInternalError:                 {Message: "एक अनपेक्षित त्रुटि हुई। कृपया फिर से प्रयास करें।"},
+		KeyUnexpectedError:               {Message: "एक अनपेक्षित त्रुटि हुई। कृपया बाद में प्रयास करें।"},
+	},
+}
+
+// Localize renders the error's message and details in lang, falling back to
+// the English text when the error has no key or the language has no entry.
+func (e *AppError) Localize(lang string) (message, details string) {
+	message, details = e.Message, e.Details
+	if e.Key == "" {
+		return message, details
+	}
+	t, ok := catalog[lang][e.Key]
+	if !ok {
+		return message, details
+	}
+	message = render(t.Message, e.Args)
+	if t.Details != "" {
+		details = render(t.Details, e.Args)
+	}
+	return message, details
+}
+
+// Translate renders a keyed message without an AppError, using fallback for
+// English or unsupported languages.
+func Translate(lang string, key MessageKey, fallback string, args ...interface{}) string {
+	if t, ok := catalog[lang][key]; ok {
+		return render(t.Message, args)
+	}
+	return fallback
+}
+
+// render only formats text that has verbs, so entries that ignore the error's
+// arguments don't pick up %!(EXTRA ...) noise.
+func render(format string, args []interface{}) string {
+	if !strings.Contains(format, "%") {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// MatchLanguage picks the supported language with the highest q-value from
+// an Accept-Language header, or DefaultLanguage.
+func MatchLanguage(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for i, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if base == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		// Earlier entries win ties, as the header order expresses preference.
+		candidates = append(candidates, candidate{lang: base, q: q - float64(i)*1e-6})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.q <= 0 {
+			break
+		}
+		if c.lang == DefaultLanguage {
+			return DefaultLanguage
+		}
+		if _, ok := catalog[c.lang]; ok {
+			return c.lang
+		}
+	}
+	return DefaultLanguage
+}
@@ -0,0 +1,176 @@
+package errors
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestMatchLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "en"},
+		{"fr", "fr"},
+		{"de-DE,de;q=0.9,en;q=0.8", "de"},
+		{"en-US,en;q=0.9,es;q=0.8", "en"},
+		{"es;q=0.5, hi;q=0.8", "hi"},
+		{"ja, fr;q=0.3", "fr"},
+		{"ja, zh;q=0.9", "en"},
+		{"fr;q=0, de;q=0.1", "de"},
+		{"fr;q=0", "en"},
+		{"es, fr", "es"},
+		{"fr;q=abc, de;q=0.2", "de"},
+		{"HI-in", "hi"},
+	}
+
+	for _, tt := range tests {
+		if got := MatchLanguage(tt.header); got != tt.expected {
+			t.Errorf("MatchLanguage(%q) = %q, expected %q", tt.header, got, tt.expected)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             *AppError
+		lang            string
+		expectedMessage string
+		expectedDetails string
+	}{
+		{
+			name:            "English Uses Constructor Text",
+			err:             InvalidFieldFormat("date", "YYYY-MM-DD"),
+			lang:            "en",
+			expectedMessage: "Invalid format for date.",
+			expectedDetails: "Expected format: YYYY-MM-DD",
+		},
+		{
+			name:            "Translated With Args",
+			err:             InvalidFieldFormat("date", "YYYY-MM-DD"),
+			lang:            "es",
+			expectedMessage: "Formato no válido para date.",
+			expectedDetails: "Formato esperado: YYYY-MM-DD",
+		},
+		{
+			name:            "Unsupported Language",
+			err:             NotGroupMember(),
+			lang:            "ja",
+			expectedMessage: NotGroupMember().Message,
+			expectedDetails: NotGroupMember().Details,
+		},
+		{
+			name:            "No Key",
+			err:             InvalidRequest("Bad input."),
+			lang:            "de",
+			expectedMessage: "Bad input.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, details := tt.err.Localize(tt.lang)
+			if message != tt.expectedMessage || details != tt.expectedDetails {
+				t.Errorf("Localize(%q) = %q, %q, expected %q, %q", tt.lang, message, details, tt.expectedMessage, tt.expectedDetails)
+			}
+		})
+	}
+
+	if got := Translate("ja", KeyNotGroupMember, "fallback"); got != "fallback" {
+		t.Errorf("Translate(ja) = %q, expected the fallback", got)
+	}
+	if got := Translate("fr", KeyMissingRequiredField, "fallback", "name"); got != "name est obligatoire." {
+		t.Errorf("Translate(fr) = %q, expected the French message", got)
+	}
+}
+
+var verbPattern = regexp.MustCompile(`%[-+# 0]*(?:\[(\d+)\])?\d*(?:\.\d+)?(?:\[(\d+)\])?([a-zA-Z%])`)
+
+// placeholders lists a format's verbs by argument index, so "%s" and "%[1]s"
+// compare equal.
+func placeholders(format string) []string {
+	var verbs []string
+	next := 1
+	for _, m := range verbPattern.FindAllStringSubmatch(format, -1) {
+		if m[3] == "%" {
+			continue
+		}
+		index := next
+		for _, explicit := range m[1:3] {
+			if explicit != "" {
+				index, _ = strconv.Atoi(explicit)
+			}
+		}
+		next = index + 1
+		verbs = append(verbs, strconv.Itoa(index)+m[3])
+	}
+	sort.Strings(verbs)
+	return verbs
+}
+
+func declaredMessageKeys(t *testing.T) []MessageKey {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "i18n.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parsing i18n.go: %v", err)
+	}
+
+	var keys []MessageKey
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != "MessageKey" {
+				continue
+			}
+			lit := value.Values[0].(*ast.BasicLit)
+			key, _ := strconv.Unquote(lit.Value)
+			keys = append(keys, MessageKey(key))
+		}
+	}
+	return keys
+}
+
+func TestCatalogCoversEveryKeyWithSamePlaceholders(t *testing.T) {
+	keys := declaredMessageKeys(t)
+	if len(keys) == 0 {
+		t.Fatal("found no MessageKey constants in i18n.go")
+	}
+	languages := []string{"es", "fr", "de", "hi"}
+	if len(catalog) != len(languages) {
+		t.Errorf("catalog has %d languages, expected %v", len(catalog), languages)
+	}
+
+	for _, key := range keys {
+		reference := catalog[languages[0]][key]
+		for _, lang := range languages {
+			entry, ok := catalog[lang][key]
+			if !ok {
+				t.Errorf("%s has no translation for %s", lang, key)
+				continue
+			}
+			if entry.Message == "" {
+				t.Errorf("%s translation of %s has an empty message", lang, key)
+			}
+			if (entry.Details == "") != (reference.Details == "") {
+				t.Errorf("%s translation of %s: details present = %v, but %v in %s", lang, key, entry.Details != "", reference.Details != "", languages[0])
+			}
+			if got, want := placeholders(entry.Message), placeholders(reference.Message); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s message for %s has placeholders %v, %s has %v", lang, key, got, languages[0], want)
+			}
+			if got, want := placeholders(entry.Details), placeholders(reference.Details); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s details for %s have placeholders %v, %s has %v", lang, key, got, languages[0], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/middleware"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminHandlers struct {
+	integrityService services.IntegrityService
+	userService      services.UserService
+	aiAuditService   services.AIAuditService
+	quotaService     services.QuotaService
+}
+
+func NewAdminHandlers(integrityService services.IntegrityService, userService services.UserService, aiAuditService services.AIAuditService, quotaService services.QuotaService) *AdminHandlers {
+	return &AdminHandlers{
+		integrityService: integrityService,
+		userService:      userService,
+		aiAuditService:   aiAuditService,
+		quotaService:     quotaService,
+	}
+}
+
+func (h *AdminHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/integrity/orphans", h.GetOrphanReport)
+	r.Get("/placeholder-claims", h.GetPendingPlaceholderClaims)
+	r.Post("/placeholder-claims/{requestID}/approve", h.ApprovePlaceholderClaim)
+	r.Post("/placeholder-claims/{requestID}/reject", h.RejectPlaceholderClaim)
+	r.Post("/placeholders/{placeholderID}/assign", h.AssignPlaceholder)
+	r.Get("/ai/stats", h.GetAIStats)
+	r.Get("/timeouts", h.GetTimeoutStats)
+	r.Get("/users/{userID}/limits", h.GetUserLimits)
+	r.Put("/users/{userID}/quota-override", h.SetQuotaOverride)
+}
+
+func (h *AdminHandlers) GetOrphanReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.integrityService.GetOrphanReport(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+func (h *AdminHandlers) GetPendingPlaceholderClaims(w http.ResponseWriter, r *http.Request) {
+	requests, err := h.userService.GetPendingPlaceholderClaims(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, requests)
+}
+
+func (h *AdminHandlers) ApprovePlaceholderClaim(w http.ResponseWriter, r *http.Request) {
+	adminID, requestID, err := parseClaimDecision(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.userService.ApprovePlaceholderClaim(r.Context(), adminID, requestID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Claim approved. Expenses have been transferred."})
+}
+
+func (h *AdminHandlers) RejectPlaceholderClaim(w http.ResponseWriter, r *http.Request) {
+	adminID, requestID, err := parseClaimDecision(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.userService.RejectPlaceholderClaim(r.Context(), adminID, requestID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Claim rejected."})
+}
+
+type AssignPlaceholderRequest struct {
+	UserID string `json:"user_id"`
+}
+
+func (h *AdminHandlers) AssignPlaceholder(w http.ResponseWriter, r *http.Request) {
+	placeholderID, err := pathID(r, "placeholderID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req AssignPlaceholderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid JSON"))
+		return
+	}
+
+	if req.UserID, err = parseID(req.UserID, "user_id"); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.userService.AssignPlaceholder(r.Context(), placeholderID, req.UserID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Placeholder assigned successfully.",
+	})
+}
+
+func parseClaimDecision(r *http.Request) (string, string, error) {
+	adminID, err := getUserID(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	requestID, err := pathID(r, "requestID")
+	if err != nil {
+		return "", "", err
+	}
+	return adminID, requestID, nil
+}
+
+func (h *AdminHandlers) GetAIStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.aiAuditService.GetStats(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+// GetTimeoutStats reports requests that ran out of their latency budget,
+// per route family, since this instance started.
+func (h *AdminHandlers) GetTimeoutStats(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"timeouts": middleware.TimeoutCounts(),
+	})
+}
+
+func (h *AdminHandlers) GetUserLimits(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "userID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	limits, err := h.quotaService.GetUserLimits(r.Context(), userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limits)
+}
+
+type SetQuotaOverrideRequest struct {
+	Exempt *bool `json:"exempt"`
+}
+
+// SetQuotaOverride exempts a user from the group, member and expense quotas,
+// or lifts the exemption.
+func (h *AdminHandlers) SetQuotaOverride(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "userID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req SetQuotaOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.Exempt == nil {
+		handleError(w, r, apperrors.MissingRequiredField("exempt"))
+		return
+	}
+
+	if err := h.quotaService.SetExempt(r.Context(), userID, *req.Exempt); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	limits, err := h.quotaService.GetUserLimits(r.Context(), userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limits)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AIFeedbackHandlers struct {
+	aiAuditService services.AIAuditService
+}
+
+func NewAIFeedbackHandlers(aiAuditService services.AIAuditService) *AIFeedbackHandlers {
+	return &AIFeedbackHandlers{
+		aiAuditService: aiAuditService,
+	}
+}
+
+type AIFeedbackRequest struct {
+	Rating  string  `json:"rating"`
+	Comment *string `json:"comment"`
+}
+
+func (h *AIFeedbackHandlers) RegisterRoutes(r chi.Router) {
+	r.Post("/ai/outputs/{outputID}/feedback", h.SubmitFeedback)
+}
+
+func (h *AIFeedbackHandlers) SubmitFeedback(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	outputID, err := pathID(r, "outputID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req AIFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if strings.TrimSpace(req.Rating) == "" {
+		handleError(w, r, apperrors.MissingRequiredField("rating"))
+		return
+	}
+
+	rating := models.AIFeedbackRating(strings.ToUpper(strings.TrimSpace(req.Rating)))
+	feedback, err := h.aiAuditService.SubmitFeedback(r.Context(), outputID, userID, rating, req.Comment)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, feedback)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AnnouncementHandlers serves announcements to users and lets admins manage
+// them. RegisterAdminRoutes goes under /api/admin.
+type AnnouncementHandlers struct {
+	announcementService services.AnnouncementService
+}
+
+func NewAnnouncementHandlers(announcementService services.AnnouncementService) *AnnouncementHandlers {
+	return &AnnouncementHandlers{
+		announcementService: announcementService,
+	}
+}
+
+func (h *AnnouncementHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/announcements", h.GetAnnouncements)
+	r.Post("/announcements/read", h.MarkAllAnnouncementsRead)
+	r.Post("/announcements/{announcementID}/read", h.MarkAnnouncementRead)
+}
+
+func (h *AnnouncementHandlers) RegisterAdminRoutes(r chi.Router) {
+	r.Get("/announcements", h.GetAllAnnouncements)
+	r.Post("/announcements", h.CreateAnnouncement)
+	r.Delete("/announcements/{announcementID}", h.DeleteAnnouncement)
+}
+
+type CreateAnnouncementRequest struct {
+	Kind     string     `json:"kind"`
+	Title    string     `json:"title"`
+	Body     string     `json:"body"`
+	LinkURL  *string    `json:"link_url"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+func (h *AnnouncementHandlers) GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	unreadOnly := false
+	if value := r.URL.Query().Get("unread"); value != "" {
+		if unreadOnly, err = strconv.ParseBool(value); err != nil {
+			handleError(w, r, apperrors.InvalidRequest("unread must be true or false."))
+			return
+		}
+	}
+
+	feed, err := h.announcementService.GetFeed(r.Context(), userID, unreadOnly)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, feed)
+}
+
+func (h *AnnouncementHandlers) MarkAnnouncementRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	announcementID, err := pathID(r, "announcementID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.announcementService.MarkRead(r.Context(), userID, announcementID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Announcement marked as read"})
+}
+
+func (h *AnnouncementHandlers) MarkAllAnnouncementsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.announcementService.MarkAllRead(r.Context(), userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "All announcements marked as read"})
+}
+
+func (h *AnnouncementHandlers) GetAllAnnouncements(w http.ResponseWriter, r *http.Request) {
+	announcements, err := h.announcementService.GetAll(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, announcements)
+}
+
+func (h *AnnouncementHandlers) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	adminID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req CreateAnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	announcement := &models.Announcement{
+		Kind:    models.AnnouncementKind(req.Kind),
+		Title:   req.Title,
+		Body:    req.Body,
+		LinkURL: req.LinkURL,
+		EndsAt:  req.EndsAt,
+	}
+	if req.StartsAt != nil {
+		announcement.StartsAt = *req.StartsAt
+	}
+
+	created, err := h.announcementService.Create(r.Context(), adminID, announcement)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (h *AnnouncementHandlers) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := pathID(r, "announcementID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.announcementService.Delete(r.Context(), announcementID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Announcement deleted"})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type AuthHandlers struct {
+	authService services.AuthService
+}
+
+func NewAuthHandlers(authService services.AuthService) *AuthHandlers {
+	return &AuthHandlers{
+		authService: authService,
+	}
+}
+
+func (h *AuthHandlers) RegisterRoutes(r chi.Router) {
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/register", h.Register)
+		r.Post("/login", h.Login)
+		r.Post("/refresh", h.Refresh)
+	})
+}
+
+func (h *AuthHandlers) Register(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.Email == "" {
+		handleError(w, r, apperrors.MissingRequiredField("Email"))
+		return
+	}
+	if req.Password == "" {
+		handleError(w, r, apperrors.MissingRequiredField("Password"))
+		return
+	}
+
+	tokens, err := h.authService.Register(r.Context(), req.Email, req.Password, req.Name)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, tokens)
+}
+
+func (h *AuthHandlers) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.Email == "" || req.Password == "" {
+		handleError(w, r, apperrors.InvalidCredentials())
+		return
+	}
+
+	tokens, err := h.authService.Login(r.Context(), req.Email, req.Password)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tokens)
+}
+
+func (h *AuthHandlers) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.RefreshToken == "" {
+		handleError(w, r, apperrors.MissingRequiredField("Refresh token"))
+		return
+	}
+
+	tokens, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tokens)
+}
@@ -8,20 +8,26 @@ import (
 
 	apperrors "unwise-backend/errors"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 func (h *Handlers) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
+	if verified := getEmailVerified(r); verified != nil {
+		if err := h.userService.RefreshEmailVerified(r.Context(), userID, *verified); err != nil {
+			handleError(w, r, err)
+			return
+		}
+	}
+
 	user, err := h.userService.GetUser(r.Context(), userID)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -31,20 +37,20 @@ func (h *Handlers) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) UploadUserAvatar(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
 		log.Printf("[UploadUserAvatar] Failed to parse multipart form: %v", err)
-		handleError(w, apperrors.InvalidRequest("Failed to parse multipart form. Maximum file size is 10MB."))
+		handleError(w, r, apperrors.InvalidRequest("Failed to parse multipart form. Maximum file size is 10MB."))
 		return
 	}
 
 	file, header, err := r.FormFile("avatar")
 	if err != nil {
 		log.Printf("[UploadUserAvatar] Failed to get avatar file: %v", err)
-		handleError(w, apperrors.MissingRequiredField("Avatar image"))
+		handleError(w, r, apperrors.MissingRequiredField("Avatar image"))
 		return
 	}
 	defer file.Close()
@@ -55,7 +61,7 @@ func (h *Handlers) UploadUserAvatar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/webp" && contentType != "image/gif" {
-		handleError(w, apperrors.InvalidRequest("Invalid image format. Supported formats: JPEG, PNG, WebP, GIF."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid image format. Supported formats: JPEG, PNG, WebP, GIF."))
 		return
 	}
 
@@ -64,13 +70,13 @@ func (h *Handlers) UploadUserAvatar(w http.ResponseWriter, r *http.Request) {
 	avatarURL, err := h.storageService.Upload(r.Context(), h.userAvatarsBucket, filename, file, contentType)
 	if err != nil {
 		log.Printf("[UploadUserAvatar] Failed to upload avatar: %v", err)
-		handleError(w, apperrors.StorageError("uploading avatar", err))
+		handleError(w, r, apperrors.StorageError("uploading avatar", err))
 		return
 	}
 
 	user, err := h.userService.UpdateAvatar(r.Context(), userID, avatarURL)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -80,26 +86,26 @@ func (h *Handlers) UploadUserAvatar(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) UploadGroupAvatar(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
 		log.Printf("[UploadGroupAvatar] Failed to parse multipart form: %v", err)
-		handleError(w, apperrors.InvalidRequest("Failed to parse multipart form. Maximum file size is 10MB."))
+		handleError(w, r, apperrors.InvalidRequest("Failed to parse multipart form. Maximum file size is 10MB."))
 		return
 	}
 
 	file, header, err := r.FormFile("avatar")
 	if err != nil {
 		log.Printf("[UploadGroupAvatar] Failed to get avatar file: %v", err)
-		handleError(w, apperrors.MissingRequiredField("Avatar image"))
+		handleError(w, r, apperrors.MissingRequiredField("Avatar image"))
 		return
 	}
 	defer file.Close()
@@ -110,7 +116,7 @@ func (h *Handlers) UploadGroupAvatar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/webp" && contentType != "image/gif" {
-		handleError(w, apperrors.InvalidRequest("Invalid image format. Supported formats: JPEG, PNG, WebP, GIF."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid image format. Supported formats: JPEG, PNG, WebP, GIF."))
 		return
 	}
 
@@ -119,13 +125,13 @@ func (h *Handlers) UploadGroupAvatar(w http.ResponseWriter, r *http.Request) {
 	avatarURL, err := h.storageService.Upload(r.Context(), h.groupPhotosBucket, filename, file, contentType)
 	if err != nil {
 		log.Printf("[UploadGroupAvatar] Failed to upload avatar: %v", err)
-		handleError(w, apperrors.StorageError("uploading group avatar", err))
+		handleError(w, r, apperrors.StorageError("uploading group avatar", err))
 		return
 	}
 
 	group, err := h.groupService.UpdateGroupAvatar(r.Context(), groupID, userID, avatarURL)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -135,13 +141,13 @@ func (h *Handlers) UploadGroupAvatar(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) GetClaimablePlaceholders(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	placeholders, err := h.userService.GetClaimablePlaceholders(r.Context(), userID)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -151,18 +157,32 @@ func (h *Handlers) GetClaimablePlaceholders(w http.ResponseWriter, r *http.Reque
 func (h *Handlers) ClaimPlaceholder(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
+		return
+	}
+
+	placeholderID, err := pathID(r, "placeholderID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	placeholderID := chi.URLParam(r, "placeholderID")
-	if _, err := uuid.Parse(placeholderID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Placeholder ID format."))
+	if err := h.userService.RequireVerifiedEmail(r.Context(), userID, getEmailVerified(r), "claiming placeholders"); err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	if err := h.userService.ClaimPlaceholder(r.Context(), userID, placeholderID); err != nil {
-		handleError(w, err)
+	claimRequest, err := h.userService.ClaimPlaceholder(r.Context(), userID, placeholderID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	if claimRequest != nil {
+		respondJSON(w, http.StatusAccepted, map[string]interface{}{
+			"success": true,
+			"message": "Claim submitted. Expenses will be transferred once an admin approves it.",
+			"request": claimRequest,
+		})
 		return
 	}
 
@@ -172,40 +192,69 @@ func (h *Handlers) ClaimPlaceholder(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-type AssignPlaceholderRequest struct {
-	UserID string `json:"user_id"`
+func (h *Handlers) GetPlaceholderSuggestions(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	suggestions, err := h.userService.GetPlaceholderSuggestions(r.Context(), userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, suggestions)
+}
+
+type MergePlaceholdersRequest struct {
+	PlaceholderIDs []string `json:"placeholder_ids"`
+	TargetID       string   `json:"target_id"`
 }
 
-func (h *Handlers) AssignPlaceholder(w http.ResponseWriter, r *http.Request) {
-	placeholderID := chi.URLParam(r, "placeholderID")
-	if _, err := uuid.Parse(placeholderID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Placeholder ID format."))
+func (h *Handlers) MergePlaceholders(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	var req AssignPlaceholderRequest
+	var req MergePlaceholdersRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid JSON"))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
-	if req.UserID == "" {
-		handleError(w, apperrors.MissingRequiredField("user_id"))
+	if req.TargetID, err = parseID(req.TargetID, "target_id"); err != nil {
+		handleError(w, r, err)
 		return
 	}
+	if len(req.PlaceholderIDs) == 0 {
+		handleError(w, r, apperrors.MissingRequiredField("placeholder_ids"))
+		return
+	}
+	for i, id := range req.PlaceholderIDs {
+		if req.PlaceholderIDs[i], err = parseID(id, "User ID"); err != nil {
+			handleError(w, r, err)
+			return
+		}
+	}
 
-	if _, err := uuid.Parse(req.UserID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid User ID format."))
+	if err := h.userService.RequireVerifiedEmail(r.Context(), userID, getEmailVerified(r), "claiming placeholders"); err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	if err := h.userService.AssignPlaceholder(r.Context(), placeholderID, req.UserID); err != nil {
-		handleError(w, err)
+	result, err := h.userService.MergePlaceholders(r.Context(), userID, req.PlaceholderIDs, req.TargetID)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"message": "Placeholder assigned successfully.",
-	})
+	status := http.StatusOK
+	if len(result.PendingClaims) > 0 {
+		status = http.StatusAccepted
+	}
+	respondJSON(w, status, result)
 }
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type BalanceEventHandlers struct {
+	balanceEventService services.BalanceEventService
+}
+
+func NewBalanceEventHandlers(balanceEventService services.BalanceEventService) *BalanceEventHandlers {
+	return &BalanceEventHandlers{
+		balanceEventService: balanceEventService,
+	}
+}
+
+func (h *BalanceEventHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/groups/{groupID}/balance-events/{userID}", h.GetMemberBalanceEvents)
+}
+
+func (h *BalanceEventHandlers) GetMemberBalanceEvents(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	userID, err := pathID(r, "userID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	audit, err := h.balanceEventService.GetMemberEvents(r.Context(), groupID, requesterID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, audit)
+}
@@ -7,7 +7,6 @@ import (
 
 	apperrors "unwise-backend/errors"
 
-	"github.com/go-chi/chi/v5"
 )
 
 type CreateCommentRequest struct {
@@ -21,19 +20,19 @@ type ReactionRequest struct {
 func (h *Handlers) GetComments(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	expenseID := chi.URLParam(r, "expenseID")
-	if expenseID == "" {
-		handleError(w, apperrors.MissingRequiredField("Expense ID"))
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	comments, err := h.commentService.GetComments(r.Context(), expenseID, userID)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -43,30 +42,30 @@ func (h *Handlers) GetComments(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) CreateComment(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	expenseID := chi.URLParam(r, "expenseID")
-	if expenseID == "" {
-		handleError(w, apperrors.MissingRequiredField("Expense ID"))
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	var req CreateCommentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid JSON"))
+		handleError(w, r, apperrors.InvalidRequest("Invalid JSON"))
 		return
 	}
 
 	if strings.TrimSpace(req.Text) == "" {
-		handleError(w, apperrors.MissingRequiredField("Text"))
+		handleError(w, r, apperrors.MissingRequiredField("Text"))
 		return
 	}
 
 	comment, err := h.commentService.AddComment(r.Context(), expenseID, userID, req.Text)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -76,18 +75,18 @@ func (h *Handlers) CreateComment(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	commentID := chi.URLParam(r, "commentID")
-	if commentID == "" {
-		handleError(w, apperrors.MissingRequiredField("Comment ID"))
+	commentID, err := pathID(r, "commentID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	if err := h.commentService.DeleteComment(r.Context(), commentID, userID); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -97,24 +96,29 @@ func (h *Handlers) DeleteComment(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) AddReaction(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
+		return
+	}
+
+	commentID, err := pathID(r, "commentID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	commentID := chi.URLParam(r, "commentID")
 	var req ReactionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid JSON"))
+		handleError(w, r, apperrors.InvalidRequest("Invalid JSON"))
 		return
 	}
 
 	if req.Emoji == "" {
-		handleError(w, apperrors.MissingRequiredField("Emoji"))
+		handleError(w, r, apperrors.MissingRequiredField("Emoji"))
 		return
 	}
 
 	if err := h.commentService.AddReaction(r.Context(), commentID, userID, req.Emoji); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -124,20 +128,24 @@ func (h *Handlers) AddReaction(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) RemoveReaction(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	commentID := chi.URLParam(r, "commentID")
+	commentID, err := pathID(r, "commentID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
 	emoji := r.URL.Query().Get("emoji") 
 
 	if emoji == "" {
-		handleError(w, apperrors.MissingRequiredField("Emoji query param"))
+		handleError(w, r, apperrors.MissingRequiredField("Emoji query param"))
 		return
 	}
 
 	if err := h.commentService.RemoveReaction(r.Context(), commentID, userID, emoji); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/services"
+)
+
+// ExportSignatureHeader carries the signature of a signed export. It is
+// detached so the file itself stays a plain CSV.
+const ExportSignatureHeader = "X-Export-Signature"
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+var csvNumberFormats = map[string]services.NumberFormat{
+	"raw":   {Decimal: "."},
+	"en":    {Decimal: ".", Thousands: ","},
+	"en-in": {Decimal: ".", Thousands: ",", Indian: true},
+	"de":    {Decimal: ",", Thousands: "."},
+	"fr":    {Decimal: ",", Thousands: " "},
+	"ch":    {Decimal: ".", Thousands: "'"},
+}
+
+// csvLabels is the fixed text of a group export, translated into the group's
+// default language.
+type csvLabels struct {
+	date, description, category, currency, cost, paidBy, yourShare string
+	paid, formerMember, unknown, tags, receipt                     string
+	debit, credit                                                  string
+}
+
+var csvGroupLabels = map[string]csvLabels{
+	"en": {
+		date: "Date", description: "Description", category: "Category", currency: "Currency", cost: "Cost", paidBy: "Paid By", yourShare: "Your Share",
+		paid: "Paid: ", formerMember: "Former member", unknown: "Unknown", tags: "Tags", receipt: "Receipt",
+		debit: "Debit", credit: "Credit",
+	},
+	"es": {
+		date: "Fecha", description: "Descripción", category: "Categoría", currency: "Moneda", cost: "Importe", paidBy: "Pagado por", yourShare: "Tu parte",
+		paid: "Pagado: ", formerMember: "Antiguo miembro", unknown: "Desconocido", tags: "Etiquetas", receipt: "Recibo",
+		debit: "Debe", credit: "Haber",
+	},
+	"fr": {
+		date: "Date", description: "Description", category: "Catégorie", currency: "Devise", cost: "Montant", paidBy: "Payé par", yourShare: "Votre part",
+		paid: "Payé : ", formerMember: "Ancien membre", unknown: "Inconnu", tags: "Étiquettes", receipt: "Reçu",
+		debit: "Débit", credit: "Crédit",
+	},
+	"de": {
+		date: "Datum", description: "Beschreibung", category: "Kategorie", currency: "Währung", cost: "Betrag", paidBy: "Bezahlt von", yourShare: "Dein Anteil",
+		paid: "Bezahlt: ", formerMember: "Ehemaliges Mitglied", unknown: "Unbekannt", tags: "Tags", receipt: "Beleg",
+		debit: "Soll", credit: "Haben",
+	},
+	"hi": {
+		date: "तारीख", description: "विवरण", category: "श्रेणी", currency: "मुद्रा", cost: "राशि", paidBy: "भुगतानकर्ता", yourShare: "आपका हिस्सा",
+		paid: "भुगतान: ", formerMember: "पूर्व सदस्य", unknown: "अज्ञात", tags: "टैग", receipt: "रसीद",
+		debit: "डेबिट", credit: "क्रेडिट",
+	},
+}
+
+func csvLabelsFor(language string) csvLabels {
+	if labels, ok := csvGroupLabels[language]; ok {
+		return labels
+	}
+	return csvGroupLabels[apperrors.DefaultLanguage]
+}
+
+var csvDelimiters = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+}
+
+type csvExportOptions struct {
+	format    services.NumberFormat
+	delimiter rune
+	bom       bool
+	sign      bool
+}
+
+func parseCSVExportOptions(r *http.Request) (csvExportOptions, error) {
+	query := r.URL.Query()
+	opts := csvExportOptions{format: csvNumberFormats["raw"], delimiter: ','}
+
+	if locale := strings.ToLower(strings.TrimSpace(query.Get("locale"))); locale != "" {
+		format, ok := csvNumberFormats[locale]
+		if !ok {
+			return opts, apperrors.InvalidRequest("Unsupported locale. Use one of: raw, en, en-in, de, fr, ch.")
+		}
+		opts.format = format
+		if format.Decimal == "," {
+			opts.delimiter = ';'
+		}
+	}
+
+	if delimiter := strings.ToLower(strings.TrimSpace(query.Get("delimiter"))); delimiter != "" {
+		d, ok := csvDelimiters[delimiter]
+		if !ok {
+			return opts, apperrors.InvalidRequest("Unsupported delimiter. Use one of: comma, semicolon, tab.")
+		}
+		opts.delimiter = d
+	}
+	if string(opts.delimiter) == opts.format.Decimal {
+		return opts, apperrors.InvalidRequest("Delimiter cannot be the same as the decimal separator.")
+	}
+
+	if bom := query.Get("bom"); bom != "" {
+		value, err := strconv.ParseBool(bom)
+		if err != nil {
+			return opts, apperrors.InvalidRequest("bom must be true or false.")
+		}
+		opts.bom = value
+	}
+
+	if sign := query.Get("sign"); sign != "" {
+		value, err := strconv.ParseBool(sign)
+		if err != nil {
+			return opts, apperrors.InvalidRequest("sign must be true or false.")
+		}
+		opts.sign = value
+	}
+
+	return opts, nil
+}
+
+// exportBody is what an export writes its file into. Unsigned exports stream
+// straight to the client; signed ones are held until Close, because the
+// signature header has to go out before the body.
+type exportBody struct {
+	w      http.ResponseWriter
+	signer services.ExportSigner
+	buf    bytes.Buffer
+}
+
+func (h *Handlers) newExportBody(w http.ResponseWriter, opts csvExportOptions) (*exportBody, error) {
+	body := &exportBody{w: w}
+	if opts.sign {
+		if !h.exportSigner.Enabled() {
+			return nil, apperrors.InvalidRequest("Export signing is not configured on this server.")
+		}
+		body.signer = h.exportSigner
+	}
+	return body, nil
+}
+
+func (b *exportBody) Write(p []byte) (int, error) {
+	if b.signer == nil {
+		return b.w.Write(p)
+	}
+	return b.buf.Write(p)
+}
+
+// Close signs and sends a buffered export. Call it only once the whole file
+// has been written without error.
+func (b *exportBody) Close() error {
+	if b.signer == nil {
+		return nil
+	}
+	b.w.Header().Set(ExportSignatureHeader, b.signer.Sign(b.buf.Bytes(), time.Now()))
+	_, err := b.w.Write(b.buf.Bytes())
+	return err
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestCSVNumberFormats(t *testing.T) {
+	tests := []struct {
+		locale   string
+		amount   float64
+		expected string
+	}{
+		{"raw", 1234567.891, "1234567.89"},
+		{"en", 1234567.5, "1,234,567.50"},
+		{"en-in", 1234567.5, "12,34,567.50"},
+		{"en-in", 100000, "1,00,000.00"},
+		{"en-in", 999, "999.00"},
+		{"de", -1234.5, "-1.234,50"},
+		{"fr", 1234.5, "1 234,50"},
+		{"ch", 1234.5, "1'234.50"},
+		{"en", -0.001, "0.00"},
+		{"de", -0.004, "0,00"},
+	}
+
+	for _, tt := range tests {
+		if got := csvNumberFormats[tt.locale].FormatAmount(tt.amount); got != tt.expected {
+			t.Errorf("FormatAmount(%v) in %s = %q, expected %q", tt.amount, tt.locale, got, tt.expected)
+		}
+	}
+}
+
+func TestParseCSVExportOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		delimiter   rune
+		decimal     string
+		expectError bool
+	}{
+		{name: "Defaults", query: "", delimiter: ',', decimal: "."},
+		{name: "Decimal Comma Switches To Semicolon", query: "locale=de", delimiter: ';', decimal: ","},
+		{name: "Locale Is Case Insensitive", query: "locale=EN-IN", delimiter: ',', decimal: "."},
+		{name: "Tab With Decimal Comma", query: "locale=fr&delimiter=tab", delimiter: '\t', decimal: ","},
+		{name: "Comma Delimiter With Decimal Comma", query: "locale=de&delimiter=comma", expectError: true},
+		{name: "Unknown Locale", query: "locale=xx", expectError: true},
+		{name: "Unknown Delimiter", query: "delimiter=pipe", expectError: true},
+		{name: "Bad BOM", query: "bom=maybe", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseCSVExportOptions(httptest.NewRequest("GET", "/export?"+tt.query, nil))
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseCSVExportOptions() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			if opts.delimiter != tt.delimiter || opts.format.Decimal != tt.decimal {
+				t.Errorf("parseCSVExportOptions() = %q/%q, expected %q/%q", opts.delimiter, opts.format.Decimal, tt.delimiter, tt.decimal)
+			}
+		})
+	}
+}
+
+func TestWriteGroupCSVPayerColumns(t *testing.T) {
+	members := []models.User{{ID: "me", Name: "Me"}, {ID: "A", Name: "Asha"}, {ID: "B", Name: "Bo"}}
+	transactions := []models.Transaction{
+		ledgerTransaction("e1", "2024-06-01", "Dinner", 1500, 0, 500,
+			[]models.ExpensePayer{{UserID: "gone", AmountPaid: 1000}, {UserID: "A", AmountPaid: 500}}, nil),
+		ledgerTransaction("e2", "2024-06-02", "Taxi", 90, 0, 30,
+			[]models.ExpensePayer{{UserID: "me", AmountPaid: 90}}, nil),
+	}
+	noReceipt := func(models.Transaction) string { return "" }
+
+	tests := []struct {
+		locale   string
+		language string
+		expected string
+	}{
+		{
+			locale: "en", language: "en",
+			expected: "Date,Description,Category,Currency,Cost,Paid By,Your Share,Paid: Me,Paid: Asha,Paid: Former member,Tags,Receipt\r\n" +
+				"2024-06-01,Dinner,EXPENSE,INR,\"1,500.00\",Unknown,500.00,0.00,500.00,\"1,000.00\",,\r\n" +
+				"2024-06-02,Taxi,EXPENSE,INR,90.00,Unknown,30.00,90.00,0.00,0.00,,\r\n",
+		},
+		{
+			locale: "de", language: "de",
+			expected: "Datum;Beschreibung;Kategorie;Währung;Betrag;Bezahlt von;Dein Anteil;Bezahlt: Me;Bezahlt: Asha;Bezahlt: Ehemaliges Mitglied;Tags;Beleg\r\n" +
+				"2024-06-01;Dinner;EXPENSE;INR;1.500,00;Unbekannt;500,00;0,00;500,00;1.000,00;;\r\n" +
+				"2024-06-02;Taxi;EXPENSE;INR;90,00;Unbekannt;30,00;90,00;0,00;0,00;;\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			opts, err := parseCSVExportOptions(httptest.NewRequest("GET", "/export?locale="+tt.locale, nil))
+			if err != nil {
+				t.Fatalf("parseCSVExportOptions: %v", err)
+			}
+			labels := csvLabelsFor(tt.language)
+
+			var buf bytes.Buffer
+			if err := writeGroupCSV(&buf, opts, labels, groupExportPayers(members, transactions, labels), transactions, noReceipt); err != nil {
+				t.Fatalf("writeGroupCSV: %v", err)
+			}
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("group CSV = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
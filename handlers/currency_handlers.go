@@ -20,7 +20,7 @@ func NewCurrencyHandlers(currencyRepo repository.CurrencyRepository) *CurrencyHa
 func (h *CurrencyHandlers) GetCurrencies(w http.ResponseWriter, r *http.Request) {
 	currencies, err := h.currencyRepo.GetAll(r.Context())
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
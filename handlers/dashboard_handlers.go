@@ -3,27 +3,65 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"strings"
+
+	"unwise-backend/services"
 )
 
 func (h *Handlers) GetDashboard(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 	email, err := getUserEmail(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 	name, _ := getUserName(r)
 
-	dashboard, err := h.dashboardService.GetDashboard(r.Context(), userID, email, name)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		version, err := h.dashboardService.GetDashboardVersion(r.Context(), userID)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+		if etagMatches(ifNoneMatch, dashboardETag(version)) {
+			w.Header().Set("ETag", dashboardETag(version))
+			w.Header().Set("Cache-Control", "private, no-cache")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	dashboard, err := h.dashboardService.GetDashboard(r.Context(), userID, email, name, getEmailVerified(r))
 	if err != nil {
 		log.Printf("[Handlers.GetDashboard] Error: %v", err)
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
+	for i := range dashboard.RecentActivity {
+		activity := &dashboard.RecentActivity[i]
+		activity.ReceiptImageURL = h.signReceiptURL(r.Context(), activity.ReceiptImagePath, services.ReceiptURLExpiry)
+	}
+
+	w.Header().Set("ETag", dashboardETag(dashboard.Version))
+	w.Header().Set("Cache-Control", "private, no-cache")
 	respondJSON(w, http.StatusOK, dashboard)
 }
+
+func dashboardETag(version string) string {
+	return `W/"` + version + `"`
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
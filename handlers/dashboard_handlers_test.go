@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"unwise-backend/middleware"
+	"unwise-backend/models"
+)
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		ifNoneMatch string
+		expected    bool
+	}{
+		{`W/"abc"`, true},
+		{`"abc"`, true},
+		{`"old", W/"abc"`, true},
+		{`*`, true},
+		{`W/"abcd"`, false},
+		{`"old"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.ifNoneMatch, `W/"abc"`); got != tt.expected {
+			t.Errorf("etagMatches(%q) = %v, expected %v", tt.ifNoneMatch, got, tt.expected)
+		}
+	}
+}
+
+type fakeDashboardService struct {
+	version string
+	builds  int
+}
+
+func (s *fakeDashboardService) GetDashboard(ctx context.Context, userID, email, name string, emailVerified *bool) (*models.DashboardResponse, error) {
+	s.builds++
+	return &models.DashboardResponse{Version: s.version}, nil
+}
+
+func (s *fakeDashboardService) GetDashboardVersion(ctx context.Context, userID string) (string, error) {
+	return s.version, nil
+}
+
+func TestGetDashboardRevalidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		ifNoneMatch    string
+		expectedStatus int
+		expectedBuilds int
+	}{
+		{name: "No Validator", expectedStatus: http.StatusOK, expectedBuilds: 1},
+		{name: "Current ETag", ifNoneMatch: `W/"v1"`, expectedStatus: http.StatusNotModified},
+		{name: "Stale ETag", ifNoneMatch: `W/"v0"`, expectedStatus: http.StatusOK, expectedBuilds: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dashboard := &fakeDashboardService{version: "v1"}
+			h := &Handlers{dashboardService: dashboard}
+
+			ctx := context.WithValue(context.Background(), middleware.UserIDKey, "alice")
+			ctx = context.WithValue(ctx, middleware.EmailKey, "alice@example.com")
+			req := httptest.NewRequest(http.MethodGet, "/api/dashboard", nil).WithContext(ctx)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			h.GetDashboard(rec, req)
+
+			if rec.Code != tt.expectedStatus || dashboard.builds != tt.expectedBuilds {
+				t.Errorf("GetDashboard() status = %d after %d builds, expected %d after %d", rec.Code, dashboard.builds, tt.expectedStatus, tt.expectedBuilds)
+			}
+			if etag := rec.Header().Get("ETag"); etag != `W/"v1"` {
+				t.Errorf("GetDashboard() ETag = %q, expected W/\"v1\"", etag)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type EventHandlers struct {
+	eventService services.EventService
+}
+
+func NewEventHandlers(eventService services.EventService) *EventHandlers {
+	return &EventHandlers{
+		eventService: eventService,
+	}
+}
+
+func (h *EventHandlers) RegisterRoutes(r chi.Router) {
+	r.Route("/groups/{groupID}/events", func(r chi.Router) {
+		r.Get("/", h.GetGroupEvents)
+		r.Post("/", h.CreateEvent)
+		r.Get("/{eventID}", h.GetEvent)
+		r.Delete("/{eventID}", h.DeleteEvent)
+	})
+}
+
+type CreateEventRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *EventHandlers) GetGroupEvents(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	events, err := h.eventService.GetGroupEvents(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, events)
+}
+
+func (h *EventHandlers) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req CreateEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	event, err := h.eventService.CreateEvent(r.Context(), groupID, userID, req.Name)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, event)
+}
+
+func (h *EventHandlers) GetEvent(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	eventID, err := pathID(r, "eventID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	event, err := h.eventService.GetEvent(r.Context(), groupID, eventID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, event)
+}
+
+func (h *EventHandlers) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	eventID, err := pathID(r, "eventID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.eventService.DeleteEvent(r.Context(), groupID, eventID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Event deleted successfully"})
+}
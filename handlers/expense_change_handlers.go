@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+func (h *Handlers) GetPendingChanges(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	changes, err := h.expenseService.GetPendingChanges(r.Context(), userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, changes)
+}
+
+func (h *Handlers) AcceptChange(w http.ResponseWriter, r *http.Request) {
+	h.respondToChange(w, r, true)
+}
+
+func (h *Handlers) RejectChange(w http.ResponseWriter, r *http.Request) {
+	h.respondToChange(w, r, false)
+}
+
+func (h *Handlers) respondToChange(w http.ResponseWriter, r *http.Request, accept bool) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	changeID, err := pathID(r, "changeID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	change, err := h.expenseService.RespondToChange(r.Context(), changeID, userID, accept)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, change)
+}
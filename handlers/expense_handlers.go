@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
 	apperrors "unwise-backend/errors"
@@ -12,149 +13,236 @@ import (
 
 	"time"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type CreateExpenseRequest struct {
-	GroupID         string                     `json:"group_id"`
-	TotalAmount     float64                    `json:"total_amount"`
-	Description     string                     `json:"description"`
-	ReceiptImageURL *string                    `json:"receipt_image_url,omitempty"`
-	Type            models.ExpenseType         `json:"split_method"`
-	Category        models.TransactionCategory `json:"type"`
-	Tax             float64                    `json:"tax"`
-	CGST            float64                    `json:"cgst"`
-	SGST            float64                    `json:"sgst"`
-	ServiceCharge   float64                    `json:"service_charge"`
-	Payers          []models.ExpensePayer      `json:"payers,omitempty"`
-	PaidByUserID    *string                    `json:"paid_by_user_id,omitempty"`
-	Splits          []models.ExpenseSplit      `json:"splits"`
-	ReceiptItems    []ReceiptItemRequest       `json:"receipt_items,omitempty"`
-	Date            *time.Time                 `json:"date,omitempty"`
+	GroupID          string                     `json:"group_id"`
+	TotalAmount      float64                    `json:"total_amount"`
+	Description      string                     `json:"description"`
+	ReceiptImagePath *string                    `json:"receipt_image_path,omitempty"`
+	ReceiptImageURL  *string                    `json:"receipt_image_url,omitempty"`
+	Type             models.ExpenseType         `json:"split_method"`
+	Category         models.TransactionCategory `json:"type"`
+	Tax              float64                    `json:"tax"`
+	CGST             float64                    `json:"cgst"`
+	SGST             float64                    `json:"sgst"`
+	TaxComponents    map[string]float64         `json:"tax_components,omitempty"`
+	ServiceCharge    float64                    `json:"service_charge"`
+	Payers           []models.ExpensePayer      `json:"payers,omitempty"`
+	PaidByUserID     *string                    `json:"paid_by_user_id,omitempty"`
+	Splits           []models.ExpenseSplit      `json:"splits"`
+	ParticipantIDs   []string                   `json:"participant_ids,omitempty"`
+	ReceiptItems     []ReceiptItemRequest       `json:"receipt_items,omitempty"`
+	EventID          *string                    `json:"event_id,omitempty"`
+	Tags             []string                   `json:"tags,omitempty"`
+	Date             *time.Time                 `json:"date,omitempty"`
+	ConfirmOverLimit bool                       `json:"confirm_over_limit,omitempty"`
+	LockedSplits     bool                       `json:"locked_splits,omitempty"`
+}
+
+type RefundRequest struct {
+	Amount       float64               `json:"amount"`
+	Description  string                `json:"description,omitempty"`
+	PaidByUserID *string               `json:"paid_by_user_id,omitempty"`
+	Payers       []models.ExpensePayer `json:"payers,omitempty"`
+	Splits       []models.ExpenseSplit `json:"splits,omitempty"`
+	Date         *time.Time            `json:"date,omitempty"`
 }
 
 type ReceiptItemRequest struct {
-	Name       string   `json:"name"`
-	Price      float64  `json:"price"`
-	AssignedTo []string `json:"assigned_to"`
+	Name       string             `json:"name"`
+	Price      float64            `json:"price"`
+	Quantity   float64            `json:"quantity,omitempty"`
+	AssignedTo []string           `json:"assigned_to"`
+	Portions   map[string]float64 `json:"portions,omitempty"`
+}
+
+// receiptItemsFromRequest turns request items into models. Users listed in
+// portions are assignees even when missing from assigned_to.
+func receiptItemsFromRequest(items []ReceiptItemRequest) []models.ReceiptItem {
+	receiptItems := make([]models.ReceiptItem, 0, len(items))
+	for _, item := range items {
+		receiptItem := models.ReceiptItem{
+			Name:     item.Name,
+			Price:    item.Price,
+			Quantity: item.Quantity,
+		}
+		seen := make(map[string]bool)
+		assignees := append([]string{}, item.AssignedTo...)
+		extra := make([]string, 0, len(item.Portions))
+		for userID := range item.Portions {
+			extra = append(extra, userID)
+		}
+		sort.Strings(extra)
+		for _, userID := range append(assignees, extra...) {
+			if seen[userID] {
+				continue
+			}
+			seen[userID] = true
+			receiptItem.Assignments = append(receiptItem.Assignments, models.ReceiptItemAssignment{
+				UserID:  userID,
+				Portion: item.Portions[userID],
+			})
+		}
+		receiptItems = append(receiptItems, receiptItem)
+	}
+	return receiptItems
 }
 
 type UpdateExpenseRequest struct {
-	TotalAmount     float64                    `json:"total_amount"`
-	Description     string                     `json:"description"`
-	ReceiptImageURL *string                    `json:"receipt_image_url,omitempty"`
-	Type            models.ExpenseType         `json:"split_method"`
-	Category        models.TransactionCategory `json:"type"`
-	Tax             float64                    `json:"tax"`
-	CGST            float64                    `json:"cgst"`
-	SGST            float64                    `json:"sgst"`
-	ServiceCharge   float64                    `json:"service_charge"`
-	Payers          []models.ExpensePayer      `json:"payers,omitempty"`
-	PaidByUserID    *string                    `json:"paid_by_user_id,omitempty"`
-	Splits          []models.ExpenseSplit      `json:"splits"`
-	ReceiptItems    []ReceiptItemRequest       `json:"receipt_items,omitempty"`
-	Date            *time.Time                 `json:"date,omitempty"`
+	TotalAmount      float64                    `json:"total_amount"`
+	Description      string                     `json:"description"`
+	ReceiptImagePath *string                    `json:"receipt_image_path,omitempty"`
+	ReceiptImageURL  *string                    `json:"receipt_image_url,omitempty"`
+	Type             models.ExpenseType         `json:"split_method"`
+	Category         models.TransactionCategory `json:"type"`
+	Tax              float64                    `json:"tax"`
+	CGST             float64                    `json:"cgst"`
+	SGST             float64                    `json:"sgst"`
+	TaxComponents    map[string]float64         `json:"tax_components,omitempty"`
+	ServiceCharge    float64                    `json:"service_charge"`
+	Payers           []models.ExpensePayer      `json:"payers,omitempty"`
+	PaidByUserID     *string                    `json:"paid_by_user_id,omitempty"`
+	Splits           []models.ExpenseSplit      `json:"splits"`
+	ReceiptItems     []ReceiptItemRequest       `json:"receipt_items,omitempty"`
+	EventID          *string                    `json:"event_id,omitempty"`
+	Tags             []string                   `json:"tags,omitempty"`
+	Date             *time.Time                 `json:"date,omitempty"`
+	ConfirmOverLimit bool                       `json:"confirm_over_limit,omitempty"`
 }
 
 func (h *Handlers) GetExpenses(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	opts, err := parsePayloadOptions(r)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	expenses, err := h.expenseService.GetByGroupID(r.Context(), groupID, userID)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
+		return
+	}
+
+	for i := range expenses {
+		h.signExpenseReceipt(r.Context(), &expenses[i], services.ReceiptURLExpiry)
+		opts.slimExpense(&expenses[i])
+	}
+
+	payload, err := opts.selectFields(expenses)
+	if err != nil {
+		handleError(w, r, apperrors.InternalError(err))
 		return
 	}
 
-	respondJSON(w, http.StatusOK, expenses)
+	respondJSON(w, http.StatusOK, payload)
 }
 
 func (h *Handlers) GetExpense(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	expenseID := chi.URLParam(r, "expenseID")
-	if expenseID == "" {
-		handleError(w, apperrors.MissingRequiredField("Expense ID"))
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	expense, err := h.expenseService.GetByID(r.Context(), expenseID, userID)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
+	h.signExpenseReceipt(r.Context(), expense, services.ReceiptURLExpiry)
+
 	respondJSON(w, http.StatusOK, expense)
 }
 
 func (h *Handlers) CreateExpense(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	var req CreateExpenseRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
-	if _, err := uuid.Parse(req.GroupID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Group ID format. Must be a valid UUID."))
+	if req.GroupID, err = parseID(req.GroupID, "Group ID"); err != nil {
+		handleError(w, r, err)
 		return
 	}
 	if req.TotalAmount <= 0 {
-		handleError(w, apperrors.InvalidAmount("Total amount must be greater than zero."))
+		handleError(w, r, apperrors.InvalidAmount("Total amount must be greater than zero."))
+		return
+	}
+	for i, id := range req.ParticipantIDs {
+		if req.ParticipantIDs[i], err = parseID(id, "participant_ids"); err != nil {
+			handleError(w, r, err)
+			return
+		}
+	}
+	if req.EventID, err = parseOptionalID(req.EventID, "event_id"); err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	if req.Category != models.TransactionCategoryPayment && req.Category != models.TransactionCategoryRepayment {
 		desc := strings.TrimSpace(req.Description)
 		if desc == "" {
-			handleError(w, apperrors.MissingRequiredField("Description"))
+			handleError(w, r, apperrors.MissingRequiredField("Description"))
 			return
 		}
 		if len(desc) < services.MinDescriptionLength || len(desc) > services.MaxDescriptionLength {
-			handleError(w, apperrors.InvalidRequest(fmt.Sprintf("Description must be between %d and %d characters.", services.MinDescriptionLength, services.MaxDescriptionLength)))
+			handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Description must be between %d and %d characters.", services.MinDescriptionLength, services.MaxDescriptionLength)))
 			return
 		}
 	}
 
-	if req.Category != models.TransactionCategoryPayment && req.Category != models.TransactionCategoryRepayment {
-		if len(req.Splits) == 0 {
-			handleError(w, apperrors.MissingRequiredField("Splits"))
-			return
-		}
+	receiptPath, err := h.receiptImagePath(userID, req.GroupID, req.ReceiptImagePath, req.ReceiptImageURL)
+	if err != nil {
+		handleError(w, r, err)
+		return
 	}
 
 	expense := &models.Expense{
-		GroupID:         req.GroupID,
-		TotalAmount:     req.TotalAmount,
-		Description:     req.Description,
-		ReceiptImageURL: req.ReceiptImageURL,
-		Type:            req.Type,
-		Tax:             req.Tax,
-		CGST:            req.CGST,
-		SGST:            req.SGST,
-		ServiceCharge:   req.ServiceCharge,
-		Payers:          req.Payers,
-		PaidByUserID:    req.PaidByUserID,
+		GroupID:          req.GroupID,
+		TotalAmount:      req.TotalAmount,
+		Description:      req.Description,
+		ReceiptImagePath: receiptPath,
+		Type:             req.Type,
+		Tax:              req.Tax,
+		CGST:             req.CGST,
+		SGST:             req.SGST,
+		TaxComponents:    req.TaxComponents,
+		ServiceCharge:    req.ServiceCharge,
+		Payers:           req.Payers,
+		PaidByUserID:     req.PaidByUserID,
+		Tags:             req.Tags,
+		EventID:          req.EventID,
+		ConfirmOverLimit: req.ConfirmOverLimit,
+		ParticipantIDs:   req.ParticipantIDs,
+		LockedSplits:     req.LockedSplits,
 	}
 
 	if req.Date != nil {
@@ -164,25 +252,12 @@ func (h *Handlers) CreateExpense(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(req.ReceiptItems) > 0 {
-		receiptItems := make([]models.ReceiptItem, 0, len(req.ReceiptItems))
-		for _, item := range req.ReceiptItems {
-			receiptItem := models.ReceiptItem{
-				Name:  item.Name,
-				Price: item.Price,
-			}
-			for _, userID := range item.AssignedTo {
-				receiptItem.Assignments = append(receiptItem.Assignments, models.ReceiptItemAssignment{
-					UserID: userID,
-				})
-			}
-			receiptItems = append(receiptItems, receiptItem)
-		}
-		expense.ReceiptItems = receiptItems
+		expense.ReceiptItems = receiptItemsFromRequest(req.ReceiptItems)
 	}
 
 	expense, err = h.expenseService.Create(r.Context(), userID, expense, req.Splits)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -192,63 +267,78 @@ func (h *Handlers) CreateExpense(w http.ResponseWriter, r *http.Request) {
 		zap.String("user_id", userID),
 		zap.Float64("amount", expense.TotalAmount))
 
+	h.signExpenseReceipt(r.Context(), expense, services.ReceiptURLExpiry)
 	respondJSON(w, http.StatusCreated, expense)
 }
 
 func (h *Handlers) UpdateExpense(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	expenseID := chi.URLParam(r, "expenseID")
-	if _, err := uuid.Parse(expenseID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Expense ID format."))
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	var req UpdateExpenseRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
 	if req.TotalAmount <= 0 {
-		handleError(w, apperrors.InvalidAmount("Total amount must be greater than zero."))
+		handleError(w, r, apperrors.InvalidAmount("Total amount must be greater than zero."))
+		return
+	}
+	if req.EventID, err = parseOptionalID(req.EventID, "event_id"); err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	if req.Category != models.TransactionCategoryPayment && req.Category != models.TransactionCategoryRepayment {
 		desc := strings.TrimSpace(req.Description)
 		if desc == "" {
-			handleError(w, apperrors.MissingRequiredField("Description"))
+			handleError(w, r, apperrors.MissingRequiredField("Description"))
 			return
 		}
 		if len(desc) < services.MinDescriptionLength || len(desc) > services.MaxDescriptionLength {
-			handleError(w, apperrors.InvalidRequest(fmt.Sprintf("Description must be between %d and %d characters.", services.MinDescriptionLength, services.MaxDescriptionLength)))
+			handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Description must be between %d and %d characters.", services.MinDescriptionLength, services.MaxDescriptionLength)))
 			return
 		}
 	}
 
 	if req.Category != models.TransactionCategoryPayment && req.Category != models.TransactionCategoryRepayment {
 		if len(req.Splits) == 0 {
-			handleError(w, apperrors.MissingRequiredField("Splits"))
+			handleError(w, r, apperrors.MissingRequiredField("Splits"))
 			return
 		}
 	}
 
+	receiptPath, err := h.updatedReceiptPath(r.Context(), expenseID, userID, req.ReceiptImagePath, req.ReceiptImageURL)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
 	expense := &models.Expense{
-		TotalAmount:     req.TotalAmount,
-		Description:     req.Description,
-		ReceiptImageURL: req.ReceiptImageURL,
-		Type:            req.Type,
-		Tax:             req.Tax,
-		CGST:            req.CGST,
-		SGST:            req.SGST,
-		ServiceCharge:   req.ServiceCharge,
-		Payers:          req.Payers,
-		PaidByUserID:    req.PaidByUserID,
+		TotalAmount:      req.TotalAmount,
+		Description:      req.Description,
+		ReceiptImagePath: receiptPath,
+		Type:             req.Type,
+		Tax:              req.Tax,
+		CGST:             req.CGST,
+		SGST:             req.SGST,
+		TaxComponents:    req.TaxComponents,
+		ServiceCharge:    req.ServiceCharge,
+		Payers:           req.Payers,
+		PaidByUserID:     req.PaidByUserID,
+		Tags:             req.Tags,
+		EventID:          req.EventID,
+		ConfirmOverLimit: req.ConfirmOverLimit,
 	}
 
 	if req.Date != nil {
@@ -258,48 +348,117 @@ func (h *Handlers) UpdateExpense(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(req.ReceiptItems) > 0 {
-		receiptItems := make([]models.ReceiptItem, 0, len(req.ReceiptItems))
-		for _, item := range req.ReceiptItems {
-			receiptItem := models.ReceiptItem{
-				Name:  item.Name,
-				Price: item.Price,
-			}
-			for _, userID := range item.AssignedTo {
-				receiptItem.Assignments = append(receiptItem.Assignments, models.ReceiptItemAssignment{
-					UserID: userID,
-				})
-			}
-			receiptItems = append(receiptItems, receiptItem)
-		}
-		expense.ReceiptItems = receiptItems
+		expense.ReceiptItems = receiptItemsFromRequest(req.ReceiptItems)
 	}
 
 	expense, err = h.expenseService.Update(r.Context(), expenseID, userID, expense, req.Splits)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
+	h.signExpenseReceipt(r.Context(), expense, services.ReceiptURLExpiry)
+
+	// The edit is waiting for settled members to confirm it.
+	if expense.PendingChange != nil {
+		respondJSON(w, http.StatusAccepted, expense)
+		return
+	}
 	respondJSON(w, http.StatusOK, expense)
 }
 
 func (h *Handlers) DeleteExpense(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	expenseID := chi.URLParam(r, "expenseID")
-	if expenseID == "" {
-		handleError(w, apperrors.MissingRequiredField("Expense ID"))
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	if err := h.expenseService.Delete(r.Context(), expenseID, userID); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Expense deleted successfully"})
 }
+
+func (h *Handlers) CreateRefund(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	if req.Amount <= 0 {
+		handleError(w, r, apperrors.InvalidAmount("Refund amount must be greater than zero."))
+		return
+	}
+
+	desc := strings.TrimSpace(req.Description)
+	if desc != "" && (len(desc) < services.MinDescriptionLength || len(desc) > services.MaxDescriptionLength) {
+		handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Description must be between %d and %d characters.", services.MinDescriptionLength, services.MaxDescriptionLength)))
+		return
+	}
+
+	refund := &models.Expense{
+		TotalAmount:  req.Amount,
+		Description:  desc,
+		PaidByUserID: req.PaidByUserID,
+		Payers:       req.Payers,
+	}
+	if req.Date != nil {
+		refund.DateISO = *req.Date
+		refund.Date = req.Date.Format("2006-01-02")
+		refund.Time = req.Date.Format("15:04")
+	}
+
+	refund, err = h.expenseService.CreateRefund(r.Context(), userID, expenseID, refund, req.Splits)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, refund)
+}
+
+// SettleExpense pays the requester's share of one expense to its payers,
+// without settling anything else in the group.
+func (h *Handlers) SettleExpense(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	payments, err := h.expenseService.SettleExpense(r.Context(), expenseID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, payments)
+}
@@ -11,7 +11,7 @@ import (
 func (h *Handlers) ExplainTransaction(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -19,12 +19,12 @@ func (h *Handlers) ExplainTransaction(w http.ResponseWriter, r *http.Request) {
 		TransactionID string `json:"transaction_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
 	if req.TransactionID == "" {
-		handleError(w, apperrors.MissingRequiredField("Transaction ID"))
+		handleError(w, r, apperrors.MissingRequiredField("Transaction ID"))
 		return
 	}
 
@@ -32,7 +32,7 @@ func (h *Handlers) ExplainTransaction(w http.ResponseWriter, r *http.Request) {
 	explanation, err := h.explanationService.ExplainTransaction(r.Context(), req.TransactionID, userID)
 	if err != nil {
 		log.Printf("[ExplainTransaction] Failed: %v", err)
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
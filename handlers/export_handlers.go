@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ExportHandlers serves the public side of export signing. Recipients of a
+// signed export usually have no account, so these routes sit outside /api.
+type ExportHandlers struct {
+	exportSigner services.ExportSigner
+}
+
+func NewExportHandlers(exportSigner services.ExportSigner) *ExportHandlers {
+	return &ExportHandlers{
+		exportSigner: exportSigner,
+	}
+}
+
+func (h *ExportHandlers) RegisterRoutes(r chi.Router) {
+	r.Post("/exports/verify", h.VerifyExport)
+}
+
+type VerifyExportResponse struct {
+	Valid    bool       `json:"valid"`
+	SignedAt *time.Time `json:"signed_at,omitempty"`
+}
+
+// VerifyExport checks an exported file against the signature it was served
+// with. The file goes in the multipart field "file" and the signature in
+// "signature" (or the X-Export-Signature header).
+func (h *ExportHandlers) VerifyExport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxSignedExportSize)
+
+	if err := r.ParseMultipartForm(services.MaxSignedExportSize); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("File too large or invalid multipart form. Max size is 20MB."))
+		return
+	}
+
+	signature := strings.TrimSpace(r.FormValue("signature"))
+	if signature == "" {
+		signature = strings.TrimSpace(r.Header.Get(ExportSignatureHeader))
+	}
+	if signature == "" {
+		handleError(w, r, apperrors.MissingRequiredField("signature"))
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		handleError(w, r, apperrors.MissingRequiredField("file"))
+		return
+	}
+	defer file.Close()
+
+	body, err := io.ReadAll(file)
+	if err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Could not read the uploaded file."))
+		return
+	}
+
+	valid, signedAt, err := h.exportSigner.Verify(body, signature)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	resp := VerifyExportResponse{Valid: valid}
+	if valid {
+		resp.SignedAt = &signedAt
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const FixturesTokenHeader = "X-Fixtures-Token"
+
+type FixtureHandlers struct {
+	fixtureService services.FixtureService
+}
+
+func NewFixtureHandlers(fixtureService services.FixtureService) *FixtureHandlers {
+	return &FixtureHandlers{
+		fixtureService: fixtureService,
+	}
+}
+
+func (h *FixtureHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/internal/fixtures", h.GetScenarios)
+	r.Post("/internal/fixtures/{scenario}", h.LoadScenario)
+}
+
+func (h *FixtureHandlers) GetScenarios(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.fixtureService.Scenarios())
+}
+
+func (h *FixtureHandlers) LoadScenario(w http.ResponseWriter, r *http.Request) {
+	result, err := h.fixtureService.Load(r.Context(), chi.URLParam(r, "scenario"))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, result)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ForecastHandlers struct {
+	forecastService services.ForecastService
+}
+
+func NewForecastHandlers(forecastService services.ForecastService) *ForecastHandlers {
+	return &ForecastHandlers{
+		forecastService: forecastService,
+	}
+}
+
+func (h *ForecastHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/groups/{groupID}/forecast", h.GetGroupForecast)
+}
+
+func (h *ForecastHandlers) GetGroupForecast(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	forecast, err := h.forecastService.GetGroupForecast(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, forecast)
+}
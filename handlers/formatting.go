@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/services"
+)
+
+const includeFormatting = "formatting"
+
+var includableFields = []string{includeFormatting}
+
+// parseIncludes reads ?include=, the optional extras a response can carry.
+func parseIncludes(r *http.Request) (map[string]bool, error) {
+	includes := make(map[string]bool)
+	for _, name := range splitListParam(r.URL.Query()["include"]) {
+		if !isIncludable(name) {
+			return nil, apperrors.InvalidRequest(fmt.Sprintf("Invalid include value '%s'. Must be one of: %s.", name, strings.Join(includableFields, ", ")))
+		}
+		includes[name] = true
+	}
+	return includes, nil
+}
+
+func isIncludable(name string) bool {
+	for _, field := range includableFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// requestLocale is the ?locale= parameter, or else the first language in the
+// Accept-Language header.
+func requestLocale(r *http.Request) string {
+	if locale := strings.TrimSpace(r.URL.Query().Get("locale")); locale != "" {
+		return locale
+	}
+	return headerLocale(r)
+}
+
+func headerLocale(r *http.Request) string {
+	locale, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	locale, _, _ = strings.Cut(locale, ";")
+	return strings.TrimSpace(locale)
+}
+
+// moneyFormatter returns a formatter for the request's locale when the client
+// asked for ?include=formatting, and nil otherwise.
+func (h *Handlers) moneyFormatter(r *http.Request) (*services.MoneyFormatter, error) {
+	includes, err := parseIncludes(r)
+	if err != nil || !includes[includeFormatting] {
+		return nil, err
+	}
+	return h.formatService.Formatter(r.Context(), requestLocale(r))
+}
+
+func formatTransaction(f *services.MoneyFormatter, t *models.Transaction) {
+	t.CurrencySymbol = f.Symbol(t.Currency)
+	t.AmountFormatted = f.Format(t.TotalAmount, t.Currency)
+	t.UserShareFormatted = f.Format(t.UserShare, t.Currency)
+}
+
+func formatDebt(f *services.MoneyFormatter, d *models.DebtEdge) {
+	d.CurrencySymbol = f.Symbol(d.Currency)
+	d.AmountFormatted = f.Format(d.Amount, d.Currency)
+}
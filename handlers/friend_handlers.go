@@ -1,13 +1,12 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
 	"strings"
 
 	apperrors "unwise-backend/errors"
-
-	"github.com/go-chi/chi/v5"
 )
 
 type AddFriendRequest struct {
@@ -17,13 +16,13 @@ type AddFriendRequest struct {
 func (h *Handlers) GetFriends(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	friends, err := h.friendService.GetFriendsWithBalances(r.Context(), userID)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -33,23 +32,23 @@ func (h *Handlers) GetFriends(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) AddFriend(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	var req AddFriendRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
 	if strings.TrimSpace(req.Email) == "" {
-		handleError(w, apperrors.MissingRequiredField("Email"))
+		handleError(w, r, apperrors.MissingRequiredField("Email"))
 		return
 	}
 
 	if err := h.friendService.AddFriendByEmail(r.Context(), userID, req.Email); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -59,36 +58,174 @@ func (h *Handlers) AddFriend(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) RemoveFriend(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	friendID := chi.URLParam(r, "friendID")
-	if friendID == "" {
-		handleError(w, apperrors.MissingRequiredField("Friend ID"))
+	friendID, err := pathID(r, "friendID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	if err := h.friendService.RemoveFriend(r.Context(), userID, friendID); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Friend removed successfully"})
 }
 
+func (h *Handlers) GetFriendBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	friendID, err := pathID(r, "friendID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	history, err := h.friendService.GetBalanceHistory(r.Context(), userID, friendID, r.URL.Query().Get("granularity"))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, history)
+}
+
 func (h *Handlers) SearchPotentialFriends(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
 	query := r.URL.Query().Get("q")
 	if query == "" {
 		respondJSON(w, http.StatusOK, []interface{}{})
 		return
 	}
 
-	results, err := h.friendService.SearchPotentialFriends(r.Context(), query)
+	results, err := h.friendService.SearchPotentialFriends(r.Context(), userID, query)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, results)
 }
+
+type ContactSuggestionsRequest struct {
+	EmailHashes []string `json:"email_hashes"`
+}
+
+// SuggestFriendsFromContacts takes SHA-256 hashes of the caller's contact
+// emails and returns the ones that belong to users they are allowed to find.
+func (h *Handlers) SuggestFriendsFromContacts(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req ContactSuggestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	suggestions, err := h.friendService.SuggestFromContacts(r.Context(), userID, req.EmailHashes)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, suggestions)
+}
+
+// ExportFriendCSV exports every transaction shared with one person across all
+// common groups. It takes the same locale, delimiter and bom options as the
+// group export.
+func (h *Handlers) ExportFriendCSV(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	friendID, err := parseID(r.URL.Query().Get("friend"), "friend")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	opts, err := parseCSVExportOptions(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	body, err := h.newExportBody(w, opts)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.userService.RequireVerifiedEmail(r.Context(), userID, getEmailVerified(r), "exporting data"); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	friend, transactions, err := h.friendService.GetSharedTransactions(r.Context(), userID, friendID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment;filename=friend_export.csv")
+
+	if opts.bom {
+		if _, err := body.Write(utf8BOM); err != nil {
+			return
+		}
+	}
+
+	writer := csv.NewWriter(body)
+	writer.Comma = opts.delimiter
+	writer.UseCRLF = true
+
+	header := []string{"Date", "Group", "Description", "Category", "Currency", "Cost", "Your Share", "Share: " + friend.Name, "Net"}
+	if err := writer.Write(header); err != nil {
+		handleError(w, r, apperrors.InternalError(err))
+		return
+	}
+
+	for _, t := range transactions {
+		record := []string{
+			t.Date,
+			t.GroupName,
+			t.Description,
+			string(t.Category),
+			t.Currency,
+			opts.format.FormatAmount(t.TotalAmount),
+			opts.format.FormatAmount(t.UserShare),
+			opts.format.FormatAmount(t.FriendShare),
+			opts.format.FormatAmount(t.Net),
+		}
+		if err := writer.Write(record); err != nil {
+			handleError(w, r, apperrors.InternalError(err))
+			return
+		}
+	}
+
+	writer.Flush()
+	if writer.Error() == nil {
+		body.Close()
+	}
+}
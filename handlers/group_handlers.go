@@ -2,26 +2,32 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	apperrors "unwise-backend/errors"
 	"unwise-backend/models"
 	"unwise-backend/services"
 
 	"encoding/csv"
-	"strconv"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type CreateGroupRequest struct {
-	Name         string           `json:"name"`
-	Type         models.GroupType `json:"type"`
-	MemberEmails []string         `json:"member_emails"`
+	Name         string                  `json:"name"`
+	Type         models.GroupType        `json:"type"`
+	MemberEmails []string                `json:"member_emails"`
+	TemplateID   string                  `json:"template_id"`
+	Locale       string                  `json:"locale"`
+	Placeholders []string                `json:"placeholders"`
+	Expenses     []models.InitialExpense `json:"expenses"`
+	Sandbox      bool                    `json:"is_sandbox"`
 }
 
 type UpdateGroupRequest struct {
@@ -36,42 +42,143 @@ type AddPlaceholderMemberRequest struct {
 	Name string `json:"name"`
 }
 
+type BackchargeMemberRequest struct {
+	ExpenseIDs []string `json:"expense_ids"`
+}
+
+type UpdateGroupLimitsRequest struct {
+	MaxExpenseAmount *float64 `json:"max_expense_amount"`
+	MaxDailyExpenses *int     `json:"max_daily_expenses"`
+	LimitAction      string   `json:"limit_action"`
+}
+
 type UpdateDefaultCurrencyRequest struct {
 	Currency string `json:"currency"`
 }
 
+type UpdateDefaultLanguageRequest struct {
+	Language string `json:"default_language"`
+}
+
+type UpdateTaxPresetRequest struct {
+	TaxPreset models.TaxPreset `json:"tax_preset"`
+}
+
+type UpdateExpenseEditPolicyRequest struct {
+	Policy string `json:"expense_edit_policy"`
+}
+
+type UpdateSettlementRoundingRequest struct {
+	Increment *int `json:"settlement_rounding"`
+}
+
+type UpdateSettlementAlgorithmRequest struct {
+	Algorithm models.SettlementAlgorithm `json:"settlement_algorithm"`
+}
+
 func (h *Handlers) GetGroups(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groups, err := h.groupService.GetByUserIDWithBalances(r.Context(), userID)
+	includeArchived := false
+	if value := r.URL.Query().Get("archived"); value != "" {
+		if includeArchived, err = strconv.ParseBool(value); err != nil {
+			handleError(w, r, apperrors.InvalidRequest("archived must be true or false."))
+			return
+		}
+	}
+
+	groups, err := h.groupService.GetByUserIDWithBalances(r.Context(), userID, includeArchived)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, groups)
 }
 
+func (h *Handlers) ArchiveGroup(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.groupService.Archive(r.Context(), groupID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Group archived"})
+}
+
+func (h *Handlers) UnarchiveGroup(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.groupService.Unarchive(r.Context(), groupID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Group unarchived"})
+}
+
+func (h *Handlers) DismissArchiveSuggestion(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.groupService.DismissArchiveSuggestion(r.Context(), groupID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Archive suggestion dismissed"})
+}
+
 func (h *Handlers) GetGroup(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	group, err := h.groupService.GetByID(r.Context(), groupID, userID)
+	group, err := h.groupService.GetByID(r.Context(), groupID, userID, parseMemberSort(r))
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -81,36 +188,55 @@ func (h *Handlers) GetGroup(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) CreateGroup(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	var req CreateGroupRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
-	name := strings.TrimSpace(req.Name)
+	name := services.NormalizeName(req.Name)
 	if name == "" {
-		handleError(w, apperrors.MissingRequiredField("Group name"))
+		handleError(w, r, apperrors.MissingRequiredField("Group name"))
 		return
 	}
-	if len(name) < services.MinGroupNameLength || len(name) > services.MaxGroupNameLength {
-		handleError(w, apperrors.InvalidRequest(fmt.Sprintf("Group name must be between %d and %d characters.", services.MinGroupNameLength, services.MaxGroupNameLength)))
+	if !services.ValidNameLength(name) {
+		handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Group name must be between %d and %d characters.", services.MinGroupNameLength, services.MaxGroupNameLength)))
 		return
 	}
 
 	groupType := models.GroupType(strings.ToUpper(string(req.Type)))
 	switch groupType {
-	case models.GroupTypeTrip, models.GroupTypeHome, models.GroupTypeCouple, models.GroupTypeOther:
+	case "", models.GroupTypeTrip, models.GroupTypeHome, models.GroupTypeCouple, models.GroupTypeOther:
 	default:
 		groupType = models.GroupTypeOther
 	}
 
-	group, err := h.groupService.Create(r.Context(), userID, name, groupType, req.MemberEmails)
+	locale := strings.TrimSpace(req.Locale)
+	if locale == "" {
+		locale = headerLocale(r)
+	}
+
+	for _, placeholder := range req.Placeholders {
+		if !services.ValidNameLength(services.NormalizeName(placeholder)) {
+			handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Placeholder names must be between %d and %d characters.", services.MinGroupNameLength, services.MaxGroupNameLength)))
+			return
+		}
+	}
+
+	opts := models.CreateGroupOptions{
+		TemplateID:   strings.TrimSpace(req.TemplateID),
+		Locale:       strings.TrimSpace(locale),
+		Placeholders: req.Placeholders,
+		Expenses:     req.Expenses,
+		Sandbox:      req.Sandbox,
+	}
+	group, err := h.groupService.Create(r.Context(), userID, name, groupType, req.MemberEmails, opts)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -119,38 +245,46 @@ func (h *Handlers) CreateGroup(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusCreated, group)
 }
 
+func (h *Handlers) GetGroupTemplates(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.groupService.GetTemplates(r.Context()))
+}
+
+func (h *Handlers) GetTaxPresets(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.groupService.GetTaxPresets(r.Context()))
+}
+
 func (h *Handlers) UpdateGroup(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if _, err := uuid.Parse(groupID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Group ID format."))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	var req UpdateGroupRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
-	name := strings.TrimSpace(req.Name)
+	name := services.NormalizeName(req.Name)
 	if name == "" {
-		handleError(w, apperrors.MissingRequiredField("Group name"))
+		handleError(w, r, apperrors.MissingRequiredField("Group name"))
 		return
 	}
-	if len(name) < services.MinGroupNameLength || len(name) > services.MaxGroupNameLength {
-		handleError(w, apperrors.InvalidRequest(fmt.Sprintf("Group name must be between %d and %d characters.", services.MinGroupNameLength, services.MaxGroupNameLength)))
+	if !services.ValidNameLength(name) {
+		handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Group name must be between %d and %d characters.", services.MinGroupNameLength, services.MaxGroupNameLength)))
 		return
 	}
 
 	group, err := h.groupService.Update(r.Context(), groupID, userID, name)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -160,18 +294,18 @@ func (h *Handlers) UpdateGroup(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) DeleteGroup(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	if err := h.groupService.Delete(r.Context(), groupID, userID); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -181,29 +315,37 @@ func (h *Handlers) DeleteGroup(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) AddMember(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if _, err := uuid.Parse(groupID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Group ID format."))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	var req AddMemberRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
 	if strings.TrimSpace(req.Email) == "" {
-		handleError(w, apperrors.MissingRequiredField("Email"))
+		handleError(w, r, apperrors.MissingRequiredField("Email"))
 		return
 	}
 
-	if err := h.groupService.AddMember(r.Context(), groupID, userID, req.Email); err != nil {
-		handleError(w, err)
+	invite, err := h.groupService.AddMember(r.Context(), groupID, userID, req.Email)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	if invite != nil {
+		respondJSON(w, http.StatusAccepted, map[string]interface{}{
+			"message": "No account uses this email yet. They will join the group when they sign up.",
+			"invite":  invite,
+		})
 		return
 	}
 
@@ -215,33 +357,33 @@ func (h *Handlers) AddMember(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) AddPlaceholderMember(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
-	groupID := chi.URLParam(r, "groupID")
-	if _, err := uuid.Parse(groupID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Group ID format."))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	var req AddPlaceholderMemberRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
-	name := strings.TrimSpace(req.Name)
+	name := services.NormalizeName(req.Name)
 	if name == "" {
-		handleError(w, apperrors.MissingRequiredField("Name"))
+		handleError(w, r, apperrors.MissingRequiredField("Name"))
 		return
 	}
-	if len(name) < services.MinGroupNameLength || len(name) > services.MaxGroupNameLength {
-		handleError(w, apperrors.InvalidRequest(fmt.Sprintf("Name must be between %d and %d characters.", services.MinGroupNameLength, services.MaxGroupNameLength)))
+	if !services.ValidNameLength(name) {
+		handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Name must be between %d and %d characters.", services.MinGroupNameLength, services.MaxGroupNameLength)))
 		return
 	}
 
-	if err := h.groupService.AddPlaceholderMember(r.Context(), groupID, userID, req.Name); err != nil {
-		handleError(w, err)
+	if err := h.groupService.AddPlaceholderMember(r.Context(), groupID, userID, name); err != nil {
+		handleError(w, r, err)
 		return
 	}
 
@@ -253,226 +395,969 @@ func (h *Handlers) AddPlaceholderMember(w http.ResponseWriter, r *http.Request)
 func (h *Handlers) RemoveMember(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	memberID := chi.URLParam(r, "userID")
-
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	memberID, err := pathID(r, "userID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
-	if memberID == "" {
-		handleError(w, apperrors.MissingRequiredField("Member ID"))
+
+	if r.URL.Query().Get("keep_history") == "true" {
+		placeholder, err := h.groupService.ConvertMemberToPlaceholder(r.Context(), groupID, userID, memberID)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"message":     "Member removed; their history is kept under a placeholder",
+			"placeholder": placeholder,
+		})
 		return
 	}
 
 	if err := h.groupService.RemoveMember(r.Context(), groupID, userID, memberID); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Member removed successfully"})
 }
 
+func (h *Handlers) BackchargeMember(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	memberID, err := pathID(r, "userID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req BackchargeMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	result, err := h.groupService.BackchargeMember(r.Context(), groupID, userID, memberID, req.ExpenseIDs)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
 func (h *Handlers) GetTransactions(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	opts, err := parsePayloadOptions(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	formatter, err := h.moneyFormatter(r)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	page, err := h.groupService.GetTransactionPage(r.Context(), groupID, userID, filter)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	transactions, err := h.groupService.GetTransactions(r.Context(), groupID, userID)
+	transactions := page.Items
+	for i := range transactions {
+		h.signExpenseReceipt(r.Context(), &transactions[i].Expense, services.ReceiptURLExpiry)
+		opts.slimExpense(&transactions[i].Expense)
+		if formatter != nil {
+			formatTransaction(formatter, &transactions[i])
+		}
+	}
+
+	payload, err := opts.selectFields(transactions)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, apperrors.InternalError(err))
 		return
 	}
 
-	respondJSON(w, http.StatusOK, transactions)
+	if filter.GroupBy != "" {
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"items":    payload,
+			"sections": page.Sections,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, payload)
 }
 
 type SettleUpRequest struct {
-	PayerID    string  `json:"payer_id"`
-	ReceiverID string  `json:"receiver_id"`
-	Amount     float64 `json:"amount"`
+	PayerID    string                   `json:"payer_id"`
+	ReceiverID string                   `json:"receiver_id"`
+	Amount     float64                  `json:"amount"`
+	Method     *models.SettlementMethod `json:"method,omitempty"`
+	Reference  *string                  `json:"reference,omitempty"`
+	ProofPath  *string                  `json:"proof_path,omitempty"`
 }
 
 func (h *Handlers) SettleUp(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
-	groupID := chi.URLParam(r, "groupID")
-	if _, err := uuid.Parse(groupID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Group ID format."))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	var req SettleUpRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
 		return
 	}
 
-	if _, err := uuid.Parse(req.PayerID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Payer ID format."))
+	if req.PayerID, err = parseID(req.PayerID, "Payer ID"); err != nil {
+		handleError(w, r, err)
 		return
 	}
-	if _, err := uuid.Parse(req.ReceiverID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Receiver ID format."))
+	if req.ReceiverID, err = parseID(req.ReceiverID, "Receiver ID"); err != nil {
+		handleError(w, r, err)
 		return
 	}
 	if req.Amount <= 0 {
-		handleError(w, apperrors.InvalidAmount("Amount must be greater than zero."))
+		handleError(w, r, apperrors.InvalidAmount("Amount must be greater than zero."))
+		return
+	}
+
+	if req.Reference != nil {
+		reference := strings.TrimSpace(*req.Reference)
+		if len(reference) > services.MaxSettlementReferenceLength {
+			handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Reference must be at most %d characters.", services.MaxSettlementReferenceLength)))
+			return
+		}
+		req.Reference = &reference
+		if reference == "" {
+			req.Reference = nil
+		}
+	}
+
+	proofPath, err := h.receiptImagePath(userID, groupID, req.ProofPath, nil)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	expense, err := h.groupService.CreateSettlement(r.Context(), groupID, userID, req.PayerID, req.ReceiverID, req.Amount)
+	details := models.SettlementDetails{
+		Method:    req.Method,
+		Reference: req.Reference,
+		ProofPath: proofPath,
+	}
+
+	expense, err := h.groupService.CreateSettlement(r.Context(), groupID, userID, req.PayerID, req.ReceiverID, req.Amount, details)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
+	h.signExpenseReceipt(r.Context(), expense, services.ReceiptURLExpiry)
 	respondJSON(w, http.StatusCreated, expense)
 }
 
-func (h *Handlers) GetSettlements(w http.ResponseWriter, r *http.Request) {
+type ReverseSettlementRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *Handlers) ReverseSettlement(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
+		return
+	}
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	var req ReverseSettlementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if len(strings.TrimSpace(req.Reason)) > services.MaxReversalReasonLength {
+		handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Reason must be at most %d characters.", services.MaxReversalReasonLength)))
 		return
 	}
 
-	settlements, err := h.settlementService.CalculateSettlements(r.Context(), groupID, userID)
+	reversal, err := h.groupService.ReverseSettlement(r.Context(), groupID, userID, expenseID, req.Reason)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, settlements)
+	respondJSON(w, http.StatusCreated, reversal)
 }
 
-func (h *Handlers) GetBalances(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetSettlementHistory(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
+		return
+	}
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	history, err := h.groupService.GetSettlementHistory(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	balances, err := h.groupService.GetBalancesEdgeList(r.Context(), groupID, userID)
+	for i := range history {
+		if proofUploadedBy(history[i].ProofPath, groupID, history[i].CreatedByID) {
+			history[i].ProofURL = h.signReceiptURL(r.Context(), history[i].ProofPath, services.ReceiptURLExpiry)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, history)
+}
+
+type CoverRequest struct {
+	PayerID          string  `json:"payer_id"`
+	BeneficiaryID    string  `json:"beneficiary_id"`
+	Amount           float64 `json:"amount"`
+	Note             string  `json:"note,omitempty"`
+	ConfirmOverLimit bool    `json:"confirm_over_limit,omitempty"`
+}
+
+func (h *Handlers) CoverExpense(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	groupID, err := pathID(r, "groupID")
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, balances)
+	var req CoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	if req.PayerID == "" {
+		req.PayerID = userID
+	}
+	if req.PayerID, err = parseID(req.PayerID, "Payer ID"); err != nil {
+		handleError(w, r, err)
+		return
+	}
+	if req.BeneficiaryID, err = parseID(req.BeneficiaryID, "Beneficiary ID"); err != nil {
+		handleError(w, r, err)
+		return
+	}
+	if req.Amount <= 0 {
+		handleError(w, r, apperrors.InvalidAmount("Amount must be greater than zero."))
+		return
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if note != "" && (len(note) < services.MinDescriptionLength || len(note) > services.MaxDescriptionLength) {
+		handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Note must be between %d and %d characters.", services.MinDescriptionLength, services.MaxDescriptionLength)))
+		return
+	}
+
+	expense, err := h.groupService.CreateCover(r.Context(), groupID, userID, req.PayerID, req.BeneficiaryID, req.Amount, note, req.ConfirmOverLimit)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, expense)
 }
 
-func (h *Handlers) ExportGroupCSV(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetSettlements(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	transactions, err := h.groupService.GetTransactions(r.Context(), groupID, userID)
+	asOf, err := parseAsOfParam(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/csv")
-	w.Header().Set("Content-Disposition", "attachment;filename=group_export.csv")
+	algorithm := models.SettlementAlgorithm(strings.ToUpper(r.URL.Query().Get("algorithm")))
+	settlements, err := h.settlementService.CalculateSettlementsWith(r.Context(), groupID, userID, asOf, algorithm)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
 
-	writer := csv.NewWriter(w)
-	defer writer.Flush()
+	respondJSON(w, http.StatusOK, settlements)
+}
 
-	header := []string{"Date", "Description", "Category", "Cost", "Paid By", "Your Share"}
-	if err := writer.Write(header); err != nil {
-		handleError(w, apperrors.InternalError(err))
+func (h *Handlers) GetBalances(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	for _, t := range transactions {
-		paidBy := "Unknown"
-		if t.PaidByUser != nil {
-			paidBy = t.PaidByUser.Name
-		}
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
 
-		record := []string{
-			t.Date,
-			t.Description,
-			string(t.Category),
-			strconv.FormatFloat(t.TotalAmount, 'f', 2, 64),
-			paidBy,
-			strconv.FormatFloat(t.UserShare, 'f', 2, 64),
-		}
-		if err := writer.Write(record); err != nil {
-			handleError(w, apperrors.InternalError(err))
-			return
+	asOf, err := parseAsOfParam(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	formatter, err := h.moneyFormatter(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	balances, err := h.groupService.GetBalancesEdgeList(r.Context(), groupID, userID, asOf)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if formatter != nil {
+		for i := range balances.Debts {
+			formatDebt(formatter, &balances.Debts[i])
 		}
 	}
+
+	respondJSON(w, http.StatusOK, balances)
 }
 
-func (h *Handlers) UpdateDefaultCurrency(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) ExportGroupCSV(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if groupID == "" {
-		handleError(w, apperrors.MissingRequiredField("Group ID"))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	var req UpdateDefaultCurrencyRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+	opts, err := parseCSVExportOptions(r)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	currency := strings.TrimSpace(strings.ToUpper(req.Currency))
-	if currency == "" {
-		handleError(w, apperrors.MissingRequiredField("Currency"))
+	body, err := h.newExportBody(w, opts)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	group, err := h.groupService.UpdateDefaultCurrency(r.Context(), groupID, userID, currency)
+	if err := h.userService.RequireVerifiedEmail(r.Context(), userID, getEmailVerified(r), "exporting data"); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	filter, err := parseTransactionFilter(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
+	filter.Limit, filter.Offset = 0, 0
 
-	zap.L().Info("Group default currency updated", zap.String("group_id", groupID), zap.String("currency", currency))
+	group, err := h.groupService.GetByID(r.Context(), groupID, userID, models.MemberSort{})
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
 
-	respondJSON(w, http.StatusOK, group)
+	transactions, err := h.groupService.GetTransactions(r.Context(), groupID, userID, filter)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment;filename=group_export.csv")
+
+	if opts.bom {
+		if _, err := body.Write(utf8BOM); err != nil {
+			return
+		}
+	}
+
+	receiptURL := func(t models.Transaction) string {
+		if signed := h.signReceiptURL(r.Context(), t.ReceiptImagePath, services.ReceiptExportURLExpiry); signed != nil {
+			return *signed
+		}
+		return ""
+	}
+	labels := csvLabelsFor(group.DefaultLanguage)
+	if err := writeGroupCSV(body, opts, labels, groupExportPayers(group.Members, transactions, labels), transactions, receiptURL); err == nil {
+		body.Close()
+	}
+}
+
+// groupExportPayers lists the members who paid for something, in member
+// order, then payers who have since left the group.
+func groupExportPayers(members []models.User, transactions []models.Transaction, labels csvLabels) []ledgerMember {
+	paid := make(map[string]bool)
+	for _, t := range transactions {
+		for _, p := range t.Payers {
+			paid[p.UserID] = true
+		}
+	}
+
+	var payers []ledgerMember
+	for _, m := range members {
+		if paid[m.ID] {
+			payers = append(payers, ledgerMember{ID: m.ID, Name: m.Name})
+			delete(paid, m.ID)
+		}
+	}
+	for _, t := range transactions {
+		for _, p := range t.Payers {
+			if paid[p.UserID] {
+				payers = append(payers, ledgerMember{ID: p.UserID, Name: labels.formerMember})
+				delete(paid, p.UserID)
+			}
+		}
+	}
+	return payers
+}
+
+func writeGroupCSV(w io.Writer, opts csvExportOptions, labels csvLabels, payers []ledgerMember, transactions []models.Transaction, receiptURL func(models.Transaction) string) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = opts.delimiter
+	writer.UseCRLF = true
+
+	header := []string{labels.date, labels.description, labels.category, labels.currency, labels.cost, labels.paidBy, labels.yourShare}
+	for _, payer := range payers {
+		header = append(header, labels.paid+payer.Name)
+	}
+	header = append(header, labels.tags, labels.receipt)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, t := range transactions {
+		paidBy := labels.unknown
+		if t.PaidByUser != nil {
+			paidBy = t.PaidByUser.Name
+		}
+
+		record := []string{
+			t.Date,
+			t.Description,
+			string(t.Category),
+			t.Currency,
+			opts.format.FormatAmount(t.TotalAmount),
+			paidBy,
+			opts.format.FormatAmount(t.UserShare),
+		}
+		for _, payer := range payers {
+			amountPaid := 0.0
+			for _, p := range t.Payers {
+				if p.UserID == payer.ID {
+					amountPaid += p.AmountPaid
+				}
+			}
+			record = append(record, opts.format.FormatAmount(amountPaid))
+		}
+		record = append(record, strings.Join(t.Tags, ";"), receiptURL(t))
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (h *Handlers) UpdateDefaultCurrency(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateDefaultCurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	currency := strings.TrimSpace(strings.ToUpper(req.Currency))
+	if currency == "" {
+		handleError(w, r, apperrors.MissingRequiredField("Currency"))
+		return
+	}
+
+	group, err := h.groupService.UpdateDefaultCurrency(r.Context(), groupID, userID, currency)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	zap.L().Info("Group default currency updated", zap.String("group_id", groupID), zap.String("currency", currency))
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+func (h *Handlers) UpdateDefaultLanguage(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateDefaultLanguageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	group, err := h.groupService.UpdateDefaultLanguage(r.Context(), groupID, userID, req.Language)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	zap.L().Info("Group default language updated", zap.String("group_id", groupID), zap.String("language", group.DefaultLanguage))
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+func (h *Handlers) UpdateTaxPreset(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateTaxPresetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	group, err := h.groupService.UpdateTaxPreset(r.Context(), groupID, userID, req.TaxPreset)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	zap.L().Info("Group tax preset updated", zap.String("group_id", groupID), zap.String("tax_preset", string(group.TaxPreset)))
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+func (h *Handlers) UpdateExpenseEditPolicy(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateExpenseEditPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	policy := models.ExpenseEditPolicy(strings.ToUpper(strings.TrimSpace(req.Policy)))
+	if policy == "" {
+		handleError(w, r, apperrors.MissingRequiredField("expense_edit_policy"))
+		return
+	}
+
+	group, err := h.groupService.UpdateExpenseEditPolicy(r.Context(), groupID, userID, policy)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	zap.L().Info("Group expense edit policy updated", zap.String("group_id", groupID), zap.String("policy", string(policy)))
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+func (h *Handlers) UpdateSettlementRounding(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateSettlementRoundingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.Increment == nil {
+		handleError(w, r, apperrors.MissingRequiredField("settlement_rounding"))
+		return
+	}
+
+	group, err := h.groupService.UpdateSettlementRounding(r.Context(), groupID, userID, *req.Increment)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	zap.L().Info("Group settlement rounding updated", zap.String("group_id", groupID), zap.Int("increment", *req.Increment))
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+func (h *Handlers) GetGroupLimits(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	limits, err := h.groupService.GetLimits(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limits)
+}
+
+func (h *Handlers) UpdateSettlementAlgorithm(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateSettlementAlgorithmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.Algorithm == "" {
+		handleError(w, r, apperrors.MissingRequiredField("settlement_algorithm"))
+		return
+	}
+
+	group, err := h.groupService.UpdateSettlementAlgorithm(r.Context(), groupID, userID, req.Algorithm)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	zap.L().Info("Group settlement algorithm updated", zap.String("group_id", groupID), zap.String("algorithm", string(group.SettlementAlgorithm)))
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+func (h *Handlers) GetGroupUsage(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	usage, err := h.groupService.GetUsage(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, usage)
+}
+
+func (h *Handlers) UpdateGroupLimits(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateGroupLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	limits, err := h.groupService.UpdateLimits(r.Context(), groupID, userID, &models.GroupLimits{
+		MaxExpenseAmount: req.MaxExpenseAmount,
+		MaxDailyExpenses: req.MaxDailyExpenses,
+		Action:           models.GroupLimitAction(strings.ToUpper(strings.TrimSpace(req.LimitAction))),
+	})
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	zap.L().Info("Group limits updated", zap.String("group_id", groupID), zap.String("user_id", userID))
+
+	respondJSON(w, http.StatusOK, limits)
+}
+
+func (h *Handlers) GetGroupActivity(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	activity, err := h.groupService.GetActivity(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, activity)
+}
+
+func (h *Handlers) GetGroupReceipts(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := parseIntParam(query.Get("limit"))
+	if err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid limit. Must be a number."))
+		return
+	}
+	offset, err := parseIntParam(query.Get("offset"))
+	if err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid offset. Must be a number."))
+		return
+	}
+
+	page, err := h.groupService.GetReceipts(r.Context(), groupID, userID, limit, offset)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	for i := range page.Items {
+		page.Items[i].ImageURL = h.signReceiptURL(r.Context(), &page.Items[i].ImagePath, services.ReceiptURLExpiry)
+	}
+
+	respondJSON(w, http.StatusOK, page)
+}
+
+func (h *Handlers) GetGroupCurrencies(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	overview, err := h.groupService.GetCurrencyOverview(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, overview)
+}
+
+func parseTransactionFilter(r *http.Request) (models.TransactionFilter, error) {
+	var filter models.TransactionFilter
+	query := r.URL.Query()
+	for _, value := range query["tag"] {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+
+	if event := query.Get("event"); event != "" {
+		id, err := parseID(event, "event")
+		if err != nil {
+			return filter, err
+		}
+		filter.EventID = id
+	}
+
+	filter.Sort = models.TransactionSort{
+		Field: models.TransactionSortField(strings.ToLower(strings.TrimSpace(query.Get("sort")))),
+		Order: models.SortOrder(strings.ToLower(strings.TrimSpace(query.Get("order")))),
+	}
+	filter.GroupBy = models.TransactionGroupBy(strings.ToLower(strings.TrimSpace(query.Get("group_by"))))
+
+	var err error
+	if filter.Limit, err = parseIntParam(query.Get("limit")); err != nil {
+		return filter, apperrors.InvalidRequest("Invalid limit. Must be a number.")
+	}
+	if filter.Offset, err = parseIntParam(query.Get("offset")); err != nil {
+		return filter, apperrors.InvalidRequest("Invalid offset. Must be a number.")
+	}
+	return filter, nil
+}
+
+func parseMemberSort(r *http.Request) models.MemberSort {
+	query := r.URL.Query()
+	return models.MemberSort{
+		Field: models.MemberSortField(strings.ToLower(strings.TrimSpace(query.Get("member_sort")))),
+		Order: models.SortOrder(strings.ToLower(strings.TrimSpace(query.Get("member_order")))),
+	}
+}
+
+func parseIntParam(value string) (int, error) {
+	if value = strings.TrimSpace(value); value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func parseAsOfParam(r *http.Request) (*time.Time, error) {
+	value := strings.TrimSpace(r.URL.Query().Get("as_of"))
+	if value == "" {
+		return nil, nil
+	}
+	asOf, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, apperrors.InvalidRequest("Invalid as_of date. Use YYYY-MM-DD.")
+	}
+	return &asOf, nil
 }
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/middleware"
+	"unwise-backend/models"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type removingGroupService struct {
+	services.GroupService
+	removed   []string
+	converted []string
+}
+
+func (s *removingGroupService) RemoveMember(ctx context.Context, groupID, userID, memberToRemoveID string) error {
+	s.removed = append(s.removed, memberToRemoveID)
+	return nil
+}
+
+func (s *removingGroupService) ConvertMemberToPlaceholder(ctx context.Context, groupID, userID, memberToRemoveID string) (*models.User, error) {
+	s.converted = append(s.converted, memberToRemoveID)
+	return &models.User{ID: "placeholder", Name: "Bob", IsPlaceholder: true}, nil
+}
+
+func TestRemoveMemberKeepHistory(t *testing.T) {
+	const (
+		groupID  = "11111111-1111-1111-1111-111111111111"
+		memberID = "22222222-2222-2222-2222-222222222222"
+	)
+
+	tests := []struct {
+		name              string
+		query             string
+		expectRemoved     int
+		expectConverted   int
+		expectPlaceholder bool
+	}{
+		{name: "Plain Removal", query: "", expectRemoved: 1},
+		{name: "Keep History", query: "?keep_history=true", expectConverted: 1, expectPlaceholder: true},
+		{name: "Keep History Off", query: "?keep_history=false", expectRemoved: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := &removingGroupService{}
+			h := &Handlers{groupService: groups}
+
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("groupID", groupID)
+			routeCtx.URLParams.Add("userID", memberID)
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, routeCtx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, "alice")
+			req := httptest.NewRequest(http.MethodDelete, "/api/groups/"+groupID+"/members/"+memberID+tt.query, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+			h.RemoveMember(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("RemoveMember() status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body)
+			}
+			if len(groups.removed) != tt.expectRemoved || len(groups.converted) != tt.expectConverted {
+				t.Errorf("RemoveMember() removed %v and converted %v, expected %d and %d", groups.removed, groups.converted, tt.expectRemoved, tt.expectConverted)
+			}
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if _, ok := body["placeholder"]; ok != tt.expectPlaceholder {
+				t.Errorf("RemoveMember() body = %s, expected placeholder present = %v", rec.Body, tt.expectPlaceholder)
+			}
+		})
+	}
+}
+
+type asOfSettlementService struct {
+	services.SettlementService
+	asOf []*time.Time
+}
+
+func (s *asOfSettlementService) CalculateSettlementsWith(ctx context.Context, groupID, userID string, asOf *time.Time, algorithm models.SettlementAlgorithm) ([]models.Settlement, error) {
+	s.asOf = append(s.asOf, asOf)
+	return []models.Settlement{}, nil
+}
+
+type asOfGroupService struct {
+	services.GroupService
+	asOf []*time.Time
+}
+
+func (s *asOfGroupService) GetBalancesEdgeList(ctx context.Context, groupID, userID string, asOf *time.Time) (*models.GroupBalancesEdgeResponse, error) {
+	s.asOf = append(s.asOf, asOf)
+	return &models.GroupBalancesEdgeResponse{Debts: []models.DebtEdge{}}, nil
+}
+
+func TestBalanceRoutesParseAsOf(t *testing.T) {
+	const groupID = "11111111-1111-1111-1111-111111111111"
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedAsOf string
+	}{
+		{name: "Valid Date", query: "?as_of=2024-05-31", expectedCode: http.StatusOK, expectedAsOf: "2024-05-31"},
+		{name: "No Date", query: "", expectedCode: http.StatusOK},
+		{name: "Day First", query: "?as_of=31-05-2024", expectedCode: http.StatusBadRequest},
+		{name: "Impossible Date", query: "?as_of=2024-02-30", expectedCode: http.StatusBadRequest},
+		{name: "Timestamp", query: "?as_of=2024-05-31T10:00:00Z", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		for _, route := range []string{"settlements", "balances"} {
+			t.Run(tt.name+"/"+route, func(t *testing.T) {
+				settlements := &asOfSettlementService{}
+				groups := &asOfGroupService{}
+				h := &Handlers{settlementService: settlements, groupService: groups}
+
+				routeCtx := chi.NewRouteContext()
+				routeCtx.URLParams.Add("groupID", groupID)
+				ctx := context.WithValue(context.Background(), chi.RouteCtxKey, routeCtx)
+				ctx = context.WithValue(ctx, middleware.UserIDKey, "alice")
+				req := httptest.NewRequest(http.MethodGet, "/api/groups/"+groupID+"/"+route+tt.query, nil).WithContext(ctx)
+				rec := httptest.NewRecorder()
+				asOf := &settlements.asOf
+				if route == "balances" {
+					h.GetBalances(rec, req)
+					asOf = &groups.asOf
+				} else {
+					h.GetSettlements(rec, req)
+				}
+
+				if rec.Code != tt.expectedCode {
+					t.Fatalf("status = %d, expected %d: %s", rec.Code, tt.expectedCode, rec.Body)
+				}
+				if tt.expectedCode != http.StatusOK {
+					var body ErrorResponse
+					if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+						t.Fatalf("decoding body: %v", err)
+					}
+					if body.Code != string(apperrors.CodeInvalidRequest) || len(*asOf) != 0 {
+						t.Errorf("code = %s after %d service calls, expected %s before any", body.Code, len(*asOf), apperrors.CodeInvalidRequest)
+					}
+					return
+				}
+				if len(*asOf) != 1 {
+					t.Fatalf("service called %d times, expected 1", len(*asOf))
+				}
+				got := ""
+				if (*asOf)[0] != nil {
+					got = (*asOf)[0].Format("2006-01-02")
+				}
+				if got != tt.expectedAsOf {
+					t.Errorf("service asked as of %q, expected %q", got, tt.expectedAsOf)
+				}
+			})
+		}
+	}
+}
@@ -15,9 +15,10 @@ import (
 )
 
 type ErrorResponse struct {
-	Error   string `json:"error,omitempty"`
-	Code    string `json:"code,omitempty"`
-	Details string `json:"details,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Details string      `json:"details,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
 }
 
 type Handlers struct {
@@ -30,6 +31,9 @@ type Handlers struct {
 	explanationService services.ExplanationService
 	friendService      services.FriendService
 	commentService     services.CommentService
+	quotaService       services.QuotaService
+	exportSigner       services.ExportSigner
+	formatService      services.CurrencyFormatService
 	storageService     storage.Storage
 	storageBucket      string
 	groupPhotosBucket  string
@@ -46,6 +50,9 @@ func NewHandlers(
 	explanationService services.ExplanationService,
 	friendService services.FriendService,
 	commentService services.CommentService,
+	quotaService services.QuotaService,
+	exportSigner services.ExportSigner,
+	formatService services.CurrencyFormatService,
 	storageService storage.Storage,
 	storageBucket string,
 	groupPhotosBucket string,
@@ -61,6 +68,9 @@ func NewHandlers(
 		explanationService: explanationService,
 		friendService:      friendService,
 		commentService:     commentService,
+		quotaService:       quotaService,
+		exportSigner:       exportSigner,
+		formatService:      formatService,
 		storageService:     storageService,
 		storageBucket:      storageBucket,
 		groupPhotosBucket:  groupPhotosBucket,
@@ -70,12 +80,16 @@ func NewHandlers(
 
 func (h *Handlers) RegisterRoutes(r chi.Router) {
 	r.Get("/dashboard", h.GetDashboard)
+	r.Get("/group-templates", h.GetGroupTemplates)
+	r.Get("/tax-presets", h.GetTaxPresets)
 
 	r.Route("/friends", func(r chi.Router) {
 		r.Get("/", h.GetFriends)
 		r.Get("/search", h.SearchPotentialFriends)
+		r.With(middleware.LimitByUser("contact-suggestions", services.ContactSuggestRateLimit, services.ContactSuggestRateBurst)).Post("/suggestions", h.SuggestFriendsFromContacts)
 		r.Post("/", h.AddFriend)
 		r.Delete("/{friendID}", h.RemoveFriend)
+		r.Get("/{friendID}/balance-history", h.GetFriendBalanceHistory)
 	})
 
 	r.Route("/groups", func(r chi.Router) {
@@ -84,16 +98,35 @@ func (h *Handlers) RegisterRoutes(r chi.Router) {
 		r.Get("/{groupID}", h.GetGroup)
 		r.Put("/{groupID}", h.UpdateGroup)
 		r.Delete("/{groupID}", h.DeleteGroup)
+		r.Post("/{groupID}/archive", h.ArchiveGroup)
+		r.Delete("/{groupID}/archive", h.UnarchiveGroup)
+		r.Post("/{groupID}/archive-suggestion/dismiss", h.DismissArchiveSuggestion)
 		r.Put("/{groupID}/currency", h.UpdateDefaultCurrency)
+		r.Put("/{groupID}/language", h.UpdateDefaultLanguage)
+		r.Put("/{groupID}/tax-preset", h.UpdateTaxPreset)
+		r.Put("/{groupID}/edit-policy", h.UpdateExpenseEditPolicy)
+		r.Put("/{groupID}/settlement-rounding", h.UpdateSettlementRounding)
+		r.Put("/{groupID}/settlement-algorithm", h.UpdateSettlementAlgorithm)
+		r.Get("/{groupID}/limits", h.GetGroupLimits)
+		r.Put("/{groupID}/limits", h.UpdateGroupLimits)
+		r.Get("/{groupID}/usage", h.GetGroupUsage)
+		r.Get("/{groupID}/activity", h.GetGroupActivity)
 		r.Post("/{groupID}/members", h.AddMember)
 		r.Post("/{groupID}/placeholders", h.AddPlaceholderMember)
 		r.Delete("/{groupID}/members/{userID}", h.RemoveMember)
+		r.Post("/{groupID}/members/{userID}/backcharge", h.BackchargeMember)
 		r.Get("/{groupID}/expenses", h.GetExpenses)
 		r.Get("/{groupID}/transactions", h.GetTransactions)
-		r.Get("/{groupID}/export", h.ExportGroupCSV)
-		r.Get("/{groupID}/balances", h.GetBalances)
+		r.Get("/{groupID}/receipts", h.GetGroupReceipts)
+		r.Get("/{groupID}/currencies", h.GetGroupCurrencies)
+		r.With(middleware.LimitByUser("export", services.ExportRateLimit, services.ExportRateBurst), middleware.RouteTimeout("export", services.ExportRequestTimeout)).Get("/{groupID}/export", h.ExportGroupCSV)
+		r.With(middleware.LimitByUser("export", services.ExportRateLimit, services.ExportRateBurst), middleware.RouteTimeout("export", services.ExportRequestTimeout)).Get("/{groupID}/export/{format}", h.ExportGroupAccounting)
+		r.With(middleware.RouteTimeout("balances", services.BalanceRequestTimeout)).Get("/{groupID}/balances", h.GetBalances)
 		r.Post("/{groupID}/settle", h.SettleUp)
+		r.Post("/{groupID}/cover", h.CoverExpense)
 		r.Get("/{groupID}/settlements", h.GetSettlements)
+		r.Get("/{groupID}/settlements/history", h.GetSettlementHistory)
+		r.Post("/{groupID}/settlements/{expenseID}/reverse", h.ReverseSettlement)
 		r.Post("/{groupID}/avatar", h.UploadGroupAvatar)
 	})
 
@@ -102,6 +135,12 @@ func (h *Handlers) RegisterRoutes(r chi.Router) {
 		r.Get("/{expenseID}", h.GetExpense)
 		r.Put("/{expenseID}", h.UpdateExpense)
 		r.Delete("/{expenseID}", h.DeleteExpense)
+		r.Post("/{expenseID}/refunds", h.CreateRefund)
+		r.Post("/{expenseID}/settle", h.SettleExpense)
+		r.Post("/{expenseID}/exclusion", h.RequestExclusion)
+		r.Post("/{expenseID}/exclusions/{userID}/accept", h.AcceptExclusion)
+		r.Post("/{expenseID}/exclusions/{userID}/reject", h.RejectExclusion)
+		r.Put("/{expenseID}/split-lock", h.SetSplitLock)
 		r.Get("/{expenseID}/comments", h.GetComments)
 		r.Post("/{expenseID}/comments", h.CreateComment)
 		r.Delete("/{expenseID}/comments/{commentID}", h.DeleteComment)
@@ -109,20 +148,37 @@ func (h *Handlers) RegisterRoutes(r chi.Router) {
 		r.Delete("/{expenseID}/comments/{commentID}/reactions", h.RemoveReaction)
 	})
 
+	r.Route("/expense-changes", func(r chi.Router) {
+		r.Get("/", h.GetPendingChanges)
+		r.Post("/{changeID}/accept", h.AcceptChange)
+		r.Post("/{changeID}/reject", h.RejectChange)
+	})
+
 	r.Route("/user", func(r chi.Router) {
 		r.Get("/me", h.GetCurrentUser)
+		r.Post("/bootstrap", h.BootstrapUser)
 		r.Post("/avatar", h.UploadUserAvatar)
+		r.With(middleware.LimitByUser("export", services.ExportRateLimit, services.ExportRateBurst), middleware.RouteTimeout("export", services.ExportRequestTimeout)).Get("/export.csv", h.ExportFriendCSV)
 		r.Delete("/me", h.DeleteAccount)
+		r.Get("/deletion-blockers", h.GetDeletionBlockers)
+		r.Get("/limits", h.GetUserLimits)
+		r.Get("/privacy", h.GetPrivacySettings)
+		r.Put("/privacy", h.UpdatePrivacySettings)
+		r.Get("/report-settings", h.GetReportSettings)
+		r.Put("/report-settings", h.UpdateReportSettings)
+		r.Get("/balance-alert", h.GetBalanceAlertSettings)
+		r.Put("/balance-alert", h.UpdateBalanceAlertSettings)
 		r.Get("/placeholders", h.GetClaimablePlaceholders)
+		r.Get("/placeholder-suggestions", h.GetPlaceholderSuggestions)
+		r.Post("/placeholders/merge", h.MergePlaceholders)
 		r.Post("/placeholders/{placeholderID}/claim", h.ClaimPlaceholder)
-		r.Post("/placeholders/{placeholderID}/assign", h.AssignPlaceholder)
 	})
 }
 
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
+	if err := json.NewEncoder(w).Encode(normalizeJSON(data)); err != nil {
 		zap.L().Error("Failed to encode JSON response", zap.Error(err))
 	}
 }
@@ -134,11 +190,24 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	respondJSON(w, status, ErrorResponse{Error: message})
 }
 
-func handleError(w http.ResponseWriter, err error) {
+// handleError renders err in the language negotiated from the request's
+// Accept-Language header. Codes stay stable across languages so clients can
+// keep branching on them.
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
 	if err == nil {
 		return
 	}
 
+	lang := apperrors.MatchLanguage(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+
+	// Whatever failed after the budget ran out (usually a cancelled query)
+	// is reported as the timeout it really is.
+	if middleware.IsLatencyBudgetExceeded(r.Context()) {
+		err = apperrors.RequestTimeout()
+	}
+
 	if appErr, ok := apperrors.AsAppError(err); ok {
 		status := apperrors.GetHTTPStatus(appErr.Type)
 
@@ -152,10 +221,12 @@ func handleError(w http.ResponseWriter, err error) {
 				zap.String("message", appErr.Message))
 		}
 
+		message, details := appErr.Localize(lang)
 		respondJSON(w, status, ErrorResponse{
-			Error:   appErr.Message,
+			Error:   message,
 			Code:    string(appErr.Code),
-			Details: appErr.Details,
+			Details: details,
+			Data:    appErr.Data,
 		})
 		return
 	}
@@ -165,7 +236,7 @@ func handleError(w http.ResponseWriter, err error) {
 		zap.String("error_type", fmt.Sprintf("%T", err)))
 
 	respondJSON(w, http.StatusInternalServerError, ErrorResponse{
-		Error: "An unexpected error occurred. Please try again later.",
+		Error: apperrors.Translate(lang, apperrors.KeyUnexpectedError, apperrors.UnexpectedErrorMessage),
 		Code:  string(apperrors.CodeInternalError),
 	})
 }
@@ -190,3 +261,13 @@ func getUserName(r *http.Request) (string, error) {
 	name, _ := middleware.GetUserName(r.Context())
 	return name, nil
 }
+
+// getEmailVerified returns the token's email verification flag, or nil when
+// the token did not carry one.
+func getEmailVerified(r *http.Request) *bool {
+	verified, ok := middleware.GetEmailVerified(r.Context())
+	if !ok {
+		return nil
+	}
+	return &verified
+}
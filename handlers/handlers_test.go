@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/middleware"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type unverifiedUserService struct {
+	services.UserService
+	flags []*bool
+}
+
+func (s *unverifiedUserService) RequireVerifiedEmail(ctx context.Context, userID string, emailVerified *bool, action string) error {
+	s.flags = append(s.flags, emailVerified)
+	return apperrors.EmailNotVerified(action)
+}
+
+func TestVerifiedEmailRoutesReturnDedicatedCode(t *testing.T) {
+	const (
+		groupID       = "11111111-1111-1111-1111-111111111111"
+		placeholderID = "22222222-2222-2222-2222-222222222222"
+		friendID      = "33333333-3333-3333-3333-333333333333"
+	)
+
+	routes := []struct {
+		name    string
+		handler func(*Handlers) http.HandlerFunc
+		method  string
+		target  string
+		params  map[string]string
+		body    string
+	}{
+		{name: "Group Export", handler: func(h *Handlers) http.HandlerFunc { return h.ExportGroupCSV }, method: http.MethodGet, target: "/api/groups/" + groupID + "/export", params: map[string]string{"groupID": groupID}},
+		{name: "Accounting Export", handler: func(h *Handlers) http.HandlerFunc { return h.ExportGroupAccounting }, method: http.MethodGet, target: "/api/groups/" + groupID + "/export/qif", params: map[string]string{"groupID": groupID, "format": "qif"}},
+		{name: "Friend Export", handler: func(h *Handlers) http.HandlerFunc { return h.ExportFriendCSV }, method: http.MethodGet, target: "/api/user/export.csv?friend=" + friendID},
+		{name: "Claim", handler: func(h *Handlers) http.HandlerFunc { return h.ClaimPlaceholder }, method: http.MethodPost, target: "/api/user/placeholders/" + placeholderID + "/claim", params: map[string]string{"placeholderID": placeholderID}},
+		{name: "Merge", handler: func(h *Handlers) http.HandlerFunc { return h.MergePlaceholders }, method: http.MethodPost, target: "/api/user/placeholders/merge", body: `{"placeholder_ids": ["` + placeholderID + `"], "target_id": "` + friendID + `"}`},
+	}
+	claims := []struct {
+		name     string
+		verified *bool
+	}{
+		{name: "Unverified Claim", verified: new(bool)},
+		{name: "Missing Claim"},
+	}
+
+	for _, route := range routes {
+		for _, claim := range claims {
+			t.Run(route.name+"/"+claim.name, func(t *testing.T) {
+				users := &unverifiedUserService{}
+				h := &Handlers{userService: users}
+
+				routeCtx := chi.NewRouteContext()
+				for key, value := range route.params {
+					routeCtx.URLParams.Add(key, value)
+				}
+				ctx := context.WithValue(context.Background(), chi.RouteCtxKey, routeCtx)
+				ctx = context.WithValue(ctx, middleware.UserIDKey, "alice")
+				if claim.verified != nil {
+					ctx = context.WithValue(ctx, middleware.EmailVerifiedKey, *claim.verified)
+				}
+				req := httptest.NewRequest(route.method, route.target, strings.NewReader(route.body)).WithContext(ctx)
+				rec := httptest.NewRecorder()
+				route.handler(h)(rec, req)
+
+				var body ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decoding body: %v", err)
+				}
+				if rec.Code != http.StatusForbidden || body.Code != string(apperrors.CodeEmailNotVerified) {
+					t.Errorf("status = %d, code = %s, expected %d and %s", rec.Code, body.Code, http.StatusForbidden, apperrors.CodeEmailNotVerified)
+				}
+				if len(users.flags) != 1 || (users.flags[0] == nil) != (claim.verified == nil) {
+					t.Errorf("RequireVerifiedEmail() got flags %v, expected the token's claim %v", users.flags, claim.verified)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// pathID returns the named URL parameter (e.g. "groupID") in canonical UUID
+// form. Every ID taken from a path goes through here, so a malformed ID is
+// rejected with 400 before it reaches a service or a query.
+func pathID(r *http.Request, param string) (string, error) {
+	return parseID(chi.URLParam(r, param), idLabel(param))
+}
+
+// parseID validates an ID taken from a request body or query string. label
+// names the field in the error, e.g. "User ID" or "payer_id".
+func parseID(value, label string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", apperrors.MissingRequiredField(label)
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return "", apperrors.InvalidRequest("Invalid " + label + " format.")
+	}
+	return id.String(), nil
+}
+
+// parseOptionalID is parseID for a nullable body field. nil (absent) and ""
+// (cleared) are passed through for the service to interpret.
+func parseOptionalID(value *string, label string) (*string, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return value, nil
+	}
+	id, err := parseID(*value, label)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// idLabel turns a route parameter name like "placeholderID" into "Placeholder ID".
+func idLabel(param string) string {
+	name := strings.TrimSuffix(param, "ID")
+	if name == "" {
+		return "ID"
+	}
+	return strings.ToUpper(name[:1]) + name[1:] + " ID"
+}
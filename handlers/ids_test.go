@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "Canonical", value: "3f2b9c1e-7a4d-4e8b-9c2f-1d5e6a7b8c9d", expected: "3f2b9c1e-7a4d-4e8b-9c2f-1d5e6a7b8c9d"},
+		{name: "Upper case and padding", value: " 3F2B9C1E-7A4D-4E8B-9C2F-1D5E6A7B8C9D ", expected: "3f2b9c1e-7a4d-4e8b-9c2f-1d5e6a7b8c9d"},
+		{name: "Braces", value: "{3f2b9c1e-7a4d-4e8b-9c2f-1d5e6a7b8c9d}", expected: "3f2b9c1e-7a4d-4e8b-9c2f-1d5e6a7b8c9d"},
+		{name: "Empty", value: "  ", wantErr: true},
+		{name: "Not a UUID", value: "1 OR 1=1", wantErr: true},
+		{name: "Truncated", value: "3f2b9c1e-7a4d-4e8b-9c2f", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseID(tt.value, "Group ID")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("parseID() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseOptionalID(t *testing.T) {
+	empty, bad := "", "nope"
+	if got, err := parseOptionalID(nil, "event_id"); got != nil || err != nil {
+		t.Errorf("parseOptionalID(nil) = %v, %v, expected nil, nil", got, err)
+	}
+	if got, err := parseOptionalID(&empty, "event_id"); got == nil || *got != "" || err != nil {
+		t.Errorf("parseOptionalID(\"\") = %v, %v, expected the cleared value passed through", got, err)
+	}
+	if _, err := parseOptionalID(&bad, "event_id"); err == nil {
+		t.Errorf("parseOptionalID(%q) error = nil, expected a validation error", bad)
+	}
+}
+
+func TestIDLabel(t *testing.T) {
+	tests := map[string]string{
+		"groupID":       "Group ID",
+		"placeholderID": "Placeholder ID",
+		"ID":            "ID",
+	}
+	for param, expected := range tests {
+		if got := idLabel(param); got != expected {
+			t.Errorf("idLabel(%q) = %q, expected %q", param, got, expected)
+		}
+	}
+}
+
+// TestRouteIDsGoThroughPathID keeps IDs parsed in one place: a handler that
+// read an ID route parameter itself would hand an unchecked string on to the
+// services.
+func TestRouteIDsGoThroughPathID(t *testing.T) {
+	rawParam := regexp.MustCompile(`chi\.URLParam\([^,]+,\s*"\w*ID"\)`)
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if file == "ids.go" || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range rawParam.FindAll(src, -1) {
+			t.Errorf("%s reads %s directly; use pathID", file, match)
+		}
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"net/http"
 
 	apperrors "unwise-backend/errors"
+	"unwise-backend/middleware"
 	"unwise-backend/services"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -26,6 +26,8 @@ func NewImportHandlers(importService services.ImportService) *ImportHandlers {
 
 func (h *ImportHandlers) RegisterRoutes(r chi.Router) {
 	r.Route("/groups/{groupID}/import", func(r chi.Router) {
+		r.Use(middleware.LimitByUser("import", services.ImportRateLimit, services.ImportRateBurst))
+		r.Use(middleware.RouteTimeout("import", services.ImportRequestTimeout))
 		r.Post("/splitwise/preview", h.PreviewSplitwiseCSV)
 		r.Post("/splitwise", h.ImportSplitwiseCSV)
 	})
@@ -34,33 +36,33 @@ func (h *ImportHandlers) RegisterRoutes(r chi.Router) {
 func (h *ImportHandlers) PreviewSplitwiseCSV(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if _, err := uuid.Parse(groupID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Group ID format."))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
 
 	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
-		handleError(w, apperrors.InvalidRequest("File too large or invalid multipart form. Max size is 5MB."))
+		handleError(w, r, apperrors.InvalidRequest("File too large or invalid multipart form. Max size is 5MB."))
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		handleError(w, apperrors.MissingRequiredField("file"))
+		handleError(w, r, apperrors.MissingRequiredField("file"))
 		return
 	}
 	defer file.Close()
 
 	if header.Header.Get("Content-Type") != "text/csv" &&
 		!isCSVFilename(header.Filename) {
-		handleError(w, apperrors.InvalidRequest("File must be a CSV file."))
+		handleError(w, r, apperrors.InvalidRequest("File must be a CSV file."))
 		return
 	}
 
@@ -71,7 +73,7 @@ func (h *ImportHandlers) PreviewSplitwiseCSV(w http.ResponseWriter, r *http.Requ
 
 	result, err := h.importService.PreviewSplitwiseCSV(r.Context(), groupID, userID, file)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
@@ -81,45 +83,53 @@ func (h *ImportHandlers) PreviewSplitwiseCSV(w http.ResponseWriter, r *http.Requ
 func (h *ImportHandlers) ImportSplitwiseCSV(w http.ResponseWriter, r *http.Request) {
 	userID, err := getUserID(r)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	groupID := chi.URLParam(r, "groupID")
-	if _, err := uuid.Parse(groupID); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid Group ID format."))
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
 	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
 
 	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
-		handleError(w, apperrors.InvalidRequest("File too large or invalid multipart form. Max size is 5MB."))
+		handleError(w, r, apperrors.InvalidRequest("File too large or invalid multipart form. Max size is 5MB."))
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		handleError(w, apperrors.MissingRequiredField("file"))
+		handleError(w, r, apperrors.MissingRequiredField("file"))
 		return
 	}
 	defer file.Close()
 
 	if header.Header.Get("Content-Type") != "text/csv" &&
 		!isCSVFilename(header.Filename) {
-		handleError(w, apperrors.InvalidRequest("File must be a CSV file."))
+		handleError(w, r, apperrors.InvalidRequest("File must be a CSV file."))
 		return
 	}
 
-	mappingJSON := r.FormValue("member_mapping")
-	if mappingJSON == "" {
-		handleError(w, apperrors.MissingRequiredField("member_mapping"))
-		return
+	// member_mapping may be left out when an earlier import into the group
+	// already mapped every member.
+	var memberMapping map[string]*string
+	if mappingJSON := r.FormValue("member_mapping"); mappingJSON != "" {
+		if err := json.Unmarshal([]byte(mappingJSON), &memberMapping); err != nil {
+			handleError(w, r, apperrors.InvalidRequest("Invalid member_mapping JSON format."))
+			return
+		}
 	}
 
-	var memberMapping map[string]*string
-	if err := json.Unmarshal([]byte(mappingJSON), &memberMapping); err != nil {
-		handleError(w, apperrors.InvalidRequest("Invalid member_mapping JSON format."))
+	var importDuplicates bool
+	switch r.FormValue("duplicates") {
+	case "", "skip":
+	case "import":
+		importDuplicates = true
+	default:
+		handleError(w, r, apperrors.InvalidRequest("duplicates must be 'skip' or 'import'."))
 		return
 	}
 
@@ -127,11 +137,12 @@ func (h *ImportHandlers) ImportSplitwiseCSV(w http.ResponseWriter, r *http.Reque
 		zap.String("group_id", groupID),
 		zap.String("filename", header.Filename),
 		zap.Int64("size", header.Size),
-		zap.Int("mappings", len(memberMapping)))
+		zap.Int("mappings", len(memberMapping)),
+		zap.Bool("import_duplicates", importDuplicates))
 
-	result, err := h.importService.ImportSplitwiseCSV(r.Context(), groupID, userID, file, memberMapping)
+	result, err := h.importService.ImportSplitwiseCSV(r.Context(), groupID, userID, file, memberMapping, importDuplicates)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
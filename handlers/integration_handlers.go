@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type IntegrationHandlers struct {
+	integrationService services.IntegrationService
+}
+
+func NewIntegrationHandlers(integrationService services.IntegrationService) *IntegrationHandlers {
+	return &IntegrationHandlers{
+		integrationService: integrationService,
+	}
+}
+
+type IntegrationRequest struct {
+	Platform           string  `json:"platform"`
+	WebhookURL         *string `json:"webhook_url"`
+	BotToken           *string `json:"bot_token"`
+	ChatID             *string `json:"chat_id"`
+	NotifyExpenses     *bool   `json:"notify_expenses"`
+	NotifySettlements  *bool   `json:"notify_settlements"`
+	ExpenseTemplate    *string `json:"expense_template"`
+	SettlementTemplate *string `json:"settlement_template"`
+	Enabled            *bool   `json:"enabled"`
+}
+
+func (req IntegrationRequest) toInput() services.IntegrationInput {
+	return services.IntegrationInput{
+		Platform:           models.IntegrationPlatform(strings.ToUpper(strings.TrimSpace(req.Platform))),
+		WebhookURL:         req.WebhookURL,
+		BotToken:           req.BotToken,
+		ChatID:             req.ChatID,
+		NotifyExpenses:     req.NotifyExpenses,
+		NotifySettlements:  req.NotifySettlements,
+		ExpenseTemplate:    req.ExpenseTemplate,
+		SettlementTemplate: req.SettlementTemplate,
+		Enabled:            req.Enabled,
+	}
+}
+
+func (h *IntegrationHandlers) RegisterRoutes(r chi.Router) {
+	r.Route("/groups/{groupID}/integrations", func(r chi.Router) {
+		r.Get("/", h.GetIntegrations)
+		r.Post("/", h.CreateIntegration)
+		r.Put("/{integrationID}", h.UpdateIntegration)
+		r.Delete("/{integrationID}", h.DeleteIntegration)
+		r.Get("/{integrationID}/deliveries", h.GetDeliveries)
+		r.Post("/{integrationID}/test", h.SendTestMessage)
+	})
+}
+
+func (h *IntegrationHandlers) GetIntegrations(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	integrations, err := h.integrationService.GetGroupIntegrations(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, integrations)
+}
+
+func (h *IntegrationHandlers) CreateIntegration(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req IntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if strings.TrimSpace(req.Platform) == "" {
+		handleError(w, r, apperrors.MissingRequiredField("platform"))
+		return
+	}
+
+	integration, err := h.integrationService.CreateIntegration(r.Context(), groupID, userID, req.toInput())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, integration)
+}
+
+func (h *IntegrationHandlers) UpdateIntegration(w http.ResponseWriter, r *http.Request) {
+	userID, groupID, integrationID, err := parseIntegrationParams(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req IntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	integration, err := h.integrationService.UpdateIntegration(r.Context(), groupID, integrationID, userID, req.toInput())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, integration)
+}
+
+func (h *IntegrationHandlers) DeleteIntegration(w http.ResponseWriter, r *http.Request) {
+	userID, groupID, integrationID, err := parseIntegrationParams(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.integrationService.DeleteIntegration(r.Context(), groupID, integrationID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Integration deleted successfully"})
+}
+
+func (h *IntegrationHandlers) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, groupID, integrationID, err := parseIntegrationParams(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	deliveries, err := h.integrationService.GetDeliveries(r.Context(), groupID, integrationID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *IntegrationHandlers) SendTestMessage(w http.ResponseWriter, r *http.Request) {
+	userID, groupID, integrationID, err := parseIntegrationParams(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.integrationService.SendTestMessage(r.Context(), groupID, integrationID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]string{"message": "Test message queued"})
+}
+
+func parseIntegrationParams(r *http.Request) (string, string, string, error) {
+	userID, err := getUserID(r)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		return "", "", "", err
+	}
+	integrationID, err := pathID(r, "integrationID")
+	if err != nil {
+		return "", "", "", err
+	}
+	return userID, groupID, integrationID, nil
+}
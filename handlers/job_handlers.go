@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type JobHandlers struct {
+	jobService services.JobService
+}
+
+func NewJobHandlers(jobService services.JobService) *JobHandlers {
+	return &JobHandlers{
+		jobService: jobService,
+	}
+}
+
+func (h *JobHandlers) RegisterAdminRoutes(r chi.Router) {
+	r.Get("/jobs/dead", h.GetDeadJobs)
+	r.Post("/jobs/{jobID}/retry", h.RetryDeadJob)
+}
+
+func (h *JobHandlers) GetDeadJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.jobService.GetDeadJobs(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, jobs)
+}
+
+func (h *JobHandlers) RetryDeadJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := pathID(r, "jobID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	job, err := h.jobService.RetryDeadJob(r.Context(), jobID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// normalizeJSON returns a copy of data in which every nil slice is empty and
+// every nil map is an empty map, so list fields always encode as [] and {}
+// rather than null. Fields tagged omitempty are still left out when empty.
+// data itself is never modified, since responses are often built from shared
+// or cached values.
+func normalizeJSON(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	v := reflect.ValueOf(data)
+	if !needsNormalizing(v.Type()) {
+		return data
+	}
+	return normalizeValue(v).Interface()
+}
+
+func normalizeValue(v reflect.Value) reflect.Value {
+	t := v.Type()
+	if !needsNormalizing(t) {
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(normalizeValue(v.Elem()))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t).Elem()
+		out.Set(normalizeValue(v.Elem()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		for i := 0; i < t.NumField(); i++ {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(normalizeValue(v.Field(i)))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.MakeSlice(t, 0, 0)
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(normalizeValue(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(normalizeValue(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), normalizeValue(iter.Value()))
+		}
+		return out
+	}
+	return v
+}
+
+var normalizeCache sync.Map
+
+// needsNormalizing reports whether a value of type t can contain a nil slice
+// or map that would encode as null. Types with their own MarshalJSON are left
+// to it, and byte slices encode as strings.
+func needsNormalizing(t reflect.Type) bool {
+	if cached, ok := normalizeCache.Load(t); ok {
+		return cached.(bool)
+	}
+	// Recursive types are assumed to need it while being inspected.
+	normalizeCache.Store(t, true)
+	result := inspectType(t)
+	normalizeCache.Store(t, result)
+	return result
+}
+
+func inspectType(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Map, reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Array:
+		return needsNormalizing(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.IsExported() && f.Tag.Get("json") != "-" && needsNormalizing(f.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+// TestResponseListsAreArrays is the response contract: any list field that
+// is present in a JSON response must be an array, never null, however the
+// service built it. omitempty fields may still be left out.
+func TestResponseListsAreArrays(t *testing.T) {
+	responses := []interface{}{
+		models.Group{},
+		&models.Group{Members: []models.User{{}}},
+		models.GroupWithBalances{Members: []models.GroupMemberWithBalance{{}}},
+		models.Expense{},
+		&models.Expense{ReceiptItems: []models.ReceiptItem{{}}},
+		[]models.Transaction{{}},
+		models.TransactionPage{Sections: []models.TransactionSection{{}}},
+		models.GroupBalancesResponse{UserBalances: []models.UserBalance{{}}},
+		models.GroupBalancesEdgeResponse{},
+		models.DashboardResponse{},
+		models.FriendWithBalance{},
+		models.Comment{},
+		models.DebtExplanation{},
+		models.GroupEventDetail{},
+		models.ReceiptGalleryPage{},
+		models.GroupForecast{},
+		map[string]interface{}{"groups": []models.DashboardGroup(nil)},
+	}
+
+	for _, response := range responses {
+		typ := reflect.TypeOf(response)
+		t.Run(typ.String(), func(t *testing.T) {
+			data, err := json.Marshal(normalizeJSON(response))
+			if err != nil {
+				t.Fatalf("encoding: %v", err)
+			}
+			var decoded interface{}
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("decoding: %v", err)
+			}
+			checkListsAreArrays(t, typ.String(), typ, decoded)
+		})
+	}
+}
+
+func checkListsAreArrays(t *testing.T, path string, typ reflect.Type, decoded interface{}) {
+	t.Helper()
+	if typ.Implements(jsonMarshalerType) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if decoded != nil {
+			checkListsAreArrays(t, path, typ.Elem(), decoded)
+		}
+	case reflect.Slice:
+		items, ok := decoded.([]interface{})
+		if !ok {
+			t.Errorf("%s: expected an array, got %s", path, encodeForError(decoded))
+			return
+		}
+		for i, item := range items {
+			checkListsAreArrays(t, path+"["+strconv.Itoa(i)+"]", typ.Elem(), item)
+		}
+	case reflect.Map:
+		entries, ok := decoded.(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: expected an object, got %s", path, encodeForError(decoded))
+			return
+		}
+		for key, value := range entries {
+			if typ.Elem().Kind() != reflect.Interface {
+				checkListsAreArrays(t, path+"."+key, typ.Elem(), value)
+			} else if value == nil {
+				t.Errorf("%s.%s: expected a value, got null", path, key)
+			}
+		}
+	case reflect.Struct:
+		fields, ok := decoded.(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: expected an object, got %s", path, encodeForError(decoded))
+			return
+		}
+		checkStructFields(t, path, typ, fields)
+	}
+}
+
+func checkStructFields(t *testing.T, path string, typ reflect.Type, fields map[string]interface{}) {
+	t.Helper()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				checkStructFields(t, path, embedded, fields)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if value, ok := fields[name]; ok {
+			checkListsAreArrays(t, path+"."+name, f.Type, value)
+		}
+	}
+}
+
+func encodeForError(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func TestNormalizeJSONLeavesInputUntouched(t *testing.T) {
+	expense := &models.Expense{ID: "e1", ReceiptItems: []models.ReceiptItem{{ID: "r1"}}}
+	normalizeJSON(expense)
+	if expense.ReceiptItems[0].Assignments != nil {
+		t.Errorf("expected the original expense to be left as it was")
+	}
+}
+
+func TestNormalizeJSONKeepsOwnMarshalers(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	payload := struct {
+		At  time.Time       `json:"at"`
+		Raw json.RawMessage `json:"raw"`
+	}{At: at, Raw: json.RawMessage(`{"a":1}`)}
+
+	data, err := json.Marshal(normalizeJSON(payload))
+	if err != nil {
+		t.Fatalf("encoding: %v", err)
+	}
+	if expected := `{"at":"2024-05-01T12:00:00Z","raw":{"a":1}}`; string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestRespondJSONNilList(t *testing.T) {
+	var groups []models.Group
+	w := httptest.NewRecorder()
+	respondJSON(w, 200, groups)
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Errorf("expected [], got %s", body)
+	}
+}
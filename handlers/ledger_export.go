@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Accounting exports turn a group's transactions into files personal finance
+// tools (GnuCash, YNAB, ...) can import. QIF and OFX are statements from the
+// caller's point of view; the ledger CSV is the whole group in double entry.
+
+type accountingFormat string
+
+const (
+	accountingQIF    accountingFormat = "qif"
+	accountingOFX    accountingFormat = "ofx"
+	accountingLedger accountingFormat = "ledger"
+)
+
+var accountingFiles = map[accountingFormat]struct {
+	contentType, filename string
+}{
+	accountingQIF:    {"application/qif; charset=utf-8", "group_export.qif"},
+	accountingOFX:    {"application/x-ofx; charset=utf-8", "group_export.ofx"},
+	accountingLedger: {"text/csv; charset=utf-8", "group_ledger.csv"},
+}
+
+// ofxNameLength is the longest NAME the OFX spec allows in a transaction.
+const ofxNameLength = 32
+
+// statementLine is one transaction as it affects the caller. Amount is what
+// they paid less their share, so the running total is their group balance.
+type statementLine struct {
+	ID     string
+	Date   time.Time
+	Payee  string
+	Memo   string
+	Amount float64
+}
+
+func (h *Handlers) ExportGroupAccounting(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	format := accountingFormat(strings.ToLower(chi.URLParam(r, "format")))
+	file, ok := accountingFiles[format]
+	if !ok {
+		handleError(w, r, apperrors.InvalidRequest("Unsupported export format. Use one of: qif, ofx, ledger."))
+		return
+	}
+
+	opts, err := parseCSVExportOptions(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	body, err := h.newExportBody(w, opts)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.userService.RequireVerifiedEmail(r.Context(), userID, getEmailVerified(r), "exporting data"); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	filter.Limit, filter.Offset = 0, 0
+
+	group, err := h.groupService.GetByID(r.Context(), groupID, userID, models.MemberSort{})
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	transactions, err := h.groupService.GetTransactions(r.Context(), groupID, userID, filter)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	transactions = chronological(transactions)
+	labels := csvLabelsFor(group.DefaultLanguage)
+
+	var lines []statementLine
+	currency := ""
+	if format != accountingLedger {
+		currency, err = statementCurrency(transactions, r.URL.Query().Get("currency"), group.DefaultCurrency)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+		lines = statementLines(transactions, currency, labels)
+	}
+
+	w.Header().Set("Content-Type", file.contentType)
+	w.Header().Set("Content-Disposition", "attachment;filename="+file.filename)
+
+	switch format {
+	case accountingQIF:
+		err = writeQIF(body, lines)
+	case accountingOFX:
+		err = writeOFX(body, group.ID, currency, lines, time.Now().UTC())
+	case accountingLedger:
+		if opts.bom {
+			if _, err := body.Write(utf8BOM); err != nil {
+				return
+			}
+		}
+		err = writeLedgerCSV(body, opts, labels, ledgerMembers(group.Members, transactions, labels), transactions)
+	}
+	if err == nil {
+		body.Close()
+	}
+}
+
+// chronological returns the transactions oldest first, as statements list them.
+func chronological(transactions []models.Transaction) []models.Transaction {
+	sorted := make([]models.Transaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return transactionDate(sorted[i]).Before(transactionDate(sorted[j]))
+	})
+	return sorted
+}
+
+func transactionDate(t models.Transaction) time.Time {
+	if !t.DateISO.IsZero() {
+		return t.DateISO
+	}
+	date, _ := time.Parse("2006-01-02", t.Date)
+	return date
+}
+
+// statementCurrency picks the one currency a QIF or OFX statement is in.
+// Without a requested currency the transactions must all share one.
+func statementCurrency(transactions []models.Transaction, requested, fallback string) (string, error) {
+	if requested = strings.ToUpper(strings.TrimSpace(requested)); requested != "" {
+		return requested, nil
+	}
+
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, t := range transactions {
+		if !seen[t.Currency] {
+			seen[t.Currency] = true
+			currencies = append(currencies, t.Currency)
+		}
+	}
+	switch len(currencies) {
+	case 0:
+		return fallback, nil
+	case 1:
+		return currencies[0], nil
+	}
+	sort.Strings(currencies)
+	return "", apperrors.InvalidRequest(fmt.Sprintf("Transactions are in several currencies (%s). Choose one with the currency parameter.", strings.Join(currencies, ", ")))
+}
+
+// statementLines keeps the transactions in currency that change the caller's
+// balance.
+func statementLines(transactions []models.Transaction, currency string, labels csvLabels) []statementLine {
+	lines := []statementLine{}
+	for _, t := range transactions {
+		if t.Currency != currency || math.Abs(t.UserNetAmount) < services.BalanceThreshold {
+			continue
+		}
+
+		paidBy := labels.unknown
+		if t.PaidByUser != nil {
+			paidBy = t.PaidByUser.Name
+		}
+		lines = append(lines, statementLine{
+			ID:    t.ID,
+			Date:  transactionDate(t),
+			Payee: t.Description,
+			Memo: fmt.Sprintf("%s: %s; %s: %s; %s: %s", labels.paidBy, paidBy,
+				labels.cost, formatStatementAmount(t.TotalAmount), labels.yourShare, formatStatementAmount(t.UserShare)),
+			Amount: t.UserNetAmount,
+		})
+	}
+	return lines
+}
+
+func formatStatementAmount(amount float64) string {
+	return csvNumberFormats["raw"].FormatAmount(amount)
+}
+
+// qifText keeps a value on one line, since QIF fields end at the newline.
+func qifText(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func writeQIF(w io.Writer, lines []statementLine) error {
+	var b strings.Builder
+	b.WriteString("!Type:Bank\n")
+	for _, line := range lines {
+		fmt.Fprintf(&b, "D%s\n", line.Date.Format("01/02/2006"))
+		fmt.Fprintf(&b, "T%s\n", formatStatementAmount(line.Amount))
+		fmt.Fprintf(&b, "P%s\n", qifText(line.Payee))
+		fmt.Fprintf(&b, "M%s\n", qifText(line.Memo))
+		b.WriteString("^\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	Amount string `xml:"TRNAMT"`
+	FITID  string `xml:"FITID"`
+	Name   string `xml:"NAME"`
+	Memo   string `xml:"MEMO"`
+}
+
+type ofxDocument struct {
+	XMLName       xml.Name         `xml:"OFX"`
+	SignOnStatus  ofxStatus        `xml:"SIGNONMSGSRSV1>SONRS>STATUS"`
+	ServerTime    string           `xml:"SIGNONMSGSRSV1>SONRS>DTSERVER"`
+	Language      string           `xml:"SIGNONMSGSRSV1>SONRS>LANGUAGE"`
+	TransactionID string           `xml:"BANKMSGSRSV1>STMTTRNRS>TRNUID"`
+	Status        ofxStatus        `xml:"BANKMSGSRSV1>STMTTRNRS>STATUS"`
+	Currency      string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>CURDEF"`
+	BankID        string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>BANKID"`
+	AccountID     string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>ACCTID"`
+	AccountType   string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>ACCTTYPE"`
+	Start         string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTSTART"`
+	End           string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTEND"`
+	Transactions  []ofxTransaction `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>STMTTRN"`
+	Balance       string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL>BALAMT"`
+	BalanceAsOf   string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL>DTASOF"`
+}
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" +
+	`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// writeOFX writes an OFX 2.2 bank statement for the group, treating the
+// caller's balance in it as the account. The group ID is the account ID so
+// tools match repeated imports to the same account; expense IDs dedupe rows.
+func writeOFX(w io.Writer, groupID, currency string, lines []statementLine, now time.Time) error {
+	const ofxTime = "20060102150405"
+
+	doc := ofxDocument{
+		SignOnStatus:  ofxStatus{Code: 0, Severity: "INFO"},
+		ServerTime:    now.Format(ofxTime),
+		Language:      "ENG",
+		TransactionID: "0",
+		Status:        ofxStatus{Code: 0, Severity: "INFO"},
+		Currency:      currency,
+		BankID:        "UNWISE",
+		AccountID:     groupID,
+		AccountType:   "CHECKING",
+		Start:         now.Format(ofxTime),
+		End:           now.Format(ofxTime),
+		Transactions:  []ofxTransaction{},
+		BalanceAsOf:   now.Format(ofxTime),
+	}
+
+	balance := 0.0
+	for i, line := range lines {
+		if i == 0 {
+			doc.Start = line.Date.UTC().Format(ofxTime)
+		}
+		trnType := "CREDIT"
+		if line.Amount < 0 {
+			trnType = "DEBIT"
+		}
+		name := []rune(qifText(line.Payee))
+		if len(name) > ofxNameLength {
+			name = name[:ofxNameLength]
+		}
+		doc.Transactions = append(doc.Transactions, ofxTransaction{
+			Type:   trnType,
+			Posted: line.Date.UTC().Format(ofxTime),
+			Amount: formatStatementAmount(line.Amount),
+			FITID:  line.ID,
+			Name:   string(name),
+			Memo:   qifText(line.Memo),
+		})
+		balance += line.Amount
+	}
+	doc.Balance = formatStatementAmount(math.Round(balance*services.RoundingFactor) / services.RoundingFactor)
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, ofxHeader); err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+type ledgerMember struct {
+	ID   string
+	Name string
+}
+
+// ledgerMembers lists the current members, then anyone else who appears in a
+// transaction (members who have since left).
+func ledgerMembers(members []models.User, transactions []models.Transaction, labels csvLabels) []ledgerMember {
+	seen := make(map[string]bool)
+	var result []ledgerMember
+	for _, m := range members {
+		seen[m.ID] = true
+		result = append(result, ledgerMember{ID: m.ID, Name: m.Name})
+	}
+
+	add := func(id, name string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		if name == "" {
+			name = labels.formerMember
+		}
+		result = append(result, ledgerMember{ID: id, Name: name})
+	}
+	for _, t := range transactions {
+		for _, p := range t.Payers {
+			add(p.UserID, "")
+		}
+		for _, s := range t.Splits {
+			add(s.UserID, s.UserName)
+		}
+	}
+	return result
+}
+
+// writeLedgerCSV writes every transaction as a balanced double entry: each
+// member is debited their share and credited what they paid, so the debits
+// and credits of a row both add up to its cost.
+func writeLedgerCSV(w io.Writer, opts csvExportOptions, labels csvLabels, members []ledgerMember, transactions []models.Transaction) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = opts.delimiter
+	writer.UseCRLF = true
+
+	header := []string{labels.date, labels.description, labels.category, labels.currency, labels.cost}
+	for _, m := range members {
+		header = append(header, m.Name+" "+labels.debit, m.Name+" "+labels.credit)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	amount := func(value float64) string {
+		if math.Abs(value) < services.BalanceThreshold {
+			return ""
+		}
+		return opts.format.FormatAmount(value)
+	}
+
+	for _, t := range transactions {
+		debits := make(map[string]float64)
+		credits := make(map[string]float64)
+		for _, s := range t.Splits {
+			debits[s.UserID] += s.Amount
+		}
+		for _, p := range t.Payers {
+			credits[p.UserID] += p.AmountPaid
+		}
+
+		record := []string{t.Date, t.Description, string(t.Category), t.Currency, opts.format.FormatAmount(t.TotalAmount)}
+		for _, m := range members {
+			record = append(record, amount(debits[m.ID]), amount(credits[m.ID]))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func ledgerTransaction(id, date, description string, total, net, share float64, payers []models.ExpensePayer, splits []models.ExpenseSplit) models.Transaction {
+	t := models.Transaction{UserNetAmount: net, UserShare: share}
+	t.ID = id
+	t.Date = date
+	t.Description = description
+	t.Category = models.TransactionCategoryExpense
+	t.Currency = "INR"
+	t.TotalAmount = total
+	t.Payers = payers
+	t.Splits = splits
+	return t
+}
+
+func TestWriteLedgerCSV(t *testing.T) {
+	labels := csvLabelsFor("en")
+	transactions := []models.Transaction{
+		ledgerTransaction("e1", "2024-06-01", "Dinner", 300, 200, 100,
+			[]models.ExpensePayer{{UserID: "me", AmountPaid: 300}},
+			[]models.ExpenseSplit{{UserID: "me", Amount: 100}, {UserID: "A", Amount: 100}, {UserID: "gone", Amount: 100}}),
+	}
+	members := ledgerMembers([]models.User{{ID: "me", Name: "Me"}, {ID: "A", Name: "Asha"}}, transactions, labels)
+
+	var buf bytes.Buffer
+	opts := csvExportOptions{format: csvNumberFormats["en"], delimiter: ','}
+	if err := writeLedgerCSV(&buf, opts, labels, members, transactions); err != nil {
+		t.Fatalf("writeLedgerCSV: %v", err)
+	}
+
+	expected := "Date,Description,Category,Currency,Cost,Me Debit,Me Credit,Asha Debit,Asha Credit,Former member Debit,Former member Credit\r\n" +
+		"2024-06-01,Dinner,EXPENSE,INR,300.00,100.00,300.00,100.00,,100.00,\r\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("ledger CSV = %q, expected %q", got, expected)
+	}
+}
+
+func TestStatementExports(t *testing.T) {
+	labels := csvLabelsFor("en")
+	transactions := chronological([]models.Transaction{
+		ledgerTransaction("e2", "2024-06-03", "Taxi\nto airport", 90, -30, 30, nil, nil),
+		ledgerTransaction("e1", "2024-06-01", "Dinner", 300, 200, 100, nil, nil),
+		ledgerTransaction("e3", "2024-06-04", "Not mine", 50, 0, 0, nil, nil),
+	})
+
+	currency, err := statementCurrency(transactions, "", "EUR")
+	if err != nil || currency != "INR" {
+		t.Fatalf("statementCurrency = %q, %v; expected INR", currency, err)
+	}
+	mixed := append(transactions, models.Transaction{Expense: models.Expense{Currency: "USD"}})
+	if _, err := statementCurrency(mixed, "", "EUR"); err == nil {
+		t.Error("statementCurrency accepted mixed currencies without a choice")
+	}
+
+	lines := statementLines(transactions, currency, labels)
+	if len(lines) != 2 {
+		t.Fatalf("got %d statement lines, expected 2", len(lines))
+	}
+
+	var qif bytes.Buffer
+	if err := writeQIF(&qif, lines); err != nil {
+		t.Fatalf("writeQIF: %v", err)
+	}
+	expectedQIF := "!Type:Bank\n" +
+		"D06/01/2024\nT200.00\nPDinner\nMPaid By: Unknown; Cost: 300.00; Your Share: 100.00\n^\n" +
+		"D06/03/2024\nT-30.00\nPTaxi to airport\nMPaid By: Unknown; Cost: 90.00; Your Share: 30.00\n^\n"
+	if got := qif.String(); got != expectedQIF {
+		t.Errorf("QIF = %q, expected %q", got, expectedQIF)
+	}
+
+	var ofx bytes.Buffer
+	if err := writeOFX(&ofx, "g1", currency, lines, time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("writeOFX: %v", err)
+	}
+	for _, want := range []string{"<CURDEF>INR</CURDEF>", "<ACCTID>g1</ACCTID>", "<TRNTYPE>DEBIT</TRNTYPE>", "<FITID>e2</FITID>", "<BALAMT>170.00</BALAMT>", "<DTSTART>20240601000000</DTSTART>"} {
+		if !strings.Contains(ofx.String(), want) {
+			t.Errorf("OFX is missing %s:\n%s", want, ofx.String())
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type UpdateNotificationSettingsRequest struct {
+	Muted       *bool `json:"muted"`
+	NewExpenses *bool `json:"new_expenses"`
+	Comments    *bool `json:"comments"`
+	Settlements *bool `json:"settlements"`
+	Reminders   *bool `json:"reminders"`
+}
+
+type UpdateQuietHoursRequest struct {
+	Enabled  *bool   `json:"enabled"`
+	Start    *string `json:"start"`
+	End      *string `json:"end"`
+	TimeZone *string `json:"time_zone"`
+}
+
+type NotificationHandlers struct {
+	notificationService services.NotificationService
+	reminderService     services.ReminderService
+}
+
+func NewNotificationHandlers(notificationService services.NotificationService, reminderService services.ReminderService) *NotificationHandlers {
+	return &NotificationHandlers{
+		notificationService: notificationService,
+		reminderService:     reminderService,
+	}
+}
+
+func (h *NotificationHandlers) RegisterRoutes(r chi.Router) {
+	r.Route("/groups/{groupID}/notification-settings", func(r chi.Router) {
+		r.Get("/", h.GetGroupSettings)
+		r.Put("/", h.UpdateGroupSettings)
+	})
+	r.Route("/groups/{groupID}/quiet-hours", func(r chi.Router) {
+		r.Get("/", h.GetQuietHours)
+		r.Put("/", h.UpdateQuietHours)
+	})
+	r.Route("/groups/{groupID}/reminder-responses", func(r chi.Router) {
+		r.Get("/", h.GetReminderResponses)
+		r.Put("/{creditorID}", h.RespondToReminders)
+		r.Delete("/{creditorID}", h.WithdrawReminderResponse)
+	})
+	r.Route("/notifications", func(r chi.Router) {
+		r.Get("/", h.GetNotifications)
+		r.Post("/{notificationID}/read", h.MarkRead)
+	})
+	r.Post("/user/remind-all", h.RemindAll)
+}
+
+func (h *NotificationHandlers) GetGroupSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	settings, err := h.notificationService.GetGroupSettings(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
+
+func (h *NotificationHandlers) UpdateGroupSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateNotificationSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	settings, err := h.notificationService.GetGroupSettings(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if req.Muted != nil {
+		settings.Muted = *req.Muted
+	}
+	if req.NewExpenses != nil {
+		settings.NewExpenses = *req.NewExpenses
+	}
+	if req.Comments != nil {
+		settings.Comments = *req.Comments
+	}
+	if req.Settlements != nil {
+		settings.Settlements = *req.Settlements
+	}
+	if req.Reminders != nil {
+		settings.Reminders = *req.Reminders
+	}
+
+	settings, err = h.notificationService.UpdateGroupSettings(r.Context(), groupID, userID, settings)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
+
+func (h *NotificationHandlers) GetQuietHours(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	quietHours, err := h.notificationService.GetQuietHours(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quietHours)
+}
+
+func (h *NotificationHandlers) UpdateQuietHours(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateQuietHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	quietHours, err := h.notificationService.GetQuietHours(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if req.Enabled != nil {
+		quietHours.Enabled = *req.Enabled
+	} else if req.Start != nil || req.End != nil {
+		quietHours.Enabled = true
+	}
+	if req.Start != nil {
+		quietHours.Start = *req.Start
+	}
+	if req.End != nil {
+		quietHours.End = *req.End
+	}
+	if req.TimeZone != nil {
+		quietHours.TimeZone = *req.TimeZone
+	}
+
+	quietHours, err = h.notificationService.UpdateQuietHours(r.Context(), groupID, userID, quietHours)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quietHours)
+}
+
+func (h *NotificationHandlers) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotifications(r.Context(), userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, notifications)
+}
+
+func (h *NotificationHandlers) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	notificationID, err := pathID(r, "notificationID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.notificationService.MarkRead(r.Context(), notificationID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Notification marked as read"})
+}
+
+func (h *NotificationHandlers) RemindAll(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	result, err := h.reminderService.RemindAllDebtors(r.Context(), userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+func (h *NotificationHandlers) GetReminderResponses(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	responses, err := h.reminderService.GetReminderResponses(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, responses)
+}
+
+func (h *NotificationHandlers) RespondToReminders(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	creditorID, err := pathID(r, "creditorID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req models.ReminderResponseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+
+	response, err := h.reminderService.RespondToReminders(r.Context(), groupID, userID, creditorID, req)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+func (h *NotificationHandlers) WithdrawReminderResponse(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	creditorID, err := pathID(r, "creditorID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.reminderService.WithdrawReminderResponse(r.Context(), groupID, userID, creditorID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Reminder response withdrawn"})
+}
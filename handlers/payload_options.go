@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+const (
+	expandSplits       = "splits"
+	expandPayers       = "payers"
+	expandReceiptItems = "receipt_items"
+	expandAssignments  = "assignments"
+)
+
+var expandableFields = []string{expandSplits, expandPayers, expandReceiptItems, expandAssignments}
+
+// payloadOptions controls how much of each expense a list endpoint returns.
+// Without ?expand= every nested collection is included, as before; with it,
+// only the listed collections are. ?fields= keeps just the named top-level keys.
+type payloadOptions struct {
+	expand map[string]bool
+	fields map[string]bool
+}
+
+func parsePayloadOptions(r *http.Request) (payloadOptions, error) {
+	var opts payloadOptions
+	query := r.URL.Query()
+
+	if _, ok := query["expand"]; ok {
+		opts.expand = make(map[string]bool)
+		for _, name := range splitListParam(query["expand"]) {
+			if !isExpandable(name) {
+				return opts, apperrors.InvalidRequest(fmt.Sprintf("Invalid expand value '%s'. Must be one of: %s.", name, strings.Join(expandableFields, ", ")))
+			}
+			opts.expand[name] = true
+		}
+		if opts.expand[expandAssignments] {
+			opts.expand[expandReceiptItems] = true
+		}
+	}
+
+	if names := splitListParam(query["fields"]); len(names) > 0 {
+		opts.fields = map[string]bool{"id": true}
+		for _, name := range names {
+			opts.fields[name] = true
+		}
+	}
+	return opts, nil
+}
+
+func splitListParam(values []string) []string {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func isExpandable(name string) bool {
+	for _, field := range expandableFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (o payloadOptions) slimExpense(expense *models.Expense) {
+	if o.expand == nil {
+		return
+	}
+	if !o.expand[expandSplits] {
+		expense.Splits = nil
+	}
+	if !o.expand[expandPayers] {
+		expense.Payers = nil
+	}
+	if !o.expand[expandReceiptItems] {
+		expense.ReceiptItems = nil
+	} else if !o.expand[expandAssignments] {
+		for i := range expense.ReceiptItems {
+			expense.ReceiptItems[i].Assignments = nil
+		}
+	}
+}
+
+// selectFields returns items unchanged unless ?fields= was given, in which case
+// each item is re-encoded keeping only the requested top-level keys.
+func (o payloadOptions) selectFields(items interface{}) (interface{}, error) {
+	if o.fields == nil {
+		return items, nil
+	}
+
+	data, err := json.Marshal(normalizeJSON(items))
+	if err != nil {
+		return nil, fmt.Errorf("encoding payload: %w", err)
+	}
+	var decoded []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+	for _, item := range decoded {
+		for key := range item {
+			if !o.fields[key] {
+				delete(item, key)
+			}
+		}
+	}
+	return decoded, nil
+}
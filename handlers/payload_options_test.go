@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestParsePayloadOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedExpand []string
+		expectedFields []string
+		wantErr        bool
+	}{
+		{name: "No Options", query: ""},
+		{name: "Empty Expand Strips Everything", query: "?expand=", expectedExpand: []string{}},
+		{name: "Comma Separated And Repeated", query: "?expand=Splits,%20payers&expand=payers", expectedExpand: []string{"payers", "splits"}},
+		{name: "Assignments Bring Their Items", query: "?expand=assignments", expectedExpand: []string{"assignments", "receipt_items"}},
+		{name: "Unknown Expand", query: "?expand=comments", wantErr: true},
+		{name: "Fields Always Keep ID", query: "?fields=description,amount", expectedFields: []string{"amount", "description", "id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parsePayloadOptions(httptest.NewRequest("GET", "/transactions"+tt.query, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePayloadOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := setKeys(opts.expand); !reflect.DeepEqual(got, tt.expectedExpand) {
+				t.Errorf("parsePayloadOptions() expand = %v, expected %v", got, tt.expectedExpand)
+			}
+			if got := setKeys(opts.fields); !reflect.DeepEqual(got, tt.expectedFields) {
+				t.Errorf("parsePayloadOptions() fields = %v, expected %v", got, tt.expectedFields)
+			}
+		})
+	}
+}
+
+// setKeys returns the sorted keys of set, or nil when set is nil so tests can
+// tell an absent option from an empty one.
+func setKeys(set map[string]bool) []string {
+	if set == nil {
+		return nil
+	}
+	names := []string{}
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSlimExpense(t *testing.T) {
+	newExpense := func() *models.Expense {
+		return &models.Expense{
+			ID:     "e1",
+			Splits: []models.ExpenseSplit{{UserID: "alice"}},
+			Payers: []models.ExpensePayer{{UserID: "bob"}},
+			ReceiptItems: []models.ReceiptItem{{
+				Name:        "Naan",
+				Assignments: []models.ReceiptItemAssignment{{UserID: "alice"}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		query             string
+		expectSplits      bool
+		expectPayers      bool
+		expectItems       bool
+		expectAssignments bool
+	}{
+		{name: "Everything By Default", query: "", expectSplits: true, expectPayers: true, expectItems: true, expectAssignments: true},
+		{name: "Nothing Expanded", query: "?expand=", expectSplits: false, expectPayers: false, expectItems: false, expectAssignments: false},
+		{name: "Items Without Assignments", query: "?expand=splits,receipt_items", expectSplits: true, expectPayers: false, expectItems: true, expectAssignments: false},
+		{name: "Assignments", query: "?expand=assignments", expectSplits: false, expectPayers: false, expectItems: true, expectAssignments: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parsePayloadOptions(httptest.NewRequest("GET", "/transactions"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("parsePayloadOptions() error = %v", err)
+			}
+			expense := newExpense()
+			opts.slimExpense(expense)
+
+			if (expense.Splits != nil) != tt.expectSplits {
+				t.Errorf("slimExpense() splits = %v, expected present = %v", expense.Splits, tt.expectSplits)
+			}
+			if (expense.Payers != nil) != tt.expectPayers {
+				t.Errorf("slimExpense() payers = %v, expected present = %v", expense.Payers, tt.expectPayers)
+			}
+			if (expense.ReceiptItems != nil) != tt.expectItems {
+				t.Fatalf("slimExpense() receipt items = %v, expected present = %v", expense.ReceiptItems, tt.expectItems)
+			}
+			if tt.expectItems && (expense.ReceiptItems[0].Assignments != nil) != tt.expectAssignments {
+				t.Errorf("slimExpense() assignments = %v, expected present = %v", expense.ReceiptItems[0].Assignments, tt.expectAssignments)
+			}
+		})
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	expenses := []models.Expense{{ID: "e1", Description: "Dinner", TotalAmount: 1200}}
+
+	opts, err := parsePayloadOptions(httptest.NewRequest("GET", "/transactions?fields=description", nil))
+	if err != nil {
+		t.Fatalf("parsePayloadOptions() error = %v", err)
+	}
+	selected, err := opts.selectFields(expenses)
+	if err != nil {
+		t.Fatalf("selectFields() error = %v", err)
+	}
+	items, ok := selected.([]map[string]json.RawMessage)
+	if !ok || len(items) != 1 {
+		t.Fatalf("selectFields() = %#v, expected one decoded item", selected)
+	}
+	if got := len(items[0]); got != 2 || string(items[0]["id"]) != `"e1"` || string(items[0]["description"]) != `"Dinner"` {
+		t.Errorf("selectFields() item = %s, expected only id and description", items[0])
+	}
+
+	unchanged, err := payloadOptions{}.selectFields(expenses)
+	if err != nil {
+		t.Fatalf("selectFields() error = %v", err)
+	}
+	if !reflect.DeepEqual(unchanged, expenses) {
+		t.Errorf("selectFields() without fields = %v, expected the items unchanged", unchanged)
+	}
+}
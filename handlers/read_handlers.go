@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type MarkReadRequest struct {
+	ExpenseIDs []string `json:"expense_ids"`
+}
+
+type ReadHandlers struct {
+	readService services.ReadService
+}
+
+func NewReadHandlers(readService services.ReadService) *ReadHandlers {
+	return &ReadHandlers{
+		readService: readService,
+	}
+}
+
+func (h *ReadHandlers) RegisterRoutes(r chi.Router) {
+	r.Post("/groups/{groupID}/transactions/read", h.MarkRead)
+	r.Get("/expenses/{expenseID}/reads", h.GetExpenseReads)
+}
+
+func (h *ReadHandlers) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req MarkReadRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+			return
+		}
+	}
+	for i, expenseID := range req.ExpenseIDs {
+		if req.ExpenseIDs[i], err = parseID(expenseID, "Expense ID"); err != nil {
+			handleError(w, r, err)
+			return
+		}
+	}
+
+	result, err := h.readService.MarkRead(r.Context(), groupID, userID, req.ExpenseIDs)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+func (h *ReadHandlers) GetExpenseReads(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	reads, err := h.readService.GetExpenseReads(r.Context(), expenseID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, reads)
+}
@@ -1,67 +1,111 @@
 package handlers
 
 import (
+	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"time"
 
 	apperrors "unwise-backend/errors"
-
-	"github.com/google/uuid"
+	"unwise-backend/models"
+	"unwise-backend/services"
 )
 
 func (h *Handlers) ScanReceipt(w http.ResponseWriter, r *http.Request) {
-	_, err := getUserID(r)
+	userID, err := getUserID(r)
 	if err != nil {
 		log.Printf("[ScanReceipt] Failed to get user ID: %v", err)
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
 		log.Printf("[ScanReceipt] Failed to parse multipart form: %v", err)
-		handleError(w, apperrors.InvalidRequest("Failed to parse multipart form. Please ensure the request is properly formatted."))
+		handleError(w, r, apperrors.InvalidRequest("Failed to parse multipart form. Please ensure the request is properly formatted."))
 		return
 	}
 	file, header, err := r.FormFile("image")
 	if err != nil {
 		log.Printf("[ScanReceipt] Failed to get image file: %v", err)
-		handleError(w, apperrors.MissingRequiredField("Image file"))
+		handleError(w, r, apperrors.MissingRequiredField("Image file"))
 		return
 	}
 	defer file.Close()
 
+	var group *models.Group
+	if groupID := r.FormValue("group_id"); groupID != "" {
+		if groupID, err = parseID(groupID, "Group ID"); err != nil {
+			handleError(w, r, err)
+			return
+		}
+		group, err = h.groupService.GetByID(r.Context(), groupID, userID, models.MemberSort{})
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+	}
+
 	contentType := header.Header.Get("Content-Type")
 	if contentType == "" {
 		contentType = "image/jpeg"
 	}
 
-	filename := uuid.New().String() + "_" + time.Now().Format("20060102_150405")
-	imageURL, err := h.storageService.Upload(r.Context(), h.storageBucket, filename, file, contentType)
-	if err != nil {
+	var groupID *string
+	if group != nil {
+		groupID = &group.ID
+	}
+	filename := receiptUploadPath(userID, groupID)
+	if _, err := h.storageService.Upload(r.Context(), h.storageBucket, filename, file, contentType); err != nil {
 		log.Printf("[ScanReceipt] Failed to upload image: %v", err)
-		handleError(w, apperrors.StorageError("uploading receipt image", err))
+		handleError(w, r, apperrors.StorageError("uploading receipt image", err))
 		return
 	}
 
 	file.Seek(0, io.SeekStart)
-	result, err := h.receiptService.ParseReceipt(r.Context(), file)
+	result, err := h.receiptService.ParseReceipt(r.Context(), userID, groupID, file)
 	if err != nil {
-		log.Printf("[ScanReceipt] Gemini parsing failed: %v", err)
-		handleError(w, apperrors.AIServiceError(err))
+		log.Printf("[ScanReceipt] Receipt parsing failed: %v", err)
+		handleError(w, r, apperrors.AIServiceError(err))
 		return
 	}
 
+	var groupPreset models.TaxPreset
+	if group != nil {
+		groupPreset = group.TaxPreset
+	}
+	taxPreset, taxComponents := services.ReceiptTaxComponents(result, groupPreset)
+	result.TaxComponents = taxComponents
+
 	response := map[string]interface{}{
-		"receipt_image_url": imageURL,
-		"items":             result.Items,
-		"subtotal":          result.Subtotal,
-		"tax":               result.Tax,
-		"cgst":              result.CGST,
-		"sgst":              result.SGST,
-		"service_charge":    result.ServiceCharge,
-		"total":             result.Total,
+		"receipt_image_path": filename,
+		"receipt_image_url":  h.signReceiptURL(r.Context(), &filename, services.ReceiptURLExpiry),
+		"items":              result.Items,
+		"subtotal":           result.Subtotal,
+		"tax":                result.Tax,
+		"cgst":               result.CGST,
+		"sgst":               result.SGST,
+		"tax_preset":         taxPreset,
+		"tax_components":     result.TaxComponents,
+		"service_charge":     result.ServiceCharge,
+		"total":              result.Total,
+		"output_id":          result.OutputID,
+		"currency":           result.Currency,
+		"currency_source":    result.CurrencySource,
+		"locale":             result.Locale,
+	}
+
+	if group != nil {
+		suggested := result.Currency
+		if suggested == "" {
+			suggested = group.DefaultCurrency
+		}
+		mismatch := services.ReceiptCurrencyMismatch(result.Currency, group.DefaultCurrency)
+		response["group_currency"] = group.DefaultCurrency
+		response["suggested_currency"] = suggested
+		response["currency_mismatch"] = mismatch
+		if mismatch {
+			response["currency_warning"] = fmt.Sprintf("This receipt looks like it is in %s, but the group's default currency is %s.", result.Currency, group.DefaultCurrency)
+		}
 	}
 
 	respondJSON(w, http.StatusOK, response)
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// personalReceiptScope stands in for the group in upload paths of receipts
+// scanned outside a group, which the uploader may attach to any of their groups.
+const personalReceiptScope = "personal"
+
+func receiptUploadPath(userID string, groupID *string) string {
+	scope := personalReceiptScope
+	if groupID != nil {
+		scope = *groupID
+	}
+	return userID + "/" + scope + "/" + uuid.New().String() + "_" + time.Now().Format("20060102_150405")
+}
+
+func (h *Handlers) receiptImagePath(userID, groupID string, path, legacyURL *string) (*string, error) {
+	value := path
+	if value == nil {
+		value = legacyURL
+	}
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	objectPath := storage.ObjectPath(h.storageBucket, *value)
+	if uploader, ok := receiptUploader(objectPath, groupID); !ok || uploader != userID {
+		return nil, apperrors.InvalidRequest("Receipt image must be one you uploaded for this group.")
+	}
+	return &objectPath, nil
+}
+
+// receiptUploader returns who uploaded a receipt path issued for groupID.
+// Paths stored before uploads were scoped per user have no uploader and
+// report ok=false, like paths issued for another group.
+func receiptUploader(objectPath, groupID string) (string, bool) {
+	parts := strings.Split(objectPath, "/")
+	if len(parts) != 3 || parts[0] == "" ||
+		(parts[1] != groupID && parts[1] != personalReceiptScope) ||
+		parts[2] == "" || parts[2] == "." || parts[2] == ".." {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// updatedReceiptPath validates a receipt sent with an expense edit. Clients
+// echo the stored path back when the receipt is unchanged, so that path is
+// accepted as is even if it predates per-user upload paths.
+func (h *Handlers) updatedReceiptPath(ctx context.Context, expenseID, userID string, path, legacyURL *string) (*string, error) {
+	value := path
+	if value == nil {
+		value = legacyURL
+	}
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	existing, err := h.expenseService.GetByID(ctx, expenseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.ReceiptImagePath != nil && storage.ObjectPath(h.storageBucket, *value) == *existing.ReceiptImagePath {
+		return nil, nil
+	}
+	return h.receiptImagePath(userID, existing.GroupID, path, legacyURL)
+}
+
+// proofUploadedBy reports whether a stored settlement proof was issued to the
+// settlement's creator for this group, so history never signs a path that was
+// saved before proofs were validated and points at someone else's upload.
+func proofUploadedBy(proofPath *string, groupID string, creatorID *string) bool {
+	if proofPath == nil || creatorID == nil {
+		return false
+	}
+	uploader, ok := receiptUploader(*proofPath, groupID)
+	return ok && uploader == *creatorID
+}
+
+func (h *Handlers) signReceiptURL(ctx context.Context, path *string, expiresIn time.Duration) *string {
+	if path == nil || *path == "" {
+		return nil
+	}
+	signedURL, err := h.storageService.CreateSignedURL(ctx, h.storageBucket, *path, expiresIn)
+	if err != nil {
+		zap.L().Warn("Failed to sign receipt URL", zap.String("path", *path), zap.Error(err))
+		return nil
+	}
+	return &signedURL
+}
+
+func (h *Handlers) signExpenseReceipt(ctx context.Context, expense *models.Expense, expiresIn time.Duration) {
+	if expense == nil {
+		return
+	}
+	expense.ReceiptImageURL = h.signReceiptURL(ctx, expense.ReceiptImagePath, expiresIn)
+	expense.SettlementProofURL = h.signReceiptURL(ctx, expense.SettlementProofPath, expiresIn)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReceiptImagePath(t *testing.T) {
+	h := &Handlers{storageBucket: "receipts"}
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		path     *string
+		legacy   *string
+		expected *string
+		wantErr  bool
+	}{
+		{name: "No receipt", expected: nil},
+		{name: "Uploaded for the group", path: str("u1/g1/abc_20240601_120000"), expected: str("u1/g1/abc_20240601_120000")},
+		{name: "Uploaded without a group", path: str("u1/personal/abc_20240601_120000"), expected: str("u1/personal/abc_20240601_120000")},
+		{name: "Signed URL of an own upload", legacy: str("https://x.supabase.co/storage/v1/object/sign/receipts/u1/g1/abc?token=t"), expected: str("u1/g1/abc")},
+		{name: "Another user's upload", path: str("u2/g1/abc"), wantErr: true},
+		{name: "Another group's upload", path: str("u1/g2/abc"), wantErr: true},
+		{name: "Pre-scoping upload", path: str("abc_20240601_120000"), wantErr: true},
+		{name: "Path traversal", path: str("u1/g1/../../u2/g2/abc"), wantErr: true},
+		{name: "Parent directory", path: str("u1/g1/.."), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.receiptImagePath("u1", "g1", tt.path, tt.legacy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("receiptImagePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("receiptImagePath() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReceiptUploadPathIsAccepted(t *testing.T) {
+	h := &Handlers{storageBucket: "receipts"}
+	groupID := "g1"
+	for _, scope := range []*string{&groupID, nil} {
+		path := receiptUploadPath("u1", scope)
+		if !strings.HasPrefix(path, "u1/") {
+			t.Errorf("receiptUploadPath() = %q, expected the user's prefix", path)
+		}
+		if _, err := h.receiptImagePath("u1", "g1", &path, nil); err != nil {
+			t.Errorf("receiptImagePath(%q) error = %v, expected the issued path to be accepted", path, err)
+		}
+	}
+}
+
+func TestProofUploadedBy(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		proof    *string
+		creator  *string
+		expected bool
+	}{
+		{name: "Creator's upload for the group", proof: str("u1/g1/abc"), creator: str("u1"), expected: true},
+		{name: "Creator's upload without a group", proof: str("u1/personal/abc"), creator: str("u1"), expected: true},
+		{name: "Someone else's upload", proof: str("u2/g1/abc"), creator: str("u1"), expected: false},
+		{name: "Another group's upload", proof: str("u1/g2/abc"), creator: str("u1"), expected: false},
+		{name: "Unscoped legacy path", proof: str("abc_20240601_120000"), creator: str("u1"), expected: false},
+		{name: "Unknown creator", proof: str("u1/g1/abc"), expected: false},
+		{name: "No proof", creator: str("u1"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proofUploadedBy(tt.proof, "g1", tt.creator); got != tt.expected {
+				t.Errorf("proofUploadedBy() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
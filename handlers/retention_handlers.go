@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RetentionHandlers struct {
+	retentionService services.RetentionService
+}
+
+func NewRetentionHandlers(retentionService services.RetentionService) *RetentionHandlers {
+	return &RetentionHandlers{
+		retentionService: retentionService,
+	}
+}
+
+func (h *RetentionHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/groups/{groupID}/retention", h.GetRetentionPolicy)
+	r.Put("/groups/{groupID}/retention", h.UpdateRetentionPolicy)
+}
+
+type UpdateRetentionPolicyRequest struct {
+	RetainYears *int   `json:"retain_years"`
+	Action      string `json:"action"`
+}
+
+func (h *RetentionHandlers) GetRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	policy, err := h.retentionService.GetPolicy(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+func (h *RetentionHandlers) UpdateRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req UpdateRetentionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.RetainYears == nil {
+		handleError(w, r, apperrors.MissingRequiredField("retain_years"))
+		return
+	}
+
+	action := models.RetentionAction(strings.ToUpper(strings.TrimSpace(req.Action)))
+	policy, err := h.retentionService.UpdatePolicy(r.Context(), groupID, userID, *req.RetainYears, action)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ShareHandlers struct {
+	shareService services.GroupShareService
+}
+
+func NewShareHandlers(shareService services.GroupShareService) *ShareHandlers {
+	return &ShareHandlers{
+		shareService: shareService,
+	}
+}
+
+func (h *ShareHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/groups/{groupID}/share-links", h.GetShareLinks)
+	r.Post("/groups/{groupID}/share-links", h.CreateShareLink)
+	r.Delete("/groups/{groupID}/share-links/{linkID}", h.RevokeShareLink)
+}
+
+func (h *ShareHandlers) RegisterPublicRoutes(r chi.Router) {
+	r.Get("/shared/groups/{token}", h.GetSharedLedger)
+}
+
+func (h *ShareHandlers) GetShareLinks(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	links, err := h.shareService.GetLinks(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, links)
+}
+
+func (h *ShareHandlers) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	link, err := h.shareService.CreateLink(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, link)
+}
+
+func (h *ShareHandlers) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	linkID, err := pathID(r, "linkID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.shareService.RevokeLink(r.Context(), groupID, linkID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Share link revoked"})
+}
+
+// Not cacheable by shared caches, so a revoked link stops working at once.
+func (h *ShareHandlers) GetSharedLedger(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(chi.URLParam(r, "token"))
+	if token == "" {
+		handleError(w, r, apperrors.NotFound("Share link"))
+		return
+	}
+
+	ledger, err := h.shareService.GetSharedLedger(r.Context(), token)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	respondJSON(w, http.StatusOK, ledger)
+}
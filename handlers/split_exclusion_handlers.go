@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/services"
+)
+
+type ExclusionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RequestExclusion lets a participant flag an expense as "I wasn't part of
+// this". The expense's creator is asked to accept or reject it.
+func (h *Handlers) RequestExclusion(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req ExclusionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if len(strings.TrimSpace(req.Reason)) > services.MaxExclusionReasonLength {
+		handleError(w, r, apperrors.InvalidRequest(fmt.Sprintf("Reason must be at most %d characters.", services.MaxExclusionReasonLength)))
+		return
+	}
+
+	expense, err := h.expenseService.RequestExclusion(r.Context(), expenseID, userID, req.Reason)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, expense)
+}
+
+func (h *Handlers) AcceptExclusion(w http.ResponseWriter, r *http.Request) {
+	h.resolveExclusion(w, r, true)
+}
+
+func (h *Handlers) RejectExclusion(w http.ResponseWriter, r *http.Request) {
+	h.resolveExclusion(w, r, false)
+}
+
+func (h *Handlers) resolveExclusion(w http.ResponseWriter, r *http.Request, accept bool) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	participantID, err := pathID(r, "userID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	expense, err := h.expenseService.ResolveExclusion(r.Context(), expenseID, userID, participantID, accept)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, expense)
+}
+
+type SplitLockRequest struct {
+	Locked *bool `json:"locked"`
+}
+
+// SetSplitLock locks or unlocks an expense's splits. Locked splits are left
+// alone by back-charges and exclusions and can't be edited until unlocked.
+func (h *Handlers) SetSplitLock(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	expenseID, err := pathID(r, "expenseID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req SplitLockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.Locked == nil {
+		handleError(w, r, apperrors.MissingRequiredField("locked"))
+		return
+	}
+
+	expense, err := h.expenseService.SetSplitLock(r.Context(), expenseID, userID, *req.Locked)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, expense)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SplitPreferenceHandlers struct {
+	splitPreferenceService services.SplitPreferenceService
+}
+
+func NewSplitPreferenceHandlers(splitPreferenceService services.SplitPreferenceService) *SplitPreferenceHandlers {
+	return &SplitPreferenceHandlers{
+		splitPreferenceService: splitPreferenceService,
+	}
+}
+
+type SetSplitPreferenceRequest struct {
+	UserPercentage *float64 `json:"user_percentage"`
+	GroupID        *string  `json:"group_id"`
+}
+
+func (h *SplitPreferenceHandlers) RegisterRoutes(r chi.Router) {
+	r.Route("/friends/{friendID}/split-preferences", func(r chi.Router) {
+		r.Get("/", h.GetPreferences)
+		r.Put("/", h.SetPreference)
+		r.Delete("/", h.DeletePreference)
+	})
+}
+
+func (h *SplitPreferenceHandlers) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, friendID, err := parseSplitPreferenceParams(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	preferences, err := h.splitPreferenceService.GetPreferences(r.Context(), userID, friendID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preferences)
+}
+
+func (h *SplitPreferenceHandlers) SetPreference(w http.ResponseWriter, r *http.Request) {
+	userID, friendID, err := parseSplitPreferenceParams(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req SetSplitPreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.UserPercentage == nil {
+		handleError(w, r, apperrors.MissingRequiredField("user_percentage"))
+		return
+	}
+	if req.GroupID != nil {
+		if *req.GroupID, err = parseID(*req.GroupID, "Group ID"); err != nil {
+			handleError(w, r, err)
+			return
+		}
+	}
+
+	preference, err := h.splitPreferenceService.SetPreference(r.Context(), userID, friendID, req.GroupID, *req.UserPercentage)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preference)
+}
+
+func (h *SplitPreferenceHandlers) DeletePreference(w http.ResponseWriter, r *http.Request) {
+	userID, friendID, err := parseSplitPreferenceParams(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var groupID *string
+	if value := r.URL.Query().Get("group_id"); value != "" {
+		if value, err = parseID(value, "Group ID"); err != nil {
+			handleError(w, r, err)
+			return
+		}
+		groupID = &value
+	}
+
+	if err := h.splitPreferenceService.DeletePreference(r.Context(), userID, friendID, groupID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Split preference deleted successfully"})
+}
+
+func parseSplitPreferenceParams(r *http.Request) (string, string, error) {
+	userID, err := getUserID(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	friendID, err := pathID(r, "friendID")
+	if err != nil {
+		return "", "", err
+	}
+	return userID, friendID, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type StandingRepaymentHandlers struct {
+	standingRepaymentService services.StandingRepaymentService
+}
+
+func NewStandingRepaymentHandlers(standingRepaymentService services.StandingRepaymentService) *StandingRepaymentHandlers {
+	return &StandingRepaymentHandlers{
+		standingRepaymentService: standingRepaymentService,
+	}
+}
+
+func (h *StandingRepaymentHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/groups/{groupID}/standing-repayments", h.GetStandingRepayments)
+	r.Post("/groups/{groupID}/standing-repayments", h.CreateStandingRepayment)
+	r.Delete("/groups/{groupID}/standing-repayments/{standingID}", h.CancelStandingRepayment)
+}
+
+type CreateStandingRepaymentRequest struct {
+	PayerID    string  `json:"payer_id"`
+	ReceiverID string  `json:"receiver_id"`
+	Amount     float64 `json:"amount"`
+	DayOfMonth int     `json:"day_of_month"`
+}
+
+func (h *StandingRepaymentHandlers) GetStandingRepayments(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	standing, err := h.standingRepaymentService.GetByGroupID(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, standing)
+}
+
+func (h *StandingRepaymentHandlers) CreateStandingRepayment(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	var req CreateStandingRepaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, r, apperrors.InvalidRequest("Invalid request body. Please provide valid JSON."))
+		return
+	}
+	if req.PayerID == "" {
+		handleError(w, r, apperrors.MissingRequiredField("payer_id"))
+		return
+	}
+	if req.ReceiverID == "" {
+		handleError(w, r, apperrors.MissingRequiredField("receiver_id"))
+		return
+	}
+
+	standing, err := h.standingRepaymentService.Create(r.Context(), groupID, userID, &models.StandingRepayment{
+		PayerID:    req.PayerID,
+		ReceiverID: req.ReceiverID,
+		Amount:     req.Amount,
+		DayOfMonth: req.DayOfMonth,
+	})
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, standing)
+}
+
+func (h *StandingRepaymentHandlers) CancelStandingRepayment(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	standingID, err := pathID(r, "standingID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.standingRepaymentService.Cancel(r.Context(), groupID, standingID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Standing repayment cancelled"})
+}
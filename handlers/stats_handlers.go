@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type StatsHandlers struct {
+	statsService services.StatsService
+}
+
+func NewStatsHandlers(statsService services.StatsService) *StatsHandlers {
+	return &StatsHandlers{
+		statsService: statsService,
+	}
+}
+
+func (h *StatsHandlers) RegisterRoutes(r chi.Router) {
+	r.Get("/groups/{groupID}/stats/fun", h.GetFunStats)
+	r.Get("/groups/{groupID}/analytics/heatmap", h.GetHeatmap)
+	r.Get("/groups/{groupID}/leaderboard", h.GetLeaderboard)
+}
+
+func (h *StatsHandlers) GetFunStats(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	stats, err := h.statsService.GetFunStats(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+func (h *StatsHandlers) GetHeatmap(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	heatmap, err := h.statsService.GetHeatmap(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, heatmap)
+}
+
+func (h *StatsHandlers) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	leaderboard, err := h.statsService.GetLeaderboard(r.Context(), groupID, userID, r.URL.Query().Get("period"))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, leaderboard)
+}
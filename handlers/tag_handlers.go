@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"unwise-backend/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TagHandlers struct {
+	tagService services.TagService
+}
+
+func NewTagHandlers(tagService services.TagService) *TagHandlers {
+	return &TagHandlers{
+		tagService: tagService,
+	}
+}
+
+func (h *TagHandlers) RegisterRoutes(r chi.Router) {
+	r.Route("/groups/{groupID}/tags", func(r chi.Router) {
+		r.Get("/", h.GetGroupTags)
+		r.Delete("/{tagID}", h.DeleteTag)
+	})
+}
+
+func (h *TagHandlers) GetGroupTags(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	tags, err := h.tagService.GetGroupTags(r.Context(), groupID, userID)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tags)
+}
+
+func (h *TagHandlers) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	groupID, err := pathID(r, "groupID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	tagID, err := pathID(r, "tagID")
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if err := h.tagService.DeleteTag(r.Context(), groupID, tagID, userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Tag deleted successfully"})
+}
@@ -14,6 +14,8 @@ import (
 	"sync"
 	"time"
 
+	"unwise-backend/services"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -23,9 +25,29 @@ const (
 	UserIDKey contextKey = "user_id"
 	EmailKey  contextKey = "email"
 	NameKey   contextKey = "name"
+
+	EmailVerifiedKey contextKey = "email_verified"
+	ScopesKey        contextKey = "scopes"
 )
 
+type TokenVerifier interface {
+	Verify(tokenString string) (jwt.MapClaims, error)
+}
+
+type AccountChecker interface {
+	IsDeleted(ctx context.Context, userID string) (bool, error)
+}
+
 type AuthMiddleware struct {
+	verifier TokenVerifier
+	accounts AccountChecker
+}
+
+func NewAuthMiddleware(verifier TokenVerifier, accounts AccountChecker) *AuthMiddleware {
+	return &AuthMiddleware{verifier: verifier, accounts: accounts}
+}
+
+type SupabaseVerifier struct {
 	jwtSecret    string
 	supabaseURL  string
 	publicKeyMu  sync.RWMutex
@@ -34,14 +56,42 @@ type AuthMiddleware struct {
 	fetchTimeout time.Duration
 }
 
-func NewAuthMiddleware(jwtSecret, supabaseURL string) *AuthMiddleware {
-	return &AuthMiddleware{
+func NewSupabaseVerifier(jwtSecret, supabaseURL string) *SupabaseVerifier {
+	return &SupabaseVerifier{
 		jwtSecret:    jwtSecret,
 		supabaseURL:  supabaseURL,
 		fetchTimeout: 1 * time.Hour,
 	}
 }
 
+type LocalVerifier struct {
+	jwtSecret string
+	issuer    string
+}
+
+func NewLocalVerifier(jwtSecret, issuer string) *LocalVerifier {
+	return &LocalVerifier{jwtSecret: jwtSecret, issuer: issuer}
+}
+
+func (v *LocalVerifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	if v.jwtSecret == "" {
+		return nil, fmt.Errorf("jwt secret not configured")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(v.jwtSecret), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithIssuer(v.issuer))
+	if err != nil {
+		return nil, err
+	}
+
+	if typ, _ := claims["typ"].(string); typ != "access" {
+		return nil, fmt.Errorf("token is not an access token")
+	}
+	return claims, nil
+}
+
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -62,57 +112,13 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if m.jwtSecret == "" {
-				log.Printf("[AUTH] JWT secret not configured")
-				return nil, fmt.Errorf("jwt secret not configured")
-			}
-
-			if strings.HasPrefix(m.jwtSecret, "eyJ") {
-				log.Printf("[AUTH] JWT secret appears to be a token, not a secret")
-				return nil, fmt.Errorf("SUPABASE_JWT_SECRET is set to a JWT token instead of the JWT secret string")
-			}
-
-			alg := token.Method.Alg()
-
-			switch alg {
-			case "HS256":
-				if decodedSecret, err := base64.StdEncoding.DecodeString(m.jwtSecret); err == nil {
-					return decodedSecret, nil
-				}
-				return []byte(m.jwtSecret), nil
-			case "ES256":
-				kid, _ := token.Header["kid"].(string)
-				publicKey, err := m.getSupabasePublicKey(kid)
-				if err != nil {
-					log.Printf("[AUTH] Failed to get Supabase public key: %v", err)
-					return nil, fmt.Errorf("ES256 verification failed: %w. Please ensure frontend sends access_token, not id_token", err)
-				}
-				return publicKey, nil
-			default:
-				log.Printf("[AUTH] Unexpected signing method: %v", alg)
-				return nil, fmt.Errorf("unexpected signing method: %v", alg)
-			}
-		})
-
+		claims, err := m.verifier.Verify(tokenString)
 		if err != nil {
-			log.Printf("[AUTH] Token parsing failed for %s %s: %v", r.Method, r.URL.Path, err)
+			log.Printf("[AUTH] Token verification failed for %s %s: %v", r.Method, r.URL.Path, err)
 			respondError(w, http.StatusUnauthorized, fmt.Sprintf("invalid token: %v", err))
 			return
 		}
 
-		if !token.Valid {
-			log.Printf("[AUTH] Token is invalid for %s %s", r.Method, r.URL.Path)
-			respondError(w, http.StatusUnauthorized, "invalid token")
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			log.Printf("[AUTH] Invalid token claims type for %s %s", r.Method, r.URL.Path)
-			respondError(w, http.StatusUnauthorized, "invalid token claims")
-			return
-		}
 		userID, ok := claims["sub"].(string)
 		if !ok {
 			log.Printf("[AUTH] User ID not found in token claims for %s %s (claims: %v)", r.Method, r.URL.Path, claims)
@@ -120,6 +126,19 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
+		if m.accounts != nil {
+			deleted, err := m.accounts.IsDeleted(r.Context(), userID)
+			if err != nil {
+				log.Printf("[AUTH] Account check failed for %s: %v", userID, err)
+				respondError(w, http.StatusServiceUnavailable, "could not verify account")
+				return
+			}
+			if deleted {
+				respondError(w, http.StatusGone, "account deleted")
+				return
+			}
+		}
+
 		email, _ := claims["email"].(string)
 		name := ""
 		if metadata, ok := claims["user_metadata"].(map[string]interface{}); ok {
@@ -127,6 +146,8 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 				name = n
 			}
 		}
+		emailVerified, hasEmailVerified := emailVerifiedClaim(claims)
+		scopes, hasScopes := scopeClaim(claims)
 
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
 		if email != "" {
@@ -135,10 +156,102 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		if name != "" {
 			ctx = context.WithValue(ctx, NameKey, name)
 		}
+		if hasEmailVerified {
+			ctx = context.WithValue(ctx, EmailVerifiedKey, emailVerified)
+			ctx = services.WithEmailVerified(ctx, emailVerified)
+		}
+		if hasScopes {
+			ctx = context.WithValue(ctx, ScopesKey, scopes)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// emailVerifiedClaim reads the email verification flag, which Supabase puts in
+// user_metadata. Tokens without it report ok=false so callers keep the
+// stored state instead of treating the email as unverified.
+func emailVerifiedClaim(claims map[string]interface{}) (verified bool, ok bool) {
+	if v, ok := claims["email_verified"].(bool); ok {
+		return v, true
+	}
+	if metadata, ok := claims["user_metadata"].(map[string]interface{}); ok {
+		if v, ok := metadata["email_verified"].(bool); ok {
+			return v, true
+		}
+	}
+	return false, false
+}
+
+// scopeClaim reads the OAuth "scope" claim (space separated) or the "scp"
+// claim (a list or a string). Interactive user sessions usually carry neither,
+// which ok=false reports so they are not treated as scoped-down tokens.
+func scopeClaim(claims map[string]interface{}) (scopes []string, ok bool) {
+	if s, ok := claims["scope"].(string); ok {
+		return strings.Fields(s), true
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp), true
+	case []interface{}:
+		for _, v := range scp {
+			if s, ok := v.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes, true
+	}
+	return nil, false
+}
+
+func (v *SupabaseVerifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if v.jwtSecret == "" {
+			log.Printf("[AUTH] JWT secret not configured")
+			return nil, fmt.Errorf("jwt secret not configured")
+		}
+
+		if strings.HasPrefix(v.jwtSecret, "eyJ") {
+			log.Printf("[AUTH] JWT secret appears to be a token, not a secret")
+			return nil, fmt.Errorf("SUPABASE_JWT_SECRET is set to a JWT token instead of the JWT secret string")
+		}
+
+		alg := token.Method.Alg()
+
+		switch alg {
+		case "HS256":
+			if decodedSecret, err := base64.StdEncoding.DecodeString(v.jwtSecret); err == nil {
+				return decodedSecret, nil
+			}
+			return []byte(v.jwtSecret), nil
+		case "ES256":
+			kid, _ := token.Header["kid"].(string)
+			publicKey, err := v.getSupabasePublicKey(kid)
+			if err != nil {
+				log.Printf("[AUTH] Failed to get Supabase public key: %v", err)
+				return nil, fmt.Errorf("ES256 verification failed: %w. Please ensure frontend sends access_token, not id_token", err)
+			}
+			return publicKey, nil
+		default:
+			log.Printf("[AUTH] Unexpected signing method: %v", alg)
+			return nil, fmt.Errorf("unexpected signing method: %v", alg)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("token is invalid")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	return claims, nil
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -161,36 +274,61 @@ func GetUserName(ctx context.Context) (string, bool) {
 	return name, ok
 }
 
-func (m *AuthMiddleware) getSupabasePublicKey(kid string) (*ecdsa.PublicKey, error) {
-	m.publicKeyMu.RLock()
-	if m.publicKeys != nil && time.Since(m.lastFetch) < m.fetchTimeout {
-		if key, ok := m.publicKeys[kid]; ok {
-			defer m.publicKeyMu.RUnlock()
+func GetEmailVerified(ctx context.Context) (bool, bool) {
+	verified, ok := ctx.Value(EmailVerifiedKey).(bool)
+	return verified, ok
+}
+
+func GetScopes(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(ScopesKey).([]string)
+	return scopes, ok
+}
+
+// Tokens without a scope claim are user sessions and get every scope.
+// Service-role tokens never reach this, as they have no sub to act as.
+func HasScope(ctx context.Context, scope string) bool {
+	scopes, ok := GetScopes(ctx)
+	if !ok {
+		return true
+	}
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *SupabaseVerifier) getSupabasePublicKey(kid string) (*ecdsa.PublicKey, error) {
+	v.publicKeyMu.RLock()
+	if v.publicKeys != nil && time.Since(v.lastFetch) < v.fetchTimeout {
+		if key, ok := v.publicKeys[kid]; ok {
+			defer v.publicKeyMu.RUnlock()
 			return key, nil
 		}
-		if kid == "" && len(m.publicKeys) > 0 {
-			for _, key := range m.publicKeys {
-				defer m.publicKeyMu.RUnlock()
+		if kid == "" && len(v.publicKeys) > 0 {
+			for _, key := range v.publicKeys {
+				defer v.publicKeyMu.RUnlock()
 				return key, nil
 			}
 		}
 	}
-	m.publicKeyMu.RUnlock()
+	v.publicKeyMu.RUnlock()
 
-	m.publicKeyMu.Lock()
-	defer m.publicKeyMu.Unlock()
+	v.publicKeyMu.Lock()
+	defer v.publicKeyMu.Unlock()
 
-	if m.publicKeys != nil && time.Since(m.lastFetch) < m.fetchTimeout {
-		if key, ok := m.publicKeys[kid]; ok {
+	if v.publicKeys != nil && time.Since(v.lastFetch) < v.fetchTimeout {
+		if key, ok := v.publicKeys[kid]; ok {
 			return key, nil
 		}
 	}
 
-	if m.supabaseURL == "" {
+	if v.supabaseURL == "" {
 		return nil, fmt.Errorf("SUPABASE_URL not configured")
 	}
 
-	baseURL := strings.TrimSuffix(m.supabaseURL, "/")
+	baseURL := strings.TrimSuffix(v.supabaseURL, "/")
 	jwksURLs := []string{
 		baseURL + "/auth/v1/.well-known/jwks.json",
 		baseURL + "/.well-known/jwks.json",
@@ -252,19 +390,19 @@ func (m *AuthMiddleware) getSupabasePublicKey(kid string) (*ecdsa.PublicKey, err
 		newPublicKeys[key.Kid] = pubKey
 	}
 
-	m.publicKeys = newPublicKeys
-	m.lastFetch = time.Now()
+	v.publicKeys = newPublicKeys
+	v.lastFetch = time.Now()
 
-	if key, ok := m.publicKeys[kid]; ok {
+	if key, ok := v.publicKeys[kid]; ok {
 		return key, nil
 	}
-	if kid == "" && len(m.publicKeys) > 0 {
-		for _, key := range m.publicKeys {
+	if kid == "" && len(v.publicKeys) > 0 {
+		for _, key := range v.publicKeys {
 			return key, nil
 		}
 	}
 
-	return nil, fmt.Errorf("key with kid %s not found in JWKS (available keys: %d)", kid, len(m.publicKeys))
+	return nil, fmt.Errorf("key with kid %s not found in JWKS (available keys: %d)", kid, len(v.publicKeys))
 }
 
 func getCurve(crv string) *elliptic.CurveParams {
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type fakeVerifier struct {
+	claims jwt.MapClaims
+}
+
+func (v fakeVerifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	return v.claims, nil
+}
+
+type fakeAccounts struct {
+	deleted map[string]bool
+	err     error
+}
+
+func (a fakeAccounts) IsDeleted(ctx context.Context, userID string) (bool, error) {
+	return a.deleted[userID], a.err
+}
+
+func TestAuthenticateRejectsDeletedAccounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		accounts AccountChecker
+		expected int
+	}{
+		{name: "Active account", accounts: fakeAccounts{}, expected: http.StatusOK},
+		{name: "Deleted account", accounts: fakeAccounts{deleted: map[string]bool{"u1": true}}, expected: http.StatusGone},
+		{name: "Lookup failure", accounts: fakeAccounts{err: errors.New("connection refused")}, expected: http.StatusServiceUnavailable},
+		{name: "No checker", accounts: nil, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewAuthMiddleware(fakeVerifier{claims: jwt.MapClaims{"sub": "u1"}}, tt.accounts)
+			handler := m.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/groups", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("Authenticate() status = %d, expected %d", rec.Code, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScopeClaim(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   map[string]interface{}
+		expected []string
+		ok       bool
+	}{
+		{name: "Scope String", claims: map[string]interface{}{"scope": "read ai"}, expected: []string{"read", "ai"}, ok: true},
+		{name: "Scp String", claims: map[string]interface{}{"scp": "ai"}, expected: []string{"ai"}, ok: true},
+		{name: "Scp Array", claims: map[string]interface{}{"scp": []interface{}{"read", 7, "ai"}}, expected: []string{"read", "ai"}, ok: true},
+		{name: "Empty Scope", claims: map[string]interface{}{"scope": ""}, expected: []string{}, ok: true},
+		{name: "Scope Wins Over Scp", claims: map[string]interface{}{"scope": "read", "scp": []interface{}{"ai"}}, expected: []string{"read"}, ok: true},
+		{name: "No Claim", claims: map[string]interface{}{"sub": "u1"}},
+		{name: "Unexpected Type", claims: map[string]interface{}{"scp": 42.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scopes, ok := scopeClaim(tt.claims)
+			if ok != tt.ok || !reflect.DeepEqual(scopes, tt.expected) {
+				t.Errorf("scopeClaim() = %v, %v, expected %v, %v", scopes, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestEmailVerifiedClaim(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   map[string]interface{}
+		verified bool
+		ok       bool
+	}{
+		{name: "Verified", claims: map[string]interface{}{"email_verified": true}, verified: true, ok: true},
+		{name: "Unverified", claims: map[string]interface{}{"email_verified": false}, ok: true},
+		{name: "User Metadata", claims: map[string]interface{}{"user_metadata": map[string]interface{}{"email_verified": true}}, verified: true, ok: true},
+		{name: "Top Level Wins", claims: map[string]interface{}{"email_verified": false, "user_metadata": map[string]interface{}{"email_verified": true}}, ok: true},
+		{name: "Missing", claims: map[string]interface{}{"sub": "u1"}},
+		{name: "Unexpected Type", claims: map[string]interface{}{"email_verified": "true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, ok := emailVerifiedClaim(tt.claims)
+			if verified != tt.verified || ok != tt.ok {
+				t.Errorf("emailVerifiedClaim() = %v, %v, expected %v, %v", verified, ok, tt.verified, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAuthenticateSetsEmailVerified(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		verified bool
+		ok       bool
+	}{
+		{name: "Verified", claims: jwt.MapClaims{"sub": "u1", "email_verified": true}, verified: true, ok: true},
+		{name: "Unverified", claims: jwt.MapClaims{"sub": "u1", "email_verified": false}, ok: true},
+		{name: "Missing", claims: jwt.MapClaims{"sub": "u1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified, ok bool
+			m := NewAuthMiddleware(fakeVerifier{claims: tt.claims}, nil)
+			handler := m.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				verified, ok = GetEmailVerified(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/groups", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if verified != tt.verified || ok != tt.ok {
+				t.Errorf("GetEmailVerified() = %v, %v, expected %v, %v", verified, ok, tt.verified, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		expected int
+	}{
+		{name: "User Session", claims: jwt.MapClaims{"sub": "u1"}, expected: http.StatusOK},
+		{name: "Scope Granted", claims: jwt.MapClaims{"sub": "u1", "scope": "read ai"}, expected: http.StatusOK},
+		{name: "Scp Array Granted", claims: jwt.MapClaims{"sub": "u1", "scp": []interface{}{"ai"}}, expected: http.StatusOK},
+		{name: "Scope Missing", claims: jwt.MapClaims{"sub": "u1", "scope": "read"}, expected: http.StatusForbidden},
+		{name: "Empty Scp Array", claims: jwt.MapClaims{"sub": "u1", "scp": []interface{}{}}, expected: http.StatusForbidden},
+		{name: "Service Role Without Sub", claims: jwt.MapClaims{"role": "service_role"}, expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewAuthMiddleware(fakeVerifier{claims: tt.claims}, nil)
+			handler := m.Authenticate(RequireScope(ScopeAI)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/expenses/parse", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("RequireScope() status = %d, expected %d", rec.Code, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+
+	"unwise-backend/database"
+
+	"go.uber.org/zap"
+)
+
+// ConsistencyTokenHeader carries a consistency token: sent back by successful
+// writes, and accepted by any request that must see that write.
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+// Consistency hands out and honours consistency tokens. A successful POST,
+// PUT or DELETE gets a token covering its write in ConsistencyTokenHeader. A
+// request that sends a token back, in the header or the consistency_token
+// query parameter, is served results at least as new as that write: caches
+// computed before it are skipped.
+func Consistency(db *database.DB) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(ConsistencyTokenHeader)
+			if value == "" {
+				value = r.URL.Query().Get("consistency_token")
+			}
+			if value != "" {
+				token, err := database.ParseConsistencyToken(value)
+				if err != nil {
+					respondError(w, http.StatusBadRequest, "Invalid "+ConsistencyTokenHeader)
+					return
+				}
+				r = r.WithContext(database.WithMinConsistency(r.Context(), token))
+			}
+
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+				w = &consistencyWriter{ResponseWriter: w, r: r, db: db}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// consistencyWriter adds a token to successful write responses just before
+// the headers go out, after the handler has committed.
+type consistencyWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	db          *database.DB
+	wroteHeader bool
+}
+
+func (w *consistencyWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if status < http.StatusBadRequest {
+			if token, err := w.db.CurrentConsistencyToken(w.r.Context()); err != nil {
+				zap.L().Warn("Failed to issue consistency token", zap.String("route", routePattern(w.r)), zap.Error(err))
+			} else {
+				w.Header().Set(ConsistencyTokenHeader, token.String())
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *consistencyWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *consistencyWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *consistencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
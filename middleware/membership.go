@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"net/http"
+
+	"unwise-backend/services"
+)
+
+func MembershipMemo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(services.WithMembershipMemo(r.Context())))
+	})
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"unwise-backend/database"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+const QueryCountHeader = "X-Query-Count"
+
+const maxLoggedStatementLength = 300
+
+func QueryBudget(budget, repeatThreshold int, exposeHeader bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, counter := database.WithQueryCounter(r.Context())
+			if exposeHeader {
+				w = &queryCountWriter{ResponseWriter: w, counter: counter}
+			}
+			r = r.WithContext(ctx)
+
+			next.ServeHTTP(w, r)
+
+			total := counter.Total()
+			statement, repeats := counter.MostRepeated()
+			if total <= budget && repeats < repeatThreshold {
+				return
+			}
+			if len(statement) > maxLoggedStatementLength {
+				statement = statement[:maxLoggedStatementLength] + "..."
+			}
+			message := "Request exceeded query budget"
+			if repeats >= repeatThreshold {
+				message = "Request repeated a query, possible N+1"
+			}
+			zap.L().Warn(message,
+				zap.String("method", r.Method),
+				zap.String("route", routePattern(r)),
+				zap.Int("queries", total),
+				zap.Int("budget", budget),
+				zap.String("most_repeated", statement),
+				zap.Int("repeats", repeats),
+				zap.String("request_id", middleware.GetReqID(r.Context())))
+		})
+	}
+}
+
+// The route pattern groups warnings for different IDs together.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
+
+// Statements run after the headers go out are logged but can't be reported.
+type queryCountWriter struct {
+	http.ResponseWriter
+	counter     *database.QueryCounter
+	wroteHeader bool
+}
+
+func (w *queryCountWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set(QueryCountHeader, strconv.Itoa(w.counter.Total()))
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *queryCountWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *queryCountWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *queryCountWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
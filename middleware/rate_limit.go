@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	apperrors "unwise-backend/errors"
+)
+
+// bucketIdleTTL is how long an untouched bucket is kept; by then it has
+// refilled, so dropping it loses nothing.
+const bucketIdleTTL = 10 * time.Minute
+
+type RateLimitQuota struct {
+	Route             string `json:"route"`
+	RequestsPerMinute int    `json:"requests_per_minute"`
+	Burst             int    `json:"burst"`
+	Remaining         int    `json:"remaining"`
+}
+
+type RateLimitResponse struct {
+	Error             string         `json:"error"`
+	Code              string         `json:"code"`
+	RetryAfterSeconds int            `json:"retry_after_seconds"`
+	Quota             RateLimitQuota `json:"quota"`
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// TokenBucketLimiter gives each key a bucket of burst tokens that refills at
+// perMinute tokens per minute, so short spikes pass while sustained load is
+// slowed down.
+type TokenBucketLimiter struct {
+	route     string
+	perMinute int
+	burst     int
+	now       func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func NewTokenBucketLimiter(route string, perMinute, burst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		route:     route,
+		perMinute: perMinute,
+		burst:     burst,
+		now:       time.Now,
+		buckets:   make(map[string]*tokenBucket),
+	}
+}
+
+// Allow takes a token from key's bucket. When none is left it reports how
+// long until the next token arrives.
+func (l *TokenBucketLimiter) Allow(key string) (allowed bool, remaining int, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	rate := float64(l.perMinute) / time.Minute.Seconds()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.burst), lastSeen: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.lastSeen).Seconds()*rate)
+		b.lastSeen = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, int(b.tokens), 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, 0, wait
+}
+
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= bucketIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Handler limits requests per authenticated user, falling back to the client
+// address for anonymous requests.
+func (l *TokenBucketLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := GetUserID(r.Context())
+		if !ok {
+			key = "ip:" + r.RemoteAddr
+		}
+
+		allowed, remaining, retryAfter := l.Allow(key)
+		w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", l.perMinute))
+		w.Header().Set("X-RateLimit-Burst", fmt.Sprintf("%d", l.burst))
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
+		if allowed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", seconds))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(RateLimitResponse{
+			Error:             fmt.Sprintf("Too many %s requests. Please try again in %d seconds.", l.route, seconds),
+			Code:              string(apperrors.CodeRateLimited),
+			RetryAfterSeconds: seconds,
+			Quota: RateLimitQuota{
+				Route:             l.route,
+				RequestsPerMinute: l.perMinute,
+				Burst:             l.burst,
+				Remaining:         remaining,
+			},
+		})
+	})
+}
+
+// LimitByUser is a per-user token bucket for one route family, used like
+// httprate.LimitByIP.
+func LimitByUser(route string, perMinute, burst int) func(http.Handler) http.Handler {
+	return NewTokenBucketLimiter(route, perMinute, burst).Handler
+}
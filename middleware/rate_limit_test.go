@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apperrors "unwise-backend/errors"
+)
+
+func TestTokenBucketLimiterAllow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	l := NewTokenBucketLimiter("export", 6, 2)
+	l.now = func() time.Time { return now }
+
+	steps := []struct {
+		name              string
+		at                time.Duration
+		key               string
+		expectedAllowed   bool
+		expectedRemaining int
+		expectedRetry     time.Duration
+	}{
+		{name: "Burst First", at: 0, key: "alice", expectedAllowed: true, expectedRemaining: 1},
+		{name: "Burst Second", at: 0, key: "alice", expectedAllowed: true, expectedRemaining: 0},
+		{name: "Burst Spent", at: 0, key: "alice", expectedAllowed: false, expectedRetry: 10 * time.Second},
+		{name: "Other Keys Unaffected", at: 0, key: "bob", expectedAllowed: true, expectedRemaining: 1},
+		{name: "Partly Refilled", at: 4 * time.Second, key: "alice", expectedAllowed: false, expectedRetry: 6 * time.Second},
+		{name: "One Token Refilled", at: 10 * time.Second, key: "alice", expectedAllowed: true, expectedRemaining: 0},
+		{name: "Refill Capped At Burst", at: time.Hour, key: "alice", expectedAllowed: true, expectedRemaining: 1},
+	}
+
+	for _, step := range steps {
+		now = start.Add(step.at)
+		allowed, remaining, retry := l.Allow(step.key)
+		retry = retry.Round(time.Millisecond)
+		if allowed != step.expectedAllowed || remaining != step.expectedRemaining || retry != step.expectedRetry {
+			t.Errorf("%s: Allow() = (%v, %d, %v), expected (%v, %d, %v)",
+				step.name, allowed, remaining, retry, step.expectedAllowed, step.expectedRemaining, step.expectedRetry)
+		}
+	}
+}
+
+func TestTokenBucketLimiterSweepsIdleBuckets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewTokenBucketLimiter("import", 1, 1)
+	l.now = func() time.Time { return now }
+
+	l.Allow("alice")
+	now = now.Add(bucketIdleTTL)
+	l.Allow("bob")
+
+	if _, ok := l.buckets["alice"]; ok {
+		t.Error("Allow() kept an idle bucket past bucketIdleTTL")
+	}
+	if _, ok := l.buckets["bob"]; !ok {
+		t.Error("Allow() dropped the bucket it just used")
+	}
+}
+
+func TestTokenBucketLimiterHandler(t *testing.T) {
+	l := NewTokenBucketLimiter("export", 1, 1)
+	handler := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/export", nil)
+		if userID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request("alice"); rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, expected %d", rec.Code, http.StatusOK)
+	}
+
+	rec := request("alice")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, expected %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, expected %q", got, "60")
+	}
+	var body RateLimitResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if body.Code != string(apperrors.CodeRateLimited) || body.RetryAfterSeconds != 60 || body.Quota.Route != "export" || body.Quota.Burst != 1 {
+		t.Errorf("body = %+v, expected a rate limited export quota", body)
+	}
+
+	if rec := request(""); rec.Code != http.StatusOK {
+		t.Errorf("anonymous request status = %d, expected its own bucket keyed by address", rec.Code)
+	}
+}
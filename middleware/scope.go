@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+const ScopeAI = "ai"
+
+// RequireScope rejects requests whose token does not grant scope, see HasScope.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasScope(r.Context(), scope) {
+				respondError(w, http.StatusForbidden, "Token is missing the required \""+scope+"\" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	apperrors "unwise-backend/errors"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// ErrLatencyBudgetExceeded is the cancellation cause of a request that ran
+// past its latency budget. Check it with context.Cause.
+var ErrLatencyBudgetExceeded = errors.New("latency budget exceeded")
+
+const latencyBudgetKey contextKey = "latency_budget"
+
+// latencyBudget is the deadline of one request. Unlike a context deadline it
+// can be moved later, so a route can ask for more time than the default.
+type latencyBudget struct {
+	start time.Time
+	timer *time.Timer
+
+	mu     sync.Mutex
+	route  string
+	budget time.Duration
+}
+
+func (b *latencyBudget) set(route string, budget time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.route, b.budget = route, budget
+	b.timer.Reset(time.Until(b.start.Add(budget)))
+}
+
+func (b *latencyBudget) current() (string, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.route, b.budget
+}
+
+// LatencyBudget cancels each request's context once defaultBudget has passed
+// since it arrived; RouteTimeout changes the budget for a route family.
+// Handlers stop cooperatively when their context is cancelled. If one returns
+// without writing a response, a 504 TIMEOUT_001 error is sent for it.
+func LatencyBudget(defaultBudget time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancelCause(r.Context())
+			defer cancel(nil)
+
+			b := &latencyBudget{start: time.Now(), route: "default", budget: defaultBudget}
+			b.timer = time.AfterFunc(defaultBudget, func() { cancel(ErrLatencyBudgetExceeded) })
+			defer b.timer.Stop()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(ctx, latencyBudgetKey, b)))
+
+			if !errors.Is(context.Cause(ctx), ErrLatencyBudgetExceeded) {
+				return
+			}
+			route, budget := b.current()
+			timeouts.record(route)
+			zap.L().Warn("Request exceeded latency budget",
+				zap.String("route", route),
+				zap.Duration("budget", budget),
+				zap.Duration("elapsed", time.Since(b.start)),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("request_id", middleware.GetReqID(r.Context())))
+
+			if ww.Status() == 0 {
+				writeTimeout(ww, r)
+			}
+		})
+	}
+}
+
+// RouteTimeout sets the latency budget for the routes it wraps, counted from
+// when the request arrived. route names the family in logs and metrics.
+// Outside LatencyBudget it falls back to a plain context deadline.
+func RouteTimeout(route string, budget time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if b, ok := r.Context().Value(latencyBudgetKey).(*latencyBudget); ok {
+				b.set(route, budget)
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeoutCause(r.Context(), budget, ErrLatencyBudgetExceeded)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// IsLatencyBudgetExceeded reports whether ctx was cancelled by its budget.
+func IsLatencyBudgetExceeded(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrLatencyBudgetExceeded)
+}
+
+func writeTimeout(w http.ResponseWriter, r *http.Request) {
+	appErr := apperrors.RequestTimeout()
+	lang := apperrors.MatchLanguage(r.Header.Get("Accept-Language"))
+	message, details := appErr.Localize(lang)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	w.WriteHeader(apperrors.GetHTTPStatus(appErr.Type))
+	json.NewEncoder(w).Encode(timeoutResponse{
+		Error:   message,
+		Code:    string(appErr.Code),
+		Details: details,
+	})
+}
+
+type timeoutResponse struct {
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+	Details string `json:"details,omitempty"`
+}
+
+type timeoutCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+var timeouts = &timeoutCounter{counts: make(map[string]int64)}
+
+func (c *timeoutCounter) record(route string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[route]++
+}
+
+// TimeoutCounts returns how many requests ran out of budget per route family
+// since the process started.
+func TimeoutCounts() map[string]int64 {
+	timeouts.mu.Lock()
+	defer timeouts.mu.Unlock()
+	counts := make(map[string]int64, len(timeouts.counts))
+	for route, n := range timeouts.counts {
+		counts[route] = n
+	}
+	return counts
+}
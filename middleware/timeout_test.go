@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apperrors "unwise-backend/errors"
+)
+
+// waitForCancel stands in for a handler that stops when its context is
+// cancelled without writing anything.
+var waitForCancel = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-r.Context().Done():
+	case <-time.After(time.Second):
+		w.WriteHeader(http.StatusOK)
+	}
+})
+
+func TestLatencyBudgetTimesOut(t *testing.T) {
+	before := TimeoutCounts()["slow-test"]
+	handler := LatencyBudget(10 * time.Millisecond)(RouteTimeout("slow-test", 20*time.Millisecond)(waitForCancel))
+
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	req.Header.Set("Accept-Language", "fr")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, expected %d", rec.Code, http.StatusGatewayTimeout)
+	}
+	var body timeoutResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if body.Code != string(apperrors.CodeRequestTimeout) || rec.Header().Get("Content-Language") != "fr" {
+		t.Errorf("body = %+v with Content-Language %q, expected a French timeout error", body, rec.Header().Get("Content-Language"))
+	}
+	if got := TimeoutCounts()["slow-test"]; got != before+1 {
+		t.Errorf("TimeoutCounts()[slow-test] = %d, expected %d", got, before+1)
+	}
+}
+
+func TestRouteTimeoutExtendsBudget(t *testing.T) {
+	handler := LatencyBudget(5 * time.Millisecond)(RouteTimeout("export-test", time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		if IsLatencyBudgetExceeded(r.Context()) {
+			t.Error("IsLatencyBudgetExceeded() = true, expected the route budget to replace the default")
+		}
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, expected %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestLatencyBudgetKeepsWrittenResponse(t *testing.T) {
+	handler := LatencyBudget(5 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, expected the handler's own %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestRouteTimeoutWithoutLatencyBudget(t *testing.T) {
+	var exceeded bool
+	handler := RouteTimeout("bare-test", 5*time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		exceeded = IsLatencyBudgetExceeded(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !exceeded {
+		t.Error("IsLatencyBudgetExceeded() = false, expected the plain deadline to carry the budget cause")
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS group_notification_settings;
+DROP INDEX IF EXISTS idx_notifications_user_unread;
+DROP INDEX IF EXISTS idx_notifications_user_created;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,28 @@
+-- In-app notifications produced by the notification dispatcher
+CREATE TABLE notifications (
+    id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE,
+    expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE CASCADE,
+    actor_id VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    event VARCHAR(30) NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_user_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Per (user, group) notification preferences. Missing rows mean "everything on".
+CREATE TABLE group_notification_settings (
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    muted BOOLEAN NOT NULL DEFAULT FALSE,
+    new_expenses BOOLEAN NOT NULL DEFAULT TRUE,
+    comments BOOLEAN NOT NULL DEFAULT TRUE,
+    settlements BOOLEAN NOT NULL DEFAULT TRUE,
+    reminders BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
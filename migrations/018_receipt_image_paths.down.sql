@@ -0,0 +1,4 @@
+-- Rollback: Restore receipt_image_url column
+-- Stored values remain object paths; public URLs are not reconstructed.
+
+ALTER TABLE expenses RENAME COLUMN receipt_image_path TO receipt_image_url;
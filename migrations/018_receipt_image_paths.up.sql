@@ -0,0 +1,14 @@
+-- Migration: Store receipt images as object paths
+-- Receipt URLs are now minted as short-lived signed URLs on read, so only the
+-- storage object path is persisted.
+
+ALTER TABLE expenses RENAME COLUMN receipt_image_url TO receipt_image_path;
+
+-- Convert existing public/signed URLs into bare object paths
+UPDATE expenses
+SET receipt_image_path = regexp_replace(
+    split_part(receipt_image_path, '?', 1),
+    '^.*/storage/v1/object/(public/|sign/)?[^/]+/',
+    ''
+)
+WHERE receipt_image_path LIKE '%/storage/v1/object/%';
@@ -0,0 +1,20 @@
+-- Rollback: Restore original cascade behavior
+
+ALTER TABLE receipt_item_assignments DROP CONSTRAINT IF EXISTS receipt_item_assignments_user_id_fkey;
+ALTER TABLE receipt_item_assignments ADD CONSTRAINT receipt_item_assignments_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE expense_payers DROP CONSTRAINT IF EXISTS expense_payers_user_id_fkey;
+ALTER TABLE expense_payers ADD CONSTRAINT expense_payers_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE expense_splits DROP CONSTRAINT IF EXISTS expense_splits_user_id_fkey;
+ALTER TABLE expense_splits ADD CONSTRAINT expense_splits_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_paid_by_user_id_fkey;
+ALTER TABLE expenses ADD CONSTRAINT expenses_paid_by_user_id_fkey
+    FOREIGN KEY (paid_by_user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
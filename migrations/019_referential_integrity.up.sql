@@ -0,0 +1,25 @@
+-- Migration: Tighten referential integrity and define delete behavior
+-- Users are soft-deleted so that shared financial history (payers, splits,
+-- item assignments) never loses one side of a transaction. Hard deletes of a
+-- user with financial history are now rejected by the database.
+
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Deleting a payer must not delete the whole expense
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_paid_by_user_id_fkey;
+ALTER TABLE expenses ADD CONSTRAINT expenses_paid_by_user_id_fkey
+    FOREIGN KEY (paid_by_user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Financial rows must not silently disappear with a user
+ALTER TABLE expense_splits DROP CONSTRAINT IF EXISTS expense_splits_user_id_fkey;
+ALTER TABLE expense_splits ADD CONSTRAINT expense_splits_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE expense_payers DROP CONSTRAINT IF EXISTS expense_payers_user_id_fkey;
+ALTER TABLE expense_payers ADD CONSTRAINT expense_payers_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE receipt_item_assignments DROP CONSTRAINT IF EXISTS receipt_item_assignments_user_id_fkey;
+ALTER TABLE receipt_item_assignments ADD CONSTRAINT receipt_item_assignments_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
@@ -0,0 +1,3 @@
+-- Rollback: Remove local credentials
+
+DROP TABLE IF EXISTS user_credentials;
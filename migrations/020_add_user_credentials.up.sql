@@ -0,0 +1,9 @@
+-- Migration: Local email/password credentials for self-hosted auth mode
+-- Only used when AUTH_PROVIDER=local; Supabase deployments leave this empty.
+
+CREATE TABLE user_credentials (
+    user_id VARCHAR(255) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
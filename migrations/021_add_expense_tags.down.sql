@@ -0,0 +1,5 @@
+-- Rollback: Remove expense tags
+
+DROP INDEX IF EXISTS idx_expense_tags_tag_id;
+DROP TABLE IF EXISTS expense_tags;
+DROP TABLE IF EXISTS tags;
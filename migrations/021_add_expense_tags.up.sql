@@ -0,0 +1,19 @@
+-- Migration: Per-group expense tags
+-- Tags are free-form labels scoped to a group and attached to expenses
+-- through a many-to-many link table.
+
+CREATE TABLE tags (
+    id VARCHAR(255) PRIMARY KEY,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (group_id, name)
+);
+
+CREATE TABLE expense_tags (
+    expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE CASCADE NOT NULL,
+    tag_id VARCHAR(255) REFERENCES tags(id) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (expense_id, tag_id)
+);
+
+CREATE INDEX idx_expense_tags_tag_id ON expense_tags(tag_id);
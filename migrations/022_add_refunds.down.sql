@@ -0,0 +1,10 @@
+-- Rollback: Remove REFUND transactions
+
+DELETE FROM expenses WHERE category = 'REFUND';
+
+DROP INDEX IF EXISTS idx_expenses_original_expense_id;
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_refund_link_check;
+ALTER TABLE expenses DROP COLUMN IF EXISTS original_expense_id;
+
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_category_check;
+ALTER TABLE expenses ADD CONSTRAINT expenses_category_check CHECK (category IN ('EXPENSE', 'REPAYMENT', 'PAYMENT'));
@@ -0,0 +1,12 @@
+-- Migration: Add REFUND transactions linked to the expense they reverse
+-- Refunds are stored with negative amounts so existing balance queries net them out.
+
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_category_check;
+ALTER TABLE expenses ADD CONSTRAINT expenses_category_check CHECK (category IN ('EXPENSE', 'REPAYMENT', 'PAYMENT', 'REFUND'));
+
+ALTER TABLE expenses ADD COLUMN original_expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE CASCADE;
+
+ALTER TABLE expenses ADD CONSTRAINT expenses_refund_link_check
+    CHECK ((category = 'REFUND') = (original_expense_id IS NOT NULL));
+
+CREATE INDEX idx_expenses_original_expense_id ON expenses(original_expense_id) WHERE original_expense_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Rollback: Per-member read receipts for group transactions
+
+DROP TABLE IF EXISTS expense_reads;
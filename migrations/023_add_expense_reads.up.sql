@@ -0,0 +1,11 @@
+-- Migration: Per-member read receipts for group transactions
+-- A row means the member has seen the transaction; seen_at keeps the first view.
+
+CREATE TABLE expense_reads (
+    expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE CASCADE NOT NULL,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (expense_id, user_id)
+);
+
+CREATE INDEX idx_expense_reads_user_id ON expense_reads(user_id);
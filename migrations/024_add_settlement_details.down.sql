@@ -0,0 +1,6 @@
+-- Rollback: Record how a settlement was paid
+
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_settlement_method_check;
+ALTER TABLE expenses DROP COLUMN IF EXISTS settlement_proof_path;
+ALTER TABLE expenses DROP COLUMN IF EXISTS settlement_reference;
+ALTER TABLE expenses DROP COLUMN IF EXISTS settlement_method;
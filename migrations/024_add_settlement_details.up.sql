@@ -0,0 +1,10 @@
+-- Migration: Record how a settlement was paid
+-- Method, an optional external reference (e.g. UPI transaction ID) and an
+-- optional proof image stored as an object path like receipts.
+
+ALTER TABLE expenses ADD COLUMN settlement_method VARCHAR(20);
+ALTER TABLE expenses ADD COLUMN settlement_reference VARCHAR(100);
+ALTER TABLE expenses ADD COLUMN settlement_proof_path TEXT;
+
+ALTER TABLE expenses ADD CONSTRAINT expenses_settlement_method_check
+    CHECK (settlement_method IS NULL OR settlement_method IN ('CASH', 'UPI', 'BANK_TRANSFER', 'CARD', 'OTHER'));
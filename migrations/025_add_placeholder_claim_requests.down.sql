@@ -0,0 +1,3 @@
+-- Rollback: Admin-approved placeholder claims
+
+DROP TABLE IF EXISTS placeholder_claim_requests;
@@ -0,0 +1,18 @@
+-- Migration: Admin-approved placeholder claims
+-- Used when PLACEHOLDER_CLAIM_POLICY=approval; a claim waits here until an admin decides.
+
+CREATE TABLE placeholder_claim_requests (
+    id VARCHAR(255) PRIMARY KEY,
+    placeholder_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    decided_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_placeholder_claim_requests_pending
+    ON placeholder_claim_requests(placeholder_id, user_id)
+    WHERE status = 'PENDING';
+
+CREATE INDEX idx_placeholder_claim_requests_status ON placeholder_claim_requests(status, created_at);
@@ -0,0 +1,4 @@
+-- Rollback: Outbound chat integrations per group
+
+DROP TABLE IF EXISTS integration_deliveries;
+DROP TABLE IF EXISTS group_integrations;
@@ -0,0 +1,36 @@
+-- Migration: Outbound chat integrations per group
+-- group_integrations holds the channel webhook; integration_deliveries is the outbox drained by the delivery worker.
+
+CREATE TABLE group_integrations (
+    id VARCHAR(255) PRIMARY KEY,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    platform VARCHAR(20) NOT NULL CHECK (platform IN ('SLACK', 'DISCORD', 'TELEGRAM')),
+    webhook_url TEXT NOT NULL,
+    chat_id VARCHAR(100),
+    notify_expenses BOOLEAN DEFAULT TRUE NOT NULL,
+    notify_settlements BOOLEAN DEFAULT TRUE NOT NULL,
+    expense_template TEXT,
+    settlement_template TEXT,
+    enabled BOOLEAN DEFAULT TRUE NOT NULL,
+    created_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX idx_group_integrations_group_id ON group_integrations(group_id);
+
+CREATE TABLE integration_deliveries (
+    id VARCHAR(255) PRIMARY KEY,
+    integration_id VARCHAR(255) REFERENCES group_integrations(id) ON DELETE CASCADE NOT NULL,
+    expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE SET NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'SENT', 'FAILED')),
+    attempts INTEGER DEFAULT 0 NOT NULL,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_integration_deliveries_due ON integration_deliveries(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_integration_deliveries_integration_id ON integration_deliveries(integration_id, created_at DESC);
@@ -0,0 +1,7 @@
+-- Rollback: Per-group expense guardrails and group activity log
+
+DROP TABLE IF EXISTS group_activity;
+ALTER TABLE expenses DROP COLUMN IF EXISTS limit_flagged;
+ALTER TABLE groups DROP COLUMN IF EXISTS limit_action;
+ALTER TABLE groups DROP COLUMN IF EXISTS max_daily_expenses;
+ALTER TABLE groups DROP COLUMN IF EXISTS max_expense_amount;
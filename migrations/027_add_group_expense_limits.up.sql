@@ -0,0 +1,20 @@
+-- Migration: Per-group expense guardrails and group activity log
+-- Limits are optional; limit_action decides whether an over-limit expense is rejected or saved and flagged.
+
+ALTER TABLE groups ADD COLUMN max_expense_amount DECIMAL(10, 2) CHECK (max_expense_amount > 0);
+ALTER TABLE groups ADD COLUMN max_daily_expenses INTEGER CHECK (max_daily_expenses > 0);
+ALTER TABLE groups ADD COLUMN limit_action VARCHAR(10) NOT NULL DEFAULT 'REJECT' CHECK (limit_action IN ('REJECT', 'FLAG'));
+
+ALTER TABLE expenses ADD COLUMN limit_flagged BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE group_activity (
+    id VARCHAR(255) PRIMARY KEY,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    actor_id VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX idx_group_activity_group_created ON group_activity(group_id, created_at DESC);
@@ -0,0 +1,3 @@
+-- Rollback: Index for per-debtor reminder cooldown lookups
+
+DROP INDEX IF EXISTS idx_notifications_actor_reminders;
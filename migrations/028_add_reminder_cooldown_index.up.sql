@@ -0,0 +1,6 @@
+-- Migration: Index for per-debtor reminder cooldown lookups
+-- Reminders are stored as REMINDER notifications; cooldowns look up the latest one sent by an actor to each debtor.
+
+CREATE INDEX IF NOT EXISTS idx_notifications_actor_reminders
+    ON notifications(actor_id, user_id, created_at DESC)
+    WHERE event = 'REMINDER';
@@ -0,0 +1,3 @@
+-- Rollback: Default split ratios per friend pair
+
+DROP TABLE IF EXISTS split_preferences;
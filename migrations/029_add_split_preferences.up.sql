@@ -0,0 +1,16 @@
+-- Migration: Default split ratios per friend pair
+-- Pairs are stored once with user_a_id < user_b_id; a row with a group_id overrides the pair default inside that group.
+
+CREATE TABLE split_preferences (
+    id VARCHAR(255) PRIMARY KEY,
+    user_a_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    user_b_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE,
+    user_a_percentage NUMERIC(5, 2) NOT NULL CHECK (user_a_percentage >= 0 AND user_a_percentage <= 100),
+    updated_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (user_a_id < user_b_id)
+);
+
+CREATE UNIQUE INDEX idx_split_preferences_pair_group ON split_preferences(user_a_id, user_b_id, (COALESCE(group_id, '')));
@@ -0,0 +1,4 @@
+-- Rollback: Audit trail for AI outputs with user feedback
+
+DROP TABLE IF EXISTS ai_feedback;
+DROP TABLE IF EXISTS ai_outputs;
@@ -0,0 +1,28 @@
+-- Migration: Audit trail for AI outputs with user feedback
+-- Prompts and responses are stored redacted (emails, long numbers and member names removed).
+
+CREATE TABLE ai_outputs (
+    id VARCHAR(255) PRIMARY KEY,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('EXPLANATION', 'RECEIPT_SCAN')),
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE,
+    expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE CASCADE,
+    model VARCHAR(100) NOT NULL,
+    prompt TEXT NOT NULL,
+    response TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_outputs_expense ON ai_outputs(expense_id, created_at DESC) WHERE expense_id IS NOT NULL;
+CREATE INDEX idx_ai_outputs_kind ON ai_outputs(kind);
+
+CREATE TABLE ai_feedback (
+    id VARCHAR(255) PRIMARY KEY,
+    output_id VARCHAR(255) REFERENCES ai_outputs(id) ON DELETE CASCADE NOT NULL,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    rating VARCHAR(10) NOT NULL CHECK (rating IN ('UP', 'DOWN')),
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (output_id, user_id)
+);
@@ -0,0 +1,3 @@
+-- Rollback: Pending group invites for emails that have no account yet
+
+DROP TABLE IF EXISTS group_invites;
@@ -0,0 +1,15 @@
+-- Migration: Pending group invites for emails that have no account yet
+-- Invites are attached when the invited user bootstraps their account on first login.
+
+CREATE TABLE group_invites (
+    id VARCHAR(255) PRIMARY KEY,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    invited_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    accepted_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    accepted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX idx_group_invites_pending ON group_invites(group_id, LOWER(email)) WHERE accepted_at IS NULL;
+CREATE INDEX idx_group_invites_email ON group_invites(LOWER(email)) WHERE accepted_at IS NULL;
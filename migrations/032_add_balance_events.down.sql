@@ -0,0 +1,3 @@
+-- Rollback: Append-only ledger of balance deltas per (group, user, currency)
+
+DROP TABLE IF EXISTS balance_events;
@@ -0,0 +1,33 @@
+-- Migration: Append-only ledger of balance deltas per (group, user, currency)
+-- Every transaction write appends the change it makes to each member's balance,
+-- so a balance can be audited event by event and cross-checked against expenses.
+
+CREATE TABLE balance_events (
+    id VARCHAR(255) PRIMARY KEY,
+    seq BIGSERIAL NOT NULL,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    delta DECIMAL(12, 2) NOT NULL,
+    event_type VARCHAR(30) NOT NULL,
+    expense_id VARCHAR(255),
+    expense_category VARCHAR(20),
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_balance_events_member ON balance_events(group_id, user_id, seq);
+CREATE INDEX idx_balance_events_expense ON balance_events(expense_id) WHERE expense_id IS NOT NULL;
+
+-- Seed the ledger with the current contribution of every existing transaction
+INSERT INTO balance_events (id, group_id, user_id, currency, delta, event_type, expense_id, expense_category, description, created_at)
+SELECT gen_random_uuid()::TEXT, e.group_id, c.user_id, e.currency, SUM(c.amount), 'BACKFILL', e.id, e.category, e.description, e.created_at
+FROM expenses e
+JOIN (
+    SELECT expense_id, user_id, amount_paid AS amount FROM expense_payers
+    UNION ALL
+    SELECT expense_id, user_id, -amount FROM expense_splits
+) c ON c.expense_id = e.id
+GROUP BY e.group_id, c.user_id, e.currency, e.id, e.category, e.description, e.created_at
+HAVING SUM(c.amount) <> 0
+ORDER BY e.created_at, e.id;
@@ -0,0 +1,3 @@
+-- Rollback: Recurring expense stubs created from group templates
+
+DROP TABLE IF EXISTS recurring_expense_stubs;
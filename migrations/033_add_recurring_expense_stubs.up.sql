@@ -0,0 +1,13 @@
+-- Migration: Recurring expense stubs created from group templates
+-- Stubs name the bills a group expects every period (rent, utilities) before any amount is known.
+
+CREATE TABLE recurring_expense_stubs (
+    id VARCHAR(255) PRIMARY KEY,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    description VARCHAR(100) NOT NULL,
+    category VARCHAR(30),
+    frequency VARCHAR(20) NOT NULL DEFAULT 'MONTHLY',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_recurring_expense_stubs_group ON recurring_expense_stubs(group_id);
@@ -0,0 +1,4 @@
+-- Rollback: Let users control how they can be found in user search
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_discoverability_check;
+ALTER TABLE users DROP COLUMN IF EXISTS discoverability;
@@ -0,0 +1,5 @@
+-- Migration: Let users control how they can be found in user search
+-- NAME: by name or exact email, EMAIL: by exact email only, NONE: not searchable
+
+ALTER TABLE users ADD COLUMN discoverability VARCHAR(10) NOT NULL DEFAULT 'NAME';
+ALTER TABLE users ADD CONSTRAINT users_discoverability_check CHECK (discoverability IN ('NAME', 'EMAIL', 'NONE'));
@@ -0,0 +1,5 @@
+-- Rollback: Expense creator and per-group edit policy
+
+ALTER TABLE groups DROP COLUMN IF EXISTS expense_edit_policy;
+DROP INDEX IF EXISTS idx_expenses_created_by;
+ALTER TABLE expenses DROP COLUMN IF EXISTS created_by_user_id;
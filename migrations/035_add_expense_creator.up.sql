@@ -0,0 +1,10 @@
+-- Migration: Expense creator and per-group edit policy
+-- created_by_user_id records who entered an expense, independently of who paid it.
+-- Rows created before this migration have no creator; the edit policy falls back to their payers.
+
+ALTER TABLE expenses ADD COLUMN created_by_user_id VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_expenses_created_by ON expenses(created_by_user_id);
+
+ALTER TABLE groups ADD COLUMN expense_edit_policy VARCHAR(20) NOT NULL DEFAULT 'ANY_MEMBER'
+    CHECK (expense_edit_policy IN ('ANY_MEMBER', 'CREATOR', 'CREATOR_OR_PAYER'));
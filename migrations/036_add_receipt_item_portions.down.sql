@@ -0,0 +1,4 @@
+-- Rollback: Receipt item quantities and per-user portions
+
+ALTER TABLE receipt_item_assignments DROP COLUMN IF EXISTS portion;
+ALTER TABLE receipt_items DROP COLUMN IF EXISTS quantity;
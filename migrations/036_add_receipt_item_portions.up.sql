@@ -0,0 +1,7 @@
+-- Migration: Receipt item quantities and per-user portions
+-- quantity is how many units a receipt line covers (3 beers); portion is how many of those units an assignee had.
+-- Assignments created before this migration have no portion and keep splitting the item equally.
+
+ALTER TABLE receipt_items ADD COLUMN quantity DECIMAL(10, 3) NOT NULL DEFAULT 1 CHECK (quantity > 0);
+
+ALTER TABLE receipt_item_assignments ADD COLUMN portion DECIMAL(10, 3) CHECK (portion > 0);
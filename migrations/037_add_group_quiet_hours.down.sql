@@ -0,0 +1,5 @@
+-- Rollback: Per-group quiet hours
+
+DROP INDEX IF EXISTS idx_notifications_user_deliver_at;
+ALTER TABLE notifications DROP COLUMN IF EXISTS deliver_at;
+DROP TABLE IF EXISTS group_quiet_hours;
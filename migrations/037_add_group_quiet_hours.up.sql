@@ -0,0 +1,20 @@
+-- Migration: Per-group quiet hours
+-- Non-urgent notifications created inside a group's quiet hours are stored with a later deliver_at
+-- and only show up (or are posted to chat integrations) once the quiet hours end.
+
+CREATE TABLE group_quiet_hours (
+    group_id VARCHAR(255) PRIMARY KEY REFERENCES groups(id) ON DELETE CASCADE,
+    enabled BOOLEAN DEFAULT TRUE NOT NULL,
+    start_time VARCHAR(5) NOT NULL,
+    end_time VARCHAR(5) NOT NULL,
+    time_zone VARCHAR(64) DEFAULT 'UTC' NOT NULL,
+    updated_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+ALTER TABLE notifications ADD COLUMN deliver_at TIMESTAMP WITH TIME ZONE;
+UPDATE notifications SET deliver_at = created_at;
+ALTER TABLE notifications ALTER COLUMN deliver_at SET DEFAULT NOW();
+ALTER TABLE notifications ALTER COLUMN deliver_at SET NOT NULL;
+
+CREATE INDEX idx_notifications_user_deliver_at ON notifications(user_id, deliver_at DESC);
@@ -0,0 +1,5 @@
+-- Rollback: Settlement reversals
+
+DROP INDEX IF EXISTS idx_expenses_reverses_expense_id;
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_reversal_category_check;
+ALTER TABLE expenses DROP COLUMN IF EXISTS reverses_expense_id;
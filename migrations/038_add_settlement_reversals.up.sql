@@ -0,0 +1,10 @@
+-- Migration: Settlement reversals
+-- A reversal is an opposite PAYMENT/REPAYMENT linked to the settlement it undoes, so balances are
+-- restored without deleting history. Each settlement can be reversed at most once.
+
+ALTER TABLE expenses ADD COLUMN reverses_expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE CASCADE;
+
+ALTER TABLE expenses ADD CONSTRAINT expenses_reversal_category_check
+    CHECK (reverses_expense_id IS NULL OR category IN ('PAYMENT', 'REPAYMENT'));
+
+CREATE UNIQUE INDEX idx_expenses_reverses_expense_id ON expenses(reverses_expense_id) WHERE reverses_expense_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Rollback: User email verification
+
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified;
@@ -0,0 +1,5 @@
+-- Migration: User email verification
+-- Mirrors the auth provider's email verification flag so sensitive actions can require it.
+-- Refreshed from the access token whenever the user record is ensured.
+
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
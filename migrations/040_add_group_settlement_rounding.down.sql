@@ -0,0 +1,3 @@
+-- Rollback: Per-group settlement rounding
+
+ALTER TABLE groups DROP COLUMN IF EXISTS settlement_rounding;
@@ -0,0 +1,6 @@
+-- Migration: Per-group settlement rounding
+-- Settlement suggestions are rounded to this increment (e.g. 10 or 100) so cash payments are practical.
+-- 0 keeps exact amounts. Balances are never rounded; what is rounded off stays owed.
+
+ALTER TABLE groups ADD COLUMN settlement_rounding INTEGER NOT NULL DEFAULT 0
+    CHECK (settlement_rounding IN (0, 1, 5, 10, 50, 100, 500));
@@ -0,0 +1,4 @@
+-- Rollback: Per-group data retention
+
+ALTER TABLE expenses DROP COLUMN IF EXISTS anonymized_at;
+DROP TABLE IF EXISTS group_retention_policies;
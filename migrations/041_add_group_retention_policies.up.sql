@@ -0,0 +1,20 @@
+-- Migration: Per-group data retention
+-- Transactions older than retain_years are archived to storage and then deleted or anonymized
+-- by the retention worker. next_run_at doubles as the worker's claim lease.
+
+CREATE TABLE group_retention_policies (
+    group_id VARCHAR(255) PRIMARY KEY REFERENCES groups(id) ON DELETE CASCADE,
+    retain_years INTEGER NOT NULL CHECK (retain_years BETWEEN 1 AND 50),
+    action VARCHAR(20) NOT NULL CHECK (action IN ('DELETE', 'ANONYMIZE')),
+    updated_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_archive_path TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX idx_group_retention_policies_due ON group_retention_policies(next_run_at);
+
+-- Anonymized rows keep their amounts but are skipped by later runs
+ALTER TABLE expenses ADD COLUMN anonymized_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,6 @@
+-- Rollback: Hashed user emails for contact sync
+
+DROP INDEX IF EXISTS idx_users_email_sha256;
+DROP TRIGGER IF EXISTS trg_users_email_sha256 ON users;
+DROP FUNCTION IF EXISTS set_user_email_sha256();
+ALTER TABLE users DROP COLUMN IF EXISTS email_sha256;
@@ -0,0 +1,27 @@
+-- Migration: Hashed user emails for contact sync
+-- Clients upload SHA-256 hashes of their contacts' emails (trimmed, lowercased)
+-- instead of the raw address book; this column lets us match them by index.
+-- Kept in sync with users.email by a trigger.
+
+ALTER TABLE users ADD COLUMN email_sha256 TEXT;
+
+CREATE OR REPLACE FUNCTION set_user_email_sha256() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.email IS NULL OR TRIM(NEW.email) = '' THEN
+        NEW.email_sha256 := NULL;
+    ELSE
+        NEW.email_sha256 := encode(sha256(convert_to(LOWER(TRIM(NEW.email)), 'UTF8')), 'hex');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_users_email_sha256
+    BEFORE INSERT OR UPDATE OF email ON users
+    FOR EACH ROW EXECUTE FUNCTION set_user_email_sha256();
+
+UPDATE users
+SET email_sha256 = encode(sha256(convert_to(LOWER(TRIM(email)), 'UTF8')), 'hex')
+WHERE email IS NOT NULL AND TRIM(email) <> '';
+
+CREATE INDEX idx_users_email_sha256 ON users(email_sha256) WHERE deleted_at IS NULL;
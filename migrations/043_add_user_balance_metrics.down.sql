@@ -0,0 +1,3 @@
+-- Rollback: Pre-aggregated user balances
+
+DROP TABLE IF EXISTS user_balance_metrics;
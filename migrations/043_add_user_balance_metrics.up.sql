@@ -0,0 +1,25 @@
+-- Migration: Pre-aggregated user balances
+-- One row per (user, group, currency) with the user's net balance there, updated
+-- by BalanceEventRepository in the same transaction as every ledger append, so
+-- dashboard totals read a handful of rows instead of scanning all expenses.
+-- Kept per group because "you owe" and "you are owed" are summed per group.
+-- A nightly job compares the rows against expenses and repairs any drift.
+
+CREATE TABLE user_balance_metrics (
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    net DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, group_id, currency)
+);
+
+INSERT INTO user_balance_metrics (user_id, group_id, currency, net)
+SELECT c.user_id, e.group_id, e.currency, SUM(c.amount)
+FROM expenses e
+JOIN (
+    SELECT expense_id, user_id, amount_paid AS amount FROM expense_payers
+    UNION ALL
+    SELECT expense_id, user_id, -amount FROM expense_splits
+) c ON c.expense_id = e.id
+GROUP BY c.user_id, e.group_id, e.currency;
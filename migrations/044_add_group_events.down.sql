@@ -0,0 +1,5 @@
+-- Rollback: Events within a group
+
+DROP INDEX IF EXISTS idx_expenses_event_id;
+ALTER TABLE expenses DROP COLUMN IF EXISTS event_id;
+DROP TABLE IF EXISTS group_events;
@@ -0,0 +1,16 @@
+-- Migration: Events within a group
+-- Named sub-trips ("Scuba day") inside a TRIP group. An expense belongs to at
+-- most one event; deleting the event leaves its expenses in the group.
+
+CREATE TABLE group_events (
+    id VARCHAR(255) PRIMARY KEY,
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    created_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    UNIQUE (group_id, name)
+);
+
+ALTER TABLE expenses ADD COLUMN event_id VARCHAR(255) REFERENCES group_events(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_expenses_event_id ON expenses(event_id) WHERE event_id IS NOT NULL;
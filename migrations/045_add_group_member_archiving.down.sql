@@ -0,0 +1,4 @@
+-- Rollback: Per-member group archiving
+
+ALTER TABLE group_members DROP COLUMN IF EXISTS archive_suggestion_dismissed_at;
+ALTER TABLE group_members DROP COLUMN IF EXISTS archived_at;
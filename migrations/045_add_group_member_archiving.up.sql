@@ -0,0 +1,8 @@
+-- Migration: Per-member group archiving
+-- A member can archive a settled group to hide it from their lists. The group
+-- reappears once a transaction is added after archived_at. Dismissing the
+-- dashboard's archive suggestion is remembered the same way, so it only comes
+-- back after the group has seen new activity.
+
+ALTER TABLE group_members ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE group_members ADD COLUMN archive_suggestion_dismissed_at TIMESTAMP WITH TIME ZONE;
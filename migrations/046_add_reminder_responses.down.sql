@@ -0,0 +1,3 @@
+-- Rollback: Debtors' responses to payment reminders
+
+DROP TABLE IF EXISTS reminder_responses;
@@ -0,0 +1,20 @@
+-- Migration: Debtors' responses to payment reminders
+-- A debtor can answer a creditor's reminders in a group by promising to pay by
+-- a date or by snoozing them for a few days. Either way the creditor's
+-- reminders are held until held_until passes; there is one response per
+-- debtor, creditor and group, replaced by the next one.
+
+CREATE TABLE reminder_responses (
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    debtor_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    creditor_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('PROMISED', 'SNOOZED')),
+    pay_by DATE,
+    held_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    note VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (group_id, debtor_id, creditor_id)
+);
+
+CREATE INDEX idx_reminder_responses_creditor ON reminder_responses(creditor_id, held_until);
@@ -0,0 +1,3 @@
+-- Rollback: Per-group default language
+
+ALTER TABLE groups DROP COLUMN IF EXISTS default_language;
@@ -0,0 +1,6 @@
+-- Migration: Per-group default language
+-- AI explanations, CSV export headers and notification messages for the group are written in this language,
+-- so members in different locales see the same wording.
+
+ALTER TABLE groups ADD COLUMN default_language TEXT NOT NULL DEFAULT 'en'
+    CHECK (default_language IN ('en', 'es', 'fr', 'de', 'hi'));
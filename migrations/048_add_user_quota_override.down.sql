@@ -0,0 +1,3 @@
+-- Rollback: Admin override for per-user quotas
+
+ALTER TABLE users DROP COLUMN IF EXISTS quota_exempt;
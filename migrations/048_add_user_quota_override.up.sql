@@ -0,0 +1,4 @@
+-- Migration: Admin override for per-user quotas
+-- Users with quota_exempt set are not held to MAX_GROUPS_PER_USER, MAX_MEMBERS_PER_GROUP or MAX_EXPENSES_PER_GROUP.
+
+ALTER TABLE users ADD COLUMN quota_exempt BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+-- Rollback: "Not for me" split exclusions
+
+DROP INDEX IF EXISTS idx_expense_splits_pending_exclusion;
+ALTER TABLE expense_splits DROP COLUMN IF EXISTS exclusion_requested_at;
+ALTER TABLE expense_splits DROP COLUMN IF EXISTS exclusion_reason;
+ALTER TABLE expense_splits DROP COLUMN IF EXISTS exclusion_status;
@@ -0,0 +1,12 @@
+-- Migration: "Not for me" split exclusions
+-- A participant can flag a split as not theirs. The flag stays PENDING until the
+-- expense's creator accepts (the split is removed and the expense re-split) or
+-- rejects it. A rejected flag stays on the split until the splits are edited.
+
+ALTER TABLE expense_splits ADD COLUMN exclusion_status VARCHAR(20)
+    CHECK (exclusion_status IN ('PENDING', 'REJECTED'));
+ALTER TABLE expense_splits ADD COLUMN exclusion_reason TEXT;
+ALTER TABLE expense_splits ADD COLUMN exclusion_requested_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_expense_splits_pending_exclusion ON expense_splits(expense_id)
+    WHERE exclusion_status = 'PENDING';
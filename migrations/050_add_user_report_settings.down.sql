@@ -0,0 +1,6 @@
+-- Rollback: Per-user calendar for reports
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_fiscal_month_start_day_check;
+ALTER TABLE users DROP COLUMN IF EXISTS fiscal_month_start_day;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_week_start_check;
+ALTER TABLE users DROP COLUMN IF EXISTS week_start;
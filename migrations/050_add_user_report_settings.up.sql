@@ -0,0 +1,9 @@
+-- Migration: Per-user calendar for reports
+-- week_start picks the first day of report weeks; fiscal_month_start_day is the
+-- day report months begin on (e.g. 25 for a salary paid on the 25th). Capped at
+-- 28 so every month has that day.
+
+ALTER TABLE users ADD COLUMN week_start VARCHAR(10) NOT NULL DEFAULT 'MONDAY';
+ALTER TABLE users ADD CONSTRAINT users_week_start_check CHECK (week_start IN ('MONDAY', 'SUNDAY'));
+ALTER TABLE users ADD COLUMN fiscal_month_start_day SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE users ADD CONSTRAINT users_fiscal_month_start_day_check CHECK (fiscal_month_start_day BETWEEN 1 AND 28);
@@ -0,0 +1,4 @@
+-- Rollback: Confirmed edits for settled pairs
+
+DROP TABLE IF EXISTS pending_expense_change_confirmations;
+DROP TABLE IF EXISTS pending_expense_changes;
@@ -0,0 +1,32 @@
+-- Migration: Confirmed edits for settled pairs
+-- An edit that would change the balance between two members who have settled
+-- up since the expense is held here until both of them confirm it. payload is
+-- the proposed expense with its payers, splits and receipt items. A pending
+-- change is superseded by any later edit of the same expense.
+
+CREATE TABLE pending_expense_changes (
+    id VARCHAR(255) PRIMARY KEY,
+    expense_id VARCHAR(255) NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    group_id VARCHAR(255) NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    requested_by VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'APPLIED', 'REJECTED', 'SUPERSEDED')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX idx_pending_expense_changes_expense ON pending_expense_changes(expense_id)
+    WHERE status = 'PENDING';
+
+CREATE TABLE pending_expense_change_confirmations (
+    change_id VARCHAR(255) NOT NULL REFERENCES pending_expense_changes(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'ACCEPTED', 'REJECTED')),
+    responded_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (change_id, user_id)
+);
+
+CREATE INDEX idx_pending_expense_change_confirmations_user ON pending_expense_change_confirmations(user_id)
+    WHERE status = 'PENDING';
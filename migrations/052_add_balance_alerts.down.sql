@@ -0,0 +1,6 @@
+-- Rollback: Balance threshold alerts
+
+DROP TABLE IF EXISTS balance_alerts;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_balance_alert_check;
+ALTER TABLE users DROP COLUMN IF EXISTS balance_alert_currency;
+ALTER TABLE users DROP COLUMN IF EXISTS balance_alert_threshold;
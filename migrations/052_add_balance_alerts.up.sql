@@ -0,0 +1,22 @@
+-- Migration: Balance threshold alerts
+-- A user with balance_alert_threshold set is notified when they owe more than
+-- that amount, in balance_alert_currency, in any group. balance_alerts holds
+-- the groups they were already alerted for; the row is removed once they owe
+-- comfortably less again, so they are alerted once per crossing.
+
+ALTER TABLE users ADD COLUMN balance_alert_threshold NUMERIC(12, 2);
+ALTER TABLE users ADD COLUMN balance_alert_currency VARCHAR(3);
+ALTER TABLE users ADD CONSTRAINT users_balance_alert_check CHECK (
+    (balance_alert_threshold IS NULL AND balance_alert_currency IS NULL)
+    OR (balance_alert_threshold > 0 AND balance_alert_currency IS NOT NULL)
+);
+
+CREATE TABLE balance_alerts (
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    group_id VARCHAR(255) NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    amount_owed NUMERIC(12, 2) NOT NULL,
+    alerted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, group_id)
+);
+
+CREATE INDEX idx_balance_alerts_group ON balance_alerts(group_id);
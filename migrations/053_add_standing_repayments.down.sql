@@ -0,0 +1,3 @@
+-- Rollback: Standing repayments
+
+DROP TABLE IF EXISTS standing_repayments;
@@ -0,0 +1,25 @@
+-- Migration: Standing repayments
+-- A monthly repayment between two members, e.g. flatmates settling the same
+-- rent share every month. The worker records a REPAYMENT on day_of_month (UTC)
+-- in the group's default currency; next_run_at doubles as its claim lease.
+-- Cancelled standing repayments are kept for the record.
+
+CREATE TABLE standing_repayments (
+    id VARCHAR(255) PRIMARY KEY,
+    group_id VARCHAR(255) NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    payer_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    receiver_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    day_of_month SMALLINT NOT NULL CHECK (day_of_month BETWEEN 1 AND 28),
+    created_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE SET NULL,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    cancelled_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    CHECK (payer_id <> receiver_id)
+);
+
+CREATE INDEX idx_standing_repayments_group ON standing_repayments(group_id) WHERE cancelled_at IS NULL;
+CREATE INDEX idx_standing_repayments_due ON standing_repayments(next_run_at) WHERE cancelled_at IS NULL;
@@ -0,0 +1,3 @@
+-- Rollback: Public group share links
+
+DROP TABLE IF EXISTS group_share_links;
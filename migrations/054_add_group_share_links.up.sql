@@ -0,0 +1,18 @@
+-- Migration: Public group share links
+-- A read-only link to a group's ledger for people without an account, e.g. a
+-- parent following a trip. Only the SHA-256 of the token is stored; the token
+-- itself is shown once, when the link is created. Revoked links are kept for
+-- the record.
+
+CREATE TABLE group_share_links (
+    id VARCHAR(255) PRIMARY KEY,
+    group_id VARCHAR(255) NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    last_viewed_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_group_share_links_group ON group_share_links(group_id) WHERE revoked_at IS NULL;
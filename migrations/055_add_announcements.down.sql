@@ -0,0 +1,4 @@
+-- Rollback: In-app announcements
+
+DROP TABLE IF EXISTS announcement_reads;
+DROP TABLE IF EXISTS announcements;
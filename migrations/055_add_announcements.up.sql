@@ -0,0 +1,26 @@
+-- Migration: In-app announcements
+-- Admin-written messages (a new feature, a maintenance window) shown to every
+-- user between starts_at and ends_at, so clients can be told things without
+-- an app release. announcement_reads records who has read which.
+
+CREATE TABLE announcements (
+    id VARCHAR(255) PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('FEATURE', 'MAINTENANCE', 'INFO')),
+    title VARCHAR(120) NOT NULL,
+    body TEXT NOT NULL,
+    link_url TEXT,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_announcements_starts_at ON announcements(starts_at DESC);
+
+CREATE TABLE announcement_reads (
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    announcement_id VARCHAR(255) NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    read_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (user_id, announcement_id)
+);
@@ -0,0 +1,3 @@
+-- Rollback: Per-expense split lock
+
+ALTER TABLE expenses DROP COLUMN IF EXISTS locked_splits;
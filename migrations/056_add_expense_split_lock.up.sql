@@ -0,0 +1,7 @@
+-- Migration: Per-expense split lock
+-- A locked expense (e.g. a personal loan recorded as an expense) is skipped by
+-- operations that re-split expenses in bulk, such as back-charging a member or
+-- accepting a "not for me" exclusion, and its splits can only be edited after
+-- it is unlocked.
+
+ALTER TABLE expenses ADD COLUMN locked_splits BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- Rollback: Symmetric friendships
+-- Reverse edges added by the migration can't be told apart from ones users
+-- added themselves, so they are kept. One-directional code still reads them
+-- correctly.
@@ -0,0 +1,10 @@
+-- Migration: Symmetric friendships
+-- Adding a friend used to store only the adder's edge, so A could see B as a
+-- friend while B didn't see A. Friendships are now stored as both edges.
+-- This adds every missing reverse edge; where both users had added each other
+-- separately, both edges take the earlier created_at.
+
+INSERT INTO friends (user_id, friend_id, created_at)
+SELECT friend_id, user_id, created_at FROM friends
+ON CONFLICT (user_id, friend_id) DO UPDATE
+SET created_at = LEAST(friends.created_at, EXCLUDED.created_at);
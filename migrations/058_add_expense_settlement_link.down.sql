@@ -0,0 +1,4 @@
+-- Rollback: Settle a single expense
+
+DROP INDEX IF EXISTS idx_expenses_settles_expense_id;
+ALTER TABLE expenses DROP COLUMN IF EXISTS settles_expense_id;
@@ -0,0 +1,8 @@
+-- Migration: Settle a single expense
+-- A payment made with "settle this expense" points at the expense it squares
+-- up, so the same member can't settle it twice. Deleting the expense keeps
+-- the payment, since the money did change hands.
+
+ALTER TABLE expenses ADD COLUMN settles_expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_expenses_settles_expense_id ON expenses(settles_expense_id) WHERE settles_expense_id IS NOT NULL;
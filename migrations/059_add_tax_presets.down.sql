@@ -0,0 +1,4 @@
+-- Rollback: Region-aware tax presets
+
+ALTER TABLE expenses DROP COLUMN IF EXISTS tax_components;
+ALTER TABLE groups DROP COLUMN IF EXISTS tax_preset;
@@ -0,0 +1,21 @@
+-- Migration: Region-aware tax presets
+-- A group's tax preset decides which tax components its expenses use: CGST
+-- and SGST in India, sales tax in the US, VAT in the EU. Components are
+-- stored in tax_components, which replaces the India-specific cgst and sgst
+-- columns over time; those stay filled for IN_GST groups meanwhile.
+
+ALTER TABLE groups ADD COLUMN tax_preset TEXT NOT NULL DEFAULT 'IN_GST'
+    CHECK (tax_preset IN ('IN_GST', 'US_SALES_TAX', 'EU_VAT', 'GENERIC'));
+
+UPDATE groups SET tax_preset = CASE default_currency
+    WHEN 'INR' THEN 'IN_GST'
+    WHEN 'USD' THEN 'US_SALES_TAX'
+    WHEN 'EUR' THEN 'EU_VAT'
+    ELSE 'GENERIC'
+END;
+
+ALTER TABLE expenses ADD COLUMN tax_components JSONB;
+
+UPDATE expenses
+SET tax_components = jsonb_strip_nulls(jsonb_build_object('cgst', NULLIF(cgst, 0), 'sgst', NULLIF(sgst, 0)))
+WHERE cgst <> 0 OR sgst <> 0;
@@ -0,0 +1,3 @@
+-- Rollback: Background jobs
+
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,21 @@
+-- Migration: Background jobs
+-- jobs is a durable queue drained by the job workers. A claimed job stays
+-- PENDING with run_at pushed out by a lease, so a job whose worker died is
+-- picked up again once the lease runs out. Jobs out of attempts become DEAD.
+
+CREATE TABLE jobs (
+    id VARCHAR(255) PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'SUCCEEDED', 'DEAD')),
+    attempts INTEGER DEFAULT 0 NOT NULL,
+    max_attempts INTEGER NOT NULL CHECK (max_attempts > 0),
+    run_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_jobs_due ON jobs(run_at) WHERE status = 'PENDING';
+CREATE INDEX idx_jobs_dead ON jobs(updated_at DESC) WHERE status = 'DEAD';
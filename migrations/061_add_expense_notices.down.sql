@@ -0,0 +1,3 @@
+-- Rollback: When each member was first notified of a transaction
+
+DROP TABLE IF EXISTS expense_notices;
@@ -0,0 +1,17 @@
+-- Migration: When each member was first notified of a transaction
+-- Together with expense_reads.seen_at this settles "you added this weeks
+-- later" disputes. Rows outlive the notifications themselves; notified_at is
+-- when the first notification was delivered, after any quiet hours.
+
+CREATE TABLE expense_notices (
+    expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE CASCADE NOT NULL,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    notified_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (expense_id, user_id)
+);
+
+INSERT INTO expense_notices (expense_id, user_id, notified_at)
+SELECT expense_id, user_id, MIN(deliver_at)
+FROM notifications
+WHERE expense_id IS NOT NULL
+GROUP BY expense_id, user_id;
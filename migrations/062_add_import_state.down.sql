@@ -0,0 +1,4 @@
+-- Rollback: Remember Splitwise import mappings and imported rows per group
+
+DROP TABLE IF EXISTS import_fingerprints;
+DROP TABLE IF EXISTS import_member_mappings;
@@ -0,0 +1,23 @@
+-- Migration: Remember Splitwise import mappings and imported rows per group
+-- import_member_mappings holds the user each CSV name was last mapped to, so
+-- later imports into the group can map members without asking again.
+-- import_fingerprints records every imported row by date, description and
+-- cost, so re-importing an overlapping export skips the rows already in.
+
+CREATE TABLE import_member_mappings (
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    csv_name VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (group_id, csv_name)
+);
+
+CREATE TABLE import_fingerprints (
+    group_id VARCHAR(255) REFERENCES groups(id) ON DELETE CASCADE NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    expense_id VARCHAR(255) REFERENCES expenses(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (group_id, fingerprint)
+);
+
+CREATE INDEX idx_import_fingerprints_expense_id ON import_fingerprints(expense_id);
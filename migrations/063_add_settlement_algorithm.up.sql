@@ -0,0 +1,7 @@
+-- Migration: Per-group settlement algorithm
+-- MIN_TRANSFERS matches the largest creditor with the largest debtor, using
+-- as few payments as possible. PAIRWISE keeps each pair's debt from the
+-- expenses they shared, so everyone pays back the people who covered them.
+
+ALTER TABLE groups ADD COLUMN settlement_algorithm TEXT NOT NULL DEFAULT 'MIN_TRANSFERS'
+    CHECK (settlement_algorithm IN ('MIN_TRANSFERS', 'PAIRWISE'));
@@ -0,0 +1,4 @@
+-- Rollback: Unicode-normalized names and accent-insensitive search
+-- Names stay normalized; only the extension is removed.
+
+DROP EXTENSION IF EXISTS unaccent;
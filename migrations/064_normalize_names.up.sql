@@ -0,0 +1,9 @@
+-- Migration: Unicode-normalized names and accent-insensitive search
+-- Names are now stored in NFC, so the same name typed with a precomposed or a
+-- combining accent is stored alike. unaccent lets user search match "Jose"
+-- against "José".
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+UPDATE users SET name = normalize(name, NFC) WHERE name IS NOT NFC NORMALIZED;
+UPDATE groups SET name = normalize(name, NFC) WHERE name IS NOT NFC NORMALIZED;
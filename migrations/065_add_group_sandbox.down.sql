@@ -0,0 +1,3 @@
+-- Rollback: Sandbox groups
+
+ALTER TABLE groups DROP COLUMN IF EXISTS is_sandbox;
@@ -0,0 +1,7 @@
+-- Migration: Sandbox groups
+-- A sandbox group is a playground: its transactions stay out of dashboard
+-- totals, friend balances and recent activity, and it sends no
+-- notifications. The flag is set when the group is created and never
+-- changes, so real debts can't be hidden by flipping it later.
+
+ALTER TABLE groups ADD COLUMN is_sandbox BOOLEAN NOT NULL DEFAULT FALSE;
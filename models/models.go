@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -12,11 +13,91 @@ type User struct {
 	IsPlaceholder bool       `json:"is_placeholder" db:"is_placeholder"`
 	ClaimedBy     *string    `json:"claimed_by,omitempty" db:"claimed_by"`
 	ClaimedAt     *time.Time `json:"claimed_at,omitempty" db:"claimed_at"`
+	EmailVerified *bool      `json:"email_verified,omitempty" db:"email_verified"`
+	DeletedAt     *time.Time `json:"-" db:"deleted_at"`
 	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 	Balance       float64    `json:"balance,omitempty"`
 }
 
+type Discoverability string
+
+const (
+	DiscoverabilityName  Discoverability = "NAME"
+	DiscoverabilityEmail Discoverability = "EMAIL"
+	DiscoverabilityNone  Discoverability = "NONE"
+)
+
+type PrivacySettings struct {
+	Discoverability Discoverability `json:"discoverability" db:"discoverability"`
+}
+
+type WeekStart string
+
+const (
+	WeekStartMonday WeekStart = "MONDAY"
+	WeekStartSunday WeekStart = "SUNDAY"
+)
+
+// ReportSettings is the calendar a user's reports are bucketed by. Report
+// months run from FiscalMonthStartDay (1-28) to the day before it in the next
+// month.
+type ReportSettings struct {
+	WeekStart           WeekStart `json:"week_start" db:"week_start"`
+	FiscalMonthStartDay int       `json:"fiscal_month_start_day" db:"fiscal_month_start_day"`
+}
+
+func DefaultReportSettings() ReportSettings {
+	return ReportSettings{WeekStart: WeekStartMonday, FiscalMonthStartDay: 1}
+}
+
+// PeriodOffsetDays is how many days the user's periods of granularity start
+// after the ISO ones (Monday weeks, calendar months), so a period is
+// date_trunc(granularity, day - offset) + offset.
+func (s ReportSettings) PeriodOffsetDays(granularity string) int {
+	switch granularity {
+	case "week":
+		if s.WeekStart == WeekStartSunday {
+			return -1
+		}
+	case "month":
+		if s.FiscalMonthStartDay > 1 {
+			return s.FiscalMonthStartDay - 1
+		}
+	}
+	return 0
+}
+
+// BalanceAlertSettings asks to be notified when the user owes more than
+// Threshold in Currency in any group. A nil Threshold turns alerts off.
+type BalanceAlertSettings struct {
+	Threshold *float64 `json:"threshold"`
+	Currency  *string  `json:"currency"`
+}
+
+// BalanceAlertSubscriber is a group member with a balance alert set and
+// whether they were already alerted for the group.
+type BalanceAlertSubscriber struct {
+	UserID    string
+	Threshold float64
+	Currency  string
+	Alerted   bool
+}
+
+type UserSearchMatch struct {
+	User        User
+	SharesGroup bool
+}
+
+// ContactSuggestion is an existing user whose email matched one of the
+// hashed contacts the caller uploaded.
+type ContactSuggestion struct {
+	ContactHash string `json:"contact_hash"`
+	User        User   `json:"user"`
+	SharesGroup bool   `json:"shares_group"`
+	IsFriend    bool   `json:"is_friend"`
+}
+
 type Currency struct {
 	Code   string `json:"code" db:"code"`
 	Name   string `json:"name" db:"name"`
@@ -38,18 +119,269 @@ const (
 )
 
 type Group struct {
-	ID              string    `json:"id" db:"id"`
-	Name            string    `json:"name" db:"name"`
-	Type            GroupType `json:"type" db:"type"`
-	DefaultCurrency string    `json:"default_currency" db:"default_currency"`
-	AvatarURL       *string   `json:"avatar_url,omitempty" db:"avatar_url"`
-	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
-	MemberCount     int       `json:"member_count,omitempty" db:"member_count"`
-	Members         []User    `json:"members,omitempty"`
-	Balances        []Balance `json:"balances,omitempty"`
-	TotalSpend      float64   `json:"total_spend,omitempty"`
-	HasDebts        bool      `json:"has_debts,omitempty"`
+	ID                  string                 `json:"id" db:"id"`
+	Name                string                 `json:"name" db:"name"`
+	Type                GroupType              `json:"type" db:"type"`
+	DefaultCurrency     string                 `json:"default_currency" db:"default_currency"`
+	AvatarURL           *string                `json:"avatar_url,omitempty" db:"avatar_url"`
+	CreatedAt           time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at" db:"updated_at"`
+	MemberCount         int                    `json:"member_count,omitempty" db:"member_count"`
+	Members             []User                 `json:"members,omitempty"`
+	Balances            []Balance              `json:"balances,omitempty"`
+	TotalSpend          float64                `json:"total_spend,omitempty"`
+	HasDebts            bool                   `json:"has_debts,omitempty"`
+	ExpenseEditPolicy   ExpenseEditPolicy      `json:"expense_edit_policy,omitempty" db:"expense_edit_policy"`
+	SettlementRounding  int                    `json:"settlement_rounding,omitempty" db:"settlement_rounding"`
+	SettlementAlgorithm SettlementAlgorithm    `json:"settlement_algorithm,omitempty" db:"settlement_algorithm"`
+	DefaultLanguage     string                 `json:"default_language,omitempty" db:"default_language"`
+	TaxPreset           TaxPreset              `json:"tax_preset,omitempty" db:"tax_preset"`
+	IsSandbox           bool                   `json:"is_sandbox" db:"is_sandbox"`
+	Limits              *GroupLimits           `json:"limits,omitempty" db:"-"`
+	RecurringExpenses   []RecurringExpenseStub `json:"recurring_expenses,omitempty" db:"-"`
+}
+
+// SettlementAlgorithm decides how settlement suggestions are worked out.
+type SettlementAlgorithm string
+
+const (
+	// SettlementAlgorithmMinTransfers settles everyone's net balance with as
+	// few payments as possible, even between members who never shared an
+	// expense.
+	SettlementAlgorithmMinTransfers SettlementAlgorithm = "MIN_TRANSFERS"
+	// SettlementAlgorithmPairwise settles each pair's own debt, built up from
+	// the expenses they shared.
+	SettlementAlgorithmPairwise SettlementAlgorithm = "PAIRWISE"
+)
+
+// TaxPreset decides which tax components a group's expenses use, so only
+// the fields that make sense in its region are shown and accepted.
+type TaxPreset string
+
+const (
+	TaxPresetIndiaGST   TaxPreset = "IN_GST"
+	TaxPresetUSSalesTax TaxPreset = "US_SALES_TAX"
+	TaxPresetEUVAT      TaxPreset = "EU_VAT"
+	TaxPresetGeneric    TaxPreset = "GENERIC"
+)
+
+// TaxComponent is one tax line a preset allows, keyed in tax_components.
+type TaxComponent struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+type TaxPresetInfo struct {
+	ID         TaxPreset      `json:"id"`
+	Name       string         `json:"name"`
+	Components []TaxComponent `json:"components"`
+}
+
+// ExpenseEditPolicy decides who may edit or delete a group's transactions.
+// Platform admins are always allowed.
+type ExpenseEditPolicy string
+
+const (
+	ExpenseEditPolicyAnyMember      ExpenseEditPolicy = "ANY_MEMBER"
+	ExpenseEditPolicyCreator        ExpenseEditPolicy = "CREATOR"
+	ExpenseEditPolicyCreatorOrPayer ExpenseEditPolicy = "CREATOR_OR_PAYER"
+)
+
+type RecurringFrequency string
+
+const (
+	RecurringFrequencyMonthly RecurringFrequency = "MONTHLY"
+)
+
+type RecurringExpenseStub struct {
+	ID          string             `json:"id,omitempty" db:"id"`
+	GroupID     string             `json:"group_id,omitempty" db:"group_id"`
+	Description string             `json:"description" db:"description"`
+	Category    string             `json:"category,omitempty" db:"category"`
+	Frequency   RecurringFrequency `json:"frequency" db:"frequency"`
+	CreatedAt   *time.Time         `json:"created_at,omitempty" db:"created_at"`
+}
+
+type GroupTemplate struct {
+	ID                string                 `json:"id"`
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description"`
+	Type              GroupType              `json:"type"`
+	Categories        []string               `json:"categories"`
+	RecurringExpenses []RecurringExpenseStub `json:"recurring_expenses"`
+	PlaceholderSlots  []string               `json:"placeholder_slots"`
+}
+
+type CreateGroupOptions struct {
+	TemplateID   string
+	Locale       string
+	Placeholders []string
+	Expenses     []InitialExpense
+	// Sandbox makes a playground group, kept out of the members' totals,
+	// friend balances and notifications.
+	Sandbox bool
+}
+
+// InitialExpense is an expense entered while the group is being created,
+// before anyone in it has an ID. Members are referred to as "me", by one of
+// the invited emails, or by placeholder name.
+type InitialExpense struct {
+	Description  string             `json:"description"`
+	TotalAmount  float64            `json:"total_amount"`
+	Currency     string             `json:"currency,omitempty"`
+	Date         *time.Time         `json:"date,omitempty"`
+	PaidBy       string             `json:"paid_by,omitempty"`
+	Participants []string           `json:"participants,omitempty"`
+	Splits       map[string]float64 `json:"splits,omitempty"`
+}
+
+type GroupLimitAction string
+
+const (
+	GroupLimitActionReject GroupLimitAction = "REJECT"
+	GroupLimitActionFlag   GroupLimitAction = "FLAG"
+)
+
+type GroupLimits struct {
+	MaxExpenseAmount *float64         `json:"max_expense_amount" db:"max_expense_amount"`
+	MaxDailyExpenses *int             `json:"max_daily_expenses" db:"max_daily_expenses"`
+	Action           GroupLimitAction `json:"limit_action" db:"limit_action"`
+	Currency         string           `json:"currency" db:"default_currency"`
+}
+
+// GroupUsage counts what a group's members did between PeriodStart and
+// PeriodEnd. Expenses excludes payments and refunds; receipt scans count only
+// scans made for the group.
+type GroupUsage struct {
+	GroupID        string    `json:"group_id"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	Expenses       int       `json:"expenses"`
+	Comments       int       `json:"comments"`
+	ReceiptScans   int       `json:"receipt_scans"`
+	AIExplanations int       `json:"ai_explanations"`
+}
+
+type RetentionAction string
+
+const (
+	RetentionActionDelete    RetentionAction = "DELETE"
+	RetentionActionAnonymize RetentionAction = "ANONYMIZE"
+)
+
+func (a RetentionAction) IsValid() bool {
+	switch a {
+	case RetentionActionDelete, RetentionActionAnonymize:
+		return true
+	}
+	return false
+}
+
+// RetainYears is 0 when the group keeps everything.
+type GroupRetentionPolicy struct {
+	GroupID         string          `json:"group_id" db:"group_id"`
+	RetainYears     int             `json:"retain_years" db:"retain_years"`
+	Action          RetentionAction `json:"action,omitempty" db:"action"`
+	UpdatedBy       *string         `json:"updated_by,omitempty" db:"updated_by"`
+	NextRunAt       *time.Time      `json:"next_run_at,omitempty" db:"next_run_at"`
+	LastRunAt       *time.Time      `json:"last_run_at,omitempty" db:"last_run_at"`
+	LastArchivePath *string         `json:"last_archive_path,omitempty" db:"last_archive_path"`
+	UpdatedAt       *time.Time      `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+type StandingRepayment struct {
+	ID            string     `json:"id" db:"id"`
+	GroupID       string     `json:"group_id" db:"group_id"`
+	PayerID       string     `json:"payer_id" db:"payer_id"`
+	ReceiverID    string     `json:"receiver_id" db:"receiver_id"`
+	Amount        float64    `json:"amount" db:"amount"`
+	DayOfMonth    int        `json:"day_of_month" db:"day_of_month"`
+	CreatedBy     *string    `json:"created_by,omitempty" db:"created_by"`
+	NextRunAt     time.Time  `json:"next_run_at" db:"next_run_at"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty" db:"last_run_at"`
+	LastExpenseID *string    `json:"last_expense_id,omitempty" db:"last_expense_id"`
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CancelledBy   *string    `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Token is only set in the response that creates the link.
+type GroupShareLink struct {
+	ID           string     `json:"id" db:"id"`
+	GroupID      string     `json:"group_id" db:"group_id"`
+	Token        string     `json:"token,omitempty" db:"-"`
+	CreatedBy    *string    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty" db:"last_viewed_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy    *string    `json:"revoked_by,omitempty" db:"revoked_by"`
+}
+
+type SharedLedger struct {
+	GroupName       string                    `json:"group_name"`
+	DefaultCurrency string                    `json:"default_currency"`
+	Totals          []CurrencySpend           `json:"totals"`
+	Members         []SharedLedgerMember      `json:"members"`
+	Transactions    []SharedLedgerTransaction `json:"transactions"`
+}
+
+type SharedLedgerMember struct {
+	Name     string           `json:"name"`
+	Balances []CurrencyAmount `json:"balances"`
+}
+
+type SharedLedgerTransaction struct {
+	Date        string              `json:"date"`
+	Description string              `json:"description"`
+	Type        TransactionCategory `json:"type"`
+	Amount      float64             `json:"amount"`
+	Currency    string              `json:"currency"`
+	PaidBy      []SharedLedgerShare `json:"paid_by"`
+	SplitAmong  []SharedLedgerShare `json:"split_among"`
+}
+
+type SharedLedgerShare struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+type GroupActivityAction string
+
+const (
+	GroupActivityLimitsUpdated      GroupActivityAction = "LIMITS_UPDATED"
+	GroupActivityLimitOverride      GroupActivityAction = "LIMIT_OVERRIDE"
+	GroupActivityLimitFlagged       GroupActivityAction = "LIMIT_FLAGGED"
+	GroupActivityEditPolicyUpdated  GroupActivityAction = "EDIT_POLICY_UPDATED"
+	GroupActivitySettlementReversed GroupActivityAction = "SETTLEMENT_REVERSED"
+	GroupActivityRoundingUpdated    GroupActivityAction = "SETTLEMENT_ROUNDING_UPDATED"
+	GroupActivityRetentionUpdated   GroupActivityAction = "RETENTION_UPDATED"
+	GroupActivityRetentionApplied   GroupActivityAction = "RETENTION_APPLIED"
+	GroupActivityMemberConverted    GroupActivityAction = "MEMBER_CONVERTED"
+	GroupActivityMemberBackcharged  GroupActivityAction = "MEMBER_BACKCHARGED"
+	GroupActivityLanguageUpdated    GroupActivityAction = "LANGUAGE_UPDATED"
+	GroupActivityExclusionRequested GroupActivityAction = "EXCLUSION_REQUESTED"
+	GroupActivityExclusionAccepted  GroupActivityAction = "EXCLUSION_ACCEPTED"
+	GroupActivityExclusionRejected  GroupActivityAction = "EXCLUSION_REJECTED"
+	GroupActivityChangeProposed     GroupActivityAction = "CHANGE_PROPOSED"
+	GroupActivityChangeApplied      GroupActivityAction = "CHANGE_APPLIED"
+	GroupActivityChangeRejected     GroupActivityAction = "CHANGE_REJECTED"
+	GroupActivityShareLinkCreated   GroupActivityAction = "SHARE_LINK_CREATED"
+	GroupActivityShareLinkRevoked   GroupActivityAction = "SHARE_LINK_REVOKED"
+	GroupActivitySplitsLocked       GroupActivityAction = "SPLITS_LOCKED"
+	GroupActivitySplitsUnlocked     GroupActivityAction = "SPLITS_UNLOCKED"
+	GroupActivityTaxPresetUpdated   GroupActivityAction = "TAX_PRESET_UPDATED"
+	GroupActivityAlgorithmUpdated   GroupActivityAction = "SETTLEMENT_ALGORITHM_UPDATED"
+)
+
+type GroupActivity struct {
+	ID        string              `json:"id" db:"id"`
+	GroupID   string              `json:"group_id" db:"group_id"`
+	ActorID   *string             `json:"actor_id,omitempty" db:"actor_id"`
+	ExpenseID *string             `json:"expense_id,omitempty" db:"expense_id"`
+	Action    GroupActivityAction `json:"action" db:"action"`
+	Message   string              `json:"message" db:"message"`
+	CreatedAt time.Time           `json:"created_at" db:"created_at"`
+	Actor     *User               `json:"actor,omitempty" db:"-"`
 }
 
 type TransactionCategory string
@@ -58,8 +390,56 @@ const (
 	TransactionCategoryExpense   TransactionCategory = "EXPENSE"
 	TransactionCategoryRepayment TransactionCategory = "REPAYMENT"
 	TransactionCategoryPayment   TransactionCategory = "PAYMENT"
+	TransactionCategoryRefund    TransactionCategory = "REFUND"
 )
 
+type SettlementMethod string
+
+const (
+	SettlementMethodCash         SettlementMethod = "CASH"
+	SettlementMethodUPI          SettlementMethod = "UPI"
+	SettlementMethodBankTransfer SettlementMethod = "BANK_TRANSFER"
+	SettlementMethodCard         SettlementMethod = "CARD"
+	SettlementMethodOther        SettlementMethod = "OTHER"
+)
+
+func (m SettlementMethod) IsValid() bool {
+	switch m {
+	case SettlementMethodCash, SettlementMethodUPI, SettlementMethodBankTransfer, SettlementMethodCard, SettlementMethodOther:
+		return true
+	}
+	return false
+}
+
+type SettlementStatus string
+
+const (
+	SettlementStatusActive   SettlementStatus = "ACTIVE"
+	SettlementStatusReversed SettlementStatus = "REVERSED"
+	SettlementStatusReversal SettlementStatus = "REVERSAL"
+)
+
+// ResolveSettlementStatus fills SettlementStatus for payments and
+// repayments from the reversal links; other categories have none.
+func (e *Expense) ResolveSettlementStatus() {
+	switch {
+	case e.Category != TransactionCategoryPayment && e.Category != TransactionCategoryRepayment:
+		e.SettlementStatus = ""
+	case e.ReversesExpenseID != nil:
+		e.SettlementStatus = SettlementStatusReversal
+	case e.ReversedByExpenseID != nil:
+		e.SettlementStatus = SettlementStatusReversed
+	default:
+		e.SettlementStatus = SettlementStatusActive
+	}
+}
+
+type SettlementDetails struct {
+	Method    *SettlementMethod
+	Reference *string
+	ProofPath *string
+}
+
 type ExpenseType string
 
 const (
@@ -70,28 +450,48 @@ const (
 )
 
 type Expense struct {
-	ID              string              `json:"id" db:"id"`
-	GroupID         string              `json:"group_id" db:"group_id"`
-	PaidByUserID    *string             `json:"paid_by_user_id,omitempty" db:"paid_by_user_id"`
-	TotalAmount     float64             `json:"total_amount" db:"total_amount"`
-	Currency        string              `json:"currency" db:"currency"`
-	Description     string              `json:"description" db:"description"`
-	ReceiptImageURL *string             `json:"receipt_image_url,omitempty" db:"receipt_image_url"`
-	Type            ExpenseType         `json:"split_method" db:"type"`
-	Category        TransactionCategory `json:"type" db:"category"`
-	Tax             float64             `json:"tax" db:"tax"`
-	CGST            float64             `json:"cgst" db:"cgst"`
-	SGST            float64             `json:"sgst" db:"sgst"`
-	ServiceCharge   float64             `json:"service_charge" db:"service_charge"`
-	Explanation     *string             `json:"explanation,omitempty" db:"explanation"`
-	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time           `json:"updated_at" db:"updated_at"`
-	DateISO         time.Time           `json:"date_iso" db:"transaction_timestamp"`
-	Date            string              `json:"date" db:"date_only"`
-	Time            string              `json:"time" db:"time_only"`
-	Splits          []ExpenseSplit      `json:"splits,omitempty"`
-	Payers          []ExpensePayer      `json:"payers,omitempty"`
-	ReceiptItems    []ReceiptItem       `json:"receipt_items,omitempty"`
+	ID                  string                 `json:"id" db:"id"`
+	GroupID             string                 `json:"group_id" db:"group_id"`
+	PaidByUserID        *string                `json:"paid_by_user_id,omitempty" db:"paid_by_user_id"`
+	CreatedByUserID     *string                `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	TotalAmount         float64                `json:"total_amount" db:"total_amount"`
+	Currency            string                 `json:"currency" db:"currency"`
+	Description         string                 `json:"description" db:"description"`
+	ReceiptImagePath    *string                `json:"receipt_image_path,omitempty" db:"receipt_image_path"`
+	ReceiptImageURL     *string                `json:"receipt_image_url,omitempty" db:"-"`
+	Type                ExpenseType            `json:"split_method" db:"type"`
+	Category            TransactionCategory    `json:"type" db:"category"`
+	OriginalExpenseID   *string                `json:"original_expense_id,omitempty" db:"original_expense_id"`
+	SettlementMethod    *SettlementMethod      `json:"settlement_method,omitempty" db:"settlement_method"`
+	SettlementReference *string                `json:"settlement_reference,omitempty" db:"settlement_reference"`
+	SettlementProofPath *string                `json:"settlement_proof_path,omitempty" db:"settlement_proof_path"`
+	SettlementProofURL  *string                `json:"settlement_proof_url,omitempty" db:"-"`
+	ReversesExpenseID   *string                `json:"reverses_expense_id,omitempty" db:"reverses_expense_id"`
+	ReversedByExpenseID *string                `json:"reversed_by_expense_id,omitempty" db:"-"`
+	SettlesExpenseID    *string                `json:"settles_expense_id,omitempty" db:"settles_expense_id"`
+	EventID             *string                `json:"event_id,omitempty" db:"event_id"`
+	SettlementStatus    SettlementStatus       `json:"settlement_status,omitempty" db:"-"`
+	LimitFlagged        bool                   `json:"limit_flagged" db:"limit_flagged"`
+	LockedSplits        bool                   `json:"locked_splits" db:"locked_splits"`
+	ConfirmOverLimit    bool                   `json:"-" db:"-"`
+	ParticipantIDs      []string               `json:"-" db:"-"`
+	Tax                 float64                `json:"tax" db:"tax"`
+	CGST                float64                `json:"cgst" db:"cgst"`
+	SGST                float64                `json:"sgst" db:"sgst"`
+	ServiceCharge       float64                `json:"service_charge" db:"service_charge"`
+	TaxComponents       map[string]float64     `json:"tax_components,omitempty" db:"tax_components"`
+	Explanation         *string                `json:"explanation,omitempty" db:"explanation"`
+	CreatedAt           time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at" db:"updated_at"`
+	DateISO             time.Time              `json:"date_iso" db:"transaction_timestamp"`
+	Date                string                 `json:"date" db:"date_only"`
+	Time                string                 `json:"time" db:"time_only"`
+	Splits              []ExpenseSplit         `json:"splits,omitempty"`
+	Payers              []ExpensePayer         `json:"payers,omitempty"`
+	ReceiptItems        []ReceiptItem          `json:"receipt_items,omitempty"`
+	Tags                []string               `json:"tags,omitempty"`
+	Reconciliation      *ReceiptReconciliation `json:"reconciliation,omitempty" db:"-"`
+	PendingChange       *PendingExpenseChange  `json:"pending_change,omitempty" db:"-"`
 }
 
 type ExpensePayer struct {
@@ -104,26 +504,91 @@ type ExpensePayer struct {
 
 type Transaction struct {
 	Expense
-	PaidByUser      *User   `json:"paid_by_user,omitempty"`
-	Type            string  `json:"type,omitempty"`
-	UserShare       float64 `json:"user_share,omitempty"`
-	UserNetAmount   float64 `json:"user_net_amount,omitempty"`
-	UserIsOwed      bool    `json:"user_is_owed,omitempty"`
-	UserIsLent      bool    `json:"user_is_lent,omitempty"`
-	UserIsPayer     bool    `json:"user_is_payer,omitempty"`
-	UserIsRecipient bool    `json:"user_is_recipient,omitempty"`
+	PaidByUser      *User      `json:"paid_by_user,omitempty"`
+	Type            string     `json:"type,omitempty"`
+	UserShare       float64    `json:"user_share,omitempty"`
+	UserNetAmount   float64    `json:"user_net_amount,omitempty"`
+	UserIsOwed      bool       `json:"user_is_owed,omitempty"`
+	UserIsLent      bool       `json:"user_is_lent,omitempty"`
+	UserIsPayer     bool       `json:"user_is_payer,omitempty"`
+	UserIsRecipient bool       `json:"user_is_recipient,omitempty"`
+	SeenAt          *time.Time `json:"seen_at,omitempty"`
+	IsNew           bool       `json:"is_new"`
+	// Set only with ?include=formatting.
+	CurrencySymbol     string `json:"currency_symbol,omitempty"`
+	AmountFormatted    string `json:"amount_formatted,omitempty"`
+	UserShareFormatted string `json:"user_share_formatted,omitempty"`
+}
+
+// SplitExclusionStatus tracks a participant's "not for me" flag on their
+// split. Accepted flags leave no trace: the split is removed.
+type SplitExclusionStatus string
+
+const (
+	SplitExclusionPending  SplitExclusionStatus = "PENDING"
+	SplitExclusionRejected SplitExclusionStatus = "REJECTED"
+)
+
+type ExpenseChangeStatus string
+
+const (
+	ExpenseChangePending    ExpenseChangeStatus = "PENDING"
+	ExpenseChangeApplied    ExpenseChangeStatus = "APPLIED"
+	ExpenseChangeRejected   ExpenseChangeStatus = "REJECTED"
+	ExpenseChangeSuperseded ExpenseChangeStatus = "SUPERSEDED"
+)
+
+type ExpenseChangeConfirmationStatus string
+
+const (
+	ExpenseChangeConfirmationPending  ExpenseChangeConfirmationStatus = "PENDING"
+	ExpenseChangeConfirmationAccepted ExpenseChangeConfirmationStatus = "ACCEPTED"
+	ExpenseChangeConfirmationRejected ExpenseChangeConfirmationStatus = "REJECTED"
+)
+
+type PendingExpenseChange struct {
+	ID            string                      `json:"id" db:"id"`
+	ExpenseID     string                      `json:"expense_id" db:"expense_id"`
+	GroupID       string                      `json:"group_id" db:"group_id"`
+	RequestedBy   string                      `json:"requested_by" db:"requested_by"`
+	Status        ExpenseChangeStatus         `json:"status" db:"status"`
+	Proposed      *Expense                    `json:"proposed,omitempty" db:"-"`
+	Confirmations []ExpenseChangeConfirmation `json:"confirmations"`
+	CreatedAt     time.Time                   `json:"created_at" db:"created_at"`
+	ResolvedAt    *time.Time                  `json:"resolved_at,omitempty" db:"resolved_at"`
+	Payload       []byte                      `json:"-" db:"payload"`
+}
+
+type ExpenseChangeConfirmation struct {
+	UserID      string                          `json:"user_id" db:"user_id"`
+	Name        string                          `json:"name,omitempty"`
+	Status      ExpenseChangeConfirmationStatus `json:"status" db:"status"`
+	RespondedAt *time.Time                      `json:"responded_at,omitempty" db:"responded_at"`
+}
+
+// PairLedger is what UserB owes UserA in one currency.
+type PairLedger struct {
+	UserA         string
+	UserB         string
+	Net           float64
+	LastSettledAt *time.Time
 }
 
 type ExpenseSplit struct {
-	ID         string    `json:"id" db:"id"`
-	ExpenseID  string    `json:"expense_id" db:"expense_id"`
-	UserID     string    `json:"user_id" db:"user_id"`
-	Amount     float64   `json:"amount" db:"amount"`
-	Percentage *float64  `json:"percentage,omitempty" db:"percentage"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
-	UserName   string    `json:"user_name,omitempty"`
-	UserEmail  string    `json:"user_email,omitempty"`
+	ID                   string                `json:"id" db:"id"`
+	ExpenseID            string                `json:"expense_id" db:"expense_id"`
+	UserID               string                `json:"user_id" db:"user_id"`
+	Amount               float64               `json:"amount" db:"amount"`
+	Percentage           *float64              `json:"percentage,omitempty" db:"percentage"`
+	ExclusionStatus      *SplitExclusionStatus `json:"exclusion_status,omitempty" db:"exclusion_status"`
+	ExclusionReason      *string               `json:"exclusion_reason,omitempty" db:"exclusion_reason"`
+	ExclusionRequestedAt *time.Time            `json:"exclusion_requested_at,omitempty" db:"exclusion_requested_at"`
+	FirstNotifiedAt      *time.Time            `json:"first_notified_at,omitempty" db:"-"`
+	FirstSeenAt          *time.Time            `json:"first_seen_at,omitempty" db:"-"`
+	CreatedAt            time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time             `json:"updated_at" db:"updated_at"`
+	UserName             string                `json:"user_name,omitempty"`
+	UserEmail            string                `json:"user_email,omitempty"`
 }
 
 type ReceiptItem struct {
@@ -131,14 +596,34 @@ type ReceiptItem struct {
 	ExpenseID   string                  `json:"expense_id" db:"expense_id"`
 	Name        string                  `json:"name" db:"name"`
 	Price       float64                 `json:"price" db:"price"`
+	Quantity    float64                 `json:"quantity" db:"quantity"`
+	UnitPrice   float64                 `json:"unit_price"`
 	CreatedAt   time.Time               `json:"created_at" db:"created_at"`
 	Assignments []ReceiptItemAssignment `json:"assignments,omitempty"`
 }
 
+type ReconciliationStatus string
+
+const (
+	ReconciliationMatched ReconciliationStatus = "MATCHED"
+	ReconciliationOver    ReconciliationStatus = "OVER"
+	ReconciliationUnder   ReconciliationStatus = "UNDER"
+)
+
+type ReceiptReconciliation struct {
+	Status       ReconciliationStatus `json:"status"`
+	ItemsTotal   float64              `json:"items_total"`
+	ChargesTotal float64              `json:"charges_total"`
+	ReceiptTotal float64              `json:"receipt_total"`
+	Delta        float64              `json:"delta"`
+}
+
 type ReceiptItemAssignment struct {
 	ID            string    `json:"id" db:"id"`
 	ReceiptItemID string    `json:"receipt_item_id" db:"receipt_item_id"`
 	UserID        string    `json:"user_id" db:"user_id"`
+	Portion       float64   `json:"portion" db:"portion"`
+	Amount        float64   `json:"amount"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -171,6 +656,7 @@ type GroupWithBalances struct {
 	Summary      GroupSummary             `json:"summary"`
 	MemberCount  int                      `json:"member_count,omitempty"`
 	TotalBalance float64                  `json:"total_balance,omitempty"`
+	Archived     bool                     `json:"archived,omitempty"`
 }
 
 type UserBalance struct {
@@ -208,10 +694,14 @@ type BalanceSummary struct {
 }
 
 type DebtEdge struct {
-	FromUser UserInfo `json:"from_user"`
-	ToUser   UserInfo `json:"to_user"`
-	Amount   float64  `json:"amount"`
-	Currency string   `json:"currency"`
+	FromUser         UserInfo          `json:"from_user"`
+	ToUser           UserInfo          `json:"to_user"`
+	Amount           float64           `json:"amount"`
+	Currency         string            `json:"currency"`
+	ReminderResponse *ReminderResponse `json:"reminder_response,omitempty"`
+	// Set only with ?include=formatting.
+	CurrencySymbol  string `json:"currency_symbol,omitempty"`
+	AmountFormatted string `json:"amount_formatted,omitempty"`
 }
 
 type UserInfo struct {
@@ -223,6 +713,7 @@ type UserInfo struct {
 type GroupBalancesEdgeResponse struct {
 	Summary BalanceSummary `json:"summary"`
 	Debts   []DebtEdge     `json:"debts"`
+	AsOf    string         `json:"as_of,omitempty"`
 }
 
 type Settlement struct {
@@ -232,27 +723,57 @@ type Settlement struct {
 	Currency   string  `json:"currency"`
 }
 
+// PairBalance is what UserB owes UserA in Currency across a group's
+// transactions; negative when UserA owes UserB. UserA sorts before UserB.
+type PairBalance struct {
+	Currency string
+	UserA    string
+	UserB    string
+	Net      float64
+}
+
 type ReceiptParseResult struct {
-	Items            []ReceiptItemData `json:"items"`
-	Subtotal         float64           `json:"subtotal"`
-	Tax              float64           `json:"tax"`
-	CGST             float64           `json:"cgst"`
-	SGST             float64           `json:"sgst"`
-	ServiceCharge    float64           `json:"service_charge"`
-	Total            float64           `json:"total"`
-	PricesIncludeTax bool              `json:"prices_include_tax"`
+	Items            []ReceiptItemData  `json:"items"`
+	Subtotal         float64            `json:"subtotal"`
+	Tax              float64            `json:"tax"`
+	CGST             float64            `json:"cgst"`
+	SGST             float64            `json:"sgst"`
+	ServiceCharge    float64            `json:"service_charge"`
+	TaxComponents    map[string]float64 `json:"tax_components,omitempty"`
+	Total            float64            `json:"total"`
+	PricesIncludeTax bool               `json:"prices_include_tax"`
+	Currency         string             `json:"currency"`
+	Locale           string             `json:"locale"`
+	CurrencySource   string             `json:"-"`
+	OutputID         string             `json:"-"`
 }
 
+const (
+	ReceiptCurrencyFromReceipt = "receipt"
+	ReceiptCurrencyFromLocale  = "locale"
+)
+
 type ReceiptItemData struct {
 	Name  string  `json:"name"`
 	Price float64 `json:"price"`
 }
 
 type DashboardResponse struct {
-	User           DashboardUserInfo   `json:"user"`
-	Metrics        DashboardMetrics    `json:"metrics"`
-	Groups         []DashboardGroup    `json:"groups"`
-	RecentActivity []DashboardActivity `json:"recent_activity"`
+	User               DashboardUserInfo   `json:"user"`
+	Metrics            DashboardMetrics    `json:"metrics"`
+	Groups             []DashboardGroup    `json:"groups"`
+	RecentActivity     []DashboardActivity `json:"recent_activity"`
+	ArchiveSuggestions []ArchiveSuggestion `json:"archive_suggestions"`
+	Version            string              `json:"-"`
+}
+
+// ArchiveSuggestion is a group the dashboard offers to archive: every member
+// is settled up and nothing has been added for a while.
+type ArchiveSuggestion struct {
+	GroupID        string    `json:"group_id"`
+	Name           string    `json:"name"`
+	AvatarURL      *string   `json:"avatar_url,omitempty"`
+	LastActivityAt time.Time `json:"last_activity_at"`
 }
 
 type DashboardUserInfo struct {
@@ -262,12 +783,12 @@ type DashboardUserInfo struct {
 }
 
 type DashboardMetrics struct {
-	TotalNetBalance float64 `json:"total_net_balance"`
-	TotalYouOwe     float64 `json:"total_you_owe"`
-	TotalYouAreOwed float64 `json:"total_you_are_owed"`
-	TotalBalances []CurrencyAmount `json:"total_balances,omitempty"`
-	BalancesOwed  []CurrencyAmount `json:"balances_owed,omitempty"`
-	BalancesOwe   []CurrencyAmount `json:"balances_owe,omitempty"`
+	TotalNetBalance float64          `json:"total_net_balance"`
+	TotalYouOwe     float64          `json:"total_you_owe"`
+	TotalYouAreOwed float64          `json:"total_you_are_owed"`
+	TotalBalances   []CurrencyAmount `json:"total_balances"`
+	BalancesOwed    []CurrencyAmount `json:"balances_owed"`
+	BalancesOwe     []CurrencyAmount `json:"balances_owe"`
 }
 
 type DashboardGroup struct {
@@ -276,6 +797,8 @@ type DashboardGroup struct {
 	AvatarURL        *string   `json:"avatar_url,omitempty"`
 	MyBalanceInGroup float64   `json:"my_balance_in_group"`
 	LastActivityAt   time.Time `json:"last_activity_at"`
+	UnreadCount      int       `json:"unread_count"`
+	IsSandbox        bool      `json:"is_sandbox"`
 }
 
 type Comment struct {
@@ -285,7 +808,7 @@ type Comment struct {
 	User      *User             `json:"user,omitempty"`
 	Text      string            `json:"text" db:"text"`
 	CreatedAt time.Time         `json:"created_at" db:"created_at"`
-	Reactions []CommentReaction `json:"reactions,omitempty"`
+	Reactions []CommentReaction `json:"reactions"`
 }
 
 type CommentReaction struct {
@@ -298,14 +821,15 @@ type CommentReaction struct {
 }
 
 type DashboardActivity struct {
-	ID              string    `json:"id"`
-	Description     string    `json:"description"`
-	Amount          float64   `json:"amount"`
-	Type            string    `json:"type"`
-	ActionText      string    `json:"action_text"`
-	ReceiptImageURL *string   `json:"receipt_image_url,omitempty"`
-	CreatedAt       time.Time `json:"created_at"`
-	Date            time.Time `json:"date"`
+	ID               string    `json:"id"`
+	Description      string    `json:"description"`
+	Amount           float64   `json:"amount"`
+	Type             string    `json:"type"`
+	ActionText       string    `json:"action_text"`
+	ReceiptImagePath *string   `json:"-"`
+	ReceiptImageURL  *string   `json:"receipt_image_url,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	Date             time.Time `json:"date"`
 }
 type Friend struct {
 	UserID    string    `json:"user_id" db:"user_id"`
@@ -323,17 +847,1032 @@ type FriendGroupBalance struct {
 type FriendWithBalance struct {
 	UserInfo
 	Email         string               `json:"email"`
-	NetBalance    float64              `json:"net_balance"`        
-	Balances      []CurrencyAmount     `json:"balances,omitempty"` 
+	NetBalance    float64              `json:"net_balance"`
+	Balances      []CurrencyAmount     `json:"balances"`
 	Groups        []DashboardGroup     `json:"groups"`
 	GroupBalances []FriendGroupBalance `json:"group_balances"`
 }
 
+// SharedTransaction is one transaction in a common group that involves both
+// the user and a friend, seen from the user's side. Net is what the friend
+// owes the user because of it (negative when the user owes the friend).
+type SharedTransaction struct {
+	ExpenseID   string              `json:"expense_id"`
+	Date        string              `json:"date"`
+	GroupID     string              `json:"group_id"`
+	GroupName   string              `json:"group_name"`
+	Description string              `json:"description"`
+	Category    TransactionCategory `json:"type"`
+	Currency    string              `json:"currency"`
+	TotalAmount float64             `json:"total_amount"`
+	TotalPaid   float64             `json:"-"`
+	UserPaid    float64             `json:"user_paid"`
+	FriendPaid  float64             `json:"friend_paid"`
+	UserShare   float64             `json:"user_share"`
+	FriendShare float64             `json:"friend_share"`
+	Net         float64             `json:"net"`
+}
+
+// BalanceHistoryBucket is the net change in what a friend owes the user over
+// one period, in one currency. Period is the first day of the period.
+type BalanceHistoryBucket struct {
+	Period       time.Time
+	Currency     string
+	Change       float64
+	Transactions int
+}
+
+type BalanceHistoryPoint struct {
+	Period       string  `json:"period"`
+	Change       float64 `json:"change"`
+	Balance      float64 `json:"balance"`
+	Transactions int     `json:"transactions"`
+}
+
+type BalanceHistorySeries struct {
+	Currency string                `json:"currency"`
+	Points   []BalanceHistoryPoint `json:"points"`
+}
+
+// FriendBalanceHistory charts how the balance with a friend evolved. Positive
+// balances are owed to the user.
+type FriendBalanceHistory struct {
+	Friend      UserInfo               `json:"friend"`
+	Granularity string                 `json:"granularity"`
+	Series      []BalanceHistorySeries `json:"series"`
+}
+
 type DebtExplanation struct {
-	TransactionID string `json:"transaction_id"`
-	Explanation   string `json:"explanation"`
+	TransactionID    string            `json:"transaction_id"`
+	Explanation      string            `json:"explanation"`
+	OutputID         string            `json:"output_id,omitempty"`
+	SuggestedActions []SuggestedAction `json:"suggested_actions"`
+}
+
+type SuggestedActionType string
+
+const (
+	SuggestedActionSettle SuggestedActionType = "settle"
+	SuggestedActionRemind SuggestedActionType = "remind"
+)
+
+// SuggestedAction is a payment FromUserID should make to ToUserID that the
+// requesting user can act on: "settle" when they are the one paying, "remind"
+// when they are the one owed.
+type SuggestedAction struct {
+	Action     SuggestedActionType `json:"action"`
+	GroupID    string              `json:"group_id"`
+	FromUserID string              `json:"from_user"`
+	FromName   string              `json:"from_name"`
+	ToUserID   string              `json:"to_user"`
+	ToName     string              `json:"to_name"`
+	Amount     float64             `json:"amount"`
 }
 
 type ExplanationRequest struct {
 	TransactionID string `json:"transaction_id"`
 }
+
+type NotificationEvent string
+
+const (
+	NotificationEventNewExpense NotificationEvent = "NEW_EXPENSE"
+	NotificationEventComment    NotificationEvent = "COMMENT"
+	NotificationEventSettlement NotificationEvent = "SETTLEMENT"
+	NotificationEventReminder   NotificationEvent = "REMINDER"
+	NotificationEventExclusion  NotificationEvent = "EXCLUSION"
+	NotificationEventChange     NotificationEvent = "CHANGE"
+	NotificationEventBalance    NotificationEvent = "BALANCE_ALERT"
+)
+
+type Notification struct {
+	ID        string            `json:"id" db:"id"`
+	UserID    string            `json:"user_id" db:"user_id"`
+	GroupID   *string           `json:"group_id,omitempty" db:"group_id"`
+	ExpenseID *string           `json:"expense_id,omitempty" db:"expense_id"`
+	ActorID   *string           `json:"actor_id,omitempty" db:"actor_id"`
+	Event     NotificationEvent `json:"event" db:"event"`
+	Message   string            `json:"message" db:"message"`
+	ReadAt    *time.Time        `json:"read_at,omitempty" db:"read_at"`
+	DeliverAt time.Time         `json:"deliver_at" db:"deliver_at"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+}
+
+// Urgent events ignore a group's quiet hours: a settlement means money moved.
+func (e NotificationEvent) Urgent() bool {
+	return e == NotificationEventSettlement
+}
+
+// GroupQuietHours holds non-urgent notifications between Start and End
+// ("HH:MM", in TimeZone). End before Start means the window spans midnight.
+type GroupQuietHours struct {
+	GroupID   string    `json:"group_id" db:"group_id"`
+	Enabled   bool      `json:"enabled" db:"enabled"`
+	Start     string    `json:"start" db:"start_time"`
+	End       string    `json:"end" db:"end_time"`
+	TimeZone  string    `json:"time_zone" db:"time_zone"`
+	UpdatedBy *string   `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type GroupNotificationSettings struct {
+	GroupID     string    `json:"group_id" db:"group_id"`
+	UserID      string    `json:"user_id" db:"user_id"`
+	Muted       bool      `json:"muted" db:"muted"`
+	NewExpenses bool      `json:"new_expenses" db:"new_expenses"`
+	Comments    bool      `json:"comments" db:"comments"`
+	Settlements bool      `json:"settlements" db:"settlements"`
+	Reminders   bool      `json:"reminders" db:"reminders"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func DefaultGroupNotificationSettings(groupID, userID string) GroupNotificationSettings {
+	return GroupNotificationSettings{
+		GroupID:     groupID,
+		UserID:      userID,
+		NewExpenses: true,
+		Comments:    true,
+		Settlements: true,
+		Reminders:   true,
+	}
+}
+
+func (s GroupNotificationSettings) Allows(event NotificationEvent) bool {
+	if s.Muted {
+		return false
+	}
+	switch event {
+	case NotificationEventNewExpense:
+		return s.NewExpenses
+	case NotificationEventComment:
+		return s.Comments
+	case NotificationEventSettlement:
+		return s.Settlements
+	case NotificationEventReminder:
+		return s.Reminders
+	default:
+		return true
+	}
+}
+
+type SplitPreference struct {
+	ID               string    `json:"id" db:"id"`
+	UserAID          string    `json:"-" db:"user_a_id"`
+	UserBID          string    `json:"-" db:"user_b_id"`
+	GroupID          *string   `json:"group_id,omitempty" db:"group_id"`
+	UserAPercentage  float64   `json:"-" db:"user_a_percentage"`
+	FriendID         string    `json:"friend_id"`
+	UserPercentage   float64   `json:"user_percentage"`
+	FriendPercentage float64   `json:"friend_percentage"`
+	UpdatedBy        *string   `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (p *SplitPreference) PercentageFor(userID string) float64 {
+	if userID == p.UserAID {
+		return p.UserAPercentage
+	}
+	return 100 - p.UserAPercentage
+}
+
+type AIOutputKind string
+
+const (
+	AIOutputExplanation AIOutputKind = "EXPLANATION"
+	AIOutputReceiptScan AIOutputKind = "RECEIPT_SCAN"
+)
+
+type AIFeedbackRating string
+
+const (
+	AIFeedbackUp   AIFeedbackRating = "UP"
+	AIFeedbackDown AIFeedbackRating = "DOWN"
+)
+
+func (r AIFeedbackRating) IsValid() bool {
+	return r == AIFeedbackUp || r == AIFeedbackDown
+}
+
+type AIOutput struct {
+	ID        string       `json:"id" db:"id"`
+	Kind      AIOutputKind `json:"kind" db:"kind"`
+	UserID    *string      `json:"user_id,omitempty" db:"user_id"`
+	GroupID   *string      `json:"group_id,omitempty" db:"group_id"`
+	ExpenseID *string      `json:"expense_id,omitempty" db:"expense_id"`
+	Model     string       `json:"model" db:"model"`
+	Prompt    string       `json:"prompt" db:"prompt"`
+	Response  string       `json:"response" db:"response"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+}
+
+type AIFeedback struct {
+	ID        string           `json:"id" db:"id"`
+	OutputID  string           `json:"output_id" db:"output_id"`
+	UserID    string           `json:"user_id" db:"user_id"`
+	Rating    AIFeedbackRating `json:"rating" db:"rating"`
+	Comment   *string          `json:"comment,omitempty" db:"comment"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+type AIOutputStats struct {
+	Kind       AIOutputKind `json:"kind"`
+	Outputs    int          `json:"outputs"`
+	Rated      int          `json:"rated"`
+	ThumbsUp   int          `json:"thumbs_up"`
+	ThumbsDown int          `json:"thumbs_down"`
+	Accuracy   *float64     `json:"accuracy,omitempty"`
+}
+
+type ReminderSkipReason string
+
+const (
+	ReminderSkipCooldown          ReminderSkipReason = "COOLDOWN"
+	ReminderSkipPlaceholder       ReminderSkipReason = "PLACEHOLDER"
+	ReminderSkipRemindersDisabled ReminderSkipReason = "REMINDERS_DISABLED"
+	ReminderSkipFailed            ReminderSkipReason = "FAILED"
+	ReminderSkipResponded         ReminderSkipReason = "RESPONDED"
+)
+
+type ReminderResponseStatus string
+
+const (
+	ReminderResponsePromised ReminderResponseStatus = "PROMISED"
+	ReminderResponseSnoozed  ReminderResponseStatus = "SNOOZED"
+)
+
+// ReminderResponse is a debtor's answer to a creditor's reminders in one
+// group: a promise to pay by PayBy, or a snooze. The creditor's reminders are
+// held until HeldUntil, after which the response has run out.
+type ReminderResponse struct {
+	GroupID    string                 `json:"group_id" db:"group_id"`
+	DebtorID   string                 `json:"debtor_id" db:"debtor_id"`
+	CreditorID string                 `json:"creditor_id" db:"creditor_id"`
+	Status     ReminderResponseStatus `json:"status" db:"status"`
+	PayBy      *string                `json:"pay_by,omitempty" db:"pay_by"`
+	HeldUntil  time.Time              `json:"held_until" db:"held_until"`
+	Note       *string                `json:"note,omitempty" db:"note"`
+	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+type ReminderResponseRequest struct {
+	Status     ReminderResponseStatus `json:"status"`
+	PayBy      string                 `json:"pay_by"`
+	SnoozeDays int                    `json:"snooze_days"`
+	Note       string                 `json:"note"`
+}
+
+type ReminderGroupDebt struct {
+	GroupID   string  `json:"group_id"`
+	GroupName string  `json:"group_name"`
+	Currency  string  `json:"currency"`
+	Amount    float64 `json:"amount"`
+}
+
+type ReminderOutcome struct {
+	UserInfo
+	Debts          []ReminderGroupDebt `json:"debts"`
+	Reason         ReminderSkipReason  `json:"reason,omitempty"`
+	LastRemindedAt *time.Time          `json:"last_reminded_at,omitempty"`
+	NextAllowedAt  *time.Time          `json:"next_allowed_at,omitempty"`
+}
+
+type RemindAllResponse struct {
+	Reminded []ReminderOutcome `json:"reminded"`
+	Skipped  []ReminderOutcome `json:"skipped"`
+}
+
+type IntegrationPlatform string
+
+const (
+	IntegrationPlatformSlack    IntegrationPlatform = "SLACK"
+	IntegrationPlatformDiscord  IntegrationPlatform = "DISCORD"
+	IntegrationPlatformTelegram IntegrationPlatform = "TELEGRAM"
+)
+
+func (p IntegrationPlatform) IsValid() bool {
+	switch p {
+	case IntegrationPlatformSlack, IntegrationPlatformDiscord, IntegrationPlatformTelegram:
+		return true
+	}
+	return false
+}
+
+type GroupIntegration struct {
+	ID                 string              `json:"id" db:"id"`
+	GroupID            string              `json:"group_id" db:"group_id"`
+	Platform           IntegrationPlatform `json:"platform" db:"platform"`
+	WebhookURL         string              `json:"-" db:"webhook_url"`
+	Target             string              `json:"target" db:"-"`
+	ChatID             *string             `json:"chat_id,omitempty" db:"chat_id"`
+	NotifyExpenses     bool                `json:"notify_expenses" db:"notify_expenses"`
+	NotifySettlements  bool                `json:"notify_settlements" db:"notify_settlements"`
+	ExpenseTemplate    *string             `json:"expense_template,omitempty" db:"expense_template"`
+	SettlementTemplate *string             `json:"settlement_template,omitempty" db:"settlement_template"`
+	Enabled            bool                `json:"enabled" db:"enabled"`
+	CreatedBy          *string             `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt          time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+func (i GroupIntegration) Allows(event NotificationEvent) bool {
+	if !i.Enabled {
+		return false
+	}
+	switch event {
+	case NotificationEventNewExpense:
+		return i.NotifyExpenses
+	case NotificationEventSettlement:
+		return i.NotifySettlements
+	default:
+		return false
+	}
+}
+
+type IntegrationDeliveryStatus string
+
+const (
+	IntegrationDeliveryPending IntegrationDeliveryStatus = "PENDING"
+	IntegrationDeliverySent    IntegrationDeliveryStatus = "SENT"
+	IntegrationDeliveryFailed  IntegrationDeliveryStatus = "FAILED"
+)
+
+type IntegrationDelivery struct {
+	ID            string                    `json:"id" db:"id"`
+	IntegrationID string                    `json:"integration_id" db:"integration_id"`
+	ExpenseID     *string                   `json:"expense_id,omitempty" db:"expense_id"`
+	Message       string                    `json:"message" db:"message"`
+	Status        IntegrationDeliveryStatus `json:"status" db:"status"`
+	Attempts      int                       `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time                 `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string                   `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time                 `json:"created_at" db:"created_at"`
+	SentAt        *time.Time                `json:"sent_at,omitempty" db:"sent_at"`
+	Integration   *GroupIntegration         `json:"-" db:"-"`
+}
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "PENDING"
+	JobSucceeded JobStatus = "SUCCEEDED"
+	JobDead      JobStatus = "DEAD"
+)
+
+type Job struct {
+	ID          string          `json:"id" db:"id"`
+	Type        string          `json:"type" db:"type"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Status      JobStatus       `json:"status" db:"status"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"max_attempts" db:"max_attempts"`
+	RunAt       time.Time       `json:"run_at" db:"run_at"`
+	LastError   *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+type OrphanCheck struct {
+	Table       string `json:"table"`
+	Check       string `json:"check"`
+	Description string `json:"description"`
+	Count       int64  `json:"count"`
+}
+
+type OrphanReport struct {
+	Checks       []OrphanCheck `json:"checks"`
+	TotalOrphans int64         `json:"total_orphans"`
+	GeneratedAt  time.Time     `json:"generated_at"`
+}
+
+type PlaceholderPurgeResult struct {
+	Placeholders []User `json:"placeholders"`
+	Deleted      int64  `json:"deleted"`
+	DryRun       bool   `json:"dry_run"`
+}
+
+type MemberCurrencyBalance struct {
+	UserID   string  `json:"user_id"`
+	Name     string  `json:"name"`
+	Currency string  `json:"currency"`
+	Balance  float64 `json:"balance"`
+}
+
+type UnbalancedExpense struct {
+	ExpenseID   string  `json:"expense_id"`
+	Description string  `json:"description"`
+	Currency    string  `json:"currency"`
+	TotalAmount float64 `json:"total_amount"`
+	PaidTotal   float64 `json:"paid_total"`
+	SplitTotal  float64 `json:"split_total"`
+}
+
+type GroupBalanceRebuild struct {
+	GroupID            string                  `json:"group_id"`
+	GroupName          string                  `json:"group_name"`
+	Balances           []MemberCurrencyBalance `json:"balances"`
+	CurrencyDrift      map[string]float64      `json:"currency_drift"`
+	UnbalancedExpenses []UnbalancedExpense     `json:"unbalanced_expenses"`
+	LedgerMismatches   []LedgerMismatch        `json:"ledger_mismatches"`
+	GeneratedAt        time.Time               `json:"generated_at"`
+}
+
+type LedgerMismatch struct {
+	UserID          string  `json:"user_id"`
+	Name            string  `json:"name"`
+	Currency        string  `json:"currency"`
+	LedgerBalance   float64 `json:"ledger_balance"`
+	ComputedBalance float64 `json:"computed_balance"`
+	Drift           float64 `json:"drift"`
+}
+
+type ForecastShare struct {
+	UserID string  `json:"user_id"`
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+type ForecastItem struct {
+	Category    string          `json:"category"`
+	Description string          `json:"description,omitempty"`
+	Currency    string          `json:"currency"`
+	Amount      float64         `json:"amount"`
+	Recurring   bool            `json:"recurring"`
+	Occurrences int             `json:"occurrences"`
+	Shares      []ForecastShare `json:"shares"`
+}
+
+type ForecastMemberTotal struct {
+	UserID   string  `json:"user_id"`
+	Name     string  `json:"name"`
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+type GroupForecast struct {
+	GroupID       string                `json:"group_id"`
+	Month         string                `json:"month"`
+	HistoryFrom   time.Time             `json:"history_from"`
+	HistoryTo     time.Time             `json:"history_to"`
+	HistoryMonths int                   `json:"history_months"`
+	Items         []ForecastItem        `json:"items"`
+	Totals        []CurrencyAmount      `json:"totals"`
+	Members       []ForecastMemberTotal `json:"members"`
+	GeneratedAt   time.Time             `json:"generated_at"`
+}
+
+type FunStatExpense struct {
+	ExpenseID   string  `json:"expense_id"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Date        string  `json:"date"`
+	PaidByID    *string `json:"paid_by_user_id,omitempty"`
+	PaidByName  *string `json:"paid_by_name,omitempty"`
+}
+
+// FunStatMember is how much one member paid for the group compared with their
+// own share of its expenses.
+type FunStatMember struct {
+	UserID     string  `json:"user_id"`
+	Name       string  `json:"name"`
+	TimesPaid  int     `json:"times_paid"`
+	AmountPaid float64 `json:"amount_paid"`
+	AmountOwed float64 `json:"amount_owed"`
+	PayerRatio float64 `json:"payer_ratio"`
+}
+
+type FunStatStreak struct {
+	Days int    `json:"days"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type GroupFunStats struct {
+	GroupID                  string          `json:"group_id"`
+	Currency                 string          `json:"currency"`
+	ExpenseCount             int             `json:"expense_count"`
+	TotalSpend               float64         `json:"total_spend"`
+	BiggestExpense           *FunStatExpense `json:"biggest_expense"`
+	MostFrequentPayer        *FunStatMember  `json:"most_frequent_payer"`
+	MostLikelyToForgetWallet *FunStatMember  `json:"most_likely_to_forget_wallet"`
+	LongestQuietStreak       *FunStatStreak  `json:"longest_quiet_streak"`
+	GeneratedAt              time.Time       `json:"generated_at"`
+}
+
+// HeatmapCell is the spending in one hour of one day of the week, in UTC.
+// DayOfWeek runs from 1 (Monday) to 7 (Sunday).
+type HeatmapCell struct {
+	DayOfWeek int     `json:"day_of_week"`
+	Hour      int     `json:"hour"`
+	Count     int     `json:"count"`
+	Total     float64 `json:"total"`
+}
+
+type HeatmapMember struct {
+	UserID string  `json:"user_id"`
+	Name   string  `json:"name"`
+	Count  int     `json:"count"`
+	Total  float64 `json:"total"`
+}
+
+type GroupHeatmap struct {
+	GroupID     string          `json:"group_id"`
+	Currency    string          `json:"currency"`
+	Cells       []HeatmapCell   `json:"cells"`
+	Members     []HeatmapMember `json:"members"`
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+// LeaderboardEntry is one member's part in a group's spending over a period.
+// Net is Paid minus Consumed; rank 1 fronted the most money.
+type LeaderboardEntry struct {
+	Rank      int     `json:"rank"`
+	UserID    string  `json:"user_id"`
+	Name      string  `json:"name"`
+	AvatarURL *string `json:"avatar_url,omitempty"`
+	Paid      float64 `json:"paid"`
+	Consumed  float64 `json:"consumed"`
+	Net       float64 `json:"net"`
+}
+
+type GroupLeaderboard struct {
+	GroupID     string             `json:"group_id"`
+	Currency    string             `json:"currency"`
+	Period      string             `json:"period"`
+	From        *time.Time         `json:"from,omitempty"`
+	Members     []LeaderboardEntry `json:"members"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	User         *User  `json:"user"`
+}
+
+type Tag struct {
+	ID        string    `json:"id" db:"id"`
+	GroupID   string    `json:"group_id" db:"group_id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type TagTotal struct {
+	TagID        string  `json:"tag_id"`
+	Name         string  `json:"name"`
+	Currency     string  `json:"currency"`
+	Total        float64 `json:"total"`
+	ExpenseCount int     `json:"expense_count"`
+}
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+type TransactionSortField string
+
+const (
+	TransactionSortDate   TransactionSortField = "date"
+	TransactionSortAmount TransactionSortField = "amount"
+	TransactionSortNet    TransactionSortField = "net"
+	TransactionSortPayer  TransactionSortField = "payer"
+)
+
+type TransactionSort struct {
+	Field TransactionSortField
+	Order SortOrder
+}
+
+type TransactionFilter struct {
+	Tags    []string
+	EventID string
+	Sort    TransactionSort
+	Limit   int
+	Offset  int
+	GroupBy TransactionGroupBy
+}
+
+type TransactionGroupBy string
+
+const (
+	TransactionGroupByMonth TransactionGroupBy = "month"
+	TransactionGroupByDay   TransactionGroupBy = "day"
+)
+
+// TransactionSubtotal covers one currency of a section. Refunds count
+// against spending; settlements are left out.
+type TransactionSubtotal struct {
+	Currency   string  `json:"currency"`
+	TotalSpent float64 `json:"total_spent"`
+	YourShare  float64 `json:"your_share"`
+}
+
+// TransactionSection is a header for a run of items on the current page.
+// Subtotals and TotalCount cover the whole section, including items on
+// other pages.
+type TransactionSection struct {
+	Key        string                `json:"key"`
+	Label      string                `json:"label"`
+	StartIndex int                   `json:"start_index"`
+	Count      int                   `json:"count"`
+	TotalCount int                   `json:"total_count"`
+	Subtotals  []TransactionSubtotal `json:"subtotals"`
+}
+
+type TransactionPage struct {
+	Items    []Transaction        `json:"items"`
+	Sections []TransactionSection `json:"sections"`
+}
+
+type ReceiptKind string
+
+const (
+	ReceiptKindReceipt         ReceiptKind = "RECEIPT"
+	ReceiptKindSettlementProof ReceiptKind = "SETTLEMENT_PROOF"
+)
+
+// ReceiptGalleryItem is one image attached to a group transaction, with enough
+// of the transaction to caption it.
+type ReceiptGalleryItem struct {
+	ExpenseID   string              `json:"expense_id"`
+	Kind        ReceiptKind         `json:"kind"`
+	ImagePath   string              `json:"-"`
+	ImageURL    *string             `json:"image_url"`
+	Description string              `json:"description"`
+	Category    TransactionCategory `json:"type"`
+	TotalAmount float64             `json:"total_amount"`
+	Currency    string              `json:"currency"`
+	Date        time.Time           `json:"date"`
+	EventID     *string             `json:"event_id,omitempty"`
+	PaidBy      *User               `json:"paid_by,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+type ReceiptGalleryPage struct {
+	Items  []ReceiptGalleryItem `json:"items"`
+	Total  int                  `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// CurrencySpend is what a group has recorded in one currency: the spend from
+// expenses net of refunds, and the number of transactions of any kind.
+type CurrencySpend struct {
+	Currency         string  `json:"currency"`
+	TotalSpend       float64 `json:"total_spend"`
+	TransactionCount int     `json:"transaction_count"`
+}
+
+type GroupCurrencyUsage struct {
+	Currency         string  `json:"currency"`
+	IsDefault        bool    `json:"is_default"`
+	TotalSpend       float64 `json:"total_spend"`
+	TransactionCount int     `json:"transaction_count"`
+	Outstanding      float64 `json:"outstanding"`
+}
+
+// GroupCurrencyOverview tells clients whether a group needs the multi-currency
+// UI: the currencies in use with spend and outstanding balance in each, and
+// the members whose debts span more than one currency.
+type GroupCurrencyOverview struct {
+	GroupID              string               `json:"group_id"`
+	DefaultCurrency      string               `json:"default_currency"`
+	Currencies           []GroupCurrencyUsage `json:"currencies"`
+	MultiCurrency        bool                 `json:"multi_currency"`
+	MixedCurrencyDebt    bool                 `json:"mixed_currency_debt"`
+	MembersWithMixedDebt []UserInfo           `json:"members_with_mixed_debt"`
+}
+
+type BackchargedExpense struct {
+	ExpenseID   string         `json:"expense_id"`
+	Description string         `json:"description"`
+	Currency    string         `json:"currency"`
+	MemberShare float64        `json:"member_share"`
+	Splits      []ExpenseSplit `json:"splits"`
+}
+
+type BackchargeResult struct {
+	GroupID  string               `json:"group_id"`
+	Member   UserInfo             `json:"member"`
+	Expenses []BackchargedExpense `json:"expenses"`
+}
+
+type MemberSortField string
+
+const (
+	MemberSortName    MemberSortField = "name"
+	MemberSortBalance MemberSortField = "balance"
+)
+
+type MemberSort struct {
+	Field MemberSortField
+	Order SortOrder
+}
+
+// GroupEvent is a named part of a TRIP group, such as "Scuba day", that
+// expenses can be attached to.
+type GroupEvent struct {
+	ID        string       `json:"id" db:"id"`
+	GroupID   string       `json:"group_id" db:"group_id"`
+	Name      string       `json:"name" db:"name"`
+	CreatedBy *string      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	Totals    []EventTotal `json:"totals"`
+}
+
+// EventTotal is an event's spend in one currency. Refunds count against it;
+// settlements are left out.
+type EventTotal struct {
+	EventID      string  `json:"-"`
+	Currency     string  `json:"currency"`
+	Total        float64 `json:"total"`
+	ExpenseCount int     `json:"expense_count"`
+}
+
+// EventMemberShare is what one member paid and owes for an event's expenses
+// in one currency. Net is positive when they are owed.
+type EventMemberShare struct {
+	UserID   string  `json:"user_id"`
+	Name     string  `json:"name"`
+	Currency string  `json:"currency"`
+	Paid     float64 `json:"paid"`
+	Share    float64 `json:"share"`
+	Net      float64 `json:"net"`
+}
+
+type GroupEventDetail struct {
+	GroupEvent
+	Shares []EventMemberShare `json:"shares"`
+}
+
+type GroupTagsResponse struct {
+	Tags   []Tag      `json:"tags"`
+	Totals []TagTotal `json:"totals"`
+}
+
+type ExpenseRead struct {
+	ExpenseID string    `json:"expense_id" db:"expense_id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	User      *User     `json:"user,omitempty"`
+	SeenAt    time.Time `json:"seen_at" db:"seen_at"`
+}
+
+// ExpenseVisibility is when a member was first notified of a transaction
+// and when they first saw it; either may be unknown.
+type ExpenseVisibility struct {
+	FirstNotifiedAt *time.Time
+	FirstSeenAt     *time.Time
+}
+
+type ReadState struct {
+	SeenAt *time.Time `json:"seen_at,omitempty"`
+	IsNew  bool       `json:"is_new"`
+}
+
+type MarkReadResponse struct {
+	Marked int `json:"marked"`
+}
+
+type SettlementHistoryEntry struct {
+	ID               string            `json:"id"`
+	Category         string            `json:"type"`
+	Date             time.Time         `json:"date"`
+	Description      string            `json:"description"`
+	Amount           float64           `json:"amount"`
+	Currency         string            `json:"currency"`
+	FromUser         *User             `json:"from_user"`
+	ToUser           *User             `json:"to_user"`
+	Method           *SettlementMethod `json:"method,omitempty"`
+	Reference        *string           `json:"reference,omitempty"`
+	ProofPath        *string           `json:"-"`
+	CreatedByID      *string           `json:"-"`
+	ProofURL         *string           `json:"proof_url,omitempty"`
+	PairBalanceAfter float64           `json:"pair_balance_after"`
+	Status           SettlementStatus  `json:"status"`
+	ReversesID       *string           `json:"reverses_id,omitempty"`
+	ReversedByID     *string           `json:"reversed_by_id,omitempty"`
+}
+
+type PlaceholderGroup struct {
+	GroupID   string           `json:"group_id"`
+	GroupName string           `json:"group_name"`
+	Balances  []CurrencyAmount `json:"balances"`
+}
+
+type ClaimablePlaceholder struct {
+	User
+	Groups []PlaceholderGroup `json:"groups"`
+}
+
+// DeletionBlockers lists what stops a user deleting their account. Positive
+// amounts are owed to the user, negative amounts are owed by them.
+type DeletionBlockers struct {
+	CanDelete bool                    `json:"can_delete"`
+	Groups    []DeletionBlockerGroup  `json:"groups"`
+	People    []DeletionBlockerPerson `json:"people"`
+}
+
+type DeletionBlockerGroup struct {
+	GroupID  string           `json:"group_id"`
+	Name     string           `json:"name"`
+	Balances []CurrencyAmount `json:"balances"`
+}
+
+type DeletionBlockerPerson struct {
+	User     UserInfo         `json:"user"`
+	Balances []CurrencyAmount `json:"balances"`
+}
+
+// QuotaUsage is a counter against a soft limit. A limit of 0 means unlimited.
+type QuotaUsage struct {
+	Used  int `json:"used"`
+	Limit int `json:"limit"`
+}
+
+type GroupQuotaUsage struct {
+	GroupID  string     `json:"group_id"`
+	Name     string     `json:"name"`
+	Members  QuotaUsage `json:"members"`
+	Expenses QuotaUsage `json:"expenses"`
+}
+
+// UserLimits reports a user's quota usage. Exempt users are never blocked,
+// but their usage is still counted.
+type UserLimits struct {
+	UserID     string            `json:"user_id"`
+	Exempt     bool              `json:"exempt"`
+	Groups     QuotaUsage        `json:"groups"`
+	GroupUsage []GroupQuotaUsage `json:"group_usage"`
+}
+
+// PlaceholderMatch is why placeholders were grouped into a merge suggestion.
+// A suggestion reports its weakest link.
+type PlaceholderMatch string
+
+const (
+	PlaceholderMatchEmail       PlaceholderMatch = "EMAIL"
+	PlaceholderMatchName        PlaceholderMatch = "NAME"
+	PlaceholderMatchSimilarName PlaceholderMatch = "SIMILAR_NAME"
+)
+
+type PlaceholderMergeSuggestion struct {
+	Placeholders []ClaimablePlaceholder `json:"placeholders"`
+	Target       User                   `json:"target"`
+	Match        PlaceholderMatch       `json:"match"`
+}
+
+type PlaceholderMergeResult struct {
+	Target        User                      `json:"target"`
+	Merged        []string                  `json:"merged_placeholder_ids"`
+	PendingClaims []PlaceholderClaimRequest `json:"pending_claims"`
+}
+
+type GroupInvite struct {
+	ID         string     `json:"id" db:"id"`
+	GroupID    string     `json:"group_id" db:"group_id"`
+	GroupName  string     `json:"group_name,omitempty" db:"-"`
+	Email      string     `json:"email" db:"email"`
+	InvitedBy  *string    `json:"invited_by,omitempty" db:"invited_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+}
+
+type BootstrapResponse struct {
+	User                  *User                     `json:"user"`
+	ClaimedPlaceholders   []User                    `json:"claimed_placeholders"`
+	PendingClaims         []PlaceholderClaimRequest `json:"pending_claims"`
+	Invitations           []GroupInvite             `json:"invitations"`
+	ClaimablePlaceholders []ClaimablePlaceholder    `json:"claimable_placeholders"`
+	SuggestSampleGroup    bool                      `json:"suggest_sample_group"`
+}
+
+type PlaceholderClaimStatus string
+
+const (
+	PlaceholderClaimPending  PlaceholderClaimStatus = "PENDING"
+	PlaceholderClaimApproved PlaceholderClaimStatus = "APPROVED"
+	PlaceholderClaimRejected PlaceholderClaimStatus = "REJECTED"
+)
+
+type PlaceholderClaimRequest struct {
+	ID            string                 `json:"id" db:"id"`
+	PlaceholderID string                 `json:"placeholder_id" db:"placeholder_id"`
+	UserID        string                 `json:"user_id" db:"user_id"`
+	Status        PlaceholderClaimStatus `json:"status" db:"status"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+	DecidedAt     *time.Time             `json:"decided_at,omitempty" db:"decided_at"`
+	DecidedBy     *string                `json:"decided_by,omitempty" db:"decided_by"`
+	Placeholder   *User                  `json:"placeholder,omitempty" db:"-"`
+	User          *User                  `json:"user,omitempty" db:"-"`
+}
+
+type BalanceEventType string
+
+const (
+	BalanceEventTransactionCreated BalanceEventType = "TRANSACTION_CREATED"
+	BalanceEventTransactionUpdated BalanceEventType = "TRANSACTION_UPDATED"
+	BalanceEventTransactionDeleted BalanceEventType = "TRANSACTION_DELETED"
+	BalanceEventPlaceholderClaimed BalanceEventType = "PLACEHOLDER_CLAIMED"
+	BalanceEventMemberConverted    BalanceEventType = "MEMBER_CONVERTED"
+	BalanceEventBackfill           BalanceEventType = "BACKFILL"
+)
+
+type BalanceMetricDrift struct {
+	UserID   string  `json:"user_id"`
+	GroupID  string  `json:"group_id"`
+	Currency string  `json:"currency"`
+	Stored   float64 `json:"stored"`
+	Actual   float64 `json:"actual"`
+}
+
+type BalanceEvent struct {
+	ID              string               `json:"id" db:"id"`
+	GroupID         string               `json:"group_id" db:"group_id"`
+	UserID          string               `json:"user_id" db:"user_id"`
+	Currency        string               `json:"currency" db:"currency"`
+	Delta           float64              `json:"delta" db:"delta"`
+	EventType       BalanceEventType     `json:"event_type" db:"event_type"`
+	ExpenseID       *string              `json:"expense_id,omitempty" db:"expense_id"`
+	ExpenseCategory *TransactionCategory `json:"expense_category,omitempty" db:"expense_category"`
+	Description     *string              `json:"description,omitempty" db:"description"`
+	RunningBalance  float64              `json:"running_balance" db:"-"`
+	CreatedAt       time.Time            `json:"created_at" db:"created_at"`
+}
+
+type BalanceAuditCurrency struct {
+	Currency        string  `json:"currency"`
+	LedgerBalance   float64 `json:"ledger_balance"`
+	ComputedBalance float64 `json:"computed_balance"`
+	Drift           float64 `json:"drift"`
+}
+
+type BalanceAudit struct {
+	GroupID    string                 `json:"group_id"`
+	UserID     string                 `json:"user_id"`
+	Events     []BalanceEvent         `json:"events"`
+	Currencies []BalanceAuditCurrency `json:"currencies"`
+	Consistent bool                   `json:"consistent"`
+}
+
+// AnnouncementKind tells clients how to present an announcement.
+type AnnouncementKind string
+
+const (
+	AnnouncementKindFeature     AnnouncementKind = "FEATURE"
+	AnnouncementKindMaintenance AnnouncementKind = "MAINTENANCE"
+	AnnouncementKindInfo        AnnouncementKind = "INFO"
+)
+
+func (k AnnouncementKind) IsValid() bool {
+	switch k {
+	case AnnouncementKindFeature, AnnouncementKindMaintenance, AnnouncementKindInfo:
+		return true
+	}
+	return false
+}
+
+// Announcement is an admin-written message shown to every user from StartsAt
+// until EndsAt (forever when nil). ReadAt is the requesting user's read time.
+type Announcement struct {
+	ID        string           `json:"id" db:"id"`
+	Kind      AnnouncementKind `json:"kind" db:"kind"`
+	Title     string           `json:"title" db:"title"`
+	Body      string           `json:"body" db:"body"`
+	LinkURL   *string          `json:"link_url,omitempty" db:"link_url"`
+	StartsAt  time.Time        `json:"starts_at" db:"starts_at"`
+	EndsAt    *time.Time       `json:"ends_at,omitempty" db:"ends_at"`
+	CreatedBy *string          `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	ReadAt    *time.Time       `json:"read_at,omitempty" db:"read_at"`
+}
+
+type AnnouncementFeed struct {
+	Announcements []Announcement `json:"announcements"`
+	UnreadCount   int            `json:"unread_count"`
+}
+
+type FixtureScenario struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type FixtureUser struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type FixtureLoadResult struct {
+	Scenario string        `json:"scenario"`
+	Password string        `json:"password"`
+	Users    []FixtureUser `json:"users"`
+	Groups   []Group       `json:"groups"`
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type ActivityRepository interface {
+	Create(ctx context.Context, activity *models.GroupActivity) error
+	GetByGroupID(ctx context.Context, groupID string, limit int) ([]models.GroupActivity, error)
+	WithTx(tx database.Querier) ActivityRepository
+}
+
+type activityRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewActivityRepository(db *database.DB) ActivityRepository {
+	return &activityRepository{db: db}
+}
+
+func (r *activityRepository) WithTx(tx database.Querier) ActivityRepository {
+	return &activityRepository{db: r.db, tx: tx}
+}
+
+func (r *activityRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *activityRepository) Create(ctx context.Context, a *models.GroupActivity) error {
+	query := `
+		INSERT INTO group_activity (id, group_id, actor_id, expense_id, action, message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query, a.ID, a.GroupID, a.ActorID, a.ExpenseID, a.Action, a.Message).Scan(&a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating group activity: %w", err)
+	}
+	return nil
+}
+
+func (r *activityRepository) GetByGroupID(ctx context.Context, groupID string, limit int) ([]models.GroupActivity, error) {
+	query := `
+		SELECT a.id, a.group_id, a.actor_id, a.expense_id, a.action, a.message, a.created_at,
+		       u.name, u.avatar_url
+		FROM group_activity a
+		LEFT JOIN users u ON u.id = a.actor_id
+		WHERE a.group_id = $1
+		ORDER BY a.created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying group activity: %w", err)
+	}
+	defer rows.Close()
+
+	activities := []models.GroupActivity{}
+	for rows.Next() {
+		var a models.GroupActivity
+		var actorName *string
+		var actorAvatar *string
+		if err := rows.Scan(&a.ID, &a.GroupID, &a.ActorID, &a.ExpenseID, &a.Action, &a.Message, &a.CreatedAt,
+			&actorName, &actorAvatar); err != nil {
+			return nil, fmt.Errorf("scanning group activity: %w", err)
+		}
+		if a.ActorID != nil && actorName != nil {
+			a.Actor = &models.User{ID: *a.ActorID, Name: *actorName, AvatarURL: actorAvatar}
+		}
+		activities = append(activities, a)
+	}
+	return activities, rows.Err()
+}
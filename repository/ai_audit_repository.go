@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type AIAuditRepository interface {
+	CreateOutput(ctx context.Context, output *models.AIOutput) error
+	GetOutputByID(ctx context.Context, outputID string) (*models.AIOutput, error)
+	GetLatestOutputForExpense(ctx context.Context, expenseID string, kind models.AIOutputKind) (*models.AIOutput, error)
+	UpsertFeedback(ctx context.Context, feedback *models.AIFeedback) error
+	GetStats(ctx context.Context) ([]models.AIOutputStats, error)
+	WithTx(tx database.Querier) AIAuditRepository
+}
+
+type aiAuditRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewAIAuditRepository(db *database.DB) AIAuditRepository {
+	return &aiAuditRepository{db: db}
+}
+
+func (r *aiAuditRepository) WithTx(tx database.Querier) AIAuditRepository {
+	return &aiAuditRepository{db: r.db, tx: tx}
+}
+
+func (r *aiAuditRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+const aiOutputColumns = `id, kind, user_id, group_id, expense_id, model, prompt, response, created_at`
+
+func scanAIOutput(row interface{ Scan(dest ...any) error }, o *models.AIOutput) error {
+	return row.Scan(&o.ID, &o.Kind, &o.UserID, &o.GroupID, &o.ExpenseID, &o.Model, &o.Prompt, &o.Response, &o.CreatedAt)
+}
+
+func (r *aiAuditRepository) CreateOutput(ctx context.Context, o *models.AIOutput) error {
+	query := `
+		INSERT INTO ai_outputs (id, kind, user_id, group_id, expense_id, model, prompt, response, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query, o.ID, o.Kind, o.UserID, o.GroupID, o.ExpenseID, o.Model, o.Prompt, o.Response).
+		Scan(&o.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating ai output: %w", err)
+	}
+	return nil
+}
+
+func (r *aiAuditRepository) GetOutputByID(ctx context.Context, outputID string) (*models.AIOutput, error) {
+	query := `SELECT ` + aiOutputColumns + ` FROM ai_outputs WHERE id = $1`
+	var o models.AIOutput
+	if err := scanAIOutput(r.getQuerier().QueryRow(ctx, query, outputID), &o); err != nil {
+		return nil, fmt.Errorf("getting ai output: %w", err)
+	}
+	return &o, nil
+}
+
+func (r *aiAuditRepository) GetLatestOutputForExpense(ctx context.Context, expenseID string, kind models.AIOutputKind) (*models.AIOutput, error) {
+	query := `SELECT ` + aiOutputColumns + ` FROM ai_outputs
+		WHERE expense_id = $1 AND kind = $2
+		ORDER BY created_at DESC
+		LIMIT 1`
+	var o models.AIOutput
+	if err := scanAIOutput(r.getQuerier().QueryRow(ctx, query, expenseID, kind), &o); err != nil {
+		return nil, fmt.Errorf("getting latest ai output: %w", err)
+	}
+	return &o, nil
+}
+
+func (r *aiAuditRepository) UpsertFeedback(ctx context.Context, f *models.AIFeedback) error {
+	query := `
+		INSERT INTO ai_feedback (id, output_id, user_id, rating, comment, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (output_id, user_id) DO UPDATE SET
+			rating = EXCLUDED.rating,
+			comment = EXCLUDED.comment,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query, f.ID, f.OutputID, f.UserID, f.Rating, f.Comment).
+		Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upserting ai feedback: %w", err)
+	}
+	return nil
+}
+
+func (r *aiAuditRepository) GetStats(ctx context.Context) ([]models.AIOutputStats, error) {
+	query := `
+		SELECT o.kind,
+			COUNT(DISTINCT o.id),
+			COUNT(DISTINCT f.output_id),
+			COUNT(f.id) FILTER (WHERE f.rating = 'UP'),
+			COUNT(f.id) FILTER (WHERE f.rating = 'DOWN')
+		FROM ai_outputs o
+		LEFT JOIN ai_feedback f ON f.output_id = o.id
+		GROUP BY o.kind
+		ORDER BY o.kind
+	`
+	rows, err := r.getQuerier().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("querying ai output stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.AIOutputStats{}
+	for rows.Next() {
+		var st models.AIOutputStats
+		if err := rows.Scan(&st.Kind, &st.Outputs, &st.Rated, &st.ThumbsUp, &st.ThumbsDown); err != nil {
+			return nil, fmt.Errorf("scanning ai output stats: %w", err)
+		}
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}
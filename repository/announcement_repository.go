@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type AnnouncementRepository interface {
+	Create(ctx context.Context, announcement *models.Announcement) error
+	GetAll(ctx context.Context) ([]models.Announcement, error)
+	GetActiveForUser(ctx context.Context, userID string) ([]models.Announcement, error)
+	Delete(ctx context.Context, id string) (bool, error)
+	MarkRead(ctx context.Context, userID, announcementID string) (bool, error)
+	MarkAllRead(ctx context.Context, userID string) error
+	WithTx(tx database.Querier) AnnouncementRepository
+}
+
+type announcementRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewAnnouncementRepository(db *database.DB) AnnouncementRepository {
+	return &announcementRepository{db: db}
+}
+
+func (r *announcementRepository) WithTx(tx database.Querier) AnnouncementRepository {
+	return &announcementRepository{db: r.db, tx: tx}
+}
+
+func (r *announcementRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+// activeAnnouncement matches announcements that have started and not ended.
+const activeAnnouncement = `a.starts_at <= NOW() AND (a.ends_at IS NULL OR a.ends_at > NOW())`
+
+func (r *announcementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	query := `
+		INSERT INTO announcements (id, kind, title, body, link_url, starts_at, ends_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query,
+		announcement.ID, announcement.Kind, announcement.Title, announcement.Body, announcement.LinkURL,
+		announcement.StartsAt, announcement.EndsAt, announcement.CreatedBy,
+	).Scan(&announcement.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating announcement: %w", err)
+	}
+	return nil
+}
+
+func (r *announcementRepository) GetAll(ctx context.Context) ([]models.Announcement, error) {
+	query := `SELECT a.id, a.kind, a.title, a.body, a.link_url, a.starts_at, a.ends_at, a.created_by, a.created_at, NULL::TIMESTAMPTZ
+	          FROM announcements a
+	          ORDER BY a.starts_at DESC, a.created_at DESC`
+	return r.query(ctx, query)
+}
+
+// GetActiveForUser returns the announcements showing now, newest first, each
+// with when userID read it.
+func (r *announcementRepository) GetActiveForUser(ctx context.Context, userID string) ([]models.Announcement, error) {
+	query := `SELECT a.id, a.kind, a.title, a.body, a.link_url, a.starts_at, a.ends_at, a.created_by, a.created_at, ar.read_at
+	          FROM announcements a
+	          LEFT JOIN announcement_reads ar ON ar.announcement_id = a.id AND ar.user_id = $1
+	          WHERE ` + activeAnnouncement + `
+	          ORDER BY a.starts_at DESC, a.created_at DESC`
+	return r.query(ctx, query, userID)
+}
+
+func (r *announcementRepository) query(ctx context.Context, query string, args ...any) ([]models.Announcement, error) {
+	rows, err := r.getQuerier().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("getting announcements: %w", err)
+	}
+	defer rows.Close()
+
+	announcements := []models.Announcement{}
+	for rows.Next() {
+		var a models.Announcement
+		if err := rows.Scan(&a.ID, &a.Kind, &a.Title, &a.Body, &a.LinkURL, &a.StartsAt, &a.EndsAt, &a.CreatedBy, &a.CreatedAt, &a.ReadAt); err != nil {
+			return nil, fmt.Errorf("scanning announcement: %w", err)
+		}
+		announcements = append(announcements, a)
+	}
+	return announcements, rows.Err()
+}
+
+func (r *announcementRepository) Delete(ctx context.Context, id string) (bool, error) {
+	tag, err := r.getQuerier().Exec(ctx, `DELETE FROM announcements WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("deleting announcement: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// MarkRead records that userID read an active announcement. It reports false
+// when the announcement doesn't exist or isn't showing; reading one twice
+// keeps the first read time.
+func (r *announcementRepository) MarkRead(ctx context.Context, userID, announcementID string) (bool, error) {
+	query := `
+		WITH target AS (
+			SELECT a.id FROM announcements a WHERE a.id = $2 AND ` + activeAnnouncement + `
+		), inserted AS (
+			INSERT INTO announcement_reads (user_id, announcement_id, read_at)
+			SELECT $1, id, NOW() FROM target
+			ON CONFLICT (user_id, announcement_id) DO NOTHING
+		)
+		SELECT EXISTS (SELECT 1 FROM target)
+	`
+	var found bool
+	if err := r.getQuerier().QueryRow(ctx, query, userID, announcementID).Scan(&found); err != nil {
+		return false, fmt.Errorf("marking announcement read: %w", err)
+	}
+	return found, nil
+}
+
+func (r *announcementRepository) MarkAllRead(ctx context.Context, userID string) error {
+	query := `
+		INSERT INTO announcement_reads (user_id, announcement_id, read_at)
+		SELECT $1, a.id, NOW() FROM announcements a WHERE ` + activeAnnouncement + `
+		ON CONFLICT (user_id, announcement_id) DO NOTHING
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("marking announcements read: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type BalanceAlertRepository interface {
+	GetSubscribers(ctx context.Context, groupID string) ([]models.BalanceAlertSubscriber, error)
+	MarkAlerted(ctx context.Context, groupID, userID string, amountOwed float64) (bool, error)
+	Clear(ctx context.Context, groupID, userID string) error
+	WithTx(tx database.Querier) BalanceAlertRepository
+}
+
+type balanceAlertRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewBalanceAlertRepository(db *database.DB) BalanceAlertRepository {
+	return &balanceAlertRepository{db: db}
+}
+
+func (r *balanceAlertRepository) WithTx(tx database.Querier) BalanceAlertRepository {
+	return &balanceAlertRepository{db: r.db, tx: tx}
+}
+
+func (r *balanceAlertRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+// GetSubscribers returns the group's members who set a balance alert.
+func (r *balanceAlertRepository) GetSubscribers(ctx context.Context, groupID string) ([]models.BalanceAlertSubscriber, error) {
+	query := `
+		SELECT u.id, u.balance_alert_threshold, u.balance_alert_currency, ba.user_id IS NOT NULL
+		FROM group_members gm
+		INNER JOIN users u ON u.id = gm.user_id
+		LEFT JOIN balance_alerts ba ON ba.user_id = gm.user_id AND ba.group_id = gm.group_id
+		WHERE gm.group_id = $1
+			AND u.balance_alert_threshold IS NOT NULL
+			AND u.deleted_at IS NULL
+			AND NOT u.is_placeholder
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("getting balance alert subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	var subscribers []models.BalanceAlertSubscriber
+	for rows.Next() {
+		var s models.BalanceAlertSubscriber
+		if err := rows.Scan(&s.UserID, &s.Threshold, &s.Currency, &s.Alerted); err != nil {
+			return nil, fmt.Errorf("scanning balance alert subscriber: %w", err)
+		}
+		subscribers = append(subscribers, s)
+	}
+	return subscribers, rows.Err()
+}
+
+// MarkAlerted records that the user was alerted for the group. It reports
+// false when they already were, so concurrent checks alert only once.
+func (r *balanceAlertRepository) MarkAlerted(ctx context.Context, groupID, userID string, amountOwed float64) (bool, error) {
+	query := `
+		INSERT INTO balance_alerts (user_id, group_id, amount_owed, alerted_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, group_id) DO NOTHING
+	`
+	tag, err := r.getQuerier().Exec(ctx, query, userID, groupID, amountOwed)
+	if err != nil {
+		return false, fmt.Errorf("marking balance alert: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *balanceAlertRepository) Clear(ctx context.Context, groupID, userID string) error {
+	query := `DELETE FROM balance_alerts WHERE user_id = $1 AND group_id = $2`
+	if _, err := r.getQuerier().Exec(ctx, query, userID, groupID); err != nil {
+		return fmt.Errorf("clearing balance alert: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+
+	"github.com/google/uuid"
+)
+
+type BalanceEventRepository interface {
+	GetExpenseContributions(ctx context.Context, expenseID string) ([]models.BalanceEvent, error)
+	Append(ctx context.Context, events []models.BalanceEvent) error
+	GetByMember(ctx context.Context, groupID, userID string) ([]models.BalanceEvent, error)
+	GetGroupTotals(ctx context.Context, groupID string) (map[string]map[string]float64, error)
+	TransferUser(ctx context.Context, fromUserID, toUserID string) error
+	TransferGroupUser(ctx context.Context, groupID, fromUserID, toUserID string) error
+	WithTx(tx database.Querier) BalanceEventRepository
+}
+
+type balanceEventRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewBalanceEventRepository(db *database.DB) BalanceEventRepository {
+	return &balanceEventRepository{db: db}
+}
+
+func (r *balanceEventRepository) WithTx(tx database.Querier) BalanceEventRepository {
+	return &balanceEventRepository{db: r.db, tx: tx}
+}
+
+func (r *balanceEventRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+// GetExpenseContributions returns what a transaction currently adds to each
+// member's balance (paid minus owed) as unsaved events.
+func (r *balanceEventRepository) GetExpenseContributions(ctx context.Context, expenseID string) ([]models.BalanceEvent, error) {
+	query := `
+		SELECT e.group_id, c.user_id, e.currency, SUM(c.amount), e.category, e.description
+		FROM expenses e
+		JOIN (
+			SELECT expense_id, user_id, amount_paid AS amount FROM expense_payers WHERE expense_id = $1
+			UNION ALL
+			SELECT expense_id, user_id, -amount FROM expense_splits WHERE expense_id = $1
+		) c ON c.expense_id = e.id
+		WHERE e.id = $1
+		GROUP BY e.group_id, c.user_id, e.currency, e.category, e.description
+	`
+	rows, err := r.getQuerier().Query(ctx, query, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("querying expense contributions: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.BalanceEvent{}
+	for rows.Next() {
+		event := models.BalanceEvent{ExpenseID: &expenseID}
+		var category models.TransactionCategory
+		var description string
+		if err := rows.Scan(&event.GroupID, &event.UserID, &event.Currency, &event.Delta, &category, &description); err != nil {
+			return nil, fmt.Errorf("scanning expense contribution: %w", err)
+		}
+		event.ExpenseCategory = &category
+		event.Description = &description
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *balanceEventRepository) Append(ctx context.Context, events []models.BalanceEvent) error {
+	query := `
+		INSERT INTO balance_events (id, group_id, user_id, currency, delta, event_type, expense_id, expense_category, description, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	`
+	for _, e := range events {
+		if _, err := r.getQuerier().Exec(ctx, query,
+			uuid.New().String(), e.GroupID, e.UserID, e.Currency, e.Delta, e.EventType, e.ExpenseID, e.ExpenseCategory, e.Description,
+		); err != nil {
+			return fmt.Errorf("appending balance event: %w", err)
+		}
+		if _, err := r.getQuerier().Exec(ctx, applyBalanceMetricQuery, e.UserID, e.GroupID, e.Currency, e.Delta); err != nil {
+			return fmt.Errorf("updating balance metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// Every ledger append goes with one of these, in the same transaction, so the
+// pre-aggregated balances never fall behind.
+const applyBalanceMetricQuery = `
+	INSERT INTO user_balance_metrics (user_id, group_id, currency, net, updated_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (user_id, group_id, currency)
+	DO UPDATE SET net = user_balance_metrics.net + EXCLUDED.net, updated_at = NOW()
+`
+
+func (r *balanceEventRepository) GetByMember(ctx context.Context, groupID, userID string) ([]models.BalanceEvent, error) {
+	query := `
+		SELECT id, group_id, user_id, currency, delta, event_type, expense_id, expense_category, description, created_at
+		FROM balance_events
+		WHERE group_id = $1 AND user_id = $2
+		ORDER BY seq ASC
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying balance events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.BalanceEvent{}
+	for rows.Next() {
+		var e models.BalanceEvent
+		if err := rows.Scan(
+			&e.ID, &e.GroupID, &e.UserID, &e.Currency, &e.Delta, &e.EventType, &e.ExpenseID, &e.ExpenseCategory, &e.Description, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning balance event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *balanceEventRepository) GetGroupTotals(ctx context.Context, groupID string) (map[string]map[string]float64, error) {
+	query := `
+		SELECT user_id, currency, SUM(delta)
+		FROM balance_events
+		WHERE group_id = $1
+		GROUP BY user_id, currency
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying balance event totals: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]map[string]float64)
+	for rows.Next() {
+		var userID, currency string
+		var total float64
+		if err := rows.Scan(&userID, &currency, &total); err != nil {
+			return nil, fmt.Errorf("scanning balance event total: %w", err)
+		}
+		if result[userID] == nil {
+			result[userID] = make(map[string]float64)
+		}
+		result[userID][currency] = total
+	}
+	return result, rows.Err()
+}
+
+// TransferUser moves fromUserID's ledger balance in every group to toUserID,
+// mirroring ExpenseRepository.TransferExpenses for placeholder claims.
+func (r *balanceEventRepository) TransferUser(ctx context.Context, fromUserID, toUserID string) error {
+	query := `
+		WITH moved AS (
+			INSERT INTO balance_events (id, group_id, user_id, currency, delta, event_type, created_at)
+			SELECT gen_random_uuid()::TEXT, t.group_id, m.user_id, t.currency, m.sign * t.total, $3, NOW()
+			FROM (
+				SELECT group_id, currency, SUM(delta) AS total
+				FROM balance_events
+				WHERE user_id = $1
+				GROUP BY group_id, currency
+				HAVING SUM(delta) <> 0
+			) t
+			CROSS JOIN (VALUES ($1::VARCHAR, -1), ($2::VARCHAR, 1)) AS m(user_id, sign)
+			ORDER BY t.group_id, t.currency, m.sign
+			RETURNING user_id, group_id, currency, delta
+		)
+		` + applyMovedBalanceMetricsQuery
+	if _, err := r.getQuerier().Exec(ctx, query, fromUserID, toUserID, models.BalanceEventPlaceholderClaimed); err != nil {
+		return fmt.Errorf("transferring balance events: %w", err)
+	}
+	return nil
+}
+
+// TransferGroupUser moves fromUserID's ledger balance in one group to
+// toUserID, mirroring ExpenseRepository.TransferGroupExpenses.
+func (r *balanceEventRepository) TransferGroupUser(ctx context.Context, groupID, fromUserID, toUserID string) error {
+	query := `
+		WITH moved AS (
+			INSERT INTO balance_events (id, group_id, user_id, currency, delta, event_type, created_at)
+			SELECT gen_random_uuid()::TEXT, t.group_id, m.user_id, t.currency, m.sign * t.total, $3, NOW()
+			FROM (
+				SELECT group_id, currency, SUM(delta) AS total
+				FROM balance_events
+				WHERE user_id = $1 AND group_id = $4
+				GROUP BY group_id, currency
+				HAVING SUM(delta) <> 0
+			) t
+			CROSS JOIN (VALUES ($1::VARCHAR, -1), ($2::VARCHAR, 1)) AS m(user_id, sign)
+			ORDER BY t.currency, m.sign
+			RETURNING user_id, group_id, currency, delta
+		)
+		` + applyMovedBalanceMetricsQuery
+	if _, err := r.getQuerier().Exec(ctx, query, fromUserID, toUserID, models.BalanceEventMemberConverted, groupID); err != nil {
+		return fmt.Errorf("transferring group balance events: %w", err)
+	}
+	return nil
+}
+
+const applyMovedBalanceMetricsQuery = `
+	INSERT INTO user_balance_metrics (user_id, group_id, currency, net, updated_at)
+	SELECT user_id, group_id, currency, SUM(delta), NOW()
+	FROM moved
+	GROUP BY user_id, group_id, currency
+	ON CONFLICT (user_id, group_id, currency)
+	DO UPDATE SET net = user_balance_metrics.net + EXCLUDED.net, updated_at = NOW()
+`
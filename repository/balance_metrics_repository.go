@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+const balanceMetricsVerifyLock = 7310452
+
+type BalanceMetricsRepository interface {
+	TryVerifyLock(ctx context.Context) (bool, error)
+	FindDrift(ctx context.Context) ([]models.BalanceMetricDrift, error)
+	Adjust(ctx context.Context, userID, groupID, currency string, delta float64) error
+	WithTx(tx database.Querier) BalanceMetricsRepository
+}
+
+type balanceMetricsRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewBalanceMetricsRepository(db *database.DB) BalanceMetricsRepository {
+	return &balanceMetricsRepository{db: db}
+}
+
+func (r *balanceMetricsRepository) WithTx(tx database.Querier) BalanceMetricsRepository {
+	return &balanceMetricsRepository{db: r.db, tx: tx}
+}
+
+func (r *balanceMetricsRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+// The lock is transaction-scoped, so this must run inside WithTx.
+func (r *balanceMetricsRepository) TryVerifyLock(ctx context.Context) (bool, error) {
+	var locked bool
+	if err := r.getQuerier().QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, balanceMetricsVerifyLock).Scan(&locked); err != nil {
+		return false, fmt.Errorf("taking balance metrics lock: %w", err)
+	}
+	return locked, nil
+}
+
+// Balances that have no metric row at all count as drift too.
+func (r *balanceMetricsRepository) FindDrift(ctx context.Context) ([]models.BalanceMetricDrift, error) {
+	query := `
+		WITH actual AS (
+			SELECT c.user_id, e.group_id, e.currency, SUM(c.amount) AS net
+			FROM expenses e
+			JOIN (
+				SELECT expense_id, user_id, amount_paid AS amount FROM expense_payers
+				UNION ALL
+				SELECT expense_id, user_id, -amount FROM expense_splits
+			) c ON c.expense_id = e.id
+			GROUP BY c.user_id, e.group_id, e.currency
+		)
+		SELECT COALESCE(a.user_id, m.user_id), COALESCE(a.group_id, m.group_id), COALESCE(a.currency, m.currency),
+			COALESCE(m.net, 0), COALESCE(a.net, 0)
+		FROM actual a
+		FULL OUTER JOIN user_balance_metrics m
+			ON m.user_id = a.user_id AND m.group_id = a.group_id AND m.currency = a.currency
+		WHERE ABS(COALESCE(m.net, 0) - COALESCE(a.net, 0)) > 0.01
+		ORDER BY 2, 1, 3
+	`
+	rows, err := r.getQuerier().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("finding balance metric drift: %w", err)
+	}
+	defer rows.Close()
+
+	var drift []models.BalanceMetricDrift
+	for rows.Next() {
+		var d models.BalanceMetricDrift
+		if err := rows.Scan(&d.UserID, &d.GroupID, &d.Currency, &d.Stored, &d.Actual); err != nil {
+			return nil, fmt.Errorf("scanning balance metric drift: %w", err)
+		}
+		drift = append(drift, d)
+	}
+	return drift, rows.Err()
+}
+
+// Adjusting by delta rather than overwriting keeps a write that lands
+// between FindDrift and the repair.
+func (r *balanceMetricsRepository) Adjust(ctx context.Context, userID, groupID, currency string, delta float64) error {
+	query := `
+		INSERT INTO user_balance_metrics (user_id, group_id, currency, net, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_id, group_id, currency)
+		DO UPDATE SET net = user_balance_metrics.net + EXCLUDED.net, updated_at = NOW()
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, userID, groupID, currency, delta); err != nil {
+		return fmt.Errorf("adjusting balance metric: %w", err)
+	}
+	return nil
+}
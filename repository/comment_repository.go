@@ -15,16 +15,29 @@ type CommentRepository interface {
 	AddReaction(ctx context.Context, reaction *models.CommentReaction) error
 	RemoveReaction(ctx context.Context, commentID, userID, emoji string) error
 	GetCommentByID(ctx context.Context, commentID string) (*models.Comment, error)
+	WithTx(tx database.Querier) CommentRepository
 }
 
 type commentRepository struct {
 	db *database.DB
+	tx database.Querier
 }
 
 func NewCommentRepository(db *database.DB) CommentRepository {
 	return &commentRepository{db: db}
 }
 
+func (r *commentRepository) WithTx(tx database.Querier) CommentRepository {
+	return &commentRepository{db: r.db, tx: tx}
+}
+
+func (r *commentRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
 func (r *commentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
 	query := `
 		INSERT INTO comments (id, expense_id, user_id, text, created_at)
@@ -37,7 +50,7 @@ func (r *commentRepository) CreateComment(ctx context.Context, comment *models.C
 		RETURNING id
 	`
 	var insertedID string
-	err := r.db.Pool.QueryRow(ctx, query, comment.ID, comment.ExpenseID, comment.UserID, comment.Text).Scan(&insertedID)
+	err := r.getQuerier().QueryRow(ctx, query, comment.ID, comment.ExpenseID, comment.UserID, comment.Text).Scan(&insertedID)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
 			return fmt.Errorf("user not authorized or expense not found")
@@ -50,7 +63,7 @@ func (r *commentRepository) CreateComment(ctx context.Context, comment *models.C
 func (r *commentRepository) GetCommentByID(ctx context.Context, commentID string) (*models.Comment, error) {
 	query := `SELECT id, expense_id, user_id, text, created_at FROM comments WHERE id = $1`
 	var c models.Comment
-	err := r.db.Pool.QueryRow(ctx, query, commentID).Scan(&c.ID, &c.ExpenseID, &c.UserID, &c.Text, &c.CreatedAt)
+	err := r.getQuerier().QueryRow(ctx, query, commentID).Scan(&c.ID, &c.ExpenseID, &c.UserID, &c.Text, &c.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("getting comment: %w", err)
 	}
@@ -66,7 +79,7 @@ func (r *commentRepository) GetCommentsByExpenseID(ctx context.Context, expenseI
 		WHERE c.expense_id = $1
 		ORDER BY c.created_at ASC
 	`
-	rows, err := r.db.Pool.Query(ctx, query, expenseID)
+	rows, err := r.getQuerier().Query(ctx, query, expenseID)
 	if err != nil {
 		return nil, fmt.Errorf("querying comments: %w", err)
 	}
@@ -109,7 +122,7 @@ func (r *commentRepository) GetCommentsByExpenseID(ctx context.Context, expenseI
 		WHERE cr.comment_id = ANY($1)
 		ORDER BY cr.created_at ASC
 	`
-	rRows, err := r.db.Pool.Query(ctx, reactionQuery, commentIDs)
+	rRows, err := r.getQuerier().Query(ctx, reactionQuery, commentIDs)
 	if err != nil {
 		return nil, fmt.Errorf("querying reactions: %w", err)
 	}
@@ -135,7 +148,7 @@ func (r *commentRepository) GetCommentsByExpenseID(ctx context.Context, expenseI
 
 func (r *commentRepository) DeleteComment(ctx context.Context, commentID string) error {
 	query := `DELETE FROM comments WHERE id = $1`
-	_, err := r.db.Pool.Exec(ctx, query, commentID)
+	_, err := r.getQuerier().Exec(ctx, query, commentID)
 	if err != nil {
 		return fmt.Errorf("deleting comment: %w", err)
 	}
@@ -145,7 +158,7 @@ func (r *commentRepository) DeleteComment(ctx context.Context, commentID string)
 func (r *commentRepository) AddReaction(ctx context.Context, reaction *models.CommentReaction) error {
 	query := `INSERT INTO comment_reactions (id, comment_id, user_id, emoji, created_at)
 	          VALUES ($1, $2, $3, $4, NOW())`
-	_, err := r.db.Pool.Exec(ctx, query, reaction.ID, reaction.CommentID, reaction.UserID, reaction.Emoji)
+	_, err := r.getQuerier().Exec(ctx, query, reaction.ID, reaction.CommentID, reaction.UserID, reaction.Emoji)
 	if err != nil {
 		return fmt.Errorf("adding reaction: %w", err)
 	}
@@ -154,7 +167,7 @@ func (r *commentRepository) AddReaction(ctx context.Context, reaction *models.Co
 
 func (r *commentRepository) RemoveReaction(ctx context.Context, commentID, userID, emoji string) error {
 	query := `DELETE FROM comment_reactions WHERE comment_id = $1 AND user_id = $2 AND emoji = $3`
-	_, err := r.db.Pool.Exec(ctx, query, commentID, userID, emoji)
+	_, err := r.getQuerier().Exec(ctx, query, commentID, userID, emoji)
 	if err != nil {
 		return fmt.Errorf("removing reaction: %w", err)
 	}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+)
+
+type CredentialRepository interface {
+	GetPasswordHash(ctx context.Context, userID string) (string, error)
+	SetPasswordHash(ctx context.Context, userID, passwordHash string) error
+	WithTx(tx database.Querier) CredentialRepository
+}
+
+type credentialRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewCredentialRepository(db *database.DB) CredentialRepository {
+	return &credentialRepository{db: db}
+}
+
+func (r *credentialRepository) WithTx(tx database.Querier) CredentialRepository {
+	return &credentialRepository{db: r.db, tx: tx}
+}
+
+func (r *credentialRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *credentialRepository) GetPasswordHash(ctx context.Context, userID string) (string, error) {
+	query := `SELECT password_hash FROM user_credentials WHERE user_id = $1`
+	var hash string
+	if err := r.getQuerier().QueryRow(ctx, query, userID).Scan(&hash); err != nil {
+		return "", fmt.Errorf("getting password hash: %w", err)
+	}
+	return hash, nil
+}
+
+func (r *credentialRepository) SetPasswordHash(ctx context.Context, userID, passwordHash string) error {
+	query := `
+		INSERT INTO user_credentials (user_id, password_hash, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET password_hash = EXCLUDED.password_hash, updated_at = NOW()
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, userID, passwordHash); err != nil {
+		return fmt.Errorf("setting password hash: %w", err)
+	}
+	return nil
+}
@@ -11,20 +11,33 @@ import (
 type CurrencyRepository interface {
 	GetAll(ctx context.Context) ([]models.Currency, error)
 	GetByCode(ctx context.Context, code string) (*models.Currency, error)
+	WithTx(tx database.Querier) CurrencyRepository
 }
 
 type currencyRepository struct {
 	db *database.DB
+	tx database.Querier
 }
 
 func NewCurrencyRepository(db *database.DB) CurrencyRepository {
 	return &currencyRepository{db: db}
 }
 
+func (r *currencyRepository) WithTx(tx database.Querier) CurrencyRepository {
+	return &currencyRepository{db: r.db, tx: tx}
+}
+
+func (r *currencyRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
 func (r *currencyRepository) GetAll(ctx context.Context) ([]models.Currency, error) {
 	query := `SELECT code, name, symbol FROM currencies ORDER BY code`
 
-	rows, err := r.db.Pool.Query(ctx, query)
+	rows, err := r.getQuerier().Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("getting all currencies: %w", err)
 	}
@@ -46,7 +59,7 @@ func (r *currencyRepository) GetByCode(ctx context.Context, code string) (*model
 	query := `SELECT code, name, symbol FROM currencies WHERE code = $1`
 
 	var c models.Currency
-	err := r.db.Pool.QueryRow(ctx, query, code).Scan(&c.Code, &c.Name, &c.Symbol)
+	err := r.getQuerier().QueryRow(ctx, query, code).Scan(&c.Code, &c.Name, &c.Symbol)
 	if err != nil {
 		return nil, fmt.Errorf("getting currency by code: %w", err)
 	}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type EventRepository interface {
+	Create(ctx context.Context, event *models.GroupEvent) error
+	GetByID(ctx context.Context, eventID string) (*models.GroupEvent, error)
+	GetByGroupID(ctx context.Context, groupID string) ([]models.GroupEvent, error)
+	GetTotalsByGroupID(ctx context.Context, groupID string) ([]models.EventTotal, error)
+	GetMemberShares(ctx context.Context, eventID string) ([]models.EventMemberShare, error)
+	Delete(ctx context.Context, eventID string) error
+	WithTx(tx database.Querier) EventRepository
+}
+
+type eventRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewEventRepository(db *database.DB) EventRepository {
+	return &eventRepository{db: db}
+}
+
+func (r *eventRepository) WithTx(tx database.Querier) EventRepository {
+	return &eventRepository{db: r.db, tx: tx}
+}
+
+func (r *eventRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *eventRepository) Create(ctx context.Context, event *models.GroupEvent) error {
+	query := `
+		INSERT INTO group_events (id, group_id, name, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING created_at
+	`
+	if err := r.getQuerier().QueryRow(ctx, query, event.ID, event.GroupID, event.Name, event.CreatedBy).Scan(&event.CreatedAt); err != nil {
+		return fmt.Errorf("creating event: %w", err)
+	}
+	return nil
+}
+
+func (r *eventRepository) GetByID(ctx context.Context, eventID string) (*models.GroupEvent, error) {
+	query := `SELECT id, group_id, name, created_by, created_at FROM group_events WHERE id = $1`
+	var e models.GroupEvent
+	if err := r.getQuerier().QueryRow(ctx, query, eventID).Scan(&e.ID, &e.GroupID, &e.Name, &e.CreatedBy, &e.CreatedAt); err != nil {
+		return nil, fmt.Errorf("getting event: %w", err)
+	}
+	return &e, nil
+}
+
+func (r *eventRepository) GetByGroupID(ctx context.Context, groupID string) ([]models.GroupEvent, error) {
+	query := `SELECT id, group_id, name, created_by, created_at FROM group_events WHERE group_id = $1 ORDER BY created_at, name`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.GroupEvent{}
+	for rows.Next() {
+		var e models.GroupEvent
+		if err := rows.Scan(&e.ID, &e.GroupID, &e.Name, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *eventRepository) GetTotalsByGroupID(ctx context.Context, groupID string) ([]models.EventTotal, error) {
+	query := `
+		SELECT e.event_id, e.currency,
+		       COALESCE(SUM(CASE WHEN e.category = 'REFUND' THEN -e.total_amount ELSE e.total_amount END), 0),
+		       COUNT(e.id)
+		FROM expenses e
+		JOIN group_events ev ON ev.id = e.event_id
+		WHERE ev.group_id = $1
+		  AND e.category NOT IN ('PAYMENT', 'REPAYMENT')
+		GROUP BY e.event_id, e.currency
+		ORDER BY e.event_id, e.currency
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying event totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []models.EventTotal{}
+	for rows.Next() {
+		var t models.EventTotal
+		if err := rows.Scan(&t.EventID, &t.Currency, &t.Total, &t.ExpenseCount); err != nil {
+			return nil, fmt.Errorf("scanning event total: %w", err)
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+func (r *eventRepository) GetMemberShares(ctx context.Context, eventID string) ([]models.EventMemberShare, error) {
+	query := `
+		WITH event_expenses AS (
+			SELECT id, currency, CASE WHEN category = 'REFUND' THEN -1 ELSE 1 END AS sign
+			FROM expenses
+			WHERE event_id = $1 AND category NOT IN ('PAYMENT', 'REPAYMENT')
+		),
+		amounts AS (
+			SELECT p.user_id, ee.currency, ee.sign * p.amount_paid AS paid, 0::numeric AS share
+			FROM expense_payers p
+			JOIN event_expenses ee ON ee.id = p.expense_id
+			UNION ALL
+			SELECT s.user_id, ee.currency, 0::numeric, ee.sign * s.amount
+			FROM expense_splits s
+			JOIN event_expenses ee ON ee.id = s.expense_id
+		)
+		SELECT a.user_id, COALESCE(u.name, ''), a.currency, SUM(a.paid), SUM(a.share)
+		FROM amounts a
+		LEFT JOIN users u ON u.id = a.user_id
+		GROUP BY a.user_id, u.name, a.currency
+		ORDER BY a.currency, u.name, a.user_id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("querying event shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := []models.EventMemberShare{}
+	for rows.Next() {
+		var s models.EventMemberShare
+		if err := rows.Scan(&s.UserID, &s.Name, &s.Currency, &s.Paid, &s.Share); err != nil {
+			return nil, fmt.Errorf("scanning event share: %w", err)
+		}
+		shares = append(shares, s)
+	}
+	return shares, rows.Err()
+}
+
+func (r *eventRepository) Delete(ctx context.Context, eventID string) error {
+	if _, err := r.getQuerier().Exec(ctx, `DELETE FROM group_events WHERE id = $1`, eventID); err != nil {
+		return fmt.Errorf("deleting event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type ExpenseChangeRepository interface {
+	Create(ctx context.Context, change *models.PendingExpenseChange) error
+	GetByIDForUpdate(ctx context.Context, changeID string) (*models.PendingExpenseChange, error)
+	GetAwaitingUser(ctx context.Context, userID string) ([]models.PendingExpenseChange, error)
+	Respond(ctx context.Context, changeID, userID string, status models.ExpenseChangeConfirmationStatus) error
+	Resolve(ctx context.Context, changeID string, status models.ExpenseChangeStatus) (bool, error)
+	SupersedePending(ctx context.Context, expenseID string) error
+	WithTx(tx database.Querier) ExpenseChangeRepository
+}
+
+type expenseChangeRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewExpenseChangeRepository(db *database.DB) ExpenseChangeRepository {
+	return &expenseChangeRepository{db: db}
+}
+
+func (r *expenseChangeRepository) WithTx(tx database.Querier) ExpenseChangeRepository {
+	return &expenseChangeRepository{db: r.db, tx: tx}
+}
+
+func (r *expenseChangeRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *expenseChangeRepository) Create(ctx context.Context, change *models.PendingExpenseChange) error {
+	query := `
+		INSERT INTO pending_expense_changes (id, expense_id, group_id, requested_by, payload, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'PENDING', NOW())
+		RETURNING status, created_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query, change.ID, change.ExpenseID, change.GroupID, change.RequestedBy, change.Payload).
+		Scan(&change.Status, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating pending expense change: %w", err)
+	}
+
+	for i := range change.Confirmations {
+		c := &change.Confirmations[i]
+		c.Status = models.ExpenseChangeConfirmationPending
+		if _, err := r.getQuerier().Exec(ctx, `
+			INSERT INTO pending_expense_change_confirmations (change_id, user_id, status)
+			VALUES ($1, $2, 'PENDING')`, change.ID, c.UserID); err != nil {
+			return fmt.Errorf("creating expense change confirmation: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *expenseChangeRepository) GetByIDForUpdate(ctx context.Context, changeID string) (*models.PendingExpenseChange, error) {
+	query := `
+		SELECT id, expense_id, group_id, requested_by, payload, status, created_at, resolved_at
+		FROM pending_expense_changes
+		WHERE id = $1
+		FOR UPDATE
+	`
+	var c models.PendingExpenseChange
+	err := r.getQuerier().QueryRow(ctx, query, changeID).Scan(
+		&c.ID, &c.ExpenseID, &c.GroupID, &c.RequestedBy, &c.Payload, &c.Status, &c.CreatedAt, &c.ResolvedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting pending expense change: %w", err)
+	}
+
+	changes := []models.PendingExpenseChange{c}
+	if err := r.loadConfirmations(ctx, changes); err != nil {
+		return nil, err
+	}
+	return &changes[0], nil
+}
+
+func (r *expenseChangeRepository) GetAwaitingUser(ctx context.Context, userID string) ([]models.PendingExpenseChange, error) {
+	query := `
+		SELECT c.id, c.expense_id, c.group_id, c.requested_by, c.payload, c.status, c.created_at, c.resolved_at
+		FROM pending_expense_changes c
+		JOIN pending_expense_change_confirmations pc ON pc.change_id = c.id
+		WHERE pc.user_id = $1 AND pc.status = 'PENDING' AND c.status = 'PENDING'
+		ORDER BY c.created_at ASC
+	`
+	rows, err := r.getQuerier().Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending expense changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []models.PendingExpenseChange{}
+	for rows.Next() {
+		var c models.PendingExpenseChange
+		if err := rows.Scan(&c.ID, &c.ExpenseID, &c.GroupID, &c.RequestedBy, &c.Payload, &c.Status, &c.CreatedAt, &c.ResolvedAt); err != nil {
+			return nil, fmt.Errorf("scanning pending expense change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadConfirmations(ctx, changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *expenseChangeRepository) loadConfirmations(ctx context.Context, changes []models.PendingExpenseChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	ids := make([]string, len(changes))
+	index := make(map[string]int, len(changes))
+	for i, c := range changes {
+		ids[i] = c.ID
+		index[c.ID] = i
+		changes[i].Confirmations = []models.ExpenseChangeConfirmation{}
+	}
+
+	query := `
+		SELECT pc.change_id, pc.user_id, u.name, pc.status, pc.responded_at
+		FROM pending_expense_change_confirmations pc
+		JOIN users u ON u.id = pc.user_id
+		WHERE pc.change_id = ANY($1)
+		ORDER BY u.name, pc.user_id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("querying expense change confirmations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var changeID string
+		var c models.ExpenseChangeConfirmation
+		if err := rows.Scan(&changeID, &c.UserID, &c.Name, &c.Status, &c.RespondedAt); err != nil {
+			return fmt.Errorf("scanning expense change confirmation: %w", err)
+		}
+		i := index[changeID]
+		changes[i].Confirmations = append(changes[i].Confirmations, c)
+	}
+	return rows.Err()
+}
+
+func (r *expenseChangeRepository) Respond(ctx context.Context, changeID, userID string, status models.ExpenseChangeConfirmationStatus) error {
+	query := `
+		UPDATE pending_expense_change_confirmations
+		SET status = $3, responded_at = NOW()
+		WHERE change_id = $1 AND user_id = $2
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, changeID, userID, status); err != nil {
+		return fmt.Errorf("responding to expense change: %w", err)
+	}
+	return nil
+}
+
+// Resolve reports false when a concurrent request already resolved the change.
+func (r *expenseChangeRepository) Resolve(ctx context.Context, changeID string, status models.ExpenseChangeStatus) (bool, error) {
+	query := `
+		UPDATE pending_expense_changes
+		SET status = $2, resolved_at = NOW()
+		WHERE id = $1 AND status = 'PENDING'
+	`
+	tag, err := r.getQuerier().Exec(ctx, query, changeID, status)
+	if err != nil {
+		return false, fmt.Errorf("resolving expense change: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *expenseChangeRepository) SupersedePending(ctx context.Context, expenseID string) error {
+	query := `
+		UPDATE pending_expense_changes
+		SET status = 'SUPERSEDED', resolved_at = NOW()
+		WHERE expense_id = $1 AND status = 'PENDING'
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, expenseID); err != nil {
+		return fmt.Errorf("superseding pending expense changes: %w", err)
+	}
+	return nil
+}
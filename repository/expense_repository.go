@@ -6,42 +6,93 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"time"
 
 	"unwise-backend/database"
 	"unwise-backend/models"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// ExpenseRepository is everything the expense tables offer. Services that
+// only need part of it should depend on one of the narrower interfaces below,
+// so their test doubles only have to implement what is actually called.
 type ExpenseRepository interface {
+	ExpenseReader
+	ExpenseWriter
+	SplitWriter
+	BalanceQueries
+	ReceiptStore
+	WithTx(tx database.Querier) ExpenseRepository
+}
+
+// ExpenseReader loads expenses with their splits and payers.
+type ExpenseReader interface {
 	GetByID(ctx context.Context, id string) (*models.Expense, error)
 	GetByGroupID(ctx context.Context, groupID string) ([]models.Expense, error)
-	GetTransactionsByGroupID(ctx context.Context, groupID string) ([]models.Transaction, error)
+	GetTransactionsByGroupID(ctx context.Context, groupID string, sort models.TransactionSort) ([]models.Transaction, error)
 	GetRecentTransactionsForUser(ctx context.Context, userID string, limit int) ([]models.Expense, error)
-	GetUserBalanceInGroup(ctx context.Context, groupID, userID string) (float64, error)
-	GetUserTotalBalance(ctx context.Context, userID string) ([]models.CurrencyAmount, []models.CurrencyAmount, []models.CurrencyAmount, error)
+	GetDashboardVersion(ctx context.Context, userID string) (string, error)
+	GetSplits(ctx context.Context, expenseID string) ([]models.ExpenseSplit, error)
+	GetPayers(ctx context.Context, expenseID string) ([]models.ExpensePayer, error)
+	GetSplitsByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]models.ExpenseSplit, error)
+	GetPayersByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]models.ExpensePayer, error)
+	GetRefundedAmount(ctx context.Context, originalExpenseID string) (float64, error)
+	HasSettledExpense(ctx context.Context, expenseID, userID string) (bool, error)
+	GetSharedTransactions(ctx context.Context, userID, friendID string, groupIDs []string) ([]models.SharedTransaction, error)
+	GetSharedBalanceChanges(ctx context.Context, userID, friendID string, groupIDs []string, granularity string, offsetDays int) ([]models.BalanceHistoryBucket, error)
+	CountGroupExpensesSince(ctx context.Context, groupID string, since time.Time) (int, error)
+	GetGroupSpendingBetween(ctx context.Context, groupID string, from, to time.Time) ([]models.Expense, error)
+}
+
+// ExpenseWriter changes expense rows themselves.
+type ExpenseWriter interface {
 	Create(ctx context.Context, expense *models.Expense) error
 	Update(ctx context.Context, expense *models.Expense) error
+	LockForUpdate(ctx context.Context, id string) error
 	UpdateExplanation(ctx context.Context, id string, explanation string) error
+	ClearExplanation(ctx context.Context, id string) error
+	ClearGroupExplanations(ctx context.Context, groupID string) error
+	SetSplitsLocked(ctx context.Context, id string, locked bool) error
 	Delete(ctx context.Context, id string) error
-	GetSplits(ctx context.Context, expenseID string) ([]models.ExpenseSplit, error)
+	TransferExpenses(ctx context.Context, fromUserID, toUserID string) error
+	TransferGroupExpenses(ctx context.Context, groupID, fromUserID, toUserID string) error
+}
+
+// SplitWriter replaces who paid for an expense and how it is split.
+type SplitWriter interface {
 	CreateSplit(ctx context.Context, split *models.ExpenseSplit) error
 	DeleteSplits(ctx context.Context, expenseID string) error
-	GetPayers(ctx context.Context, expenseID string) ([]models.ExpensePayer, error)
+	SetSplitExclusion(ctx context.Context, expenseID, userID string, status *models.SplitExclusionStatus, reason *string) error
+	ExcludeFromSplit(ctx context.Context, expenseID, userID string, remaining []models.ExpenseSplit) error
 	CreatePayer(ctx context.Context, payer *models.ExpensePayer) error
 	DeletePayers(ctx context.Context, expenseID string) error
+}
+
+// BalanceQueries computes balances from payers and splits.
+type BalanceQueries interface {
+	GetUserBalanceInGroup(ctx context.Context, groupID, userID string) (float64, error)
+	GetUserTotalBalance(ctx context.Context, userID string) ([]models.CurrencyAmount, []models.CurrencyAmount, []models.CurrencyAmount, error)
+	GetGroupBalancesByUserID(ctx context.Context, userID string, groupIDs []string) (map[string]float64, error)
+	GetGroupMemberBalances(ctx context.Context, groupID string, asOf *time.Time) (map[string]map[string]float64, error)
+	GetGroupTotalSpend(ctx context.Context, groupID string) (float64, error)
+	GetGroupSpendByCurrency(ctx context.Context, groupID string) ([]models.CurrencySpend, error)
+	GetPairwiseBalances(ctx context.Context, userID, friendID string, groupIDs []string) (map[string]float64, error)
+	GetPairwiseBalancesAllFriends(ctx context.Context, userID string) (map[string]map[string]float64, error)
+	GetPairLedgers(ctx context.Context, groupID, currency string, userIDs []string) ([]models.PairLedger, error)
+	GetGroupPairBalances(ctx context.Context, groupID string, asOf *time.Time) ([]models.PairBalance, error)
+}
+
+// ReceiptStore holds scanned receipt items, their assignments and the
+// receipt images attached to a group's transactions.
+type ReceiptStore interface {
 	GetReceiptItems(ctx context.Context, expenseID string) ([]models.ReceiptItem, error)
+	GetReceiptItemsByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]models.ReceiptItem, error)
 	CreateReceiptItem(ctx context.Context, item *models.ReceiptItem) error
 	GetReceiptItemAssignments(ctx context.Context, receiptItemID string) ([]models.ReceiptItemAssignment, error)
 	CreateReceiptItemAssignment(ctx context.Context, assignment *models.ReceiptItemAssignment) error
 	DeleteReceiptItems(ctx context.Context, expenseID string) error
-	GetSplitsByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]models.ExpenseSplit, error)
-	GetPayersByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]models.ExpensePayer, error)
-	GetGroupBalancesByUserID(ctx context.Context, userID string, groupIDs []string) (map[string]float64, error)
-	GetGroupMemberBalances(ctx context.Context, groupID string) (map[string]map[string]float64, error)
-	GetGroupTotalSpend(ctx context.Context, groupID string) (float64, error)
-	GetPairwiseBalances(ctx context.Context, userID, friendID string, groupIDs []string) (map[string]float64, error)
-	GetPairwiseBalancesAllFriends(ctx context.Context, userID string) (map[string]map[string]float64, error)
-	TransferExpenses(ctx context.Context, fromUserID, toUserID string) error
-	WithTx(tx database.Querier) ExpenseRepository
+	GetReceiptsByGroupID(ctx context.Context, groupID string, limit, offset int) ([]models.ReceiptGalleryItem, int, error)
 }
 
 type expenseRepository struct {
@@ -66,20 +117,26 @@ func (r *expenseRepository) getQuerier() database.Querier {
 
 func (r *expenseRepository) GetByID(ctx context.Context, id string) (*models.Expense, error) {
 	var expense models.Expense
-	query := `SELECT id, group_id, paid_by_user_id, total_amount, currency, description, 
-	          receipt_image_url, type, category, tax, cgst, sgst, service_charge, explanation, created_at, updated_at, 
-	          transaction_timestamp, date_only::TEXT, time_only::TEXT
+	query := `SELECT id, group_id, paid_by_user_id, created_by_user_id, total_amount, currency, description, 
+	          receipt_image_path, type, category, original_expense_id, settlement_method, settlement_reference, settlement_proof_path, limit_flagged, tax, cgst, sgst, service_charge, explanation, created_at, updated_at, 
+	          transaction_timestamp, date_only::TEXT, time_only::TEXT,
+	          reverses_expense_id, (SELECT r.id FROM expenses r WHERE r.reverses_expense_id = expenses.id), event_id, locked_splits,
+	          settles_expense_id, tax_components
 	          FROM expenses WHERE id = $1`
 
 	err := r.getQuerier().QueryRow(ctx, query, id).Scan(
-		&expense.ID, &expense.GroupID, &expense.PaidByUserID, &expense.TotalAmount, &expense.Currency,
-		&expense.Description, &expense.ReceiptImageURL, &expense.Type, &expense.Category,
+		&expense.ID, &expense.GroupID, &expense.PaidByUserID, &expense.CreatedByUserID, &expense.TotalAmount, &expense.Currency,
+		&expense.Description, &expense.ReceiptImagePath, &expense.Type, &expense.Category, &expense.OriginalExpenseID,
+		&expense.SettlementMethod, &expense.SettlementReference, &expense.SettlementProofPath, &expense.LimitFlagged,
 		&expense.Tax, &expense.CGST, &expense.SGST, &expense.ServiceCharge, &expense.Explanation,
 		&expense.CreatedAt, &expense.UpdatedAt, &expense.DateISO, &expense.Date, &expense.Time,
+		&expense.ReversesExpenseID, &expense.ReversedByExpenseID, &expense.EventID, &expense.LockedSplits,
+		&expense.SettlesExpenseID, &expense.TaxComponents,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("getting expense by id: %w", err)
 	}
+	expense.ResolveSettlementStatus()
 
 	payers, err := r.GetPayers(ctx, id)
 	if err != nil {
@@ -102,8 +159,8 @@ func (r *expenseRepository) GetByID(ctx context.Context, id string) (*models.Exp
 }
 
 func (r *expenseRepository) GetByGroupID(ctx context.Context, groupID string) ([]models.Expense, error) {
-	query := `SELECT id, group_id, paid_by_user_id, total_amount, currency, description,
-	          receipt_image_url, type, category, tax, cgst, sgst, service_charge, explanation, created_at, updated_at, 
+	query := `SELECT id, group_id, paid_by_user_id, created_by_user_id, total_amount, currency, description,
+	          receipt_image_path, type, category, original_expense_id, tax, cgst, sgst, service_charge, explanation, created_at, updated_at, 
 	          transaction_timestamp, date_only::TEXT, time_only::TEXT
 	          FROM expenses WHERE group_id = $1
 	          ORDER BY transaction_timestamp DESC, created_at DESC`
@@ -119,8 +176,8 @@ func (r *expenseRepository) GetByGroupID(ctx context.Context, groupID string) ([
 	for rows.Next() {
 		var expense models.Expense
 		if err := rows.Scan(
-			&expense.ID, &expense.GroupID, &expense.PaidByUserID, &expense.TotalAmount, &expense.Currency,
-			&expense.Description, &expense.ReceiptImageURL, &expense.Type, &expense.Category,
+			&expense.ID, &expense.GroupID, &expense.PaidByUserID, &expense.CreatedByUserID, &expense.TotalAmount, &expense.Currency,
+			&expense.Description, &expense.ReceiptImagePath, &expense.Type, &expense.Category, &expense.OriginalExpenseID,
 			&expense.Tax, &expense.CGST, &expense.SGST, &expense.ServiceCharge, &expense.Explanation,
 			&expense.CreatedAt, &expense.UpdatedAt, &expense.DateISO, &expense.Date, &expense.Time,
 		); err != nil {
@@ -141,13 +198,9 @@ func (r *expenseRepository) GetByGroupID(ctx context.Context, groupID string) ([
 			return nil, fmt.Errorf("batch getting payers: %w", err)
 		}
 
-		allReceiptItems := make(map[string][]models.ReceiptItem)
-		for _, expenseID := range expenseIDs {
-			items, err := r.GetReceiptItems(ctx, expenseID)
-			if err != nil {
-				return nil, fmt.Errorf("getting receipt items for expense %s: %w", expenseID, err)
-			}
-			allReceiptItems[expenseID] = items
+		allReceiptItems, err := r.GetReceiptItemsByExpenseIDs(ctx, expenseIDs)
+		if err != nil {
+			return nil, fmt.Errorf("batch getting receipt items: %w", err)
 		}
 
 		for i := range expenses {
@@ -181,13 +234,19 @@ func (r *expenseRepository) Create(ctx context.Context, expense *models.Expense)
 	}
 
 	query := `INSERT INTO expenses (id, group_id, paid_by_user_id, total_amount, currency, description,
-	          receipt_image_url, type, category, tax, cgst, sgst, service_charge, created_at, updated_at, transaction_timestamp, date_only, time_only)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW(), $14, $15, $16)`
+	          receipt_image_path, type, category, original_expense_id, settlement_method, settlement_reference, settlement_proof_path,
+	          tax, cgst, sgst, service_charge, created_at, updated_at, transaction_timestamp, date_only, time_only, limit_flagged, created_by_user_id,
+	          reverses_expense_id, event_id, locked_splits, settles_expense_id, tax_components)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, NOW(), NOW(), $18, $19, $20, $21, $22, $23, $24, $25, $26,
+	          NULLIF($27::jsonb, 'null'::jsonb))`
 
 	_, err := r.getQuerier().Exec(ctx, query,
 		expense.ID, expense.GroupID, expense.PaidByUserID, expense.TotalAmount, expense.Currency,
-		expense.Description, expense.ReceiptImageURL, expense.Type, category,
+		expense.Description, expense.ReceiptImagePath, expense.Type, category, expense.OriginalExpenseID,
+		expense.SettlementMethod, expense.SettlementReference, expense.SettlementProofPath,
 		expense.Tax, expense.CGST, expense.SGST, expense.ServiceCharge, expense.DateISO, expense.Date, expense.Time,
+		expense.LimitFlagged, expense.CreatedByUserID, expense.ReversesExpenseID, expense.EventID, expense.LockedSplits,
+		expense.SettlesExpenseID, expense.TaxComponents,
 	)
 	if err != nil {
 		return fmt.Errorf("creating expense: %w", err)
@@ -197,14 +256,16 @@ func (r *expenseRepository) Create(ctx context.Context, expense *models.Expense)
 
 func (r *expenseRepository) Update(ctx context.Context, expense *models.Expense) error {
 	query := `UPDATE expenses SET total_amount = $1, description = $2, 
-	          receipt_image_url = $3, type = $4, category = $5, 
-	          tax = $6, cgst = $7, sgst = $8, service_charge = $9, transaction_timestamp = $10, date_only = $11, time_only = $12, updated_at = NOW()
-	          WHERE id = $13`
+	          receipt_image_path = $3, type = $4, category = $5, 
+	          tax = $6, cgst = $7, sgst = $8, service_charge = $9, transaction_timestamp = $10, date_only = $11, time_only = $12,
+	          limit_flagged = $13, event_id = $14, tax_components = NULLIF($15::jsonb, 'null'::jsonb), updated_at = NOW()
+	          WHERE id = $16`
 
 	_, err := r.getQuerier().Exec(ctx, query,
-		expense.TotalAmount, expense.Description, expense.ReceiptImageURL,
+		expense.TotalAmount, expense.Description, expense.ReceiptImagePath,
 		expense.Type, expense.Category,
-		expense.Tax, expense.CGST, expense.SGST, expense.ServiceCharge, expense.DateISO, expense.Date, expense.Time, expense.ID,
+		expense.Tax, expense.CGST, expense.SGST, expense.ServiceCharge, expense.DateISO, expense.Date, expense.Time,
+		expense.LimitFlagged, expense.EventID, expense.TaxComponents, expense.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("updating expense: %w", err)
@@ -221,6 +282,30 @@ func (r *expenseRepository) UpdateExplanation(ctx context.Context, id string, ex
 	return nil
 }
 
+func (r *expenseRepository) ClearExplanation(ctx context.Context, id string) error {
+	query := `UPDATE expenses SET explanation = NULL WHERE id = $1`
+	if _, err := r.getQuerier().Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("clearing expense explanation: %w", err)
+	}
+	return nil
+}
+
+func (r *expenseRepository) ClearGroupExplanations(ctx context.Context, groupID string) error {
+	query := `UPDATE expenses SET explanation = NULL WHERE group_id = $1 AND explanation IS NOT NULL`
+	if _, err := r.getQuerier().Exec(ctx, query, groupID); err != nil {
+		return fmt.Errorf("clearing group expense explanations: %w", err)
+	}
+	return nil
+}
+
+func (r *expenseRepository) SetSplitsLocked(ctx context.Context, id string, locked bool) error {
+	query := `UPDATE expenses SET locked_splits = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := r.getQuerier().Exec(ctx, query, locked, id); err != nil {
+		return fmt.Errorf("setting expense split lock: %w", err)
+	}
+	return nil
+}
+
 func (r *expenseRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM expenses WHERE id = $1`
 
@@ -232,7 +317,8 @@ func (r *expenseRepository) Delete(ctx context.Context, id string) error {
 }
 
 func (r *expenseRepository) GetSplits(ctx context.Context, expenseID string) ([]models.ExpenseSplit, error) {
-	query := `SELECT id, expense_id, user_id, amount, percentage, created_at, updated_at
+	query := `SELECT id, expense_id, user_id, amount, percentage,
+	                 exclusion_status, exclusion_reason, exclusion_requested_at, created_at, updated_at
 	          FROM expense_splits WHERE expense_id = $1`
 
 	rows, err := r.getQuerier().Query(ctx, query, expenseID)
@@ -245,8 +331,9 @@ func (r *expenseRepository) GetSplits(ctx context.Context, expenseID string) ([]
 	for rows.Next() {
 		var split models.ExpenseSplit
 		if err := rows.Scan(
-			&split.ID, &split.ExpenseID, &split.UserID, &split.Amount,
-			&split.Percentage, &split.CreatedAt, &split.UpdatedAt,
+			&split.ID, &split.ExpenseID, &split.UserID, &split.Amount, &split.Percentage,
+			&split.ExclusionStatus, &split.ExclusionReason, &split.ExclusionRequestedAt,
+			&split.CreatedAt, &split.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scanning expense split: %w", err)
 		}
@@ -269,6 +356,45 @@ func (r *expenseRepository) CreateSplit(ctx context.Context, split *models.Expen
 	return nil
 }
 
+// SetSplitExclusion sets or, with a nil status, clears the exclusion flag on
+// userID's split of the expense.
+func (r *expenseRepository) SetSplitExclusion(ctx context.Context, expenseID, userID string, status *models.SplitExclusionStatus, reason *string) error {
+	query := `UPDATE expense_splits
+	          SET exclusion_status = $3, exclusion_reason = $4,
+	              exclusion_requested_at = CASE WHEN $3::VARCHAR = 'PENDING' THEN NOW() ELSE exclusion_requested_at END,
+	              updated_at = NOW()
+	          WHERE expense_id = $1 AND user_id = $2`
+	tag, err := r.getQuerier().Exec(ctx, query, expenseID, userID, status, reason)
+	if err != nil {
+		return fmt.Errorf("setting split exclusion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("setting split exclusion: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// ExcludeFromSplit removes userID's split and sets the amounts of the
+// remaining splits in place, keeping their own exclusion flags. Run it in a
+// transaction.
+func (r *expenseRepository) ExcludeFromSplit(ctx context.Context, expenseID, userID string, remaining []models.ExpenseSplit) error {
+	q := r.getQuerier()
+	if _, err := q.Exec(ctx, `DELETE FROM expense_splits WHERE expense_id = $1 AND user_id = $2`, expenseID, userID); err != nil {
+		return fmt.Errorf("deleting excluded split: %w", err)
+	}
+	for _, split := range remaining {
+		query := `UPDATE expense_splits SET amount = $3, percentage = $4, updated_at = NOW()
+		          WHERE expense_id = $1 AND user_id = $2`
+		if _, err := q.Exec(ctx, query, expenseID, split.UserID, split.Amount, split.Percentage); err != nil {
+			return fmt.Errorf("updating remaining split: %w", err)
+		}
+	}
+	if _, err := q.Exec(ctx, `UPDATE expenses SET updated_at = NOW() WHERE id = $1`, expenseID); err != nil {
+		return fmt.Errorf("touching re-split expense: %w", err)
+	}
+	return nil
+}
+
 func (r *expenseRepository) DeleteSplits(ctx context.Context, expenseID string) error {
 	query := `DELETE FROM expense_splits WHERE expense_id = $1`
 
@@ -280,66 +406,74 @@ func (r *expenseRepository) DeleteSplits(ctx context.Context, expenseID string)
 }
 
 func (r *expenseRepository) GetReceiptItems(ctx context.Context, expenseID string) ([]models.ReceiptItem, error) {
-	query := `SELECT id, expense_id, name, price, created_at
-	          FROM receipt_items WHERE expense_id = $1`
+	items, err := r.GetReceiptItemsByExpenseIDs(ctx, []string{expenseID})
+	if err != nil {
+		return nil, err
+	}
+	return items[expenseID], nil
+}
 
-	rows, err := r.getQuerier().Query(ctx, query, expenseID)
+// GetReceiptItemsByExpenseIDs loads the receipt items of many expenses, with
+// their assignments, in one query.
+func (r *expenseRepository) GetReceiptItemsByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]models.ReceiptItem, error) {
+	result := make(map[string][]models.ReceiptItem)
+	if len(expenseIDs) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT i.id, i.expense_id, i.name, i.price, i.quantity, i.created_at,
+	                 a.id, a.user_id, COALESCE(a.portion, 0), a.created_at
+	          FROM receipt_items i
+	          LEFT JOIN receipt_item_assignments a ON a.receipt_item_id = i.id
+	          WHERE i.expense_id = ANY($1)
+	          ORDER BY i.expense_id, i.created_at, i.id, a.created_at`
+
+	rows, err := r.getQuerier().Query(ctx, query, expenseIDs)
 	if err != nil {
-		return nil, fmt.Errorf("getting receipt items: %w", err)
+		return nil, fmt.Errorf("batch getting receipt items: %w", err)
 	}
 	defer rows.Close()
 
-	var items []models.ReceiptItem
-	itemIDs := make([]string, 0)
-	itemMap := make(map[string]*models.ReceiptItem)
-
+	// Rows of one item are adjacent, so each row either continues the last
+	// item of its expense or starts a new one.
 	for rows.Next() {
 		var item models.ReceiptItem
+		var assignmentID, userID *string
+		var portion float64
+		var assignedAt *time.Time
 		if err := rows.Scan(
-			&item.ID, &item.ExpenseID, &item.Name, &item.Price, &item.CreatedAt,
+			&item.ID, &item.ExpenseID, &item.Name, &item.Price, &item.Quantity, &item.CreatedAt,
+			&assignmentID, &userID, &portion, &assignedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scanning receipt item: %w", err)
 		}
-		item.Assignments = []models.ReceiptItemAssignment{}
-		items = append(items, item)
-		itemIDs = append(itemIDs, item.ID)
-	}
-
-	if len(itemIDs) == 0 {
-		return items, nil
-	}
-
-	for i := range items {
-		itemMap[items[i].ID] = &items[i]
-	}
-
-	assignQuery := `SELECT id, receipt_item_id, user_id, created_at
-	               FROM receipt_item_assignments WHERE receipt_item_id = ANY($1)`
 
-	aRows, err := r.getQuerier().Query(ctx, assignQuery, itemIDs)
-	if err != nil {
-		return nil, fmt.Errorf("getting batch receipt assignments: %w", err)
-	}
-	defer aRows.Close()
-
-	for aRows.Next() {
-		var a models.ReceiptItemAssignment
-		if err := aRows.Scan(&a.ID, &a.ReceiptItemID, &a.UserID, &a.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scanning assignment: %w", err)
+		items := result[item.ExpenseID]
+		if len(items) == 0 || items[len(items)-1].ID != item.ID {
+			item.Assignments = []models.ReceiptItemAssignment{}
+			items = append(items, item)
 		}
-		if item, ok := itemMap[a.ReceiptItemID]; ok {
-			item.Assignments = append(item.Assignments, a)
+		if assignmentID != nil {
+			last := &items[len(items)-1]
+			a := models.ReceiptItemAssignment{ID: *assignmentID, ReceiptItemID: item.ID, Portion: portion}
+			if userID != nil {
+				a.UserID = *userID
+			}
+			if assignedAt != nil {
+				a.CreatedAt = *assignedAt
+			}
+			last.Assignments = append(last.Assignments, a)
 		}
+		result[item.ExpenseID] = items
 	}
-
-	return items, nil
+	return result, rows.Err()
 }
 
 func (r *expenseRepository) CreateReceiptItem(ctx context.Context, item *models.ReceiptItem) error {
-	query := `INSERT INTO receipt_items (id, expense_id, name, price, created_at)
-	          VALUES ($1, $2, $3, $4, NOW())`
+	query := `INSERT INTO receipt_items (id, expense_id, name, price, quantity, created_at)
+	          VALUES ($1, $2, $3, $4, $5, NOW())`
 
-	_, err := r.getQuerier().Exec(ctx, query, item.ID, item.ExpenseID, item.Name, item.Price)
+	_, err := r.getQuerier().Exec(ctx, query, item.ID, item.ExpenseID, item.Name, item.Price, item.Quantity)
 	if err != nil {
 		return fmt.Errorf("creating receipt item: %w", err)
 	}
@@ -347,7 +481,7 @@ func (r *expenseRepository) CreateReceiptItem(ctx context.Context, item *models.
 }
 
 func (r *expenseRepository) GetReceiptItemAssignments(ctx context.Context, receiptItemID string) ([]models.ReceiptItemAssignment, error) {
-	query := `SELECT id, receipt_item_id, user_id, created_at
+	query := `SELECT id, receipt_item_id, user_id, COALESCE(portion, 0), created_at
 	          FROM receipt_item_assignments WHERE receipt_item_id = $1`
 
 	rows, err := r.getQuerier().Query(ctx, query, receiptItemID)
@@ -360,7 +494,7 @@ func (r *expenseRepository) GetReceiptItemAssignments(ctx context.Context, recei
 	for rows.Next() {
 		var assignment models.ReceiptItemAssignment
 		if err := rows.Scan(
-			&assignment.ID, &assignment.ReceiptItemID, &assignment.UserID, &assignment.CreatedAt,
+			&assignment.ID, &assignment.ReceiptItemID, &assignment.UserID, &assignment.Portion, &assignment.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scanning receipt item assignment: %w", err)
 		}
@@ -371,25 +505,51 @@ func (r *expenseRepository) GetReceiptItemAssignments(ctx context.Context, recei
 }
 
 func (r *expenseRepository) CreateReceiptItemAssignment(ctx context.Context, assignment *models.ReceiptItemAssignment) error {
-	query := `INSERT INTO receipt_item_assignments (id, receipt_item_id, user_id, created_at)
-	          VALUES ($1, $2, $3, NOW())`
+	query := `INSERT INTO receipt_item_assignments (id, receipt_item_id, user_id, portion, created_at)
+	          VALUES ($1, $2, $3, $4, NOW())`
 
-	_, err := r.getQuerier().Exec(ctx, query, assignment.ID, assignment.ReceiptItemID, assignment.UserID)
+	_, err := r.getQuerier().Exec(ctx, query, assignment.ID, assignment.ReceiptItemID, assignment.UserID, assignment.Portion)
 	if err != nil {
 		return fmt.Errorf("creating receipt item assignment: %w", err)
 	}
 	return nil
 }
 
-func (r *expenseRepository) GetTransactionsByGroupID(ctx context.Context, groupID string) ([]models.Transaction, error) {
-	query := `SELECT e.id, e.group_id, e.paid_by_user_id, e.total_amount, e.description,
-	          e.receipt_image_url, e.type, e.category, e.tax, e.cgst, e.sgst, e.service_charge, e.explanation,
+var transactionSortColumns = map[models.TransactionSortField]string{
+	models.TransactionSortAmount: "e.total_amount",
+	models.TransactionSortPayer:  "LOWER(u.name)",
+}
+
+func transactionOrderBy(sort models.TransactionSort) string {
+	dateOrder := "DESC"
+	if sort.Field == models.TransactionSortDate && sort.Order == models.SortOrderAsc {
+		dateOrder = "ASC"
+	}
+	tiebreak := fmt.Sprintf("e.transaction_timestamp %[1]s, e.created_at %[1]s, e.id %[1]s", dateOrder)
+
+	column, ok := transactionSortColumns[sort.Field]
+	if !ok {
+		return tiebreak
+	}
+	direction := "DESC"
+	if sort.Order == models.SortOrderAsc || (sort.Field == models.TransactionSortPayer && sort.Order == "") {
+		direction = "ASC"
+	}
+	return fmt.Sprintf("%s %s NULLS LAST, %s", column, direction, tiebreak)
+}
+
+func (r *expenseRepository) GetTransactionsByGroupID(ctx context.Context, groupID string, sort models.TransactionSort) ([]models.Transaction, error) {
+	query := `SELECT e.id, e.group_id, e.paid_by_user_id, e.created_by_user_id, e.total_amount, e.currency, e.description,
+	          e.receipt_image_path, e.type, e.category, e.original_expense_id,
+	          e.settlement_method, e.settlement_reference, e.settlement_proof_path, e.limit_flagged, e.tax, e.cgst, e.sgst, e.service_charge, e.explanation,
 	          e.created_at, e.updated_at, e.transaction_timestamp, e.date_only::TEXT, e.time_only::TEXT,
+	          e.reverses_expense_id, (SELECT r.id FROM expenses r WHERE r.reverses_expense_id = e.id), e.event_id, e.locked_splits,
+	          e.settles_expense_id, e.tax_components,
 	          u.id, u.email, u.name, u.avatar_url, u.created_at, u.updated_at
 	          FROM expenses e
 	          LEFT JOIN users u ON e.paid_by_user_id = u.id
 	          WHERE e.group_id = $1
-	          ORDER BY e.transaction_timestamp DESC, e.created_at DESC`
+	          ORDER BY ` + transactionOrderBy(sort)
 
 	rows, err := r.getQuerier().Query(ctx, query, groupID)
 	if err != nil {
@@ -407,16 +567,20 @@ func (r *expenseRepository) GetTransactionsByGroupID(ctx context.Context, groupI
 		var userCreatedAt, userUpdatedAt sql.NullTime
 
 		err := rows.Scan(
-			&t.ID, &t.GroupID, &t.PaidByUserID, &t.TotalAmount,
-			&t.Expense.Description, &t.ReceiptImageURL, &t.Expense.Type, &t.Category,
+			&t.ID, &t.GroupID, &t.PaidByUserID, &t.CreatedByUserID, &t.TotalAmount, &t.Currency,
+			&t.Expense.Description, &t.ReceiptImagePath, &t.Expense.Type, &t.Category, &t.OriginalExpenseID,
+			&t.SettlementMethod, &t.SettlementReference, &t.SettlementProofPath, &t.LimitFlagged,
 			&t.Tax, &t.CGST, &t.SGST, &t.ServiceCharge, &t.Explanation,
 			&t.CreatedAt, &t.UpdatedAt, &t.DateISO, &t.Date, &t.Time,
+			&t.ReversesExpenseID, &t.ReversedByExpenseID, &t.EventID, &t.LockedSplits,
+			&t.SettlesExpenseID, &t.TaxComponents,
 			&userID, &userEmail, &userName, &userAvatarURL,
 			&userCreatedAt, &userUpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning transaction: %w", err)
 		}
+		t.ResolveSettlementStatus()
 
 		t.Type = string(t.Category)
 
@@ -453,6 +617,11 @@ func (r *expenseRepository) GetTransactionsByGroupID(ctx context.Context, groupI
 		return nil, fmt.Errorf("batch getting payers: %w", err)
 	}
 
+	allReceiptItems, err := r.GetReceiptItemsByExpenseIDs(ctx, transactionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("batch getting receipt items: %w", err)
+	}
+
 	for i := range transactions {
 		splits := allSplits[transactions[i].ID]
 		if splits == nil {
@@ -465,6 +634,12 @@ func (r *expenseRepository) GetTransactionsByGroupID(ctx context.Context, groupI
 			payers = []models.ExpensePayer{}
 		}
 		transactions[i].Payers = payers
+
+		items := allReceiptItems[transactions[i].ID]
+		if items == nil {
+			items = []models.ReceiptItem{}
+		}
+		transactions[i].ReceiptItems = items
 	}
 
 	return transactions, nil
@@ -516,13 +691,16 @@ func (r *expenseRepository) DeletePayers(ctx context.Context, expenseID string)
 	return nil
 }
 
+// GetRecentTransactionsForUser returns the latest transactions across the
+// user's groups, except sandbox groups.
 func (r *expenseRepository) GetRecentTransactionsForUser(ctx context.Context, userID string, limit int) ([]models.Expense, error) {
 	query := `SELECT DISTINCT e.id, e.group_id, e.paid_by_user_id, e.total_amount, e.description,
-	          e.receipt_image_url, e.type, e.category, e.tax, e.cgst, e.sgst, e.service_charge, e.explanation,
+	          e.receipt_image_path, e.type, e.category, e.original_expense_id, e.tax, e.cgst, e.sgst, e.service_charge, e.explanation,
 	          e.created_at, e.updated_at, e.transaction_timestamp, e.date_only::TEXT, e.time_only::TEXT
 	          FROM expenses e
 	          INNER JOIN group_members gm ON e.group_id = gm.group_id
-	          WHERE gm.user_id = $1
+	          INNER JOIN groups g ON g.id = e.group_id
+	          WHERE gm.user_id = $1 AND NOT g.is_sandbox
 	          ORDER BY e.transaction_timestamp DESC, e.created_at DESC
 	          LIMIT $2`
 
@@ -537,7 +715,7 @@ func (r *expenseRepository) GetRecentTransactionsForUser(ctx context.Context, us
 		var expense models.Expense
 		if err := rows.Scan(
 			&expense.ID, &expense.GroupID, &expense.PaidByUserID, &expense.TotalAmount,
-			&expense.Description, &expense.ReceiptImageURL, &expense.Type, &expense.Category,
+			&expense.Description, &expense.ReceiptImagePath, &expense.Type, &expense.Category, &expense.OriginalExpenseID,
 			&expense.Tax, &expense.CGST, &expense.SGST, &expense.ServiceCharge, &expense.Explanation,
 			&expense.CreatedAt, &expense.UpdatedAt, &expense.DateISO, &expense.Date, &expense.Time,
 		); err != nil {
@@ -549,6 +727,29 @@ func (r *expenseRepository) GetRecentTransactionsForUser(ctx context.Context, us
 	return expenses, nil
 }
 
+func (r *expenseRepository) GetDashboardVersion(ctx context.Context, userID string) (string, error) {
+	query := `
+		WITH my_groups AS (
+			SELECT group_id FROM group_members WHERE user_id = $1
+		)
+		SELECT concat_ws('|',
+			(SELECT updated_at FROM users WHERE id = $1),
+			(SELECT COUNT(*) FROM my_groups),
+			(SELECT MAX(g.updated_at) FROM groups g JOIN my_groups mg ON mg.group_id = g.id),
+			(SELECT COUNT(*) FROM group_members gm JOIN my_groups mg ON mg.group_id = gm.group_id),
+			(SELECT COUNT(archived_at) || ':' || COALESCE(MAX(archived_at)::TEXT, '') || ':' || COALESCE(MAX(archive_suggestion_dismissed_at)::TEXT, '')
+			 FROM group_members WHERE user_id = $1),
+			(SELECT COUNT(*) || ':' || COALESCE(MAX(e.updated_at)::TEXT, '') FROM expenses e JOIN my_groups mg ON mg.group_id = e.group_id),
+			(SELECT COUNT(*) || ':' || COALESCE(MAX(er.seen_at)::TEXT, '') FROM expense_reads er WHERE er.user_id = $1)
+		)
+	`
+	var version string
+	if err := r.getQuerier().QueryRow(ctx, query, userID).Scan(&version); err != nil {
+		return "", fmt.Errorf("getting dashboard version: %w", err)
+	}
+	return version, nil
+}
+
 func (r *expenseRepository) GetUserBalanceInGroup(ctx context.Context, groupID, userID string) (float64, error) {
 	query := `SELECT 
 	          COALESCE(SUM(p.amount_paid), 0) - COALESCE(SUM(s.amount), 0) as balance
@@ -565,27 +766,19 @@ func (r *expenseRepository) GetUserBalanceInGroup(ctx context.Context, groupID,
 	return balance, nil
 }
 
+// Only groups the user is still in are counted.
 func (r *expenseRepository) GetUserTotalBalance(ctx context.Context, userID string) ([]models.CurrencyAmount, []models.CurrencyAmount, []models.CurrencyAmount, error) {
 	query := `
-		WITH group_currency_nets AS (
-			SELECT 
-				e.group_id,
-				e.currency,
-				COALESCE(SUM(p.amount_paid), 0) - COALESCE(SUM(s.amount), 0) as balance
-			FROM expenses e
-			INNER JOIN group_members gm ON e.group_id = gm.group_id
-			LEFT JOIN expense_payers p ON e.id = p.expense_id AND p.user_id = $1
-			LEFT JOIN expense_splits s ON e.id = s.expense_id AND s.user_id = $1
-			WHERE gm.user_id = $1
-			GROUP BY e.group_id, e.currency
-		)
-		SELECT 
-			currency,
-			COALESCE(SUM(balance), 0) as total_net,
-			COALESCE(SUM(CASE WHEN balance < -0.01 THEN ABS(balance) ELSE 0 END), 0) as total_owe,
-			COALESCE(SUM(CASE WHEN balance > 0.01 THEN balance ELSE 0 END), 0) as total_owed
-		FROM group_currency_nets
-		GROUP BY currency
+		SELECT
+			m.currency,
+			COALESCE(SUM(m.net), 0) as total_net,
+			COALESCE(SUM(CASE WHEN m.net < -0.01 THEN ABS(m.net) ELSE 0 END), 0) as total_owe,
+			COALESCE(SUM(CASE WHEN m.net > 0.01 THEN m.net ELSE 0 END), 0) as total_owed
+		FROM user_balance_metrics m
+		INNER JOIN group_members gm ON gm.group_id = m.group_id AND gm.user_id = m.user_id
+		INNER JOIN groups g ON g.id = m.group_id
+		WHERE m.user_id = $1 AND NOT g.is_sandbox
+		GROUP BY m.currency
 	`
 
 	rows, err := r.getQuerier().Query(ctx, query, userID)
@@ -630,7 +823,8 @@ func (r *expenseRepository) GetSplitsByExpenseIDs(ctx context.Context, expenseID
 		return make(map[string][]models.ExpenseSplit), nil
 	}
 
-	query := `SELECT id, expense_id, user_id, amount, percentage, created_at, updated_at
+	query := `SELECT id, expense_id, user_id, amount, percentage,
+	                 exclusion_status, exclusion_reason, exclusion_requested_at, created_at, updated_at
 	          FROM expense_splits WHERE expense_id = ANY($1)`
 
 	rows, err := r.getQuerier().Query(ctx, query, expenseIDs)
@@ -642,7 +836,8 @@ func (r *expenseRepository) GetSplitsByExpenseIDs(ctx context.Context, expenseID
 	result := make(map[string][]models.ExpenseSplit)
 	for rows.Next() {
 		var split models.ExpenseSplit
-		if err := rows.Scan(&split.ID, &split.ExpenseID, &split.UserID, &split.Amount, &split.Percentage, &split.CreatedAt, &split.UpdatedAt); err != nil {
+		if err := rows.Scan(&split.ID, &split.ExpenseID, &split.UserID, &split.Amount, &split.Percentage,
+			&split.ExclusionStatus, &split.ExclusionReason, &split.ExclusionRequestedAt, &split.CreatedAt, &split.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scanning split: %w", err)
 		}
 		result[split.ExpenseID] = append(result[split.ExpenseID], split)
@@ -720,13 +915,14 @@ func (r *expenseRepository) GetGroupBalancesByUserID(ctx context.Context, userID
 	return result, nil
 }
 
-func (r *expenseRepository) GetGroupMemberBalances(ctx context.Context, groupID string) (map[string]map[string]float64, error) {
+func (r *expenseRepository) GetGroupMemberBalances(ctx context.Context, groupID string, asOf *time.Time) (map[string]map[string]float64, error) {
 	query := `
 		WITH member_payments AS (
 			SELECT e.currency, p.user_id, COALESCE(SUM(p.amount_paid), 0) as paid
 			FROM expense_payers p
 			JOIN expenses e ON e.id = p.expense_id
 			WHERE e.group_id = $1
+				AND ($2::DATE IS NULL OR COALESCE(e.date_only, e.transaction_timestamp::DATE) <= $2::DATE)
 			GROUP BY e.currency, p.user_id
 		),
 		member_splits AS (
@@ -734,6 +930,7 @@ func (r *expenseRepository) GetGroupMemberBalances(ctx context.Context, groupID
 			FROM expense_splits s
 			JOIN expenses e ON e.id = s.expense_id
 			WHERE e.group_id = $1
+				AND ($2::DATE IS NULL OR COALESCE(e.date_only, e.transaction_timestamp::DATE) <= $2::DATE)
 			GROUP BY e.currency, s.user_id
 		)
 		SELECT 
@@ -744,7 +941,7 @@ func (r *expenseRepository) GetGroupMemberBalances(ctx context.Context, groupID
 		FULL OUTER JOIN member_splits ms ON mp.user_id = ms.user_id AND mp.currency = ms.currency
 	`
 
-	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	rows, err := r.getQuerier().Query(ctx, query, groupID, asOf)
 	if err != nil {
 		return nil, fmt.Errorf("batch getting group member balances: %w", err)
 	}
@@ -816,6 +1013,265 @@ func (r *expenseRepository) GetPairwiseBalances(ctx context.Context, userID, fri
 	return result, nil
 }
 
+// GetGroupPairBalances returns, per currency, what each pair of people who
+// shared a transaction owes each other, counting transactions dated on or
+// before asOf when it is set. Transactions are attributed as in
+// GetPairLedgers; pairs that come out even are left out.
+func (r *expenseRepository) GetGroupPairBalances(ctx context.Context, groupID string, asOf *time.Time) ([]models.PairBalance, error) {
+	query := `
+		WITH group_expenses AS (
+			SELECT id, currency
+			FROM expenses
+			WHERE group_id = $1
+				AND ($2::DATE IS NULL OR COALESCE(date_only, transaction_timestamp::DATE) <= $2::DATE)
+		),
+		paid AS (
+			SELECT p.expense_id, p.user_id, SUM(p.amount_paid) AS amount
+			FROM expense_payers p
+			JOIN group_expenses e ON e.id = p.expense_id
+			GROUP BY p.expense_id, p.user_id
+		),
+		totals AS (
+			SELECT expense_id, SUM(amount) AS total_paid
+			FROM paid
+			GROUP BY expense_id
+			HAVING SUM(amount) <> 0
+		),
+		owed AS (
+			SELECT s.expense_id, s.user_id, SUM(s.amount) AS amount
+			FROM expense_splits s
+			JOIN group_expenses e ON e.id = s.expense_id
+			GROUP BY s.expense_id, s.user_id
+		),
+		people AS (
+			SELECT expense_id, user_id FROM paid
+			UNION
+			SELECT expense_id, user_id FROM owed
+		)
+		SELECT e.currency, a.user_id, b.user_id,
+			SUM((COALESCE(pa.amount, 0) * COALESCE(ob.amount, 0)
+				- COALESCE(pb.amount, 0) * COALESCE(oa.amount, 0)) / t.total_paid) AS net
+		FROM totals t
+		JOIN group_expenses e ON e.id = t.expense_id
+		JOIN people a ON a.expense_id = t.expense_id
+		JOIN people b ON b.expense_id = t.expense_id AND a.user_id COLLATE "C" < b.user_id COLLATE "C"
+		LEFT JOIN paid pa ON pa.expense_id = t.expense_id AND pa.user_id = a.user_id
+		LEFT JOIN paid pb ON pb.expense_id = t.expense_id AND pb.user_id = b.user_id
+		LEFT JOIN owed oa ON oa.expense_id = t.expense_id AND oa.user_id = a.user_id
+		LEFT JOIN owed ob ON ob.expense_id = t.expense_id AND ob.user_id = b.user_id
+		GROUP BY e.currency, a.user_id, b.user_id
+		HAVING ROUND(SUM((COALESCE(pa.amount, 0) * COALESCE(ob.amount, 0)
+			- COALESCE(pb.amount, 0) * COALESCE(oa.amount, 0)) / t.total_paid)::NUMERIC, 2) <> 0`
+
+	rows, err := r.getQuerier().Query(ctx, query, groupID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("getting group pair balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := []models.PairBalance{}
+	for rows.Next() {
+		var b models.PairBalance
+		if err := rows.Scan(&b.Currency, &b.UserA, &b.UserB, &b.Net); err != nil {
+			return nil, fmt.Errorf("scanning pair balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// UserA sorts before UserB; LastSettledAt is the latest payment between them.
+func (r *expenseRepository) GetPairLedgers(ctx context.Context, groupID, currency string, userIDs []string) ([]models.PairLedger, error) {
+	if len(userIDs) < 2 {
+		return []models.PairLedger{}, nil
+	}
+
+	query := `
+		WITH group_expenses AS (
+			SELECT id, category, transaction_timestamp
+			FROM expenses
+			WHERE group_id = $1 AND currency = $2
+		),
+		paid AS (
+			SELECT p.expense_id, p.user_id, SUM(p.amount_paid) AS amount
+			FROM expense_payers p
+			JOIN group_expenses e ON e.id = p.expense_id
+			GROUP BY p.expense_id, p.user_id
+		),
+		totals AS (
+			SELECT expense_id, SUM(amount) AS total_paid
+			FROM paid
+			GROUP BY expense_id
+			HAVING SUM(amount) <> 0
+		),
+		owed AS (
+			SELECT s.expense_id, s.user_id, SUM(s.amount) AS amount
+			FROM expense_splits s
+			JOIN group_expenses e ON e.id = s.expense_id
+			WHERE s.user_id = ANY($3)
+			GROUP BY s.expense_id, s.user_id
+		),
+		pairs AS (
+			SELECT a.id AS user_a, b.id AS user_b
+			FROM unnest($3::TEXT[]) AS a(id)
+			JOIN unnest($3::TEXT[]) AS b(id) ON a.id COLLATE "C" < b.id COLLATE "C"
+		)
+		SELECT pairs.user_a, pairs.user_b,
+			SUM((COALESCE(pa.amount, 0) * COALESCE(ob.amount, 0)
+				- COALESCE(pb.amount, 0) * COALESCE(oa.amount, 0)) / t.total_paid),
+			MAX(e.transaction_timestamp) FILTER (
+				WHERE e.category IN ('PAYMENT', 'REPAYMENT')
+					AND ((pa.amount IS NOT NULL AND ob.amount IS NOT NULL)
+						OR (pb.amount IS NOT NULL AND oa.amount IS NOT NULL))
+			)
+		FROM pairs
+		CROSS JOIN totals t
+		JOIN group_expenses e ON e.id = t.expense_id
+		LEFT JOIN paid pa ON pa.expense_id = t.expense_id AND pa.user_id = pairs.user_a
+		LEFT JOIN paid pb ON pb.expense_id = t.expense_id AND pb.user_id = pairs.user_b
+		LEFT JOIN owed oa ON oa.expense_id = t.expense_id AND oa.user_id = pairs.user_a
+		LEFT JOIN owed ob ON ob.expense_id = t.expense_id AND ob.user_id = pairs.user_b
+		WHERE (pa.amount IS NOT NULL OR oa.amount IS NOT NULL)
+			AND (pb.amount IS NOT NULL OR ob.amount IS NOT NULL)
+		GROUP BY pairs.user_a, pairs.user_b`
+
+	rows, err := r.getQuerier().Query(ctx, query, groupID, currency, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("getting pair ledgers: %w", err)
+	}
+	defer rows.Close()
+
+	ledgers := []models.PairLedger{}
+	for rows.Next() {
+		var l models.PairLedger
+		if err := rows.Scan(&l.UserA, &l.UserB, &l.Net, &l.LastSettledAt); err != nil {
+			return nil, fmt.Errorf("scanning pair ledger: %w", err)
+		}
+		ledgers = append(ledgers, l)
+	}
+	return ledgers, rows.Err()
+}
+
+// GetSharedTransactions returns the transactions in groupIDs where both users
+// paid or owe something, oldest first. Net is left for the caller to work out.
+func (r *expenseRepository) GetSharedTransactions(ctx context.Context, userID, friendID string, groupIDs []string) ([]models.SharedTransaction, error) {
+	if len(groupIDs) == 0 {
+		return []models.SharedTransaction{}, nil
+	}
+
+	query := `
+		WITH paid AS (
+			SELECT expense_id,
+				SUM(amount_paid) AS total_paid,
+				SUM(amount_paid) FILTER (WHERE user_id = $1) AS user_paid,
+				SUM(amount_paid) FILTER (WHERE user_id = $2) AS friend_paid
+			FROM expense_payers
+			GROUP BY expense_id
+		),
+		owed AS (
+			SELECT expense_id,
+				SUM(amount) FILTER (WHERE user_id = $1) AS user_share,
+				SUM(amount) FILTER (WHERE user_id = $2) AS friend_share
+			FROM expense_splits
+			WHERE user_id IN ($1, $2)
+			GROUP BY expense_id
+		)
+		SELECT e.id, COALESCE(e.date_only::TEXT, e.transaction_timestamp::DATE::TEXT), g.id, g.name,
+			e.description, e.category, e.currency, e.total_amount,
+			COALESCE(paid.total_paid, 0), COALESCE(paid.user_paid, 0), COALESCE(paid.friend_paid, 0),
+			COALESCE(owed.user_share, 0), COALESCE(owed.friend_share, 0)
+		FROM expenses e
+		JOIN groups g ON g.id = e.group_id
+		LEFT JOIN paid ON paid.expense_id = e.id
+		LEFT JOIN owed ON owed.expense_id = e.id
+		WHERE e.group_id = ANY($3)
+			AND (paid.user_paid IS NOT NULL OR owed.user_share IS NOT NULL)
+			AND (paid.friend_paid IS NOT NULL OR owed.friend_share IS NOT NULL)
+		ORDER BY e.transaction_timestamp ASC, e.created_at ASC`
+
+	rows, err := r.getQuerier().Query(ctx, query, userID, friendID, groupIDs)
+	if err != nil {
+		return nil, fmt.Errorf("getting shared transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []models.SharedTransaction{}
+	for rows.Next() {
+		var t models.SharedTransaction
+		if err := rows.Scan(
+			&t.ExpenseID, &t.Date, &t.GroupID, &t.GroupName,
+			&t.Description, &t.Category, &t.Currency, &t.TotalAmount,
+			&t.TotalPaid, &t.UserPaid, &t.FriendPaid,
+			&t.UserShare, &t.FriendShare,
+		); err != nil {
+			return nil, fmt.Errorf("scanning shared transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+// GetSharedBalanceChanges sums, per period and currency, what the friend owes
+// the user from the transactions they share in groupIDs. Each transaction is
+// attributed as in GetSharedTransactions: a person's share is owed to the
+// payers in proportion to what they paid. granularity is a date_trunc unit;
+// periods start offsetDays after its ISO boundaries (see
+// models.ReportSettings.PeriodOffsetDays).
+func (r *expenseRepository) GetSharedBalanceChanges(ctx context.Context, userID, friendID string, groupIDs []string, granularity string, offsetDays int) ([]models.BalanceHistoryBucket, error) {
+	if len(groupIDs) == 0 {
+		return []models.BalanceHistoryBucket{}, nil
+	}
+
+	query := `
+		WITH paid AS (
+			SELECT expense_id,
+				SUM(amount_paid) AS total_paid,
+				SUM(amount_paid) FILTER (WHERE user_id = $1) AS user_paid,
+				SUM(amount_paid) FILTER (WHERE user_id = $2) AS friend_paid
+			FROM expense_payers
+			GROUP BY expense_id
+		),
+		owed AS (
+			SELECT expense_id,
+				SUM(amount) FILTER (WHERE user_id = $1) AS user_share,
+				SUM(amount) FILTER (WHERE user_id = $2) AS friend_share
+			FROM expense_splits
+			WHERE user_id IN ($1, $2)
+			GROUP BY expense_id
+		)
+		SELECT (date_trunc($4::TEXT, (COALESCE(e.date_only, e.transaction_timestamp::DATE) - $5::INT)::TIMESTAMP)
+			+ make_interval(days => $5::INT))::DATE AS period,
+			e.currency,
+			SUM((COALESCE(paid.user_paid, 0) * COALESCE(owed.friend_share, 0)
+				- COALESCE(paid.friend_paid, 0) * COALESCE(owed.user_share, 0)) / paid.total_paid),
+			COUNT(*)
+		FROM expenses e
+		JOIN paid ON paid.expense_id = e.id
+		LEFT JOIN owed ON owed.expense_id = e.id
+		WHERE e.group_id = ANY($3)
+			AND paid.total_paid <> 0
+			AND (paid.user_paid IS NOT NULL OR owed.user_share IS NOT NULL)
+			AND (paid.friend_paid IS NOT NULL OR owed.friend_share IS NOT NULL)
+		GROUP BY period, e.currency
+		ORDER BY period, e.currency`
+
+	rows, err := r.getQuerier().Query(ctx, query, userID, friendID, groupIDs, granularity, offsetDays)
+	if err != nil {
+		return nil, fmt.Errorf("getting shared balance changes: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []models.BalanceHistoryBucket{}
+	for rows.Next() {
+		var b models.BalanceHistoryBucket
+		if err := rows.Scan(&b.Period, &b.Currency, &b.Change, &b.Transactions); err != nil {
+			return nil, fmt.Errorf("scanning shared balance change: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
 func (r *expenseRepository) GetPairwiseBalancesAllFriends(ctx context.Context, userID string) (map[string]map[string]float64, error) {
 	groupQuery := `SELECT group_id FROM group_members WHERE user_id = $1`
 	groupRows, err := r.getQuerier().Query(ctx, groupQuery, userID)
@@ -990,12 +1446,74 @@ func (r *expenseRepository) GetGroupMemberBalancesBatch(ctx context.Context, gro
 }
 
 func (r *expenseRepository) GetGroupTotalSpend(ctx context.Context, groupID string) (float64, error) {
-	query := `SELECT COALESCE(SUM(total_amount), 0) FROM expenses WHERE group_id = $1 AND category = 'EXPENSE'`
+	query := `SELECT COALESCE(SUM(total_amount), 0) FROM expenses WHERE group_id = $1 AND category IN ('EXPENSE', 'REFUND')`
 	var total float64
-	err := r.db.Pool.QueryRow(ctx, query, groupID).Scan(&total)
+	err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&total)
 	return total, err
 }
 
+func (r *expenseRepository) GetGroupSpendByCurrency(ctx context.Context, groupID string) ([]models.CurrencySpend, error) {
+	query := `SELECT currency,
+	                 COALESCE(SUM(total_amount) FILTER (WHERE category IN ('EXPENSE', 'REFUND')), 0),
+	                 COUNT(*)
+	          FROM expenses
+	          WHERE group_id = $1
+	          GROUP BY currency
+	          ORDER BY currency`
+
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("getting group spend by currency: %w", err)
+	}
+	defer rows.Close()
+
+	var spend []models.CurrencySpend
+	for rows.Next() {
+		var cs models.CurrencySpend
+		if err := rows.Scan(&cs.Currency, &cs.TotalSpend, &cs.TransactionCount); err != nil {
+			return nil, fmt.Errorf("scanning currency spend: %w", err)
+		}
+		spend = append(spend, cs)
+	}
+	return spend, rows.Err()
+}
+
+// LockForUpdate locks the expense row until the transaction ends, so writes
+// that depend on its current state, like refunds, run one at a time.
+func (r *expenseRepository) LockForUpdate(ctx context.Context, id string) error {
+	var lockedID string
+	query := `SELECT id FROM expenses WHERE id = $1 FOR UPDATE`
+
+	if err := r.getQuerier().QueryRow(ctx, query, id).Scan(&lockedID); err != nil {
+		return fmt.Errorf("locking expense: %w", err)
+	}
+	return nil
+}
+
+func (r *expenseRepository) GetRefundedAmount(ctx context.Context, originalExpenseID string) (float64, error) {
+	query := `SELECT COALESCE(SUM(-total_amount), 0) FROM expenses WHERE original_expense_id = $1 AND category = 'REFUND'`
+	var total float64
+	if err := r.getQuerier().QueryRow(ctx, query, originalExpenseID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("getting refunded amount: %w", err)
+	}
+	return total, nil
+}
+
+// HasSettledExpense reports whether userID has a payment settling expenseID
+// that hasn't been reversed.
+func (r *expenseRepository) HasSettledExpense(ctx context.Context, expenseID, userID string) (bool, error) {
+	query := `SELECT EXISTS (
+	              SELECT 1 FROM expenses p
+	              WHERE p.settles_expense_id = $1 AND p.paid_by_user_id = $2 AND p.category = 'PAYMENT'
+	              AND NOT EXISTS (SELECT 1 FROM expenses r WHERE r.reverses_expense_id = p.id)
+	          )`
+	var settled bool
+	if err := r.getQuerier().QueryRow(ctx, query, expenseID, userID).Scan(&settled); err != nil {
+		return false, fmt.Errorf("checking expense settlement: %w", err)
+	}
+	return settled, nil
+}
+
 func (r *expenseRepository) TransferExpenses(ctx context.Context, fromUserID, toUserID string) error {
 	payerQuery := `UPDATE expense_payers SET user_id = $1 WHERE user_id = $2`
 	_, err := r.getQuerier().Exec(ctx, payerQuery, toUserID, fromUserID)
@@ -1017,3 +1535,135 @@ func (r *expenseRepository) TransferExpenses(ctx context.Context, fromUserID, to
 
 	return nil
 }
+
+// TransferGroupExpenses is TransferExpenses limited to one group's
+// transactions, used when a departing member is replaced by a placeholder.
+func (r *expenseRepository) TransferGroupExpenses(ctx context.Context, groupID, fromUserID, toUserID string) error {
+	payerQuery := `UPDATE expense_payers SET user_id = $1
+	               WHERE user_id = $2 AND expense_id IN (SELECT id FROM expenses WHERE group_id = $3)`
+	if _, err := r.getQuerier().Exec(ctx, payerQuery, toUserID, fromUserID, groupID); err != nil {
+		return fmt.Errorf("transferring group expense payers: %w", err)
+	}
+
+	splitQuery := `UPDATE expense_splits SET user_id = $1
+	               WHERE user_id = $2 AND expense_id IN (SELECT id FROM expenses WHERE group_id = $3)`
+	if _, err := r.getQuerier().Exec(ctx, splitQuery, toUserID, fromUserID, groupID); err != nil {
+		return fmt.Errorf("transferring group expense splits: %w", err)
+	}
+
+	expenseQuery := `UPDATE expenses SET paid_by_user_id = $1 WHERE paid_by_user_id = $2 AND group_id = $3`
+	if _, err := r.getQuerier().Exec(ctx, expenseQuery, toUserID, fromUserID, groupID); err != nil {
+		return fmt.Errorf("transferring group expenses paid_by: %w", err)
+	}
+
+	return nil
+}
+
+func (r *expenseRepository) CountGroupExpensesSince(ctx context.Context, groupID string, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM expenses WHERE group_id = $1 AND category = 'EXPENSE' AND created_at >= $2`
+	var count int
+	if err := r.getQuerier().QueryRow(ctx, query, groupID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting group expenses: %w", err)
+	}
+	return count, nil
+}
+
+func (r *expenseRepository) GetGroupSpendingBetween(ctx context.Context, groupID string, from, to time.Time) ([]models.Expense, error) {
+	query := `SELECT id, group_id, total_amount, currency, description, category, original_expense_id, transaction_timestamp
+	          FROM expenses
+	          WHERE group_id = $1 AND category IN ('EXPENSE', 'REFUND')
+	            AND transaction_timestamp >= $2 AND transaction_timestamp < $3
+	          ORDER BY transaction_timestamp ASC, created_at ASC`
+
+	rows, err := r.getQuerier().Query(ctx, query, groupID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting group spending: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := []models.Expense{}
+	expenseIDs := make([]string, 0)
+	for rows.Next() {
+		var expense models.Expense
+		if err := rows.Scan(
+			&expense.ID, &expense.GroupID, &expense.TotalAmount, &expense.Currency, &expense.Description,
+			&expense.Category, &expense.OriginalExpenseID, &expense.DateISO,
+		); err != nil {
+			return nil, fmt.Errorf("scanning expense: %w", err)
+		}
+		expenses = append(expenses, expense)
+		expenseIDs = append(expenseIDs, expense.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating expenses: %w", err)
+	}
+
+	allSplits, err := r.GetSplitsByExpenseIDs(ctx, expenseIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range expenses {
+		expenses[i].Splits = allSplits[expenses[i].ID]
+	}
+	return expenses, nil
+}
+
+// receiptImagesQuery lists every receipt and settlement proof in group $1,
+// one row per image.
+const receiptImagesQuery = `
+	SELECT e.id, 'RECEIPT' AS kind, e.receipt_image_path AS image_path, e.description, e.category, e.total_amount,
+	       e.currency, e.transaction_timestamp, e.event_id, e.paid_by_user_id, e.created_at
+	FROM expenses e
+	WHERE e.group_id = $1 AND COALESCE(e.receipt_image_path, '') <> ''
+	UNION ALL
+	SELECT e.id, 'SETTLEMENT_PROOF', e.settlement_proof_path, e.description, e.category, e.total_amount,
+	       e.currency, e.transaction_timestamp, e.event_id, e.paid_by_user_id, e.created_at
+	FROM expenses e
+	WHERE e.group_id = $1 AND COALESCE(e.settlement_proof_path, '') <> ''
+`
+
+// GetReceiptsByGroupID returns one page of the group's receipt images, newest
+// transaction first, and the total number of images.
+func (r *expenseRepository) GetReceiptsByGroupID(ctx context.Context, groupID string, limit, offset int) ([]models.ReceiptGalleryItem, int, error) {
+	var total int
+	if err := r.getQuerier().QueryRow(ctx, `SELECT COUNT(*) FROM (`+receiptImagesQuery+`) i`, groupID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting receipts: %w", err)
+	}
+
+	query := `SELECT i.id, i.kind, i.image_path, i.description, i.category, i.total_amount, i.currency,
+	          i.transaction_timestamp, i.event_id, i.created_at,
+	          u.id, u.email, u.name, u.avatar_url
+	          FROM (` + receiptImagesQuery + `) i
+	          LEFT JOIN users u ON u.id = i.paid_by_user_id
+	          ORDER BY i.transaction_timestamp DESC, i.created_at DESC, i.id, i.kind
+	          LIMIT $2 OFFSET $3`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying receipts: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ReceiptGalleryItem{}
+	for rows.Next() {
+		var item models.ReceiptGalleryItem
+		var userID, userEmail, userName, userAvatarURL sql.NullString
+		if err := rows.Scan(
+			&item.ExpenseID, &item.Kind, &item.ImagePath, &item.Description, &item.Category, &item.TotalAmount, &item.Currency,
+			&item.Date, &item.EventID, &item.CreatedAt,
+			&userID, &userEmail, &userName, &userAvatarURL,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scanning receipt: %w", err)
+		}
+		if userID.Valid {
+			item.PaidBy = &models.User{ID: userID.String, Email: userEmail.String, Name: userName.String}
+			if userAvatarURL.Valid {
+				item.PaidBy.AvatarURL = &userAvatarURL.String
+			}
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterating receipts: %w", err)
+	}
+	return items, total, nil
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// countingQuerier answers every query with the rows its respond func picks
+// and counts the queries it was asked to run.
+type countingQuerier struct {
+	queries int
+	respond func(sql string) [][]interface{}
+}
+
+func (q *countingQuerier) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	q.queries++
+	return pgconn.CommandTag{}, nil
+}
+
+func (q *countingQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	q.queries++
+	return &fakeRows{rows: q.respond(sql), index: -1}, nil
+}
+
+func (q *countingQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	q.queries++
+	return &fakeRows{rows: q.respond(sql), index: 0}
+}
+
+// fakeRows scans each value into the destination at the same position. Nil
+// values leave the destination untouched.
+type fakeRows struct {
+	rows  [][]interface{}
+	index int
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) Values() ([]interface{}, error)               { return r.rows[r.index], nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (r *fakeRows) Next() bool {
+	r.index++
+	return r.index < len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	if r.index >= len(r.rows) {
+		return pgx.ErrNoRows
+	}
+	for i, value := range r.rows[r.index] {
+		if value == nil || i >= len(dest) {
+			continue
+		}
+		target := reflect.ValueOf(dest[i]).Elem()
+		v := reflect.ValueOf(value)
+		if target.Kind() == reflect.Ptr {
+			ptr := reflect.New(target.Type().Elem())
+			ptr.Elem().Set(v.Convert(target.Type().Elem()))
+			target.Set(ptr)
+			continue
+		}
+		target.Set(v.Convert(target.Type()))
+	}
+	return nil
+}
+
+func TestGetByGroupIDQueryCount(t *testing.T) {
+	for _, count := range []int{1, 5, 40} {
+		t.Run(fmt.Sprintf("%d expenses", count), func(t *testing.T) {
+			q := &countingQuerier{respond: itemizedGroupRows(count)}
+			repo := &expenseRepository{tx: q}
+
+			expenses, err := repo.GetByGroupID(context.Background(), "group-1")
+			if err != nil {
+				t.Fatalf("GetByGroupID() error = %v", err)
+			}
+			if len(expenses) != count {
+				t.Fatalf("got %d expenses, want %d", len(expenses), count)
+			}
+			checkReceiptItems(t, expenses[len(expenses)-1].ReceiptItems)
+
+			// expenses, splits, payers, receipt items with assignments
+			if q.queries != 4 {
+				t.Errorf("GetByGroupID ran %d queries for %d expenses, want 4", q.queries, count)
+			}
+		})
+	}
+}
+
+func TestGetTransactionsByGroupIDQueryCount(t *testing.T) {
+	for _, count := range []int{1, 40} {
+		t.Run(fmt.Sprintf("%d transactions", count), func(t *testing.T) {
+			q := &countingQuerier{respond: itemizedGroupRows(count)}
+			repo := &expenseRepository{tx: q}
+
+			transactions, err := repo.GetTransactionsByGroupID(context.Background(), "group-1", models.TransactionSort{})
+			if err != nil {
+				t.Fatalf("GetTransactionsByGroupID() error = %v", err)
+			}
+			if len(transactions) != count {
+				t.Fatalf("got %d transactions, want %d", len(transactions), count)
+			}
+			checkReceiptItems(t, transactions[0].ReceiptItems)
+
+			if q.queries != 4 {
+				t.Errorf("GetTransactionsByGroupID ran %d queries for %d transactions, want 4", q.queries, count)
+			}
+		})
+	}
+}
+
+// itemizedGroupRows fakes a group of count expenses that each have two
+// receipt items, the first assigned to two members and the second to none.
+func itemizedGroupRows(count int) func(sql string) [][]interface{} {
+	return func(sql string) [][]interface{} {
+		switch {
+		case strings.Contains(sql, "FROM receipt_items"):
+			var rows [][]interface{}
+			for i := 0; i < count; i++ {
+				expenseID := fmt.Sprintf("expense-%d", i)
+				item := expenseID + "-item"
+				rows = append(rows,
+					[]interface{}{item + "-1", expenseID, "Pizza", 20.0, 1.0, nil, "assignment-a", "user-a", 1.0, nil},
+					[]interface{}{item + "-1", expenseID, "Pizza", 20.0, 1.0, nil, "assignment-b", "user-b", 1.0, nil},
+					[]interface{}{item + "-2", expenseID, "Water", 2.0, 1.0, nil, nil, nil, 0.0, nil},
+				)
+			}
+			return rows
+		case strings.Contains(sql, "FROM expenses"):
+			var rows [][]interface{}
+			for i := 0; i < count; i++ {
+				rows = append(rows, []interface{}{fmt.Sprintf("expense-%d", i), "group-1"})
+			}
+			return rows
+		default:
+			return nil
+		}
+	}
+}
+
+func checkReceiptItems(t *testing.T, items []models.ReceiptItem) {
+	t.Helper()
+	if len(items) != 2 {
+		t.Fatalf("got %d receipt items, want 2", len(items))
+	}
+	if got := len(items[0].Assignments); got != 2 {
+		t.Errorf("first item has %d assignments, want 2", got)
+	}
+	if items[1].Assignments == nil || len(items[1].Assignments) != 0 {
+		t.Errorf("second item assignments = %v, want an empty list", items[1].Assignments)
+	}
+}
+
+// datedExpense is one payer-to-ower leg of an expense for datedLedgerQuerier.
+type datedExpense struct {
+	date     string
+	currency string
+	payer    string
+	ower     string
+	amount   float64
+}
+
+// datedLedgerQuerier answers the group balance queries from in-memory
+// expenses, keeping those dated on or before the as_of the repository binds
+// as $2, as the SQL filter does.
+type datedLedgerQuerier struct {
+	countingQuerier
+	expenses []datedExpense
+	bound    []*time.Time
+}
+
+func (q *datedLedgerQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	q.queries++
+	if !strings.Contains(sql, "$2::DATE IS NULL OR COALESCE(") || !strings.Contains(sql, "::DATE) <= $2::DATE") {
+		return nil, fmt.Errorf("query has no as_of filter")
+	}
+	asOf, ok := args[1].(*time.Time)
+	if !ok {
+		return nil, fmt.Errorf("as_of bound as %T, expected *time.Time", args[1])
+	}
+	q.bound = append(q.bound, asOf)
+
+	var included []datedExpense
+	for _, e := range q.expenses {
+		date, err := time.Parse("2006-01-02", e.date)
+		if err != nil {
+			return nil, err
+		}
+		if asOf == nil || !date.After(*asOf) {
+			included = append(included, e)
+		}
+	}
+
+	var rows [][]interface{}
+	if strings.Contains(sql, "member_payments") {
+		balances := make(map[[2]string]float64)
+		for _, e := range included {
+			balances[[2]string{e.payer, e.currency}] += e.amount
+			balances[[2]string{e.ower, e.currency}] -= e.amount
+		}
+		for key, balance := range balances {
+			rows = append(rows, []interface{}{key[0], key[1], balance})
+		}
+	} else {
+		pairs := make(map[[3]string]float64)
+		for _, e := range included {
+			if e.payer < e.ower {
+				pairs[[3]string{e.currency, e.payer, e.ower}] += e.amount
+			} else {
+				pairs[[3]string{e.currency, e.ower, e.payer}] -= e.amount
+			}
+		}
+		for key, net := range pairs {
+			rows = append(rows, []interface{}{key[0], key[1], key[2], net})
+		}
+	}
+	return &fakeRows{rows: rows, index: -1}, nil
+}
+
+func TestGroupBalancesAsOf(t *testing.T) {
+	date := func(value string) *time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return &d
+	}
+	tests := []struct {
+		name     string
+		asOf     *time.Time
+		expected float64
+	}{
+		{name: "All Transactions", expected: 200},
+		{name: "End Of Trip", asOf: date("2024-05-31"), expected: 300},
+		{name: "Same Day Counts", asOf: date("2024-06-02"), expected: 200},
+		{name: "Before Anything", asOf: date("2024-05-01"), expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &datedLedgerQuerier{expenses: []datedExpense{
+				{date: "2024-05-20", currency: "INR", payer: "alice", ower: "bob", amount: 300},
+				{date: "2024-06-02", currency: "INR", payer: "bob", ower: "alice", amount: 100},
+			}}
+			repo := &expenseRepository{tx: q}
+
+			balances, err := repo.GetGroupMemberBalances(context.Background(), "group-1", tt.asOf)
+			if err != nil {
+				t.Fatalf("GetGroupMemberBalances() error = %v", err)
+			}
+			if got := balances["alice"]["INR"]; got != tt.expected {
+				t.Errorf("GetGroupMemberBalances() alice = %v, expected %v", got, tt.expected)
+			}
+
+			pairs, err := repo.GetGroupPairBalances(context.Background(), "group-1", tt.asOf)
+			if err != nil {
+				t.Fatalf("GetGroupPairBalances() error = %v", err)
+			}
+			var net float64
+			for _, p := range pairs {
+				if p.UserA == "alice" && p.UserB == "bob" && p.Currency == "INR" {
+					net = p.Net
+				}
+			}
+			if net != tt.expected {
+				t.Errorf("GetGroupPairBalances() alice/bob = %v, expected %v", net, tt.expected)
+			}
+
+			for _, bound := range q.bound {
+				if bound != tt.asOf {
+					t.Errorf("as_of bound as %v, expected %v", bound, tt.asOf)
+				}
+			}
+		})
+	}
+}
@@ -13,28 +13,44 @@ type FriendRepository interface {
 	Remove(ctx context.Context, userID, friendID string) error
 	List(ctx context.Context, userID string) ([]models.User, error)
 	IsFriend(ctx context.Context, userID, friendID string) (bool, error)
+	WithTx(tx database.Querier) FriendRepository
 }
 
 type friendRepository struct {
 	db *database.DB
+	tx database.Querier
 }
 
 func NewFriendRepository(db *database.DB) FriendRepository {
 	return &friendRepository{db: db}
 }
 
+func (r *friendRepository) WithTx(tx database.Querier) FriendRepository {
+	return &friendRepository{db: r.db, tx: tx}
+}
+
+func (r *friendRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+// Add stores a friendship as both edges, so each user sees the other.
 func (r *friendRepository) Add(ctx context.Context, userID, friendID string) error {
-	query := `INSERT INTO friends (user_id, friend_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
-	_, err := r.db.Pool.Exec(ctx, query, userID, friendID)
+	query := `INSERT INTO friends (user_id, friend_id) VALUES ($1, $2), ($2, $1) ON CONFLICT DO NOTHING`
+	_, err := r.getQuerier().Exec(ctx, query, userID, friendID)
 	if err != nil {
 		return fmt.Errorf("adding friend: %w", err)
 	}
 	return nil
 }
 
+// Remove ends a friendship for both users.
 func (r *friendRepository) Remove(ctx context.Context, userID, friendID string) error {
-	query := `DELETE FROM friends WHERE user_id = $1 AND friend_id = $2`
-	_, err := r.db.Pool.Exec(ctx, query, userID, friendID)
+	query := `DELETE FROM friends
+	          WHERE (user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1)`
+	_, err := r.getQuerier().Exec(ctx, query, userID, friendID)
 	if err != nil {
 		return fmt.Errorf("removing friend: %w", err)
 	}
@@ -49,7 +65,7 @@ func (r *friendRepository) List(ctx context.Context, userID string) ([]models.Us
 		WHERE f.user_id = $1
 		ORDER BY u.name ASC
 	`
-	rows, err := r.db.Pool.Query(ctx, query, userID)
+	rows, err := r.getQuerier().Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("listing friends: %w", err)
 	}
@@ -69,7 +85,7 @@ func (r *friendRepository) List(ctx context.Context, userID string) ([]models.Us
 func (r *friendRepository) IsFriend(ctx context.Context, userID, friendID string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM friends WHERE user_id = $1 AND friend_id = $2)`
 	var exists bool
-	err := r.db.Pool.QueryRow(ctx, query, userID, friendID).Scan(&exists)
+	err := r.getQuerier().QueryRow(ctx, query, userID, friendID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("checking friendship: %w", err)
 	}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// friendsTable is an in-memory friends table that runs the Add and Remove
+// statements: each "($a, $b)" VALUES tuple inserts an edge and each
+// "(user_id = $a AND friend_id = $b)" condition deletes one.
+type friendsTable struct {
+	countingQuerier
+	edges map[[2]string]bool
+}
+
+var (
+	valuesTuple  = regexp.MustCompile(`\(\$(\d+), \$(\d+)\)`)
+	deletedEdges = regexp.MustCompile(`user_id = \$(\d+) AND friend_id = \$(\d+)`)
+)
+
+func (f *friendsTable) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	arg := func(n string) string {
+		i, _ := strconv.Atoi(n)
+		return args[i-1].(string)
+	}
+	switch {
+	case strings.HasPrefix(sql, "INSERT INTO friends"):
+		for _, m := range valuesTuple.FindAllStringSubmatch(sql, -1) {
+			f.edges[[2]string{arg(m[1]), arg(m[2])}] = true
+		}
+	case strings.HasPrefix(sql, "DELETE FROM friends"):
+		for _, m := range deletedEdges.FindAllStringSubmatch(sql, -1) {
+			delete(f.edges, [2]string{arg(m[1]), arg(m[2])})
+		}
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func TestFriendshipsAreSymmetric(t *testing.T) {
+	table := &friendsTable{edges: map[[2]string]bool{{"carol", "alice"}: true}}
+	repo := (&friendRepository{}).WithTx(table)
+	ctx := context.Background()
+
+	if err := repo.Add(ctx, "alice", "bob"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if !table.edges[[2]string{"alice", "bob"}] || !table.edges[[2]string{"bob", "alice"}] {
+		t.Errorf("Add() edges = %v, expected alice->bob and bob->alice", table.edges)
+	}
+
+	if err := repo.Remove(ctx, "alice", "carol"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if table.edges[[2]string{"carol", "alice"}] {
+		t.Errorf("Remove() left carol->alice, expected the friendship removed for both users")
+	}
+	if len(table.edges) != 2 {
+		t.Errorf("Remove() edges = %v, expected alice and bob untouched", table.edges)
+	}
+}
+
+func TestCountOrphansReportsAsymmetricFriendships(t *testing.T) {
+	q := &countingQuerier{respond: func(sql string) [][]interface{} {
+		if strings.Contains(sql, "r.user_id = f.friend_id AND r.friend_id = f.user_id") {
+			return [][]interface{}{{int64(3)}}
+		}
+		return [][]interface{}{{int64(0)}}
+	}}
+	repo := (&integrityRepository{}).WithTx(q)
+
+	checks, err := repo.CountOrphans(context.Background())
+	if err != nil {
+		t.Fatalf("CountOrphans() error = %v", err)
+	}
+	for _, c := range checks {
+		if c.Table == "friends" && c.Check == "asymmetric" {
+			if c.Count != 3 {
+				t.Errorf("CountOrphans() friends.asymmetric = %d, expected 3", c.Count)
+			}
+			return
+		}
+	}
+	t.Error("CountOrphans() has no friends.asymmetric check")
+}
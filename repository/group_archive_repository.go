@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+// GroupArchiveRepository stores each member's archived groups and dismissed
+// archive suggestions. Both only hold until the group's next transaction.
+type GroupArchiveRepository interface {
+	Archive(ctx context.Context, groupID, userID string) error
+	Unarchive(ctx context.Context, groupID, userID string) error
+	DismissSuggestion(ctx context.Context, groupID, userID string) error
+	GetArchivedGroupIDs(ctx context.Context, userID string) (map[string]bool, error)
+	GetSuggestions(ctx context.Context, userID string, inactiveSince time.Time) ([]models.ArchiveSuggestion, error)
+	WithTx(tx database.Querier) GroupArchiveRepository
+}
+
+type groupArchiveRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewGroupArchiveRepository(db *database.DB) GroupArchiveRepository {
+	return &groupArchiveRepository{db: db}
+}
+
+func (r *groupArchiveRepository) WithTx(tx database.Querier) GroupArchiveRepository {
+	return &groupArchiveRepository{db: r.db, tx: tx}
+}
+
+func (r *groupArchiveRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+// groupActivityCTE computes last_activity_at for the groups of user $1, the
+// same way the dashboard orders them.
+const groupActivityCTE = `
+	WITH activity AS (
+		SELECT g.id AS group_id, COALESCE(MAX(e.created_at), g.updated_at) AS last_activity_at
+		FROM groups g
+		JOIN group_members gm ON gm.group_id = g.id AND gm.user_id = $1
+		LEFT JOIN expenses e ON e.group_id = g.id
+		GROUP BY g.id, g.updated_at
+	)
+`
+
+func (r *groupArchiveRepository) Archive(ctx context.Context, groupID, userID string) error {
+	query := `UPDATE group_members SET archived_at = NOW() WHERE group_id = $1 AND user_id = $2`
+	if _, err := r.getQuerier().Exec(ctx, query, groupID, userID); err != nil {
+		return fmt.Errorf("archiving group: %w", err)
+	}
+	return nil
+}
+
+// Unarchive also dismisses the suggestion, so a group the user just brought
+// back is not immediately offered for archiving again.
+func (r *groupArchiveRepository) Unarchive(ctx context.Context, groupID, userID string) error {
+	query := `
+		UPDATE group_members SET archived_at = NULL, archive_suggestion_dismissed_at = NOW()
+		WHERE group_id = $1 AND user_id = $2
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, groupID, userID); err != nil {
+		return fmt.Errorf("unarchiving group: %w", err)
+	}
+	return nil
+}
+
+func (r *groupArchiveRepository) DismissSuggestion(ctx context.Context, groupID, userID string) error {
+	query := `UPDATE group_members SET archive_suggestion_dismissed_at = NOW() WHERE group_id = $1 AND user_id = $2`
+	if _, err := r.getQuerier().Exec(ctx, query, groupID, userID); err != nil {
+		return fmt.Errorf("dismissing archive suggestion: %w", err)
+	}
+	return nil
+}
+
+// GetArchivedGroupIDs returns the user's groups that are archived and have
+// had no transactions since.
+func (r *groupArchiveRepository) GetArchivedGroupIDs(ctx context.Context, userID string) (map[string]bool, error) {
+	query := groupActivityCTE + `
+		SELECT gm.group_id
+		FROM group_members gm
+		JOIN activity a ON a.group_id = gm.group_id
+		WHERE gm.user_id = $1 AND gm.archived_at >= a.last_activity_at
+	`
+	rows, err := r.getQuerier().Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying archived groups: %w", err)
+	}
+	defer rows.Close()
+
+	archived := make(map[string]bool)
+	for rows.Next() {
+		var groupID string
+		if err := rows.Scan(&groupID); err != nil {
+			return nil, fmt.Errorf("scanning archived group: %w", err)
+		}
+		archived[groupID] = true
+	}
+	return archived, rows.Err()
+}
+
+// GetSuggestions returns the user's visible groups with no activity since
+// inactiveSince where no current member has an open balance, skipping ones
+// whose suggestion was dismissed after their last transaction.
+func (r *groupArchiveRepository) GetSuggestions(ctx context.Context, userID string, inactiveSince time.Time) ([]models.ArchiveSuggestion, error) {
+	query := groupActivityCTE + `
+		SELECT g.id, g.name, g.avatar_url, a.last_activity_at
+		FROM group_members gm
+		JOIN groups g ON g.id = gm.group_id
+		JOIN activity a ON a.group_id = gm.group_id
+		WHERE gm.user_id = $1
+		  AND a.last_activity_at < $2
+		  AND (gm.archived_at IS NULL OR gm.archived_at < a.last_activity_at)
+		  AND (gm.archive_suggestion_dismissed_at IS NULL OR gm.archive_suggestion_dismissed_at < a.last_activity_at)
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_balance_metrics m
+			JOIN group_members mm ON mm.group_id = m.group_id AND mm.user_id = m.user_id
+			WHERE m.group_id = gm.group_id AND ABS(m.net) > 0.01
+		  )
+		ORDER BY a.last_activity_at
+	`
+	rows, err := r.getQuerier().Query(ctx, query, userID, inactiveSince)
+	if err != nil {
+		return nil, fmt.Errorf("querying archive suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []models.ArchiveSuggestion{}
+	for rows.Next() {
+		var s models.ArchiveSuggestion
+		if err := rows.Scan(&s.GroupID, &s.Name, &s.AvatarURL, &s.LastActivityAt); err != nil {
+			return nil, fmt.Errorf("scanning archive suggestion: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
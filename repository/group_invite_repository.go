@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+
+	"github.com/google/uuid"
+)
+
+type GroupInviteRepository interface {
+	Create(ctx context.Context, groupID, email, invitedBy string) (*models.GroupInvite, error)
+	GetPendingByEmailForUpdate(ctx context.Context, email string) ([]models.GroupInvite, error)
+	MarkAccepted(ctx context.Context, inviteID, userID string) error
+	WithTx(tx database.Querier) GroupInviteRepository
+}
+
+type groupInviteRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewGroupInviteRepository(db *database.DB) GroupInviteRepository {
+	return &groupInviteRepository{db: db}
+}
+
+func (r *groupInviteRepository) WithTx(tx database.Querier) GroupInviteRepository {
+	return &groupInviteRepository{db: r.db, tx: tx}
+}
+
+func (r *groupInviteRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *groupInviteRepository) Create(ctx context.Context, groupID, email, invitedBy string) (*models.GroupInvite, error) {
+	query := `
+		INSERT INTO group_invites (id, group_id, email, invited_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (group_id, LOWER(email)) WHERE accepted_at IS NULL
+		DO UPDATE SET invited_by = EXCLUDED.invited_by
+		RETURNING id, group_id, email, invited_by, created_at, accepted_at
+	`
+	var invite models.GroupInvite
+	err := r.getQuerier().QueryRow(ctx, query, uuid.New().String(), groupID, strings.TrimSpace(email), invitedBy).Scan(
+		&invite.ID, &invite.GroupID, &invite.Email, &invite.InvitedBy, &invite.CreatedAt, &invite.AcceptedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating group invite: %w", err)
+	}
+	return &invite, nil
+}
+
+func (r *groupInviteRepository) GetPendingByEmailForUpdate(ctx context.Context, email string) ([]models.GroupInvite, error) {
+	query := `
+		SELECT i.id, i.group_id, i.email, i.invited_by, i.created_at, i.accepted_at, g.name
+		FROM group_invites i
+		JOIN groups g ON g.id = i.group_id
+		WHERE LOWER(i.email) = LOWER($1) AND i.accepted_at IS NULL
+		ORDER BY i.created_at ASC
+		FOR UPDATE OF i
+	`
+	rows, err := r.getQuerier().Query(ctx, query, strings.TrimSpace(email))
+	if err != nil {
+		return nil, fmt.Errorf("querying pending group invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []models.GroupInvite{}
+	for rows.Next() {
+		var invite models.GroupInvite
+		if err := rows.Scan(
+			&invite.ID, &invite.GroupID, &invite.Email, &invite.InvitedBy, &invite.CreatedAt, &invite.AcceptedAt, &invite.GroupName,
+		); err != nil {
+			return nil, fmt.Errorf("scanning group invite: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+	return invites, rows.Err()
+}
+
+func (r *groupInviteRepository) MarkAccepted(ctx context.Context, inviteID, userID string) error {
+	query := `
+		UPDATE group_invites
+		SET accepted_at = NOW(), accepted_by = $2
+		WHERE id = $1 AND accepted_at IS NULL
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, inviteID, userID); err != nil {
+		return fmt.Errorf("accepting group invite: %w", err)
+	}
+	return nil
+}
@@ -17,7 +17,25 @@ type GroupRepository interface {
 	Update(ctx context.Context, group *models.Group) error
 	UpdateAvatarURL(ctx context.Context, groupID string, avatarURL string) error
 	UpdateDefaultCurrency(ctx context.Context, groupID string, currency string) error
+	GetLimits(ctx context.Context, groupID string) (*models.GroupLimits, error)
+	UpdateLimits(ctx context.Context, groupID string, limits *models.GroupLimits) error
+	GetExpenseEditPolicy(ctx context.Context, groupID string) (models.ExpenseEditPolicy, error)
+	UpdateExpenseEditPolicy(ctx context.Context, groupID string, policy models.ExpenseEditPolicy) error
+	GetSettlementRounding(ctx context.Context, groupID string) (int, error)
+	UpdateSettlementRounding(ctx context.Context, groupID string, increment int) error
+	GetSettlementAlgorithm(ctx context.Context, groupID string) (models.SettlementAlgorithm, error)
+	UpdateSettlementAlgorithm(ctx context.Context, groupID string, algorithm models.SettlementAlgorithm) error
+	GetDefaultLanguage(ctx context.Context, groupID string) (string, error)
+	UpdateDefaultLanguage(ctx context.Context, groupID string, language string) error
+	GetTaxPreset(ctx context.Context, groupID string) (models.TaxPreset, error)
+	IsSandbox(ctx context.Context, groupID string) (bool, error)
+	UpdateTaxPreset(ctx context.Context, groupID string, preset models.TaxPreset) error
+	GetUsage(ctx context.Context, groupID string, from, to time.Time) (*models.GroupUsage, error)
+	AddRecurringStub(ctx context.Context, stub *models.RecurringExpenseStub) error
+	GetRecurringStubs(ctx context.Context, groupID string) ([]models.RecurringExpenseStub, error)
 	Delete(ctx context.Context, id string) error
+	LockForShare(ctx context.Context, groupID string) error
+	LockForUpdate(ctx context.Context, groupID string) error
 	AddMember(ctx context.Context, groupID, userID string) error
 	RemoveMember(ctx context.Context, groupID, userID string) error
 	GetMembers(ctx context.Context, groupID string) ([]models.User, error)
@@ -49,10 +67,10 @@ func (r *groupRepository) getQuerier() database.Querier {
 
 func (r *groupRepository) GetByID(ctx context.Context, id string) (*models.Group, error) {
 	var group models.Group
-	query := `SELECT id, name, type, default_currency, avatar_url, created_at, updated_at FROM groups WHERE id = $1`
+	query := `SELECT id, name, type, default_currency, avatar_url, expense_edit_policy, settlement_rounding, settlement_algorithm, default_language, tax_preset, is_sandbox, created_at, updated_at FROM groups WHERE id = $1`
 
 	err := r.getQuerier().QueryRow(ctx, query, id).Scan(
-		&group.ID, &group.Name, &group.Type, &group.DefaultCurrency, &group.AvatarURL, &group.CreatedAt, &group.UpdatedAt,
+		&group.ID, &group.Name, &group.Type, &group.DefaultCurrency, &group.AvatarURL, &group.ExpenseEditPolicy, &group.SettlementRounding, &group.SettlementAlgorithm, &group.DefaultLanguage, &group.TaxPreset, &group.IsSandbox, &group.CreatedAt, &group.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("getting group by id: %w", err)
@@ -74,6 +92,7 @@ func (r *groupRepository) GetByUserID(ctx context.Context, userID string) ([]mod
 	          g.type, 
 	          g.default_currency,
 	          g.avatar_url,
+	          g.is_sandbox,
 	          g.created_at, 
 	          g.updated_at
 	          FROM groups g
@@ -93,7 +112,7 @@ func (r *groupRepository) GetByUserID(ctx context.Context, userID string) ([]mod
 
 	for rows.Next() {
 		var group models.Group
-		if err := rows.Scan(&group.ID, &group.Name, &group.Type, &group.DefaultCurrency, &group.AvatarURL, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		if err := rows.Scan(&group.ID, &group.Name, &group.Type, &group.DefaultCurrency, &group.AvatarURL, &group.IsSandbox, &group.CreatedAt, &group.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scanning group: %w", err)
 		}
 		group.Members = []models.User{}
@@ -146,10 +165,10 @@ func (r *groupRepository) Create(ctx context.Context, group *models.Group) error
 		groupType = models.GroupTypeOther
 	}
 
-	query := `INSERT INTO groups (id, name, type, created_at, updated_at)
-	          VALUES ($1, $2, $3, NOW(), NOW())`
+	query := `INSERT INTO groups (id, name, type, is_sandbox, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, NOW(), NOW())`
 
-	_, err := r.getQuerier().Exec(ctx, query, group.ID, group.Name, groupType)
+	_, err := r.getQuerier().Exec(ctx, query, group.ID, group.Name, groupType, group.IsSandbox)
 	if err != nil {
 		return fmt.Errorf("creating group: %w", err)
 	}
@@ -184,6 +203,176 @@ func (r *groupRepository) UpdateDefaultCurrency(ctx context.Context, groupID str
 	return nil
 }
 
+func (r *groupRepository) GetLimits(ctx context.Context, groupID string) (*models.GroupLimits, error) {
+	query := `SELECT max_expense_amount, max_daily_expenses, limit_action, default_currency FROM groups WHERE id = $1`
+	var limits models.GroupLimits
+	err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&limits.MaxExpenseAmount, &limits.MaxDailyExpenses, &limits.Action, &limits.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("getting group limits: %w", err)
+	}
+	return &limits, nil
+}
+
+func (r *groupRepository) UpdateLimits(ctx context.Context, groupID string, limits *models.GroupLimits) error {
+	query := `UPDATE groups SET max_expense_amount = $1, max_daily_expenses = $2, limit_action = $3, updated_at = NOW() WHERE id = $4`
+	_, err := r.getQuerier().Exec(ctx, query, limits.MaxExpenseAmount, limits.MaxDailyExpenses, limits.Action, groupID)
+	if err != nil {
+		return fmt.Errorf("updating group limits: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) GetExpenseEditPolicy(ctx context.Context, groupID string) (models.ExpenseEditPolicy, error) {
+	query := `SELECT expense_edit_policy FROM groups WHERE id = $1`
+	var policy models.ExpenseEditPolicy
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&policy); err != nil {
+		return "", fmt.Errorf("getting group expense edit policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (r *groupRepository) UpdateExpenseEditPolicy(ctx context.Context, groupID string, policy models.ExpenseEditPolicy) error {
+	query := `UPDATE groups SET expense_edit_policy = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.getQuerier().Exec(ctx, query, policy, groupID)
+	if err != nil {
+		return fmt.Errorf("updating group expense edit policy: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) GetSettlementRounding(ctx context.Context, groupID string) (int, error) {
+	query := `SELECT settlement_rounding FROM groups WHERE id = $1`
+	var increment int
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&increment); err != nil {
+		return 0, fmt.Errorf("getting group settlement rounding: %w", err)
+	}
+	return increment, nil
+}
+
+func (r *groupRepository) UpdateSettlementRounding(ctx context.Context, groupID string, increment int) error {
+	query := `UPDATE groups SET settlement_rounding = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.getQuerier().Exec(ctx, query, increment, groupID)
+	if err != nil {
+		return fmt.Errorf("updating group settlement rounding: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) GetDefaultLanguage(ctx context.Context, groupID string) (string, error) {
+	query := `SELECT default_language FROM groups WHERE id = $1`
+	var language string
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&language); err != nil {
+		return "", fmt.Errorf("getting group default language: %w", err)
+	}
+	return language, nil
+}
+
+func (r *groupRepository) UpdateDefaultLanguage(ctx context.Context, groupID string, language string) error {
+	query := `UPDATE groups SET default_language = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.getQuerier().Exec(ctx, query, language, groupID)
+	if err != nil {
+		return fmt.Errorf("updating group default language: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) GetSettlementAlgorithm(ctx context.Context, groupID string) (models.SettlementAlgorithm, error) {
+	query := `SELECT settlement_algorithm FROM groups WHERE id = $1`
+	var algorithm models.SettlementAlgorithm
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&algorithm); err != nil {
+		return "", fmt.Errorf("getting group settlement algorithm: %w", err)
+	}
+	return algorithm, nil
+}
+
+func (r *groupRepository) UpdateSettlementAlgorithm(ctx context.Context, groupID string, algorithm models.SettlementAlgorithm) error {
+	query := `UPDATE groups SET settlement_algorithm = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.getQuerier().Exec(ctx, query, algorithm, groupID)
+	if err != nil {
+		return fmt.Errorf("updating group settlement algorithm: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) GetTaxPreset(ctx context.Context, groupID string) (models.TaxPreset, error) {
+	query := `SELECT tax_preset FROM groups WHERE id = $1`
+	var preset models.TaxPreset
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&preset); err != nil {
+		return "", fmt.Errorf("getting group tax preset: %w", err)
+	}
+	return preset, nil
+}
+
+func (r *groupRepository) IsSandbox(ctx context.Context, groupID string) (bool, error) {
+	query := `SELECT is_sandbox FROM groups WHERE id = $1`
+	var sandbox bool
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&sandbox); err != nil {
+		return false, fmt.Errorf("getting group sandbox flag: %w", err)
+	}
+	return sandbox, nil
+}
+
+func (r *groupRepository) UpdateTaxPreset(ctx context.Context, groupID string, preset models.TaxPreset) error {
+	query := `UPDATE groups SET tax_preset = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.getQuerier().Exec(ctx, query, preset, groupID)
+	if err != nil {
+		return fmt.Errorf("updating group tax preset: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) GetUsage(ctx context.Context, groupID string, from, to time.Time) (*models.GroupUsage, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM expenses
+			 WHERE group_id = $1 AND category = 'EXPENSE' AND created_at >= $2 AND created_at < $3),
+			(SELECT COUNT(*) FROM comments c
+			 INNER JOIN expenses e ON e.id = c.expense_id
+			 WHERE e.group_id = $1 AND c.created_at >= $2 AND c.created_at < $3),
+			(SELECT COUNT(*) FILTER (WHERE kind = 'RECEIPT_SCAN') FROM ai_outputs
+			 WHERE group_id = $1 AND created_at >= $2 AND created_at < $3),
+			(SELECT COUNT(*) FILTER (WHERE kind = 'EXPLANATION') FROM ai_outputs
+			 WHERE group_id = $1 AND created_at >= $2 AND created_at < $3)
+	`
+	usage := models.GroupUsage{GroupID: groupID, PeriodStart: from, PeriodEnd: to}
+	err := r.getQuerier().QueryRow(ctx, query, groupID, from, to).
+		Scan(&usage.Expenses, &usage.Comments, &usage.ReceiptScans, &usage.AIExplanations)
+	if err != nil {
+		return nil, fmt.Errorf("getting group usage: %w", err)
+	}
+	return &usage, nil
+}
+
+func (r *groupRepository) AddRecurringStub(ctx context.Context, stub *models.RecurringExpenseStub) error {
+	query := `INSERT INTO recurring_expense_stubs (id, group_id, description, category, frequency, created_at)
+	          VALUES ($1, $2, $3, NULLIF($4, ''), $5, NOW())`
+	_, err := r.getQuerier().Exec(ctx, query, stub.ID, stub.GroupID, stub.Description, stub.Category, stub.Frequency)
+	if err != nil {
+		return fmt.Errorf("creating recurring expense stub: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) GetRecurringStubs(ctx context.Context, groupID string) ([]models.RecurringExpenseStub, error) {
+	query := `SELECT id, group_id, description, COALESCE(category, ''), frequency, created_at
+	          FROM recurring_expense_stubs WHERE group_id = $1 ORDER BY created_at, description`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying recurring expense stubs: %w", err)
+	}
+	defer rows.Close()
+
+	stubs := []models.RecurringExpenseStub{}
+	for rows.Next() {
+		var stub models.RecurringExpenseStub
+		if err := rows.Scan(&stub.ID, &stub.GroupID, &stub.Description, &stub.Category, &stub.Frequency, &stub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning recurring expense stub: %w", err)
+		}
+		stubs = append(stubs, stub)
+	}
+	return stubs, rows.Err()
+}
+
 func (r *groupRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM groups WHERE id = $1`
 
@@ -194,6 +383,32 @@ func (r *groupRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// LockForShare locks the group row until the transaction ends, so the group
+// cannot be deleted and no member removed while transactions are added to
+// it. Concurrent writers share the lock. It fails with pgx.ErrNoRows if the
+// group is gone.
+func (r *groupRepository) LockForShare(ctx context.Context, groupID string) error {
+	var id string
+	query := `SELECT id FROM groups WHERE id = $1 FOR SHARE`
+
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&id); err != nil {
+		return fmt.Errorf("locking group: %w", err)
+	}
+	return nil
+}
+
+// LockForUpdate locks the group row against LockForShare until the
+// transaction ends, for deleting the group or removing a member.
+func (r *groupRepository) LockForUpdate(ctx context.Context, groupID string) error {
+	var id string
+	query := `SELECT id FROM groups WHERE id = $1 FOR UPDATE`
+
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&id); err != nil {
+		return fmt.Errorf("locking group for update: %w", err)
+	}
+	return nil
+}
+
 func (r *groupRepository) AddMember(ctx context.Context, groupID, userID string) error {
 	query := `INSERT INTO group_members (group_id, user_id, created_at)
 	          VALUES ($1, $2, NOW())
@@ -220,7 +435,8 @@ func (r *groupRepository) GetMembers(ctx context.Context, groupID string) ([]mod
 	query := `SELECT u.id, COALESCE(u.email, ''), u.name, u.avatar_url, u.is_placeholder, u.claimed_by, u.claimed_at, u.created_at, u.updated_at
 	          FROM users u
 	          INNER JOIN group_members gm ON u.id = gm.user_id
-	          WHERE gm.group_id = $1`
+	          WHERE gm.group_id = $1
+	          ORDER BY gm.created_at, u.name, u.id`
 
 	rows, err := r.getQuerier().Query(ctx, query, groupID)
 	if err != nil {
@@ -259,12 +475,14 @@ func (r *groupRepository) GetGroupsWithLastActivity(ctx context.Context, userID
 	          g.id, 
 	          g.name,
 	          g.avatar_url,
+	          g.is_sandbox,
 	          COALESCE(MAX(e.created_at), g.updated_at) as last_activity_at
 	          FROM groups g
 	          INNER JOIN group_members gm ON g.id = gm.group_id
 	          LEFT JOIN expenses e ON g.id = e.group_id
 	          WHERE gm.user_id = $1
-	          GROUP BY g.id, g.name, g.avatar_url, g.updated_at
+	          GROUP BY g.id, g.name, g.avatar_url, g.is_sandbox, g.updated_at, gm.archived_at
+	          HAVING gm.archived_at IS NULL OR gm.archived_at < COALESCE(MAX(e.created_at), g.updated_at)
 	          ORDER BY last_activity_at DESC`
 
 	rows, err := r.getQuerier().Query(ctx, query, userID)
@@ -276,7 +494,7 @@ func (r *groupRepository) GetGroupsWithLastActivity(ctx context.Context, userID
 	var groups []models.DashboardGroup
 	for rows.Next() {
 		var group models.DashboardGroup
-		if err := rows.Scan(&group.ID, &group.Name, &group.AvatarURL, &group.LastActivityAt); err != nil {
+		if err := rows.Scan(&group.ID, &group.Name, &group.AvatarURL, &group.IsSandbox, &group.LastActivityAt); err != nil {
 			return nil, fmt.Errorf("scanning group: %w", err)
 		}
 		groups = append(groups, group)
@@ -305,7 +523,7 @@ func (r *groupRepository) GetGroupsDetailedByUserID(ctx context.Context, userID
 			GROUP BY e.group_id, s.user_id
 		)
 		SELECT 
-			g.id as g_id, g.name as g_name, g.type as g_type, g.avatar_url as g_avatar_url, 
+			g.id as g_id, g.name as g_name, g.type as g_type, g.avatar_url as g_avatar_url, g.is_sandbox as g_is_sandbox,
 			g.created_at as g_created_at, g.updated_at as g_updated_at,
 			u.id as u_id, COALESCE(u.email, '') as u_email, u.name as u_name, 
 			u.avatar_url as u_avatar_url, u.is_placeholder as u_is_placeholder,
@@ -335,11 +553,11 @@ func (r *groupRepository) GetGroupsDetailedByUserID(ctx context.Context, userID
 		var gAvatarURL, uAvatarURL, uClaimedBy *string
 		var gCreatedAt, gUpdatedAt, uCreatedAt, uUpdatedAt time.Time
 		var uClaimedAt *time.Time
-		var uIsPlaceholder bool
+		var gIsSandbox, uIsPlaceholder bool
 		var uBalance float64
 
 		if err := rows.Scan(
-			&gID, &gName, &gType, &gAvatarURL, &gCreatedAt, &gUpdatedAt,
+			&gID, &gName, &gType, &gAvatarURL, &gIsSandbox, &gCreatedAt, &gUpdatedAt,
 			&uID, &uEmail, &uName, &uAvatarURL, &uIsPlaceholder,
 			&uClaimedBy, &uClaimedAt, &uCreatedAt, &uUpdatedAt,
 			&uBalance,
@@ -354,6 +572,7 @@ func (r *groupRepository) GetGroupsDetailedByUserID(ctx context.Context, userID
 				Name:      gName,
 				Type:      models.GroupType(gType),
 				AvatarURL: gAvatarURL,
+				IsSandbox: gIsSandbox,
 				CreatedAt: gCreatedAt,
 				UpdatedAt: gUpdatedAt,
 				Members:   []models.User{},
@@ -388,13 +607,13 @@ func (r *groupRepository) GetGroupsDetailedByUserID(ctx context.Context, userID
 
 func (r *groupRepository) GetCommonGroups(ctx context.Context, userID1, userID2 string) ([]models.Group, error) {
 	query := `
-		SELECT g.id, g.name, g.avatar_url, g.created_at, g.updated_at, g.type
+		SELECT g.id, g.name, g.avatar_url, g.created_at, g.updated_at, g.type, g.is_sandbox
 		FROM groups g
 		JOIN group_members gm1 ON g.id = gm1.group_id
 		JOIN group_members gm2 ON g.id = gm2.group_id
 		WHERE gm1.user_id = $1 AND gm2.user_id = $2
 	`
-	rows, err := r.db.Pool.Query(ctx, query, userID1, userID2)
+	rows, err := r.getQuerier().Query(ctx, query, userID1, userID2)
 	if err != nil {
 		return nil, fmt.Errorf("getting common groups: %w", err)
 	}
@@ -403,7 +622,7 @@ func (r *groupRepository) GetCommonGroups(ctx context.Context, userID1, userID2
 	var groups []models.Group
 	for rows.Next() {
 		var g models.Group
-		if err := rows.Scan(&g.ID, &g.Name, &g.AvatarURL, &g.CreatedAt, &g.UpdatedAt, &g.Type); err != nil {
+		if err := rows.Scan(&g.ID, &g.Name, &g.AvatarURL, &g.CreatedAt, &g.UpdatedAt, &g.Type, &g.IsSandbox); err != nil {
 			return nil, fmt.Errorf("scanning group: %w", err)
 		}
 		groups = append(groups, g)
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type GroupShareLinkRepository interface {
+	Create(ctx context.Context, link *models.GroupShareLink, tokenHash string) error
+	GetByID(ctx context.Context, id string) (*models.GroupShareLink, error)
+	GetActiveByGroupID(ctx context.Context, groupID string) ([]models.GroupShareLink, error)
+	CountActive(ctx context.Context, groupID string) (int, error)
+	Revoke(ctx context.Context, id, revokedBy string) (bool, error)
+	ResolveToken(ctx context.Context, tokenHash string) (string, error)
+	WithTx(tx database.Querier) GroupShareLinkRepository
+}
+
+type groupShareLinkRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewGroupShareLinkRepository(db *database.DB) GroupShareLinkRepository {
+	return &groupShareLinkRepository{db: db}
+}
+
+func (r *groupShareLinkRepository) WithTx(tx database.Querier) GroupShareLinkRepository {
+	return &groupShareLinkRepository{db: r.db, tx: tx}
+}
+
+func (r *groupShareLinkRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+const groupShareLinkColumns = `id, group_id, created_by, created_at, last_viewed_at, revoked_at, revoked_by`
+
+func scanGroupShareLink(row interface{ Scan(dest ...any) error }, l *models.GroupShareLink) error {
+	return row.Scan(&l.ID, &l.GroupID, &l.CreatedBy, &l.CreatedAt, &l.LastViewedAt, &l.RevokedAt, &l.RevokedBy)
+}
+
+func (r *groupShareLinkRepository) Create(ctx context.Context, link *models.GroupShareLink, tokenHash string) error {
+	query := `
+		INSERT INTO group_share_links (id, group_id, token_hash, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING created_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query, link.ID, link.GroupID, tokenHash, link.CreatedBy).Scan(&link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating group share link: %w", err)
+	}
+	return nil
+}
+
+func (r *groupShareLinkRepository) GetByID(ctx context.Context, id string) (*models.GroupShareLink, error) {
+	query := `SELECT ` + groupShareLinkColumns + ` FROM group_share_links WHERE id = $1`
+	var link models.GroupShareLink
+	if err := scanGroupShareLink(r.getQuerier().QueryRow(ctx, query, id), &link); err != nil {
+		return nil, fmt.Errorf("getting group share link: %w", err)
+	}
+	return &link, nil
+}
+
+func (r *groupShareLinkRepository) GetActiveByGroupID(ctx context.Context, groupID string) ([]models.GroupShareLink, error) {
+	query := `SELECT ` + groupShareLinkColumns + `
+		FROM group_share_links
+		WHERE group_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("getting group share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.GroupShareLink{}
+	for rows.Next() {
+		var l models.GroupShareLink
+		if err := scanGroupShareLink(rows, &l); err != nil {
+			return nil, fmt.Errorf("scanning group share link: %w", err)
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+func (r *groupShareLinkRepository) CountActive(ctx context.Context, groupID string) (int, error) {
+	query := `SELECT COUNT(*) FROM group_share_links WHERE group_id = $1 AND revoked_at IS NULL`
+	var count int
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting group share links: %w", err)
+	}
+	return count, nil
+}
+
+func (r *groupShareLinkRepository) Revoke(ctx context.Context, id, revokedBy string) (bool, error) {
+	query := `UPDATE group_share_links SET revoked_at = NOW(), revoked_by = $2
+	          WHERE id = $1 AND revoked_at IS NULL`
+	tag, err := r.getQuerier().Exec(ctx, query, id, revokedBy)
+	if err != nil {
+		return false, fmt.Errorf("revoking group share link: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *groupShareLinkRepository) ResolveToken(ctx context.Context, tokenHash string) (string, error) {
+	query := `UPDATE group_share_links SET last_viewed_at = NOW()
+	          WHERE token_hash = $1 AND revoked_at IS NULL
+	          RETURNING group_id`
+	var groupID string
+	if err := r.getQuerier().QueryRow(ctx, query, tokenHash).Scan(&groupID); err != nil {
+		return "", fmt.Errorf("resolving group share token: %w", err)
+	}
+	return groupID, nil
+}
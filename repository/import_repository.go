@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+)
+
+type ImportRepository interface {
+	GetMemberMappings(ctx context.Context, groupID string) (map[string]string, error)
+	SaveMemberMappings(ctx context.Context, groupID string, mappings map[string]string) error
+	GetFingerprints(ctx context.Context, groupID string, fingerprints []string) (map[string]string, error)
+	AddFingerprint(ctx context.Context, groupID, fingerprint, expenseID string) error
+	WithTx(tx database.Querier) ImportRepository
+}
+
+type importRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewImportRepository(db *database.DB) ImportRepository {
+	return &importRepository{db: db}
+}
+
+func (r *importRepository) WithTx(tx database.Querier) ImportRepository {
+	return &importRepository{db: r.db, tx: tx}
+}
+
+func (r *importRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+// GetMemberMappings returns the user each CSV name was last mapped to in the
+// group, keyed by CSV name.
+func (r *importRepository) GetMemberMappings(ctx context.Context, groupID string) (map[string]string, error) {
+	query := `SELECT csv_name, user_id FROM import_member_mappings WHERE group_id = $1`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying import member mappings: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := make(map[string]string)
+	for rows.Next() {
+		var csvName, userID string
+		if err := rows.Scan(&csvName, &userID); err != nil {
+			return nil, fmt.Errorf("scanning import member mapping: %w", err)
+		}
+		mappings[csvName] = userID
+	}
+	return mappings, rows.Err()
+}
+
+func (r *importRepository) SaveMemberMappings(ctx context.Context, groupID string, mappings map[string]string) error {
+	query := `
+		INSERT INTO import_member_mappings (group_id, csv_name, user_id, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (group_id, csv_name) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			updated_at = NOW()
+	`
+	for csvName, userID := range mappings {
+		if _, err := r.getQuerier().Exec(ctx, query, groupID, csvName, userID); err != nil {
+			return fmt.Errorf("saving import member mapping: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetFingerprints returns which of fingerprints were already imported into
+// the group, mapped to the transaction each one created.
+func (r *importRepository) GetFingerprints(ctx context.Context, groupID string, fingerprints []string) (map[string]string, error) {
+	found := make(map[string]string)
+	if len(fingerprints) == 0 {
+		return found, nil
+	}
+
+	query := `SELECT fingerprint, expense_id FROM import_fingerprints WHERE group_id = $1 AND fingerprint = ANY($2)`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, fingerprints)
+	if err != nil {
+		return nil, fmt.Errorf("querying import fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fingerprint, expenseID string
+		if err := rows.Scan(&fingerprint, &expenseID); err != nil {
+			return nil, fmt.Errorf("scanning import fingerprint: %w", err)
+		}
+		found[fingerprint] = expenseID
+	}
+	return found, rows.Err()
+}
+
+// AddFingerprint records that fingerprint was imported as expenseID. The
+// first transaction imported with a fingerprint keeps it.
+func (r *importRepository) AddFingerprint(ctx context.Context, groupID, fingerprint, expenseID string) error {
+	query := `
+		INSERT INTO import_fingerprints (group_id, fingerprint, expense_id, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (group_id, fingerprint) DO NOTHING
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, groupID, fingerprint, expenseID); err != nil {
+		return fmt.Errorf("adding import fingerprint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type IntegrationRepository interface {
+	GetByGroupID(ctx context.Context, groupID string) ([]models.GroupIntegration, error)
+	GetByID(ctx context.Context, integrationID string) (*models.GroupIntegration, error)
+	Create(ctx context.Context, integration *models.GroupIntegration) error
+	Update(ctx context.Context, integration *models.GroupIntegration) error
+	Delete(ctx context.Context, integrationID string) error
+	EnqueueDelivery(ctx context.Context, delivery *models.IntegrationDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.IntegrationDelivery, error)
+	MarkDeliverySent(ctx context.Context, deliveryID string) error
+	MarkDeliveryFailed(ctx context.Context, deliveryID, lastError string, retryAt *time.Time) error
+	GetRecentDeliveries(ctx context.Context, integrationID string, limit int) ([]models.IntegrationDelivery, error)
+	WithTx(tx database.Querier) IntegrationRepository
+}
+
+type integrationRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewIntegrationRepository(db *database.DB) IntegrationRepository {
+	return &integrationRepository{db: db}
+}
+
+func (r *integrationRepository) WithTx(tx database.Querier) IntegrationRepository {
+	return &integrationRepository{db: r.db, tx: tx}
+}
+
+func (r *integrationRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+const integrationColumns = `id, group_id, platform, webhook_url, chat_id, notify_expenses, notify_settlements,
+	expense_template, settlement_template, enabled, created_by, created_at, updated_at`
+
+func scanIntegration(row interface{ Scan(dest ...any) error }, i *models.GroupIntegration) error {
+	return row.Scan(
+		&i.ID, &i.GroupID, &i.Platform, &i.WebhookURL, &i.ChatID, &i.NotifyExpenses, &i.NotifySettlements,
+		&i.ExpenseTemplate, &i.SettlementTemplate, &i.Enabled, &i.CreatedBy, &i.CreatedAt, &i.UpdatedAt,
+	)
+}
+
+func (r *integrationRepository) GetByGroupID(ctx context.Context, groupID string) ([]models.GroupIntegration, error) {
+	query := `SELECT ` + integrationColumns + ` FROM group_integrations WHERE group_id = $1 ORDER BY created_at`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying group integrations: %w", err)
+	}
+	defer rows.Close()
+
+	integrations := []models.GroupIntegration{}
+	for rows.Next() {
+		var i models.GroupIntegration
+		if err := scanIntegration(rows, &i); err != nil {
+			return nil, fmt.Errorf("scanning group integration: %w", err)
+		}
+		integrations = append(integrations, i)
+	}
+	return integrations, rows.Err()
+}
+
+func (r *integrationRepository) GetByID(ctx context.Context, integrationID string) (*models.GroupIntegration, error) {
+	query := `SELECT ` + integrationColumns + ` FROM group_integrations WHERE id = $1`
+	var i models.GroupIntegration
+	if err := scanIntegration(r.getQuerier().QueryRow(ctx, query, integrationID), &i); err != nil {
+		return nil, fmt.Errorf("getting group integration: %w", err)
+	}
+	return &i, nil
+}
+
+func (r *integrationRepository) Create(ctx context.Context, i *models.GroupIntegration) error {
+	query := `
+		INSERT INTO group_integrations (id, group_id, platform, webhook_url, chat_id, notify_expenses, notify_settlements,
+			expense_template, settlement_template, enabled, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query,
+		i.ID, i.GroupID, i.Platform, i.WebhookURL, i.ChatID, i.NotifyExpenses, i.NotifySettlements,
+		i.ExpenseTemplate, i.SettlementTemplate, i.Enabled, i.CreatedBy,
+	).Scan(&i.CreatedAt, &i.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("creating group integration: %w", err)
+	}
+	return nil
+}
+
+func (r *integrationRepository) Update(ctx context.Context, i *models.GroupIntegration) error {
+	query := `
+		UPDATE group_integrations
+		SET webhook_url = $2, chat_id = $3, notify_expenses = $4, notify_settlements = $5,
+			expense_template = $6, settlement_template = $7, enabled = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query,
+		i.ID, i.WebhookURL, i.ChatID, i.NotifyExpenses, i.NotifySettlements,
+		i.ExpenseTemplate, i.SettlementTemplate, i.Enabled,
+	).Scan(&i.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("updating group integration: %w", err)
+	}
+	return nil
+}
+
+func (r *integrationRepository) Delete(ctx context.Context, integrationID string) error {
+	if _, err := r.getQuerier().Exec(ctx, `DELETE FROM group_integrations WHERE id = $1`, integrationID); err != nil {
+		return fmt.Errorf("deleting group integration: %w", err)
+	}
+	return nil
+}
+
+func (r *integrationRepository) EnqueueDelivery(ctx context.Context, d *models.IntegrationDelivery) error {
+	query := `
+		INSERT INTO integration_deliveries (id, integration_id, expense_id, message, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, 'PENDING', 0, COALESCE($5, NOW()), NOW())
+		RETURNING status, next_attempt_at, created_at
+	`
+	var nextAttemptAt *time.Time
+	if !d.NextAttemptAt.IsZero() {
+		nextAttemptAt = &d.NextAttemptAt
+	}
+	err := r.getQuerier().QueryRow(ctx, query, d.ID, d.IntegrationID, d.ExpenseID, d.Message, nextAttemptAt).
+		Scan(&d.Status, &d.NextAttemptAt, &d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("enqueueing integration delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *integrationRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.IntegrationDelivery, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM integration_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE integration_deliveries d
+		SET attempts = d.attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due, group_integrations gi
+		WHERE d.id = due.id AND gi.id = d.integration_id
+		RETURNING d.id, d.integration_id, d.expense_id, d.message, d.status, d.attempts, d.next_attempt_at, d.created_at,
+			gi.platform, gi.webhook_url, gi.chat_id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claiming integration deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.IntegrationDelivery{}
+	for rows.Next() {
+		var d models.IntegrationDelivery
+		integration := &models.GroupIntegration{}
+		if err := rows.Scan(
+			&d.ID, &d.IntegrationID, &d.ExpenseID, &d.Message, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.CreatedAt,
+			&integration.Platform, &integration.WebhookURL, &integration.ChatID,
+		); err != nil {
+			return nil, fmt.Errorf("scanning integration delivery: %w", err)
+		}
+		integration.ID = d.IntegrationID
+		d.Integration = integration
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *integrationRepository) MarkDeliverySent(ctx context.Context, deliveryID string) error {
+	query := `UPDATE integration_deliveries SET status = 'SENT', sent_at = NOW(), last_error = NULL WHERE id = $1`
+	if _, err := r.getQuerier().Exec(ctx, query, deliveryID); err != nil {
+		return fmt.Errorf("marking integration delivery sent: %w", err)
+	}
+	return nil
+}
+
+func (r *integrationRepository) MarkDeliveryFailed(ctx context.Context, deliveryID, lastError string, retryAt *time.Time) error {
+	query := `
+		UPDATE integration_deliveries
+		SET last_error = $2,
+			status = CASE WHEN $3::timestamptz IS NULL THEN 'FAILED' ELSE 'PENDING' END,
+			next_attempt_at = COALESCE($3::timestamptz, next_attempt_at)
+		WHERE id = $1
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, deliveryID, lastError, retryAt); err != nil {
+		return fmt.Errorf("marking integration delivery failed: %w", err)
+	}
+	return nil
+}
+
+func (r *integrationRepository) GetRecentDeliveries(ctx context.Context, integrationID string, limit int) ([]models.IntegrationDelivery, error) {
+	query := `
+		SELECT id, integration_id, expense_id, message, status, attempts, next_attempt_at, last_error, created_at, sent_at
+		FROM integration_deliveries
+		WHERE integration_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.getQuerier().Query(ctx, query, integrationID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying integration deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.IntegrationDelivery{}
+	for rows.Next() {
+		var d models.IntegrationDelivery
+		if err := rows.Scan(&d.ID, &d.IntegrationID, &d.ExpenseID, &d.Message, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.SentAt); err != nil {
+			return nil, fmt.Errorf("scanning integration delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
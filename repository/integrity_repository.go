@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type IntegrityRepository interface {
+	CountOrphans(ctx context.Context) ([]models.OrphanCheck, error)
+	FindOrphanedPlaceholders(ctx context.Context) ([]models.User, error)
+	DeleteOrphanedPlaceholders(ctx context.Context, placeholderIDs []string) (int64, error)
+	GetExpenseTotals(ctx context.Context, groupID string) ([]models.UnbalancedExpense, error)
+	WithTx(tx database.Querier) IntegrityRepository
+}
+
+type integrityRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewIntegrityRepository(db *database.DB) IntegrityRepository {
+	return &integrityRepository{db: db}
+}
+
+func (r *integrityRepository) WithTx(tx database.Querier) IntegrityRepository {
+	return &integrityRepository{db: r.db, tx: tx}
+}
+
+func (r *integrityRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+type orphanQuery struct {
+	table       string
+	check       string
+	description string
+	query       string
+}
+
+var orphanQueries = []orphanQuery{
+	{
+		table:       "expenses",
+		check:       "missing_payers",
+		description: "Expenses with no payer rows",
+		query: `SELECT COUNT(*) FROM expenses e
+			WHERE NOT EXISTS (SELECT 1 FROM expense_payers ep WHERE ep.expense_id = e.id)`,
+	},
+	{
+		table:       "expenses",
+		check:       "missing_splits",
+		description: "Expenses with no split rows",
+		query: `SELECT COUNT(*) FROM expenses e
+			WHERE NOT EXISTS (SELECT 1 FROM expense_splits es WHERE es.expense_id = e.id)`,
+	},
+	{
+		table:       "expenses",
+		check:       "paid_by_not_payer",
+		description: "Expenses whose paid_by_user_id is not among the payer rows",
+		query: `SELECT COUNT(*) FROM expenses e
+			WHERE e.paid_by_user_id IS NOT NULL
+			AND EXISTS (SELECT 1 FROM expense_payers ep WHERE ep.expense_id = e.id)
+			AND NOT EXISTS (SELECT 1 FROM expense_payers ep WHERE ep.expense_id = e.id AND ep.user_id = e.paid_by_user_id)`,
+	},
+	{
+		table:       "receipt_item_assignments",
+		check:       "user_outside_splits",
+		description: "Receipt item assignments for users with no split on the expense",
+		query: `SELECT COUNT(*) FROM receipt_item_assignments ria
+			JOIN receipt_items ri ON ri.id = ria.receipt_item_id
+			WHERE NOT EXISTS (
+				SELECT 1 FROM expense_splits es WHERE es.expense_id = ri.expense_id AND es.user_id = ria.user_id
+			)`,
+	},
+	{
+		table:       "group_members",
+		check:       "deleted_user",
+		description: "Group memberships held by deleted users",
+		query: `SELECT COUNT(*) FROM group_members gm
+			JOIN users u ON u.id = gm.user_id
+			WHERE u.deleted_at IS NOT NULL`,
+	},
+	{
+		table:       "friends",
+		check:       "deleted_user",
+		description: "Friendships involving deleted users",
+		query: `SELECT COUNT(*) FROM friends f
+			JOIN users u ON u.id = f.user_id OR u.id = f.friend_id
+			WHERE u.deleted_at IS NOT NULL`,
+	},
+	{
+		table:       "friends",
+		check:       "asymmetric",
+		description: "Friendships stored in only one direction",
+		query: `SELECT COUNT(*) FROM friends f
+			WHERE NOT EXISTS (SELECT 1 FROM friends r WHERE r.user_id = f.friend_id AND r.friend_id = f.user_id)`,
+	},
+	{
+		table:       "groups",
+		check:       "no_members",
+		description: "Groups with no remaining members",
+		query: `SELECT COUNT(*) FROM groups g
+			WHERE NOT EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = g.id)`,
+	},
+	{
+		table:       "comments",
+		check:       "author_not_member",
+		description: "Comments by active users who are no longer members of the expense's group",
+		query: `SELECT COUNT(*) FROM comments c
+			JOIN expenses e ON e.id = c.expense_id
+			JOIN users u ON u.id = c.user_id
+			WHERE u.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = e.group_id AND gm.user_id = c.user_id)`,
+	},
+	{
+		table:       "users",
+		check:       "claimed_by_deleted_user",
+		description: "Placeholders claimed by a user that has since been deleted",
+		query: `SELECT COUNT(*) FROM users p
+			JOIN users u ON u.id = p.claimed_by
+			WHERE u.deleted_at IS NOT NULL`,
+	},
+}
+
+func (r *integrityRepository) CountOrphans(ctx context.Context) ([]models.OrphanCheck, error) {
+	checks := make([]models.OrphanCheck, 0, len(orphanQueries))
+	for _, q := range orphanQueries {
+		var count int64
+		if err := r.getQuerier().QueryRow(ctx, q.query).Scan(&count); err != nil {
+			return nil, fmt.Errorf("counting orphans for %s.%s: %w", q.table, q.check, err)
+		}
+		checks = append(checks, models.OrphanCheck{
+			Table:       q.table,
+			Check:       q.check,
+			Description: q.description,
+			Count:       count,
+		})
+	}
+	return checks, nil
+}
+
+const orphanedPlaceholderCondition = `u.is_placeholder = TRUE AND u.claimed_by IS NULL
+	AND NOT EXISTS (SELECT 1 FROM group_members gm WHERE gm.user_id = u.id)
+	AND NOT EXISTS (SELECT 1 FROM expense_payers ep WHERE ep.user_id = u.id)
+	AND NOT EXISTS (SELECT 1 FROM expense_splits es WHERE es.user_id = u.id)
+	AND NOT EXISTS (SELECT 1 FROM expenses e WHERE e.paid_by_user_id = u.id)`
+
+func (r *integrityRepository) FindOrphanedPlaceholders(ctx context.Context) ([]models.User, error) {
+	query := `SELECT u.id, u.name, u.created_at FROM users u WHERE ` + orphanedPlaceholderCondition + ` ORDER BY u.created_at`
+	rows, err := r.getQuerier().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("querying orphaned placeholders: %w", err)
+	}
+	defer rows.Close()
+
+	placeholders := []models.User{}
+	for rows.Next() {
+		u := models.User{IsPlaceholder: true}
+		if err := rows.Scan(&u.ID, &u.Name, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning orphaned placeholder: %w", err)
+		}
+		placeholders = append(placeholders, u)
+	}
+	return placeholders, rows.Err()
+}
+
+func (r *integrityRepository) DeleteOrphanedPlaceholders(ctx context.Context, placeholderIDs []string) (int64, error) {
+	if len(placeholderIDs) == 0 {
+		return 0, nil
+	}
+	query := `DELETE FROM users u WHERE u.id = ANY($1) AND ` + orphanedPlaceholderCondition
+	tag, err := r.getQuerier().Exec(ctx, query, placeholderIDs)
+	if err != nil {
+		return 0, fmt.Errorf("deleting orphaned placeholders: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *integrityRepository) GetExpenseTotals(ctx context.Context, groupID string) ([]models.UnbalancedExpense, error) {
+	query := `
+		SELECT e.id, e.description, e.currency, e.total_amount,
+			COALESCE((SELECT SUM(ep.amount_paid) FROM expense_payers ep WHERE ep.expense_id = e.id), 0),
+			COALESCE((SELECT SUM(es.amount) FROM expense_splits es WHERE es.expense_id = e.id), 0)
+		FROM expenses e
+		WHERE e.group_id = $1
+		ORDER BY e.transaction_timestamp, e.id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying expense totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []models.UnbalancedExpense{}
+	for rows.Next() {
+		var e models.UnbalancedExpense
+		if err := rows.Scan(&e.ExpenseID, &e.Description, &e.Currency, &e.TotalAmount, &e.PaidTotal, &e.SplitTotal); err != nil {
+			return nil, fmt.Errorf("scanning expense totals: %w", err)
+		}
+		totals = append(totals, e)
+	}
+	return totals, rows.Err()
+}
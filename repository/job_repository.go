@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type JobRepository interface {
+	Enqueue(ctx context.Context, job *models.Job) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.Job, error)
+	MarkSucceeded(ctx context.Context, jobID string) error
+	MarkFailed(ctx context.Context, jobID, lastError string, retryAt *time.Time) error
+	GetDead(ctx context.Context, limit int) ([]models.Job, error)
+	Requeue(ctx context.Context, jobID string) (*models.Job, error)
+	WithTx(tx database.Querier) JobRepository
+}
+
+type jobRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewJobRepository(db *database.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) WithTx(tx database.Querier) JobRepository {
+	return &jobRepository{db: r.db, tx: tx}
+}
+
+func (r *jobRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+const jobColumns = `id, type, payload, status, attempts, max_attempts, run_at, last_error, created_at, updated_at, completed_at`
+
+func scanJob(row pgx.Row) (models.Job, error) {
+	var j models.Job
+	var payload []byte
+	err := row.Scan(&j.ID, &j.Type, &payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.LastError, &j.CreatedAt, &j.UpdatedAt, &j.CompletedAt)
+	j.Payload = json.RawMessage(payload)
+	return j, err
+}
+
+func (r *jobRepository) Enqueue(ctx context.Context, job *models.Job) error {
+	query := `
+		INSERT INTO jobs (id, type, payload, status, attempts, max_attempts, run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, 'PENDING', 0, $4, COALESCE($5, NOW()), NOW(), NOW())
+		RETURNING status, run_at, created_at, updated_at
+	`
+	var runAt *time.Time
+	if !job.RunAt.IsZero() {
+		runAt = &job.RunAt
+	}
+	err := r.getQuerier().QueryRow(ctx, query, job.ID, job.Type, []byte(job.Payload), job.MaxAttempts, runAt).
+		Scan(&job.Status, &job.RunAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("enqueueing job: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue takes up to limit due jobs, counting the attempt and leasing them
+// for lease. Concurrent workers skip each other's rows.
+func (r *jobRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.Job, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM jobs
+			WHERE status = 'PENDING' AND run_at <= NOW()
+			ORDER BY run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE jobs j
+		SET attempts = j.attempts + 1, run_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+		FROM due
+		WHERE j.id = due.id
+		RETURNING j.id, j.type, j.payload, j.status, j.attempts, j.max_attempts, j.run_at, j.last_error,
+			j.created_at, j.updated_at, j.completed_at
+	`
+	rows, err := r.getQuerier().Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claiming jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *jobRepository) MarkSucceeded(ctx context.Context, jobID string) error {
+	query := `UPDATE jobs SET status = 'SUCCEEDED', completed_at = NOW(), updated_at = NOW(), last_error = NULL WHERE id = $1`
+	if _, err := r.getQuerier().Exec(ctx, query, jobID); err != nil {
+		return fmt.Errorf("marking job succeeded: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) MarkFailed(ctx context.Context, jobID, lastError string, retryAt *time.Time) error {
+	query := `
+		UPDATE jobs
+		SET last_error = $2,
+			status = CASE WHEN $3::timestamptz IS NULL THEN 'DEAD' ELSE 'PENDING' END,
+			run_at = COALESCE($3::timestamptz, run_at),
+			updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, jobID, lastError, retryAt); err != nil {
+		return fmt.Errorf("marking job failed: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) GetDead(ctx context.Context, limit int) ([]models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE status = 'DEAD' ORDER BY updated_at DESC LIMIT $1`
+	rows, err := r.getQuerier().Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying dead jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *jobRepository) Requeue(ctx context.Context, jobID string) (*models.Job, error) {
+	query := `
+		UPDATE jobs SET status = 'PENDING', attempts = 0, run_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'DEAD'
+		RETURNING ` + jobColumns
+	j, err := scanJob(r.getQuerier().QueryRow(ctx, query, jobID))
+	if err != nil {
+		return nil, fmt.Errorf("requeueing job: %w", err)
+	}
+	return &j, nil
+}
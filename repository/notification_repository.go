@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	GetByUserID(ctx context.Context, userID string, limit int) ([]models.Notification, error)
+	MarkRead(ctx context.Context, notificationID, userID string) error
+	GetSettings(ctx context.Context, groupID, userID string) (*models.GroupNotificationSettings, error)
+	GetSettingsForGroup(ctx context.Context, groupID string) (map[string]models.GroupNotificationSettings, error)
+	UpsertSettings(ctx context.Context, settings *models.GroupNotificationSettings) error
+	GetQuietHours(ctx context.Context, groupID string) (*models.GroupQuietHours, error)
+	UpsertQuietHours(ctx context.Context, quietHours *models.GroupQuietHours) error
+	GetLastRemindersByActor(ctx context.Context, actorID string, since time.Time) (map[string]time.Time, error)
+	WithTx(tx database.Querier) NotificationRepository
+}
+
+type notificationRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewNotificationRepository(db *database.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) WithTx(tx database.Querier) NotificationRepository {
+	return &notificationRepository{db: r.db, tx: tx}
+}
+
+func (r *notificationRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *notificationRepository) Create(ctx context.Context, n *models.Notification) error {
+	query := `
+		WITH n AS (
+			INSERT INTO notifications (id, user_id, group_id, expense_id, actor_id, event, message, deliver_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, NOW()), NOW())
+			RETURNING expense_id, user_id, deliver_at, created_at
+		), notice AS (
+			INSERT INTO expense_notices (expense_id, user_id, notified_at)
+			SELECT expense_id, user_id, deliver_at FROM n WHERE expense_id IS NOT NULL
+			ON CONFLICT (expense_id, user_id) DO UPDATE SET notified_at = LEAST(expense_notices.notified_at, EXCLUDED.notified_at)
+		)
+		SELECT deliver_at, created_at FROM n
+	`
+	var deliverAt *time.Time
+	if !n.DeliverAt.IsZero() {
+		deliverAt = &n.DeliverAt
+	}
+	err := r.getQuerier().QueryRow(ctx, query, n.ID, n.UserID, n.GroupID, n.ExpenseID, n.ActorID, n.Event, n.Message, deliverAt).Scan(&n.DeliverAt, &n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating notification: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepository) GetByUserID(ctx context.Context, userID string, limit int) ([]models.Notification, error) {
+	query := `
+		SELECT id, user_id, group_id, expense_id, actor_id, event, message, read_at, deliver_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND deliver_at <= NOW()
+		ORDER BY deliver_at DESC
+		LIMIT $2
+	`
+	rows, err := r.getQuerier().Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.GroupID, &n.ExpenseID, &n.ActorID, &n.Event, &n.Message, &n.ReadAt, &n.DeliverAt, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, notificationID, userID string) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`
+	tag, err := r.getQuerier().Exec(ctx, query, notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("marking notification read: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("notification not found")
+	}
+	return nil
+}
+
+func (r *notificationRepository) GetSettings(ctx context.Context, groupID, userID string) (*models.GroupNotificationSettings, error) {
+	query := `
+		SELECT group_id, user_id, muted, new_expenses, comments, settlements, reminders, updated_at
+		FROM group_notification_settings
+		WHERE group_id = $1 AND user_id = $2
+	`
+	var s models.GroupNotificationSettings
+	err := r.getQuerier().QueryRow(ctx, query, groupID, userID).Scan(
+		&s.GroupID, &s.UserID, &s.Muted, &s.NewExpenses, &s.Comments, &s.Settlements, &s.Reminders, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting notification settings: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *notificationRepository) GetSettingsForGroup(ctx context.Context, groupID string) (map[string]models.GroupNotificationSettings, error) {
+	query := `
+		SELECT group_id, user_id, muted, new_expenses, comments, settlements, reminders, updated_at
+		FROM group_notification_settings
+		WHERE group_id = $1
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying notification settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]models.GroupNotificationSettings)
+	for rows.Next() {
+		var s models.GroupNotificationSettings
+		if err := rows.Scan(&s.GroupID, &s.UserID, &s.Muted, &s.NewExpenses, &s.Comments, &s.Settlements, &s.Reminders, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning notification settings: %w", err)
+		}
+		settings[s.UserID] = s
+	}
+	return settings, rows.Err()
+}
+
+func (r *notificationRepository) UpsertSettings(ctx context.Context, s *models.GroupNotificationSettings) error {
+	query := `
+		INSERT INTO group_notification_settings (group_id, user_id, muted, new_expenses, comments, settlements, reminders, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (group_id, user_id) DO UPDATE SET
+			muted = EXCLUDED.muted,
+			new_expenses = EXCLUDED.new_expenses,
+			comments = EXCLUDED.comments,
+			settlements = EXCLUDED.settlements,
+			reminders = EXCLUDED.reminders,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query, s.GroupID, s.UserID, s.Muted, s.NewExpenses, s.Comments, s.Settlements, s.Reminders).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upserting notification settings: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepository) GetQuietHours(ctx context.Context, groupID string) (*models.GroupQuietHours, error) {
+	query := `
+		SELECT group_id, enabled, start_time, end_time, time_zone, updated_by, updated_at
+		FROM group_quiet_hours
+		WHERE group_id = $1
+	`
+	var q models.GroupQuietHours
+	err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(
+		&q.GroupID, &q.Enabled, &q.Start, &q.End, &q.TimeZone, &q.UpdatedBy, &q.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting quiet hours: %w", err)
+	}
+	return &q, nil
+}
+
+func (r *notificationRepository) UpsertQuietHours(ctx context.Context, q *models.GroupQuietHours) error {
+	query := `
+		INSERT INTO group_quiet_hours (group_id, enabled, start_time, end_time, time_zone, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (group_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			start_time = EXCLUDED.start_time,
+			end_time = EXCLUDED.end_time,
+			time_zone = EXCLUDED.time_zone,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query, q.GroupID, q.Enabled, q.Start, q.End, q.TimeZone, q.UpdatedBy).Scan(&q.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upserting quiet hours: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepository) GetLastRemindersByActor(ctx context.Context, actorID string, since time.Time) (map[string]time.Time, error) {
+	query := `
+		SELECT user_id, MAX(created_at)
+		FROM notifications
+		WHERE actor_id = $1 AND event = $2 AND created_at >= $3
+		GROUP BY user_id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, actorID, models.NotificationEventReminder, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying recent reminders: %w", err)
+	}
+	defer rows.Close()
+
+	reminders := make(map[string]time.Time)
+	for rows.Next() {
+		var userID string
+		var sentAt time.Time
+		if err := rows.Scan(&userID, &sentAt); err != nil {
+			return nil, fmt.Errorf("scanning recent reminder: %w", err)
+		}
+		reminders[userID] = sentAt
+	}
+	return reminders, rows.Err()
+}
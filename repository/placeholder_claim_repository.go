@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+
+	"github.com/google/uuid"
+)
+
+type PlaceholderClaimRepository interface {
+	Create(ctx context.Context, placeholderID, userID string) (*models.PlaceholderClaimRequest, error)
+	GetByIDForUpdate(ctx context.Context, requestID string) (*models.PlaceholderClaimRequest, error)
+	GetPending(ctx context.Context) ([]models.PlaceholderClaimRequest, error)
+	Decide(ctx context.Context, requestID, adminID string, status models.PlaceholderClaimStatus) error
+	RejectPendingForPlaceholder(ctx context.Context, placeholderID, adminID string) error
+	WithTx(tx database.Querier) PlaceholderClaimRepository
+}
+
+type placeholderClaimRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewPlaceholderClaimRepository(db *database.DB) PlaceholderClaimRepository {
+	return &placeholderClaimRepository{db: db}
+}
+
+func (r *placeholderClaimRepository) WithTx(tx database.Querier) PlaceholderClaimRepository {
+	return &placeholderClaimRepository{db: r.db, tx: tx}
+}
+
+func (r *placeholderClaimRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *placeholderClaimRepository) Create(ctx context.Context, placeholderID, userID string) (*models.PlaceholderClaimRequest, error) {
+	query := `
+		INSERT INTO placeholder_claim_requests (id, placeholder_id, user_id, status, created_at)
+		VALUES ($1, $2, $3, 'PENDING', NOW())
+		ON CONFLICT (placeholder_id, user_id) WHERE status = 'PENDING'
+		DO UPDATE SET created_at = placeholder_claim_requests.created_at
+		RETURNING id, placeholder_id, user_id, status, created_at, decided_at, decided_by
+	`
+	var req models.PlaceholderClaimRequest
+	err := r.getQuerier().QueryRow(ctx, query, uuid.New().String(), placeholderID, userID).Scan(
+		&req.ID, &req.PlaceholderID, &req.UserID, &req.Status, &req.CreatedAt, &req.DecidedAt, &req.DecidedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating placeholder claim request: %w", err)
+	}
+	return &req, nil
+}
+
+func (r *placeholderClaimRepository) GetByIDForUpdate(ctx context.Context, requestID string) (*models.PlaceholderClaimRequest, error) {
+	query := `
+		SELECT id, placeholder_id, user_id, status, created_at, decided_at, decided_by
+		FROM placeholder_claim_requests
+		WHERE id = $1
+		FOR UPDATE
+	`
+	var req models.PlaceholderClaimRequest
+	err := r.getQuerier().QueryRow(ctx, query, requestID).Scan(
+		&req.ID, &req.PlaceholderID, &req.UserID, &req.Status, &req.CreatedAt, &req.DecidedAt, &req.DecidedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting placeholder claim request: %w", err)
+	}
+	return &req, nil
+}
+
+func (r *placeholderClaimRepository) GetPending(ctx context.Context) ([]models.PlaceholderClaimRequest, error) {
+	query := `
+		SELECT c.id, c.placeholder_id, c.user_id, c.status, c.created_at, c.decided_at, c.decided_by,
+		       p.name, u.name, COALESCE(u.email, ''), u.avatar_url
+		FROM placeholder_claim_requests c
+		JOIN users p ON p.id = c.placeholder_id
+		JOIN users u ON u.id = c.user_id
+		WHERE c.status = 'PENDING'
+		ORDER BY c.created_at ASC
+	`
+	rows, err := r.getQuerier().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending placeholder claims: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []models.PlaceholderClaimRequest{}
+	for rows.Next() {
+		var req models.PlaceholderClaimRequest
+		placeholder := &models.User{IsPlaceholder: true}
+		claimer := &models.User{}
+		if err := rows.Scan(
+			&req.ID, &req.PlaceholderID, &req.UserID, &req.Status, &req.CreatedAt, &req.DecidedAt, &req.DecidedBy,
+			&placeholder.Name, &claimer.Name, &claimer.Email, &claimer.AvatarURL,
+		); err != nil {
+			return nil, fmt.Errorf("scanning placeholder claim: %w", err)
+		}
+		placeholder.ID = req.PlaceholderID
+		claimer.ID = req.UserID
+		req.Placeholder = placeholder
+		req.User = claimer
+		requests = append(requests, req)
+	}
+	return requests, rows.Err()
+}
+
+func (r *placeholderClaimRepository) Decide(ctx context.Context, requestID, adminID string, status models.PlaceholderClaimStatus) error {
+	query := `
+		UPDATE placeholder_claim_requests
+		SET status = $2, decided_at = NOW(), decided_by = $3
+		WHERE id = $1 AND status = 'PENDING'
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, requestID, status, adminID); err != nil {
+		return fmt.Errorf("deciding placeholder claim: %w", err)
+	}
+	return nil
+}
+
+func (r *placeholderClaimRepository) RejectPendingForPlaceholder(ctx context.Context, placeholderID, adminID string) error {
+	query := `
+		UPDATE placeholder_claim_requests
+		SET status = 'REJECTED', decided_at = NOW(), decided_by = $2
+		WHERE placeholder_id = $1 AND status = 'PENDING'
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, placeholderID, adminID); err != nil {
+		return fmt.Errorf("rejecting pending placeholder claims: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRepositoriesUseQuerier fails when a repository method reaches for
+// db.Pool directly instead of getQuerier(), which would run the query
+// outside a transaction the caller passed in through WithTx.
+func TestRepositoriesUseQuerier(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatalf("listing repository files: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	checked := 0
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", name, err)
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Name.Name == "getQuerier" {
+				continue
+			}
+			checked++
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == "Pool" {
+					t.Errorf("%s: %s uses db.Pool directly; use r.getQuerier() so it joins the caller's transaction",
+						fset.Position(sel.Pos()), fn.Name.Name)
+				}
+				return true
+			})
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no repository functions found")
+	}
+}
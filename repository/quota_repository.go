@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type QuotaRepository interface {
+	CountUserGroups(ctx context.Context, userID string) (int, error)
+	CountGroupMembers(ctx context.Context, groupID string) (int, error)
+	CountGroupExpenses(ctx context.Context, groupID string) (int, error)
+	GetGroupUsage(ctx context.Context, userID string) ([]models.GroupQuotaUsage, error)
+	IsExempt(ctx context.Context, userID string) (bool, error)
+	SetExempt(ctx context.Context, userID string, exempt bool) error
+	WithTx(tx database.Querier) QuotaRepository
+}
+
+type quotaRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewQuotaRepository(db *database.DB) QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+func (r *quotaRepository) WithTx(tx database.Querier) QuotaRepository {
+	return &quotaRepository{db: r.db, tx: tx}
+}
+
+func (r *quotaRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *quotaRepository) CountUserGroups(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM group_members WHERE user_id = $1`
+	var count int
+	if err := r.getQuerier().QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting user groups: %w", err)
+	}
+	return count, nil
+}
+
+// CountGroupMembers counts members plus pending email invites, since every
+// invite becomes a member when its recipient signs up.
+func (r *quotaRepository) CountGroupMembers(ctx context.Context, groupID string) (int, error) {
+	query := `
+		SELECT (SELECT COUNT(*) FROM group_members WHERE group_id = $1)
+		     + (SELECT COUNT(*) FROM group_invites WHERE group_id = $1 AND accepted_at IS NULL)
+	`
+	var count int
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting group members: %w", err)
+	}
+	return count, nil
+}
+
+// CountGroupExpenses counts expenses only; settlements, repayments and
+// refunds never count toward the quota so debts can always be cleared.
+func (r *quotaRepository) CountGroupExpenses(ctx context.Context, groupID string) (int, error) {
+	query := `SELECT COUNT(*) FROM expenses WHERE group_id = $1 AND category = 'EXPENSE'`
+	var count int
+	if err := r.getQuerier().QueryRow(ctx, query, groupID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting group expenses: %w", err)
+	}
+	return count, nil
+}
+
+// GetGroupUsage returns member and expense counts for each of the user's
+// groups. Limits are left for the caller to fill in.
+func (r *quotaRepository) GetGroupUsage(ctx context.Context, userID string) ([]models.GroupQuotaUsage, error) {
+	query := `
+		SELECT g.id, g.name,
+		       (SELECT COUNT(*) FROM group_members m WHERE m.group_id = g.id)
+		     + (SELECT COUNT(*) FROM group_invites i WHERE i.group_id = g.id AND i.accepted_at IS NULL),
+		       (SELECT COUNT(*) FROM expenses e WHERE e.group_id = g.id AND e.category = 'EXPENSE')
+		FROM groups g
+		INNER JOIN group_members gm ON gm.group_id = g.id AND gm.user_id = $1
+		ORDER BY g.name, g.id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting group usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []models.GroupQuotaUsage{}
+	for rows.Next() {
+		var u models.GroupQuotaUsage
+		if err := rows.Scan(&u.GroupID, &u.Name, &u.Members.Used, &u.Expenses.Used); err != nil {
+			return nil, fmt.Errorf("scanning group usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+func (r *quotaRepository) IsExempt(ctx context.Context, userID string) (bool, error) {
+	query := `SELECT quota_exempt FROM users WHERE id = $1`
+	var exempt bool
+	if err := r.getQuerier().QueryRow(ctx, query, userID).Scan(&exempt); err != nil {
+		return false, fmt.Errorf("getting user quota override: %w", err)
+	}
+	return exempt, nil
+}
+
+func (r *quotaRepository) SetExempt(ctx context.Context, userID string, exempt bool) error {
+	query := `UPDATE users SET quota_exempt = $1, updated_at = NOW() WHERE id = $2`
+	tag, err := r.getQuerier().Exec(ctx, query, exempt, userID)
+	if err != nil {
+		return fmt.Errorf("setting user quota override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("setting user quota override: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
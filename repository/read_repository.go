@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type ReadRepository interface {
+	MarkSeen(ctx context.Context, groupID, userID string, expenseIDs []string) (int64, error)
+	GetReadStates(ctx context.Context, groupID, userID string) (map[string]models.ReadState, error)
+	GetUnreadCounts(ctx context.Context, userID string, groupIDs []string) (map[string]int, error)
+	GetByExpenseID(ctx context.Context, expenseID string) ([]models.ExpenseRead, error)
+	GetVisibility(ctx context.Context, expenseID string) (map[string]models.ExpenseVisibility, error)
+	WithTx(tx database.Querier) ReadRepository
+}
+
+type readRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewReadRepository(db *database.DB) ReadRepository {
+	return &readRepository{db: db}
+}
+
+func (r *readRepository) WithTx(tx database.Querier) ReadRepository {
+	return &readRepository{db: r.db, tx: tx}
+}
+
+func (r *readRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *readRepository) MarkSeen(ctx context.Context, groupID, userID string, expenseIDs []string) (int64, error) {
+	query := `
+		INSERT INTO expense_reads (expense_id, user_id, seen_at)
+		SELECT e.id, $2, NOW()
+		FROM expenses e
+		WHERE e.group_id = $1
+		  AND (cardinality($3::text[]) = 0 OR e.id = ANY($3))
+		ON CONFLICT (expense_id, user_id) DO NOTHING
+	`
+	if expenseIDs == nil {
+		expenseIDs = []string{}
+	}
+	tag, err := r.getQuerier().Exec(ctx, query, groupID, userID, expenseIDs)
+	if err != nil {
+		return 0, fmt.Errorf("marking expenses seen: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *readRepository) GetReadStates(ctx context.Context, groupID, userID string) (map[string]models.ReadState, error) {
+	query := `
+		SELECT e.id, er.seen_at, (er.seen_at IS NULL AND e.created_at > gm.created_at)
+		FROM expenses e
+		JOIN group_members gm ON gm.group_id = e.group_id AND gm.user_id = $2
+		LEFT JOIN expense_reads er ON er.expense_id = e.id AND er.user_id = $2
+		WHERE e.group_id = $1
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying read states: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[string]models.ReadState)
+	for rows.Next() {
+		var expenseID string
+		var state models.ReadState
+		if err := rows.Scan(&expenseID, &state.SeenAt, &state.IsNew); err != nil {
+			return nil, fmt.Errorf("scanning read state: %w", err)
+		}
+		states[expenseID] = state
+	}
+	return states, rows.Err()
+}
+
+func (r *readRepository) GetUnreadCounts(ctx context.Context, userID string, groupIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(groupIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT e.group_id, COUNT(*)
+		FROM expenses e
+		JOIN group_members gm ON gm.group_id = e.group_id AND gm.user_id = $1
+		WHERE e.group_id = ANY($2)
+		  AND e.created_at > gm.created_at
+		  AND NOT EXISTS (
+			SELECT 1 FROM expense_reads er
+			WHERE er.expense_id = e.id AND er.user_id = $1
+		  )
+		GROUP BY e.group_id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, userID, groupIDs)
+	if err != nil {
+		return nil, fmt.Errorf("querying unread counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID string
+		var count int
+		if err := rows.Scan(&groupID, &count); err != nil {
+			return nil, fmt.Errorf("scanning unread count: %w", err)
+		}
+		counts[groupID] = count
+	}
+	return counts, rows.Err()
+}
+
+func (r *readRepository) GetByExpenseID(ctx context.Context, expenseID string) ([]models.ExpenseRead, error) {
+	query := `
+		SELECT er.expense_id, er.user_id, er.seen_at, u.name, COALESCE(u.email, ''), u.avatar_url
+		FROM expense_reads er
+		JOIN users u ON u.id = er.user_id
+		WHERE er.expense_id = $1
+		ORDER BY er.seen_at ASC
+	`
+	rows, err := r.getQuerier().Query(ctx, query, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("querying expense reads: %w", err)
+	}
+	defer rows.Close()
+
+	reads := []models.ExpenseRead{}
+	for rows.Next() {
+		var read models.ExpenseRead
+		user := &models.User{}
+		if err := rows.Scan(&read.ExpenseID, &read.UserID, &read.SeenAt, &user.Name, &user.Email, &user.AvatarURL); err != nil {
+			return nil, fmt.Errorf("scanning expense read: %w", err)
+		}
+		user.ID = read.UserID
+		read.User = user
+		reads = append(reads, read)
+	}
+	return reads, rows.Err()
+}
+
+// GetVisibility returns, per member, when they were first notified of the
+// expense and when they first saw it. Members with neither are left out.
+func (r *readRepository) GetVisibility(ctx context.Context, expenseID string) (map[string]models.ExpenseVisibility, error) {
+	query := `
+		SELECT COALESCE(er.user_id, en.user_id), en.notified_at, er.seen_at
+		FROM (SELECT user_id, seen_at FROM expense_reads WHERE expense_id = $1) er
+		FULL JOIN (SELECT user_id, notified_at FROM expense_notices WHERE expense_id = $1) en ON en.user_id = er.user_id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("querying expense visibility: %w", err)
+	}
+	defer rows.Close()
+
+	visibility := make(map[string]models.ExpenseVisibility)
+	for rows.Next() {
+		var userID string
+		var v models.ExpenseVisibility
+		if err := rows.Scan(&userID, &v.FirstNotifiedAt, &v.FirstSeenAt); err != nil {
+			return nil, fmt.Errorf("scanning expense visibility: %w", err)
+		}
+		visibility[userID] = v
+	}
+	return visibility, rows.Err()
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetVisibility(t *testing.T) {
+	notified := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	seen := notified.Add(time.Hour)
+	q := &countingQuerier{respond: func(sql string) [][]interface{} {
+		return [][]interface{}{
+			{"alice", notified, seen},
+			{"bob", notified, nil},
+			{"carol", nil, seen},
+		}
+	}}
+	repo := (&readRepository{}).WithTx(q)
+
+	visibility, err := repo.GetVisibility(context.Background(), "e1")
+	if err != nil {
+		t.Fatalf("GetVisibility() error = %v", err)
+	}
+	if len(visibility) != 3 {
+		t.Fatalf("GetVisibility() = %v, expected three members", visibility)
+	}
+	if v := visibility["alice"]; v.FirstNotifiedAt == nil || !v.FirstNotifiedAt.Equal(notified) || v.FirstSeenAt == nil || !v.FirstSeenAt.Equal(seen) {
+		t.Errorf("GetVisibility() alice = %+v, expected notified and seen", v)
+	}
+	if v := visibility["bob"]; v.FirstNotifiedAt == nil || v.FirstSeenAt != nil {
+		t.Errorf("GetVisibility() bob = %+v, expected notified but never seen", v)
+	}
+	if v := visibility["carol"]; v.FirstNotifiedAt != nil || v.FirstSeenAt == nil {
+		t.Errorf("GetVisibility() carol = %+v, expected seen without a notification", v)
+	}
+}
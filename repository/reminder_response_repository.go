@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type ReminderResponseRepository interface {
+	Upsert(ctx context.Context, response *models.ReminderResponse) error
+	Delete(ctx context.Context, groupID, debtorID, creditorID string) (bool, error)
+	GetActiveByGroupID(ctx context.Context, groupID string, now time.Time) ([]models.ReminderResponse, error)
+	GetActiveByCreditorID(ctx context.Context, creditorID string, now time.Time) ([]models.ReminderResponse, error)
+	WithTx(tx database.Querier) ReminderResponseRepository
+}
+
+type reminderResponseRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewReminderResponseRepository(db *database.DB) ReminderResponseRepository {
+	return &reminderResponseRepository{db: db}
+}
+
+func (r *reminderResponseRepository) WithTx(tx database.Querier) ReminderResponseRepository {
+	return &reminderResponseRepository{db: r.db, tx: tx}
+}
+
+func (r *reminderResponseRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+// Upsert replaces any earlier response from the debtor to the creditor in the
+// group, keeping its original created_at.
+func (r *reminderResponseRepository) Upsert(ctx context.Context, response *models.ReminderResponse) error {
+	query := `
+		INSERT INTO reminder_responses (group_id, debtor_id, creditor_id, status, pay_by, held_until, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (group_id, debtor_id, creditor_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			pay_by = EXCLUDED.pay_by,
+			held_until = EXCLUDED.held_until,
+			note = EXCLUDED.note,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query,
+		response.GroupID, response.DebtorID, response.CreditorID, response.Status,
+		response.PayBy, response.HeldUntil, response.Note,
+	).Scan(&response.CreatedAt, &response.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("saving reminder response: %w", err)
+	}
+	return nil
+}
+
+func (r *reminderResponseRepository) Delete(ctx context.Context, groupID, debtorID, creditorID string) (bool, error) {
+	query := `DELETE FROM reminder_responses WHERE group_id = $1 AND debtor_id = $2 AND creditor_id = $3`
+	tag, err := r.getQuerier().Exec(ctx, query, groupID, debtorID, creditorID)
+	if err != nil {
+		return false, fmt.Errorf("deleting reminder response: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const reminderResponseColumns = `group_id, debtor_id, creditor_id, status, pay_by::TEXT, held_until, note, created_at, updated_at`
+
+func (r *reminderResponseRepository) GetActiveByGroupID(ctx context.Context, groupID string, now time.Time) ([]models.ReminderResponse, error) {
+	query := `SELECT ` + reminderResponseColumns + `
+		FROM reminder_responses
+		WHERE group_id = $1 AND held_until > $2
+		ORDER BY held_until`
+	return r.queryResponses(ctx, query, groupID, now)
+}
+
+func (r *reminderResponseRepository) GetActiveByCreditorID(ctx context.Context, creditorID string, now time.Time) ([]models.ReminderResponse, error) {
+	query := `SELECT ` + reminderResponseColumns + `
+		FROM reminder_responses
+		WHERE creditor_id = $1 AND held_until > $2
+		ORDER BY held_until`
+	return r.queryResponses(ctx, query, creditorID, now)
+}
+
+func (r *reminderResponseRepository) queryResponses(ctx context.Context, query string, args ...interface{}) ([]models.ReminderResponse, error) {
+	rows, err := r.getQuerier().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying reminder responses: %w", err)
+	}
+	defer rows.Close()
+
+	responses := []models.ReminderResponse{}
+	for rows.Next() {
+		var resp models.ReminderResponse
+		if err := rows.Scan(
+			&resp.GroupID, &resp.DebtorID, &resp.CreditorID, &resp.Status, &resp.PayBy,
+			&resp.HeldUntil, &resp.Note, &resp.CreatedAt, &resp.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning reminder response: %w", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses, rows.Err()
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type RetentionRepository interface {
+	GetPolicy(ctx context.Context, groupID string) (*models.GroupRetentionPolicy, error)
+	UpsertPolicy(ctx context.Context, policy *models.GroupRetentionPolicy) error
+	DeletePolicy(ctx context.Context, groupID string) error
+	ClaimDuePolicies(ctx context.Context, limit int, lease time.Duration) ([]models.GroupRetentionPolicy, error)
+	MarkPolicyRun(ctx context.Context, groupID string, ranAt time.Time, archivePath *string, nextRunAt time.Time) error
+	GetExpiredExpenseIDs(ctx context.Context, groupID string, cutoff time.Time, action models.RetentionAction) ([]string, error)
+	DeleteExpenses(ctx context.Context, expenseIDs []string) error
+	AnonymizeExpenses(ctx context.Context, expenseIDs []string, description string) error
+	WithTx(tx database.Querier) RetentionRepository
+}
+
+type retentionRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewRetentionRepository(db *database.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+func (r *retentionRepository) WithTx(tx database.Querier) RetentionRepository {
+	return &retentionRepository{db: r.db, tx: tx}
+}
+
+func (r *retentionRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+const retentionPolicyColumns = `group_id, retain_years, action, updated_by, next_run_at, last_run_at, last_archive_path, updated_at`
+
+func scanRetentionPolicy(row interface{ Scan(dest ...any) error }, p *models.GroupRetentionPolicy) error {
+	return row.Scan(&p.GroupID, &p.RetainYears, &p.Action, &p.UpdatedBy, &p.NextRunAt, &p.LastRunAt, &p.LastArchivePath, &p.UpdatedAt)
+}
+
+func (r *retentionRepository) GetPolicy(ctx context.Context, groupID string) (*models.GroupRetentionPolicy, error) {
+	query := `SELECT ` + retentionPolicyColumns + ` FROM group_retention_policies WHERE group_id = $1`
+	var p models.GroupRetentionPolicy
+	if err := scanRetentionPolicy(r.getQuerier().QueryRow(ctx, query, groupID), &p); err != nil {
+		return nil, fmt.Errorf("getting retention policy: %w", err)
+	}
+	return &p, nil
+}
+
+// Saving makes the policy due straight away, so a shorter period applies on
+// the worker's next pass.
+func (r *retentionRepository) UpsertPolicy(ctx context.Context, p *models.GroupRetentionPolicy) error {
+	query := `
+		INSERT INTO group_retention_policies (group_id, retain_years, action, updated_by, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW(), NOW())
+		ON CONFLICT (group_id) DO UPDATE SET
+			retain_years = EXCLUDED.retain_years,
+			action = EXCLUDED.action,
+			updated_by = EXCLUDED.updated_by,
+			next_run_at = NOW(),
+			updated_at = NOW()
+		RETURNING ` + retentionPolicyColumns
+	if err := scanRetentionPolicy(r.getQuerier().QueryRow(ctx, query, p.GroupID, p.RetainYears, p.Action, p.UpdatedBy), p); err != nil {
+		return fmt.Errorf("upserting retention policy: %w", err)
+	}
+	return nil
+}
+
+func (r *retentionRepository) DeletePolicy(ctx context.Context, groupID string) error {
+	query := `DELETE FROM group_retention_policies WHERE group_id = $1`
+	if _, err := r.getQuerier().Exec(ctx, query, groupID); err != nil {
+		return fmt.Errorf("deleting retention policy: %w", err)
+	}
+	return nil
+}
+
+// Claiming pushes next_run_at forward by lease, so concurrent workers never
+// run the same group.
+func (r *retentionRepository) ClaimDuePolicies(ctx context.Context, limit int, lease time.Duration) ([]models.GroupRetentionPolicy, error) {
+	query := `
+		WITH due AS (
+			SELECT group_id FROM group_retention_policies
+			WHERE next_run_at <= NOW()
+			ORDER BY next_run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE group_retention_policies p
+		SET next_run_at = NOW() + make_interval(secs => $2)
+		FROM due
+		WHERE p.group_id = due.group_id
+		RETURNING p.group_id, p.retain_years, p.action, p.updated_by, p.next_run_at, p.last_run_at, p.last_archive_path, p.updated_at
+	`
+	rows, err := r.getQuerier().Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claiming retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []models.GroupRetentionPolicy{}
+	for rows.Next() {
+		var p models.GroupRetentionPolicy
+		if err := scanRetentionPolicy(rows, &p); err != nil {
+			return nil, fmt.Errorf("scanning retention policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+func (r *retentionRepository) MarkPolicyRun(ctx context.Context, groupID string, ranAt time.Time, archivePath *string, nextRunAt time.Time) error {
+	query := `UPDATE group_retention_policies
+	          SET last_run_at = $2, last_archive_path = COALESCE($3, last_archive_path), next_run_at = $4
+	          WHERE group_id = $1`
+	if _, err := r.getQuerier().Exec(ctx, query, groupID, ranAt, archivePath, nextRunAt); err != nil {
+		return fmt.Errorf("marking retention run: %w", err)
+	}
+	return nil
+}
+
+// Deletion skips transactions that a newer refund or reversal points at,
+// since deleting them would cascade to it.
+func (r *retentionRepository) GetExpiredExpenseIDs(ctx context.Context, groupID string, cutoff time.Time, action models.RetentionAction) ([]string, error) {
+	query := `SELECT e.id FROM expenses e
+	          WHERE e.group_id = $1 AND e.transaction_timestamp < $2 AND e.anonymized_at IS NULL
+	          ORDER BY e.transaction_timestamp, e.id`
+	if action == models.RetentionActionDelete {
+		query = `SELECT e.id FROM expenses e
+		         WHERE e.group_id = $1 AND e.transaction_timestamp < $2
+		           AND NOT EXISTS (
+		               SELECT 1 FROM expenses d
+		               WHERE (d.original_expense_id = e.id OR d.reverses_expense_id = e.id)
+		                 AND d.transaction_timestamp >= $2
+		           )
+		         ORDER BY e.transaction_timestamp, e.id`
+	}
+
+	rows, err := r.getQuerier().Query(ctx, query, groupID, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("getting expired expenses: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning expired expense id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *retentionRepository) DeleteExpenses(ctx context.Context, expenseIDs []string) error {
+	query := `DELETE FROM expenses WHERE id = ANY($1)`
+	if _, err := r.getQuerier().Exec(ctx, query, expenseIDs); err != nil {
+		return fmt.Errorf("deleting expired expenses: %w", err)
+	}
+	return nil
+}
+
+// Amounts, payers and splits are kept so balances do not change.
+func (r *retentionRepository) AnonymizeExpenses(ctx context.Context, expenseIDs []string, description string) error {
+	query := `UPDATE expenses
+	          SET description = $2, receipt_image_path = NULL, settlement_reference = NULL, settlement_proof_path = NULL,
+	              explanation = NULL, anonymized_at = NOW()
+	          WHERE id = ANY($1)`
+	if _, err := r.getQuerier().Exec(ctx, query, expenseIDs, description); err != nil {
+		return fmt.Errorf("anonymizing expenses: %w", err)
+	}
+
+	query = `UPDATE receipt_items SET name = 'Item' WHERE expense_id = ANY($1)`
+	if _, err := r.getQuerier().Exec(ctx, query, expenseIDs); err != nil {
+		return fmt.Errorf("anonymizing receipt items: %w", err)
+	}
+
+	query = `DELETE FROM comments WHERE expense_id = ANY($1)`
+	if _, err := r.getQuerier().Exec(ctx, query, expenseIDs); err != nil {
+		return fmt.Errorf("deleting comments of anonymized expenses: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type SplitPreferenceRepository interface {
+	GetForPair(ctx context.Context, userAID, userBID string) ([]models.SplitPreference, error)
+	Find(ctx context.Context, userAID, userBID, groupID string) (*models.SplitPreference, error)
+	Upsert(ctx context.Context, preference *models.SplitPreference) error
+	Delete(ctx context.Context, userAID, userBID string, groupID *string) (bool, error)
+	WithTx(tx database.Querier) SplitPreferenceRepository
+}
+
+type splitPreferenceRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewSplitPreferenceRepository(db *database.DB) SplitPreferenceRepository {
+	return &splitPreferenceRepository{db: db}
+}
+
+func (r *splitPreferenceRepository) WithTx(tx database.Querier) SplitPreferenceRepository {
+	return &splitPreferenceRepository{db: r.db, tx: tx}
+}
+
+func (r *splitPreferenceRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+const splitPreferenceColumns = `id, user_a_id, user_b_id, group_id, user_a_percentage, updated_by, created_at, updated_at`
+
+func scanSplitPreference(row interface{ Scan(dest ...any) error }, p *models.SplitPreference) error {
+	return row.Scan(&p.ID, &p.UserAID, &p.UserBID, &p.GroupID, &p.UserAPercentage, &p.UpdatedBy, &p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *splitPreferenceRepository) GetForPair(ctx context.Context, userAID, userBID string) ([]models.SplitPreference, error) {
+	query := `SELECT ` + splitPreferenceColumns + ` FROM split_preferences
+		WHERE user_a_id = $1 AND user_b_id = $2
+		ORDER BY group_id NULLS FIRST, created_at`
+	rows, err := r.getQuerier().Query(ctx, query, userAID, userBID)
+	if err != nil {
+		return nil, fmt.Errorf("querying split preferences: %w", err)
+	}
+	defer rows.Close()
+
+	preferences := []models.SplitPreference{}
+	for rows.Next() {
+		var p models.SplitPreference
+		if err := scanSplitPreference(rows, &p); err != nil {
+			return nil, fmt.Errorf("scanning split preference: %w", err)
+		}
+		preferences = append(preferences, p)
+	}
+	return preferences, rows.Err()
+}
+
+func (r *splitPreferenceRepository) Find(ctx context.Context, userAID, userBID, groupID string) (*models.SplitPreference, error) {
+	query := `SELECT ` + splitPreferenceColumns + ` FROM split_preferences
+		WHERE user_a_id = $1 AND user_b_id = $2 AND (group_id = $3 OR group_id IS NULL)
+		ORDER BY group_id NULLS LAST
+		LIMIT 1`
+	var p models.SplitPreference
+	if err := scanSplitPreference(r.getQuerier().QueryRow(ctx, query, userAID, userBID, groupID), &p); err != nil {
+		return nil, fmt.Errorf("finding split preference: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *splitPreferenceRepository) Upsert(ctx context.Context, p *models.SplitPreference) error {
+	query := `
+		INSERT INTO split_preferences (id, user_a_id, user_b_id, group_id, user_a_percentage, updated_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (user_a_id, user_b_id, (COALESCE(group_id, ''))) DO UPDATE SET
+			user_a_percentage = EXCLUDED.user_a_percentage,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query, p.ID, p.UserAID, p.UserBID, p.GroupID, p.UserAPercentage, p.UpdatedBy).
+		Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upserting split preference: %w", err)
+	}
+	return nil
+}
+
+func (r *splitPreferenceRepository) Delete(ctx context.Context, userAID, userBID string, groupID *string) (bool, error) {
+	query := `DELETE FROM split_preferences WHERE user_a_id = $1 AND user_b_id = $2 AND COALESCE(group_id, '') = COALESCE($3, '')`
+	tag, err := r.getQuerier().Exec(ctx, query, userAID, userBID, groupID)
+	if err != nil {
+		return false, fmt.Errorf("deleting split preference: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+type StandingRepaymentRepository interface {
+	Create(ctx context.Context, standing *models.StandingRepayment) error
+	GetByID(ctx context.Context, id string) (*models.StandingRepayment, error)
+	GetActiveByGroupID(ctx context.Context, groupID string) ([]models.StandingRepayment, error)
+	Cancel(ctx context.Context, id string, cancelledBy *string) (bool, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.StandingRepayment, error)
+	MarkRun(ctx context.Context, id string, ranAt time.Time, expenseID string, nextRunAt time.Time) error
+	WithTx(tx database.Querier) StandingRepaymentRepository
+}
+
+type standingRepaymentRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewStandingRepaymentRepository(db *database.DB) StandingRepaymentRepository {
+	return &standingRepaymentRepository{db: db}
+}
+
+func (r *standingRepaymentRepository) WithTx(tx database.Querier) StandingRepaymentRepository {
+	return &standingRepaymentRepository{db: r.db, tx: tx}
+}
+
+func (r *standingRepaymentRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+const standingRepaymentColumns = `id, group_id, payer_id, receiver_id, amount, day_of_month, created_by,
+	next_run_at, last_run_at, last_expense_id, cancelled_at, cancelled_by, created_at`
+
+func scanStandingRepayment(row interface{ Scan(dest ...any) error }, s *models.StandingRepayment) error {
+	return row.Scan(&s.ID, &s.GroupID, &s.PayerID, &s.ReceiverID, &s.Amount, &s.DayOfMonth, &s.CreatedBy,
+		&s.NextRunAt, &s.LastRunAt, &s.LastExpenseID, &s.CancelledAt, &s.CancelledBy, &s.CreatedAt)
+}
+
+func (r *standingRepaymentRepository) Create(ctx context.Context, standing *models.StandingRepayment) error {
+	query := `
+		INSERT INTO standing_repayments (id, group_id, payer_id, receiver_id, amount, day_of_month, created_by, next_run_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at
+	`
+	err := r.getQuerier().QueryRow(ctx, query,
+		standing.ID, standing.GroupID, standing.PayerID, standing.ReceiverID,
+		standing.Amount, standing.DayOfMonth, standing.CreatedBy, standing.NextRunAt,
+	).Scan(&standing.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating standing repayment: %w", err)
+	}
+	return nil
+}
+
+func (r *standingRepaymentRepository) GetByID(ctx context.Context, id string) (*models.StandingRepayment, error) {
+	query := `SELECT ` + standingRepaymentColumns + ` FROM standing_repayments WHERE id = $1`
+	var standing models.StandingRepayment
+	if err := scanStandingRepayment(r.getQuerier().QueryRow(ctx, query, id), &standing); err != nil {
+		return nil, fmt.Errorf("getting standing repayment: %w", err)
+	}
+	return &standing, nil
+}
+
+func (r *standingRepaymentRepository) GetActiveByGroupID(ctx context.Context, groupID string) ([]models.StandingRepayment, error) {
+	query := `SELECT ` + standingRepaymentColumns + `
+		FROM standing_repayments
+		WHERE group_id = $1 AND cancelled_at IS NULL
+		ORDER BY next_run_at, created_at`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("getting standing repayments: %w", err)
+	}
+	defer rows.Close()
+
+	standing := []models.StandingRepayment{}
+	for rows.Next() {
+		var s models.StandingRepayment
+		if err := scanStandingRepayment(rows, &s); err != nil {
+			return nil, fmt.Errorf("scanning standing repayment: %w", err)
+		}
+		standing = append(standing, s)
+	}
+	return standing, rows.Err()
+}
+
+// cancelledBy is nil when the worker cancels. Reports false if it was already
+// cancelled.
+func (r *standingRepaymentRepository) Cancel(ctx context.Context, id string, cancelledBy *string) (bool, error) {
+	query := `UPDATE standing_repayments SET cancelled_at = NOW(), cancelled_by = $2
+	          WHERE id = $1 AND cancelled_at IS NULL`
+	tag, err := r.getQuerier().Exec(ctx, query, id, cancelledBy)
+	if err != nil {
+		return false, fmt.Errorf("cancelling standing repayment: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Claimed rows have next_run_at pushed out by lease, so another worker skips
+// them until MarkRun schedules the next month.
+func (r *standingRepaymentRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.StandingRepayment, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM standing_repayments
+			WHERE next_run_at <= NOW() AND cancelled_at IS NULL
+			ORDER BY next_run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE standing_repayments s
+		SET next_run_at = NOW() + make_interval(secs => $2)
+		FROM due
+		WHERE s.id = due.id
+		RETURNING s.id, s.group_id, s.payer_id, s.receiver_id, s.amount, s.day_of_month, s.created_by,
+			s.next_run_at, s.last_run_at, s.last_expense_id, s.cancelled_at, s.cancelled_by, s.created_at
+	`
+	rows, err := r.getQuerier().Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claiming standing repayments: %w", err)
+	}
+	defer rows.Close()
+
+	standing := []models.StandingRepayment{}
+	for rows.Next() {
+		var s models.StandingRepayment
+		if err := scanStandingRepayment(rows, &s); err != nil {
+			return nil, fmt.Errorf("scanning standing repayment: %w", err)
+		}
+		standing = append(standing, s)
+	}
+	return standing, rows.Err()
+}
+
+func (r *standingRepaymentRepository) MarkRun(ctx context.Context, id string, ranAt time.Time, expenseID string, nextRunAt time.Time) error {
+	query := `UPDATE standing_repayments
+	          SET last_run_at = $2, last_expense_id = $3, next_run_at = $4
+	          WHERE id = $1`
+	if _, err := r.getQuerier().Exec(ctx, query, id, ranAt, expenseID, nextRunAt); err != nil {
+		return fmt.Errorf("marking standing repayment run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+)
+
+// StatsRepository holds the aggregate queries behind a group's fun stats,
+// spending heatmap and leaderboard.
+// Every query looks at EXPENSE transactions in a single currency only, so
+// settlements and refunds do not count as spending.
+type StatsRepository interface {
+	GetExpenseTotals(ctx context.Context, groupID, currency string) (count int, total float64, err error)
+	GetBiggestExpense(ctx context.Context, groupID, currency string) (*models.FunStatExpense, error)
+	GetMemberPaymentStats(ctx context.Context, groupID, currency string) ([]models.FunStatMember, error)
+	GetLongestQuietStreak(ctx context.Context, groupID, currency string) (*models.FunStatStreak, error)
+	GetSpendingHeatmap(ctx context.Context, groupID, currency string) ([]models.HeatmapCell, []models.HeatmapMember, error)
+	GetLeaderboard(ctx context.Context, groupID, currency string, since *time.Time) ([]models.LeaderboardEntry, error)
+	WithTx(tx database.Querier) StatsRepository
+}
+
+type statsRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewStatsRepository(db *database.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+func (r *statsRepository) WithTx(tx database.Querier) StatsRepository {
+	return &statsRepository{db: r.db, tx: tx}
+}
+
+func (r *statsRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *statsRepository) GetExpenseTotals(ctx context.Context, groupID, currency string) (int, float64, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(total_amount), 0)
+	          FROM expenses WHERE group_id = $1 AND currency = $2 AND category = 'EXPENSE'`
+	var count int
+	var total float64
+	if err := r.getQuerier().QueryRow(ctx, query, groupID, currency).Scan(&count, &total); err != nil {
+		return 0, 0, fmt.Errorf("getting expense totals: %w", err)
+	}
+	return count, total, nil
+}
+
+// GetBiggestExpense returns nil when the group has no expenses in currency.
+func (r *statsRepository) GetBiggestExpense(ctx context.Context, groupID, currency string) (*models.FunStatExpense, error) {
+	query := `SELECT e.id, e.description, e.total_amount, e.date_only::TEXT, e.paid_by_user_id, u.name
+	          FROM expenses e
+	          LEFT JOIN users u ON u.id = e.paid_by_user_id
+	          WHERE e.group_id = $1 AND e.currency = $2 AND e.category = 'EXPENSE'
+	          ORDER BY e.total_amount DESC, e.transaction_timestamp ASC
+	          LIMIT 1`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, currency)
+	if err != nil {
+		return nil, fmt.Errorf("getting biggest expense: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	var e models.FunStatExpense
+	if err := rows.Scan(&e.ExpenseID, &e.Description, &e.Amount, &e.Date, &e.PaidByID, &e.PaidByName); err != nil {
+		return nil, fmt.Errorf("scanning biggest expense: %w", err)
+	}
+	return &e, nil
+}
+
+// GetMemberPaymentStats returns every current member, including those who
+// never paid or were never part of a split.
+func (r *statsRepository) GetMemberPaymentStats(ctx context.Context, groupID, currency string) ([]models.FunStatMember, error) {
+	query := `
+		WITH group_expenses AS (
+			SELECT id FROM expenses WHERE group_id = $1 AND currency = $2 AND category = 'EXPENSE'
+		),
+		paid AS (
+			SELECT p.user_id, COUNT(DISTINCT p.expense_id) AS times_paid, SUM(p.amount_paid) AS amount_paid
+			FROM expense_payers p
+			JOIN group_expenses ge ON ge.id = p.expense_id
+			GROUP BY p.user_id
+		),
+		owed AS (
+			SELECT s.user_id, SUM(s.amount) AS amount_owed
+			FROM expense_splits s
+			JOIN group_expenses ge ON ge.id = s.expense_id
+			GROUP BY s.user_id
+		)
+		SELECT u.id, u.name, COALESCE(paid.times_paid, 0), COALESCE(paid.amount_paid, 0), COALESCE(owed.amount_owed, 0)
+		FROM group_members gm
+		JOIN users u ON u.id = gm.user_id
+		LEFT JOIN paid ON paid.user_id = gm.user_id
+		LEFT JOIN owed ON owed.user_id = gm.user_id
+		WHERE gm.group_id = $1
+		ORDER BY u.name, u.id`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, currency)
+	if err != nil {
+		return nil, fmt.Errorf("getting member payment stats: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.FunStatMember{}
+	for rows.Next() {
+		var m models.FunStatMember
+		if err := rows.Scan(&m.UserID, &m.Name, &m.TimesPaid, &m.AmountPaid, &m.AmountOwed); err != nil {
+			return nil, fmt.Errorf("scanning member payment stats: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// GetLongestQuietStreak finds the longest run of days with no expenses
+// between two days that had some. It returns nil when there is no such gap.
+func (r *statsRepository) GetLongestQuietStreak(ctx context.Context, groupID, currency string) (*models.FunStatStreak, error) {
+	query := `
+		WITH days AS (
+			SELECT DISTINCT date_only AS day
+			FROM expenses
+			WHERE group_id = $1 AND currency = $2 AND category = 'EXPENSE' AND date_only IS NOT NULL
+		),
+		gaps AS (
+			SELECT LAG(day) OVER (ORDER BY day) AS previous_day, day
+			FROM days
+		)
+		SELECT (day - previous_day - 1), (previous_day + 1)::TEXT, (day - 1)::TEXT
+		FROM gaps
+		WHERE previous_day IS NOT NULL AND day - previous_day > 1
+		ORDER BY day - previous_day DESC, day ASC
+		LIMIT 1`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, currency)
+	if err != nil {
+		return nil, fmt.Errorf("getting longest quiet streak: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	var streak models.FunStatStreak
+	if err := rows.Scan(&streak.Days, &streak.From, &streak.To); err != nil {
+		return nil, fmt.Errorf("scanning longest quiet streak: %w", err)
+	}
+	return &streak, nil
+}
+
+// GetSpendingHeatmap returns spend per day of week and hour (UTC) of
+// transaction_timestamp, and per-member payment counts, from one grouped
+// query. Spend is summed from payers, so a cell's total is the expenses'
+// cost and an expense paid by several members counts once per member.
+func (r *statsRepository) GetSpendingHeatmap(ctx context.Context, groupID, currency string) ([]models.HeatmapCell, []models.HeatmapMember, error) {
+	query := `
+		WITH payments AS (
+			SELECT e.id,
+			       EXTRACT(ISODOW FROM e.transaction_timestamp AT TIME ZONE 'UTC')::INT AS day_of_week,
+			       EXTRACT(HOUR FROM e.transaction_timestamp AT TIME ZONE 'UTC')::INT AS hour,
+			       p.user_id, COALESCE(u.name, '') AS name, p.amount_paid
+			FROM expenses e
+			JOIN expense_payers p ON p.expense_id = e.id
+			LEFT JOIN users u ON u.id = p.user_id
+			WHERE e.group_id = $1 AND e.currency = $2 AND e.category = 'EXPENSE'
+		)
+		SELECT GROUPING(user_id, name) = 0, day_of_week, hour, user_id, name,
+		       COUNT(DISTINCT id), COALESCE(SUM(amount_paid), 0)
+		FROM payments
+		GROUP BY GROUPING SETS ((day_of_week, hour), (user_id, name))
+		ORDER BY day_of_week, hour, name, user_id`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, currency)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting spending heatmap: %w", err)
+	}
+	defer rows.Close()
+
+	cells := []models.HeatmapCell{}
+	members := []models.HeatmapMember{}
+	for rows.Next() {
+		var (
+			perMember       bool
+			dayOfWeek, hour *int
+			userID, name    *string
+			count           int
+			total           float64
+		)
+		if err := rows.Scan(&perMember, &dayOfWeek, &hour, &userID, &name, &count, &total); err != nil {
+			return nil, nil, fmt.Errorf("scanning spending heatmap: %w", err)
+		}
+		if perMember {
+			members = append(members, models.HeatmapMember{UserID: *userID, Name: *name, Count: count, Total: total})
+			continue
+		}
+		cells = append(cells, models.HeatmapCell{DayOfWeek: *dayOfWeek, Hour: *hour, Count: count, Total: total})
+	}
+	return cells, members, rows.Err()
+}
+
+// GetLeaderboard returns every current member's amount paid and share of
+// expenses since the given time (or ever, when nil), ranked by net
+// contribution in one grouped query. Members who tie share a rank.
+func (r *statsRepository) GetLeaderboard(ctx context.Context, groupID, currency string, since *time.Time) ([]models.LeaderboardEntry, error) {
+	query := `
+		WITH period_expenses AS (
+			SELECT id FROM expenses
+			WHERE group_id = $1 AND currency = $2 AND category = 'EXPENSE'
+			  AND ($3::TIMESTAMPTZ IS NULL OR transaction_timestamp >= $3)
+		),
+		contributions AS (
+			SELECT p.user_id, p.amount_paid AS paid, 0 AS consumed
+			FROM expense_payers p JOIN period_expenses pe ON pe.id = p.expense_id
+			UNION ALL
+			SELECT s.user_id, 0, s.amount
+			FROM expense_splits s JOIN period_expenses pe ON pe.id = s.expense_id
+		)
+		SELECT RANK() OVER (ORDER BY ROUND((COALESCE(SUM(c.paid), 0) - COALESCE(SUM(c.consumed), 0))::NUMERIC, 2) DESC),
+		       u.id, u.name, u.avatar_url, COALESCE(SUM(c.paid), 0), COALESCE(SUM(c.consumed), 0)
+		FROM group_members gm
+		JOIN users u ON u.id = gm.user_id
+		LEFT JOIN contributions c ON c.user_id = gm.user_id
+		WHERE gm.group_id = $1
+		GROUP BY u.id, u.name, u.avatar_url
+		ORDER BY 1, u.name, u.id`
+	rows, err := r.getQuerier().Query(ctx, query, groupID, currency, since)
+	if err != nil {
+		return nil, fmt.Errorf("getting leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.LeaderboardEntry{}
+	for rows.Next() {
+		var e models.LeaderboardEntry
+		if err := rows.Scan(&e.Rank, &e.UserID, &e.Name, &e.AvatarURL, &e.Paid, &e.Consumed); err != nil {
+			return nil, fmt.Errorf("scanning leaderboard: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"unwise-backend/database"
+	"unwise-backend/models"
+
+	"github.com/google/uuid"
+)
+
+type TagRepository interface {
+	GetByGroupID(ctx context.Context, groupID string) ([]models.Tag, error)
+	GetByID(ctx context.Context, tagID string) (*models.Tag, error)
+	EnsureTags(ctx context.Context, groupID string, names []string) ([]models.Tag, error)
+	SetExpenseTags(ctx context.Context, expenseID string, tagIDs []string) error
+	GetTagNamesByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]string, error)
+	GetTotalsByGroupID(ctx context.Context, groupID string) ([]models.TagTotal, error)
+	Delete(ctx context.Context, tagID string) error
+	WithTx(tx database.Querier) TagRepository
+}
+
+type tagRepository struct {
+	db *database.DB
+	tx database.Querier
+}
+
+func NewTagRepository(db *database.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) WithTx(tx database.Querier) TagRepository {
+	return &tagRepository{db: r.db, tx: tx}
+}
+
+func (r *tagRepository) getQuerier() database.Querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Pool
+}
+
+func (r *tagRepository) GetByGroupID(ctx context.Context, groupID string) ([]models.Tag, error) {
+	query := `SELECT id, group_id, name, created_at FROM tags WHERE group_id = $1 ORDER BY name`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.ID, &t.GroupID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, tagID string) (*models.Tag, error) {
+	query := `SELECT id, group_id, name, created_at FROM tags WHERE id = $1`
+	var t models.Tag
+	if err := r.getQuerier().QueryRow(ctx, query, tagID).Scan(&t.ID, &t.GroupID, &t.Name, &t.CreatedAt); err != nil {
+		return nil, fmt.Errorf("getting tag: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *tagRepository) EnsureTags(ctx context.Context, groupID string, names []string) ([]models.Tag, error) {
+	query := `
+		INSERT INTO tags (id, group_id, name, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (group_id, name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id, group_id, name, created_at
+	`
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		var t models.Tag
+		err := r.getQuerier().QueryRow(ctx, query, uuid.New().String(), groupID, name).Scan(&t.ID, &t.GroupID, &t.Name, &t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ensuring tag %q: %w", name, err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) SetExpenseTags(ctx context.Context, expenseID string, tagIDs []string) error {
+	if _, err := r.getQuerier().Exec(ctx, `DELETE FROM expense_tags WHERE expense_id = $1`, expenseID); err != nil {
+		return fmt.Errorf("clearing expense tags: %w", err)
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO expense_tags (expense_id, tag_id)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := r.getQuerier().Exec(ctx, query, expenseID, tagIDs); err != nil {
+		return fmt.Errorf("linking expense tags: %w", err)
+	}
+	return nil
+}
+
+func (r *tagRepository) GetTagNamesByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	if len(expenseIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT et.expense_id, t.name
+		FROM expense_tags et
+		JOIN tags t ON t.id = et.tag_id
+		WHERE et.expense_id = ANY($1)
+		ORDER BY t.name
+	`
+	rows, err := r.getQuerier().Query(ctx, query, expenseIDs)
+	if err != nil {
+		return nil, fmt.Errorf("querying expense tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var expenseID, name string
+		if err := rows.Scan(&expenseID, &name); err != nil {
+			return nil, fmt.Errorf("scanning expense tag: %w", err)
+		}
+		result[expenseID] = append(result[expenseID], name)
+	}
+	return result, rows.Err()
+}
+
+func (r *tagRepository) GetTotalsByGroupID(ctx context.Context, groupID string) ([]models.TagTotal, error) {
+	query := `
+		SELECT t.id, t.name, e.currency, COALESCE(SUM(e.total_amount), 0), COUNT(e.id)
+		FROM tags t
+		JOIN expense_tags et ON et.tag_id = t.id
+		JOIN expenses e ON e.id = et.expense_id
+		WHERE t.group_id = $1
+		  AND e.category NOT IN ('PAYMENT', 'REPAYMENT')
+		GROUP BY t.id, t.name, e.currency
+		ORDER BY t.name, e.currency
+	`
+	rows, err := r.getQuerier().Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("querying tag totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []models.TagTotal{}
+	for rows.Next() {
+		var t models.TagTotal
+		if err := rows.Scan(&t.TagID, &t.Name, &t.Currency, &t.Total, &t.ExpenseCount); err != nil {
+			return nil, fmt.Errorf("scanning tag total: %w", err)
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+func (r *tagRepository) Delete(ctx context.Context, tagID string) error {
+	if _, err := r.getQuerier().Exec(ctx, `DELETE FROM tags WHERE id = $1`, tagID); err != nil {
+		return fmt.Errorf("deleting tag: %w", err)
+	}
+	return nil
+}
@@ -15,9 +15,23 @@ type UserRepository interface {
 	Update(ctx context.Context, user *models.User) error
 	UpdateAvatarURL(ctx context.Context, userID string, avatarURL string) error
 	Delete(ctx context.Context, id string) error
-	Search(ctx context.Context, query string) ([]models.User, error)
-	GetUnclaimedPlaceholders(ctx context.Context) ([]models.User, error)
-	ClaimPlaceholder(ctx context.Context, placeholderID, claimerID string) error
+	Search(ctx context.Context, searcherID, query string) ([]models.UserSearchMatch, error)
+	MatchEmailHashes(ctx context.Context, searcherID string, hashes []string) ([]models.ContactSuggestion, error)
+	GetPrivacySettings(ctx context.Context, userID string) (*models.PrivacySettings, error)
+	UpdatePrivacySettings(ctx context.Context, userID string, settings *models.PrivacySettings) error
+	GetReportSettings(ctx context.Context, userID string) (*models.ReportSettings, error)
+	UpdateReportSettings(ctx context.Context, userID string, settings *models.ReportSettings) error
+	GetBalanceAlertSettings(ctx context.Context, userID string) (*models.BalanceAlertSettings, error)
+	UpdateBalanceAlertSettings(ctx context.Context, userID string, settings *models.BalanceAlertSettings) error
+	GetUnclaimedPlaceholders(ctx context.Context, viewerID, verifiedEmail string) ([]models.User, error)
+	GetPlaceholderGroups(ctx context.Context, placeholderIDs []string) (map[string][]models.PlaceholderGroup, error)
+	GetByIDForUpdate(ctx context.Context, id string) (*models.User, error)
+	ClaimPlaceholder(ctx context.Context, placeholderID, claimerID string) (bool, error)
+	GetUsersSharingGroups(ctx context.Context, userID string) ([]models.User, error)
+	SharesTransactions(ctx context.Context, userID1, userID2 string) (bool, error)
+	MergePlaceholder(ctx context.Context, placeholderID, targetID string) (bool, error)
+	UpdateEmailVerified(ctx context.Context, userID string, verified bool) error
+	IsDeleted(ctx context.Context, userID string) (bool, error)
 	WithTx(tx database.Querier) UserRepository
 }
 
@@ -43,12 +57,12 @@ func (r *userRepository) getQuerier() database.Querier {
 
 func (r *userRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	var user models.User
-	query := `SELECT id, COALESCE(email, ''), name, avatar_url, is_placeholder, claimed_by, claimed_at, created_at, updated_at 
+	query := `SELECT id, COALESCE(email, ''), name, avatar_url, is_placeholder, claimed_by, claimed_at, email_verified, deleted_at, created_at, updated_at 
 	          FROM users WHERE id = $1`
 
 	err := r.getQuerier().QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.Email, &user.Name, &user.AvatarURL, &user.IsPlaceholder,
-		&user.ClaimedBy, &user.ClaimedAt, &user.CreatedAt, &user.UpdatedAt,
+		&user.ClaimedBy, &user.ClaimedAt, &user.EmailVerified, &user.DeletedAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("getting user by id: %w", err)
@@ -58,12 +72,12 @@ func (r *userRepository) GetByID(ctx context.Context, id string) (*models.User,
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	query := `SELECT id, COALESCE(email, ''), name, avatar_url, is_placeholder, claimed_by, claimed_at, created_at, updated_at 
+	query := `SELECT id, COALESCE(email, ''), name, avatar_url, is_placeholder, claimed_by, claimed_at, email_verified, created_at, updated_at 
 	          FROM users WHERE email = $1`
 
 	err := r.getQuerier().QueryRow(ctx, query, email).Scan(
 		&user.ID, &user.Email, &user.Name, &user.AvatarURL, &user.IsPlaceholder,
-		&user.ClaimedBy, &user.ClaimedAt, &user.CreatedAt, &user.UpdatedAt,
+		&user.ClaimedBy, &user.ClaimedAt, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("getting user by email: %w", err)
@@ -72,8 +86,8 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 }
 
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	query := `INSERT INTO users (id, email, name, avatar_url, is_placeholder, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+	query := `INSERT INTO users (id, email, name, avatar_url, is_placeholder, email_verified, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, COALESCE($6, FALSE), NOW(), NOW())
 	          ON CONFLICT (id) DO UPDATE SET
 	              email = EXCLUDED.email,
 	              name = EXCLUDED.name,
@@ -85,7 +99,7 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 		email = nil
 	}
 
-	_, err := r.getQuerier().Exec(ctx, query, user.ID, email, user.Name, user.AvatarURL, user.IsPlaceholder)
+	_, err := r.getQuerier().Exec(ctx, query, user.ID, email, user.Name, user.AvatarURL, user.IsPlaceholder, user.EmailVerified)
 	if err != nil {
 		return fmt.Errorf("creating user: %w", err)
 	}
@@ -112,8 +126,26 @@ func (r *userRepository) UpdateAvatarURL(ctx context.Context, userID string, ava
 	return nil
 }
 
+func (r *userRepository) UpdateEmailVerified(ctx context.Context, userID string, verified bool) error {
+	query := `UPDATE users SET email_verified = $1, updated_at = NOW() WHERE id = $2 AND email_verified IS DISTINCT FROM $1`
+	_, err := r.getQuerier().Exec(ctx, query, verified, userID)
+	if err != nil {
+		return fmt.Errorf("updating user email verification: %w", err)
+	}
+	return nil
+}
+
 func (r *userRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
+	query := `
+		WITH removed_memberships AS (
+			DELETE FROM group_members WHERE user_id = $1
+		), removed_friendships AS (
+			DELETE FROM friends WHERE user_id = $1 OR friend_id = $1
+		)
+		UPDATE users
+		SET deleted_at = NOW(), email = NULL, name = 'Deleted User', avatar_url = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
 	_, err := r.getQuerier().Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("deleting user: %w", err)
@@ -121,41 +153,171 @@ func (r *userRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *userRepository) Search(ctx context.Context, queryStr string) ([]models.User, error) {
+// Search finds users the searcher may discover: anyone sharing a group with
+// them by name or email substring, everyone else per their discoverability.
+// Names match without regard to case or accents, so "Jose" finds "José";
+// queryStr should be NFC-normalized like stored names.
+func (r *userRepository) Search(ctx context.Context, searcherID, queryStr string) ([]models.UserSearchMatch, error) {
 	query := `
-		SELECT id, COALESCE(email, ''), name, avatar_url, is_placeholder, claimed_by, claimed_at, created_at, updated_at
-		FROM users
-		WHERE email ILIKE '%' || $1 || '%' OR name ILIKE '%' || $1 || '%'
+		SELECT * FROM (
+			SELECT u.id, COALESCE(u.email, ''), u.name, u.avatar_url, u.is_placeholder, u.claimed_by, u.claimed_at, u.created_at, u.updated_at,
+				u.discoverability,
+				EXISTS (
+					SELECT 1 FROM group_members mine
+					JOIN group_members theirs ON theirs.group_id = mine.group_id
+					WHERE mine.user_id = $2 AND theirs.user_id = u.id
+				) AS shares_group
+			FROM users u
+			WHERE u.deleted_at IS NULL AND u.id <> $2
+				AND (u.email ILIKE '%' || $1 || '%' OR unaccent(u.name) ILIKE '%' || unaccent($1::TEXT) || '%')
+		) candidates
+		WHERE shares_group
+			OR (discoverability = 'NAME' AND (LOWER(email) = LOWER($1) OR unaccent(name) ILIKE '%' || unaccent($1::TEXT) || '%'))
+			OR (discoverability = 'EMAIL' AND LOWER(email) = LOWER($1))
+		ORDER BY shares_group DESC, name
 		LIMIT 10
 	`
-	rows, err := r.getQuerier().Query(ctx, query, queryStr)
+	rows, err := r.getQuerier().Query(ctx, query, queryStr, searcherID)
 	if err != nil {
 		return nil, fmt.Errorf("searching users: %w", err)
 	}
 	defer rows.Close()
 
-	var users []models.User
+	var matches []models.UserSearchMatch
 	for rows.Next() {
-		var u models.User
+		var m models.UserSearchMatch
+		var discoverability models.Discoverability
 		if err := rows.Scan(
-			&u.ID, &u.Email, &u.Name, &u.AvatarURL, &u.IsPlaceholder,
-			&u.ClaimedBy, &u.ClaimedAt, &u.CreatedAt, &u.UpdatedAt,
+			&m.User.ID, &m.User.Email, &m.User.Name, &m.User.AvatarURL, &m.User.IsPlaceholder,
+			&m.User.ClaimedBy, &m.User.ClaimedAt, &m.User.CreatedAt, &m.User.UpdatedAt,
+			&discoverability, &m.SharesGroup,
 		); err != nil {
 			return nil, fmt.Errorf("scanning user: %w", err)
 		}
-		users = append(users, u)
+		matches = append(matches, m)
 	}
-	return users, nil
+	return matches, nil
+}
+
+// MatchEmailHashes returns the real users whose email_sha256 is in hashes and
+// whom the searcher may discover: anyone sharing a group with them, everyone
+// else unless their discoverability is NONE (both NAME and EMAIL allow exact
+// email matches).
+func (r *userRepository) MatchEmailHashes(ctx context.Context, searcherID string, hashes []string) ([]models.ContactSuggestion, error) {
+	query := `
+		SELECT * FROM (
+			SELECT u.id, COALESCE(u.email, ''), u.name, u.avatar_url, u.is_placeholder, u.claimed_by, u.claimed_at, u.created_at, u.updated_at,
+				u.email_sha256, u.discoverability,
+				EXISTS (
+					SELECT 1 FROM group_members mine
+					JOIN group_members theirs ON theirs.group_id = mine.group_id
+					WHERE mine.user_id = $1 AND theirs.user_id = u.id
+				) AS shares_group,
+				EXISTS (SELECT 1 FROM friends f WHERE f.user_id = $1 AND f.friend_id = u.id) AS is_friend
+			FROM users u
+			WHERE u.email_sha256 = ANY($2) AND u.deleted_at IS NULL
+				AND u.id <> $1 AND NOT u.is_placeholder
+		) candidates
+		WHERE shares_group OR discoverability <> 'NONE'
+		ORDER BY is_friend, shares_group DESC, name
+	`
+	rows, err := r.getQuerier().Query(ctx, query, searcherID, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("matching email hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.ContactSuggestion
+	for rows.Next() {
+		var m models.ContactSuggestion
+		var discoverability models.Discoverability
+		if err := rows.Scan(
+			&m.User.ID, &m.User.Email, &m.User.Name, &m.User.AvatarURL, &m.User.IsPlaceholder,
+			&m.User.ClaimedBy, &m.User.ClaimedAt, &m.User.CreatedAt, &m.User.UpdatedAt,
+			&m.ContactHash, &discoverability, &m.SharesGroup, &m.IsFriend,
+		); err != nil {
+			return nil, fmt.Errorf("scanning contact match: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+func (r *userRepository) GetPrivacySettings(ctx context.Context, userID string) (*models.PrivacySettings, error) {
+	query := `SELECT discoverability FROM users WHERE id = $1 AND deleted_at IS NULL`
+	var settings models.PrivacySettings
+	if err := r.getQuerier().QueryRow(ctx, query, userID).Scan(&settings.Discoverability); err != nil {
+		return nil, fmt.Errorf("getting privacy settings: %w", err)
+	}
+	return &settings, nil
+}
+
+func (r *userRepository) UpdatePrivacySettings(ctx context.Context, userID string, settings *models.PrivacySettings) error {
+	query := `UPDATE users SET discoverability = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := r.getQuerier().Exec(ctx, query, settings.Discoverability, userID); err != nil {
+		return fmt.Errorf("updating privacy settings: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) GetReportSettings(ctx context.Context, userID string) (*models.ReportSettings, error) {
+	query := `SELECT week_start, fiscal_month_start_day FROM users WHERE id = $1 AND deleted_at IS NULL`
+	var settings models.ReportSettings
+	if err := r.getQuerier().QueryRow(ctx, query, userID).Scan(&settings.WeekStart, &settings.FiscalMonthStartDay); err != nil {
+		return nil, fmt.Errorf("getting report settings: %w", err)
+	}
+	return &settings, nil
 }
 
-func (r *userRepository) GetUnclaimedPlaceholders(ctx context.Context) ([]models.User, error) {
+func (r *userRepository) UpdateReportSettings(ctx context.Context, userID string, settings *models.ReportSettings) error {
+	query := `UPDATE users SET week_start = $1, fiscal_month_start_day = $2, updated_at = NOW() WHERE id = $3`
+	if _, err := r.getQuerier().Exec(ctx, query, settings.WeekStart, settings.FiscalMonthStartDay, userID); err != nil {
+		return fmt.Errorf("updating report settings: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) GetBalanceAlertSettings(ctx context.Context, userID string) (*models.BalanceAlertSettings, error) {
+	query := `SELECT balance_alert_threshold, balance_alert_currency FROM users WHERE id = $1 AND deleted_at IS NULL`
+	var settings models.BalanceAlertSettings
+	if err := r.getQuerier().QueryRow(ctx, query, userID).Scan(&settings.Threshold, &settings.Currency); err != nil {
+		return nil, fmt.Errorf("getting balance alert settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateBalanceAlertSettings also forgets the alerts already sent, so the new
+// threshold is checked from scratch.
+func (r *userRepository) UpdateBalanceAlertSettings(ctx context.Context, userID string, settings *models.BalanceAlertSettings) error {
 	query := `
-		SELECT id, COALESCE(email, ''), name, avatar_url, is_placeholder, claimed_by, claimed_at, created_at, updated_at
-		FROM users
-		WHERE is_placeholder = TRUE AND claimed_by IS NULL
-		ORDER BY name
+		WITH cleared AS (DELETE FROM balance_alerts WHERE user_id = $3)
+		UPDATE users SET balance_alert_threshold = $1, balance_alert_currency = $2, updated_at = NOW() WHERE id = $3
 	`
-	rows, err := r.getQuerier().Query(ctx, query)
+	if _, err := r.getQuerier().Exec(ctx, query, settings.Threshold, settings.Currency, userID); err != nil {
+		return fmt.Errorf("updating balance alert settings: %w", err)
+	}
+	return nil
+}
+
+// GetUnclaimedPlaceholders lists the unclaimed placeholders viewerID may
+// see: those in a group with viewerID, and those whose email matches
+// verifiedEmail, which must be empty unless the viewer has verified it.
+func (r *userRepository) GetUnclaimedPlaceholders(ctx context.Context, viewerID, verifiedEmail string) ([]models.User, error) {
+	query := `
+		SELECT u.id, COALESCE(u.email, ''), u.name, u.avatar_url, u.is_placeholder, u.claimed_by, u.claimed_at, u.created_at, u.updated_at
+		FROM users u
+		WHERE u.is_placeholder = TRUE AND u.claimed_by IS NULL AND u.deleted_at IS NULL
+		  AND (
+		      EXISTS (
+		          SELECT 1 FROM group_members pm
+		          JOIN group_members vm ON vm.group_id = pm.group_id
+		          WHERE pm.user_id = u.id AND vm.user_id = $1
+		      )
+		      OR ($2 <> '' AND LOWER(u.email) = LOWER($2))
+		  )
+		ORDER BY u.name
+	`
+	rows, err := r.getQuerier().Query(ctx, query, viewerID, verifiedEmail)
 	if err != nil {
 		return nil, fmt.Errorf("getting unclaimed placeholders: %w", err)
 	}
@@ -175,11 +337,175 @@ func (r *userRepository) GetUnclaimedPlaceholders(ctx context.Context) ([]models
 	return users, nil
 }
 
-func (r *userRepository) ClaimPlaceholder(ctx context.Context, placeholderID, claimerID string) error {
-	query := `UPDATE users SET claimed_by = $1, claimed_at = NOW(), updated_at = NOW() WHERE id = $2 AND is_placeholder = TRUE`
-	_, err := r.getQuerier().Exec(ctx, query, claimerID, placeholderID)
+func (r *userRepository) GetPlaceholderGroups(ctx context.Context, placeholderIDs []string) (map[string][]models.PlaceholderGroup, error) {
+	result := make(map[string][]models.PlaceholderGroup)
+	if len(placeholderIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		WITH paid AS (
+			SELECT p.user_id, e.group_id, e.currency, SUM(p.amount_paid) AS amount
+			FROM expense_payers p
+			JOIN expenses e ON e.id = p.expense_id
+			WHERE p.user_id = ANY($1)
+			GROUP BY p.user_id, e.group_id, e.currency
+		),
+		owed AS (
+			SELECT s.user_id, e.group_id, e.currency, SUM(s.amount) AS amount
+			FROM expense_splits s
+			JOIN expenses e ON e.id = s.expense_id
+			WHERE s.user_id = ANY($1)
+			GROUP BY s.user_id, e.group_id, e.currency
+		),
+		net AS (
+			SELECT user_id, group_id, currency,
+			       COALESCE(paid.amount, 0) - COALESCE(owed.amount, 0) AS balance
+			FROM paid
+			FULL OUTER JOIN owed USING (user_id, group_id, currency)
+		)
+		SELECT gm.user_id, g.id, g.name, net.currency, net.balance
+		FROM group_members gm
+		JOIN groups g ON g.id = gm.group_id
+		LEFT JOIN net ON net.user_id = gm.user_id AND net.group_id = gm.group_id
+		WHERE gm.user_id = ANY($1)
+		ORDER BY gm.user_id, g.name, g.id, net.currency
+	`
+	rows, err := r.getQuerier().Query(ctx, query, placeholderIDs)
 	if err != nil {
-		return fmt.Errorf("claiming placeholder: %w", err)
+		return nil, fmt.Errorf("getting placeholder groups: %w", err)
 	}
-	return nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, groupID, groupName string
+		var currency *string
+		var balance *float64
+		if err := rows.Scan(&userID, &groupID, &groupName, &currency, &balance); err != nil {
+			return nil, fmt.Errorf("scanning placeholder group: %w", err)
+		}
+
+		groups := result[userID]
+		if len(groups) == 0 || groups[len(groups)-1].GroupID != groupID {
+			groups = append(groups, models.PlaceholderGroup{
+				GroupID:   groupID,
+				GroupName: groupName,
+				Balances:  []models.CurrencyAmount{},
+			})
+		}
+		if currency != nil && balance != nil {
+			last := &groups[len(groups)-1]
+			last.Balances = append(last.Balances, models.CurrencyAmount{
+				Currency: *currency,
+				Amount:   *balance,
+			})
+		}
+		result[userID] = groups
+	}
+	return result, rows.Err()
+}
+
+func (r *userRepository) GetByIDForUpdate(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	query := `SELECT id, COALESCE(email, ''), name, avatar_url, is_placeholder, claimed_by, claimed_at, email_verified, created_at, updated_at
+	          FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+
+	err := r.getQuerier().QueryRow(ctx, query, id).Scan(
+		&user.ID, &user.Email, &user.Name, &user.AvatarURL, &user.IsPlaceholder,
+		&user.ClaimedBy, &user.ClaimedAt, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("locking user by id: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) ClaimPlaceholder(ctx context.Context, placeholderID, claimerID string) (bool, error) {
+	query := `UPDATE users SET claimed_by = $1, claimed_at = NOW(), updated_at = NOW()
+	          WHERE id = $2 AND is_placeholder = TRUE AND claimed_by IS NULL`
+	tag, err := r.getQuerier().Exec(ctx, query, claimerID, placeholderID)
+	if err != nil {
+		return false, fmt.Errorf("claiming placeholder: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetUsersSharingGroups returns userID and every active user or unclaimed
+// placeholder that is in at least one group with them.
+func (r *userRepository) GetUsersSharingGroups(ctx context.Context, userID string) ([]models.User, error) {
+	query := `
+		SELECT DISTINCT u.id, COALESCE(u.email, ''), u.name, u.avatar_url, u.is_placeholder, u.claimed_by, u.claimed_at, u.created_at, u.updated_at
+		FROM users u
+		JOIN group_members gm ON gm.user_id = u.id
+		JOIN group_members mine ON mine.group_id = gm.group_id AND mine.user_id = $1
+		WHERE u.deleted_at IS NULL AND u.claimed_by IS NULL
+		ORDER BY u.name, u.id
+	`
+	rows, err := r.getQuerier().Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting users sharing groups: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(
+			&u.ID, &u.Email, &u.Name, &u.AvatarURL, &u.IsPlaceholder,
+			&u.ClaimedBy, &u.ClaimedAt, &u.CreatedAt, &u.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning user sharing groups: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// SharesTransactions reports whether both users paid for or split any one
+// transaction, which means they are different people.
+func (r *userRepository) SharesTransactions(ctx context.Context, userID1, userID2 string) (bool, error) {
+	query := `
+		WITH involved AS (
+			SELECT expense_id, user_id FROM expense_payers WHERE user_id IN ($1, $2)
+			UNION
+			SELECT expense_id, user_id FROM expense_splits WHERE user_id IN ($1, $2)
+		)
+		SELECT EXISTS (
+			SELECT 1 FROM involved GROUP BY expense_id HAVING COUNT(DISTINCT user_id) = 2
+		)
+	`
+	var shared bool
+	if err := r.getQuerier().QueryRow(ctx, query, userID1, userID2).Scan(&shared); err != nil {
+		return false, fmt.Errorf("checking shared transactions: %w", err)
+	}
+	return shared, nil
+}
+
+// MergePlaceholder moves placeholderID's group memberships to targetID and
+// marks it as claimed by the target. Transactions are moved separately with
+// ExpenseRepository.TransferExpenses.
+func (r *userRepository) MergePlaceholder(ctx context.Context, placeholderID, targetID string) (bool, error) {
+	membershipQuery := `
+		INSERT INTO group_members (group_id, user_id)
+		SELECT group_id, $2 FROM group_members WHERE user_id = $1
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := r.getQuerier().Exec(ctx, membershipQuery, placeholderID, targetID); err != nil {
+		return false, fmt.Errorf("copying placeholder memberships: %w", err)
+	}
+
+	if _, err := r.getQuerier().Exec(ctx, `DELETE FROM group_members WHERE user_id = $1`, placeholderID); err != nil {
+		return false, fmt.Errorf("removing placeholder memberships: %w", err)
+	}
+
+	return r.ClaimPlaceholder(ctx, placeholderID, targetID)
+}
+
+func (r *userRepository) IsDeleted(ctx context.Context, userID string) (bool, error) {
+	var deleted bool
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NOT NULL)`
+	if err := r.getQuerier().QueryRow(ctx, query, userID).Scan(&deleted); err != nil {
+		return false, fmt.Errorf("checking user deletion: %w", err)
+	}
+	return deleted, nil
 }
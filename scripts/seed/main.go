@@ -1,18 +1,16 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"strings"
 	"time"
 
 	"unwise-backend/config"
 	"unwise-backend/database"
 	"unwise-backend/models"
+	"unwise-backend/supabase"
 
 	"github.com/google/uuid"
 )
@@ -202,9 +200,18 @@ func seedUsers(ctx context.Context, db *database.DB, cfg *config.Config) ([]mode
 	}
 
 	if cfg.SupabaseURL != "" && cfg.SupabaseServiceRoleKey != "" {
+		admin, err := supabase.NewAdminClient(supabase.AdminConfig{
+			URL:            cfg.SupabaseURL,
+			ServiceRoleKey: cfg.SupabaseServiceRoleKey,
+			Timeout:        cfg.SupabaseAdminTimeout,
+			MaxRetries:     cfg.SupabaseAdminMaxRetries,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Supabase admin client: %w", err)
+		}
 		log.Println("Creating users in Supabase Auth...")
 		for _, user := range users {
-			if err := createSupabaseAuthUser(ctx, cfg, user.ID, user.Email, user.Name, testPassword); err != nil {
+			if err := createSupabaseAuthUser(ctx, admin, user.ID, user.Email, user.Name, testPassword); err != nil {
 				log.Printf("Warning: Failed to create Supabase Auth user for %s: %v", user.Email, err)
 				log.Println("Continuing with database user creation...")
 			}
@@ -235,49 +242,17 @@ func seedUsers(ctx context.Context, db *database.DB, cfg *config.Config) ([]mode
 	return users, nil
 }
 
-func createSupabaseAuthUser(ctx context.Context, cfg *config.Config, userID, email, name, password string) error {
-	url := fmt.Sprintf("%s/auth/v1/admin/users", strings.TrimSuffix(cfg.SupabaseURL, "/"))
-
-	payload := map[string]interface{}{
-		"id":            userID,
-		"email":         email,
-		"password":      password,
-		"email_confirm": true,
-		"user_metadata": map[string]interface{}{
+func createSupabaseAuthUser(ctx context.Context, admin supabase.AdminClient, userID, email, name, password string) error {
+	_, err := admin.CreateUser(ctx, supabase.CreateUserParams{
+		ID:           userID,
+		Email:        email,
+		Password:     password,
+		EmailConfirm: true,
+		UserMetadata: map[string]interface{}{
 			"name": name,
 		},
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("apikey", cfg.SupabaseServiceRoleKey)
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.SupabaseServiceRoleKey))
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		var errorBody map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&errorBody); err == nil {
-			return fmt.Errorf("supabase API error (status %d): %v", resp.StatusCode, errorBody)
-		}
-		return fmt.Errorf("supabase API error: status %d", resp.StatusCode)
-	}
-
-	return nil
+	})
+	return err
 }
 
 func seedGroups(ctx context.Context, db *database.DB, users []models.User) ([]models.Group, error) {
@@ -479,14 +454,14 @@ func seedExpenses(ctx context.Context, db *database.DB, groups []models.Group, u
 	expenses = append(expenses, expense8)
 
 	expenseQuery := `
-		INSERT INTO expenses (id, group_id, paid_by_user_id, total_amount, description, receipt_image_url, type, category, created_at, updated_at)
+		INSERT INTO expenses (id, group_id, paid_by_user_id, total_amount, description, receipt_image_path, type, category, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	for _, expense := range expenses {
 		if _, err := db.Pool.Exec(ctx, expenseQuery,
 			expense.ID, expense.GroupID, expense.PaidByUserID, expense.TotalAmount,
-			expense.Description, expense.ReceiptImageURL, expense.Type, expense.Category,
+			expense.Description, expense.ReceiptImagePath, expense.Type, expense.Category,
 			expense.CreatedAt, expense.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to insert expense: %w", err)
@@ -0,0 +1,314 @@
+// Command stubgen writes test stubs for the interfaces of a package: one
+// struct per interface whose methods all return zero values and, where the
+// method returns an error, a "not stubbed" error. Test doubles embed a stub
+// and override only the methods a test relies on, so a call the test did not
+// expect fails with an error naming the method instead of a nil panic.
+//
+// It only needs the standard library, so it runs from go:generate without
+// any tool being installed:
+//
+//	//go:generate go run ../scripts/stubgen -src ../repository -import unwise-backend/repository -out repository_stubs_test.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	src := flag.String("src", ".", "directory of the package whose interfaces are stubbed")
+	importPath := flag.String("import", "", "import path of the source package; empty when the stubs live in the same package")
+	pkg := flag.String("pkg", "", "package of the generated file (default: the package in the current directory)")
+	names := flag.String("interfaces", "", "comma-separated interfaces to stub (default: all exported interfaces)")
+	out := flag.String("out", "", "output file")
+	flag.Parse()
+
+	if *out == "" {
+		log.Fatal("stubgen: -out is required")
+	}
+	if *pkg == "" {
+		name, err := packageName(".")
+		if err != nil {
+			log.Fatalf("stubgen: %v", err)
+		}
+		*pkg = name
+	}
+
+	g, err := load(*src, *importPath)
+	if err != nil {
+		log.Fatalf("stubgen: %v", err)
+	}
+
+	var selected []string
+	if *names != "" {
+		for _, n := range strings.Split(*names, ",") {
+			n = strings.TrimSpace(n)
+			if _, ok := g.interfaces[n]; !ok {
+				log.Fatalf("stubgen: no interface %s in %s", n, *src)
+			}
+			selected = append(selected, n)
+		}
+	} else {
+		for n := range g.interfaces {
+			if ast.IsExported(n) {
+				selected = append(selected, n)
+			}
+		}
+		sort.Strings(selected)
+	}
+
+	code, err := g.generate(*pkg, selected)
+	if err != nil {
+		log.Fatalf("stubgen: %v", err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatalf("stubgen: %v", err)
+	}
+}
+
+type generator struct {
+	fset       *token.FileSet
+	importPath string
+	qualifier  string
+	interfaces map[string]*ast.InterfaceType
+	// imports maps the package names used in the source files to their paths.
+	imports map[string]string
+	used    map[string]bool
+}
+
+func packageName(dir string) (string, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	for name := range pkgs {
+		if !strings.HasSuffix(name, "_test") {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no package in %s", dir)
+}
+
+func load(dir, importPath string) (*generator, error) {
+	fset := token.NewFileSet()
+	notTest := func(fi os.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }
+	pkgs, err := parser.ParseDir(fset, dir, notTest, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		fset:       fset,
+		importPath: importPath,
+		interfaces: make(map[string]*ast.InterfaceType),
+		imports:    make(map[string]string),
+		used:       make(map[string]bool),
+	}
+	for _, p := range pkgs {
+		for _, f := range p.Files {
+			for _, imp := range f.Imports {
+				path, _ := strconv.Unquote(imp.Path.Value)
+				name := path[strings.LastIndex(path, "/")+1:]
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				g.imports[name] = path
+			}
+			if importPath != "" {
+				g.qualifier = f.Name.Name
+			}
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if it, ok := ts.Type.(*ast.InterfaceType); ok {
+						g.interfaces[ts.Name.Name] = it
+					}
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+type method struct {
+	name string
+	typ  *ast.FuncType
+}
+
+// methods lists an interface's methods, expanding interfaces it embeds.
+func (g *generator) methods(name string) ([]method, error) {
+	it := g.interfaces[name]
+	var ms []method
+	for _, field := range it.Methods.List {
+		switch t := field.Type.(type) {
+		case *ast.FuncType:
+			for _, n := range field.Names {
+				ms = append(ms, method{name: n.Name, typ: t})
+			}
+		case *ast.Ident:
+			if _, ok := g.interfaces[t.Name]; !ok {
+				return nil, fmt.Errorf("%s embeds %s, which is not an interface in the package", name, t.Name)
+			}
+			embedded, err := g.methods(t.Name)
+			if err != nil {
+				return nil, err
+			}
+			ms = append(ms, embedded...)
+		default:
+			return nil, fmt.Errorf("%s embeds an interface from another package, which is not supported", name)
+		}
+	}
+	return ms, nil
+}
+
+// qualify rewrites the source package's own types for use from another
+// package and records which imports the rewritten expression needs.
+func (g *generator) qualify(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if g.qualifier != "" && ast.IsExported(t.Name) {
+			g.used[g.qualifier] = true
+			return &ast.SelectorExpr{X: ast.NewIdent(g.qualifier), Sel: ast.NewIdent(t.Name)}
+		}
+		return t
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			g.used[x.Name] = true
+		}
+		return t
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: g.qualify(t.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: t.Len, Elt: g.qualify(t.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: g.qualify(t.Key), Value: g.qualify(t.Value)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: g.qualify(t.Elt)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: t.Dir, Value: g.qualify(t.Value)}
+	case *ast.FuncType:
+		return &ast.FuncType{Params: g.qualifyFields(t.Params), Results: g.qualifyFields(t.Results)}
+	default:
+		return t
+	}
+}
+
+func (g *generator) qualifyFields(fields *ast.FieldList) *ast.FieldList {
+	if fields == nil {
+		return nil
+	}
+	out := &ast.FieldList{}
+	for _, f := range fields.List {
+		out.List = append(out.List, &ast.Field{Names: f.Names, Type: g.qualify(f.Type)})
+	}
+	return out
+}
+
+func (g *generator) expr(e ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, e)
+	return buf.String()
+}
+
+// types flattens a field list into one type per value, so "a, b string"
+// becomes two strings.
+func (g *generator) types(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	var types []string
+	for _, f := range fields.List {
+		t := g.expr(g.qualify(f.Type))
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func (g *generator) generate(pkg string, names []string) ([]byte, error) {
+	var body bytes.Buffer
+	for _, name := range names {
+		ms, err := g.methods(name)
+		if err != nil {
+			return nil, err
+		}
+		stub := "stub" + name
+		self := name
+		if g.qualifier != "" {
+			self = g.qualifier + "." + name
+			g.used[g.qualifier] = true
+		}
+
+		fmt.Fprintf(&body, "\n// %s implements %s; every method is unstubbed.\ntype %s struct{}\n", stub, self, stub)
+		for _, m := range ms {
+			params := g.types(m.typ.Params)
+			results := g.types(m.typ.Results)
+
+			named := make([]string, len(results))
+			for i, r := range results {
+				named[i] = fmt.Sprintf("r%d %s", i, r)
+			}
+			var ret string
+			switch {
+			case len(results) > 0 && results[len(results)-1] == "error":
+				vals := make([]string, len(results))
+				for i := range results[:len(results)-1] {
+					vals[i] = fmt.Sprintf("r%d", i)
+				}
+				vals[len(vals)-1] = fmt.Sprintf("errNotStubbed(%q)", name+"."+m.name)
+				ret = "return " + strings.Join(vals, ", ")
+			case len(results) == 1 && results[0] == self:
+				ret = "return s"
+			default:
+				ret = "return"
+			}
+
+			resultList := ""
+			if len(named) > 0 {
+				resultList = " (" + strings.Join(named, ", ") + ")"
+			}
+			fmt.Fprintf(&body, "func (s %s) %s(%s)%s { %s }\n", stub, m.name, strings.Join(params, ", "), resultList, ret)
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by scripts/stubgen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	var imports []string
+	for name := range g.used {
+		path, ok := g.imports[name]
+		if name == g.qualifier {
+			path, ok = g.importPath, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("no import for package %s", name)
+		}
+		imports = append(imports, strconv.Quote(path))
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%s\n", imp)
+	}
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
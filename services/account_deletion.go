@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+
+	"go.uber.org/zap"
+)
+
+// GetDeletionBlockers lists the groups where userID still has a balance and
+// the people they would settle with, so they know what to clear before
+// deleting their account.
+func (s *userService) GetDeletionBlockers(ctx context.Context, userID string) (*models.DeletionBlockers, error) {
+	groups, err := s.groupRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting user groups", err)
+	}
+
+	balances := make(map[string]map[string]float64)
+	settlements := make(map[string][]models.Settlement)
+	for _, group := range groups {
+		memberBalances, err := s.expenseRepo.GetGroupMemberBalances(ctx, group.ID, nil)
+		if err != nil {
+			return nil, apperrors.DatabaseError("getting group member balances", err)
+		}
+		own := memberBalances[userID]
+		if !hasOpenBalance(own) {
+			continue
+		}
+		balances[group.ID] = own
+
+		groupSettlements, err := s.settlementService.CalculateSettlements(ctx, group.ID, userID, nil)
+		if err != nil {
+			zap.L().Warn("Failed to calculate settlements for deletion blockers", zap.String("group_id", group.ID), zap.Error(err))
+			continue
+		}
+		settlements[group.ID] = groupSettlements
+	}
+
+	return buildDeletionBlockers(userID, groups, balances, settlements), nil
+}
+
+func hasOpenBalance(byCurrency map[string]float64) bool {
+	for _, balance := range byCurrency {
+		if math.Abs(balance) > BalanceThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// buildDeletionBlockers turns the user's per-group balances into the group
+// list, and the suggested settlements involving them into a per-person list
+// summed across groups. Both are sorted by name.
+func buildDeletionBlockers(userID string, groups []models.Group, balances map[string]map[string]float64, settlements map[string][]models.Settlement) *models.DeletionBlockers {
+	blockers := &models.DeletionBlockers{
+		Groups: make([]models.DeletionBlockerGroup, 0),
+		People: make([]models.DeletionBlockerPerson, 0),
+	}
+
+	members := make(map[string]models.User)
+	for _, group := range groups {
+		for _, member := range group.Members {
+			members[member.ID] = member
+		}
+	}
+
+	owed := make(map[string]map[string]float64)
+	for _, group := range groups {
+		groupBalances := currencyAmounts(balances[group.ID])
+		if len(groupBalances) == 0 {
+			continue
+		}
+		blockers.Groups = append(blockers.Groups, models.DeletionBlockerGroup{
+			GroupID:  group.ID,
+			Name:     group.Name,
+			Balances: groupBalances,
+		})
+
+		for _, st := range settlements[group.ID] {
+			otherID, amount := st.FromUserID, st.Amount
+			if st.FromUserID == userID {
+				otherID, amount = st.ToUserID, -st.Amount
+			} else if st.ToUserID != userID {
+				continue
+			}
+			if owed[otherID] == nil {
+				owed[otherID] = make(map[string]float64)
+			}
+			owed[otherID][st.Currency] += amount
+		}
+	}
+
+	for otherID, byCurrency := range owed {
+		personBalances := currencyAmounts(byCurrency)
+		if len(personBalances) == 0 {
+			continue
+		}
+		member := members[otherID]
+		blockers.People = append(blockers.People, models.DeletionBlockerPerson{
+			User:     models.UserInfo{ID: otherID, Name: member.Name, AvatarURL: member.AvatarURL},
+			Balances: personBalances,
+		})
+	}
+
+	sort.Slice(blockers.Groups, func(i, j int) bool {
+		if blockers.Groups[i].Name != blockers.Groups[j].Name {
+			return blockers.Groups[i].Name < blockers.Groups[j].Name
+		}
+		return blockers.Groups[i].GroupID < blockers.Groups[j].GroupID
+	})
+	sort.Slice(blockers.People, func(i, j int) bool {
+		if blockers.People[i].User.Name != blockers.People[j].User.Name {
+			return blockers.People[i].User.Name < blockers.People[j].User.Name
+		}
+		return blockers.People[i].User.ID < blockers.People[j].User.ID
+	})
+
+	blockers.CanDelete = len(blockers.Groups) == 0
+	return blockers
+}
+
+// currencyAmounts rounds byCurrency to cents, drops settled currencies and
+// sorts the rest by currency code.
+func currencyAmounts(byCurrency map[string]float64) []models.CurrencyAmount {
+	amounts := make([]models.CurrencyAmount, 0, len(byCurrency))
+	for currency, amount := range byCurrency {
+		amount = math.Round(amount*RoundingFactor) / RoundingFactor
+		if math.Abs(amount) <= BalanceThreshold {
+			continue
+		}
+		amounts = append(amounts, models.CurrencyAmount{Currency: currency, Amount: amount})
+	}
+	sort.Slice(amounts, func(i, j int) bool { return amounts[i].Currency < amounts[j].Currency })
+	return amounts
+}
+
+// summarizeDeletionBlockers renders the group list for the deletion error,
+// e.g. "Goa Trip (INR -250.00), Flat (USD 20.00)".
+func summarizeDeletionBlockers(blockers *models.DeletionBlockers) string {
+	parts := make([]string, 0, len(blockers.Groups))
+	for _, group := range blockers.Groups {
+		amounts := make([]string, len(group.Balances))
+		for i, b := range group.Balances {
+			amounts[i] = fmt.Sprintf("%s %.2f", b.Currency, b.Amount)
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", group.Name, strings.Join(amounts, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestBuildDeletionBlockers(t *testing.T) {
+	groups := []models.Group{
+		{ID: "g1", Name: "Goa Trip", Members: []models.User{{ID: "me", Name: "Me"}, {ID: "A", Name: "Asha"}, {ID: "B", Name: "Ben"}}},
+		{ID: "g2", Name: "Flat", Members: []models.User{{ID: "me", Name: "Me"}, {ID: "A", Name: "Asha"}}},
+		{ID: "g3", Name: "Settled", Members: []models.User{{ID: "me", Name: "Me"}, {ID: "B", Name: "Ben"}}},
+	}
+
+	tests := []struct {
+		name        string
+		balances    map[string]map[string]float64
+		settlements map[string][]models.Settlement
+		groups      []models.DeletionBlockerGroup
+		people      []models.DeletionBlockerPerson
+		summary     string
+	}{
+		{
+			name:   "Nothing outstanding",
+			groups: []models.DeletionBlockerGroup{},
+			people: []models.DeletionBlockerPerson{},
+		},
+		{
+			name: "Balances across groups and currencies",
+			balances: map[string]map[string]float64{
+				"g1": {"INR": -250},
+				"g2": {"INR": 100, "USD": 20.004},
+				"g3": {"INR": 0.004},
+			},
+			settlements: map[string][]models.Settlement{
+				"g1": {
+					{FromUserID: "me", ToUserID: "A", Amount: 150, Currency: "INR"},
+					{FromUserID: "me", ToUserID: "B", Amount: 100, Currency: "INR"},
+				},
+				"g2": {
+					{FromUserID: "A", ToUserID: "me", Amount: 100, Currency: "INR"},
+					{FromUserID: "A", ToUserID: "me", Amount: 20, Currency: "USD"},
+				},
+			},
+			groups: []models.DeletionBlockerGroup{
+				{GroupID: "g2", Name: "Flat", Balances: []models.CurrencyAmount{{Currency: "INR", Amount: 100}, {Currency: "USD", Amount: 20}}},
+				{GroupID: "g1", Name: "Goa Trip", Balances: []models.CurrencyAmount{{Currency: "INR", Amount: -250}}},
+			},
+			people: []models.DeletionBlockerPerson{
+				{User: models.UserInfo{ID: "A", Name: "Asha"}, Balances: []models.CurrencyAmount{{Currency: "INR", Amount: -50}, {Currency: "USD", Amount: 20}}},
+				{User: models.UserInfo{ID: "B", Name: "Ben"}, Balances: []models.CurrencyAmount{{Currency: "INR", Amount: -100}}},
+			},
+			summary: "Flat (INR 100.00, USD 20.00), Goa Trip (INR -250.00)",
+		},
+		{
+			name:     "Debts to one person cancel out across groups",
+			balances: map[string]map[string]float64{"g1": {"INR": -100}, "g2": {"INR": 100}},
+			settlements: map[string][]models.Settlement{
+				"g1": {{FromUserID: "me", ToUserID: "A", Amount: 100, Currency: "INR"}},
+				"g2": {{FromUserID: "A", ToUserID: "me", Amount: 100, Currency: "INR"}},
+			},
+			groups: []models.DeletionBlockerGroup{
+				{GroupID: "g2", Name: "Flat", Balances: []models.CurrencyAmount{{Currency: "INR", Amount: 100}}},
+				{GroupID: "g1", Name: "Goa Trip", Balances: []models.CurrencyAmount{{Currency: "INR", Amount: -100}}},
+			},
+			people:  []models.DeletionBlockerPerson{},
+			summary: "Flat (INR 100.00), Goa Trip (INR -100.00)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockers := buildDeletionBlockers("me", groups, tt.balances, tt.settlements)
+			if !reflect.DeepEqual(blockers.Groups, tt.groups) {
+				t.Errorf("groups = %+v, expected %+v", blockers.Groups, tt.groups)
+			}
+			if !reflect.DeepEqual(blockers.People, tt.people) {
+				t.Errorf("people = %+v, expected %+v", blockers.People, tt.people)
+			}
+			if blockers.CanDelete != (len(tt.groups) == 0) {
+				t.Errorf("can_delete = %v", blockers.CanDelete)
+			}
+			if summary := summarizeDeletionBlockers(blockers); summary != tt.summary {
+				t.Errorf("summary = %q, expected %q", summary, tt.summary)
+			}
+		})
+	}
+}
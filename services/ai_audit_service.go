@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AIAuditService interface {
+	Record(ctx context.Context, output *models.AIOutput, names []string) error
+	LatestOutputID(ctx context.Context, expenseID string, kind models.AIOutputKind) string
+	SubmitFeedback(ctx context.Context, outputID, userID string, rating models.AIFeedbackRating, comment *string) (*models.AIFeedback, error)
+	GetStats(ctx context.Context) ([]models.AIOutputStats, error)
+}
+
+type aiAuditService struct {
+	auditRepo   repository.AIAuditRepository
+	expenseRepo repository.ExpenseWriter
+	groupRepo   repository.GroupRepository
+}
+
+func NewAIAuditService(auditRepo repository.AIAuditRepository, expenseRepo repository.ExpenseWriter, groupRepo repository.GroupRepository) AIAuditService {
+	return &aiAuditService{
+		auditRepo:   auditRepo,
+		expenseRepo: expenseRepo,
+		groupRepo:   groupRepo,
+	}
+}
+
+var (
+	redactEmailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+`)
+	redactNumberPattern = regexp.MustCompile(`\d[\d -]{8,}\d`)
+)
+
+func (s *aiAuditService) Record(ctx context.Context, output *models.AIOutput, names []string) error {
+	output.ID = uuid.New().String()
+	output.Prompt = redactAIText(output.Prompt, names)
+	output.Response = redactAIText(output.Response, names)
+	if err := s.auditRepo.CreateOutput(ctx, output); err != nil {
+		return apperrors.DatabaseError("recording ai output", err)
+	}
+	return nil
+}
+
+func (s *aiAuditService) LatestOutputID(ctx context.Context, expenseID string, kind models.AIOutputKind) string {
+	output, err := s.auditRepo.GetLatestOutputForExpense(ctx, expenseID, kind)
+	if err != nil {
+		if !apperrors.IsNotFoundError(err) {
+			zap.L().Warn("Failed to look up ai output", zap.String("expense_id", expenseID), zap.Error(err))
+		}
+		return ""
+	}
+	return output.ID
+}
+
+func (s *aiAuditService) SubmitFeedback(ctx context.Context, outputID, userID string, rating models.AIFeedbackRating, comment *string) (*models.AIFeedback, error) {
+	if !rating.IsValid() {
+		return nil, apperrors.InvalidRequest("Rating must be UP or DOWN.")
+	}
+	if comment != nil {
+		trimmed := strings.TrimSpace(*comment)
+		if len(trimmed) > MaxAIFeedbackCommentLength {
+			return nil, apperrors.InvalidRequest(fmt.Sprintf("Comment cannot exceed %d characters.", MaxAIFeedbackCommentLength))
+		}
+		comment = nil
+		if trimmed != "" {
+			comment = &trimmed
+		}
+	}
+
+	output, err := s.auditRepo.GetOutputByID(ctx, outputID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.NotFound("AI output")
+		}
+		return nil, apperrors.DatabaseError("getting ai output", err)
+	}
+
+	switch {
+	case output.GroupID != nil:
+		if err := RequireGroupMembership(ctx, s.groupRepo, *output.GroupID, userID); err != nil {
+			return nil, err
+		}
+	case output.UserID == nil || *output.UserID != userID:
+		return nil, apperrors.NotFound("AI output")
+	}
+
+	feedback := &models.AIFeedback{
+		ID:       uuid.New().String(),
+		OutputID: outputID,
+		UserID:   userID,
+		Rating:   rating,
+		Comment:  comment,
+	}
+	if err := s.auditRepo.UpsertFeedback(ctx, feedback); err != nil {
+		return nil, apperrors.DatabaseError("saving ai feedback", err)
+	}
+
+	if rating == models.AIFeedbackDown && output.Kind == models.AIOutputExplanation && output.ExpenseID != nil {
+		if err := s.expenseRepo.ClearExplanation(ctx, *output.ExpenseID); err != nil {
+			return nil, apperrors.DatabaseError("clearing cached explanation", err)
+		}
+		zap.L().Info("Cleared cached explanation after negative feedback",
+			zap.String("expense_id", *output.ExpenseID),
+			zap.String("output_id", outputID))
+	}
+
+	return feedback, nil
+}
+
+func (s *aiAuditService) GetStats(ctx context.Context) ([]models.AIOutputStats, error) {
+	stats, err := s.auditRepo.GetStats(ctx)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting ai output stats", err)
+	}
+	for i := range stats {
+		if rated := stats[i].ThumbsUp + stats[i].ThumbsDown; rated > 0 {
+			accuracy := float64(stats[i].ThumbsUp) / float64(rated)
+			stats[i].Accuracy = &accuracy
+		}
+	}
+	return stats, nil
+}
+
+func redactAIText(text string, names []string) string {
+	text = redactEmailPattern.ReplaceAllString(text, "[email]")
+	text = redactNumberPattern.ReplaceAllString(text, "[number]")
+
+	sorted := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	replacements := make([]string, 0, len(sorted)*2)
+	for i, name := range sorted {
+		replacements = append(replacements, name, fmt.Sprintf("[member %d]", i+1))
+	}
+	if len(replacements) > 0 {
+		text = strings.NewReplacer(replacements...).Replace(text)
+	}
+	return text
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+func TestRedactAIText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		names    []string
+		expected string
+	}{
+		{name: "Email", text: "Send it to asha.k+trip@example.co.in now", expected: "Send it to [email] now"},
+		{name: "Phone Number", text: "Call +91 98765 43210 later", expected: "Call +[number] later"},
+		{name: "Short Numbers Kept", text: "Dinner for 4 cost 1200", expected: "Dinner for 4 cost 1200"},
+		{name: "Longer Names First", text: "Ann paid Anna back", names: []string{"Ann", "Anna"}, expected: "[member 2] paid [member 1] back"},
+		{name: "Blank Names Ignored", text: "Bob paid", names: []string{" ", "Bob "}, expected: "[member 1] paid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactAIText(tt.text, tt.names); got != tt.expected {
+				t.Errorf("redactAIText() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+type feedbackAuditRepo struct {
+	stubAIAuditRepository
+	outputs  map[string]*models.AIOutput
+	feedback []*models.AIFeedback
+}
+
+func (r *feedbackAuditRepo) GetOutputByID(_ context.Context, id string) (*models.AIOutput, error) {
+	if output, ok := r.outputs[id]; ok {
+		return output, nil
+	}
+	return nil, fmt.Errorf("getting ai output: no rows in result set")
+}
+
+func (r *feedbackAuditRepo) UpsertFeedback(_ context.Context, feedback *models.AIFeedback) error {
+	r.feedback = append(r.feedback, feedback)
+	return nil
+}
+
+type clearingExpenseWriter struct {
+	stubExpenseWriter
+	cleared []string
+}
+
+func (w *clearingExpenseWriter) ClearExplanation(_ context.Context, expenseID string) error {
+	w.cleared = append(w.cleared, expenseID)
+	return nil
+}
+
+func TestSubmitFeedback(t *testing.T) {
+	alice, groupID, expenseID := "alice", "g1", "e1"
+	outputs := map[string]*models.AIOutput{
+		"explanation": {ID: "explanation", Kind: models.AIOutputExplanation, GroupID: &groupID, ExpenseID: &expenseID},
+		"scan":        {ID: "scan", Kind: models.AIOutputReceiptScan, UserID: &alice},
+	}
+	members := &countingMemberRepo{members: map[string]bool{"g1/alice": true}}
+	comment := "   "
+
+	tests := []struct {
+		name         string
+		outputID     string
+		userID       string
+		rating       models.AIFeedbackRating
+		expectedCode apperrors.ErrorCode
+		cleared      int
+	}{
+		{name: "Thumbs Down Clears Explanation", outputID: "explanation", userID: "alice", rating: models.AIFeedbackDown, cleared: 1},
+		{name: "Thumbs Up Keeps Explanation", outputID: "explanation", userID: "alice", rating: models.AIFeedbackUp},
+		{name: "Own Receipt Scan", outputID: "scan", userID: "alice", rating: models.AIFeedbackDown},
+		{name: "Someone Else's Receipt Scan", outputID: "scan", userID: "bob", rating: models.AIFeedbackUp, expectedCode: apperrors.NotFound("AI output").Code},
+		{name: "Explanation Outside Group", outputID: "explanation", userID: "bob", rating: models.AIFeedbackUp, expectedCode: apperrors.CodeNotGroupMember},
+		{name: "Invalid Rating", outputID: "explanation", userID: "alice", rating: "MEH", expectedCode: apperrors.CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &feedbackAuditRepo{outputs: outputs}
+			writer := &clearingExpenseWriter{}
+			s := NewAIAuditService(repo, writer, members)
+
+			feedback, err := s.SubmitFeedback(context.Background(), tt.outputID, tt.userID, tt.rating, &comment)
+			if tt.expectedCode != "" {
+				if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+					t.Errorf("SubmitFeedback() error = %v, expected %s", err, tt.expectedCode)
+				}
+				if len(repo.feedback) != 0 {
+					t.Error("SubmitFeedback() saved feedback for a rejected request")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SubmitFeedback() error = %v", err)
+			}
+			if feedback.Comment != nil {
+				t.Errorf("SubmitFeedback() comment = %q, expected a blank comment to be dropped", *feedback.Comment)
+			}
+			if len(writer.cleared) != tt.cleared {
+				t.Errorf("SubmitFeedback() cleared %d explanations, expected %d", len(writer.cleared), tt.cleared)
+			}
+		})
+	}
+}
+
+type fixedStatsAuditRepo struct {
+	stubAIAuditRepository
+	stats []models.AIOutputStats
+}
+
+func (r *fixedStatsAuditRepo) GetStats(_ context.Context) ([]models.AIOutputStats, error) {
+	return r.stats, nil
+}
+
+func TestGetStatsAccuracy(t *testing.T) {
+	repo := &fixedStatsAuditRepo{stats: []models.AIOutputStats{
+		{Kind: models.AIOutputExplanation, Outputs: 10, Rated: 4, ThumbsUp: 3, ThumbsDown: 1},
+		{Kind: models.AIOutputReceiptScan, Outputs: 5},
+	}}
+	s := NewAIAuditService(repo, &clearingExpenseWriter{}, &mockGroupRepo{})
+
+	stats, err := s.GetStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats[0].Accuracy == nil || *stats[0].Accuracy != 0.75 {
+		t.Errorf("GetStats() accuracy = %v, expected 0.75", stats[0].Accuracy)
+	}
+	if stats[1].Accuracy != nil {
+		t.Errorf("GetStats() accuracy = %v, expected nil with no ratings", *stats[1].Accuracy)
+	}
+}
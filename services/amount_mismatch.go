@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+// rowTolerance is how far a row may be from its expected amount before it is
+// flagged: a cent for the remainder of an uneven split, plus float noise.
+const rowTolerance = BalanceThreshold + AmountTolerance
+
+// splitMismatch describes each split of an expense whose splits do not add up
+// to expectedTotal. Equal and percentage splits fix what every row should be,
+// so those rows get an expected amount and the wrong ones are flagged; exact
+// amounts are only listed.
+func splitMismatch(expense *models.Expense, splits []models.ExpenseSplit, total, expectedTotal float64) *apperrors.AmountMismatchData {
+	data := newAmountMismatchData("split", total, expectedTotal)
+
+	percentageTotal := 0.0
+	withPercentage := 0
+	for i, split := range splits {
+		entry := apperrors.AmountEntry{
+			Index:      i,
+			UserID:     split.UserID,
+			UserName:   split.UserName,
+			Amount:     split.Amount,
+			Percentage: split.Percentage,
+		}
+		switch {
+		case split.Percentage != nil:
+			percentage := *split.Percentage
+			percentageTotal += percentage
+			withPercentage++
+			setExpectedAmount(&entry, expectedTotal*percentage/100)
+		case expense.Type == models.ExpenseTypeEqual:
+			setExpectedAmount(&entry, expectedTotal/float64(len(splits)))
+		}
+		data.Entries = append(data.Entries, entry)
+	}
+
+	if withPercentage > 0 {
+		rounded := math.Round(percentageTotal*RoundingFactor) / RoundingFactor
+		data.PercentageTotal = &rounded
+	}
+	return data
+}
+
+// payerMismatch describes the payers of an expense whose payments do not add
+// up to expectedTotal. A sole payer should have paid the whole amount.
+func payerMismatch(payers []models.ExpensePayer, total, expectedTotal float64) *apperrors.AmountMismatchData {
+	data := newAmountMismatchData("payer", total, expectedTotal)
+	for i, payer := range payers {
+		entry := apperrors.AmountEntry{
+			Index:  i,
+			UserID: payer.UserID,
+			Amount: payer.AmountPaid,
+		}
+		if len(payers) == 1 {
+			setExpectedAmount(&entry, expectedTotal)
+		}
+		data.Entries = append(data.Entries, entry)
+	}
+	return data
+}
+
+func newAmountMismatchData(kind string, total, expectedTotal float64) *apperrors.AmountMismatchData {
+	return &apperrors.AmountMismatchData{
+		Kind:          kind,
+		Total:         total,
+		ExpectedTotal: expectedTotal,
+		Difference:    math.Round((total-expectedTotal)*RoundingFactor) / RoundingFactor,
+		Entries:       []apperrors.AmountEntry{},
+	}
+}
+
+func setExpectedAmount(entry *apperrors.AmountEntry, expected float64) {
+	expected = math.Round(expected*RoundingFactor) / RoundingFactor
+	delta := math.Round((entry.Amount-expected)*RoundingFactor) / RoundingFactor
+	entry.Expected = &expected
+	entry.Delta = &delta
+	entry.Mismatched = math.Abs(entry.Amount-expected) > rowTolerance
+}
+
+func (s *expenseService) checkExpenseAmounts(ctx context.Context, groupID string, expense *models.Expense, splits []models.ExpenseSplit) error {
+	return checkExpenseAmountsWith(ctx, s.groupRepo, groupID, expense, splits)
+}
+
+// checkExpenseAmountsWith validates an expense's amounts and, when they do not
+// add up, names the members in the error's rows.
+func checkExpenseAmountsWith(ctx context.Context, groupRepo repository.GroupRepository, groupID string, expense *models.Expense, splits []models.ExpenseSplit) error {
+	err := validateExpenseTotals(expense, splits)
+	if err == nil {
+		return nil
+	}
+	appErr, ok := apperrors.AsAppError(err)
+	if !ok {
+		return err
+	}
+	data, ok := appErr.Data.(*apperrors.AmountMismatchData)
+	if !ok {
+		return err
+	}
+
+	group, groupErr := groupRepo.GetByID(ctx, groupID)
+	if groupErr != nil {
+		zap.L().Warn("Failed to get group members for amount mismatch", zap.String("group_id", groupID), zap.Error(groupErr))
+		return err
+	}
+	names := make(map[string]string, len(group.Members))
+	for _, m := range group.Members {
+		names[m.ID] = m.Name
+	}
+	for i := range data.Entries {
+		if name, ok := names[data.Entries[i].UserID]; ok {
+			data.Entries[i].UserName = name
+		}
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+func TestAmountMismatchEntries(t *testing.T) {
+	pct := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name            string
+		expense         *models.Expense
+		splits          []models.ExpenseSplit
+		kind            string
+		difference      float64
+		mismatched      []int
+		withExpected    bool
+		percentageTotal *float64
+	}{
+		{
+			name: "Equal split with one wrong row",
+			expense: &models.Expense{TotalAmount: 100, Type: models.ExpenseTypeEqual,
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 100}}},
+			splits:       []models.ExpenseSplit{{UserID: "A", Amount: 33.34}, {UserID: "B", Amount: 43.33}, {UserID: "C", Amount: 33.33}},
+			kind:         "split",
+			difference:   10,
+			mismatched:   []int{1},
+			withExpected: true,
+		},
+		{
+			name: "Percentages that do not reach 100",
+			expense: &models.Expense{TotalAmount: 200, Type: models.ExpenseTypePercentage,
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 200}}},
+			splits: []models.ExpenseSplit{
+				{UserID: "A", Amount: 100, Percentage: pct(50)},
+				{UserID: "B", Amount: 80, Percentage: pct(40)},
+			},
+			kind:            "split",
+			difference:      -20,
+			withExpected:    true,
+			percentageTotal: pct(90),
+		},
+		{
+			name: "Percentage row with the wrong amount",
+			expense: &models.Expense{TotalAmount: 200, Type: models.ExpenseTypePercentage,
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 200}}},
+			splits: []models.ExpenseSplit{
+				{UserID: "A", Amount: 150, Percentage: pct(50)},
+				{UserID: "B", Amount: 100, Percentage: pct(50)},
+			},
+			kind:            "split",
+			difference:      50,
+			mismatched:      []int{0},
+			withExpected:    true,
+			percentageTotal: pct(100),
+		},
+		{
+			name: "Exact amounts are listed without expectations",
+			expense: &models.Expense{TotalAmount: 50, Type: models.ExpenseTypeExactAmount,
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 50}}},
+			splits:     []models.ExpenseSplit{{UserID: "A", Amount: 20}, {UserID: "B", Amount: 20}},
+			kind:       "split",
+			difference: -10,
+		},
+		{
+			name: "Sole payer paid too little",
+			expense: &models.Expense{TotalAmount: 50, Type: models.ExpenseTypeExactAmount,
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 45}}},
+			splits:       []models.ExpenseSplit{{UserID: "A", Amount: 25}, {UserID: "B", Amount: 25}},
+			kind:         "payer",
+			difference:   -5,
+			mismatched:   []int{0},
+			withExpected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &expenseService{}
+			err := s.validateExpenseAmounts(tt.expense, tt.splits)
+			appErr, ok := apperrors.AsAppError(err)
+			if !ok || appErr.Code != apperrors.CodeAmountMismatch {
+				t.Fatalf("expected an amount mismatch, got %v", err)
+			}
+			data, ok := appErr.Data.(*apperrors.AmountMismatchData)
+			if !ok {
+				t.Fatalf("error data = %T, expected *AmountMismatchData", appErr.Data)
+			}
+			if data.Kind != tt.kind || data.Difference != tt.difference {
+				t.Errorf("kind/difference = %s/%.2f, expected %s/%.2f", data.Kind, data.Difference, tt.kind, tt.difference)
+			}
+			if (data.PercentageTotal == nil) != (tt.percentageTotal == nil) ||
+				(data.PercentageTotal != nil && *data.PercentageTotal != *tt.percentageTotal) {
+				t.Errorf("percentage_total = %v, expected %v", data.PercentageTotal, tt.percentageTotal)
+			}
+
+			var mismatched []int
+			for i, entry := range data.Entries {
+				if entry.Index != i {
+					t.Errorf("entry %d has index %d", i, entry.Index)
+				}
+				if (entry.Expected != nil) != tt.withExpected {
+					t.Errorf("entry %d expected = %v", i, entry.Expected)
+				}
+				if entry.Mismatched {
+					mismatched = append(mismatched, i)
+				}
+			}
+			if len(mismatched) != len(tt.mismatched) {
+				t.Fatalf("mismatched rows = %v, expected %v", mismatched, tt.mismatched)
+			}
+			for i := range mismatched {
+				if mismatched[i] != tt.mismatched[i] {
+					t.Errorf("mismatched rows = %v, expected %v", mismatched, tt.mismatched)
+				}
+			}
+		})
+	}
+}
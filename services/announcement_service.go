@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AnnouncementService delivers admin-written announcements (new features,
+// maintenance windows) to clients without an app release.
+type AnnouncementService interface {
+	Create(ctx context.Context, adminID string, announcement *models.Announcement) (*models.Announcement, error)
+	GetAll(ctx context.Context) ([]models.Announcement, error)
+	Delete(ctx context.Context, announcementID string) error
+	GetFeed(ctx context.Context, userID string, unreadOnly bool) (*models.AnnouncementFeed, error)
+	MarkRead(ctx context.Context, userID, announcementID string) error
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type announcementService struct {
+	announcementRepo repository.AnnouncementRepository
+}
+
+func NewAnnouncementService(announcementRepo repository.AnnouncementRepository) AnnouncementService {
+	return &announcementService{announcementRepo: announcementRepo}
+}
+
+// Create publishes an announcement. StartsAt defaults to now, so it shows at
+// once; a later StartsAt schedules it, e.g. a maintenance notice a day ahead.
+func (s *announcementService) Create(ctx context.Context, adminID string, announcement *models.Announcement) (*models.Announcement, error) {
+	if err := validateAnnouncement(announcement); err != nil {
+		return nil, err
+	}
+
+	announcement.ID = uuid.New().String()
+	announcement.CreatedBy = &adminID
+	if announcement.StartsAt.IsZero() {
+		announcement.StartsAt = time.Now()
+	}
+	if announcement.EndsAt != nil && !announcement.EndsAt.After(announcement.StartsAt) {
+		return nil, apperrors.InvalidRequest("ends_at must be after starts_at.")
+	}
+
+	if err := s.announcementRepo.Create(ctx, announcement); err != nil {
+		return nil, apperrors.DatabaseError("creating announcement", err)
+	}
+
+	zap.L().Info("Announcement created",
+		zap.String("announcement_id", announcement.ID),
+		zap.String("admin_id", adminID),
+		zap.String("kind", string(announcement.Kind)))
+	return announcement, nil
+}
+
+func validateAnnouncement(announcement *models.Announcement) error {
+	announcement.Kind = models.AnnouncementKind(strings.ToUpper(strings.TrimSpace(string(announcement.Kind))))
+	if announcement.Kind == "" {
+		announcement.Kind = models.AnnouncementKindInfo
+	}
+	if !announcement.Kind.IsValid() {
+		return apperrors.InvalidRequest("kind must be FEATURE, MAINTENANCE or INFO.")
+	}
+
+	announcement.Title = strings.TrimSpace(announcement.Title)
+	announcement.Body = strings.TrimSpace(announcement.Body)
+	if announcement.Title == "" {
+		return apperrors.MissingRequiredField("title")
+	}
+	if announcement.Body == "" {
+		return apperrors.MissingRequiredField("body")
+	}
+	if utf8.RuneCountInString(announcement.Title) > MaxAnnouncementTitleLength {
+		return apperrors.InvalidRequest(fmt.Sprintf("title can be at most %d characters.", MaxAnnouncementTitleLength))
+	}
+	if utf8.RuneCountInString(announcement.Body) > MaxAnnouncementBodyLength {
+		return apperrors.InvalidRequest(fmt.Sprintf("body can be at most %d characters.", MaxAnnouncementBodyLength))
+	}
+
+	if announcement.LinkURL != nil {
+		link := strings.TrimSpace(*announcement.LinkURL)
+		if link == "" {
+			announcement.LinkURL = nil
+			return nil
+		}
+		u, err := url.Parse(link)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return apperrors.InvalidRequest("link_url must be an https URL.")
+		}
+		announcement.LinkURL = &link
+	}
+	return nil
+}
+
+func (s *announcementService) GetAll(ctx context.Context) ([]models.Announcement, error) {
+	announcements, err := s.announcementRepo.GetAll(ctx)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting announcements", err)
+	}
+	return announcements, nil
+}
+
+func (s *announcementService) Delete(ctx context.Context, announcementID string) error {
+	deleted, err := s.announcementRepo.Delete(ctx, announcementID)
+	if err != nil {
+		return apperrors.DatabaseError("deleting announcement", err)
+	}
+	if !deleted {
+		return apperrors.NotFound("Announcement")
+	}
+	zap.L().Info("Announcement deleted", zap.String("announcement_id", announcementID))
+	return nil
+}
+
+// GetFeed returns the announcements showing now, newest first, with the
+// user's read times. UnreadCount always counts every unread one, so a client
+// can badge it even when fetching only the unread list.
+func (s *announcementService) GetFeed(ctx context.Context, userID string, unreadOnly bool) (*models.AnnouncementFeed, error) {
+	announcements, err := s.announcementRepo.GetActiveForUser(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting announcements", err)
+	}
+
+	feed := &models.AnnouncementFeed{Announcements: make([]models.Announcement, 0, len(announcements))}
+	for _, a := range announcements {
+		if a.ReadAt == nil {
+			feed.UnreadCount++
+		} else if unreadOnly {
+			continue
+		}
+		feed.Announcements = append(feed.Announcements, a)
+	}
+	return feed, nil
+}
+
+func (s *announcementService) MarkRead(ctx context.Context, userID, announcementID string) error {
+	found, err := s.announcementRepo.MarkRead(ctx, userID, announcementID)
+	if err != nil {
+		return apperrors.DatabaseError("marking announcement read", err)
+	}
+	if !found {
+		return apperrors.NotFound("Announcement")
+	}
+	return nil
+}
+
+func (s *announcementService) MarkAllRead(ctx context.Context, userID string) error {
+	if err := s.announcementRepo.MarkAllRead(ctx, userID); err != nil {
+		return apperrors.DatabaseError("marking announcements read", err)
+	}
+	return nil
+}
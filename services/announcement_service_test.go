@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestValidateAnnouncement(t *testing.T) {
+	link := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		input    models.Announcement
+		wantErr  bool
+		wantKind models.AnnouncementKind
+	}{
+		{"defaults to info", models.Announcement{Title: "Hi", Body: "Hello"}, false, models.AnnouncementKindInfo},
+		{"kind is case-insensitive", models.Announcement{Kind: "maintenance", Title: "Down", Body: "Sunday 2am"}, false, models.AnnouncementKindMaintenance},
+		{"unknown kind", models.Announcement{Kind: "PROMO", Title: "Hi", Body: "Hello"}, true, ""},
+		{"missing title", models.Announcement{Title: "  ", Body: "Hello"}, true, ""},
+		{"missing body", models.Announcement{Title: "Hi"}, true, ""},
+		{"https link", models.Announcement{Title: "Hi", Body: "Hello", LinkURL: link("https://example.com/changelog")}, false, models.AnnouncementKindInfo},
+		{"http link", models.Announcement{Title: "Hi", Body: "Hello", LinkURL: link("http://example.com")}, true, ""},
+		{"script link", models.Announcement{Title: "Hi", Body: "Hello", LinkURL: link("javascript:alert(1)")}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.input
+			err := validateAnnouncement(&a)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateAnnouncement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && a.Kind != tt.wantKind {
+				t.Errorf("kind = %q, want %q", a.Kind, tt.wantKind)
+			}
+		})
+	}
+}
+
+type fakeAnnouncementRepo struct {
+	stubAnnouncementRepository
+	active []models.Announcement
+}
+
+func (r *fakeAnnouncementRepo) GetActiveForUser(ctx context.Context, userID string) ([]models.Announcement, error) {
+	return r.active, nil
+}
+
+func TestGetFeedCountsUnread(t *testing.T) {
+	readAt := time.Now()
+	repo := &fakeAnnouncementRepo{active: []models.Announcement{
+		{ID: "new"},
+		{ID: "read", ReadAt: &readAt},
+		{ID: "older"},
+	}}
+	s := NewAnnouncementService(repo)
+
+	feed, err := s.GetFeed(context.Background(), "user-1", false)
+	if err != nil {
+		t.Fatalf("GetFeed() error = %v", err)
+	}
+	if len(feed.Announcements) != 3 || feed.UnreadCount != 2 {
+		t.Errorf("feed has %d announcements, %d unread; want 3, 2", len(feed.Announcements), feed.UnreadCount)
+	}
+
+	feed, err = s.GetFeed(context.Background(), "user-1", true)
+	if err != nil {
+		t.Fatalf("GetFeed(unread) error = %v", err)
+	}
+	if len(feed.Announcements) != 2 || feed.UnreadCount != 2 {
+		t.Errorf("unread feed has %d announcements, %d unread; want 2, 2", len(feed.Announcements), feed.UnreadCount)
+	}
+}
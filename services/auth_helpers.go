@@ -8,7 +8,7 @@ import (
 )
 
 func RequireGroupMembership(ctx context.Context, groupRepo repository.GroupRepository, groupID, userID string) error {
-	isMember, err := groupRepo.IsMember(ctx, groupID, userID)
+	isMember, err := isGroupMember(ctx, groupRepo, groupID, userID)
 	if err != nil {
 		return apperrors.DatabaseError("checking membership", err)
 	}
@@ -16,4 +16,24 @@ func RequireGroupMembership(ctx context.Context, groupRepo repository.GroupRepos
 		return apperrors.NotGroupMember()
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Checks inside a transaction that has locked the group must not use this, as
+// the memo may predate the lock.
+func isGroupMember(ctx context.Context, groupRepo repository.GroupRepository, groupID, userID string) (bool, error) {
+	memo := membershipMemoFrom(ctx)
+	if memo != nil {
+		if isMember, ok := memo.get(groupID, userID); ok {
+			return isMember, nil
+		}
+	}
+
+	isMember, err := groupRepo.IsMember(ctx, groupID, userID)
+	if err != nil {
+		return false, err
+	}
+	if memo != nil {
+		memo.set(groupID, userID, isMember)
+	}
+	return isMember, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthService interface {
+	Register(ctx context.Context, email, password, name string) (*models.AuthTokens, error)
+	Login(ctx context.Context, email, password string) (*models.AuthTokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.AuthTokens, error)
+}
+
+type localAuthService struct {
+	userRepo       repository.UserRepository
+	credentialRepo repository.CredentialRepository
+	db             *database.DB
+	jwtSecret      []byte
+}
+
+func NewLocalAuthService(userRepo repository.UserRepository, credentialRepo repository.CredentialRepository, db *database.DB, jwtSecret string) AuthService {
+	return &localAuthService{
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		db:             db,
+		jwtSecret:      []byte(jwtSecret),
+	}
+}
+
+func (s *localAuthService) Register(ctx context.Context, email, password, name string) (*models.AuthTokens, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	name = strings.TrimSpace(name)
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, apperrors.InvalidEmail(email)
+	}
+	if len(password) < MinPasswordLength {
+		return nil, apperrors.InvalidRequestWithDetails("Password is too short.", fmt.Sprintf("Passwords must be at least %d characters long.", MinPasswordLength))
+	}
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	if _, err := s.userRepo.GetByEmail(ctx, email); err == nil {
+		return nil, apperrors.DuplicateEntry("An account with this email")
+	} else if !apperrors.IsNotFoundError(err) {
+		return nil, apperrors.DatabaseError("checking existing account", err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.InternalError(err)
+	}
+
+	user := &models.User{
+		ID:    uuid.New().String(),
+		Email: email,
+		Name:  name,
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.userRepo.WithTx(q).Create(ctx, user); err != nil {
+			return apperrors.DatabaseError("creating user", err)
+		}
+		if err := s.credentialRepo.WithTx(q).SetPasswordHash(ctx, user.ID, string(hash)); err != nil {
+			return apperrors.DatabaseError("storing credentials", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if apperrors.IsDuplicateError(err) {
+			return nil, apperrors.DuplicateEntry("An account with this email")
+		}
+		return nil, err
+	}
+
+	zap.L().Info("Registered local user", zap.String("user_id", user.ID))
+	return s.issueTokens(user)
+}
+
+func (s *localAuthService) Login(ctx context.Context, email, password string) (*models.AuthTokens, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.InvalidCredentials()
+		}
+		return nil, apperrors.DatabaseError("getting user", err)
+	}
+
+	hash, err := s.credentialRepo.GetPasswordHash(ctx, user.ID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.InvalidCredentials()
+		}
+		return nil, apperrors.DatabaseError("getting credentials", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return nil, apperrors.InvalidCredentials()
+	}
+
+	return s.issueTokens(user)
+}
+
+func (s *localAuthService) Refresh(ctx context.Context, refreshToken string) (*models.AuthTokens, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithIssuer(LocalTokenIssuer))
+	if err != nil {
+		if strings.Contains(err.Error(), "expired") {
+			return nil, apperrors.TokenExpired()
+		}
+		return nil, apperrors.TokenInvalid()
+	}
+	if typ, _ := claims["typ"].(string); typ != "refresh" {
+		return nil, apperrors.TokenInvalid()
+	}
+
+	userID, _ := claims["sub"].(string)
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.TokenInvalid()
+		}
+		return nil, apperrors.DatabaseError("getting user", err)
+	}
+	if user.DeletedAt != nil {
+		return nil, apperrors.AccountDeleted()
+	}
+	if user.Email == "" {
+		return nil, apperrors.TokenInvalid()
+	}
+
+	return s.issueTokens(user)
+}
+
+func (s *localAuthService) issueTokens(user *models.User) (*models.AuthTokens, error) {
+	now := time.Now()
+
+	accessToken, err := s.signToken(user, "access", now, now.Add(AccessTokenTTL))
+	if err != nil {
+		return nil, apperrors.InternalError(err)
+	}
+	refreshToken, err := s.signToken(user, "refresh", now, now.Add(RefreshTokenTTL))
+	if err != nil {
+		return nil, apperrors.InternalError(err)
+	}
+
+	return &models.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(AccessTokenTTL.Seconds()),
+		User:         user,
+	}, nil
+}
+
+func (s *localAuthService) signToken(user *models.User, typ string, issuedAt, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":   user.ID,
+		"email": user.Email,
+		"user_metadata": map[string]interface{}{
+			"full_name": user.Name,
+		},
+		"typ": typ,
+		"iss": LocalTokenIssuer,
+		"iat": issuedAt.Unix(),
+		"exp": expiresAt.Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type emailUserRepo struct {
+	fakeUserRepo
+}
+
+func (r *emailUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("getting user by email: no rows in result set")
+}
+
+type fakeCredentialRepo struct {
+	stubCredentialRepository
+	hashes map[string]string
+}
+
+func (r fakeCredentialRepo) GetPasswordHash(ctx context.Context, userID string) (string, error) {
+	if hash, ok := r.hashes[userID]; ok {
+		return hash, nil
+	}
+	return "", fmt.Errorf("getting password hash: no rows in result set")
+}
+
+func TestLocalAuthLoginAndRefresh(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &emailUserRepo{fakeUserRepo{users: map[string]*models.User{
+		"u1": {ID: "u1", Email: "asha@example.com", Name: "Asha"},
+		"u2": {ID: "u2", Email: "nopassword@example.com"},
+	}}}
+	s := NewLocalAuthService(users, fakeCredentialRepo{hashes: map[string]string{"u1": string(hash)}}, nil, "test-secret")
+	ctx := context.Background()
+
+	failures := []struct {
+		name     string
+		email    string
+		password string
+	}{
+		{name: "Wrong Password", email: "asha@example.com", password: "battery staple"},
+		{name: "Unknown Email", email: "nobody@example.com", password: "correct horse"},
+		{name: "No Password Set", email: "nopassword@example.com", password: "correct horse"},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Login(ctx, tt.email, tt.password)
+			if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.InvalidCredentials().Code {
+				t.Errorf("Login() error = %v, expected invalid credentials", err)
+			}
+		})
+	}
+
+	tokens, err := s.Login(ctx, "  Asha@Example.com ", "correct horse")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if tokens.User.ID != "u1" || tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("Login() = %+v, expected tokens for u1", tokens)
+	}
+
+	if _, err := s.Refresh(ctx, tokens.AccessToken); err == nil {
+		t.Error("Refresh() accepted an access token")
+	}
+	if _, err := s.Refresh(ctx, tokens.RefreshToken+"x"); err == nil {
+		t.Error("Refresh() accepted a tampered token")
+	}
+	other := NewLocalAuthService(users, fakeCredentialRepo{}, nil, "other-secret")
+	if _, err := other.Refresh(ctx, tokens.RefreshToken); err == nil {
+		t.Error("Refresh() accepted a token signed with another secret")
+	}
+
+	refreshed, err := s.Refresh(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if refreshed.User.ID != "u1" {
+		t.Errorf("Refresh() user = %s, expected u1", refreshed.User.ID)
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+// BalanceAlertService notifies members who set a balance alert when a write
+// leaves them owing more than their threshold in a group.
+type BalanceAlertService interface {
+	CheckGroup(ctx context.Context, groupID string) error
+}
+
+type balanceAlertService struct {
+	alertRepo           repository.BalanceAlertRepository
+	expenseRepo         repository.ExpenseRepository
+	notificationService NotificationService
+}
+
+func NewBalanceAlertService(alertRepo repository.BalanceAlertRepository, expenseRepo repository.ExpenseRepository, notificationService NotificationService) BalanceAlertService {
+	return &balanceAlertService{
+		alertRepo:           alertRepo,
+		expenseRepo:         expenseRepo,
+		notificationService: notificationService,
+	}
+}
+
+// CheckGroup evaluates every subscribed member of the group against their
+// threshold. A member is alerted once when they cross it and not again until
+// they owe less than BalanceAlertRearmRatio of it.
+func (s *balanceAlertService) CheckGroup(ctx context.Context, groupID string) error {
+	subscribers, err := s.alertRepo.GetSubscribers(ctx, groupID)
+	if err != nil {
+		return apperrors.DatabaseError("getting balance alert subscribers", err)
+	}
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	balances, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, nil)
+	if err != nil {
+		return apperrors.DatabaseError("getting group member balances", err)
+	}
+
+	for _, sub := range subscribers {
+		owed := math.Round(-balances[sub.UserID][sub.Currency]*RoundingFactor) / RoundingFactor
+		alerted := balanceAlertActive(owed, sub.Threshold, sub.Alerted)
+		if alerted == sub.Alerted {
+			continue
+		}
+
+		if !alerted {
+			if err := s.alertRepo.Clear(ctx, groupID, sub.UserID); err != nil {
+				return apperrors.DatabaseError("clearing balance alert", err)
+			}
+			continue
+		}
+
+		inserted, err := s.alertRepo.MarkAlerted(ctx, groupID, sub.UserID, owed)
+		if err != nil {
+			return apperrors.DatabaseError("marking balance alert", err)
+		}
+		if !inserted {
+			continue
+		}
+		zap.L().Info("Balance alert threshold crossed",
+			zap.String("group_id", groupID),
+			zap.String("user_id", sub.UserID),
+			zap.Float64("owed", owed),
+			zap.Float64("threshold", sub.Threshold))
+		if s.notificationService == nil {
+			continue
+		}
+		err = s.notificationService.Dispatch(ctx, NotificationPayload{
+			Event:      models.NotificationEventBalance,
+			GroupID:    groupID,
+			Template:   notifyBalanceAlert,
+			Args:       []interface{}{owed, sub.Currency, sub.Threshold},
+			Recipients: []string{sub.UserID},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// balanceAlertActive reports whether a member owing owed should be in the
+// alerted state. An alert starts above the threshold and only ends once the
+// debt drops below BalanceAlertRearmRatio of it, so a balance hovering around
+// the threshold does not alert on every expense.
+func balanceAlertActive(owed, threshold float64, alerted bool) bool {
+	if alerted {
+		return owed >= threshold*BalanceAlertRearmRatio
+	}
+	return owed > threshold
+}
+
+// checkBalanceAlertsAsync runs the balance alert check for a group after a
+// write has committed, without holding up the response.
+func checkBalanceAlertsAsync(balanceAlertService BalanceAlertService, groupID string) {
+	if balanceAlertService == nil {
+		return
+	}
+	go func() {
+		if err := balanceAlertService.CheckGroup(context.Background(), groupID); err != nil {
+			zap.L().Error("Failed to check balance alerts",
+				zap.String("group_id", groupID),
+				zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestBalanceAlertActive(t *testing.T) {
+	tests := []struct {
+		name    string
+		owed    float64
+		alerted bool
+		want    bool
+	}{
+		{"below threshold", 1500, false, false},
+		{"at threshold", 2000, false, false},
+		{"crosses threshold", 2000.01, false, true},
+		{"stays above", 2500, true, true},
+		{"dips just below threshold", 1900, true, true},
+		{"at rearm point", 1600, true, true},
+		{"drops below rearm point", 1599.99, true, false},
+		{"owed money instead", -300, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := balanceAlertActive(tt.owed, 2000, tt.alerted); got != tt.want {
+				t.Errorf("balanceAlertActive(%v, 2000, %v) = %v, want %v", tt.owed, tt.alerted, got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeBalanceAlertRepo struct {
+	stubBalanceAlertRepository
+	thresholds map[string]float64
+	alerted    map[string]bool
+}
+
+func (r *fakeBalanceAlertRepo) GetSubscribers(ctx context.Context, groupID string) ([]models.BalanceAlertSubscriber, error) {
+	var subscribers []models.BalanceAlertSubscriber
+	for userID, threshold := range r.thresholds {
+		subscribers = append(subscribers, models.BalanceAlertSubscriber{
+			UserID: userID, Threshold: threshold, Currency: "INR", Alerted: r.alerted[userID],
+		})
+	}
+	return subscribers, nil
+}
+
+func (r *fakeBalanceAlertRepo) MarkAlerted(ctx context.Context, groupID, userID string, amountOwed float64) (bool, error) {
+	if r.alerted[userID] {
+		return false, nil
+	}
+	r.alerted[userID] = true
+	return true, nil
+}
+
+func (r *fakeBalanceAlertRepo) Clear(ctx context.Context, groupID, userID string) error {
+	delete(r.alerted, userID)
+	return nil
+}
+
+type recordingNotificationService struct {
+	stubNotificationService
+	sent []NotificationPayload
+}
+
+func (s *recordingNotificationService) Dispatch(ctx context.Context, payload NotificationPayload) error {
+	s.sent = append(s.sent, payload)
+	return nil
+}
+
+func TestCheckGroupAlertsOncePerCrossing(t *testing.T) {
+	alerts := &fakeBalanceAlertRepo{thresholds: map[string]float64{"A": 2000}, alerted: map[string]bool{}}
+	expenses := &mockExpenseRepo{}
+	notifications := &recordingNotificationService{}
+	s := NewBalanceAlertService(alerts, expenses, notifications)
+
+	// A's debt after each write; only the first crossing and the one after
+	// paying down below the rearm point alert.
+	for _, owed := range []float64{1800, 2100, 2400, 1900, 2100, 1000, 2200} {
+		expenses.balances = map[string]map[string]float64{"A": {"INR": -owed, "USD": -5000}}
+		if err := s.CheckGroup(context.Background(), "group-1"); err != nil {
+			t.Fatalf("CheckGroup() error = %v", err)
+		}
+	}
+
+	if len(notifications.sent) != 2 {
+		t.Fatalf("sent %d alerts, want 2", len(notifications.sent))
+	}
+	for _, payload := range notifications.sent {
+		if payload.Template != notifyBalanceAlert || len(payload.Recipients) != 1 || payload.Recipients[0] != "A" {
+			t.Errorf("unexpected alert %+v", payload)
+		}
+	}
+	if owed := notifications.sent[1].Args[0]; owed != 2200.0 {
+		t.Errorf("second alert reports %v owed, want 2200", owed)
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+type BalanceEventService interface {
+	GetMemberEvents(ctx context.Context, groupID, requesterID, userID string) (*models.BalanceAudit, error)
+}
+
+type balanceEventService struct {
+	balanceEventRepo repository.BalanceEventRepository
+	groupRepo        repository.GroupRepository
+	expenseRepo      repository.BalanceQueries
+}
+
+func NewBalanceEventService(balanceEventRepo repository.BalanceEventRepository, groupRepo repository.GroupRepository, expenseRepo repository.BalanceQueries) BalanceEventService {
+	return &balanceEventService{
+		balanceEventRepo: balanceEventRepo,
+		groupRepo:        groupRepo,
+		expenseRepo:      expenseRepo,
+	}
+}
+
+func (s *balanceEventService) GetMemberEvents(ctx context.Context, groupID, requesterID, userID string) (*models.BalanceAudit, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, requesterID); err != nil {
+		return nil, err
+	}
+
+	events, err := s.balanceEventRepo.GetByMember(ctx, groupID, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting balance events", err)
+	}
+	if len(events) == 0 {
+		isMember, err := isGroupMember(ctx, s.groupRepo, groupID, userID)
+		if err != nil {
+			return nil, apperrors.DatabaseError("checking membership", err)
+		}
+		if !isMember {
+			return nil, apperrors.UserNotFound()
+		}
+	}
+
+	balances, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, nil)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group member balances", err)
+	}
+
+	audit := buildBalanceAudit(groupID, userID, events, balances[userID])
+	if !audit.Consistent {
+		zap.L().Warn("Balance ledger disagrees with computed balance",
+			zap.String("group_id", groupID),
+			zap.String("user_id", userID),
+			zap.Any("currencies", audit.Currencies))
+	}
+	return audit, nil
+}
+
+// buildBalanceAudit fills in running balances per currency and compares the
+// ledger total against the balance computed from payers and splits.
+func buildBalanceAudit(groupID, userID string, events []models.BalanceEvent, computed map[string]float64) *models.BalanceAudit {
+	audit := &models.BalanceAudit{
+		GroupID:    groupID,
+		UserID:     userID,
+		Events:     events,
+		Currencies: []models.BalanceAuditCurrency{},
+		Consistent: true,
+	}
+
+	ledger := make(map[string]float64)
+	for i := range audit.Events {
+		e := &audit.Events[i]
+		ledger[e.Currency] += e.Delta
+		e.RunningBalance = math.Round(ledger[e.Currency]*RoundingFactor) / RoundingFactor
+	}
+	for currency := range computed {
+		if _, ok := ledger[currency]; !ok {
+			ledger[currency] = 0
+		}
+	}
+
+	for currency, total := range ledger {
+		c := models.BalanceAuditCurrency{
+			Currency:        currency,
+			LedgerBalance:   math.Round(total*RoundingFactor) / RoundingFactor,
+			ComputedBalance: math.Round(computed[currency]*RoundingFactor) / RoundingFactor,
+		}
+		c.Drift = math.Round((c.LedgerBalance-c.ComputedBalance)*RoundingFactor) / RoundingFactor
+		if math.Abs(c.Drift) > BalanceThreshold {
+			audit.Consistent = false
+		}
+		audit.Currencies = append(audit.Currencies, c)
+	}
+	sort.Slice(audit.Currencies, func(i, j int) bool { return audit.Currencies[i].Currency < audit.Currencies[j].Currency })
+	return audit
+}
+
+// diffBalanceEvents turns two snapshots of a transaction's contributions into
+// the events needed to move the ledger from before to after.
+func diffBalanceEvents(before, after []models.BalanceEvent, eventType models.BalanceEventType) []models.BalanceEvent {
+	type key struct{ userID, currency string }
+	deltas := make(map[key]*models.BalanceEvent)
+	var order []key
+	add := func(e models.BalanceEvent, sign float64) {
+		k := key{e.UserID, e.Currency}
+		d, ok := deltas[k]
+		if !ok {
+			d = &models.BalanceEvent{GroupID: e.GroupID, UserID: e.UserID, Currency: e.Currency, EventType: eventType}
+			deltas[k] = d
+			order = append(order, k)
+		}
+		d.Delta += sign * e.Delta
+		d.ExpenseID, d.ExpenseCategory, d.Description = e.ExpenseID, e.ExpenseCategory, e.Description
+	}
+	for _, e := range before {
+		add(e, -1)
+	}
+	for _, e := range after {
+		add(e, 1)
+	}
+
+	events := []models.BalanceEvent{}
+	for _, k := range order {
+		d := deltas[k]
+		d.Delta = math.Round(d.Delta*RoundingFactor) / RoundingFactor
+		if math.Abs(d.Delta) < AmountTolerance {
+			continue
+		}
+		events = append(events, *d)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].UserID != events[j].UserID {
+			return events[i].UserID < events[j].UserID
+		}
+		return events[i].Currency < events[j].Currency
+	})
+	return events
+}
+
+func snapshotBalanceContributions(ctx context.Context, repo repository.BalanceEventRepository, q database.Querier, expenseID string) ([]models.BalanceEvent, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	contributions, err := repo.WithTx(q).GetExpenseContributions(ctx, expenseID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting balance contributions", err)
+	}
+	return contributions, nil
+}
+
+// recordBalanceEvents appends the change a write made to a transaction's
+// contributions, given the snapshot taken before the write (nil for inserts).
+func recordBalanceEvents(ctx context.Context, repo repository.BalanceEventRepository, q database.Querier, eventType models.BalanceEventType, expenseID string, before []models.BalanceEvent) error {
+	if repo == nil {
+		return nil
+	}
+	after, err := snapshotBalanceContributions(ctx, repo, q, expenseID)
+	if err != nil {
+		return err
+	}
+	if err := repo.WithTx(q).Append(ctx, diffBalanceEvents(before, after, eventType)); err != nil {
+		return apperrors.DatabaseError("recording balance events", err)
+	}
+	return nil
+}
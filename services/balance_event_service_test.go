@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+	"unwise-backend/models"
+)
+
+func contribution(userID, currency string, delta float64) models.BalanceEvent {
+	expenseID := "e1"
+	return models.BalanceEvent{GroupID: "g1", UserID: userID, Currency: currency, Delta: delta, ExpenseID: &expenseID}
+}
+
+func TestDiffBalanceEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   []models.BalanceEvent
+		after    []models.BalanceEvent
+		expected map[string]float64
+	}{
+		{
+			name:     "Created",
+			after:    []models.BalanceEvent{contribution("A", "INR", 60), contribution("B", "INR", -60)},
+			expected: map[string]float64{"A": 60, "B": -60},
+		},
+		{
+			name:     "Deleted",
+			before:   []models.BalanceEvent{contribution("A", "INR", 60), contribution("B", "INR", -60)},
+			expected: map[string]float64{"A": -60, "B": 60},
+		},
+		{
+			name:     "Updated only emits changed members",
+			before:   []models.BalanceEvent{contribution("A", "INR", 60), contribution("B", "INR", -30), contribution("C", "INR", -30)},
+			after:    []models.BalanceEvent{contribution("A", "INR", 80), contribution("B", "INR", -30), contribution("C", "INR", -50)},
+			expected: map[string]float64{"A": 20, "C": -20},
+		},
+		{
+			name:     "No change",
+			before:   []models.BalanceEvent{contribution("A", "INR", 10)},
+			after:    []models.BalanceEvent{contribution("A", "INR", 10)},
+			expected: map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := diffBalanceEvents(tt.before, tt.after, models.BalanceEventTransactionUpdated)
+			if len(events) != len(tt.expected) {
+				t.Fatalf("expected %d events, got %d: %+v", len(tt.expected), len(events), events)
+			}
+			for _, e := range events {
+				if e.Delta != tt.expected[e.UserID] {
+					t.Errorf("user %s: expected delta %.2f, got %.2f", e.UserID, tt.expected[e.UserID], e.Delta)
+				}
+				if e.EventType != models.BalanceEventTransactionUpdated || e.ExpenseID == nil || *e.ExpenseID != "e1" || e.GroupID != "g1" {
+					t.Errorf("unexpected event metadata: %+v", e)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildBalanceAudit(t *testing.T) {
+	events := []models.BalanceEvent{
+		contribution("A", "INR", 100),
+		contribution("A", "USD", -20),
+		contribution("A", "INR", -40.5),
+	}
+
+	audit := buildBalanceAudit("g1", "A", events, map[string]float64{"INR": 59.5, "USD": -20})
+	if !audit.Consistent {
+		t.Errorf("expected consistent audit, got %+v", audit.Currencies)
+	}
+	if audit.Events[2].RunningBalance != 59.5 || audit.Events[1].RunningBalance != -20 {
+		t.Errorf("unexpected running balances: %+v", audit.Events)
+	}
+
+	audit = buildBalanceAudit("g1", "A", events, map[string]float64{"INR": 59.5, "USD": -20, "EUR": 5})
+	if audit.Consistent {
+		t.Fatal("expected drift for a balance missing from the ledger")
+	}
+	if c := audit.Currencies[0]; c.Currency != "EUR" || c.Drift != -5 {
+		t.Errorf("unexpected EUR drift: %+v", c)
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+type BalanceMetricsService interface {
+	Verify(ctx context.Context) (int, error)
+	RunVerifier(ctx context.Context)
+}
+
+type balanceMetricsService struct {
+	metricsRepo repository.BalanceMetricsRepository
+	db          *database.DB
+}
+
+func NewBalanceMetricsService(metricsRepo repository.BalanceMetricsRepository, db *database.DB) BalanceMetricsService {
+	return &balanceMetricsService{
+		metricsRepo: metricsRepo,
+		db:          db,
+	}
+}
+
+func (s *balanceMetricsService) RunVerifier(ctx context.Context) {
+	zap.L().Info("Balance metrics verifier started")
+	timer := time.NewTimer(time.Until(nextBalanceMetricsVerify(time.Now())))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("Balance metrics verifier stopped")
+			return
+		case <-timer.C:
+			if _, err := s.Verify(ctx); err != nil {
+				zap.L().Error("Balance metrics verification failed", zap.Error(err))
+			}
+			timer.Reset(time.Until(nextBalanceMetricsVerify(time.Now())))
+		}
+	}
+}
+
+// Drift means some write skipped the ledger, so each one is logged as an
+// error. Only one instance verifies at a time; the others return 0.
+func (s *balanceMetricsService) Verify(ctx context.Context) (int, error) {
+	repaired := 0
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		metricsRepo := s.metricsRepo.WithTx(q)
+
+		locked, err := metricsRepo.TryVerifyLock(ctx)
+		if err != nil {
+			return apperrors.DatabaseError("locking balance metrics", err)
+		}
+		if !locked {
+			zap.L().Debug("Balance metrics verification already running elsewhere")
+			return nil
+		}
+
+		drift, err := metricsRepo.FindDrift(ctx)
+		if err != nil {
+			return apperrors.DatabaseError("finding balance metric drift", err)
+		}
+		for _, d := range drift {
+			zap.L().Error("Balance metric drifted from expenses",
+				zap.String("user_id", d.UserID),
+				zap.String("group_id", d.GroupID),
+				zap.String("currency", d.Currency),
+				zap.Float64("stored", d.Stored),
+				zap.Float64("actual", d.Actual))
+			if err := metricsRepo.Adjust(ctx, d.UserID, d.GroupID, d.Currency, d.Actual-d.Stored); err != nil {
+				return apperrors.DatabaseError("repairing balance metric", err)
+			}
+		}
+		repaired = len(drift)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	zap.L().Info("Balance metrics verified", zap.Int("repaired", repaired))
+	return repaired, nil
+}
+
+func nextBalanceMetricsVerify(now time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), BalanceMetricsVerifyHourUTC, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
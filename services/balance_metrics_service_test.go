@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBalanceMetricsVerify(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		{
+			name:     "before the hour runs today",
+			now:      time.Date(2026, 3, 14, 1, 15, 0, 0, time.UTC),
+			expected: time.Date(2026, 3, 14, BalanceMetricsVerifyHourUTC, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "exactly on the hour waits a day",
+			now:      time.Date(2026, 3, 14, BalanceMetricsVerifyHourUTC, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 3, 15, BalanceMetricsVerifyHourUTC, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "local time is converted to UTC first",
+			now:      time.Date(2026, 12, 31, 23, 0, 0, 0, ist),
+			expected: time.Date(2027, 1, 1, BalanceMetricsVerifyHourUTC, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextBalanceMetricsVerify(tt.now); !got.Equal(tt.expected) {
+				t.Errorf("nextBalanceMetricsVerify(%v) = %v, expected %v", tt.now, got, tt.expected)
+			}
+		})
+	}
+}
@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	apperrors "unwise-backend/errors"
 	"unwise-backend/models"
@@ -20,44 +24,47 @@ type CommentService interface {
 }
 
 type commentService struct {
-	commentRepo repository.CommentRepository
-	expenseRepo repository.ExpenseRepository
-	groupRepo   repository.GroupRepository
+	commentRepo         repository.CommentRepository
+	expenseRepo         repository.ExpenseReader
+	groupRepo           repository.GroupRepository
+	notificationRepo    repository.NotificationRepository
+	notificationService NotificationService
 }
 
 func NewCommentService(
 	commentRepo repository.CommentRepository,
-	expenseRepo repository.ExpenseRepository,
+	expenseRepo repository.ExpenseReader,
 	groupRepo repository.GroupRepository,
+	notificationRepo repository.NotificationRepository,
+	notificationService NotificationService,
 ) CommentService {
 	return &commentService{
-		commentRepo: commentRepo,
-		expenseRepo: expenseRepo,
-		groupRepo:   groupRepo,
+		commentRepo:         commentRepo,
+		expenseRepo:         expenseRepo,
+		groupRepo:           groupRepo,
+		notificationRepo:    notificationRepo,
+		notificationService: notificationService,
 	}
 }
 
-func (s *commentService) checkAccess(ctx context.Context, expenseID, userID string) error {
+func (s *commentService) checkAccess(ctx context.Context, expenseID, userID string) (*models.Expense, error) {
 	expense, err := s.expenseRepo.GetByID(ctx, expenseID)
 	if err != nil {
 		if apperrors.IsNotFoundError(err) {
-			return apperrors.ExpenseNotFound()
+			return nil, apperrors.ExpenseNotFound()
 		}
-		return apperrors.DatabaseError("finding expense", err)
+		return nil, apperrors.DatabaseError("finding expense", err)
 	}
 
-	isMember, err := s.groupRepo.IsMember(ctx, expense.GroupID, userID)
-	if err != nil {
-		return apperrors.DatabaseError("checking membership", err)
-	}
-	if !isMember {
-		return apperrors.NotGroupMember()
+	if err := RequireGroupMembership(ctx, s.groupRepo, expense.GroupID, userID); err != nil {
+		return nil, err
 	}
-	return nil
+	return expense, nil
 }
 
 func (s *commentService) AddComment(ctx context.Context, expenseID, userID, text string) (*models.Comment, error) {
-	if err := s.checkAccess(ctx, expenseID, userID); err != nil {
+	expense, err := s.checkAccess(ctx, expenseID, userID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -72,11 +79,97 @@ func (s *commentService) AddComment(ctx context.Context, expenseID, userID, text
 		return nil, apperrors.DatabaseError("creating comment", err)
 	}
 
+	recipients, err := s.commentRecipients(ctx, expense, userID, text)
+	if err != nil {
+		zap.L().Error("Failed to resolve comment recipients",
+			zap.String("expense_id", expenseID),
+			zap.Error(err))
+	} else if len(recipients) > 0 {
+		dispatchNotificationAsync(s.notificationService, NotificationPayload{
+			Event:      models.NotificationEventComment,
+			GroupID:    expense.GroupID,
+			ExpenseID:  expenseID,
+			ActorID:    userID,
+			Template:   notifyComment,
+			Args:       []interface{}{expense.Description},
+			Recipients: recipients,
+		})
+	}
+
 	return comment, nil
 }
 
+func (s *commentService) commentRecipients(ctx context.Context, expense *models.Expense, actorID, text string) ([]string, error) {
+	members, err := s.groupRepo.GetMembers(ctx, expense.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("getting group members: %w", err)
+	}
+	settings, err := s.notificationRepo.GetSettingsForGroup(ctx, expense.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("getting notification settings: %w", err)
+	}
+	return resolveCommentRecipients(expense, actorID, text, members, settings), nil
+}
+
+// resolveCommentRecipients returns the current, non-placeholder members who
+// paid for or share in the expense, plus anyone @mentioned by name or email,
+// excluding the author and members who muted comments for the group.
+func resolveCommentRecipients(expense *models.Expense, actorID, text string, members []models.User, settings map[string]models.GroupNotificationSettings) []string {
+	participants := make(map[string]bool)
+	if expense.PaidByUserID != nil {
+		participants[*expense.PaidByUserID] = true
+	}
+	for _, p := range expense.Payers {
+		participants[p.UserID] = true
+	}
+	for _, split := range expense.Splits {
+		participants[split.UserID] = true
+	}
+
+	lowered := strings.ToLower(text)
+	recipients := []string{}
+	for _, m := range members {
+		if m.IsPlaceholder || m.ID == actorID {
+			continue
+		}
+		if !participants[m.ID] && !isMentioned(lowered, m) {
+			continue
+		}
+		if userSettings, ok := settings[m.ID]; ok && !userSettings.Allows(models.NotificationEventComment) {
+			continue
+		}
+		recipients = append(recipients, m.ID)
+	}
+	return recipients
+}
+
+func isMentioned(loweredText string, member models.User) bool {
+	for _, handle := range []string{member.Name, member.Email} {
+		handle = strings.ToLower(strings.TrimSpace(handle))
+		if handle == "" {
+			continue
+		}
+		mention := "@" + handle
+		for start := 0; ; {
+			i := strings.Index(loweredText[start:], mention)
+			if i < 0 {
+				break
+			}
+			end := start + i + len(mention)
+			if end == len(loweredText) {
+				return true
+			}
+			if next, _ := utf8.DecodeRuneInString(loweredText[end:]); !unicode.IsLetter(next) && !unicode.IsDigit(next) {
+				return true
+			}
+			start = end
+		}
+	}
+	return false
+}
+
 func (s *commentService) GetComments(ctx context.Context, expenseID, userID string) ([]models.Comment, error) {
-	if err := s.checkAccess(ctx, expenseID, userID); err != nil {
+	if _, err := s.checkAccess(ctx, expenseID, userID); err != nil {
 		return nil, err
 	}
 
@@ -110,7 +203,7 @@ func (s *commentService) AddReaction(ctx context.Context, commentID, userID, emo
 		return apperrors.DatabaseError("finding comment", err)
 	}
 
-	if err := s.checkAccess(ctx, comment.ExpenseID, userID); err != nil {
+	if _, err := s.checkAccess(ctx, comment.ExpenseID, userID); err != nil {
 		return err
 	}
 
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"unwise-backend/models"
+)
+
+func TestResolveCommentRecipients(t *testing.T) {
+	payer := "alice"
+	expense := &models.Expense{
+		PaidByUserID: &payer,
+		Payers:       []models.ExpensePayer{{UserID: "alice"}},
+		Splits: []models.ExpenseSplit{
+			{UserID: "alice"},
+			{UserID: "bob"},
+			{UserID: "placeholder"},
+		},
+	}
+	members := []models.User{
+		{ID: "alice", Name: "Alice", Email: "alice@example.com"},
+		{ID: "bob", Name: "Bob", Email: "bob@example.com"},
+		{ID: "carol", Name: "Carol", Email: "carol@example.com"},
+		{ID: "dave", Name: "Dave Smith", Email: "dave@example.com"},
+		{ID: "placeholder", Name: "Eve", IsPlaceholder: true},
+	}
+
+	tests := []struct {
+		name     string
+		actorID  string
+		text     string
+		settings map[string]models.GroupNotificationSettings
+		expected []string
+	}{
+		{
+			name:     "Only participants, placeholder skipped",
+			actorID:  "alice",
+			text:     "Looks right",
+			expected: []string{"bob"},
+		},
+		{
+			name:     "Non-participant author notifies all participants",
+			actorID:  "carol",
+			text:     "Was I there?",
+			expected: []string{"alice", "bob"},
+		},
+		{
+			name:     "Mentions by name and email",
+			actorID:  "alice",
+			text:     "@carol and @dave smith, can you check? cc @dave@example.com",
+			expected: []string{"bob", "carol", "dave"},
+		},
+		{
+			name:     "Mention needs a word boundary",
+			actorID:  "alice",
+			text:     "@carolina said hi",
+			expected: []string{"bob"},
+		},
+		{
+			name:     "Mentioned placeholder skipped",
+			actorID:  "alice",
+			text:     "@Eve owes this",
+			expected: []string{"bob"},
+		},
+		{
+			name:    "Muted group and disabled comments skipped",
+			actorID: "carol",
+			text:    "@dave smith fyi",
+			settings: map[string]models.GroupNotificationSettings{
+				"alice": {Muted: true, Comments: true},
+				"dave":  {Comments: false},
+			},
+			expected: []string{"bob"},
+		},
+		{
+			name:     "Self mention ignored",
+			actorID:  "bob",
+			text:     "@bob note to self",
+			expected: []string{"alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveCommentRecipients(expense, tt.actorID, tt.text, members, tt.settings)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
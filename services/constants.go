@@ -1,13 +1,81 @@
 package services
 
+import "time"
+
 const (
 	BalanceThreshold = 0.01
 	AmountTolerance  = 0.001
 	RoundingFactor   = 100.0
+
+	// Receipt item quantities and portions are stored with three decimals.
+	PortionRoundingFactor = 1000.0
+	PortionTolerance      = 0.001
+)
+
+const (
+	DashboardCacheTTL        = 30 * time.Second
+	DashboardCacheMaxEntries = 10000
+)
+
+const (
+	MinRetentionYears     = 1
+	MaxRetentionYears     = 50
+	RetentionPollInterval = time.Hour
+	RetentionRunInterval  = 24 * time.Hour
+	RetentionBatchSize    = 5
+
+	RetentionAnonymizedDescription = "Archived transaction"
+)
+
+const BalanceMetricsVerifyHourUTC = 3
+
+// Fun stats are recomputed at most once per UTC day for each group.
+const FunStatsCacheMaxEntries = 5000
+
+// MaxFiscalMonthStartDay is the latest day a user's report month can start
+// on; every month has a 28th.
+const MaxFiscalMonthStartDay = 28
+
+// Standing repayments run on a day every month has.
+const (
+	MaxStandingRepaymentDay       = 28
+	StandingRepaymentPollInterval = 15 * time.Minute
+	StandingRepaymentLease        = time.Hour
+	StandingRepaymentBatchSize    = 20
+	StandingRepaymentDescription  = "Monthly repayment"
+)
+
+// A claimed job whose worker dies is run again once JobLease expires.
+const (
+	JobWorkers        = 4
+	JobPollInterval   = 2 * time.Second
+	JobTimeout        = 5 * time.Minute
+	JobLease          = 10 * time.Minute
+	JobMaxAttempts    = 5
+	JobRetryBaseDelay = 30 * time.Second
+	JobRetryMaxDelay  = time.Hour
+	DeadJobsLimit     = 100
+	MaxJobErrorLength = 2000
+)
+
+// A balance alert fires when a member owes more than their threshold in a
+// group, and fires again only after they owe less than this share of it.
+const BalanceAlertRearmRatio = 0.8
+
+// Spending heatmaps are recomputed at most once an hour for each group.
+const (
+	HeatmapCacheTTL        = time.Hour
+	HeatmapCacheMaxEntries = 5000
 )
 
 const (
 	RecentTransactionsLimit = 5
+	NotificationsLimit      = 50
+)
+
+const (
+	DefaultQuietHoursStart = "22:00"
+	DefaultQuietHoursEnd   = "08:00"
 )
 
 const (
@@ -17,7 +85,184 @@ const (
 	MaxGroupNameLength   = 50
 )
 
+const (
+	MaxInitialGroupExpenses = 50
+)
+
 const (
 	GeneralRateLimit = 500
 	AIRateLimit      = 8
 )
+
+// Token buckets for CSV import/export, per user: requests per minute and burst.
+const (
+	ImportRateLimit = 2
+	ImportRateBurst = 3
+	ExportRateLimit = 6
+	ExportRateBurst = 5
+)
+
+// Export signature checks are public, so they are limited per IP, and the
+// uploaded file is capped well above what a group export produces.
+const (
+	ExportVerifyRateLimit = 30
+	MaxSignedExportSize   = 20 << 20
+)
+
+// Share link views are public, so they are limited per IP.
+const (
+	ShareLinkRateLimit = 30
+	MaxGroupShareLinks = 5
+	ShareTokenBytes    = 32
+)
+
+// Fixture loads mint accounts, so they are limited per IP.
+const (
+	FixtureRateLimit = 10
+	FixturePassword  = "FixturePassword123!"
+	FixtureEmailHost = "fixtures.unwise.test"
+)
+
+// Latency budgets per route family, counted from when a request arrives.
+// Everything not listed gets DefaultRequestTimeout.
+const (
+	DefaultRequestTimeout = 15 * time.Second
+	BalanceRequestTimeout = 2 * time.Second
+	AIRequestTimeout      = 10 * time.Second
+	ExportRequestTimeout  = 60 * time.Second
+	ImportRequestTimeout  = 120 * time.Second
+)
+
+// A statement run RepeatedQueryThreshold times in one request is usually an N+1.
+const (
+	RequestQueryBudget     = 50
+	RepeatedQueryThreshold = 10
+)
+
+// Contact sync: hashes accepted per request, and a per-user token bucket so
+// the endpoint can't be used to probe the user table for addresses.
+const (
+	MaxContactHashes        = 1000
+	ContactSuggestRateLimit = 2
+	ContactSuggestRateBurst = 3
+)
+
+const (
+	ReceiptURLExpiry       = 15 * time.Minute
+	ReceiptExportURLExpiry = 7 * 24 * time.Hour
+)
+
+const (
+	LocalTokenIssuer  = "unwise"
+	AccessTokenTTL    = 1 * time.Hour
+	RefreshTokenTTL   = 30 * 24 * time.Hour
+	MinPasswordLength = 8
+	AuthRateLimit     = 20
+)
+
+const (
+	MaxTagLength      = 30
+	MaxTagsPerExpense = 10
+)
+
+const (
+	MaxEventNameLength = 50
+)
+
+// The dashboard suggests archiving a group once everyone is settled and
+// nothing has been added for this many days.
+const (
+	ArchiveSuggestionInactiveDays = 30
+)
+
+const (
+	MaxMarkReadBatchSize = 500
+)
+
+const (
+	MaxSettlementReferenceLength = 100
+	MaxReversalReasonLength      = 200
+	MaxExclusionReasonLength     = 200
+)
+
+// UnverifiedSettlementLimits is the largest settlement, per currency, that a
+// user without a verified email may record. There are no exchange rates, so
+// each currency has its own figure, each worth roughly ₹1000; currencies not
+// listed use the INR figure.
+var UnverifiedSettlementLimits = map[string]float64{
+	"INR": 1000,
+	"USD": 12,
+	"EUR": 11,
+	"GBP": 10,
+	"JPY": 1800,
+	"CAD": 16,
+	"AUD": 18,
+	"CNY": 85,
+	"THB": 400,
+	"SGD": 15,
+}
+
+const (
+	PlaceholderClaimPolicyOpen     = "open"
+	PlaceholderClaimPolicyMatch    = "match"
+	PlaceholderClaimPolicyApproval = "approval"
+	MaxPlaceholdersPerMerge        = 20
+)
+
+const (
+	MaxIntegrationsPerGroup      = 5
+	MaxIntegrationTemplateLength = 1000
+	IntegrationDeliveriesLimit   = 50
+	IntegrationPollInterval      = 5 * time.Second
+	IntegrationDeliveryBatchSize = 20
+	IntegrationDeliveryLease     = 2 * time.Minute
+	IntegrationMaxAttempts       = 5
+	IntegrationRetryBaseDelay    = 30 * time.Second
+	IntegrationRequestTimeout    = 10 * time.Second
+)
+
+const (
+	GroupActivityLimit = 100
+)
+
+const (
+	MaxTransactionPageSize = 200
+)
+
+const (
+	MaxBackchargeExpenses = 50
+)
+
+const (
+	ReceiptGalleryPageSize    = 30
+	MaxReceiptGalleryPageSize = 100
+)
+
+const (
+	ReminderCooldown       = 24 * time.Hour
+	MaxReminderPromiseDays = 60
+	MaxReminderSnoozeDays  = 14
+)
+
+const (
+	MaxAnnouncementTitleLength = 120
+	MaxAnnouncementBodyLength  = 2000
+)
+
+const (
+	AIModelName                = "gemini-2.0-flash"
+	MaxAIFeedbackCommentLength = 1000
+)
+
+const (
+	OpenAIOCRModelName  = "gpt-4o-mini"
+	OCRProviderTimeout  = 30 * time.Second
+	OCRFailureThreshold = 3
+	OCRProviderCooldown = 2 * time.Minute
+)
+
+const (
+	ForecastHistoryMonths      = 3
+	ForecastRecurringMinMonths = 2
+	ForecastUncategorized      = "uncategorized"
+)
@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math"
+	"sync"
+	"time"
 
 	apperrors "unwise-backend/errors"
 	"unwise-backend/models"
@@ -13,28 +17,119 @@ import (
 )
 
 type DashboardService interface {
-	GetDashboard(ctx context.Context, userID, email, name string) (*models.DashboardResponse, error)
+	GetDashboard(ctx context.Context, userID, email, name string, emailVerified *bool) (*models.DashboardResponse, error)
+	GetDashboardVersion(ctx context.Context, userID string) (string, error)
+}
+
+type dashboardCacheEntry struct {
+	version   string
+	response  *models.DashboardResponse
+	expiresAt time.Time
+}
+
+// dashboardExpenses is the part of the expense repository the dashboard reads.
+type dashboardExpenses interface {
+	repository.ExpenseReader
+	repository.BalanceQueries
 }
 
 type dashboardService struct {
 	userRepo    repository.UserRepository
 	groupRepo   repository.GroupRepository
-	expenseRepo repository.ExpenseRepository
+	expenseRepo dashboardExpenses
+	readRepo    repository.ReadRepository
+	archiveRepo repository.GroupArchiveRepository
 	userService UserService
+
+	cacheMu sync.Mutex
+	cache   map[string]dashboardCacheEntry
 }
 
-func NewDashboardService(userRepo repository.UserRepository, groupRepo repository.GroupRepository, expenseRepo repository.ExpenseRepository, userService UserService) DashboardService {
+func NewDashboardService(userRepo repository.UserRepository, groupRepo repository.GroupRepository, expenseRepo dashboardExpenses, readRepo repository.ReadRepository, archiveRepo repository.GroupArchiveRepository, userService UserService) DashboardService {
 	return &dashboardService{
 		userRepo:    userRepo,
 		groupRepo:   groupRepo,
 		expenseRepo: expenseRepo,
+		readRepo:    readRepo,
+		archiveRepo: archiveRepo,
 		userService: userService,
+		cache:       make(map[string]dashboardCacheEntry),
+	}
+}
+
+func (s *dashboardService) GetDashboardVersion(ctx context.Context, userID string) (string, error) {
+	version, err := s.expenseRepo.GetDashboardVersion(ctx, userID)
+	if err != nil {
+		return "", apperrors.DatabaseError("getting dashboard version", err)
+	}
+	sum := sha256.Sum256([]byte(userID + "|" + version))
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func (s *dashboardService) GetDashboard(ctx context.Context, userID, email, name string, emailVerified *bool) (*models.DashboardResponse, error) {
+	version, err := s.GetDashboardVersion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached := s.getCached(userID, version); cached != nil {
+		zap.L().Debug("Serving cached dashboard", zap.String("user_id", userID))
+		return cached, nil
 	}
+
+	dashboard, err := s.buildDashboard(ctx, userID, email, name, emailVerified)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Version = version
+	s.putCached(userID, dashboard)
+	return copyDashboard(dashboard), nil
 }
 
-func (s *dashboardService) GetDashboard(ctx context.Context, userID, email, name string) (*models.DashboardResponse, error) {
+func (s *dashboardService) getCached(userID, version string) *models.DashboardResponse {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	entry, ok := s.cache[userID]
+	if !ok || entry.version != version || time.Now().After(entry.expiresAt) {
+		return nil
+	}
+	return copyDashboard(entry.response)
+}
+
+func (s *dashboardService) putCached(userID string, dashboard *models.DashboardResponse) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	now := time.Now()
+	if len(s.cache) >= DashboardCacheMaxEntries {
+		for id, entry := range s.cache {
+			if now.After(entry.expiresAt) {
+				delete(s.cache, id)
+			}
+		}
+	}
+	if len(s.cache) >= DashboardCacheMaxEntries {
+		return
+	}
+	s.cache[userID] = dashboardCacheEntry{
+		version:   dashboard.Version,
+		response:  dashboard,
+		expiresAt: now.Add(DashboardCacheTTL),
+	}
+}
+
+func copyDashboard(dashboard *models.DashboardResponse) *models.DashboardResponse {
+	copied := *dashboard
+	copied.Groups = append([]models.DashboardGroup(nil), dashboard.Groups...)
+	copied.RecentActivity = append([]models.DashboardActivity(nil), dashboard.RecentActivity...)
+	copied.ArchiveSuggestions = append([]models.ArchiveSuggestion{}, dashboard.ArchiveSuggestions...)
+	return &copied
+}
+
+func (s *dashboardService) buildDashboard(ctx context.Context, userID, email, name string, emailVerified *bool) (*models.DashboardResponse, error) {
 	zap.L().Debug("Fetching dashboard data", zap.String("user_id", userID))
-	user, err := s.userService.EnsureUser(ctx, userID, email, name)
+	user, err := s.userService.EnsureUser(ctx, userID, email, name, emailVerified)
 	if err != nil {
 		zap.L().Error("Failed to ensure user exists for dashboard", zap.String("user_id", userID), zap.Error(err))
 		return nil, apperrors.InternalError(fmt.Errorf("ensuring user exists: %w", err))
@@ -63,6 +158,12 @@ func (s *dashboardService) GetDashboard(ctx context.Context, userID, email, name
 		return nil, apperrors.DatabaseError("getting group balances", err)
 	}
 
+	unreadCounts, err := s.readRepo.GetUnreadCounts(ctx, userID, groupIDs)
+	if err != nil {
+		zap.L().Error("Failed to get unread counts", zap.String("user_id", userID), zap.Error(err))
+		return nil, apperrors.DatabaseError("getting unread counts", err)
+	}
+
 	if groups == nil {
 		groups = []models.DashboardGroup{}
 	}
@@ -70,6 +171,7 @@ func (s *dashboardService) GetDashboard(ctx context.Context, userID, email, name
 	for i := range groups {
 		balance := groupBalances[groups[i].ID]
 		groups[i].MyBalanceInGroup = math.Round(balance*RoundingFactor) / RoundingFactor
+		groups[i].UnreadCount = unreadCounts[groups[i].ID]
 	}
 
 	recentExpenses, err := s.expenseRepo.GetRecentTransactionsForUser(ctx, userID, RecentTransactionsLimit)
@@ -102,17 +204,24 @@ func (s *dashboardService) GetDashboard(ctx context.Context, userID, email, name
 		actionText := s.generateActionTextOptimized(expense, userID, payers, splits)
 
 		recentActivity = append(recentActivity, models.DashboardActivity{
-			ID:              expense.ID,
-			Description:     expense.Description,
-			Amount:          expense.TotalAmount,
-			Type:            string(expense.Category),
-			ActionText:      actionText,
-			ReceiptImageURL: expense.ReceiptImageURL,
-			CreatedAt:       expense.CreatedAt,
-			Date:            expense.DateISO,
+			ID:               expense.ID,
+			Description:      expense.Description,
+			Amount:           expense.TotalAmount,
+			Type:             string(expense.Category),
+			ActionText:       actionText,
+			ReceiptImagePath: expense.ReceiptImagePath,
+			CreatedAt:        expense.CreatedAt,
+			Date:             expense.DateISO,
 		})
 	}
 
+	inactiveSince := time.Now().AddDate(0, 0, -ArchiveSuggestionInactiveDays)
+	archiveSuggestions, err := s.archiveRepo.GetSuggestions(ctx, userID, inactiveSince)
+	if err != nil {
+		zap.L().Error("Failed to get archive suggestions", zap.String("user_id", userID), zap.Error(err))
+		return nil, apperrors.DatabaseError("getting archive suggestions", err)
+	}
+
 	var legacyNet, legacyOwe, legacyOwed float64
 	for _, b := range totalBalances {
 		if b.Currency == "INR" {
@@ -152,8 +261,9 @@ func (s *dashboardService) GetDashboard(ctx context.Context, userID, email, name
 			BalancesOwe:   oweBalances,
 			BalancesOwed:  owedBalances,
 		},
-		Groups:         groups,
-		RecentActivity: recentActivity,
+		Groups:             groups,
+		RecentActivity:     recentActivity,
+		ArchiveSuggestions: archiveSuggestions,
 	}, nil
 }
 
@@ -187,6 +297,14 @@ func (s *dashboardService) generateActionTextOptimized(expense models.Expense, u
 		}
 		return fmt.Sprintf("You received repayment of $%.2f", expense.TotalAmount)
 
+	case models.TransactionCategoryRefund:
+		if userPaidAmount < -BalanceThreshold {
+			return fmt.Sprintf("You received a refund of $%.2f", math.Abs(userPaidAmount))
+		} else if userShareAmount < -BalanceThreshold {
+			return fmt.Sprintf("You got back $%.2f", math.Abs(userShareAmount))
+		}
+		return "Refund recorded"
+
 	case models.TransactionCategoryExpense:
 		if math.Abs(netAmount) < BalanceThreshold {
 			return "You are settled"
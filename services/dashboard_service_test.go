@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+type versionedExpenseRepo struct {
+	mockExpenseRepo
+	versions map[string]string
+}
+
+func (r *versionedExpenseRepo) GetDashboardVersion(_ context.Context, userID string) (string, error) {
+	return r.versions[userID], nil
+}
+
+func TestGetDashboardVersion(t *testing.T) {
+	repo := &versionedExpenseRepo{versions: map[string]string{"alice": "v1", "bob": "v1"}}
+	s := NewDashboardService(nil, nil, repo, nil, nil, nil)
+	ctx := context.Background()
+
+	first, err := s.GetDashboardVersion(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetDashboardVersion() error = %v", err)
+	}
+	again, _ := s.GetDashboardVersion(ctx, "alice")
+	bob, _ := s.GetDashboardVersion(ctx, "bob")
+	repo.versions["alice"] = "v2"
+	changed, _ := s.GetDashboardVersion(ctx, "alice")
+
+	if first != again {
+		t.Errorf("GetDashboardVersion() = %q then %q, expected it to be stable", first, again)
+	}
+	if first == bob {
+		t.Error("GetDashboardVersion() gave two users with the same data version the same ETag")
+	}
+	if first == changed {
+		t.Error("GetDashboardVersion() did not change with the data version")
+	}
+}
+
+func TestDashboardCache(t *testing.T) {
+	s := NewDashboardService(nil, nil, nil, nil, nil, nil).(*dashboardService)
+	s.putCached("alice", &models.DashboardResponse{Version: "v1", Groups: []models.DashboardGroup{{}}})
+
+	if s.getCached("bob", "v1") != nil {
+		t.Error("getCached() returned another user's dashboard")
+	}
+	if s.getCached("alice", "v2") != nil {
+		t.Error("getCached() returned a dashboard for a stale version")
+	}
+
+	hit := s.getCached("alice", "v1")
+	if hit == nil || len(hit.Groups) != 1 {
+		t.Fatalf("getCached() = %+v, expected the cached dashboard", hit)
+	}
+	hit.Groups[0].Name = "changed by caller"
+	hit.Groups = nil
+	if again := s.getCached("alice", "v1"); len(again.Groups) != 1 || again.Groups[0].Name != "" {
+		t.Errorf("getCached() = %+v, expected callers' changes not to reach the cache", again)
+	}
+
+	entry := s.cache["alice"]
+	entry.expiresAt = time.Now().Add(-time.Second)
+	s.cache["alice"] = entry
+	if s.getCached("alice", "v1") != nil {
+		t.Error("getCached() returned an expired dashboard")
+	}
+}
+
+func TestCopyDashboardArchiveSuggestions(t *testing.T) {
+	copied := copyDashboard(&models.DashboardResponse{})
+	if copied.ArchiveSuggestions == nil {
+		t.Error("copyDashboard() ArchiveSuggestions = nil, expected an empty list so the JSON is []")
+	}
+
+	original := &models.DashboardResponse{ArchiveSuggestions: []models.ArchiveSuggestion{{GroupID: "g1"}}}
+	copied = copyDashboard(original)
+	copied.ArchiveSuggestions[0].GroupID = "changed"
+	if original.ArchiveSuggestions[0].GroupID != "g1" {
+		t.Error("copyDashboard() shares ArchiveSuggestions with the original")
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+)
+
+type EventService interface {
+	GetGroupEvents(ctx context.Context, groupID, userID string) ([]models.GroupEvent, error)
+	GetEvent(ctx context.Context, groupID, eventID, userID string) (*models.GroupEventDetail, error)
+	CreateEvent(ctx context.Context, groupID, userID, name string) (*models.GroupEvent, error)
+	DeleteEvent(ctx context.Context, groupID, eventID, userID string) error
+}
+
+type eventService struct {
+	eventRepo repository.EventRepository
+	groupRepo repository.GroupRepository
+}
+
+func NewEventService(eventRepo repository.EventRepository, groupRepo repository.GroupRepository) EventService {
+	return &eventService{
+		eventRepo: eventRepo,
+		groupRepo: groupRepo,
+	}
+}
+
+func (s *eventService) GetGroupEvents(ctx context.Context, groupID, userID string) ([]models.GroupEvent, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	events, err := s.eventRepo.GetByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting events", err)
+	}
+
+	totals, err := s.eventRepo.GetTotalsByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting event totals", err)
+	}
+	byEvent := make(map[string][]models.EventTotal)
+	for _, t := range totals {
+		t.Total = math.Round(t.Total*RoundingFactor) / RoundingFactor
+		byEvent[t.EventID] = append(byEvent[t.EventID], t)
+	}
+	for i := range events {
+		events[i].Totals = byEvent[events[i].ID]
+		if events[i].Totals == nil {
+			events[i].Totals = []models.EventTotal{}
+		}
+	}
+	return events, nil
+}
+
+func (s *eventService) GetEvent(ctx context.Context, groupID, eventID, userID string) (*models.GroupEventDetail, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	event, err := s.getGroupEvent(ctx, groupID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.eventRepo.GetTotalsByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting event totals", err)
+	}
+	event.Totals = []models.EventTotal{}
+	for _, t := range totals {
+		if t.EventID == eventID {
+			t.Total = math.Round(t.Total*RoundingFactor) / RoundingFactor
+			event.Totals = append(event.Totals, t)
+		}
+	}
+
+	shares, err := s.eventRepo.GetMemberShares(ctx, eventID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting event shares", err)
+	}
+	for i := range shares {
+		shares[i].Paid = math.Round(shares[i].Paid*RoundingFactor) / RoundingFactor
+		shares[i].Share = math.Round(shares[i].Share*RoundingFactor) / RoundingFactor
+		shares[i].Net = math.Round((shares[i].Paid-shares[i].Share)*RoundingFactor) / RoundingFactor
+	}
+
+	return &models.GroupEventDetail{GroupEvent: *event, Shares: shares}, nil
+}
+
+func (s *eventService) CreateEvent(ctx context.Context, groupID, userID, name string) (*models.GroupEvent, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	name = NormalizeName(strings.Join(strings.Fields(name), " "))
+	if name == "" {
+		return nil, apperrors.MissingRequiredField("Event name")
+	}
+	if NameLength(name) > MaxEventNameLength {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("Event name can be at most %d characters.", MaxEventNameLength))
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.GroupNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting group", err)
+	}
+	if group.Type != models.GroupTypeTrip {
+		return nil, apperrors.InvalidRequest("Events can only be created in TRIP groups.")
+	}
+
+	event := &models.GroupEvent{
+		ID:        uuid.New().String(),
+		GroupID:   groupID,
+		Name:      name,
+		CreatedBy: &userID,
+		Totals:    []models.EventTotal{},
+	}
+	if err := s.eventRepo.Create(ctx, event); err != nil {
+		if apperrors.IsDuplicateError(err) {
+			return nil, apperrors.DuplicateEntry("Event")
+		}
+		return nil, apperrors.DatabaseError("creating event", err)
+	}
+	return event, nil
+}
+
+// DeleteEvent removes the event. Its expenses stay in the group, unattached.
+func (s *eventService) DeleteEvent(ctx context.Context, groupID, eventID, userID string) error {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return err
+	}
+	if _, err := s.getGroupEvent(ctx, groupID, eventID); err != nil {
+		return err
+	}
+	if err := s.eventRepo.Delete(ctx, eventID); err != nil {
+		return apperrors.DatabaseError("deleting event", err)
+	}
+	return nil
+}
+
+func (s *eventService) getGroupEvent(ctx context.Context, groupID, eventID string) (*models.GroupEvent, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.NotFound("Event")
+		}
+		return nil, apperrors.DatabaseError("getting event", err)
+	}
+	if event.GroupID != groupID {
+		return nil, apperrors.NotFound("Event")
+	}
+	return event, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+type fakeEventRepo struct {
+	stubEventRepository
+	events  map[string]*models.GroupEvent
+	totals  []models.EventTotal
+	shares  []models.EventMemberShare
+	created []*models.GroupEvent
+	deleted []string
+}
+
+func (r *fakeEventRepo) Create(_ context.Context, event *models.GroupEvent) error {
+	for _, existing := range r.events {
+		if existing.GroupID == event.GroupID && existing.Name == event.Name {
+			return fmt.Errorf("creating event: duplicate key value violates unique constraint")
+		}
+	}
+	r.created = append(r.created, event)
+	return nil
+}
+
+func (r *fakeEventRepo) GetByID(_ context.Context, eventID string) (*models.GroupEvent, error) {
+	if event, ok := r.events[eventID]; ok {
+		copied := *event
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("getting event: no rows in result set")
+}
+
+func (r *fakeEventRepo) GetTotalsByGroupID(context.Context, string) ([]models.EventTotal, error) {
+	return r.totals, nil
+}
+
+func (r *fakeEventRepo) GetMemberShares(context.Context, string) ([]models.EventMemberShare, error) {
+	return r.shares, nil
+}
+
+func (r *fakeEventRepo) Delete(_ context.Context, eventID string) error {
+	r.deleted = append(r.deleted, eventID)
+	return nil
+}
+
+func TestCreateEvent(t *testing.T) {
+	groups := &fixedGroupRepo{groups: map[string]*models.Group{
+		"trip": {ID: "trip", Type: models.GroupTypeTrip},
+		"home": {ID: "home", Type: models.GroupTypeHome},
+	}}
+	existing := map[string]*models.GroupEvent{"e1": {ID: "e1", GroupID: "trip", Name: "Day 1"}}
+
+	tests := []struct {
+		name         string
+		groupID      string
+		eventName    string
+		expectedName string
+		expectedCode apperrors.ErrorCode
+	}{
+		{name: "Trip Group", groupID: "trip", eventName: "  Beach \t day ", expectedName: "Beach day"},
+		{name: "Not A Trip", groupID: "home", eventName: "Diwali", expectedCode: apperrors.CodeInvalidRequest},
+		{name: "Blank Name", groupID: "trip", eventName: "   ", expectedCode: apperrors.CodeMissingRequiredField},
+		{name: "Name Too Long", groupID: "trip", eventName: strings.Repeat("a", MaxEventNameLength+1), expectedCode: apperrors.CodeInvalidRequest},
+		{name: "Duplicate Name", groupID: "trip", eventName: "Day 1", expectedCode: apperrors.CodeDuplicateEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &fakeEventRepo{events: existing}
+			s := NewEventService(events, groups)
+
+			event, err := s.CreateEvent(context.Background(), tt.groupID, "alice", tt.eventName)
+			if tt.expectedCode != "" {
+				if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+					t.Errorf("CreateEvent() error = %v, expected %s", err, tt.expectedCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateEvent() error = %v", err)
+			}
+			if event.Name != tt.expectedName || event.GroupID != tt.groupID || len(events.created) != 1 {
+				t.Errorf("CreateEvent() = %+v, expected %q saved in %s", event, tt.expectedName, tt.groupID)
+			}
+		})
+	}
+}
+
+func TestGetEvent(t *testing.T) {
+	events := &fakeEventRepo{
+		events: map[string]*models.GroupEvent{
+			"e1":    {ID: "e1", GroupID: "trip", Name: "Day 1"},
+			"other": {ID: "other", GroupID: "elsewhere", Name: "Day 1"},
+		},
+		totals: []models.EventTotal{
+			{EventID: "e1", Currency: "INR", Total: 1000.004, ExpenseCount: 2},
+			{EventID: "e2", Currency: "INR", Total: 50},
+		},
+		shares: []models.EventMemberShare{
+			{UserID: "alice", Currency: "INR", Paid: 1000.004, Share: 333.333},
+			{UserID: "bob", Currency: "INR", Share: 666.671},
+		},
+	}
+	s := NewEventService(events, &mockGroupRepo{})
+
+	detail, err := s.GetEvent(context.Background(), "trip", "e1", "alice")
+	if err != nil {
+		t.Fatalf("GetEvent() error = %v", err)
+	}
+	if len(detail.Totals) != 1 || detail.Totals[0].Total != 1000 {
+		t.Errorf("GetEvent() totals = %+v, expected only this event's 1000 INR", detail.Totals)
+	}
+	if detail.Shares[0].Net != 666.67 || detail.Shares[1].Net != -666.67 {
+		t.Errorf("GetEvent() nets = %v and %v, expected 666.67 and -666.67", detail.Shares[0].Net, detail.Shares[1].Net)
+	}
+
+	for _, eventID := range []string{"other", "missing"} {
+		if _, err := s.GetEvent(context.Background(), "trip", eventID, "alice"); err == nil {
+			t.Errorf("GetEvent(%s) succeeded, expected not found", eventID)
+		} else if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeNotFound {
+			t.Errorf("GetEvent(%s) error = %v, expected not found", eventID, err)
+		}
+	}
+	if err := s.DeleteEvent(context.Background(), "trip", "other", "alice"); err == nil || len(events.deleted) != 0 {
+		t.Errorf("DeleteEvent() of another group's event = %v, deleted %v, expected not found and nothing deleted", err, events.deleted)
+	}
+}
+
+func TestCheckEvent(t *testing.T) {
+	s := &expenseService{eventRepo: &fakeEventRepo{events: map[string]*models.GroupEvent{
+		"e1": {ID: "e1", GroupID: "trip"},
+		"e2": {ID: "e2", GroupID: "elsewhere"},
+	}}}
+	id := func(v string) *string { return &v }
+
+	tests := []struct {
+		name      string
+		eventID   *string
+		wantErr   bool
+		expectNil bool
+	}{
+		{name: "No Event", eventID: nil, expectNil: true},
+		{name: "Empty Detaches", eventID: id(""), expectNil: true},
+		{name: "Event In Group", eventID: id("e1")},
+		{name: "Event In Another Group", eventID: id("e2"), wantErr: true},
+		{name: "Unknown Event", eventID: id("e3"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expense := &models.Expense{GroupID: "trip", EventID: tt.eventID}
+			err := s.checkEvent(context.Background(), expense)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (expense.EventID == nil) != tt.expectNil {
+				t.Errorf("checkEvent() EventID = %v, expected nil = %v", expense.EventID, tt.expectNil)
+			}
+		})
+	}
+}
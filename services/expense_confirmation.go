@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Tags are only replaced when the edit named them.
+type changePayload struct {
+	Expense          models.Expense        `json:"expense"`
+	Splits           []models.ExpenseSplit `json:"splits"`
+	ReplaceTags      bool                  `json:"replace_tags"`
+	ConfirmOverLimit bool                  `json:"confirm_over_limit"`
+}
+
+type pairKey struct {
+	a, b string
+}
+
+func newPairKey(x, y string) pairKey {
+	if y < x {
+		x, y = y, x
+	}
+	return pairKey{a: x, b: y}
+}
+
+// pairContributions attributes shares to payers like pairwiseNet, so pairs
+// without a payer owe nothing.
+func pairContributions(payers []models.ExpensePayer, splits []models.ExpenseSplit) map[pairKey]float64 {
+	result := make(map[pairKey]float64)
+	paid := make(map[string]float64)
+	owed := make(map[string]float64)
+	totalPaid := 0.0
+	for _, p := range payers {
+		paid[p.UserID] += p.AmountPaid
+		totalPaid += p.AmountPaid
+	}
+	if math.Abs(totalPaid) < BalanceThreshold {
+		return result
+	}
+	for _, split := range splits {
+		owed[split.UserID] += split.Amount
+	}
+
+	var users []string
+	for id := range paid {
+		users = append(users, id)
+	}
+	for id := range owed {
+		if _, ok := paid[id]; !ok {
+			users = append(users, id)
+		}
+	}
+	sort.Strings(users)
+
+	for i, a := range users {
+		for _, b := range users[i+1:] {
+			if paid[a] == 0 && paid[b] == 0 {
+				continue
+			}
+			result[pairKey{a: a, b: b}] = (paid[a]*owed[b] - paid[b]*owed[a]) / totalPaid
+		}
+	}
+	return result
+}
+
+func changedPairs(existing *models.Expense, payers []models.ExpensePayer, splits []models.ExpenseSplit) map[pairKey]bool {
+	before := pairContributions(existing.Payers, existing.Splits)
+	after := pairContributions(payers, splits)
+
+	changed := make(map[pairKey]bool)
+	for key, amount := range before {
+		if math.Abs(after[key]-amount) > BalanceThreshold {
+			changed[key] = true
+		}
+	}
+	for key, amount := range after {
+		if _, ok := before[key]; !ok && math.Abs(amount) > BalanceThreshold {
+			changed[key] = true
+		}
+	}
+	return changed
+}
+
+// Both members of a changed pair must accept when they last settled on or
+// after the expense's date. The editor's own acceptance is implied.
+func (s *expenseService) settledPairConfirmers(ctx context.Context, existing, expense *models.Expense, splits []models.ExpenseSplit, userID string) ([]string, error) {
+	if s.changeRepo == nil {
+		return nil, nil
+	}
+	changed := changedPairs(existing, expense.Payers, splits)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var userIDs []string
+	for key := range changed {
+		for _, id := range []string{key.a, key.b} {
+			if !seen[id] {
+				seen[id] = true
+				userIDs = append(userIDs, id)
+			}
+		}
+	}
+
+	ledgers, err := s.expenseRepo.GetPairLedgers(ctx, existing.GroupID, existing.Currency, userIDs)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting pair balances", err)
+	}
+
+	confirm := make(map[string]bool)
+	for _, l := range ledgers {
+		if !changed[newPairKey(l.UserA, l.UserB)] || l.LastSettledAt == nil {
+			continue
+		}
+		if existing.DateISO.After(*l.LastSettledAt) || math.Abs(l.Net) > BalanceThreshold {
+			continue
+		}
+		confirm[l.UserA] = true
+		confirm[l.UserB] = true
+	}
+	delete(confirm, userID)
+
+	confirmers := make([]string, 0, len(confirm))
+	for id := range confirm {
+		confirmers = append(confirmers, id)
+	}
+	sort.Strings(confirmers)
+	return confirmers, nil
+}
+
+func (s *expenseService) proposeChange(ctx context.Context, existing, expense *models.Expense, splits []models.ExpenseSplit, userID string, confirmers []string) (*models.PendingExpenseChange, error) {
+	payload, err := json.Marshal(changePayload{
+		Expense:          *expense,
+		Splits:           splits,
+		ReplaceTags:      expense.Tags != nil,
+		ConfirmOverLimit: expense.ConfirmOverLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding pending change: %w", err)
+	}
+
+	change := &models.PendingExpenseChange{
+		ID:          uuid.New().String(),
+		ExpenseID:   existing.ID,
+		GroupID:     existing.GroupID,
+		RequestedBy: userID,
+		Payload:     payload,
+	}
+	for _, id := range confirmers {
+		change.Confirmations = append(change.Confirmations, models.ExpenseChangeConfirmation{UserID: id})
+	}
+
+	names := s.memberNames(ctx, existing.GroupID)
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		txRepo := s.changeRepo.WithTx(q)
+		if err := txRepo.SupersedePending(ctx, existing.ID); err != nil {
+			return apperrors.DatabaseError("superseding pending changes", err)
+		}
+		if err := txRepo.Create(ctx, change); err != nil {
+			return apperrors.DatabaseError("storing pending change", err)
+		}
+		return s.recordExpenseActivity(ctx, q, existing, userID, models.GroupActivityChangeProposed,
+			fmt.Sprintf("%s proposed a change to '%s' that members who settled up need to confirm", nameOf(names, userID), existing.Description))
+	})
+	if err != nil {
+		zap.L().Error("Failed to store pending expense change", zap.String("expense_id", existing.ID), zap.Error(err))
+		return nil, err
+	}
+
+	zap.L().Info("Expense change held for confirmation",
+		zap.String("expense_id", existing.ID),
+		zap.String("change_id", change.ID),
+		zap.Strings("confirmers", confirmers))
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:      models.NotificationEventChange,
+		GroupID:    existing.GroupID,
+		ExpenseID:  existing.ID,
+		ActorID:    userID,
+		Template:   notifyChangeRequested,
+		Args:       []interface{}{nameOf(names, userID), existing.Description},
+		Recipients: confirmers,
+	})
+
+	if err := s.describeChange(change, names); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *expenseService) GetPendingChanges(ctx context.Context, userID string) ([]models.PendingExpenseChange, error) {
+	changes, err := s.changeRepo.GetAwaitingUser(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting pending changes", err)
+	}
+	for i := range changes {
+		if err := s.describeChange(&changes[i], nil); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// The last acceptance applies the change as an edit by its proposer;
+// accepting again retries an apply that failed.
+func (s *expenseService) RespondToChange(ctx context.Context, changeID, userID string, accept bool) (*models.PendingExpenseChange, error) {
+	var change *models.PendingExpenseChange
+	var expense *models.Expense
+	var name string
+	ready := false
+
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		txRepo := s.changeRepo.WithTx(q)
+		var err error
+		change, err = txRepo.GetByIDForUpdate(ctx, changeID)
+		if err != nil {
+			if apperrors.IsNotFoundError(err) {
+				return apperrors.NotFound("Pending change")
+			}
+			return apperrors.DatabaseError("getting pending change", err)
+		}
+		confirmation := findConfirmation(change, userID)
+		if confirmation == nil {
+			return apperrors.NotFound("Pending change")
+		}
+		if change.Status != models.ExpenseChangePending {
+			return apperrors.Conflict(fmt.Sprintf("This change is no longer pending (%s).", change.Status))
+		}
+
+		if !accept {
+			if err := txRepo.Respond(ctx, changeID, userID, models.ExpenseChangeConfirmationRejected); err != nil {
+				return apperrors.DatabaseError("rejecting change", err)
+			}
+			if _, err := txRepo.Resolve(ctx, changeID, models.ExpenseChangeRejected); err != nil {
+				return apperrors.DatabaseError("rejecting change", err)
+			}
+			confirmation.Status = models.ExpenseChangeConfirmationRejected
+			change.Status = models.ExpenseChangeRejected
+
+			expense, err = s.expenseRepo.WithTx(q).GetByID(ctx, change.ExpenseID)
+			if err != nil {
+				return apperrors.DatabaseError("getting expense", err)
+			}
+			name = s.memberName(ctx, change.GroupID, userID)
+			return s.recordExpenseActivity(ctx, q, expense, userID, models.GroupActivityChangeRejected,
+				fmt.Sprintf("%s rejected a change to '%s'", name, expense.Description))
+		}
+
+		if confirmation.Status != models.ExpenseChangeConfirmationAccepted {
+			if err := txRepo.Respond(ctx, changeID, userID, models.ExpenseChangeConfirmationAccepted); err != nil {
+				return apperrors.DatabaseError("accepting change", err)
+			}
+			confirmation.Status = models.ExpenseChangeConfirmationAccepted
+		}
+		ready = true
+		for _, c := range change.Confirmations {
+			if c.Status != models.ExpenseChangeConfirmationAccepted {
+				ready = false
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !accept {
+		zap.L().Info("Expense change rejected", zap.String("change_id", changeID), zap.String("user_id", userID))
+		dispatchNotificationAsync(s.notificationService, NotificationPayload{
+			Event:      models.NotificationEventChange,
+			GroupID:    change.GroupID,
+			ExpenseID:  change.ExpenseID,
+			ActorID:    userID,
+			Template:   notifyChangeRejected,
+			Args:       []interface{}{name, expense.Description},
+			Recipients: []string{change.RequestedBy},
+		})
+	}
+
+	if ready {
+		var payload changePayload
+		if err := json.Unmarshal(change.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("decoding pending change: %w", err)
+		}
+		proposed := payload.Expense
+		proposed.ConfirmOverLimit = payload.ConfirmOverLimit
+		if payload.ReplaceTags && proposed.Tags == nil {
+			proposed.Tags = []string{}
+		}
+		if !payload.ReplaceTags {
+			proposed.Tags = nil
+		}
+		if _, err := s.update(ctx, change.ExpenseID, change.RequestedBy, &proposed, payload.Splits, change); err != nil {
+			zap.L().Error("Failed to apply confirmed expense change", zap.String("change_id", changeID), zap.Error(err))
+			return nil, err
+		}
+		change.Status = models.ExpenseChangeApplied
+
+		zap.L().Info("Expense change applied", zap.String("change_id", changeID), zap.String("expense_id", change.ExpenseID))
+		dispatchNotificationAsync(s.notificationService, NotificationPayload{
+			Event:      models.NotificationEventChange,
+			GroupID:    change.GroupID,
+			ExpenseID:  change.ExpenseID,
+			ActorID:    userID,
+			Template:   notifyChangeApplied,
+			Args:       []interface{}{proposed.Description},
+			Recipients: []string{change.RequestedBy},
+		})
+	}
+
+	if err := s.describeChange(change, nil); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Any edit other than the approved one supersedes the waiting change, which
+// was proposed against the expense as it was.
+func (s *expenseService) closePendingChanges(ctx context.Context, q database.Querier, expense *models.Expense, approved *models.PendingExpenseChange) error {
+	if s.changeRepo == nil {
+		return nil
+	}
+	txRepo := s.changeRepo.WithTx(q)
+	if approved != nil {
+		ok, err := txRepo.Resolve(ctx, approved.ID, models.ExpenseChangeApplied)
+		if err != nil {
+			return apperrors.DatabaseError("applying pending change", err)
+		}
+		if !ok {
+			return apperrors.Conflict("This change is no longer pending.")
+		}
+		if err := s.recordExpenseActivity(ctx, q, expense, approved.RequestedBy, models.GroupActivityChangeApplied,
+			fmt.Sprintf("A change to '%s' was confirmed and applied", expense.Description)); err != nil {
+			return err
+		}
+	}
+	if err := txRepo.SupersedePending(ctx, expense.ID); err != nil {
+		return apperrors.DatabaseError("superseding pending changes", err)
+	}
+	return nil
+}
+
+func (s *expenseService) describeChange(change *models.PendingExpenseChange, names map[string]string) error {
+	var payload changePayload
+	if err := json.Unmarshal(change.Payload, &payload); err != nil {
+		return fmt.Errorf("decoding pending change: %w", err)
+	}
+	proposed := payload.Expense
+	proposed.Splits = payload.Splits
+	change.Proposed = &proposed
+
+	for i := range change.Confirmations {
+		if name, ok := names[change.Confirmations[i].UserID]; ok {
+			change.Confirmations[i].Name = name
+		}
+	}
+	return nil
+}
+
+func (s *expenseService) recordExpenseActivity(ctx context.Context, q database.Querier, expense *models.Expense, actorID string, action models.GroupActivityAction, message string) error {
+	if s.activityRepo == nil {
+		return nil
+	}
+	activity := &models.GroupActivity{
+		ID:        uuid.New().String(),
+		GroupID:   expense.GroupID,
+		ActorID:   &actorID,
+		ExpenseID: &expense.ID,
+		Action:    action,
+		Message:   message,
+	}
+	if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+		return apperrors.DatabaseError("recording group activity", err)
+	}
+	return nil
+}
+
+func (s *expenseService) memberNames(ctx context.Context, groupID string) map[string]string {
+	names := make(map[string]string)
+	members, err := s.groupRepo.GetMembers(ctx, groupID)
+	if err != nil {
+		zap.L().Warn("Failed to get group members", zap.String("group_id", groupID), zap.Error(err))
+	}
+	for _, m := range members {
+		names[m.ID] = m.Name
+	}
+	return names
+}
+
+func nameOf(names map[string]string, userID string) string {
+	if name, ok := names[userID]; ok {
+		return name
+	}
+	return "Someone"
+}
+
+func findConfirmation(change *models.PendingExpenseChange, userID string) *models.ExpenseChangeConfirmation {
+	for i := range change.Confirmations {
+		if change.Confirmations[i].UserID == userID {
+			return &change.Confirmations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestPairContributions(t *testing.T) {
+	got := pairContributions(
+		[]models.ExpensePayer{{UserID: "A", AmountPaid: 60}, {UserID: "B", AmountPaid: 30}},
+		[]models.ExpenseSplit{{UserID: "A", Amount: 30}, {UserID: "B", Amount: 30}, {UserID: "C", Amount: 30}},
+	)
+	want := map[pairKey]float64{
+		{a: "A", b: "B"}: 10, // B owes A 2/3 of 30, A owes B 1/3 of 30
+		{a: "A", b: "C"}: 20,
+		{a: "B", b: "C"}: 10,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for key, amount := range want {
+		if math.Abs(got[key]-amount) > AmountTolerance {
+			t.Errorf("%v owes %.2f, want %.2f", key, got[key], amount)
+		}
+	}
+}
+
+func TestSettledPairConfirmers(t *testing.T) {
+	expenseDate := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	settledAfter := expenseDate.AddDate(0, 0, 7)
+	settledBefore := expenseDate.AddDate(0, 0, -7)
+
+	existing := &models.Expense{
+		ID: "e1", GroupID: "g1", Currency: "INR", DateISO: expenseDate,
+		Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 90}},
+		Splits: []models.ExpenseSplit{{UserID: "A", Amount: 30}, {UserID: "B", Amount: 30}, {UserID: "C", Amount: 30}},
+	}
+	// Moves 10 from C's share to B's: A-B and A-C change, B-C does not.
+	edited := &models.Expense{Payers: existing.Payers}
+	splits := []models.ExpenseSplit{{UserID: "A", Amount: 30}, {UserID: "B", Amount: 40}, {UserID: "C", Amount: 20}}
+
+	tests := []struct {
+		name    string
+		ledgers []models.PairLedger
+		editor  string
+		want    []string
+	}{
+		{
+			name:    "Settled Since Expense",
+			ledgers: []models.PairLedger{{UserA: "A", UserB: "B", LastSettledAt: &settledAfter}},
+			editor:  "C",
+			want:    []string{"A", "B"},
+		},
+		{
+			name:    "Editor Is In The Pair",
+			ledgers: []models.PairLedger{{UserA: "A", UserB: "B", LastSettledAt: &settledAfter}},
+			editor:  "A",
+			want:    []string{"B"},
+		},
+		{
+			name:    "Settled Before Expense",
+			ledgers: []models.PairLedger{{UserA: "A", UserB: "B", LastSettledAt: &settledBefore}},
+			editor:  "A",
+		},
+		{
+			name:    "Still Owing",
+			ledgers: []models.PairLedger{{UserA: "A", UserB: "C", Net: 15, LastSettledAt: &settledAfter}},
+			editor:  "A",
+		},
+		{
+			name:    "Never Settled",
+			ledgers: []models.PairLedger{{UserA: "A", UserB: "B"}},
+			editor:  "A",
+		},
+		{
+			name:    "Unchanged Pair",
+			ledgers: []models.PairLedger{{UserA: "B", UserB: "C", LastSettledAt: &settledAfter}},
+			editor:  "A",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &expenseService{
+				expenseRepo: &mockExpenseRepo{pairLedgers: tt.ledgers},
+				changeRepo:  &mockExpenseChangeRepo{},
+			}
+			got, err := s.settledPairConfirmers(context.Background(), existing, edited, splits, tt.editor)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("confirmers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeChange(t *testing.T) {
+	s := &expenseService{}
+	change := &models.PendingExpenseChange{
+		Payload:       []byte(`{"expense": {"description": "Dinner", "tags": ["food"]}, "splits": [{"user_id": "A", "amount": 10}], "replace_tags": true}`),
+		Confirmations: []models.ExpenseChangeConfirmation{{UserID: "A"}},
+	}
+	if err := s.describeChange(change, map[string]string{"A": "Asha"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if change.Proposed.Description != "Dinner" || len(change.Proposed.Splits) != 1 || change.Proposed.Tags[0] != "food" {
+		t.Errorf("unexpected proposed expense %+v", change.Proposed)
+	}
+	if change.Confirmations[0].Name != "Asha" {
+		t.Errorf("confirmation name = %q, want Asha", change.Confirmations[0].Name)
+	}
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"unwise-backend/database"
@@ -20,19 +23,54 @@ type ExpenseService interface {
 	Create(ctx context.Context, userID string, expense *models.Expense, splits []models.ExpenseSplit) (*models.Expense, error)
 	Update(ctx context.Context, expenseID, userID string, expense *models.Expense, splits []models.ExpenseSplit) (*models.Expense, error)
 	Delete(ctx context.Context, expenseID, userID string) error
+	CreateRefund(ctx context.Context, userID, originalExpenseID string, refund *models.Expense, splits []models.ExpenseSplit) (*models.Expense, error)
+	RequestExclusion(ctx context.Context, expenseID, userID, reason string) (*models.Expense, error)
+	ResolveExclusion(ctx context.Context, expenseID, userID, participantID string, accept bool) (*models.Expense, error)
+	SetSplitLock(ctx context.Context, expenseID, userID string, locked bool) (*models.Expense, error)
+	SettleExpense(ctx context.Context, expenseID, userID string) ([]models.Expense, error)
+	GetPendingChanges(ctx context.Context, userID string) ([]models.PendingExpenseChange, error)
+	RespondToChange(ctx context.Context, changeID, userID string, accept bool) (*models.PendingExpenseChange, error)
 }
 
 type expenseService struct {
-	expenseRepo repository.ExpenseRepository
-	groupRepo   repository.GroupRepository
-	db          *database.DB
+	expenseRepo         repository.ExpenseRepository
+	groupRepo           repository.GroupRepository
+	tagRepo             repository.TagRepository
+	eventRepo           repository.EventRepository
+	readRepo            repository.ReadRepository
+	activityRepo        repository.ActivityRepository
+	splitPreferenceRepo repository.SplitPreferenceRepository
+	balanceEventRepo    repository.BalanceEventRepository
+	changeRepo          repository.ExpenseChangeRepository
+	notificationService NotificationService
+	balanceAlertService BalanceAlertService
+	quotaService        QuotaService
+	emailVerifier       EmailVerifier
+	db                  *database.DB
+	admins              map[string]bool
 }
 
-func NewExpenseService(expenseRepo repository.ExpenseRepository, groupRepo repository.GroupRepository, db *database.DB) ExpenseService {
+func NewExpenseService(expenseRepo repository.ExpenseRepository, groupRepo repository.GroupRepository, tagRepo repository.TagRepository, eventRepo repository.EventRepository, readRepo repository.ReadRepository, activityRepo repository.ActivityRepository, splitPreferenceRepo repository.SplitPreferenceRepository, balanceEventRepo repository.BalanceEventRepository, changeRepo repository.ExpenseChangeRepository, notificationService NotificationService, balanceAlertService BalanceAlertService, quotaService QuotaService, emailVerifier EmailVerifier, db *database.DB, adminUserIDs []string) ExpenseService {
+	admins := make(map[string]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = true
+	}
 	return &expenseService{
-		expenseRepo: expenseRepo,
-		groupRepo:   groupRepo,
-		db:          db,
+		expenseRepo:         expenseRepo,
+		groupRepo:           groupRepo,
+		tagRepo:             tagRepo,
+		eventRepo:           eventRepo,
+		readRepo:            readRepo,
+		activityRepo:        activityRepo,
+		splitPreferenceRepo: splitPreferenceRepo,
+		balanceEventRepo:    balanceEventRepo,
+		changeRepo:          changeRepo,
+		notificationService: notificationService,
+		balanceAlertService: balanceAlertService,
+		quotaService:        quotaService,
+		emailVerifier:       emailVerifier,
+		db:                  db,
+		admins:              admins,
 	}
 }
 
@@ -52,9 +90,34 @@ func (s *expenseService) GetByID(ctx context.Context, expenseID, userID string)
 		return nil, err
 	}
 
+	expense, err = s.withTags(ctx, expense)
+	if err != nil {
+		return nil, err
+	}
+	applyReceiptItemShares(expense.ReceiptItems)
+	expense.Reconciliation = reconcileReceipt(expense)
+
+	visibility, err := s.readRepo.GetVisibility(ctx, expenseID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting expense visibility", err)
+	}
+	applySplitVisibility(expense.Splits, visibility)
 	return expense, nil
 }
 
+// applySplitVisibility sets when each split's member was first notified of
+// the expense and first saw it, for disputes over when it was added.
+func applySplitVisibility(splits []models.ExpenseSplit, visibility map[string]models.ExpenseVisibility) {
+	for i := range splits {
+		v, ok := visibility[splits[i].UserID]
+		if !ok {
+			continue
+		}
+		splits[i].FirstNotifiedAt = v.FirstNotifiedAt
+		splits[i].FirstSeenAt = v.FirstSeenAt
+	}
+}
+
 func (s *expenseService) GetByGroupID(ctx context.Context, groupID, userID string) ([]models.Expense, error) {
 	zap.L().Debug("Getting expenses by group ID", zap.String("group_id", groupID), zap.String("user_id", userID))
 	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
@@ -70,15 +133,78 @@ func (s *expenseService) GetByGroupID(ctx context.Context, groupID, userID strin
 	if expenses == nil {
 		expenses = []models.Expense{}
 	}
+
+	expenseIDs := make([]string, len(expenses))
+	for i := range expenses {
+		expenseIDs[i] = expenses[i].ID
+	}
+	tagsByExpense, err := s.tagRepo.GetTagNamesByExpenseIDs(ctx, expenseIDs)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting expense tags", err)
+	}
+	for i := range expenses {
+		expenses[i].Tags = tagsByExpense[expenses[i].ID]
+		applyReceiptItemShares(expenses[i].ReceiptItems)
+		expenses[i].Reconciliation = reconcileReceipt(&expenses[i])
+	}
 	return expenses, nil
 }
 
+func (s *expenseService) withTags(ctx context.Context, expense *models.Expense) (*models.Expense, error) {
+	tagsByExpense, err := s.tagRepo.GetTagNamesByExpenseIDs(ctx, []string{expense.ID})
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting expense tags", err)
+	}
+	expense.Tags = tagsByExpense[expense.ID]
+	return expense, nil
+}
+
+// checkEvent validates expense.EventID. An empty ID detaches the expense
+// from its event and is cleared to nil.
+func (s *expenseService) checkEvent(ctx context.Context, expense *models.Expense) error {
+	if expense.EventID == nil {
+		return nil
+	}
+	if *expense.EventID == "" {
+		expense.EventID = nil
+		return nil
+	}
+	event, err := s.eventRepo.GetByID(ctx, *expense.EventID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.NotFound("Event")
+		}
+		return apperrors.DatabaseError("getting event", err)
+	}
+	if event.GroupID != expense.GroupID {
+		return apperrors.NotFound("Event")
+	}
+	return nil
+}
+
+func (s *expenseService) saveTags(ctx context.Context, q database.Querier, groupID, expenseID string, names []string) error {
+	txTagRepo := s.tagRepo.WithTx(q)
+	tags, err := txTagRepo.EnsureTags(ctx, groupID, names)
+	if err != nil {
+		return apperrors.DatabaseError("creating tags", err)
+	}
+	tagIDs := make([]string, len(tags))
+	for i, t := range tags {
+		tagIDs[i] = t.ID
+	}
+	if err := txTagRepo.SetExpenseTags(ctx, expenseID, tagIDs); err != nil {
+		return apperrors.DatabaseError("linking expense tags", err)
+	}
+	return nil
+}
+
 func (s *expenseService) Create(ctx context.Context, userID string, expense *models.Expense, splits []models.ExpenseSplit) (*models.Expense, error) {
 	if err := RequireGroupMembership(ctx, s.groupRepo, expense.GroupID, userID); err != nil {
 		return nil, err
 	}
 
 	expense.ID = uuid.New().String()
+	expense.CreatedByUserID = &userID
 
 	if expense.DateISO.IsZero() {
 		expense.DateISO = time.Now()
@@ -89,6 +215,11 @@ func (s *expenseService) Create(ctx context.Context, userID string, expense *mod
 	if expense.Category == "" {
 		expense.Category = models.TransactionCategoryExpense
 	}
+	if expense.Category == models.TransactionCategoryExpense {
+		if err := s.quotaService.CheckExpenses(ctx, userID, expense.GroupID, 1); err != nil {
+			return nil, err
+		}
+	}
 
 	if expense.Type == "" {
 		expense.Type = models.ExpenseTypeEqual
@@ -119,59 +250,381 @@ func (s *expenseService) Create(ctx context.Context, userID string, expense *mod
 		}
 	}
 
-	if err := s.validateExpenseAmounts(expense, splits); err != nil {
+	if len(expense.ParticipantIDs) > 0 {
+		if len(splits) > 0 {
+			return nil, apperrors.InvalidRequest("Send either splits or participant_ids, not both.")
+		}
+		if expense.Type != models.ExpenseTypeEqual {
+			return nil, apperrors.InvalidRequest("participant_ids can only be used with split_method EQUAL.")
+		}
+		equal, err := s.participantSplits(ctx, expense)
+		if err != nil {
+			return nil, err
+		}
+		splits = equal
+	}
+
+	if len(splits) == 0 && expense.Category == models.TransactionCategoryExpense {
+		preferred, err := s.preferredSplits(ctx, expense)
+		if err != nil {
+			return nil, err
+		}
+		splits = preferred
+	}
+
+	if err := prepareReceiptItems(expense.ReceiptItems); err != nil {
+		return nil, err
+	}
+	if err := applyTaxPreset(groupTaxPreset(ctx, s.groupRepo, expense.GroupID), expense); err != nil {
+		return nil, err
+	}
+	if err := s.checkExpenseAmounts(ctx, expense.GroupID, expense, splits); err != nil {
 		return nil, err
 	}
 
-	err := s.db.WithTx(ctx, func(q database.Querier) error {
-		txRepo := s.expenseRepo.WithTx(q)
-		if err := txRepo.Create(ctx, expense); err != nil {
-			return apperrors.DatabaseError("creating expense", err)
+	tags, err := normalizeTagNames(expense.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEvent(ctx, expense); err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		limitActivity, err := s.checkExpenseLimits(ctx, q, userID, expense, nil)
+		if err != nil {
+			return err
 		}
+		if err := s.insertExpense(ctx, q, expense, splits, tags); err != nil {
+			return err
+		}
+		return s.recordLimitActivity(ctx, q, limitActivity)
+	})
 
-		for i := range expense.Payers {
-			expense.Payers[i].ID = uuid.New().String()
-			expense.Payers[i].ExpenseID = expense.ID
-			if err := txRepo.CreatePayer(ctx, &expense.Payers[i]); err != nil {
-				return apperrors.DatabaseError("creating expense payer", err)
-			}
+	if err != nil {
+		zap.L().Error("Failed to create expense transactionally", zap.String("group_id", expense.GroupID), zap.Error(err))
+		return nil, err
+	}
+
+	zap.L().Info("Expense created successfully", zap.String("expense_id", expense.ID), zap.String("group_id", expense.GroupID), zap.Float64("amount", expense.TotalAmount))
+
+	markSeenByActor(ctx, s.readRepo, expense.GroupID, userID, expense.ID)
+	checkBalanceAlertsAsync(s.balanceAlertService, expense.GroupID)
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:     models.NotificationEventNewExpense,
+		GroupID:   expense.GroupID,
+		ExpenseID: expense.ID,
+		ActorID:   userID,
+		Template:  notifyNewExpense,
+		Args:      []interface{}{expense.Description, expense.TotalAmount, expense.Currency},
+	})
+	return s.GetByID(ctx, expense.ID, userID)
+}
+
+// participantSplits splits the expense equally between ParticipantIDs, who
+// must all be in the group.
+func (s *expenseService) participantSplits(ctx context.Context, expense *models.Expense) ([]models.ExpenseSplit, error) {
+	members, err := s.groupRepo.GetMembers(ctx, expense.GroupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group members", err)
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, m := range members {
+		isMember[m.ID] = true
+	}
+	for _, id := range expense.ParticipantIDs {
+		if !isMember[id] {
+			return nil, apperrors.InvalidRequest("Every participant must be a member of the group.")
 		}
+	}
+	return equalSplits(expense.TotalAmount, expense.ParticipantIDs), nil
+}
 
-		for i := range splits {
-			splits[i].ID = uuid.New().String()
-			splits[i].ExpenseID = expense.ID
-			if err := txRepo.CreateSplit(ctx, &splits[i]); err != nil {
-				return apperrors.DatabaseError("creating expense split", err)
-			}
+// equalSplits divides total into equal shares in whole cents. Leftover cents
+// go one each to the participants with the lowest user IDs, so the same
+// participants always get the same amounts whatever order they were sent in.
+func equalSplits(total float64, userIDs []string) []models.ExpenseSplit {
+	ids := append([]string(nil), userIDs...)
+	sort.Strings(ids)
+	unique := ids[:0]
+	for i, id := range ids {
+		if i == 0 || id != ids[i-1] {
+			unique = append(unique, id)
 		}
+	}
+	if len(unique) == 0 {
+		return nil
+	}
 
-		for i := range expense.ReceiptItems {
-			expense.ReceiptItems[i].ID = uuid.New().String()
-			expense.ReceiptItems[i].ExpenseID = expense.ID
-			if err := txRepo.CreateReceiptItem(ctx, &expense.ReceiptItems[i]); err != nil {
-				return apperrors.DatabaseError("creating receipt item", err)
+	cents := int64(math.Round(total * RoundingFactor))
+	base, remainder := cents/int64(len(unique)), cents%int64(len(unique))
+	splits := make([]models.ExpenseSplit, len(unique))
+	for i, id := range unique {
+		share := base
+		if int64(i) < remainder {
+			share++
+		}
+		splits[i] = models.ExpenseSplit{UserID: id, Amount: float64(share) / RoundingFactor}
+	}
+	return splits
+}
+
+func (s *expenseService) preferredSplits(ctx context.Context, expense *models.Expense) ([]models.ExpenseSplit, error) {
+	members, err := s.groupRepo.GetMembers(ctx, expense.GroupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group members", err)
+	}
+	if len(members) != 2 {
+		return nil, apperrors.MissingRequiredField("Splits")
+	}
+
+	userAID, userBID := canonicalPair(members[0].ID, members[1].ID)
+	preference, err := s.splitPreferenceRepo.Find(ctx, userAID, userBID, expense.GroupID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.MissingRequiredField("Splits")
+		}
+		return nil, apperrors.DatabaseError("getting split preference", err)
+	}
+
+	zap.L().Info("Applying saved split preference",
+		zap.String("group_id", expense.GroupID),
+		zap.String("preference_id", preference.ID))
+	expense.Type = models.ExpenseTypePercentage
+	return deriveRatioSplits(expense.TotalAmount, userAID, preference.UserAPercentage, userBID), nil
+}
+
+func (s *expenseService) checkExpenseLimits(ctx context.Context, q database.Querier, userID string, expense *models.Expense, existing *models.Expense) (*models.GroupActivity, error) {
+	return checkExpenseLimitsWith(ctx, s.groupRepo, s.expenseRepo, q, userID, expense, existing)
+}
+
+// checkExpenseLimitsWith checks expense against its group's limits. New
+// expenses are counted against the daily limit inside q, the transaction that
+// will insert them; edits never are, so q may be nil for them.
+func checkExpenseLimitsWith(ctx context.Context, groupRepo repository.GroupRepository, expenseRepo repository.ExpenseRepository, q database.Querier, userID string, expense *models.Expense, existing *models.Expense) (*models.GroupActivity, error) {
+	if expense.Category != models.TransactionCategoryExpense {
+		return nil, nil
+	}
+
+	limits, err := groupRepo.GetLimits(ctx, expense.GroupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group limits", err)
+	}
+
+	currency := expense.Currency
+	if currency == "" && existing != nil {
+		currency = existing.Currency
+	}
+
+	var violations []string
+	amountChanged := existing == nil || math.Abs(existing.TotalAmount-expense.TotalAmount) > AmountTolerance || currency != existing.Currency
+	if limits.MaxExpenseAmount != nil {
+		var violation string
+		switch {
+		case currency != limits.Currency:
+			// There are no exchange rates to convert with, so an amount in
+			// another currency is treated as over the limit.
+			violation = fmt.Sprintf("amount %.2f %s cannot be checked against the group limit of %.2f %s without an exchange rate", expense.TotalAmount, currency, *limits.MaxExpenseAmount, limits.Currency)
+		case expense.TotalAmount > *limits.MaxExpenseAmount+AmountTolerance:
+			violation = fmt.Sprintf("amount %.2f %s is above the group limit of %.2f", expense.TotalAmount, currency, *limits.MaxExpenseAmount)
+		}
+		if violation != "" {
+			if !amountChanged {
+				expense.LimitFlagged = existing.LimitFlagged
+				return nil, nil
 			}
-			for j := range expense.ReceiptItems[i].Assignments {
-				expense.ReceiptItems[i].Assignments[j].ID = uuid.New().String()
-				expense.ReceiptItems[i].Assignments[j].ReceiptItemID = expense.ReceiptItems[i].ID
-				if err := txRepo.CreateReceiptItemAssignment(ctx, &expense.ReceiptItems[i].Assignments[j]); err != nil {
-					return apperrors.DatabaseError("creating receipt item assignment", err)
-				}
+			violations = append(violations, violation)
+		}
+	}
+	if existing == nil && limits.MaxDailyExpenses != nil {
+		// Concurrent creates would all count the same expenses, so they take
+		// the group lock exclusively until their insert commits.
+		if err := groupRepo.WithTx(q).LockForUpdate(ctx, expense.GroupID); err != nil {
+			if apperrors.IsNotFoundError(err) {
+				return nil, apperrors.GroupNotFound()
 			}
+			return nil, apperrors.DatabaseError("locking group", err)
 		}
+		startOfDay := time.Now().UTC().Truncate(24 * time.Hour)
+		count, err := expenseRepo.WithTx(q).CountGroupExpensesSince(ctx, expense.GroupID, startOfDay)
+		if err != nil {
+			return nil, apperrors.DatabaseError("counting today's expenses", err)
+		}
+		if count >= *limits.MaxDailyExpenses {
+			violations = append(violations, fmt.Sprintf("the group already has %d expenses today (limit %d)", count, *limits.MaxDailyExpenses))
+		}
+	}
+
+	expense.LimitFlagged = false
+	if len(violations) == 0 {
+		return nil, nil
+	}
+
+	details := strings.Join(violations, "; ")
+	activity := &models.GroupActivity{
+		ID:        uuid.New().String(),
+		GroupID:   expense.GroupID,
+		ActorID:   &userID,
+		ExpenseID: &expense.ID,
+	}
+	switch {
+	case expense.ConfirmOverLimit:
+		activity.Action = models.GroupActivityLimitOverride
+		activity.Message = fmt.Sprintf("Limit overridden for '%s': %s", expense.Description, details)
+	case limits.Action == models.GroupLimitActionFlag:
+		expense.LimitFlagged = true
+		activity.Action = models.GroupActivityLimitFlagged
+		activity.Message = fmt.Sprintf("'%s' flagged for review: %s", expense.Description, details)
+	default:
+		return nil, apperrors.ExpenseLimitExceeded(details)
+	}
+
+	zap.L().Info("Expense above group limits",
+		zap.String("group_id", expense.GroupID),
+		zap.String("expense_id", expense.ID),
+		zap.String("action", string(activity.Action)),
+		zap.String("details", details))
+	return activity, nil
+}
+
+func (s *expenseService) recordLimitActivity(ctx context.Context, q database.Querier, activity *models.GroupActivity) error {
+	return recordLimitActivityWith(ctx, s.activityRepo, q, activity)
+}
+
+func recordLimitActivityWith(ctx context.Context, activityRepo repository.ActivityRepository, q database.Querier, activity *models.GroupActivity) error {
+	if activity == nil || activityRepo == nil {
 		return nil
-	})
+	}
+	if err := activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+		return apperrors.DatabaseError("recording group activity", err)
+	}
+	return nil
+}
 
-	if err != nil {
-		zap.L().Error("Failed to create expense transactionally", zap.String("group_id", expense.GroupID), zap.Error(err))
-		return nil, err
+func (s *expenseService) insertExpense(ctx context.Context, q database.Querier, expense *models.Expense, splits []models.ExpenseSplit, tags []string) error {
+	if err := lockGroupForWrite(ctx, s.groupRepo, q, expense.GroupID, expenseParticipants(expense, splits)...); err != nil {
+		return err
 	}
 
-	zap.L().Info("Expense created successfully", zap.String("expense_id", expense.ID), zap.String("group_id", expense.GroupID), zap.Float64("amount", expense.TotalAmount))
-	return s.expenseRepo.GetByID(ctx, expense.ID)
+	txRepo := s.expenseRepo.WithTx(q)
+	if err := txRepo.Create(ctx, expense); err != nil {
+		return expenseWriteError("creating expense", err)
+	}
+
+	if len(tags) > 0 {
+		if err := s.saveTags(ctx, q, expense.GroupID, expense.ID, tags); err != nil {
+			return err
+		}
+	}
+
+	for i := range expense.Payers {
+		expense.Payers[i].ID = uuid.New().String()
+		expense.Payers[i].ExpenseID = expense.ID
+		if err := txRepo.CreatePayer(ctx, &expense.Payers[i]); err != nil {
+			return expenseWriteError("creating expense payer", err)
+		}
+	}
+
+	for i := range splits {
+		splits[i].ID = uuid.New().String()
+		splits[i].ExpenseID = expense.ID
+		if err := txRepo.CreateSplit(ctx, &splits[i]); err != nil {
+			return expenseWriteError("creating expense split", err)
+		}
+	}
+
+	if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, expense.ID, nil); err != nil {
+		return err
+	}
+
+	for i := range expense.ReceiptItems {
+		expense.ReceiptItems[i].ID = uuid.New().String()
+		expense.ReceiptItems[i].ExpenseID = expense.ID
+		if err := txRepo.CreateReceiptItem(ctx, &expense.ReceiptItems[i]); err != nil {
+			return apperrors.DatabaseError("creating receipt item", err)
+		}
+		for j := range expense.ReceiptItems[i].Assignments {
+			expense.ReceiptItems[i].Assignments[j].ID = uuid.New().String()
+			expense.ReceiptItems[i].Assignments[j].ReceiptItemID = expense.ReceiptItems[i].ID
+			if err := txRepo.CreateReceiptItemAssignment(ctx, &expense.ReceiptItems[i].Assignments[j]); err != nil {
+				return apperrors.DatabaseError("creating receipt item assignment", err)
+			}
+		}
+	}
+	return nil
 }
 
+// lockGroupForWrite locks the group for the rest of the transaction and
+// re-checks that userIDs still belong to it. The membership checks before
+// the transaction can race with the group being deleted or a member removed;
+// both take the group lock first, so once it is held the answer here stays
+// true until commit. Every transaction that writes expense rows takes it.
+func lockGroupForWrite(ctx context.Context, groupRepo repository.GroupRepository, q database.Querier, groupID string, userIDs ...string) error {
+	txGroupRepo := groupRepo.WithTx(q)
+	if err := txGroupRepo.LockForShare(ctx, groupID); err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.GroupNotFound()
+		}
+		return apperrors.DatabaseError("locking group", err)
+	}
+
+	checked := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if checked[id] {
+			continue
+		}
+		checked[id] = true
+		isMember, err := txGroupRepo.IsMember(ctx, groupID, id)
+		if err != nil {
+			return apperrors.DatabaseError("checking membership", err)
+		}
+		if !isMember {
+			return apperrors.Wrap(fmt.Errorf("user %s left the group", id), apperrors.NotGroupMember())
+		}
+	}
+	return nil
+}
+
+func expenseParticipants(expense *models.Expense, splits []models.ExpenseSplit) []string {
+	userIDs := make([]string, 0, len(expense.Payers)+len(splits))
+	for _, p := range expense.Payers {
+		userIDs = append(userIDs, p.UserID)
+	}
+	for _, sp := range splits {
+		userIDs = append(userIDs, sp.UserID)
+	}
+	return userIDs
+}
+
+// expenseWriteError maps a foreign key violation while writing an expense,
+// left by a group or user deleted mid-request, to the error the client would
+// have got a moment later instead of a database error.
+func expenseWriteError(operation string, err error) error {
+	if !apperrors.IsForeignKeyError(err) {
+		return apperrors.DatabaseError(operation, err)
+	}
+	if strings.Contains(err.Error(), "group_id") {
+		return apperrors.Wrap(err, apperrors.GroupNotFound())
+	}
+	return apperrors.Wrap(err, apperrors.NotGroupMember())
+}
+
+// Update returns the expense unchanged, with a PendingChange, when the edit
+// needs settled members to confirm it.
 func (s *expenseService) Update(ctx context.Context, expenseID, userID string, expense *models.Expense, splits []models.ExpenseSplit) (*models.Expense, error) {
+	change, err := s.update(ctx, expenseID, userID, expense, splits, nil)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.GetByID(ctx, expenseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	updated.PendingChange = change
+	return updated, nil
+}
+
+func (s *expenseService) update(ctx context.Context, expenseID, userID string, expense *models.Expense, splits []models.ExpenseSplit, approved *models.PendingExpenseChange) (*models.PendingExpenseChange, error) {
 	zap.L().Info("Updating expense", zap.String("expense_id", expenseID), zap.String("user_id", userID))
 	existingExpense, err := s.expenseRepo.GetByID(ctx, expenseID)
 	if err != nil {
@@ -185,6 +638,24 @@ func (s *expenseService) Update(ctx context.Context, expenseID, userID string, e
 	if err := RequireGroupMembership(ctx, s.groupRepo, existingExpense.GroupID, userID); err != nil {
 		return nil, err
 	}
+	if err := s.requireEditRights(ctx, existingExpense, userID); err != nil {
+		return nil, err
+	}
+	if existingExpense.Category == models.TransactionCategoryRefund {
+		return nil, apperrors.InvalidRequest("Refunds cannot be edited. Delete the refund and record a new one.")
+	}
+	if existingExpense.SettlementStatus == models.SettlementStatusReversed || existingExpense.SettlementStatus == models.SettlementStatusReversal {
+		return nil, apperrors.SettlementReversalLocked()
+	}
+
+	refunded, err := s.expenseRepo.GetRefundedAmount(ctx, expenseID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting refunded amount", err)
+	}
+	if refunded > 0 && expense.TotalAmount+AmountTolerance < refunded {
+		return nil, apperrors.InvalidAmount(fmt.Sprintf("Total amount cannot be less than the %.2f already refunded.", refunded))
+	}
+
 	expense.ID = expenseID
 	expense.GroupID = existingExpense.GroupID
 	if expense.Category == "" {
@@ -201,12 +672,15 @@ func (s *expenseService) Update(ctx context.Context, expenseID, userID string, e
 		expense.Time = existingExpense.Time
 	}
 
-	if expense.ReceiptImageURL == nil {
-		expense.ReceiptImageURL = existingExpense.ReceiptImageURL
+	if expense.ReceiptImagePath == nil {
+		expense.ReceiptImagePath = existingExpense.ReceiptImagePath
 	}
 	if len(expense.ReceiptItems) == 0 {
 		expense.ReceiptItems = existingExpense.ReceiptItems
 	}
+	if err := prepareReceiptItems(expense.ReceiptItems); err != nil {
+		return nil, err
+	}
 
 	if len(expense.Payers) == 0 {
 		if expense.PaidByUserID == nil && existingExpense.PaidByUserID != nil {
@@ -224,16 +698,73 @@ func (s *expenseService) Update(ctx context.Context, expenseID, userID string, e
 		}
 	}
 
-	if err := s.validateExpenseAmounts(expense, splits); err != nil {
+	if err := applyTaxPreset(groupTaxPreset(ctx, s.groupRepo, existingExpense.GroupID), expense); err != nil {
+		return nil, err
+	}
+	if err := s.checkExpenseAmounts(ctx, existingExpense.GroupID, expense, splits); err != nil {
+		return nil, err
+	}
+	if existingExpense.LockedSplits && splitsChanged(existingExpense.Splits, splits) {
+		return nil, apperrors.ExpenseSplitsLocked()
+	}
+
+	var tags []string
+	if expense.Tags != nil {
+		tags, err = normalizeTagNames(expense.Tags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if expense.EventID == nil {
+		expense.EventID = existingExpense.EventID
+	}
+	if err := s.checkEvent(ctx, expense); err != nil {
 		return nil, err
 	}
 
+	limitActivity, err := s.checkExpenseLimits(ctx, nil, userID, expense, existingExpense)
+	if err != nil {
+		return nil, err
+	}
+
+	if approved == nil {
+		confirmers, err := s.settledPairConfirmers(ctx, existingExpense, expense, splits, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(confirmers) > 0 {
+			return s.proposeChange(ctx, existingExpense, expense, splits, userID, confirmers)
+		}
+	}
+
 	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := lockGroupForWrite(ctx, s.groupRepo, q, expense.GroupID, expenseParticipants(expense, splits)...); err != nil {
+			return err
+		}
 		txRepo := s.expenseRepo.WithTx(q)
 
+		if err := s.closePendingChanges(ctx, q, existingExpense, approved); err != nil {
+			return err
+		}
+
+		before, err := snapshotBalanceContributions(ctx, s.balanceEventRepo, q, expenseID)
+		if err != nil {
+			return err
+		}
+
 		if err := txRepo.Update(ctx, expense); err != nil {
 			return apperrors.DatabaseError("updating expense", err)
 		}
+		if err := s.recordLimitActivity(ctx, q, limitActivity); err != nil {
+			return err
+		}
+
+		if expense.Tags != nil {
+			if err := s.saveTags(ctx, q, expense.GroupID, expenseID, tags); err != nil {
+				return err
+			}
+		}
 
 		if err := txRepo.DeletePayers(ctx, expenseID); err != nil {
 			return apperrors.DatabaseError("deleting existing payers", err)
@@ -243,7 +774,7 @@ func (s *expenseService) Update(ctx context.Context, expenseID, userID string, e
 			expense.Payers[i].ID = uuid.New().String()
 			expense.Payers[i].ExpenseID = expenseID
 			if err := txRepo.CreatePayer(ctx, &expense.Payers[i]); err != nil {
-				return apperrors.DatabaseError("creating expense payer", err)
+				return expenseWriteError("creating expense payer", err)
 			}
 		}
 
@@ -255,10 +786,14 @@ func (s *expenseService) Update(ctx context.Context, expenseID, userID string, e
 			splits[i].ID = uuid.New().String()
 			splits[i].ExpenseID = expenseID
 			if err := txRepo.CreateSplit(ctx, &splits[i]); err != nil {
-				return apperrors.DatabaseError("creating expense split", err)
+				return expenseWriteError("creating expense split", err)
 			}
 		}
 
+		if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionUpdated, expenseID, before); err != nil {
+			return err
+		}
+
 		if err := txRepo.DeleteReceiptItems(ctx, expenseID); err != nil {
 			return apperrors.DatabaseError("deleting existing receipt items", err)
 		}
@@ -286,10 +821,167 @@ func (s *expenseService) Update(ctx context.Context, expenseID, userID string, e
 	}
 
 	zap.L().Info("Expense updated successfully", zap.String("expense_id", expenseID), zap.Float64("new_amount", expense.TotalAmount))
-	return s.expenseRepo.GetByID(ctx, expenseID)
+	checkBalanceAlertsAsync(s.balanceAlertService, existingExpense.GroupID)
+	return nil, nil
+}
+
+// reconcileReceipt compares itemized receipt lines plus taxes and service
+// charge against the expense total. Items that already sum to the total are
+// treated as tax-inclusive prices. Returns nil for expenses without items.
+func reconcileReceipt(expense *models.Expense) *models.ReceiptReconciliation {
+	if len(expense.ReceiptItems) == 0 {
+		return nil
+	}
+
+	itemsTotal := 0.0
+	for _, item := range expense.ReceiptItems {
+		itemsTotal += item.Price
+	}
+	taxes := expense.Tax
+	if taxes == 0 {
+		taxes = expense.CGST + expense.SGST
+	}
+
+	itemsTotal = math.Round(itemsTotal*RoundingFactor) / RoundingFactor
+	charges := math.Round((taxes+expense.ServiceCharge)*RoundingFactor) / RoundingFactor
+	total := math.Round(expense.TotalAmount*RoundingFactor) / RoundingFactor
+
+	result := &models.ReceiptReconciliation{
+		Status:       models.ReconciliationMatched,
+		ItemsTotal:   itemsTotal,
+		ChargesTotal: charges,
+		ReceiptTotal: math.Round((itemsTotal+charges)*RoundingFactor) / RoundingFactor,
+	}
+	if math.Abs(itemsTotal-total) <= BalanceThreshold {
+		result.ReceiptTotal = itemsTotal
+		return result
+	}
+
+	result.Delta = math.Round((result.ReceiptTotal-total)*RoundingFactor) / RoundingFactor
+	switch {
+	case result.Delta > BalanceThreshold:
+		result.Status = models.ReconciliationOver
+	case result.Delta < -BalanceThreshold:
+		result.Status = models.ReconciliationUnder
+	default:
+		result.Delta = 0
+	}
+	return result
+}
+
+// prepareReceiptItems validates item quantities and assignee portions before
+// they are stored. Quantity defaults to one unit; when no assignee has a
+// portion the quantity is shared equally between them.
+func prepareReceiptItems(items []models.ReceiptItem) error {
+	for i := range items {
+		item := &items[i]
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+		if item.Quantity < 0 {
+			return apperrors.InvalidRequest(fmt.Sprintf("Quantity for '%s' must be greater than zero.", item.Name))
+		}
+		if len(item.Assignments) == 0 {
+			continue
+		}
+
+		withPortion, portions := 0, 0.0
+		for _, a := range item.Assignments {
+			if a.Portion < 0 {
+				return apperrors.InvalidRequest(fmt.Sprintf("Portions for '%s' must be greater than zero.", item.Name))
+			}
+			if a.Portion > 0 {
+				withPortion++
+				portions += a.Portion
+			}
+		}
+
+		switch withPortion {
+		case 0:
+			share := math.Round(item.Quantity/float64(len(item.Assignments))*PortionRoundingFactor) / PortionRoundingFactor
+			for j := range item.Assignments {
+				item.Assignments[j].Portion = share
+			}
+		case len(item.Assignments):
+			// Stored portions are rounded, so allow that rounding per assignee.
+			if math.Abs(portions-item.Quantity) > PortionTolerance*float64(len(item.Assignments)) {
+				return apperrors.InvalidRequestWithDetails(
+					fmt.Sprintf("Portions for '%s' add up to %g but its quantity is %g.", item.Name, portions, item.Quantity),
+					"Every unit of an item must be assigned to someone.",
+				)
+			}
+		default:
+			return apperrors.InvalidRequest(fmt.Sprintf("Give a portion for every person sharing '%s', or for none of them.", item.Name))
+		}
+	}
+	return nil
+}
+
+// applyReceiptItemShares works out what each assignee owes for every item,
+// weighted by their portion. Amounts are allocated in whole cents with the
+// leftover cents going to the largest remainders, so each item's amounts add
+// up to its price exactly. Assignments without a portion share equally.
+func applyReceiptItemShares(items []models.ReceiptItem) {
+	for i := range items {
+		item := &items[i]
+		quantity := item.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+		item.UnitPrice = math.Round(item.Price/quantity*RoundingFactor) / RoundingFactor
+		if len(item.Assignments) == 0 {
+			continue
+		}
+
+		weights := make([]float64, len(item.Assignments))
+		total := 0.0
+		for j, a := range item.Assignments {
+			weights[j] = a.Portion
+			if weights[j] <= 0 {
+				weights[j] = 1
+			}
+			total += weights[j]
+		}
+
+		cents := int64(math.Round(math.Abs(item.Price) * RoundingFactor))
+		allocated := make([]int64, len(weights))
+		remainders := make([]float64, len(weights))
+		left := cents
+		for j, w := range weights {
+			exact := float64(cents) * w / total
+			allocated[j] = int64(math.Floor(exact))
+			remainders[j] = exact - float64(allocated[j])
+			left -= allocated[j]
+		}
+		order := make([]int, len(weights))
+		for j := range order {
+			order[j] = j
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return remainders[order[a]] > remainders[order[b]]
+		})
+		for k := 0; left > 0; k++ {
+			allocated[order[k%len(order)]]++
+			left--
+		}
+
+		sign := 1.0
+		if item.Price < 0 {
+			sign = -1
+		}
+		for j := range item.Assignments {
+			item.Assignments[j].Amount = sign * float64(allocated[j]) / RoundingFactor
+		}
+	}
 }
 
 func (s *expenseService) validateExpenseAmounts(expense *models.Expense, splits []models.ExpenseSplit) error {
+	return validateExpenseTotals(expense, splits)
+}
+
+// validateExpenseTotals checks that the payers and the splits each add up to
+// the expense total.
+func validateExpenseTotals(expense *models.Expense, splits []models.ExpenseSplit) error {
 	totalPaid := 0.0
 	for _, payer := range expense.Payers {
 		totalPaid += payer.AmountPaid
@@ -301,7 +993,7 @@ func (s *expenseService) validateExpenseAmounts(expense *models.Expense, splits
 		zap.L().Warn("Expense validation failed: amount mismatch (payers)",
 			zap.Float64("total_paid", roundedTotalPaid),
 			zap.Float64("total_amount", roundedTotalAmount))
-		return apperrors.AmountMismatch(roundedTotalPaid, roundedTotalAmount, "payer")
+		return apperrors.AmountMismatch(roundedTotalPaid, roundedTotalAmount, "payer", payerMismatch(expense.Payers, roundedTotalPaid, roundedTotalAmount))
 	}
 
 	totalSplit := 0.0
@@ -314,7 +1006,7 @@ func (s *expenseService) validateExpenseAmounts(expense *models.Expense, splits
 		zap.L().Warn("Expense validation failed: amount mismatch (splits)",
 			zap.Float64("total_split", roundedTotalSplit),
 			zap.Float64("total_amount", roundedTotalAmount))
-		return apperrors.AmountMismatch(roundedTotalSplit, roundedTotalAmount, "split")
+		return apperrors.AmountMismatch(roundedTotalSplit, roundedTotalAmount, "split", splitMismatch(expense, splits, roundedTotalSplit, roundedTotalAmount))
 	}
 
 	return nil
@@ -334,12 +1026,225 @@ func (s *expenseService) Delete(ctx context.Context, expenseID, userID string) e
 	if err := RequireGroupMembership(ctx, s.groupRepo, expense.GroupID, userID); err != nil {
 		return err
 	}
+	if err := s.requireEditRights(ctx, expense, userID); err != nil {
+		return err
+	}
+	if expense.SettlementStatus == models.SettlementStatusReversed || expense.SettlementStatus == models.SettlementStatusReversal {
+		return apperrors.SettlementReversalLocked()
+	}
 
-	if err := s.expenseRepo.Delete(ctx, expenseID); err != nil {
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		before, err := snapshotBalanceContributions(ctx, s.balanceEventRepo, q, expenseID)
+		if err != nil {
+			return err
+		}
+		if err := s.expenseRepo.WithTx(q).Delete(ctx, expenseID); err != nil {
+			return apperrors.DatabaseError("deleting expense", err)
+		}
+		return recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionDeleted, expenseID, before)
+	})
+	if err != nil {
 		zap.L().Error("Failed to delete expense record", zap.String("expense_id", expenseID), zap.Error(err))
-		return apperrors.DatabaseError("deleting expense", err)
+		return err
 	}
 
 	zap.L().Info("Expense deleted successfully", zap.String("expense_id", expenseID))
+	checkBalanceAlertsAsync(s.balanceAlertService, expense.GroupID)
 	return nil
 }
+
+// requireEditRights applies the group's expense edit policy to an update or
+// delete of expense by userID. Platform admins bypass the policy.
+func (s *expenseService) requireEditRights(ctx context.Context, expense *models.Expense, userID string) error {
+	if s.admins[userID] {
+		return nil
+	}
+	policy, err := s.groupRepo.GetExpenseEditPolicy(ctx, expense.GroupID)
+	if err != nil {
+		return apperrors.DatabaseError("getting group expense edit policy", err)
+	}
+	if !canEditExpense(policy, expense, userID) {
+		zap.L().Debug("Expense edit denied by group policy",
+			zap.String("expense_id", expense.ID),
+			zap.String("user_id", userID),
+			zap.String("policy", string(policy)))
+		return apperrors.ExpenseEditNotAllowed(string(policy))
+	}
+	return nil
+}
+
+// canEditExpense reports whether the policy lets userID change expense.
+// Expenses recorded before creators were tracked have no creator, so their
+// payers stand in for it.
+func canEditExpense(policy models.ExpenseEditPolicy, expense *models.Expense, userID string) bool {
+	isPayer := expense.PaidByUserID != nil && *expense.PaidByUserID == userID
+	for _, payer := range expense.Payers {
+		if payer.UserID == userID {
+			isPayer = true
+		}
+	}
+
+	switch policy {
+	case models.ExpenseEditPolicyCreator:
+		if expense.CreatedByUserID == nil {
+			return isPayer
+		}
+		return *expense.CreatedByUserID == userID
+	case models.ExpenseEditPolicyCreatorOrPayer:
+		return isPayer || (expense.CreatedByUserID != nil && *expense.CreatedByUserID == userID)
+	default:
+		return true
+	}
+}
+
+func (s *expenseService) CreateRefund(ctx context.Context, userID, originalExpenseID string, refund *models.Expense, splits []models.ExpenseSplit) (*models.Expense, error) {
+	original, err := s.expenseRepo.GetByID(ctx, originalExpenseID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.ExpenseNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting expense", err)
+	}
+
+	if err := RequireGroupMembership(ctx, s.groupRepo, original.GroupID, userID); err != nil {
+		return nil, err
+	}
+	if original.Category != models.TransactionCategoryExpense {
+		return nil, apperrors.InvalidRequest("Only expenses can be refunded.")
+	}
+
+	amount := math.Round(refund.TotalAmount*RoundingFactor) / RoundingFactor
+	if amount <= 0 {
+		return nil, apperrors.InvalidAmount("Refund amount must be greater than zero.")
+	}
+
+	refund.ID = uuid.New().String()
+	refund.CreatedByUserID = &userID
+	refund.GroupID = original.GroupID
+	refund.Currency = original.Currency
+	refund.Category = models.TransactionCategoryRefund
+	refund.Type = models.ExpenseTypeExactAmount
+	refund.OriginalExpenseID = &original.ID
+	refund.EventID = original.EventID
+	refund.TotalAmount = amount
+	refund.Tax, refund.CGST, refund.SGST, refund.ServiceCharge = 0, 0, 0, 0
+	refund.TaxComponents = nil
+	refund.ReceiptItems = nil
+
+	if refund.Description == "" {
+		refund.Description = "Refund: " + original.Description
+		if len(refund.Description) > MaxDescriptionLength {
+			refund.Description = refund.Description[:MaxDescriptionLength]
+		}
+	}
+
+	if refund.DateISO.IsZero() {
+		refund.DateISO = time.Now()
+		refund.Date = refund.DateISO.Format("2006-01-02")
+		refund.Time = refund.DateISO.Format("15:04")
+	}
+
+	if len(refund.Payers) == 0 {
+		if refund.PaidByUserID != nil {
+			refund.Payers = []models.ExpensePayer{{UserID: *refund.PaidByUserID, AmountPaid: amount}}
+		} else {
+			refund.Payers = proportionalPayers(original.Payers, original.TotalAmount, amount)
+		}
+	}
+	if len(splits) == 0 {
+		splits = proportionalSplits(original.Splits, original.TotalAmount, amount)
+	}
+
+	if err := s.checkExpenseAmounts(ctx, original.GroupID, refund, splits); err != nil {
+		return nil, err
+	}
+
+	if refund.PaidByUserID == nil && len(refund.Payers) > 0 {
+		refund.PaidByUserID = &refund.Payers[0].UserID
+	}
+
+	refund.TotalAmount = -amount
+	for i := range refund.Payers {
+		refund.Payers[i].AmountPaid = -refund.Payers[i].AmountPaid
+	}
+	for i := range splits {
+		splits[i].Amount = -splits[i].Amount
+		splits[i].Percentage = nil
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		txRepo := s.expenseRepo.WithTx(q)
+		if err := txRepo.LockForUpdate(ctx, original.ID); err != nil {
+			if apperrors.IsNotFoundError(err) {
+				return apperrors.ExpenseNotFound()
+			}
+			return apperrors.DatabaseError("locking expense", err)
+		}
+		refunded, err := txRepo.GetRefundedAmount(ctx, original.ID)
+		if err != nil {
+			return apperrors.DatabaseError("getting refunded amount", err)
+		}
+		remaining := math.Round((original.TotalAmount-refunded)*RoundingFactor) / RoundingFactor
+		if amount > remaining+AmountTolerance {
+			return apperrors.InvalidAmount(fmt.Sprintf("Refund of %.2f exceeds the %.2f left to refund on this expense.", amount, remaining))
+		}
+		return s.insertExpense(ctx, q, refund, splits, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("Refund created successfully", zap.String("expense_id", refund.ID), zap.String("original_expense_id", original.ID), zap.Float64("amount", amount))
+
+	markSeenByActor(ctx, s.readRepo, refund.GroupID, userID, refund.ID)
+	checkBalanceAlertsAsync(s.balanceAlertService, refund.GroupID)
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:     models.NotificationEventNewExpense,
+		GroupID:   refund.GroupID,
+		ExpenseID: refund.ID,
+		ActorID:   userID,
+		Template:  notifyRefund,
+		Args:      []interface{}{original.Description, amount, refund.Currency},
+	})
+	return s.GetByID(ctx, refund.ID, userID)
+}
+
+func proportionalPayers(payers []models.ExpensePayer, total, amount float64) []models.ExpensePayer {
+	shares := make([]float64, len(payers))
+	for i, p := range payers {
+		shares[i] = p.AmountPaid
+	}
+	amounts := distributeProportionally(shares, total, amount)
+	result := make([]models.ExpensePayer, len(payers))
+	for i, p := range payers {
+		result[i] = models.ExpensePayer{UserID: p.UserID, AmountPaid: amounts[i]}
+	}
+	return result
+}
+
+func proportionalSplits(splits []models.ExpenseSplit, total, amount float64) []models.ExpenseSplit {
+	shares := make([]float64, len(splits))
+	for i, sp := range splits {
+		shares[i] = sp.Amount
+	}
+	amounts := distributeProportionally(shares, total, amount)
+	result := make([]models.ExpenseSplit, len(splits))
+	for i, sp := range splits {
+		result[i] = models.ExpenseSplit{UserID: sp.UserID, Amount: amounts[i]}
+	}
+	return result
+}
+
+func distributeProportionally(shares []float64, total, amount float64) []float64 {
+	result := make([]float64, len(shares))
+	if len(shares) == 0 || total == 0 {
+		return result
+	}
+	allocated := 0.0
+	for i, share := range shares {
+		result[i] = math.Round(amount*share/total*RoundingFactor) / RoundingFactor
+		allocated += result[i]
+	}
+	result[len(result)-1] = math.Round((result[len(result)-1]+amount-allocated)*RoundingFactor) / RoundingFactor
+	return result
+}
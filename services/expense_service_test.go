@@ -1,8 +1,16 @@
 package services
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
 	"testing"
+	"time"
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
 	"unwise-backend/models"
+	"unwise-backend/repository"
 )
 
 func TestExpenseValidation(t *testing.T) {
@@ -69,3 +77,427 @@ func TestExpenseValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestExpenseLimits(t *testing.T) {
+	maxAmount := 5000.0
+	tests := []struct {
+		name         string
+		action       models.GroupLimitAction
+		expense      *models.Expense
+		shouldError  bool
+		wantActivity models.GroupActivityAction
+		wantFlagged  bool
+	}{
+		{
+			name:    "Within Limit",
+			action:  models.GroupLimitActionReject,
+			expense: &models.Expense{TotalAmount: 1200, Currency: "INR", Category: models.TransactionCategoryExpense},
+		},
+		{
+			name:        "Over Limit Rejected",
+			action:      models.GroupLimitActionReject,
+			expense:     &models.Expense{TotalAmount: 120000, Currency: "INR", Category: models.TransactionCategoryExpense},
+			shouldError: true,
+		},
+		{
+			name:         "Over Limit Confirmed",
+			action:       models.GroupLimitActionReject,
+			expense:      &models.Expense{TotalAmount: 120000, Currency: "INR", Category: models.TransactionCategoryExpense, ConfirmOverLimit: true},
+			wantActivity: models.GroupActivityLimitOverride,
+		},
+		{
+			name:         "Over Limit Flagged",
+			action:       models.GroupLimitActionFlag,
+			expense:      &models.Expense{TotalAmount: 120000, Currency: "INR", Category: models.TransactionCategoryExpense},
+			wantActivity: models.GroupActivityLimitFlagged,
+			wantFlagged:  true,
+		},
+		{
+			name:        "Other Currency Rejected Without Rate",
+			action:      models.GroupLimitActionReject,
+			expense:     &models.Expense{TotalAmount: 12, Currency: "USD", Category: models.TransactionCategoryExpense},
+			shouldError: true,
+		},
+		{
+			name:         "Other Currency Flagged Without Rate",
+			action:       models.GroupLimitActionFlag,
+			expense:      &models.Expense{TotalAmount: 12, Currency: "USD", Category: models.TransactionCategoryExpense},
+			wantActivity: models.GroupActivityLimitFlagged,
+			wantFlagged:  true,
+		},
+		{
+			name:    "Settlements Ignored",
+			action:  models.GroupLimitActionReject,
+			expense: &models.Expense{TotalAmount: 120000, Currency: "INR", Category: models.TransactionCategoryPayment},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &expenseService{
+				expenseRepo: &mockExpenseRepo{},
+				groupRepo: &mockGroupRepo{limits: &models.GroupLimits{
+					MaxExpenseAmount: &maxAmount,
+					Action:           tt.action,
+					Currency:         "INR",
+				}},
+			}
+			activity, err := s.checkExpenseLimits(context.Background(), nil, "A", tt.expense, nil)
+			if (err != nil) != tt.shouldError {
+				t.Fatalf("expected error: %v, got: %v", tt.shouldError, err)
+			}
+			var gotActivity models.GroupActivityAction
+			if activity != nil {
+				gotActivity = activity.Action
+			}
+			if gotActivity != tt.wantActivity {
+				t.Errorf("expected activity %q, got %q", tt.wantActivity, gotActivity)
+			}
+			if tt.expense.LimitFlagged != tt.wantFlagged {
+				t.Errorf("expected flagged %v, got %v", tt.wantFlagged, tt.expense.LimitFlagged)
+			}
+		})
+	}
+}
+
+func TestDeriveRatioSplits(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      float64
+		percentage float64
+		wantA      float64
+		wantB      float64
+	}{
+		{"Even Ratio", 100, 70, 70, 30},
+		{"Rounds First Share", 99.99, 70, 69.99, 30},
+		{"Remainder Goes To Second", 100, 33.33, 33.33, 66.67},
+		{"Odd Cents", 10.01, 50, 5.01, 5},
+		{"Whole Expense", 250, 100, 250, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splits := deriveRatioSplits(tt.total, "A", tt.percentage, "B")
+			if len(splits) != 2 {
+				t.Fatalf("expected 2 splits, got %d", len(splits))
+			}
+			if splits[0].Amount != tt.wantA || splits[1].Amount != tt.wantB {
+				t.Errorf("expected %.2f/%.2f, got %.2f/%.2f", tt.wantA, tt.wantB, splits[0].Amount, splits[1].Amount)
+			}
+			if sum := math.Round((splits[0].Amount+splits[1].Amount)*RoundingFactor) / RoundingFactor; sum != tt.total {
+				t.Errorf("splits sum to %.2f, expected %.2f", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestEqualSplits(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   float64
+		userIDs []string
+		want    map[string]float64
+	}{
+		{"Even Split", 90, []string{"A", "B", "C"}, map[string]float64{"A": 30, "B": 30, "C": 30}},
+		{"Three Way 100", 100, []string{"C", "A", "B"}, map[string]float64{"A": 33.34, "B": 33.33, "C": 33.33}},
+		{"Two Leftover Cents", 10.01, []string{"B", "C", "A"}, map[string]float64{"A": 3.34, "B": 3.34, "C": 3.33}},
+		{"Duplicates Count Once", 50, []string{"A", "B", "A"}, map[string]float64{"A": 25, "B": 25}},
+		{"Single Participant", 12.5, []string{"A"}, map[string]float64{"A": 12.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splits := equalSplits(tt.total, tt.userIDs)
+			if len(splits) != len(tt.want) {
+				t.Fatalf("expected %d splits, got %d", len(tt.want), len(splits))
+			}
+			sum := 0.0
+			for _, sp := range splits {
+				if sp.Amount != tt.want[sp.UserID] {
+					t.Errorf("%s: expected %.2f, got %.2f", sp.UserID, tt.want[sp.UserID], sp.Amount)
+				}
+				sum += sp.Amount
+			}
+			if math.Round(sum*RoundingFactor)/RoundingFactor != tt.total {
+				t.Errorf("splits sum to %.2f, expected %.2f", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestPreferredSplits(t *testing.T) {
+	s := &expenseService{
+		groupRepo: &mockGroupRepo{members: []models.User{{ID: "B"}, {ID: "A"}}},
+		splitPreferenceRepo: &mockSplitPreferenceRepo{preference: &models.SplitPreference{
+			ID: "pref", UserAID: "A", UserBID: "B", UserAPercentage: 30,
+		}},
+	}
+	expense := &models.Expense{GroupID: "G", TotalAmount: 45.55, Type: models.ExpenseTypeEqual}
+
+	splits, err := s.preferredSplits(context.Background(), expense)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if splits[0].UserID != "A" || splits[0].Amount != 13.67 || splits[1].UserID != "B" || splits[1].Amount != 31.88 {
+		t.Errorf("unexpected splits: %+v", splits)
+	}
+	if expense.Type != models.ExpenseTypePercentage {
+		t.Errorf("expected split method %q, got %q", models.ExpenseTypePercentage, expense.Type)
+	}
+	if err := s.validateExpenseAmounts(&models.Expense{TotalAmount: 45.55, Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 45.55}}}, splits); err != nil {
+		t.Errorf("derived splits failed validation: %v", err)
+	}
+
+	s.groupRepo = &mockGroupRepo{members: []models.User{{ID: "A"}, {ID: "B"}, {ID: "C"}}}
+	if _, err := s.preferredSplits(context.Background(), expense); err == nil {
+		t.Error("expected error for a group with more than two members")
+	}
+}
+
+func TestReconcileReceipt(t *testing.T) {
+	items := []models.ReceiptItem{{Price: 100}, {Price: 50}}
+	tests := []struct {
+		name       string
+		expense    models.Expense
+		wantStatus models.ReconciliationStatus
+		wantDelta  float64
+	}{
+		{
+			name:       "Items plus tax and service charge",
+			expense:    models.Expense{TotalAmount: 172.50, Tax: 7.50, ServiceCharge: 15, ReceiptItems: items},
+			wantStatus: models.ReconciliationMatched,
+		},
+		{
+			name:       "CGST and SGST used when tax is empty",
+			expense:    models.Expense{TotalAmount: 157.50, CGST: 3.75, SGST: 3.75, ReceiptItems: items},
+			wantStatus: models.ReconciliationMatched,
+		},
+		{
+			name:       "Tax-inclusive item prices",
+			expense:    models.Expense{TotalAmount: 150, Tax: 7.14, ReceiptItems: items},
+			wantStatus: models.ReconciliationMatched,
+		},
+		{
+			name:       "Receipt exceeds total",
+			expense:    models.Expense{TotalAmount: 140, Tax: 5, ReceiptItems: items},
+			wantStatus: models.ReconciliationOver,
+			wantDelta:  15,
+		},
+		{
+			name:       "Receipt short of total",
+			expense:    models.Expense{TotalAmount: 200, Tax: 10, ReceiptItems: items},
+			wantStatus: models.ReconciliationUnder,
+			wantDelta:  -40,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconcileReceipt(&tt.expense)
+			if got == nil {
+				t.Fatal("expected a reconciliation result")
+			}
+			if got.Status != tt.wantStatus || got.Delta != tt.wantDelta {
+				t.Errorf("expected %s (%.2f), got %s (%.2f)", tt.wantStatus, tt.wantDelta, got.Status, got.Delta)
+			}
+		})
+	}
+
+	if got := reconcileReceipt(&models.Expense{TotalAmount: 10}); got != nil {
+		t.Errorf("expected nil for expense without receipt items, got %+v", got)
+	}
+}
+
+func TestCanEditExpense(t *testing.T) {
+	creator, payer := "creator", "payer"
+	entered := &models.Expense{CreatedByUserID: &creator, Payers: []models.ExpensePayer{{UserID: payer}}}
+	legacy := &models.Expense{PaidByUserID: &payer}
+
+	tests := []struct {
+		name    string
+		policy  models.ExpenseEditPolicy
+		expense *models.Expense
+		userID  string
+		want    bool
+	}{
+		{"Any member", models.ExpenseEditPolicyAnyMember, entered, "other", true},
+		{"Creator policy allows creator", models.ExpenseEditPolicyCreator, entered, creator, true},
+		{"Creator policy denies payer", models.ExpenseEditPolicyCreator, entered, payer, false},
+		{"Creator or payer allows payer", models.ExpenseEditPolicyCreatorOrPayer, entered, payer, true},
+		{"Creator or payer denies others", models.ExpenseEditPolicyCreatorOrPayer, entered, "other", false},
+		{"Legacy expense falls back to payer", models.ExpenseEditPolicyCreator, legacy, payer, true},
+		{"Legacy expense denies others", models.ExpenseEditPolicyCreator, legacy, "other", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canEditExpense(tt.policy, tt.expense, tt.userID); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRequireEditRightsAdminBypass(t *testing.T) {
+	creator := "creator"
+	expense := &models.Expense{GroupID: "g1", CreatedByUserID: &creator}
+	s := &expenseService{
+		groupRepo: &mockGroupRepo{editPolicy: models.ExpenseEditPolicyCreator},
+		admins:    map[string]bool{"admin": true},
+	}
+
+	if err := s.requireEditRights(context.Background(), expense, "admin"); err != nil {
+		t.Errorf("expected admin to bypass the policy, got %v", err)
+	}
+	if err := s.requireEditRights(context.Background(), expense, "member"); err == nil {
+		t.Error("expected non-creator to be denied")
+	}
+}
+
+func TestReceiptItemPortions(t *testing.T) {
+	items := []models.ReceiptItem{
+		{Name: "Beer", Price: 10, Quantity: 3, Assignments: []models.ReceiptItemAssignment{
+			{UserID: "bob", Portion: 2}, {UserID: "alice", Portion: 1},
+		}},
+		{Name: "Pizza", Price: 10, Assignments: []models.ReceiptItemAssignment{
+			{UserID: "bob"}, {UserID: "alice"}, {UserID: "carol"},
+		}},
+	}
+	if err := prepareReceiptItems(items); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	applyReceiptItemShares(items)
+
+	if items[0].UnitPrice != 3.33 {
+		t.Errorf("expected unit price 3.33, got %.2f", items[0].UnitPrice)
+	}
+	if got := items[0].Assignments[0].Amount; got != 6.67 {
+		t.Errorf("expected bob to owe 6.67 for beer, got %.2f", got)
+	}
+	if got := items[0].Assignments[1].Amount; got != 3.33 {
+		t.Errorf("expected alice to owe 3.33 for beer, got %.2f", got)
+	}
+
+	if items[1].Quantity != 1 {
+		t.Errorf("expected quantity to default to 1, got %g", items[1].Quantity)
+	}
+	sum := 0.0
+	for _, a := range items[1].Assignments {
+		if a.Portion != 0.333 {
+			t.Errorf("expected equal portion 0.333, got %g", a.Portion)
+		}
+		sum += a.Amount
+	}
+	if math.Abs(sum-10) > AmountTolerance {
+		t.Errorf("expected pizza shares to add up to 10, got %.2f", sum)
+	}
+
+	// Stored equal portions must still validate on a later update.
+	if err := prepareReceiptItems(items); err != nil {
+		t.Errorf("expected stored portions to revalidate, got %v", err)
+	}
+
+	invalid := []models.ReceiptItem{
+		{Name: "Short", Price: 9, Quantity: 3, Assignments: []models.ReceiptItemAssignment{{UserID: "bob", Portion: 2}}},
+		{Name: "Mixed", Price: 9, Quantity: 3, Assignments: []models.ReceiptItemAssignment{{UserID: "bob", Portion: 3}, {UserID: "alice"}}},
+		{Name: "Negative", Price: 9, Quantity: -1},
+	}
+	for _, item := range invalid {
+		if err := prepareReceiptItems([]models.ReceiptItem{item}); err == nil {
+			t.Errorf("expected error for %s", item.Name)
+		}
+	}
+}
+
+func TestExpenseWriteError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected apperrors.ErrorCode
+	}{
+		{
+			name:     "Group deleted",
+			err:      fmt.Errorf(`creating expense: ERROR: insert or update on table "expenses" violates foreign key constraint "expenses_group_id_fkey" (SQLSTATE 23503)`),
+			expected: apperrors.CodeGroupNotFound,
+		},
+		{
+			name:     "User deleted",
+			err:      fmt.Errorf(`creating split: ERROR: insert or update on table "expense_splits" violates foreign key constraint "expense_splits_user_id_fkey" (SQLSTATE 23503)`),
+			expected: apperrors.CodeNotGroupMember,
+		},
+		{
+			name:     "Other database error",
+			err:      fmt.Errorf("creating expense: connection reset"),
+			expected: apperrors.CodeDatabaseError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr, ok := apperrors.AsAppError(expenseWriteError("writing", tt.err))
+			if !ok {
+				t.Fatal("expected an AppError")
+			}
+			if appErr.Code != tt.expected {
+				t.Errorf("expenseWriteError() code = %s, expected %s", appErr.Code, tt.expected)
+			}
+		})
+	}
+}
+
+type dailyCountExpenseRepo struct {
+	mockExpenseRepo
+	count int
+	calls *[]string
+}
+
+func (m *dailyCountExpenseRepo) CountGroupExpensesSince(ctx context.Context, groupID string, since time.Time) (int, error) {
+	*m.calls = append(*m.calls, "count")
+	return m.count, nil
+}
+func (m *dailyCountExpenseRepo) WithTx(tx database.Querier) repository.ExpenseRepository { return m }
+
+type exclusiveLockGroupRepo struct {
+	mockGroupRepo
+	calls *[]string
+}
+
+func (m *exclusiveLockGroupRepo) LockForUpdate(ctx context.Context, groupID string) error {
+	*m.calls = append(*m.calls, "lock")
+	return nil
+}
+func (m *exclusiveLockGroupRepo) WithTx(tx database.Querier) repository.GroupRepository { return m }
+
+func TestDailyExpenseLimitCountsUnderGroupLock(t *testing.T) {
+	maxDaily := 3
+	tests := []struct {
+		name        string
+		count       int
+		shouldError bool
+	}{
+		{name: "Below Daily Limit", count: 2},
+		{name: "Daily Limit Reached", count: 3, shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			s := &expenseService{
+				expenseRepo: &dailyCountExpenseRepo{count: tt.count, calls: &calls},
+				groupRepo: &exclusiveLockGroupRepo{calls: &calls, mockGroupRepo: mockGroupRepo{limits: &models.GroupLimits{
+					MaxDailyExpenses: &maxDaily,
+					Action:           models.GroupLimitActionReject,
+					Currency:         "INR",
+				}}},
+			}
+			expense := &models.Expense{GroupID: "g1", TotalAmount: 100, Currency: "INR", Category: models.TransactionCategoryExpense}
+			_, err := s.checkExpenseLimits(context.Background(), nil, "A", expense, nil)
+			if (err != nil) != tt.shouldError {
+				t.Fatalf("expected error: %v, got: %v", tt.shouldError, err)
+			}
+			if strings.Join(calls, ",") != "lock,count" {
+				t.Errorf("calls = %v, expected the group locked before counting", calls)
+			}
+		})
+	}
+}
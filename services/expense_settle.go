@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SettleExpense records the payments that clear userID's share of one
+// expense, leaving the rest of their balance in the group as it is. Each
+// payer gets the part of the share they covered, in the expense's currency.
+// A member settles an expense once, unless the payments are reversed.
+func (s *expenseService) SettleExpense(ctx context.Context, expenseID, userID string) ([]models.Expense, error) {
+	expense, err := s.expenseRepo.GetByID(ctx, expenseID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.ExpenseNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting expense", err)
+	}
+	if err := RequireGroupMembership(ctx, s.groupRepo, expense.GroupID, userID); err != nil {
+		return nil, err
+	}
+	if expense.Category != models.TransactionCategoryExpense {
+		return nil, apperrors.InvalidRequest("Only expenses can be settled on their own.")
+	}
+
+	refunded, err := s.expenseRepo.GetRefundedAmount(ctx, expenseID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting refunded amount", err)
+	}
+	if refunded > 0 {
+		return nil, apperrors.InvalidRequest("Expenses with refunds can't be settled on their own. Settle up with the group instead.")
+	}
+	settled, err := s.expenseRepo.HasSettledExpense(ctx, expenseID, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking expense settlement", err)
+	}
+	if settled {
+		return nil, apperrors.Conflict("You have already settled this expense.")
+	}
+
+	legs := expenseSettlementLegs(expense, userID)
+	if len(legs) == 0 {
+		return nil, apperrors.InvalidRequest("You don't owe anything on this expense.")
+	}
+	totals := make(map[string]float64)
+	for _, leg := range legs {
+		totals[leg.Currency] += leg.Amount
+	}
+	for currency, total := range totals {
+		if err := requireVerifiedForSettlement(ctx, s.emailVerifier, userID, total, currency); err != nil {
+			return nil, err
+		}
+	}
+
+	names := s.memberNames(ctx, expense.GroupID)
+	now := time.Now()
+	payments := make([]*models.Expense, len(legs))
+	for i, leg := range legs {
+		paymentID := uuid.New().String()
+		payments[i] = &models.Expense{
+			ID:               paymentID,
+			GroupID:          expense.GroupID,
+			PaidByUserID:     &userID,
+			CreatedByUserID:  &userID,
+			TotalAmount:      leg.Amount,
+			Currency:         leg.Currency,
+			Description:      fmt.Sprintf("Payment from %s to %s for '%s'", nameOf(names, userID), nameOf(names, leg.ToUserID), expense.Description),
+			Type:             models.ExpenseTypeEqual,
+			Category:         models.TransactionCategoryPayment,
+			SettlesExpenseID: &expense.ID,
+			DateISO:          now,
+			Date:             now.Format("2006-01-02"),
+			Time:             now.Format("15:04"),
+			Payers: []models.ExpensePayer{
+				{ID: uuid.New().String(), ExpenseID: paymentID, UserID: userID, AmountPaid: leg.Amount},
+			},
+			Splits: []models.ExpenseSplit{
+				{ID: uuid.New().String(), ExpenseID: paymentID, UserID: leg.ToUserID, Amount: leg.Amount},
+			},
+		}
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		userIDs := []string{userID}
+		for _, leg := range legs {
+			userIDs = append(userIDs, leg.ToUserID)
+		}
+		if err := lockGroupForWrite(ctx, s.groupRepo, q, expense.GroupID, userIDs...); err != nil {
+			return err
+		}
+
+		txRepo := s.expenseRepo.WithTx(q)
+		for _, payment := range payments {
+			if err := txRepo.Create(ctx, payment); err != nil {
+				return expenseWriteError("creating payment transaction", err)
+			}
+			if err := txRepo.CreatePayer(ctx, &payment.Payers[0]); err != nil {
+				return expenseWriteError("creating payment payer", err)
+			}
+			if err := txRepo.CreateSplit(ctx, &payment.Splits[0]); err != nil {
+				return expenseWriteError("creating payment split", err)
+			}
+			if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, payment.ID, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("Expense settled",
+		zap.String("expense_id", expenseID),
+		zap.String("user_id", userID),
+		zap.Int("payments", len(payments)))
+
+	result := make([]models.Expense, 0, len(payments))
+	for i, payment := range payments {
+		markSeenByActor(ctx, s.readRepo, expense.GroupID, userID, payment.ID)
+		dispatchNotificationAsync(s.notificationService, NotificationPayload{
+			Event:      models.NotificationEventSettlement,
+			GroupID:    expense.GroupID,
+			ExpenseID:  payment.ID,
+			ActorID:    userID,
+			Template:   notifySettlement,
+			Args:       []interface{}{nameOf(names, userID), nameOf(names, legs[i].ToUserID), legs[i].Amount, legs[i].Currency},
+			Recipients: []string{userID, legs[i].ToUserID},
+		})
+
+		created, err := s.expenseRepo.GetByID(ctx, payment.ID)
+		if err != nil {
+			return nil, apperrors.DatabaseError("getting payment", err)
+		}
+		result = append(result, *created)
+	}
+	checkBalanceAlertsAsync(s.balanceAlertService, expense.GroupID)
+	return result, nil
+}
+
+// expenseSettlementLegs works out what userID pays each payer to clear their
+// share of expense. The share is owed to the payers in proportion to what
+// they paid, as in pairwiseNet, so the part the user paid themselves needs
+// no payment. Rounding leftovers go to the last payer, keeping the total to
+// the cent.
+func expenseSettlementLegs(expense *models.Expense, userID string) []models.Settlement {
+	split := findSplit(expense.Splits, userID)
+	if split == nil {
+		return nil
+	}
+
+	payers := make([]models.ExpensePayer, 0, len(expense.Payers))
+	totalPaid, othersPaid := 0.0, 0.0
+	for _, p := range expense.Payers {
+		totalPaid += p.AmountPaid
+		if p.UserID != userID && p.AmountPaid > 0 {
+			othersPaid += p.AmountPaid
+			payers = append(payers, p)
+		}
+	}
+	if totalPaid < BalanceThreshold || len(payers) == 0 {
+		return nil
+	}
+	sort.Slice(payers, func(i, j int) bool { return payers[i].UserID < payers[j].UserID })
+
+	owed := int64(math.Round(split.Amount * othersPaid / totalPaid * RoundingFactor))
+	legs := make([]models.Settlement, 0, len(payers))
+	var assigned int64
+	for i, p := range payers {
+		cents := int64(math.Round(split.Amount * p.AmountPaid / totalPaid * RoundingFactor))
+		if i == len(payers)-1 {
+			cents = owed - assigned
+		}
+		assigned += cents
+		if cents <= 0 {
+			continue
+		}
+		legs = append(legs, models.Settlement{
+			FromUserID: userID,
+			ToUserID:   p.UserID,
+			Amount:     float64(cents) / RoundingFactor,
+			Currency:   expense.Currency,
+		})
+	}
+	return legs
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestExpenseSettlementLegs(t *testing.T) {
+	tests := []struct {
+		name     string
+		expense  models.Expense
+		expected map[string]float64
+	}{
+		{
+			name: "Single payer",
+			expense: models.Expense{
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 90}},
+				Splits: []models.ExpenseSplit{{UserID: "A", Amount: 30}, {UserID: "U", Amount: 30}, {UserID: "B", Amount: 30}},
+			},
+			expected: map[string]float64{"A": 30},
+		},
+		{
+			name: "Share split between payers",
+			expense: models.Expense{
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 60}, {UserID: "B", AmountPaid: 40}},
+				Splits: []models.ExpenseSplit{{UserID: "A", Amount: 50}, {UserID: "U", Amount: 50}},
+			},
+			expected: map[string]float64{"A": 30, "B": 20},
+		},
+		{
+			name: "Own payment covers part of the share",
+			expense: models.Expense{
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 75}, {UserID: "U", AmountPaid: 25}},
+				Splits: []models.ExpenseSplit{{UserID: "A", Amount: 50}, {UserID: "U", Amount: 50}},
+			},
+			expected: map[string]float64{"A": 37.5},
+		},
+		{
+			name: "Rounding leftover goes to the last payer",
+			expense: models.Expense{
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 10}, {UserID: "B", AmountPaid: 10}, {UserID: "C", AmountPaid: 10}},
+				Splits: []models.ExpenseSplit{{UserID: "U", Amount: 10}, {UserID: "A", Amount: 20}},
+			},
+			expected: map[string]float64{"A": 3.33, "B": 3.33, "C": 3.34},
+		},
+		{
+			name: "Not in the split",
+			expense: models.Expense{
+				Payers: []models.ExpensePayer{{UserID: "A", AmountPaid: 50}},
+				Splits: []models.ExpenseSplit{{UserID: "A", Amount: 25}, {UserID: "B", Amount: 25}},
+			},
+		},
+		{
+			name: "Sole payer",
+			expense: models.Expense{
+				Payers: []models.ExpensePayer{{UserID: "U", AmountPaid: 50}},
+				Splits: []models.ExpenseSplit{{UserID: "A", Amount: 25}, {UserID: "U", Amount: 25}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expense.Currency = "INR"
+			legs := expenseSettlementLegs(&tt.expense, "U")
+			if len(legs) != len(tt.expected) {
+				t.Fatalf("got %d legs, expected %d: %+v", len(legs), len(tt.expected), legs)
+			}
+			for _, leg := range legs {
+				if leg.FromUserID != "U" || leg.Currency != "INR" {
+					t.Errorf("unexpected leg %+v", leg)
+				}
+				if leg.Amount != tt.expected[leg.ToUserID] {
+					t.Errorf("payment to %s = %.2f, expected %.2f", leg.ToUserID, leg.Amount, tt.expected[leg.ToUserID])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+
+	"go.uber.org/zap"
+)
+
+// SetSplitLock locks or unlocks an expense's splits. A locked expense, such
+// as a personal loan recorded as an expense, is never re-split by back-charging
+// or exclusions, and editing its splits needs an explicit unlock first. Anyone
+// the group's edit policy lets change the expense can do either.
+func (s *expenseService) SetSplitLock(ctx context.Context, expenseID, userID string, locked bool) (*models.Expense, error) {
+	expense, err := s.expenseRepo.GetByID(ctx, expenseID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.ExpenseNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting expense", err)
+	}
+	if err := RequireGroupMembership(ctx, s.groupRepo, expense.GroupID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.requireEditRights(ctx, expense, userID); err != nil {
+		return nil, err
+	}
+	if expense.LockedSplits == locked {
+		return s.GetByID(ctx, expenseID, userID)
+	}
+
+	action, verb := models.GroupActivitySplitsLocked, "locked"
+	if !locked {
+		action, verb = models.GroupActivitySplitsUnlocked, "unlocked"
+	}
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.expenseRepo.WithTx(q).SetSplitsLocked(ctx, expenseID, locked); err != nil {
+			return apperrors.DatabaseError("setting split lock", err)
+		}
+		return s.recordExpenseActivity(ctx, q, expense, userID, action,
+			fmt.Sprintf("The splits of '%s' were %s", expense.Description, verb))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("Expense split lock changed",
+		zap.String("expense_id", expenseID),
+		zap.String("user_id", userID),
+		zap.Bool("locked", locked))
+	return s.GetByID(ctx, expenseID, userID)
+}
+
+// splitsChanged reports whether splits give anyone a different share than
+// existing does, to the cent.
+func splitsChanged(existing, splits []models.ExpenseSplit) bool {
+	shares := make(map[string]int64, len(existing))
+	for _, sp := range existing {
+		shares[sp.UserID] += int64(math.Round(sp.Amount * RoundingFactor))
+	}
+	for _, sp := range splits {
+		shares[sp.UserID] -= int64(math.Round(sp.Amount * RoundingFactor))
+	}
+	for _, diff := range shares {
+		if diff != 0 {
+			return true
+		}
+	}
+	return false
+}
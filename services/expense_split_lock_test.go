@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestSplitsChanged(t *testing.T) {
+	existing := []models.ExpenseSplit{{UserID: "A", Amount: 60}, {UserID: "B", Amount: 40}}
+	tests := []struct {
+		name     string
+		splits   []models.ExpenseSplit
+		expected bool
+	}{
+		{name: "Same shares", splits: []models.ExpenseSplit{{UserID: "A", Amount: 60}, {UserID: "B", Amount: 40}}},
+		{name: "Reordered", splits: []models.ExpenseSplit{{UserID: "B", Amount: 40}, {UserID: "A", Amount: 60}}},
+		{name: "Float noise", splits: []models.ExpenseSplit{{UserID: "A", Amount: 60.001}, {UserID: "B", Amount: 39.999}}},
+		{name: "Shares moved", splits: []models.ExpenseSplit{{UserID: "A", Amount: 50}, {UserID: "B", Amount: 50}}, expected: true},
+		{name: "Participant added", splits: []models.ExpenseSplit{{UserID: "A", Amount: 60}, {UserID: "B", Amount: 40}, {UserID: "C", Amount: 0.01}}, expected: true},
+		{name: "Participant dropped", splits: []models.ExpenseSplit{{UserID: "A", Amount: 100}}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitsChanged(existing, tt.splits); got != tt.expected {
+				t.Errorf("splitsChanged() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 
 	apperrors "unwise-backend/errors"
 	"unwise-backend/models"
@@ -17,15 +19,23 @@ type ExplanationService interface {
 	ExplainTransaction(ctx context.Context, transactionID, userID string) (*models.DebtExplanation, error)
 }
 
+// explanationExpenses is the part of the expense repository explanations
+// need: reading a transaction and caching its explanation.
+type explanationExpenses interface {
+	repository.ExpenseReader
+	repository.ExpenseWriter
+}
+
 type explanationService struct {
-	expenseRepo repository.ExpenseRepository
-	groupRepo   repository.GroupRepository
-	userRepo    repository.UserRepository
-	apiKey      string
-	client      *genai.Client
+	expenseRepo  explanationExpenses
+	groupRepo    repository.GroupRepository
+	userRepo     repository.UserRepository
+	auditService AIAuditService
+	apiKey       string
+	client       *genai.Client
 }
 
-func NewExplanationService(apiKey string, expenseRepo repository.ExpenseRepository, groupRepo repository.GroupRepository, userRepo repository.UserRepository) (ExplanationService, error) {
+func NewExplanationService(apiKey string, expenseRepo explanationExpenses, groupRepo repository.GroupRepository, userRepo repository.UserRepository, auditService AIAuditService) (ExplanationService, error) {
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
 	if err != nil {
@@ -33,11 +43,12 @@ func NewExplanationService(apiKey string, expenseRepo repository.ExpenseReposito
 	}
 
 	return &explanationService{
-		expenseRepo: expenseRepo,
-		groupRepo:   groupRepo,
-		userRepo:    userRepo,
-		apiKey:      apiKey,
-		client:      client,
+		expenseRepo:  expenseRepo,
+		groupRepo:    groupRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+		apiKey:       apiKey,
+		client:       client,
 	}, nil
 }
 
@@ -50,13 +61,6 @@ func (s *explanationService) ExplainTransaction(ctx context.Context, transaction
 		return nil, apperrors.DatabaseError("getting expense", err)
 	}
 
-	if expense.Explanation != nil && *expense.Explanation != "" {
-		return &models.DebtExplanation{
-			TransactionID: transactionID,
-			Explanation:   *expense.Explanation,
-		}, nil
-	}
-
 	if err := RequireGroupMembership(ctx, s.groupRepo, expense.GroupID, userID); err != nil {
 		return nil, err
 	}
@@ -110,14 +114,27 @@ func (s *explanationService) ExplainTransaction(ctx context.Context, transaction
 		}
 	}
 
-	beforeDebts := s.getSimplifiedDebts(beforeBalances, userMap)
-	afterDebts := s.getSimplifiedDebts(afterBalances, userMap)
+	afterEdges := simplifyDebts(afterBalances)
+	actions := suggestNextActions(afterEdges, expense.GroupID, userID, userMap)
+
+	if expense.Explanation != nil && *expense.Explanation != "" {
+		return &models.DebtExplanation{
+			TransactionID:    transactionID,
+			Explanation:      *expense.Explanation,
+			OutputID:         s.auditService.LatestOutputID(ctx, transactionID, models.AIOutputExplanation),
+			SuggestedActions: actions,
+		}, nil
+	}
+
+	beforeDebts := describeDebts(simplifyDebts(beforeBalances), userMap)
+	afterDebts := describeDebts(afterEdges, userMap)
 	targetPayers := allPayers[transactionID]
 	targetSplits := allSplits[transactionID]
 
-	prompt := s.buildPrompt(expense, targetPayers, targetSplits, beforeDebts, afterDebts, userMap)
+	language := groupLanguage(ctx, s.groupRepo, expense.GroupID)
+	prompt := s.buildPrompt(expense, targetPayers, targetSplits, beforeDebts, afterDebts, userMap, language)
 
-	model := s.client.GenerativeModel("gemini-2.0-flash")
+	model := s.client.GenerativeModel(AIModelName)
 	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
 		return nil, apperrors.AIServiceError(err)
@@ -130,6 +147,12 @@ func (s *explanationService) ExplainTransaction(ctx context.Context, transaction
 		}
 	}
 
+	result := &models.DebtExplanation{
+		TransactionID:    transactionID,
+		Explanation:      explanationText,
+		SuggestedActions: actions,
+	}
+
 	if explanationText != "" {
 		go func() {
 			err := s.expenseRepo.UpdateExplanation(context.Background(), transactionID, explanationText)
@@ -137,15 +160,39 @@ func (s *explanationService) ExplainTransaction(ctx context.Context, transaction
 				zap.L().Error("Failed to cache explanation", zap.String("transaction_id", transactionID), zap.Error(err))
 			}
 		}()
+
+		names := make([]string, 0, len(userMap))
+		for _, name := range userMap {
+			names = append(names, name)
+		}
+		output := &models.AIOutput{
+			Kind:      models.AIOutputExplanation,
+			UserID:    &userID,
+			GroupID:   &expense.GroupID,
+			ExpenseID: &transactionID,
+			Model:     AIModelName,
+			Prompt:    prompt,
+			Response:  explanationText,
+		}
+		if err := s.auditService.Record(ctx, output, names); err != nil {
+			zap.L().Error("Failed to record explanation output", zap.String("transaction_id", transactionID), zap.Error(err))
+		} else {
+			result.OutputID = output.ID
+		}
 	}
 
-	return &models.DebtExplanation{
-		TransactionID: transactionID,
-		Explanation:   explanationText,
-	}, nil
+	return result, nil
 }
 
-func (s *explanationService) getSimplifiedDebts(balances map[string]float64, userMap map[string]string) []string {
+type debtEdge struct {
+	from   string
+	to     string
+	amount float64
+}
+
+// simplifyDebts pairs debtors with creditors greedily, in user ID order so the
+// same balances always produce the same payments.
+func simplifyDebts(balances map[string]float64) []debtEdge {
 	creditors := make([]string, 0)
 	debtors := make([]string, 0)
 	creditorBal := make(map[string]float64)
@@ -160,8 +207,10 @@ func (s *explanationService) getSimplifiedDebts(balances map[string]float64, use
 			debtorBal[id] = -bal
 		}
 	}
+	sort.Strings(creditors)
+	sort.Strings(debtors)
 
-	var results []string
+	var edges []debtEdge
 	for len(creditors) > 0 && len(debtors) > 0 {
 		c := creditors[0]
 		d := debtors[0]
@@ -170,7 +219,7 @@ func (s *explanationService) getSimplifiedDebts(balances map[string]float64, use
 			amt = debtorBal[d]
 		}
 
-		results = append(results, fmt.Sprintf("%s owes %s $%.2f", userMap[d], userMap[c], amt))
+		edges = append(edges, debtEdge{from: d, to: c, amount: amt})
 
 		creditorBal[c] -= amt
 		debtorBal[d] -= amt
@@ -182,10 +231,45 @@ func (s *explanationService) getSimplifiedDebts(balances map[string]float64, use
 			debtors = debtors[1:]
 		}
 	}
+	return edges
+}
+
+func describeDebts(edges []debtEdge, userMap map[string]string) []string {
+	var results []string
+	for _, e := range edges {
+		results = append(results, fmt.Sprintf("%s owes %s $%.2f", userMap[e.from], userMap[e.to], e.amount))
+	}
 	return results
 }
 
-func (s *explanationService) buildPrompt(target *models.Expense, payers []models.ExpensePayer, splits []models.ExpenseSplit, before, after []string, userMap map[string]string) string {
+// suggestNextActions keeps the payments that involve userID, so the client can
+// offer a settle or remind button for each.
+func suggestNextActions(edges []debtEdge, groupID, userID string, userMap map[string]string) []models.SuggestedAction {
+	actions := []models.SuggestedAction{}
+	for _, e := range edges {
+		var action models.SuggestedActionType
+		switch userID {
+		case e.from:
+			action = models.SuggestedActionSettle
+		case e.to:
+			action = models.SuggestedActionRemind
+		default:
+			continue
+		}
+		actions = append(actions, models.SuggestedAction{
+			Action:     action,
+			GroupID:    groupID,
+			FromUserID: e.from,
+			FromName:   userMap[e.from],
+			ToUserID:   e.to,
+			ToName:     userMap[e.to],
+			Amount:     math.Round(e.amount*RoundingFactor) / RoundingFactor,
+		})
+	}
+	return actions
+}
+
+func (s *explanationService) buildPrompt(target *models.Expense, payers []models.ExpensePayer, splits []models.ExpenseSplit, before, after []string, userMap map[string]string, language string) string {
 	beforeList := ""
 	for _, d := range before {
 		beforeList += "- " + d + "\n"
@@ -231,6 +315,8 @@ Please provide a concise, friendly explanation of what happened. Focus on:
 2. Did this transaction "cancel out" any existing debts? 
 3. Why does the 'After' state look the way it does? (e.g., "By paying for dinner, you effectively repaid your debt to Sarah while also putting John in your debt").
 
-Keep it under 3-4 sentences. Use names clearly. Be conversational but accurate. Do NOT start with conversational fillers like "Okay so", "Let's see", or "Here is the breakdown". Get straight to the explanation.`,
-		target.Description, target.TotalAmount, target.Category, participantInfo, beforeList, afterList)
+Keep it under 3-4 sentences. Use names clearly. Be conversational but accurate. Do NOT start with conversational fillers like "Okay so", "Let's see", or "Here is the breakdown". Get straight to the explanation.
+
+Write the explanation in %s, whatever language the descriptions and names are in.`,
+		target.Description, target.TotalAmount, target.Category, participantInfo, beforeList, afterList, groupLanguages[language])
 }
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestSuggestNextActions(t *testing.T) {
+	userMap := map[string]string{"a": "Asha", "b": "Ben", "c": "Chen", "d": "Dev"}
+
+	tests := []struct {
+		name     string
+		balances map[string]float64
+		userID   string
+		expected []models.SuggestedAction
+	}{
+		{
+			name:     "User owes",
+			balances: map[string]float64{"a": 30, "b": -30},
+			userID:   "b",
+			expected: []models.SuggestedAction{{Action: models.SuggestedActionSettle, FromUserID: "b", ToUserID: "a", Amount: 30}},
+		},
+		{
+			name:     "User is owed by two members",
+			balances: map[string]float64{"a": 50, "b": -20, "c": -30},
+			userID:   "a",
+			expected: []models.SuggestedAction{
+				{Action: models.SuggestedActionRemind, FromUserID: "b", ToUserID: "a", Amount: 20},
+				{Action: models.SuggestedActionRemind, FromUserID: "c", ToUserID: "a", Amount: 30},
+			},
+		},
+		{
+			name:     "Debts between other members are left out",
+			balances: map[string]float64{"a": 10, "b": -10, "c": 5, "d": -5},
+			userID:   "d",
+			expected: []models.SuggestedAction{{Action: models.SuggestedActionSettle, FromUserID: "d", ToUserID: "c", Amount: 5}},
+		},
+		{
+			name:     "Rounded to cents",
+			balances: map[string]float64{"a": 10.004, "b": -10.004},
+			userID:   "a",
+			expected: []models.SuggestedAction{{Action: models.SuggestedActionRemind, FromUserID: "b", ToUserID: "a", Amount: 10}},
+		},
+		{
+			name:     "Settled group",
+			balances: map[string]float64{"a": 0.004, "b": -0.004},
+			userID:   "a",
+			expected: []models.SuggestedAction{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := suggestNextActions(simplifyDebts(tt.balances), "g1", tt.userID, userMap)
+			if len(actions) != len(tt.expected) {
+				t.Fatalf("expected %d actions, got %d: %+v", len(tt.expected), len(actions), actions)
+			}
+			for i, want := range tt.expected {
+				got := actions[i]
+				if got.Action != want.Action || got.FromUserID != want.FromUserID || got.ToUserID != want.ToUserID || got.Amount != want.Amount {
+					t.Errorf("action %d: expected %+v, got %+v", i, want, got)
+				}
+				if got.GroupID != "g1" || got.FromName != userMap[want.FromUserID] || got.ToName != userMap[want.ToUserID] {
+					t.Errorf("action %d: missing group or names: %+v", i, got)
+				}
+			}
+		})
+	}
+}
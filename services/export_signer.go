@@ -0,0 +1,88 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "unwise-backend/errors"
+)
+
+// ExportSigner signs exported files so whoever receives one (an employer's
+// finance team, say) can check it is byte-for-byte what the server produced.
+// Signatures look like "t=1717171717,v1=<hex>": an HMAC-SHA256 over the
+// signing time, a dot and the file.
+type ExportSigner interface {
+	Enabled() bool
+	Sign(body []byte, signedAt time.Time) string
+	// Verify reports whether signature matches body and when it was made.
+	// A signature that cannot be parsed is an InvalidRequest error.
+	Verify(body []byte, signature string) (valid bool, signedAt time.Time, err error)
+}
+
+type exportSigner struct {
+	key []byte
+}
+
+// NewExportSigner returns a signer keyed with key. With an empty key signing
+// is disabled and exports asking for a signature are rejected.
+func NewExportSigner(key string) ExportSigner {
+	return &exportSigner{key: []byte(key)}
+}
+
+func (s *exportSigner) Enabled() bool {
+	return len(s.key) > 0
+}
+
+func (s *exportSigner) Sign(body []byte, signedAt time.Time) string {
+	t := signedAt.Unix()
+	return "t=" + strconv.FormatInt(t, 10) + ",v1=" + hex.EncodeToString(s.mac(t, body))
+}
+
+func (s *exportSigner) Verify(body []byte, signature string) (bool, time.Time, error) {
+	t, mac, err := parseExportSignature(signature)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	if !s.Enabled() {
+		return false, time.Time{}, apperrors.InvalidRequest("Export signing is not configured on this server.")
+	}
+	return hmac.Equal(mac, s.mac(t, body)), time.Unix(t, 0).UTC(), nil
+}
+
+func (s *exportSigner) mac(t int64, body []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(strconv.FormatInt(t, 10) + "."))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+func parseExportSignature(signature string) (int64, []byte, error) {
+	malformed := apperrors.InvalidFieldFormat("signature", "t=<unix seconds>,v1=<hex>")
+
+	var t int64
+	var mac []byte
+	for _, part := range strings.Split(strings.TrimSpace(signature), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return 0, nil, malformed
+		}
+		var err error
+		switch name {
+		case "t":
+			t, err = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			mac, err = hex.DecodeString(value)
+		}
+		if err != nil {
+			return 0, nil, malformed
+		}
+	}
+	if t <= 0 || len(mac) != sha256.Size {
+		return 0, nil, malformed
+	}
+	return t, mac, nil
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportSignerVerify(t *testing.T) {
+	signer := NewExportSigner("test-key")
+	signedAt := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	body := []byte("Date,Description,Cost\r\n2026-03-13,Dinner,42.00\r\n")
+	signature := signer.Sign(body, signedAt)
+
+	tests := []struct {
+		name      string
+		signer    ExportSigner
+		body      []byte
+		signature string
+		valid     bool
+		wantErr   bool
+	}{
+		{name: "untouched file", signer: signer, body: body, signature: signature, valid: true},
+		{name: "edited amount", signer: signer, body: []byte("Date,Description,Cost\r\n2026-03-13,Dinner,92.00\r\n"), signature: signature},
+		{name: "signed with another key", signer: NewExportSigner("other-key"), body: body, signature: signature},
+		{name: "backdated timestamp", signer: signer, body: body, signature: strings.Replace(signature, strconv.FormatInt(signedAt.Unix(), 10), "1700000000", 1)},
+		{name: "malformed signature", signer: signer, body: body, signature: "v1=abc", wantErr: true},
+		{name: "signing disabled", signer: NewExportSigner(""), body: body, signature: signature, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, at, err := tt.signer.Verify(tt.body, tt.signature)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if valid != tt.valid {
+				t.Errorf("Verify() valid = %v, expected %v", valid, tt.valid)
+			}
+			if tt.valid && !at.Equal(signedAt) {
+				t.Errorf("Verify() signedAt = %v, expected %v", at, signedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+	"unwise-backend/supabase"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type FixtureService interface {
+	Scenarios() []models.FixtureScenario
+	Load(ctx context.Context, scenario string) (*models.FixtureLoadResult, error)
+}
+
+type fixtureService struct {
+	userRepo       repository.UserRepository
+	credentialRepo repository.CredentialRepository
+	groupService   GroupService
+	authAdmin      supabase.AdminClient
+	db             *database.DB
+}
+
+// Whichever of credentialRepo (local auth) and authAdmin (Supabase) is set
+// receives the fixture users' passwords.
+func NewFixtureService(userRepo repository.UserRepository, credentialRepo repository.CredentialRepository, groupService GroupService, authAdmin supabase.AdminClient, db *database.DB) FixtureService {
+	return &fixtureService{
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		groupService:   groupService,
+		authAdmin:      authAdmin,
+		db:             db,
+	}
+}
+
+type fixtureScenario struct {
+	models.FixtureScenario
+	users  []string
+	groups []fixtureGroup
+}
+
+type fixtureGroup struct {
+	name      string
+	groupType models.GroupType
+	currency  string
+	members   []string
+	expenses  []fixtureExpense
+}
+
+type fixtureExpense struct {
+	daysAgo int
+	models.InitialExpense
+}
+
+var fixtureScenarios = []fixtureScenario{
+	{
+		FixtureScenario: models.FixtureScenario{
+			Name:        "trip-with-debts",
+			Description: "Three friends back from a trip; Asha is owed money by both of the others.",
+		},
+		users: []string{"Asha", "Ben", "Chloe"},
+		groups: []fixtureGroup{{
+			name:      "Goa Trip",
+			groupType: models.GroupTypeTrip,
+			currency:  "INR",
+			members:   []string{"Asha", "Ben", "Chloe"},
+			expenses: []fixtureExpense{
+				{daysAgo: 6, InitialExpense: models.InitialExpense{Description: "Flights", TotalAmount: 18000, PaidBy: "Asha", Participants: []string{"Asha", "Ben", "Chloe"}}},
+				{daysAgo: 5, InitialExpense: models.InitialExpense{Description: "Beach shack dinner", TotalAmount: 2400, PaidBy: "Ben", Participants: []string{"Asha", "Ben", "Chloe"}}},
+				{daysAgo: 4, InitialExpense: models.InitialExpense{Description: "Scooter rental", TotalAmount: 1500, PaidBy: "Asha", Splits: map[string]float64{"Asha": 500, "Ben": 1000}}},
+				{daysAgo: 3, InitialExpense: models.InitialExpense{Description: "Hotel", TotalAmount: 9000, PaidBy: "Asha", Participants: []string{"Asha", "Ben", "Chloe"}}},
+				{daysAgo: 2, InitialExpense: models.InitialExpense{Description: "Snorkelling", TotalAmount: 3000, PaidBy: "Chloe", Participants: []string{"Ben", "Chloe"}}},
+			},
+		}},
+	},
+	{
+		FixtureScenario: models.FixtureScenario{
+			Name:        "multi-currency-home",
+			Description: "Three flatmates in a EUR home group with a few USD and GBP expenses.",
+		},
+		users: []string{"Dara", "Eli", "Farah"},
+		groups: []fixtureGroup{{
+			name:      "Flat 4B",
+			groupType: models.GroupTypeHome,
+			currency:  "EUR",
+			members:   []string{"Dara", "Eli", "Farah"},
+			expenses: []fixtureExpense{
+				{daysAgo: 20, InitialExpense: models.InitialExpense{Description: "Rent", TotalAmount: 1800, Currency: "EUR", PaidBy: "Dara", Participants: []string{"Dara", "Eli", "Farah"}}},
+				{daysAgo: 12, InitialExpense: models.InitialExpense{Description: "Groceries", TotalAmount: 96.30, Currency: "EUR", PaidBy: "Eli", Participants: []string{"Dara", "Eli", "Farah"}}},
+				{daysAgo: 9, InitialExpense: models.InitialExpense{Description: "Streaming subscription", TotalAmount: 22.99, Currency: "USD", PaidBy: "Farah", Participants: []string{"Dara", "Eli", "Farah"}}},
+				{daysAgo: 5, InitialExpense: models.InitialExpense{Description: "Train to London", TotalAmount: 240, Currency: "GBP", PaidBy: "Dara", Splits: map[string]float64{"Dara": 120, "Farah": 120}}},
+				{daysAgo: 1, InitialExpense: models.InitialExpense{Description: "Electricity", TotalAmount: 134.50, Currency: "EUR", PaidBy: "Farah", Participants: []string{"Dara", "Eli", "Farah"}}},
+			},
+		}},
+	},
+}
+
+func findFixtureScenario(name string) (fixtureScenario, bool) {
+	for _, sc := range fixtureScenarios {
+		if sc.Name == name {
+			return sc, true
+		}
+	}
+	return fixtureScenario{}, false
+}
+
+func (s *fixtureService) Scenarios() []models.FixtureScenario {
+	scenarios := make([]models.FixtureScenario, len(fixtureScenarios))
+	for i, sc := range fixtureScenarios {
+		scenarios[i] = sc.FixtureScenario
+	}
+	return scenarios
+}
+
+// Each load makes new accounts with a fresh email tag, so loads never clash.
+func (s *fixtureService) Load(ctx context.Context, name string) (*models.FixtureLoadResult, error) {
+	scenario, ok := findFixtureScenario(strings.ToLower(strings.TrimSpace(name)))
+	if !ok {
+		return nil, apperrors.NotFound("Fixture scenario")
+	}
+
+	tag, err := fixtureTag()
+	if err != nil {
+		return nil, apperrors.InternalError(err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(FixturePassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.InternalError(err)
+	}
+
+	result := &models.FixtureLoadResult{Scenario: scenario.Name, Password: FixturePassword}
+	users := make(map[string]models.FixtureUser, len(scenario.users))
+	for _, key := range scenario.users {
+		user, err := s.createUser(ctx, key, tag, string(hash))
+		if err != nil {
+			return nil, err
+		}
+		users[key] = *user
+		result.Users = append(result.Users, *user)
+	}
+
+	for _, fg := range scenario.groups {
+		group, err := s.createGroup(ctx, fg, users)
+		if err != nil {
+			return nil, err
+		}
+		result.Groups = append(result.Groups, *group)
+	}
+
+	zap.L().Info("Fixture scenario loaded",
+		zap.String("scenario", scenario.Name),
+		zap.String("tag", tag),
+		zap.Int("users", len(result.Users)),
+		zap.Int("groups", len(result.Groups)))
+	return result, nil
+}
+
+func (s *fixtureService) createUser(ctx context.Context, name, tag, passwordHash string) (*models.FixtureUser, error) {
+	verified := true
+	user := &models.User{
+		ID:            uuid.New().String(),
+		Name:          name,
+		Email:         fmt.Sprintf("%s+%s@%s", strings.ToLower(name), tag, FixtureEmailHost),
+		EmailVerified: &verified,
+	}
+
+	if s.authAdmin != nil {
+		_, err := s.authAdmin.CreateUser(ctx, supabase.CreateUserParams{
+			ID:           user.ID,
+			Email:        user.Email,
+			Password:     FixturePassword,
+			EmailConfirm: true,
+			UserMetadata: map[string]interface{}{"name": user.Name},
+		})
+		if err != nil {
+			return nil, apperrors.InternalError(fmt.Errorf("creating Supabase auth user: %w", err))
+		}
+	}
+
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.userRepo.WithTx(q).Create(ctx, user); err != nil {
+			return apperrors.DatabaseError("creating fixture user", err)
+		}
+		if s.credentialRepo != nil {
+			if err := s.credentialRepo.WithTx(q).SetPasswordHash(ctx, user.ID, passwordHash); err != nil {
+				return apperrors.DatabaseError("storing fixture credentials", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.FixtureUser{ID: user.ID, Name: user.Name, Email: user.Email}, nil
+}
+
+func (s *fixtureService) createGroup(ctx context.Context, fg fixtureGroup, users map[string]models.FixtureUser) (*models.Group, error) {
+	creator := users[fg.members[0]]
+	emails := make([]string, 0, len(fg.members)-1)
+	for _, key := range fg.members[1:] {
+		emails = append(emails, users[key].Email)
+	}
+
+	opts := models.CreateGroupOptions{Expenses: fixtureInitialExpenses(fg, users, time.Now())}
+	group, err := s.groupService.Create(ctx, creator.ID, fg.name, fg.groupType, emails, opts)
+	if err != nil {
+		return nil, err
+	}
+	if fg.currency != "" && fg.currency != group.DefaultCurrency {
+		if group, err = s.groupService.UpdateDefaultCurrency(ctx, group.ID, creator.ID, fg.currency); err != nil {
+			return nil, err
+		}
+	}
+	return group, nil
+}
+
+func fixtureInitialExpenses(fg fixtureGroup, users map[string]models.FixtureUser, now time.Time) []models.InitialExpense {
+	ref := func(key string) string {
+		if key == fg.members[0] {
+			return initialExpenseSelf
+		}
+		return users[key].Email
+	}
+
+	expenses := make([]models.InitialExpense, 0, len(fg.expenses))
+	for _, fe := range fg.expenses {
+		in := fe.InitialExpense
+		date := now.AddDate(0, 0, -fe.daysAgo)
+		in.Date = &date
+		if in.Currency == "" {
+			in.Currency = fg.currency
+		}
+		in.PaidBy = ref(in.PaidBy)
+		if len(in.Participants) > 0 {
+			participants := make([]string, len(in.Participants))
+			for i, key := range in.Participants {
+				participants[i] = ref(key)
+			}
+			in.Participants = participants
+		}
+		if len(in.Splits) > 0 {
+			splits := make(map[string]float64, len(in.Splits))
+			for key, amount := range in.Splits {
+				splits[ref(key)] = amount
+			}
+			in.Splits = splits
+		}
+		expenses = append(expenses, in)
+	}
+	return expenses
+}
+
+func fixtureTag() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating fixture tag: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
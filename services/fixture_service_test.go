@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+// TestFixtureScenariosBuild checks every scenario's expenses resolve their
+// members and add up, so a bad fixture fails here rather than on staging.
+func TestFixtureScenariosBuild(t *testing.T) {
+	for _, sc := range fixtureScenarios {
+		t.Run(sc.Name, func(t *testing.T) {
+			users := make(map[string]models.FixtureUser, len(sc.users))
+			for _, key := range sc.users {
+				users[key] = models.FixtureUser{ID: "id-" + key, Name: key, Email: key + "@" + FixtureEmailHost}
+			}
+
+			for _, fg := range sc.groups {
+				refs := map[string]string{initialExpenseSelf: users[fg.members[0]].ID}
+				for _, key := range fg.members {
+					if _, ok := users[key]; !ok {
+						t.Fatalf("group %q member %q is not a scenario user", fg.name, key)
+					}
+					if key != fg.members[0] {
+						refs[memberRefKey(users[key].Email)] = users[key].ID
+					}
+				}
+
+				for _, in := range fixtureInitialExpenses(fg, users, time.Now()) {
+					if _, _, err := buildInitialExpense("group", refs[initialExpenseSelf], fg.currency, refs, in); err != nil {
+						t.Errorf("%s: %v", in.Description, err)
+					}
+				}
+			}
+		})
+	}
+}
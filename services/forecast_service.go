@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+)
+
+type ForecastService interface {
+	GetGroupForecast(ctx context.Context, groupID, userID string) (*models.GroupForecast, error)
+}
+
+type forecastService struct {
+	groupRepo   repository.GroupRepository
+	expenseRepo repository.ExpenseReader
+	tagRepo     repository.TagRepository
+	userRepo    repository.UserRepository
+}
+
+func NewForecastService(groupRepo repository.GroupRepository, expenseRepo repository.ExpenseReader, tagRepo repository.TagRepository, userRepo repository.UserRepository) ForecastService {
+	return &forecastService{
+		groupRepo:   groupRepo,
+		expenseRepo: expenseRepo,
+		tagRepo:     tagRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *forecastService) GetGroupForecast(ctx context.Context, groupID, userID string) (*models.GroupForecast, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := reportSettings(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	monthStart := periodStart(settings, "month", time.Now())
+	historyFrom := monthStart.AddDate(0, -ForecastHistoryMonths, 0)
+
+	expenses, err := s.expenseRepo.GetGroupSpendingBetween(ctx, groupID, historyFrom, monthStart)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group spending", err)
+	}
+
+	expenseIDs := make([]string, len(expenses))
+	for i, e := range expenses {
+		expenseIDs[i] = e.ID
+	}
+	tags, err := s.tagRepo.GetTagNamesByExpenseIDs(ctx, expenseIDs)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting expense tags", err)
+	}
+	for i := range expenses {
+		expenses[i].Tags = tags[expenses[i].ID]
+	}
+
+	members, err := s.groupRepo.GetMembers(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group members", err)
+	}
+	names := make(map[string]string, len(members))
+	for _, m := range members {
+		names[m.ID] = m.Name
+	}
+
+	forecast := buildForecast(expenses, names, ForecastHistoryMonths, settings)
+	forecast.GroupID = groupID
+	forecast.Month = monthStart.AddDate(0, 1, 0).Format("2006-01")
+	forecast.HistoryFrom = historyFrom
+	forecast.HistoryTo = monthStart
+	forecast.GeneratedAt = time.Now()
+	return forecast, nil
+}
+
+type forecastBucket struct {
+	category    string
+	description string
+	currency    string
+	recurring   bool
+	amount      float64
+	occurrences int
+	shares      map[string]float64
+}
+
+// buildForecast projects expenses whose description repeats across months at
+// their latest amount and averages everything else per category (first tag).
+// Months are the user's report months.
+func buildForecast(expenses []models.Expense, names map[string]string, months int, settings models.ReportSettings) *models.GroupForecast {
+	net := make([]models.Expense, 0, len(expenses))
+	index := make(map[string]int, len(expenses))
+	for _, e := range expenses {
+		if e.Category == models.TransactionCategoryRefund {
+			if e.OriginalExpenseID != nil {
+				if i, ok := index[*e.OriginalExpenseID]; ok {
+					net[i].TotalAmount -= e.TotalAmount
+					net[i].Splits = subtractSplits(net[i].Splits, e.Splits)
+					continue
+				}
+			}
+			e.TotalAmount = -e.TotalAmount
+			e.Splits = subtractSplits(nil, e.Splits)
+		}
+		index[e.ID] = len(net)
+		net = append(net, e)
+	}
+
+	type series struct {
+		months     map[string]bool
+		latest     models.Expense
+		expenseIDs []string
+	}
+	byDescription := make(map[string]*series)
+	for _, e := range net {
+		if e.Category != models.TransactionCategoryExpense {
+			continue
+		}
+		key := e.Currency + "|" + strings.ToLower(strings.Join(strings.Fields(e.Description), " "))
+		sr, ok := byDescription[key]
+		if !ok {
+			sr = &series{months: make(map[string]bool)}
+			byDescription[key] = sr
+		}
+		sr.months[periodStart(settings, "month", e.DateISO).Format("2006-01")] = true
+		sr.expenseIDs = append(sr.expenseIDs, e.ID)
+		if !e.DateISO.Before(sr.latest.DateISO) {
+			sr.latest = e
+		}
+	}
+
+	buckets := make(map[string]*forecastBucket)
+	recurring := make(map[string]bool)
+	for key, sr := range byDescription {
+		if len(sr.months) < ForecastRecurringMinMonths {
+			continue
+		}
+		for _, id := range sr.expenseIDs {
+			recurring[id] = true
+		}
+		b := &forecastBucket{
+			category:    forecastCategory(sr.latest.Tags),
+			description: sr.latest.Description,
+			currency:    sr.latest.Currency,
+			recurring:   true,
+			amount:      sr.latest.TotalAmount,
+			occurrences: len(sr.months),
+			shares:      make(map[string]float64),
+		}
+		for _, split := range sr.latest.Splits {
+			b.shares[split.UserID] += split.Amount
+		}
+		buckets["recurring|"+key] = b
+	}
+
+	for _, e := range net {
+		if recurring[e.ID] {
+			continue
+		}
+		category := forecastCategory(e.Tags)
+		key := "category|" + e.Currency + "|" + category
+		b, ok := buckets[key]
+		if !ok {
+			b = &forecastBucket{category: category, currency: e.Currency, shares: make(map[string]float64)}
+			buckets[key] = b
+		}
+		b.amount += e.TotalAmount / float64(months)
+		b.occurrences++
+		for _, split := range e.Splits {
+			b.shares[split.UserID] += split.Amount / float64(months)
+		}
+	}
+
+	forecast := &models.GroupForecast{
+		HistoryMonths: months,
+		Items:         []models.ForecastItem{},
+		Totals:        []models.CurrencyAmount{},
+		Members:       []models.ForecastMemberTotal{},
+	}
+	totals := make(map[string]float64)
+	memberTotals := make(map[string]map[string]float64)
+	for _, b := range buckets {
+		amount := math.Round(b.amount*RoundingFactor) / RoundingFactor
+		if amount <= BalanceThreshold {
+			continue
+		}
+		item := models.ForecastItem{
+			Category:    b.category,
+			Description: b.description,
+			Currency:    b.currency,
+			Amount:      amount,
+			Recurring:   b.recurring,
+			Occurrences: b.occurrences,
+			Shares:      []models.ForecastShare{},
+		}
+		for userID, share := range b.shares {
+			share = math.Round(share*RoundingFactor) / RoundingFactor
+			if math.Abs(share) <= BalanceThreshold {
+				continue
+			}
+			item.Shares = append(item.Shares, models.ForecastShare{UserID: userID, Name: names[userID], Amount: share})
+			if memberTotals[b.currency] == nil {
+				memberTotals[b.currency] = make(map[string]float64)
+			}
+			memberTotals[b.currency][userID] += share
+		}
+		sort.Slice(item.Shares, func(i, j int) bool { return item.Shares[i].Amount > item.Shares[j].Amount })
+		forecast.Items = append(forecast.Items, item)
+		totals[b.currency] += amount
+	}
+	sort.Slice(forecast.Items, func(i, j int) bool {
+		a, b := forecast.Items[i], forecast.Items[j]
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		if a.Recurring != b.Recurring {
+			return a.Recurring
+		}
+		return a.Amount > b.Amount
+	})
+
+	for currency, total := range totals {
+		forecast.Totals = append(forecast.Totals, models.CurrencyAmount{Currency: currency, Amount: math.Round(total*RoundingFactor) / RoundingFactor})
+	}
+	sort.Slice(forecast.Totals, func(i, j int) bool { return forecast.Totals[i].Currency < forecast.Totals[j].Currency })
+
+	for currency, byUser := range memberTotals {
+		for userID, amount := range byUser {
+			forecast.Members = append(forecast.Members, models.ForecastMemberTotal{
+				UserID:   userID,
+				Name:     names[userID],
+				Currency: currency,
+				Amount:   math.Round(amount*RoundingFactor) / RoundingFactor,
+			})
+		}
+	}
+	sort.Slice(forecast.Members, func(i, j int) bool {
+		a, b := forecast.Members[i], forecast.Members[j]
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		return a.Amount > b.Amount
+	})
+
+	return forecast
+}
+
+func forecastCategory(tags []string) string {
+	if len(tags) == 0 {
+		return ForecastUncategorized
+	}
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return sorted[0]
+}
+
+func subtractSplits(splits, refunded []models.ExpenseSplit) []models.ExpenseSplit {
+	result := append([]models.ExpenseSplit(nil), splits...)
+	for _, r := range refunded {
+		result = append(result, models.ExpenseSplit{UserID: r.UserID, Amount: -r.Amount})
+	}
+	return result
+}
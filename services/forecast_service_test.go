@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"unwise-backend/models"
+)
+
+func forecastExpense(id, description string, amount float64, date string, tags []string, splits map[string]float64) models.Expense {
+	day, _ := time.Parse("2006-01-02", date)
+	e := models.Expense{
+		ID:          id,
+		Description: description,
+		TotalAmount: amount,
+		Currency:    "INR",
+		Category:    models.TransactionCategoryExpense,
+		DateISO:     day,
+		Tags:        tags,
+	}
+	for userID, share := range splits {
+		e.Splits = append(e.Splits, models.ExpenseSplit{UserID: userID, Amount: share})
+	}
+	return e
+}
+
+func TestBuildForecast(t *testing.T) {
+	original := "g1"
+	refund := forecastExpense("r1", "Groceries refund", 30, "2026-09-20", nil, map[string]float64{"A": 15, "B": 15})
+	refund.Category = models.TransactionCategoryRefund
+	refund.OriginalExpenseID = &original
+
+	expenses := []models.Expense{
+		forecastExpense("e1", "Rent", 1000, "2026-07-01", []string{"home"}, map[string]float64{"A": 500, "B": 500}),
+		forecastExpense("g1", "Groceries", 300, "2026-07-10", []string{"food"}, map[string]float64{"A": 150, "B": 150}),
+		forecastExpense("e2", "rent ", 1200, "2026-08-01", []string{"home"}, map[string]float64{"A": 600, "B": 600}),
+		forecastExpense("g2", "Takeaway", 90, "2026-08-12", []string{"food"}, map[string]float64{"A": 90}),
+		forecastExpense("g3", "Vegetables", 120, "2026-09-05", nil, map[string]float64{"B": 120}),
+		refund,
+	}
+
+	forecast := buildForecast(expenses, map[string]string{"A": "Alice", "B": "Bob"}, 3, models.DefaultReportSettings())
+
+	if len(forecast.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d: %+v", len(forecast.Items), forecast.Items)
+	}
+
+	rent := forecast.Items[0]
+	if !rent.Recurring || rent.Amount != 1200 || rent.Occurrences != 2 || rent.Category != "home" {
+		t.Errorf("unexpected recurring item: %+v", rent)
+	}
+
+	food := forecast.Items[1]
+	if food.Recurring || food.Category != "food" || food.Amount != 120 {
+		t.Errorf("expected food averaged to 120 after refund, got %+v", food)
+	}
+
+	other := forecast.Items[2]
+	if other.Category != ForecastUncategorized || other.Amount != 40 {
+		t.Errorf("expected uncategorized 40, got %+v", other)
+	}
+
+	if len(forecast.Totals) != 1 || forecast.Totals[0].Amount != 1360 {
+		t.Errorf("expected INR total 1360, got %+v", forecast.Totals)
+	}
+
+	expected := map[string]float64{"A": 600 + 75, "B": 600 + 45 + 40}
+	for _, m := range forecast.Members {
+		if m.Amount != expected[m.UserID] {
+			t.Errorf("member %s: expected %.2f, got %.2f", m.UserID, expected[m.UserID], m.Amount)
+		}
+	}
+}
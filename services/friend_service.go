@@ -2,7 +2,13 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
 	apperrors "unwise-backend/errors"
 	"unwise-backend/models"
@@ -15,18 +21,21 @@ type FriendService interface {
 	AddFriendByEmail(ctx context.Context, userID, email string) error
 	GetFriendsWithBalances(ctx context.Context, userID string) ([]models.FriendWithBalance, error)
 	RemoveFriend(ctx context.Context, userID, friendID string) error
-	SearchPotentialFriends(ctx context.Context, query string) ([]models.User, error)
+	SearchPotentialFriends(ctx context.Context, userID, query string) ([]models.User, error)
+	SuggestFromContacts(ctx context.Context, userID string, emailHashes []string) ([]models.ContactSuggestion, error)
+	GetSharedTransactions(ctx context.Context, userID, friendID string) (*models.User, []models.SharedTransaction, error)
+	GetBalanceHistory(ctx context.Context, userID, friendID, granularity string) (*models.FriendBalanceHistory, error)
 }
 
 type friendService struct {
 	friendRepo        repository.FriendRepository
 	userRepo          repository.UserRepository
 	groupRepo         repository.GroupRepository
-	expenseRepo       repository.ExpenseRepository
+	expenseRepo       repository.ExpenseReader
 	settlementService SettlementService
 }
 
-func NewFriendService(friendRepo repository.FriendRepository, userRepo repository.UserRepository, groupRepo repository.GroupRepository, expenseRepo repository.ExpenseRepository, settlementService SettlementService) FriendService {
+func NewFriendService(friendRepo repository.FriendRepository, userRepo repository.UserRepository, groupRepo repository.GroupRepository, expenseRepo repository.ExpenseReader, settlementService SettlementService) FriendService {
 	return &friendService{
 		friendRepo:        friendRepo,
 		userRepo:          userRepo,
@@ -36,19 +45,85 @@ func NewFriendService(friendRepo repository.FriendRepository, userRepo repositor
 	}
 }
 
-func (s *friendService) SearchPotentialFriends(ctx context.Context, query string) ([]models.User, error) {
+func (s *friendService) SearchPotentialFriends(ctx context.Context, userID, query string) ([]models.User, error) {
 	if query == "" {
 		return []models.User{}, nil
 	}
-	zap.L().Debug("Searching potential friends", zap.String("query", query))
-	users, err := s.userRepo.Search(ctx, query)
+	zap.L().Debug("Searching potential friends", zap.String("user_id", userID), zap.String("query", query))
+	matches, err := s.userRepo.Search(ctx, userID, NormalizeName(query))
 	if err != nil {
 		zap.L().Error("Failed to search potential friends", zap.String("query", query), zap.Error(err))
 		return nil, apperrors.DatabaseError("searching users", err)
 	}
+
+	users := make([]models.User, 0, len(matches))
+	for _, m := range matches {
+		if !m.SharesGroup && !strings.EqualFold(m.User.Email, strings.TrimSpace(query)) {
+			m.User.Email = maskEmail(m.User.Email)
+		}
+		users = append(users, m.User)
+	}
 	return users, nil
 }
 
+// SuggestFromContacts matches hashed contact emails against existing users.
+// Only the hashes leave the device, so the raw address book never reaches us.
+func (s *friendService) SuggestFromContacts(ctx context.Context, userID string, emailHashes []string) ([]models.ContactSuggestion, error) {
+	hashes, err := normalizeContactHashes(emailHashes)
+	if err != nil {
+		return nil, err
+	}
+	if len(hashes) == 0 {
+		return []models.ContactSuggestion{}, nil
+	}
+
+	matches, err := s.userRepo.MatchEmailHashes(ctx, userID, hashes)
+	if err != nil {
+		zap.L().Error("Failed to match contact hashes", zap.String("user_id", userID), zap.Int("hashes", len(hashes)), zap.Error(err))
+		return nil, apperrors.DatabaseError("matching contacts", err)
+	}
+	if matches == nil {
+		matches = []models.ContactSuggestion{}
+	}
+	return matches, nil
+}
+
+// normalizeContactHashes lowercases and dedupes hex SHA-256 hashes, rejecting
+// anything else so a client can't send raw emails by mistake.
+func normalizeContactHashes(emailHashes []string) ([]string, error) {
+	if len(emailHashes) > MaxContactHashes {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("At most %d contact hashes can be checked per request.", MaxContactHashes))
+	}
+
+	seen := make(map[string]bool, len(emailHashes))
+	hashes := make([]string, 0, len(emailHashes))
+	for _, h := range emailHashes {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if len(h) != sha256.Size*2 {
+			return nil, apperrors.InvalidRequest("Contact hashes must be hex-encoded SHA-256 digests.")
+		}
+		if _, err := hex.DecodeString(h); err != nil {
+			return nil, apperrors.InvalidRequest("Contact hashes must be hex-encoded SHA-256 digests.")
+		}
+		if !seen[h] {
+			seen[h] = true
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes, nil
+}
+
+// maskEmail keeps the first character of the local part and the domain,
+// e.g. "alice@example.com" becomes "a****@example.com".
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return ""
+	}
+	first, size := utf8.DecodeRuneInString(local)
+	return string(first) + strings.Repeat("*", max(utf8.RuneCountInString(local[size:]), 1)) + "@" + domain
+}
+
 func (s *friendService) AddFriendByEmail(ctx context.Context, userID, email string) error {
 	zap.L().Info("Adding friend by email", zap.String("user_id", userID), zap.String("friend_email", email))
 	friendUser, err := s.userRepo.GetByEmail(ctx, email)
@@ -87,6 +162,142 @@ func (s *friendService) RemoveFriend(ctx context.Context, userID, friendID strin
 	return nil
 }
 
+// GetSharedTransactions returns the other person and every transaction both
+// users take part in across their common groups.
+func (s *friendService) GetSharedTransactions(ctx context.Context, userID, friendID string) (*models.User, []models.SharedTransaction, error) {
+	friend, groupIDs, err := s.commonGroups(ctx, userID, friendID, "export shared transactions with")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactions, err := s.expenseRepo.GetSharedTransactions(ctx, userID, friendID, groupIDs)
+	if err != nil {
+		return nil, nil, apperrors.DatabaseError("getting shared transactions", err)
+	}
+	for i := range transactions {
+		transactions[i].Net = pairwiseNet(transactions[i])
+	}
+	return friend, transactions, nil
+}
+
+// commonGroups returns the other person and the IDs of the groups both users
+// are in, leaving out sandbox groups so they never count towards a balance.
+// Sharing no group is treated as not knowing the person at all.
+func (s *friendService) commonGroups(ctx context.Context, userID, friendID, action string) (*models.User, []string, error) {
+	if friendID == userID {
+		return nil, nil, apperrors.CannotAddSelf(action)
+	}
+
+	groups, err := s.groupRepo.GetCommonGroups(ctx, userID, friendID)
+	if err != nil {
+		return nil, nil, apperrors.DatabaseError("getting common groups", err)
+	}
+	if len(groups) == 0 {
+		return nil, nil, apperrors.FriendNotFound()
+	}
+
+	friend, err := s.userRepo.GetByID(ctx, friendID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, nil, apperrors.FriendNotFound()
+		}
+		return nil, nil, apperrors.DatabaseError("getting friend", err)
+	}
+
+	groupIDs := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if g.IsSandbox {
+			continue
+		}
+		groupIDs = append(groupIDs, g.ID)
+	}
+	return friend, groupIDs, nil
+}
+
+// balanceHistoryGranularities are the period lengths a balance history can be
+// bucketed by.
+var balanceHistoryGranularities = map[string]bool{"day": true, "week": true, "month": true}
+
+// GetBalanceHistory returns the running balance with a friend per currency,
+// one point per period with shared transactions. Weeks and months follow the
+// user's report settings. Transactions are attributed
+// as in GetSharedTransactions, so the last point of each series matches the
+// sum of the export's Net column.
+func (s *friendService) GetBalanceHistory(ctx context.Context, userID, friendID, granularity string) (*models.FriendBalanceHistory, error) {
+	granularity = strings.ToLower(strings.TrimSpace(granularity))
+	if granularity == "" {
+		granularity = "week"
+	}
+	if !balanceHistoryGranularities[granularity] {
+		return nil, apperrors.InvalidRequest("granularity must be one of: day, week, month.")
+	}
+
+	friend, groupIDs, err := s.commonGroups(ctx, userID, friendID, "chart balances with")
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := reportSettings(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.expenseRepo.GetSharedBalanceChanges(ctx, userID, friendID, groupIDs, granularity, settings.PeriodOffsetDays(granularity))
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting shared balance changes", err)
+	}
+
+	return &models.FriendBalanceHistory{
+		Friend: models.UserInfo{
+			ID:        friend.ID,
+			Name:      friend.Name,
+			AvatarURL: friend.AvatarURL,
+		},
+		Granularity: granularity,
+		Series:      buildBalanceSeries(buckets),
+	}, nil
+}
+
+// buildBalanceSeries turns period changes, ordered by period, into one running
+// balance series per currency.
+func buildBalanceSeries(buckets []models.BalanceHistoryBucket) []models.BalanceHistorySeries {
+	series := []models.BalanceHistorySeries{}
+	index := make(map[string]int)
+	running := make(map[string]float64)
+	for _, b := range buckets {
+		i, ok := index[b.Currency]
+		if !ok {
+			i = len(series)
+			index[b.Currency] = i
+			series = append(series, models.BalanceHistorySeries{Currency: b.Currency, Points: []models.BalanceHistoryPoint{}})
+		}
+		running[b.Currency] += b.Change
+		series[i].Points = append(series[i].Points, models.BalanceHistoryPoint{
+			Period:       b.Period.Format("2006-01-02"),
+			Change:       math.Round(b.Change*RoundingFactor) / RoundingFactor,
+			Balance:      math.Round(running[b.Currency]*RoundingFactor) / RoundingFactor,
+			Transactions: b.Transactions,
+		})
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Currency < series[j].Currency
+	})
+	return series
+}
+
+// pairwiseNet is what the friend owes the user for one transaction. Each
+// person's share is owed to the payers in proportion to what they paid, so
+// only the part of the friend's share the user covered (and vice versa)
+// counts between the two of them.
+func pairwiseNet(t models.SharedTransaction) float64 {
+	if math.Abs(t.TotalPaid) < BalanceThreshold {
+		return 0
+	}
+	net := (t.UserPaid*t.FriendShare - t.FriendPaid*t.UserShare) / t.TotalPaid
+	return math.Round(net*RoundingFactor) / RoundingFactor
+}
+
 func (s *friendService) GetFriendsWithBalances(ctx context.Context, userID string) ([]models.FriendWithBalance, error) {
 	zap.L().Debug("Getting friends with balances", zap.String("user_id", userID))
 	friends, err := s.friendRepo.List(ctx, userID)
@@ -109,11 +320,12 @@ func (s *friendService) GetFriendsWithBalances(ctx context.Context, userID strin
 		zap.L().Error("Failed to get user groups for friend balance calculation", zap.String("user_id", userID), zap.Error(err))
 		return nil, apperrors.DatabaseError("getting user groups", err)
 	}
+	userGroups = nonSandboxGroups(userGroups)
 
 	pairwiseBalances := make(map[string]map[string]map[string]float64)
 
 	for _, group := range userGroups {
-		settlements, err := s.settlementService.CalculateSettlements(ctx, group.ID, userID)
+		settlements, err := s.settlementService.CalculateSettlements(ctx, group.ID, userID, nil)
 		if err != nil {
 			zap.L().Warn("Failed to calculate settlements for group", zap.String("group_id", group.ID), zap.Error(err))
 			continue
@@ -213,3 +425,14 @@ func (s *friendService) GetFriendsWithBalances(ctx context.Context, userID strin
 
 	return results, nil
 }
+
+// nonSandboxGroups drops sandbox groups, whose balances are play money.
+func nonSandboxGroups(groups []models.Group) []models.Group {
+	kept := make([]models.Group, 0, len(groups))
+	for _, g := range groups {
+		if !g.IsSandbox {
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		email    string
+		expected string
+	}{
+		{"alice@example.com", "a****@example.com"},
+		{"b@example.com", "b*@example.com"},
+		{"émile@example.fr", "é****@example.fr"},
+		{"", ""},
+		{"not-an-email", ""},
+	}
+
+	for _, tt := range tests {
+		if got := maskEmail(tt.email); got != tt.expected {
+			t.Errorf("maskEmail(%q) = %q, expected %q", tt.email, got, tt.expected)
+		}
+	}
+}
+
+func TestPairwiseNet(t *testing.T) {
+	tests := []struct {
+		name     string
+		tx       models.SharedTransaction
+		expected float64
+	}{
+		{
+			name:     "user paid, split equally between two",
+			tx:       models.SharedTransaction{TotalPaid: 100, UserPaid: 100, UserShare: 50, FriendShare: 50},
+			expected: 50,
+		},
+		{
+			name:     "third person paid",
+			tx:       models.SharedTransaction{TotalPaid: 90, UserShare: 30, FriendShare: 30},
+			expected: 0,
+		},
+		{
+			name:     "both paid part of a three-way split",
+			tx:       models.SharedTransaction{TotalPaid: 90, UserPaid: 60, FriendPaid: 30, UserShare: 30, FriendShare: 30},
+			expected: 10,
+		},
+		{
+			name:     "friend settled up with the user",
+			tx:       models.SharedTransaction{TotalPaid: 25, FriendPaid: 25, UserShare: 25},
+			expected: -25,
+		},
+		{
+			name:     "nothing paid",
+			tx:       models.SharedTransaction{UserShare: 10},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := pairwiseNet(tt.tx); got != tt.expected {
+			t.Errorf("%s: pairwiseNet() = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeContactHashes(t *testing.T) {
+	hash := "ff8d9819fc0e12bf0d24892e45987e249a28dce836a85cad60e28eaaa8c6d976"
+
+	got, err := normalizeContactHashes([]string{hash, " " + strings.ToUpper(hash) + " "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != hash {
+		t.Errorf("expected duplicates to collapse to [%s], got %v", hash, got)
+	}
+
+	invalid := []string{"alice@example.com", hash[:63], strings.Replace(hash, "f", "g", 1)}
+	for _, h := range invalid {
+		if _, err := normalizeContactHashes([]string{h}); err == nil {
+			t.Errorf("expected %q to be rejected", h)
+		}
+	}
+
+	tooMany := make([]string, MaxContactHashes+1)
+	for i := range tooMany {
+		tooMany[i] = hash
+	}
+	if _, err := normalizeContactHashes(tooMany); err == nil {
+		t.Error("expected more than MaxContactHashes to be rejected")
+	}
+}
+
+func TestBuildBalanceSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+	buckets := []models.BalanceHistoryBucket{
+		{Period: day(3), Currency: "INR", Change: 300.004, Transactions: 2},
+		{Period: day(3), Currency: "EUR", Change: -12.5, Transactions: 1},
+		{Period: day(10), Currency: "INR", Change: 149.996, Transactions: 1},
+		{Period: day(17), Currency: "INR", Change: -450, Transactions: 1},
+	}
+
+	expected := []models.BalanceHistorySeries{
+		{Currency: "EUR", Points: []models.BalanceHistoryPoint{
+			{Period: "2024-06-03", Change: -12.5, Balance: -12.5, Transactions: 1},
+		}},
+		{Currency: "INR", Points: []models.BalanceHistoryPoint{
+			{Period: "2024-06-03", Change: 300, Balance: 300, Transactions: 2},
+			{Period: "2024-06-10", Change: 150, Balance: 450, Transactions: 1},
+			{Period: "2024-06-17", Change: -450, Balance: 0, Transactions: 1},
+		}},
+	}
+	if got := buildBalanceSeries(buckets); !reflect.DeepEqual(got, expected) {
+		t.Errorf("buildBalanceSeries = %+v, expected %+v", got, expected)
+	}
+
+	if got := buildBalanceSeries(nil); len(got) != 0 || got == nil {
+		t.Errorf("buildBalanceSeries(nil) = %#v, expected an empty slice", got)
+	}
+}
+
+func TestNonSandboxGroups(t *testing.T) {
+	groups := []models.Group{
+		{ID: "trip"},
+		{ID: "playground", IsSandbox: true},
+		{ID: "flat"},
+	}
+
+	got := nonSandboxGroups(groups)
+	ids := make([]string, len(got))
+	for i, g := range got {
+		ids[i] = g.ID
+	}
+	if expected := []string{"trip", "flat"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("nonSandboxGroups() = %v, expected %v", ids, expected)
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BackchargeMember adds a member who joined late to past expenses of the
+// group. Every expense is re-split to include them and the existing shares
+// shrink to match, all in one transaction so either every expense moves or
+// none does.
+func (s *groupService) BackchargeMember(ctx context.Context, groupID, userID, memberID string, expenseIDs []string) (*models.BackchargeResult, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(expenseIDs))
+	seen := make(map[string]bool, len(expenseIDs))
+	for _, id := range expenseIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, apperrors.MissingRequiredField("expense_ids")
+	}
+	if len(ids) > MaxBackchargeExpenses {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("At most %d expenses can be back-charged at once.", MaxBackchargeExpenses))
+	}
+
+	isMember, err := isGroupMember(ctx, s.groupRepo, groupID, memberID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking membership", err)
+	}
+	if !isMember {
+		return nil, apperrors.UserNotFound()
+	}
+	member, err := s.userRepo.GetByID(ctx, memberID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting member", err)
+	}
+
+	policy, err := s.groupRepo.GetExpenseEditPolicy(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group expense edit policy", err)
+	}
+
+	expenses := make([]*models.Expense, 0, len(ids))
+	for _, id := range ids {
+		expense, err := s.expenseRepo.GetByID(ctx, id)
+		if err != nil {
+			if apperrors.IsNotFoundError(err) {
+				return nil, apperrors.ExpenseNotFound()
+			}
+			return nil, apperrors.DatabaseError("getting expense", err)
+		}
+		if expense.GroupID != groupID {
+			return nil, apperrors.ExpenseNotFound()
+		}
+		if err := validateBackcharge(expense, memberID); err != nil {
+			return nil, err
+		}
+		if !canEditExpense(policy, expense, userID) {
+			return nil, apperrors.ExpenseEditNotAllowed(string(policy))
+		}
+		expenses = append(expenses, expense)
+	}
+
+	result := &models.BackchargeResult{
+		GroupID:  groupID,
+		Member:   models.UserInfo{ID: member.ID, Name: member.Name, AvatarURL: member.AvatarURL},
+		Expenses: make([]models.BackchargedExpense, 0, len(expenses)),
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		txRepo := s.expenseRepo.WithTx(q)
+
+		for _, expense := range expenses {
+			before, err := snapshotBalanceContributions(ctx, s.balanceEventRepo, q, expense.ID)
+			if err != nil {
+				return err
+			}
+
+			splits := backchargeSplits(expense, memberID)
+			if err := txRepo.DeleteSplits(ctx, expense.ID); err != nil {
+				return apperrors.DatabaseError("deleting existing splits", err)
+			}
+			var memberShare float64
+			for i := range splits {
+				splits[i].ID = uuid.New().String()
+				splits[i].ExpenseID = expense.ID
+				if err := txRepo.CreateSplit(ctx, &splits[i]); err != nil {
+					return apperrors.DatabaseError("creating expense split", err)
+				}
+				if splits[i].UserID == memberID {
+					memberShare = splits[i].Amount
+				}
+			}
+
+			if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionUpdated, expense.ID, before); err != nil {
+				return err
+			}
+
+			result.Expenses = append(result.Expenses, models.BackchargedExpense{
+				ExpenseID:   expense.ID,
+				Description: expense.Description,
+				Currency:    expense.Currency,
+				MemberShare: memberShare,
+				Splits:      splits,
+			})
+		}
+
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityMemberBackcharged,
+			Message: fmt.Sprintf("%s was added to %d past expense(s)", member.Name, len(expenses)),
+		}
+		if len(expenses) == 1 {
+			activity.ExpenseID = &expenses[0].ID
+			activity.Message = fmt.Sprintf("%s was added to '%s'", member.Name, expenses[0].Description)
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("Failed to back-charge member", zap.String("group_id", groupID), zap.String("member_id", memberID), zap.Error(err))
+		return nil, err
+	}
+
+	zap.L().Info("Member back-charged",
+		zap.String("group_id", groupID),
+		zap.String("member_id", memberID),
+		zap.Int("expenses", len(expenses)))
+	return result, nil
+}
+
+// validateBackcharge rejects expenses that cannot take another participant:
+// anything other than a plain expense, expenses with locked splits, itemized
+// receipts whose items decide the shares, and expenses the member already
+// shares.
+func validateBackcharge(expense *models.Expense, memberID string) error {
+	if expense.Category != models.TransactionCategoryExpense {
+		return apperrors.InvalidRequest(fmt.Sprintf("'%s' is not an expense and cannot be back-charged.", expense.Description))
+	}
+	if expense.LockedSplits {
+		return apperrors.InvalidRequest(fmt.Sprintf("'%s' has locked splits; unlock it first to back-charge it.", expense.Description))
+	}
+	if expense.Type == models.ExpenseTypeItemized {
+		return apperrors.InvalidRequest(fmt.Sprintf("'%s' is itemized; assign the member to its items instead.", expense.Description))
+	}
+	if len(expense.Splits) == 0 {
+		return apperrors.InvalidRequest(fmt.Sprintf("'%s' has no splits to re-divide.", expense.Description))
+	}
+	for _, sp := range expense.Splits {
+		if sp.UserID == memberID {
+			return apperrors.InvalidRequest(fmt.Sprintf("The member already shares '%s'.", expense.Description))
+		}
+	}
+	return nil
+}
+
+// backchargeSplits re-splits expense with memberID added. Equal expenses are
+// split equally again. Otherwise the member takes an average share and the
+// existing participants keep their relative proportions of the rest.
+func backchargeSplits(expense *models.Expense, memberID string) []models.ExpenseSplit {
+	if expense.Type == models.ExpenseTypeEqual {
+		userIDs := make([]string, 0, len(expense.Splits)+1)
+		for _, sp := range expense.Splits {
+			userIDs = append(userIDs, sp.UserID)
+		}
+		return equalSplits(expense.TotalAmount, append(userIDs, memberID))
+	}
+
+	memberShare := math.Round(expense.TotalAmount/float64(len(expense.Splits)+1)*RoundingFactor) / RoundingFactor
+	splits := proportionalSplits(expense.Splits, expense.TotalAmount, expense.TotalAmount-memberShare)
+	splits = append(splits, models.ExpenseSplit{UserID: memberID, Amount: memberShare})
+
+	if expense.Type == models.ExpenseTypePercentage && expense.TotalAmount != 0 {
+		for i := range splits {
+			percentage := math.Round(splits[i].Amount/expense.TotalAmount*100*RoundingFactor) / RoundingFactor
+			splits[i].Percentage = &percentage
+		}
+	}
+	return splits
+}
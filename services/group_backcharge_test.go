@@ -0,0 +1,95 @@
+package services
+
+import (
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestBackchargeSplits(t *testing.T) {
+	tests := []struct {
+		name        string
+		expense     models.Expense
+		expected    map[string]float64
+		percentages map[string]float64
+	}{
+		{
+			name: "Equal split gains a participant",
+			expense: models.Expense{
+				Type:        models.ExpenseTypeEqual,
+				TotalAmount: 100,
+				Splits:      []models.ExpenseSplit{{UserID: "A", Amount: 50}, {UserID: "B", Amount: 50}},
+			},
+			expected: map[string]float64{"A": 33.34, "B": 33.33, "N": 33.33},
+		},
+		{
+			name: "Exact amounts keep their proportions",
+			expense: models.Expense{
+				Type:        models.ExpenseTypeExactAmount,
+				TotalAmount: 90,
+				Splits:      []models.ExpenseSplit{{UserID: "A", Amount: 60}, {UserID: "B", Amount: 30}},
+			},
+			expected: map[string]float64{"A": 40, "B": 20, "N": 30},
+		},
+		{
+			name: "Percentages are recomputed",
+			expense: models.Expense{
+				Type:        models.ExpenseTypePercentage,
+				TotalAmount: 200,
+				Splits:      []models.ExpenseSplit{{UserID: "A", Amount: 150}, {UserID: "B", Amount: 50}},
+			},
+			expected:    map[string]float64{"A": 100, "B": 33.33, "N": 66.67},
+			percentages: map[string]float64{"A": 50, "B": 16.67, "N": 33.34},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splits := backchargeSplits(&tt.expense, "N")
+			if len(splits) != len(tt.expected) {
+				t.Fatalf("got %d splits, expected %d", len(splits), len(tt.expected))
+			}
+			sum := 0.0
+			for _, sp := range splits {
+				if sp.Amount != tt.expected[sp.UserID] {
+					t.Errorf("%s share = %.2f, expected %.2f", sp.UserID, sp.Amount, tt.expected[sp.UserID])
+				}
+				if tt.percentages != nil && (sp.Percentage == nil || *sp.Percentage != tt.percentages[sp.UserID]) {
+					t.Errorf("%s percentage = %v, expected %.2f", sp.UserID, sp.Percentage, tt.percentages[sp.UserID])
+				}
+				sum += sp.Amount
+			}
+			if diff := sum - tt.expense.TotalAmount; diff > AmountTolerance || diff < -AmountTolerance {
+				t.Errorf("splits sum to %.2f, expected %.2f", sum, tt.expense.TotalAmount)
+			}
+		})
+	}
+}
+
+func TestValidateBackcharge(t *testing.T) {
+	splits := []models.ExpenseSplit{{UserID: "A", Amount: 10}, {UserID: "B", Amount: 10}}
+	tests := []struct {
+		name    string
+		expense models.Expense
+		wantErr bool
+	}{
+		{name: "Plain expense", expense: models.Expense{Category: models.TransactionCategoryExpense, Type: models.ExpenseTypeEqual, Splits: splits}},
+		{name: "Repayment", expense: models.Expense{Category: models.TransactionCategoryRepayment, Type: models.ExpenseTypeExactAmount, Splits: splits}, wantErr: true},
+		{name: "Itemized", expense: models.Expense{Category: models.TransactionCategoryExpense, Type: models.ExpenseTypeItemized, Splits: splits}, wantErr: true},
+		{name: "No splits", expense: models.Expense{Category: models.TransactionCategoryExpense, Type: models.ExpenseTypeEqual}, wantErr: true},
+		{name: "Locked splits", expense: models.Expense{Category: models.TransactionCategoryExpense, Type: models.ExpenseTypeEqual, Splits: splits, LockedSplits: true}, wantErr: true},
+		{
+			name:    "Member already included",
+			expense: models.Expense{Category: models.TransactionCategoryExpense, Type: models.ExpenseTypeEqual, Splits: append(splits, models.ExpenseSplit{UserID: "N", Amount: 10})},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBackcharge(&tt.expense, "N"); (err != nil) != tt.wantErr {
+				t.Errorf("validateBackcharge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+func (s *groupService) GetCurrencyOverview(ctx context.Context, groupID, userID string) (*models.GroupCurrencyOverview, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.GroupNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting group", err)
+	}
+
+	spend, err := s.expenseRepo.GetGroupSpendByCurrency(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group spend by currency", err)
+	}
+
+	balances, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, nil)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group member balances", err)
+	}
+
+	return buildCurrencyOverview(group, spend, balances), nil
+}
+
+// buildCurrencyOverview lists every currency with transactions or an open
+// balance, the default currency first and the rest by spend. A currency's
+// outstanding amount is what its creditors are owed in total. Members owing
+// or owed in two or more currencies have mixed-currency debt.
+func buildCurrencyOverview(group *models.Group, spend []models.CurrencySpend, balances map[string]map[string]float64) *models.GroupCurrencyOverview {
+	usage := make(map[string]*models.GroupCurrencyUsage)
+	use := func(currency string) *models.GroupCurrencyUsage {
+		u, ok := usage[currency]
+		if !ok {
+			u = &models.GroupCurrencyUsage{Currency: currency, IsDefault: currency == group.DefaultCurrency}
+			usage[currency] = u
+		}
+		return u
+	}
+
+	for _, cs := range spend {
+		u := use(cs.Currency)
+		u.TotalSpend = math.Round(cs.TotalSpend*RoundingFactor) / RoundingFactor
+		u.TransactionCount = cs.TransactionCount
+	}
+
+	openCurrencies := make(map[string]int)
+	for userID, byCurrency := range balances {
+		for currency, balance := range byCurrency {
+			balance = math.Round(balance*RoundingFactor) / RoundingFactor
+			if math.Abs(balance) <= BalanceThreshold {
+				continue
+			}
+			openCurrencies[userID]++
+			if balance > 0 {
+				use(currency).Outstanding += balance
+			}
+		}
+	}
+
+	currencies := make([]models.GroupCurrencyUsage, 0, len(usage))
+	for _, u := range usage {
+		u.Outstanding = math.Round(u.Outstanding*RoundingFactor) / RoundingFactor
+		currencies = append(currencies, *u)
+	}
+	sort.Slice(currencies, func(i, j int) bool {
+		a, b := currencies[i], currencies[j]
+		if a.IsDefault != b.IsDefault {
+			return a.IsDefault
+		}
+		if a.TotalSpend != b.TotalSpend {
+			return a.TotalSpend > b.TotalSpend
+		}
+		return a.Currency < b.Currency
+	})
+
+	mixed := make([]models.UserInfo, 0)
+	for _, member := range group.Members {
+		if openCurrencies[member.ID] > 1 {
+			mixed = append(mixed, models.UserInfo{ID: member.ID, Name: member.Name, AvatarURL: member.AvatarURL})
+		}
+	}
+
+	return &models.GroupCurrencyOverview{
+		GroupID:              group.ID,
+		DefaultCurrency:      group.DefaultCurrency,
+		Currencies:           currencies,
+		MultiCurrency:        len(currencies) > 1 || len(currencies) == 1 && !currencies[0].IsDefault,
+		MixedCurrencyDebt:    len(mixed) > 0,
+		MembersWithMixedDebt: mixed,
+	}
+}
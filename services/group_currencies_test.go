@@ -0,0 +1,96 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestBuildCurrencyOverview(t *testing.T) {
+	group := &models.Group{
+		ID:              "g1",
+		DefaultCurrency: "INR",
+		Members:         []models.User{{ID: "A", Name: "Asha"}, {ID: "B", Name: "Ben"}, {ID: "C", Name: "Chen"}},
+	}
+
+	tests := []struct {
+		name          string
+		spend         []models.CurrencySpend
+		balances      map[string]map[string]float64
+		expected      []models.GroupCurrencyUsage
+		multiCurrency bool
+		mixedMembers  []string
+	}{
+		{
+			name:     "Empty group",
+			expected: []models.GroupCurrencyUsage{},
+		},
+		{
+			name:  "Default currency only",
+			spend: []models.CurrencySpend{{Currency: "INR", TotalSpend: 1200, TransactionCount: 3}},
+			balances: map[string]map[string]float64{
+				"A": {"INR": 400},
+				"B": {"INR": -400},
+				"C": {"INR": 0.004},
+			},
+			expected: []models.GroupCurrencyUsage{
+				{Currency: "INR", IsDefault: true, TotalSpend: 1200, TransactionCount: 3, Outstanding: 400},
+			},
+		},
+		{
+			name:  "Only a foreign currency",
+			spend: []models.CurrencySpend{{Currency: "EUR", TotalSpend: 50, TransactionCount: 1}},
+			balances: map[string]map[string]float64{
+				"A": {"EUR": 25},
+				"B": {"EUR": -25},
+			},
+			expected: []models.GroupCurrencyUsage{
+				{Currency: "EUR", TotalSpend: 50, TransactionCount: 1, Outstanding: 25},
+			},
+			multiCurrency: true,
+		},
+		{
+			name: "Mixed currency debt",
+			spend: []models.CurrencySpend{
+				{Currency: "EUR", TotalSpend: 90, TransactionCount: 2},
+				{Currency: "INR", TotalSpend: 600, TransactionCount: 2},
+				{Currency: "USD", TotalSpend: 120.004, TransactionCount: 1},
+			},
+			balances: map[string]map[string]float64{
+				"A": {"INR": 300, "EUR": -30},
+				"B": {"INR": -300, "USD": 0},
+				"C": {"EUR": 30, "USD": 0},
+			},
+			expected: []models.GroupCurrencyUsage{
+				{Currency: "INR", IsDefault: true, TotalSpend: 600, TransactionCount: 2, Outstanding: 300},
+				{Currency: "USD", TotalSpend: 120, TransactionCount: 1},
+				{Currency: "EUR", TotalSpend: 90, TransactionCount: 2, Outstanding: 30},
+			},
+			multiCurrency: true,
+			mixedMembers:  []string{"A"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overview := buildCurrencyOverview(group, tt.spend, tt.balances)
+			if !reflect.DeepEqual(overview.Currencies, tt.expected) {
+				t.Errorf("currencies = %+v, expected %+v", overview.Currencies, tt.expected)
+			}
+			if overview.MultiCurrency != tt.multiCurrency {
+				t.Errorf("multi_currency = %v, expected %v", overview.MultiCurrency, tt.multiCurrency)
+			}
+			var mixed []string
+			for _, member := range overview.MembersWithMixedDebt {
+				mixed = append(mixed, member.ID)
+			}
+			if !reflect.DeepEqual(mixed, tt.mixedMembers) {
+				t.Errorf("members with mixed debt = %v, expected %v", mixed, tt.mixedMembers)
+			}
+			if overview.MixedCurrencyDebt != (len(tt.mixedMembers) > 0) {
+				t.Errorf("mixed_currency_debt = %v", overview.MixedCurrencyDebt)
+			}
+		})
+	}
+}
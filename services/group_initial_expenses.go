@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"unwise-backend/models"
+
+	"github.com/google/uuid"
+)
+
+// initialExpenseSelf refers to the group's creator in an InitialExpense.
+const initialExpenseSelf = "me"
+
+// memberRefKey normalises a member reference, so emails and placeholder
+// names match regardless of case and surrounding spaces.
+func memberRefKey(ref string) string {
+	return strings.ToLower(NormalizeName(ref))
+}
+
+// buildInitialExpense turns an InitialExpense into an expense of the new
+// group, resolving member references through refs. The payer defaults to the
+// creator and, without splits or participants, everyone in refs shares the
+// cost equally.
+func buildInitialExpense(groupID, userID, currency string, refs map[string]string, in models.InitialExpense) (*models.Expense, []models.ExpenseSplit, error) {
+	description := strings.TrimSpace(in.Description)
+	if len(description) < MinDescriptionLength || len(description) > MaxDescriptionLength {
+		return nil, nil, fmt.Errorf("description must be between %d and %d characters", MinDescriptionLength, MaxDescriptionLength)
+	}
+	amount := math.Round(in.TotalAmount*RoundingFactor) / RoundingFactor
+	if amount <= 0 {
+		return nil, nil, fmt.Errorf("total_amount must be greater than zero")
+	}
+
+	resolve := func(ref string) (string, error) {
+		if id, ok := refs[memberRefKey(ref)]; ok {
+			return id, nil
+		}
+		return "", fmt.Errorf("'%s' is not a member of the group", ref)
+	}
+
+	payerRef := in.PaidBy
+	if strings.TrimSpace(payerRef) == "" {
+		payerRef = initialExpenseSelf
+	}
+	payerID, err := resolve(payerRef)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	expenseType := models.ExpenseTypeEqual
+	var splits []models.ExpenseSplit
+	switch {
+	case len(in.Splits) > 0 && len(in.Participants) > 0:
+		return nil, nil, fmt.Errorf("send either splits or participants, not both")
+	case len(in.Splits) > 0:
+		expenseType = models.ExpenseTypeExactAmount
+		seen := make(map[string]bool, len(in.Splits))
+		total := 0.0
+		for ref, share := range in.Splits {
+			id, err := resolve(ref)
+			if err != nil {
+				return nil, nil, err
+			}
+			if seen[id] {
+				return nil, nil, fmt.Errorf("'%s' is listed more than once in splits", ref)
+			}
+			seen[id] = true
+			share = math.Round(share*RoundingFactor) / RoundingFactor
+			if share < 0 {
+				return nil, nil, fmt.Errorf("split for '%s' cannot be negative", ref)
+			}
+			total += share
+			splits = append(splits, models.ExpenseSplit{UserID: id, Amount: share})
+		}
+		if math.Abs(total-amount) > AmountTolerance {
+			return nil, nil, fmt.Errorf("splits add up to %.2f instead of %.2f", total, amount)
+		}
+		sort.Slice(splits, func(i, j int) bool { return splits[i].UserID < splits[j].UserID })
+	default:
+		var ids []string
+		if len(in.Participants) > 0 {
+			for _, ref := range in.Participants {
+				id, err := resolve(ref)
+				if err != nil {
+					return nil, nil, err
+				}
+				ids = append(ids, id)
+			}
+		} else {
+			for _, id := range refs {
+				ids = append(ids, id)
+			}
+		}
+		splits = equalSplits(amount, ids)
+	}
+
+	if c := strings.ToUpper(strings.TrimSpace(in.Currency)); c != "" {
+		currency = c
+	}
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+
+	expenseID := uuid.New().String()
+	expense := &models.Expense{
+		ID:              expenseID,
+		GroupID:         groupID,
+		PaidByUserID:    &payerID,
+		CreatedByUserID: &userID,
+		TotalAmount:     amount,
+		Currency:        currency,
+		Description:     description,
+		Type:            expenseType,
+		Category:        models.TransactionCategoryExpense,
+		DateISO:         date,
+		Date:            date.Format("2006-01-02"),
+		Time:            date.Format("15:04"),
+		Payers: []models.ExpensePayer{
+			{
+				ID:         uuid.New().String(),
+				ExpenseID:  expenseID,
+				UserID:     payerID,
+				AmountPaid: amount,
+			},
+		},
+	}
+	for i := range splits {
+		splits[i].ID = uuid.New().String()
+		splits[i].ExpenseID = expenseID
+	}
+	return expense, splits, nil
+}
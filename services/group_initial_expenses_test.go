@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestBuildInitialExpense(t *testing.T) {
+	refs := map[string]string{
+		"me":            "u1",
+		"asha@mail.com": "u2",
+		"priya":         "p1",
+	}
+
+	tests := []struct {
+		name     string
+		in       models.InitialExpense
+		payer    string
+		expected map[string]float64
+		wantErr  bool
+	}{
+		{
+			name:     "Everyone shares by default",
+			in:       models.InitialExpense{Description: "Groceries", TotalAmount: 100},
+			payer:    "u1",
+			expected: map[string]float64{"p1": 33.34, "u1": 33.33, "u2": 33.33},
+		},
+		{
+			name:     "Participants by email and placeholder name",
+			in:       models.InitialExpense{Description: "Taxi", TotalAmount: 40, PaidBy: " Asha@Mail.com", Participants: []string{"asha@mail.com", "Priya"}},
+			payer:    "u2",
+			expected: map[string]float64{"u2": 20, "p1": 20},
+		},
+		{
+			name:     "Exact splits",
+			in:       models.InitialExpense{Description: "Deposit", TotalAmount: 500, PaidBy: "priya", Splits: map[string]float64{"me": 300, "priya": 200}},
+			payer:    "p1",
+			expected: map[string]float64{"u1": 300, "p1": 200},
+		},
+		{name: "Unknown member", in: models.InitialExpense{Description: "Taxi", TotalAmount: 40, Participants: []string{"rahul"}}, wantErr: true},
+		{name: "Splits do not add up", in: models.InitialExpense{Description: "Deposit", TotalAmount: 500, Splits: map[string]float64{"me": 300}}, wantErr: true},
+		{name: "Same member twice in splits", in: models.InitialExpense{Description: "Deposit", TotalAmount: 500, Splits: map[string]float64{"me": 250, "ME": 250}}, wantErr: true},
+		{name: "Splits and participants", in: models.InitialExpense{Description: "Deposit", TotalAmount: 10, Splits: map[string]float64{"me": 10}, Participants: []string{"me"}}, wantErr: true},
+		{name: "Zero amount", in: models.InitialExpense{Description: "Nothing", TotalAmount: 0}, wantErr: true},
+		{name: "Short description", in: models.InitialExpense{Description: "x", TotalAmount: 10}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expense, splits, err := buildInitialExpense("g1", "u1", "INR", refs, tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildInitialExpense() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(expense.Payers) != 1 || expense.Payers[0].UserID != tt.payer || expense.Payers[0].AmountPaid != expense.TotalAmount {
+				t.Errorf("expected %s to pay %.2f, got %+v", tt.payer, expense.TotalAmount, expense.Payers)
+			}
+			if len(splits) != len(tt.expected) {
+				t.Fatalf("expected %d splits, got %+v", len(tt.expected), splits)
+			}
+			for _, split := range splits {
+				if split.Amount != tt.expected[split.UserID] {
+					t.Errorf("split for %s = %.2f, expected %.2f", split.UserID, split.Amount, tt.expected[split.UserID])
+				}
+				if split.ExpenseID != expense.ID {
+					t.Errorf("split not linked to expense %s", expense.ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// groupLanguages are the languages a group can default to, by code. The
+// names are used in AI prompts and activity messages.
+var groupLanguages = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"hi": "Hindi",
+}
+
+// normalizeGroupLanguage accepts a language code or tag ("de", "de-CH",
+// "ES") and returns the supported base code.
+func normalizeGroupLanguage(language string) (string, error) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(language)), "-")
+	if base == "" {
+		return "", apperrors.MissingRequiredField("default_language")
+	}
+	if _, ok := groupLanguages[base]; !ok {
+		return "", apperrors.InvalidRequest("default_language must be one of: en, es, fr, de, hi.")
+	}
+	return base, nil
+}
+
+// UpdateDefaultLanguage sets the language the group's AI explanations,
+// exports and notifications are written in. Cached explanations are cleared
+// so they are regenerated in the new language.
+func (s *groupService) UpdateDefaultLanguage(ctx context.Context, groupID, userID, language string) (*models.Group, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	language, err := normalizeGroupLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.groupRepo.WithTx(q).UpdateDefaultLanguage(ctx, groupID, language); err != nil {
+			return apperrors.DatabaseError("updating group default language", err)
+		}
+		if err := s.expenseRepo.WithTx(q).ClearGroupExplanations(ctx, groupID); err != nil {
+			return apperrors.DatabaseError("clearing expense explanations", err)
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityLanguageUpdated,
+			Message: fmt.Sprintf("Group language set to %s", groupLanguages[language]),
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetByID(ctx, groupID)
+}
+
+// groupLanguage returns the group's default language, falling back to
+// English when it cannot be read.
+func groupLanguage(ctx context.Context, groupRepo repository.GroupRepository, groupID string) string {
+	if groupID == "" {
+		return apperrors.DefaultLanguage
+	}
+	language, err := groupRepo.GetDefaultLanguage(ctx, groupID)
+	if err != nil {
+		zap.L().Warn("Failed to get group language, using the default", zap.String("group_id", groupID), zap.Error(err))
+		return apperrors.DefaultLanguage
+	}
+	if _, ok := groupLanguages[language]; !ok {
+		return apperrors.DefaultLanguage
+	}
+	return language
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeGroupLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "Code", input: "es", expected: "es"},
+		{name: "Tag with region", input: "de-CH", expected: "de"},
+		{name: "Upper case with spaces", input: " HI ", expected: "hi"},
+		{name: "Unsupported language", input: "pt-BR", wantErr: true},
+		{name: "Empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeGroupLanguage(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeGroupLanguage(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("normalizeGroupLanguage(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNotificationTemplatesCoverEveryLanguage(t *testing.T) {
+	args := map[notificationTemplate][]interface{}{
+		notifyNewExpense:         {"Dinner", 120.5, "INR"},
+		notifyRefund:             {"Dinner", 20.0, "INR"},
+		notifyComment:            {"Dinner"},
+		notifyReminder:           {"Asha", "INR 250.00", "Goa Trip"},
+		notifySettlement:         {"Asha", "Ben", 250.0, "INR"},
+		notifySettlementReversed: {250.0, "INR"},
+		notifyCover:              {"Asha", "Ben", 80.0, "INR"},
+		notifyExclusionRequested: {"Asha", "Dinner"},
+		notifyExclusionAccepted:  {"Dinner"},
+		notifyExclusionRejected:  {"Asha", "Dinner"},
+		notifyChangeRequested:    {"Asha", "Dinner"},
+		notifyChangeApplied:      {"Dinner"},
+		notifyChangeRejected:     {"Asha", "Dinner"},
+		notifyBalanceAlert:       {2400.0, "INR", 2000.0},
+		notifyStandingCreated:    {"Asha", 15000.0, "INR", "Ben", "Asha"},
+		notifyStandingRecorded:   {"Ben", "Asha", 15000.0, "INR"},
+		notifyStandingCancelled:  {"Asha", 15000.0, "INR", "Ben", "Asha"},
+	}
+
+	for language := range groupLanguages {
+		for template, templateArgs := range args {
+			if _, ok := notificationTemplates[language][template]; !ok {
+				t.Errorf("%s has no %s template", language, template)
+				continue
+			}
+			message := renderNotification(language, template, templateArgs...)
+			if strings.Contains(message, "%!") {
+				t.Errorf("%s %s rendered badly: %q", language, template, message)
+			}
+			for _, arg := range templateArgs {
+				if s, ok := arg.(string); ok && !strings.Contains(message, s) {
+					t.Errorf("%s %s dropped %q: %q", language, template, s, message)
+				}
+			}
+		}
+	}
+
+	if got := renderNotification("pt", notifyComment, "Dinner"); got != "New comment on Dinner" {
+		t.Errorf("unsupported language rendered %q, expected the English text", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"unwise-backend/database"
@@ -16,39 +18,83 @@ import (
 )
 
 type GroupService interface {
-	GetByID(ctx context.Context, groupID, userID string) (*models.Group, error)
+	GetByID(ctx context.Context, groupID, userID string, memberSort models.MemberSort) (*models.Group, error)
 	GetByUserID(ctx context.Context, userID string) ([]models.Group, error)
-	GetByUserIDWithBalances(ctx context.Context, userID string) ([]models.GroupWithBalances, error)
-	Create(ctx context.Context, userID string, name string, groupType models.GroupType, memberEmails []string) (*models.Group, error)
+	GetByUserIDWithBalances(ctx context.Context, userID string, includeArchived bool) ([]models.GroupWithBalances, error)
+	Create(ctx context.Context, userID string, name string, groupType models.GroupType, memberEmails []string, opts models.CreateGroupOptions) (*models.Group, error)
+	GetTemplates(ctx context.Context) []models.GroupTemplate
 	Update(ctx context.Context, groupID, userID string, name string) (*models.Group, error)
 	UpdateGroupAvatar(ctx context.Context, groupID, userID, avatarURL string) (*models.Group, error)
 	UpdateDefaultCurrency(ctx context.Context, groupID, userID, currency string) (*models.Group, error)
+	UpdateDefaultLanguage(ctx context.Context, groupID, userID, language string) (*models.Group, error)
+	GetTaxPresets(ctx context.Context) []models.TaxPresetInfo
+	UpdateTaxPreset(ctx context.Context, groupID, userID string, preset models.TaxPreset) (*models.Group, error)
+	GetLimits(ctx context.Context, groupID, userID string) (*models.GroupLimits, error)
+	UpdateLimits(ctx context.Context, groupID, userID string, limits *models.GroupLimits) (*models.GroupLimits, error)
+	GetUsage(ctx context.Context, groupID, userID string) (*models.GroupUsage, error)
+	UpdateExpenseEditPolicy(ctx context.Context, groupID, userID string, policy models.ExpenseEditPolicy) (*models.Group, error)
+	UpdateSettlementRounding(ctx context.Context, groupID, userID string, increment int) (*models.Group, error)
+	UpdateSettlementAlgorithm(ctx context.Context, groupID, userID string, algorithm models.SettlementAlgorithm) (*models.Group, error)
+	GetActivity(ctx context.Context, groupID, userID string) ([]models.GroupActivity, error)
 	Delete(ctx context.Context, groupID, userID string) error
-	AddMember(ctx context.Context, groupID, userID, newMemberEmail string) error
+	Archive(ctx context.Context, groupID, userID string) error
+	Unarchive(ctx context.Context, groupID, userID string) error
+	DismissArchiveSuggestion(ctx context.Context, groupID, userID string) error
+	AddMember(ctx context.Context, groupID, userID, newMemberEmail string) (*models.GroupInvite, error)
 	AddPlaceholderMember(ctx context.Context, groupID, userID, name string) error
 	RemoveMember(ctx context.Context, groupID, userID, memberToRemoveID string) error
-	GetTransactions(ctx context.Context, groupID, userID string) ([]models.Transaction, error)
-	CreateRepayment(ctx context.Context, groupID, payerID, receiverID string, amount float64) (*models.Expense, error)
-	CreateSettlement(ctx context.Context, groupID, requesterID, fromUserID, toUserID string, amount float64) (*models.Expense, error)
+	ConvertMemberToPlaceholder(ctx context.Context, groupID, userID, memberToRemoveID string) (*models.User, error)
+	BackchargeMember(ctx context.Context, groupID, userID, memberID string, expenseIDs []string) (*models.BackchargeResult, error)
+	GetTransactions(ctx context.Context, groupID, userID string, filter models.TransactionFilter) ([]models.Transaction, error)
+	GetTransactionPage(ctx context.Context, groupID, userID string, filter models.TransactionFilter) (*models.TransactionPage, error)
+	GetReceipts(ctx context.Context, groupID, userID string, limit, offset int) (*models.ReceiptGalleryPage, error)
+	GetCurrencyOverview(ctx context.Context, groupID, userID string) (*models.GroupCurrencyOverview, error)
+	CreateRepayment(ctx context.Context, groupID, payerID, receiverID string, amount float64, description string) (*models.Expense, error)
+	CreateSettlement(ctx context.Context, groupID, requesterID, fromUserID, toUserID string, amount float64, details models.SettlementDetails) (*models.Expense, error)
+	ReverseSettlement(ctx context.Context, groupID, userID, expenseID, reason string) (*models.Expense, error)
+	GetSettlementHistory(ctx context.Context, groupID, userID string) ([]models.SettlementHistoryEntry, error)
+	CreateCover(ctx context.Context, groupID, requesterID, payerID, beneficiaryID string, amount float64, note string, confirmOverLimit bool) (*models.Expense, error)
 	GetBalances(ctx context.Context, groupID, userID string) (*models.GroupBalancesResponse, error)
-	GetBalancesEdgeList(ctx context.Context, groupID, userID string) (*models.GroupBalancesEdgeResponse, error)
+	GetBalancesEdgeList(ctx context.Context, groupID, userID string, asOf *time.Time) (*models.GroupBalancesEdgeResponse, error)
 }
 
 type groupService struct {
-	groupRepo         repository.GroupRepository
-	userRepo          repository.UserRepository
-	expenseRepo       repository.ExpenseRepository
-	settlementService SettlementService
-	db                *database.DB
+	groupRepo            repository.GroupRepository
+	userRepo             repository.UserRepository
+	expenseRepo          repository.ExpenseRepository
+	tagRepo              repository.TagRepository
+	readRepo             repository.ReadRepository
+	activityRepo         repository.ActivityRepository
+	inviteRepo           repository.GroupInviteRepository
+	balanceEventRepo     repository.BalanceEventRepository
+	archiveRepo          repository.GroupArchiveRepository
+	reminderResponseRepo repository.ReminderResponseRepository
+	settlementService    SettlementService
+	notificationService  NotificationService
+	balanceAlertService  BalanceAlertService
+	quotaService         QuotaService
+	emailVerifier        EmailVerifier
+	db                   database.TxRunner
 }
 
-func NewGroupService(groupRepo repository.GroupRepository, userRepo repository.UserRepository, expenseRepo repository.ExpenseRepository, settlementService SettlementService, db *database.DB) GroupService {
+func NewGroupService(groupRepo repository.GroupRepository, userRepo repository.UserRepository, expenseRepo repository.ExpenseRepository, tagRepo repository.TagRepository, readRepo repository.ReadRepository, activityRepo repository.ActivityRepository, inviteRepo repository.GroupInviteRepository, balanceEventRepo repository.BalanceEventRepository, archiveRepo repository.GroupArchiveRepository, reminderResponseRepo repository.ReminderResponseRepository, settlementService SettlementService, notificationService NotificationService, balanceAlertService BalanceAlertService, quotaService QuotaService, emailVerifier EmailVerifier, db *database.DB) GroupService {
 	return &groupService{
-		groupRepo:         groupRepo,
-		userRepo:          userRepo,
-		expenseRepo:       expenseRepo,
-		settlementService: settlementService,
-		db:                db,
+		groupRepo:            groupRepo,
+		userRepo:             userRepo,
+		expenseRepo:          expenseRepo,
+		tagRepo:              tagRepo,
+		readRepo:             readRepo,
+		activityRepo:         activityRepo,
+		inviteRepo:           inviteRepo,
+		balanceEventRepo:     balanceEventRepo,
+		archiveRepo:          archiveRepo,
+		reminderResponseRepo: reminderResponseRepo,
+		settlementService:    settlementService,
+		notificationService:  notificationService,
+		balanceAlertService:  balanceAlertService,
+		quotaService:         quotaService,
+		emailVerifier:        emailVerifier,
+		db:                   db,
 	}
 }
 
@@ -56,7 +102,10 @@ func (s *groupService) requireMembership(ctx context.Context, groupID, userID st
 	return RequireGroupMembership(ctx, s.groupRepo, groupID, userID)
 }
 
-func (s *groupService) GetByID(ctx context.Context, groupID, userID string) (*models.Group, error) {
+func (s *groupService) GetByID(ctx context.Context, groupID, userID string, memberSort models.MemberSort) (*models.Group, error) {
+	if err := validateMemberSort(memberSort); err != nil {
+		return nil, err
+	}
 	if err := s.requireMembership(ctx, groupID, userID); err != nil {
 		return nil, err
 	}
@@ -90,6 +139,12 @@ func (s *groupService) GetByID(ctx context.Context, groupID, userID string) (*mo
 	}
 	group.HasDebts = hasDebts
 
+	stubs, err := s.groupRepo.GetRecurringStubs(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting recurring expense stubs", err)
+	}
+	group.RecurringExpenses = stubs
+
 	for i := range group.Members {
 		for _, balance := range balances {
 			if balance.UserID == group.Members[i].ID {
@@ -98,10 +153,52 @@ func (s *groupService) GetByID(ctx context.Context, groupID, userID string) (*mo
 			}
 		}
 	}
+	sortMembers(group.Members, memberSort)
 
 	return group, nil
 }
 
+func validateMemberSort(memberSort models.MemberSort) error {
+	switch memberSort.Field {
+	case "", models.MemberSortName, models.MemberSortBalance:
+	default:
+		return apperrors.InvalidRequestWithDetails("Invalid member sort field.", "Allowed values: name, balance")
+	}
+	return validateSortOrder(memberSort.Order)
+}
+
+func validateSortOrder(order models.SortOrder) error {
+	switch order {
+	case "", models.SortOrderAsc, models.SortOrderDesc:
+		return nil
+	}
+	return apperrors.InvalidRequestWithDetails("Invalid sort order.", "Allowed values: asc, desc")
+}
+
+func sortMembers(members []models.User, memberSort models.MemberSort) {
+	if memberSort.Field == "" {
+		return
+	}
+	desc := memberSort.Order == models.SortOrderDesc
+	sort.SliceStable(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if memberSort.Field == models.MemberSortBalance && a.Balance != b.Balance {
+			if desc {
+				return a.Balance > b.Balance
+			}
+			return a.Balance < b.Balance
+		}
+		nameA, nameB := strings.ToLower(a.Name), strings.ToLower(b.Name)
+		if nameA != nameB {
+			if desc && memberSort.Field == models.MemberSortName {
+				return nameA > nameB
+			}
+			return nameA < nameB
+		}
+		return a.ID < b.ID
+	})
+}
+
 func (s *groupService) GetByUserID(ctx context.Context, userID string) ([]models.Group, error) {
 	groups, err := s.groupRepo.GetByUserID(ctx, userID)
 	if err != nil {
@@ -114,14 +211,24 @@ func (s *groupService) GetByUserID(ctx context.Context, userID string) ([]models
 	return groups, nil
 }
 
-func (s *groupService) GetByUserIDWithBalances(ctx context.Context, userID string) ([]models.GroupWithBalances, error) {
+// GetByUserIDWithBalances lists the user's groups. Groups they archived are
+// left out unless includeArchived is set, in which case they are marked.
+func (s *groupService) GetByUserIDWithBalances(ctx context.Context, userID string, includeArchived bool) ([]models.GroupWithBalances, error) {
 	groups, err := s.groupRepo.GetGroupsDetailedByUserID(ctx, userID)
 	if err != nil {
 		return nil, apperrors.DatabaseError("getting detailed groups", err)
 	}
 
+	archived, err := s.archiveRepo.GetArchivedGroupIDs(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting archived groups", err)
+	}
+
 	result := make([]models.GroupWithBalances, 0, len(groups))
 	for _, group := range groups {
+		if archived[group.ID] && !includeArchived {
+			continue
+		}
 		var currentUserIDBalance float64
 		membersWithBalance := make([]models.GroupMemberWithBalance, 0, len(group.Members))
 
@@ -161,23 +268,54 @@ func (s *groupService) GetByUserIDWithBalances(ctx context.Context, userID strin
 				TotalNet: currentUserIDBalance,
 				State:    state,
 			},
+			Archived: archived[group.ID],
 		})
 	}
 
 	return result, nil
 }
 
-func (s *groupService) Create(ctx context.Context, userID string, name string, groupType models.GroupType, memberEmails []string) (*models.Group, error) {
+func (s *groupService) Create(ctx context.Context, userID string, name string, groupType models.GroupType, memberEmails []string, opts models.CreateGroupOptions) (*models.Group, error) {
+	var template *models.GroupTemplate
+	if opts.TemplateID != "" {
+		t, ok := findGroupTemplate(opts.TemplateID)
+		if !ok {
+			return nil, apperrors.InvalidRequest(fmt.Sprintf("Unknown group template '%s'.", opts.TemplateID))
+		}
+		template = &t
+		if groupType == "" {
+			groupType = t.Type
+		}
+	}
 	if groupType == "" {
 		groupType = models.GroupTypeOther
 	}
+	if len(opts.Expenses) > MaxInitialGroupExpenses {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("A new group can start with at most %d expenses.", MaxInitialGroupExpenses))
+	}
+	if err := s.quotaService.CheckGroups(ctx, userID, 1); err != nil {
+		return nil, err
+	}
 
 	group := &models.Group{
-		ID:   uuid.New().String(),
-		Name: name,
-		Type: groupType,
+		ID:        uuid.New().String(),
+		Name:      NormalizeName(name),
+		Type:      groupType,
+		IsSandbox: opts.Sandbox,
+	}
+
+	members := 1 + len(memberEmails) + len(opts.Placeholders)
+	if template != nil && len(template.PlaceholderSlots) > len(memberEmails) {
+		members += len(template.PlaceholderSlots) - len(memberEmails)
+	}
+	if err := s.quotaService.CheckMembers(ctx, userID, group.ID, members); err != nil {
+		return nil, err
+	}
+	if err := s.quotaService.CheckExpenses(ctx, userID, group.ID, len(opts.Expenses)); err != nil {
+		return nil, err
 	}
 
+	var expenseIDs []string
 	err := s.db.WithTx(ctx, func(q database.Querier) error {
 		txRepo := s.groupRepo.WithTx(q)
 		if err := txRepo.Create(ctx, group); err != nil {
@@ -189,6 +327,8 @@ func (s *groupService) Create(ctx context.Context, userID string, name string, g
 		}
 
 		txUserRepo := s.userRepo.WithTx(q)
+		refs := map[string]string{initialExpenseSelf: userID}
+		added := 0
 		for _, email := range memberEmails {
 			user, err := txUserRepo.GetByEmail(ctx, email)
 			if err != nil {
@@ -197,11 +337,45 @@ func (s *groupService) Create(ctx context.Context, userID string, name string, g
 				}
 				return apperrors.DatabaseError("finding user by email", err)
 			}
+			refs[memberRefKey(email)] = user.ID
 			if user.ID != userID {
 				if err := txRepo.AddMember(ctx, group.ID, user.ID); err != nil {
 					return apperrors.DatabaseError("adding member to group", err)
 				}
+				added++
+			}
+		}
+
+		for _, name := range opts.Placeholders {
+			if _, taken := refs[memberRefKey(name)]; taken {
+				return apperrors.InvalidRequest(fmt.Sprintf("Placeholder '%s' clashes with another member.", name))
+			}
+			placeholder := &models.User{
+				ID:            uuid.New().String(),
+				Name:          NormalizeName(name),
+				IsPlaceholder: true,
+			}
+			if err := txUserRepo.Create(ctx, placeholder); err != nil {
+				return apperrors.DatabaseError("creating placeholder user", err)
+			}
+			if err := txRepo.AddMember(ctx, group.ID, placeholder.ID); err != nil {
+				return apperrors.DatabaseError("adding placeholder member", err)
+			}
+			refs[memberRefKey(name)] = placeholder.ID
+		}
+
+		if template != nil {
+			if err := s.applyGroupTemplate(ctx, q, group.ID, *template, opts.Locale, added); err != nil {
+				return err
+			}
+		}
+
+		if len(opts.Expenses) > 0 {
+			ids, err := s.insertInitialExpenses(ctx, q, group.ID, userID, refs, opts.Expenses)
+			if err != nil {
+				return err
 			}
+			expenseIDs = ids
 		}
 		return nil
 	})
@@ -210,7 +384,110 @@ func (s *groupService) Create(ctx context.Context, userID string, name string, g
 		return nil, err
 	}
 
-	return s.groupRepo.GetByID(ctx, group.ID)
+	for _, expenseID := range expenseIDs {
+		markSeenByActor(ctx, s.readRepo, group.ID, userID, expenseID)
+	}
+
+	created, err := s.groupRepo.GetByID(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	if template != nil {
+		if created.RecurringExpenses, err = s.groupRepo.GetRecurringStubs(ctx, group.ID); err != nil {
+			return nil, apperrors.DatabaseError("getting recurring expense stubs", err)
+		}
+	}
+	return created, nil
+}
+
+// applyGroupTemplate sets up a new group from a template. Placeholder slots
+// already filled by invited members are skipped.
+func (s *groupService) applyGroupTemplate(ctx context.Context, q database.Querier, groupID string, template models.GroupTemplate, locale string, addedMembers int) error {
+	txRepo := s.groupRepo.WithTx(q)
+
+	if currency := currencyForLocale(locale); currency != "" {
+		if err := txRepo.UpdateDefaultCurrency(ctx, groupID, currency); err != nil {
+			return apperrors.DatabaseError("setting group default currency", err)
+		}
+		if err := txRepo.UpdateTaxPreset(ctx, groupID, taxPresetForCurrency(currency)); err != nil {
+			return apperrors.DatabaseError("setting group tax preset", err)
+		}
+	}
+
+	if len(template.Categories) > 0 {
+		if _, err := s.tagRepo.WithTx(q).EnsureTags(ctx, groupID, template.Categories); err != nil {
+			return apperrors.DatabaseError("creating template categories", err)
+		}
+	}
+
+	for _, stub := range template.RecurringExpenses {
+		stub.ID = uuid.New().String()
+		stub.GroupID = groupID
+		if err := txRepo.AddRecurringStub(ctx, &stub); err != nil {
+			return apperrors.DatabaseError("creating recurring expense stub", err)
+		}
+	}
+
+	txUserRepo := s.userRepo.WithTx(q)
+	for i := addedMembers; i < len(template.PlaceholderSlots); i++ {
+		placeholder := &models.User{
+			ID:            uuid.New().String(),
+			Name:          template.PlaceholderSlots[i],
+			IsPlaceholder: true,
+		}
+		if err := txUserRepo.Create(ctx, placeholder); err != nil {
+			return apperrors.DatabaseError("creating placeholder user", err)
+		}
+		if err := txRepo.AddMember(ctx, groupID, placeholder.ID); err != nil {
+			return apperrors.DatabaseError("adding placeholder member", err)
+		}
+	}
+	return nil
+}
+
+// insertInitialExpenses adds the expenses a group was created with, in the
+// group's default currency unless they name their own.
+func (s *groupService) insertInitialExpenses(ctx context.Context, q database.Querier, groupID, userID string, refs map[string]string, inputs []models.InitialExpense) ([]string, error) {
+	group, err := s.groupRepo.WithTx(q).GetByID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group for currency", err)
+	}
+	currency := group.DefaultCurrency
+	if currency == "" {
+		currency = "INR"
+	}
+
+	txRepo := s.expenseRepo.WithTx(q)
+	ids := make([]string, 0, len(inputs))
+	for i, in := range inputs {
+		expense, splits, err := buildInitialExpense(groupID, userID, currency, refs, in)
+		if err != nil {
+			return nil, apperrors.InvalidRequest(fmt.Sprintf("Expense %d: %v.", i+1, err))
+		}
+
+		if err := txRepo.Create(ctx, expense); err != nil {
+			return nil, apperrors.DatabaseError("creating initial expense", err)
+		}
+		for j := range expense.Payers {
+			if err := txRepo.CreatePayer(ctx, &expense.Payers[j]); err != nil {
+				return nil, apperrors.DatabaseError("creating initial expense payer", err)
+			}
+		}
+		for j := range splits {
+			if err := txRepo.CreateSplit(ctx, &splits[j]); err != nil {
+				return nil, apperrors.DatabaseError("creating initial expense split", err)
+			}
+		}
+		if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, expense.ID, nil); err != nil {
+			return nil, err
+		}
+		ids = append(ids, expense.ID)
+	}
+	return ids, nil
+}
+
+func (s *groupService) GetTemplates(ctx context.Context) []models.GroupTemplate {
+	return groupTemplates
 }
 
 func (s *groupService) Update(ctx context.Context, groupID, userID string, name string) (*models.Group, error) {
@@ -226,7 +503,7 @@ func (s *groupService) Update(ctx context.Context, groupID, userID string, name
 		return nil, apperrors.DatabaseError("getting group", err)
 	}
 
-	group.Name = name
+	group.Name = NormalizeName(name)
 	if err := s.groupRepo.Update(ctx, group); err != nil {
 		return nil, apperrors.DatabaseError("updating group", err)
 	}
@@ -262,40 +539,319 @@ func (s *groupService) UpdateDefaultCurrency(ctx context.Context, groupID, userI
 	return s.groupRepo.GetByID(ctx, groupID)
 }
 
+func (s *groupService) GetLimits(ctx context.Context, groupID, userID string) (*models.GroupLimits, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	limits, err := s.groupRepo.GetLimits(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group limits", err)
+	}
+	return limits, nil
+}
+
+// GetUsage counts the group's activity in the current calendar month (UTC),
+// including the AI calls its members made for it.
+func (s *groupService) GetUsage(ctx context.Context, groupID, userID string) (*models.GroupUsage, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	from, to := usageMonth(time.Now())
+	usage, err := s.groupRepo.GetUsage(ctx, groupID, from, to)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group usage", err)
+	}
+	return usage, nil
+}
+
+// usageMonth returns the start of now's calendar month in UTC and the start
+// of the next.
+func usageMonth(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 1, 0)
+}
+
+func (s *groupService) UpdateLimits(ctx context.Context, groupID, userID string, limits *models.GroupLimits) (*models.GroupLimits, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	if limits.MaxExpenseAmount != nil && *limits.MaxExpenseAmount <= 0 {
+		return nil, apperrors.InvalidAmount("max_expense_amount must be greater than zero.")
+	}
+	if limits.MaxDailyExpenses != nil && *limits.MaxDailyExpenses <= 0 {
+		return nil, apperrors.InvalidRequest("max_daily_expenses must be greater than zero.")
+	}
+	if limits.Action == "" {
+		limits.Action = models.GroupLimitActionReject
+	}
+	if limits.Action != models.GroupLimitActionReject && limits.Action != models.GroupLimitActionFlag {
+		return nil, apperrors.InvalidRequest("limit_action must be REJECT or FLAG.")
+	}
+
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.groupRepo.WithTx(q).UpdateLimits(ctx, groupID, limits); err != nil {
+			return apperrors.DatabaseError("updating group limits", err)
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityLimitsUpdated,
+			Message: describeGroupLimits(limits),
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetLimits(ctx, groupID)
+}
+
+func (s *groupService) UpdateExpenseEditPolicy(ctx context.Context, groupID, userID string, policy models.ExpenseEditPolicy) (*models.Group, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	switch policy {
+	case models.ExpenseEditPolicyAnyMember, models.ExpenseEditPolicyCreator, models.ExpenseEditPolicyCreatorOrPayer:
+	default:
+		return nil, apperrors.InvalidRequest("expense_edit_policy must be ANY_MEMBER, CREATOR or CREATOR_OR_PAYER.")
+	}
+
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.groupRepo.WithTx(q).UpdateExpenseEditPolicy(ctx, groupID, policy); err != nil {
+			return apperrors.DatabaseError("updating group expense edit policy", err)
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityEditPolicyUpdated,
+			Message: fmt.Sprintf("Expense edit policy set to %s", policy),
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetByID(ctx, groupID)
+}
+
+func (s *groupService) UpdateSettlementRounding(ctx context.Context, groupID, userID string, increment int) (*models.Group, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	switch increment {
+	case 0, 1, 5, 10, 50, 100, 500:
+	default:
+		return nil, apperrors.InvalidRequest("settlement_rounding must be 0 (exact), 1, 5, 10, 50, 100 or 500.")
+	}
+
+	message := fmt.Sprintf("Settlement suggestions rounded to the nearest %d", increment)
+	if increment == 0 {
+		message = "Settlement suggestions use exact amounts"
+	}
+
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.groupRepo.WithTx(q).UpdateSettlementRounding(ctx, groupID, increment); err != nil {
+			return apperrors.DatabaseError("updating group settlement rounding", err)
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityRoundingUpdated,
+			Message: message,
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetByID(ctx, groupID)
+}
+
+func (s *groupService) UpdateSettlementAlgorithm(ctx context.Context, groupID, userID string, algorithm models.SettlementAlgorithm) (*models.Group, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	algorithm = models.SettlementAlgorithm(strings.ToUpper(strings.TrimSpace(string(algorithm))))
+	if err := ValidateSettlementAlgorithm(algorithm); err != nil {
+		return nil, err
+	}
+
+	message := "Settlement suggestions use the fewest payments"
+	if algorithm == models.SettlementAlgorithmPairwise {
+		message = "Settlement suggestions follow who paid for whom"
+	}
+
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.groupRepo.WithTx(q).UpdateSettlementAlgorithm(ctx, groupID, algorithm); err != nil {
+			return apperrors.DatabaseError("updating group settlement algorithm", err)
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityAlgorithmUpdated,
+			Message: message,
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetByID(ctx, groupID)
+}
+
+func describeGroupLimits(limits *models.GroupLimits) string {
+	maxAmount := "none"
+	if limits.MaxExpenseAmount != nil {
+		maxAmount = fmt.Sprintf("%.2f", *limits.MaxExpenseAmount)
+	}
+	maxDaily := "none"
+	if limits.MaxDailyExpenses != nil {
+		maxDaily = fmt.Sprintf("%d", *limits.MaxDailyExpenses)
+	}
+	return fmt.Sprintf("Expense limits updated: max amount %s, max per day %s, over-limit action %s", maxAmount, maxDaily, limits.Action)
+}
+
+func (s *groupService) GetActivity(ctx context.Context, groupID, userID string) ([]models.GroupActivity, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	activities, err := s.activityRepo.GetByGroupID(ctx, groupID, GroupActivityLimit)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group activity", err)
+	}
+	return activities, nil
+}
+
+// Delete removes a settled group. The group is locked before the balance
+// check, so an expense created concurrently either commits first and blocks
+// the delete, or waits and then fails with GroupNotFound.
 func (s *groupService) Delete(ctx context.Context, groupID, userID string) error {
 	if err := s.requireMembership(ctx, groupID, userID); err != nil {
 		return err
 	}
 
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		txGroupRepo := s.groupRepo.WithTx(q)
+		if err := s.lockGroup(ctx, txGroupRepo, groupID); err != nil {
+			return err
+		}
+
+		balances, err := s.calculateBalances(ctx, groupID)
+		if err != nil {
+			return apperrors.DatabaseError("calculating balances", err)
+		}
+		if len(balances) > 0 {
+			return apperrors.CannotDeleteGroupWithDebts()
+		}
+
+		if err := txGroupRepo.Delete(ctx, groupID); err != nil {
+			return apperrors.DatabaseError("deleting group", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	forgetGroupMemberships(ctx, groupID)
+
+	return nil
+}
+
+// Archive hides a settled group from the caller's group list and dashboard
+// until someone adds a transaction to it. Other members are not affected.
+func (s *groupService) Archive(ctx context.Context, groupID, userID string) error {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return err
+	}
+
 	balances, err := s.calculateBalances(ctx, groupID)
 	if err != nil {
 		return apperrors.DatabaseError("calculating balances", err)
 	}
 	if len(balances) > 0 {
-		return apperrors.CannotDeleteGroupWithDebts()
+		return apperrors.OutstandingBalance("Settle up all balances before archiving this group.")
 	}
 
-	if err := s.groupRepo.Delete(ctx, groupID); err != nil {
-		return apperrors.DatabaseError("deleting group", err)
+	if err := s.archiveRepo.Archive(ctx, groupID, userID); err != nil {
+		return apperrors.DatabaseError("archiving group", err)
 	}
+	zap.L().Info("Group archived", zap.String("group_id", groupID), zap.String("user_id", userID))
+	return nil
+}
 
+func (s *groupService) Unarchive(ctx context.Context, groupID, userID string) error {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return err
+	}
+	if err := s.archiveRepo.Unarchive(ctx, groupID, userID); err != nil {
+		return apperrors.DatabaseError("unarchiving group", err)
+	}
 	return nil
 }
 
-func (s *groupService) AddMember(ctx context.Context, groupID, userID, newMemberEmail string) error {
+// DismissArchiveSuggestion stops the dashboard suggesting this group until it
+// has new activity and goes quiet again.
+func (s *groupService) DismissArchiveSuggestion(ctx context.Context, groupID, userID string) error {
 	if err := s.requireMembership(ctx, groupID, userID); err != nil {
 		return err
 	}
+	if err := s.archiveRepo.DismissSuggestion(ctx, groupID, userID); err != nil {
+		return apperrors.DatabaseError("dismissing archive suggestion", err)
+	}
+	return nil
+}
+
+func (s *groupService) AddMember(ctx context.Context, groupID, userID, newMemberEmail string) (*models.GroupInvite, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.quotaService.CheckMembers(ctx, userID, groupID, 1); err != nil {
+		return nil, err
+	}
 
 	zap.L().Info("Adding member to group", zap.String("group_id", groupID), zap.String("requested_by", userID), zap.String("email", newMemberEmail))
 
 	user, err := s.userRepo.GetByEmail(ctx, newMemberEmail)
 	if err != nil {
-		zap.L().Error("User lookup failed for email", zap.String("email", newMemberEmail), zap.Error(err))
 		if apperrors.IsNotFoundError(err) {
-			return apperrors.UserNotFoundByEmail(newMemberEmail)
+			invite, err := s.inviteRepo.Create(ctx, groupID, newMemberEmail, userID)
+			if err != nil {
+				return nil, apperrors.DatabaseError("creating group invite", err)
+			}
+			zap.L().Info("No account for email, saved pending group invite", zap.String("email", newMemberEmail), zap.String("group_id", groupID))
+			return invite, nil
 		}
-		return apperrors.DatabaseError("finding user by email", err)
+		zap.L().Error("User lookup failed for email", zap.String("email", newMemberEmail), zap.Error(err))
+		return nil, apperrors.DatabaseError("finding user by email", err)
 	}
 
 	zap.L().Info("Found user for group invitation", zap.String("email", user.Email), zap.String("user_id", user.ID), zap.String("group_id", groupID))
@@ -303,24 +859,28 @@ func (s *groupService) AddMember(ctx context.Context, groupID, userID, newMember
 	if err := s.groupRepo.AddMember(ctx, groupID, user.ID); err != nil {
 		zap.L().Error("Failed to add member to group", zap.String("user_id", user.ID), zap.String("group_id", groupID), zap.Error(err))
 		if apperrors.IsDuplicateError(err) {
-			return apperrors.AlreadyMember()
+			return nil, apperrors.AlreadyMember()
 		}
-		return apperrors.DatabaseError("adding member", err)
+		return nil, apperrors.DatabaseError("adding member", err)
 	}
+	forgetGroupMemberships(ctx, groupID)
 
 	zap.L().Info("Successfully added member to group", zap.String("user_id", user.ID), zap.String("group_id", groupID))
-	return nil
+	return nil, nil
 }
 
 func (s *groupService) AddPlaceholderMember(ctx context.Context, groupID, userID, name string) error {
 	if err := s.requireMembership(ctx, groupID, userID); err != nil {
 		return err
 	}
+	if err := s.quotaService.CheckMembers(ctx, userID, groupID, 1); err != nil {
+		return err
+	}
 
 	newUserID := uuid.New().String()
 	user := &models.User{
 		ID:            newUserID,
-		Name:          name,
+		Name:          NormalizeName(name),
 		Email:         "",
 		IsPlaceholder: true,
 	}
@@ -345,43 +905,227 @@ func (s *groupService) RemoveMember(ctx context.Context, groupID, userID, member
 		return err
 	}
 
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		txGroupRepo := s.groupRepo.WithTx(q)
+		if err := s.lockGroup(ctx, txGroupRepo, groupID); err != nil {
+			return err
+		}
+		if err := s.requireSettledMember(ctx, groupID, memberToRemoveID); err != nil {
+			return err
+		}
+		if err := txGroupRepo.RemoveMember(ctx, groupID, memberToRemoveID); err != nil {
+			return apperrors.DatabaseError("removing member", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	forgetGroupMemberships(ctx, groupID)
+
+	return nil
+}
+
+// ConvertMemberToPlaceholder removes a settled member but keeps their history:
+// a new placeholder with the same name and avatar takes over their payers,
+// splits and ledger entries in this group only, so old expenses still show
+// who was involved. The departed user can claim the placeholder if they rejoin.
+func (s *groupService) ConvertMemberToPlaceholder(ctx context.Context, groupID, userID, memberToRemoveID string) (*models.User, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.userRepo.GetByID(ctx, memberToRemoveID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting member", err)
+	}
+	if member.IsPlaceholder {
+		return nil, apperrors.InvalidRequest("Member is already a placeholder.")
+	}
+	isMember, err := isGroupMember(ctx, s.groupRepo, groupID, memberToRemoveID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking membership", err)
+	}
+	if !isMember {
+		return nil, apperrors.UserNotFound()
+	}
+
+	placeholder := &models.User{
+		ID:            uuid.New().String(),
+		Name:          member.Name,
+		AvatarURL:     member.AvatarURL,
+		IsPlaceholder: true,
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		txGroupRepo := s.groupRepo.WithTx(q)
+		if err := s.lockGroup(ctx, txGroupRepo, groupID); err != nil {
+			return err
+		}
+		if err := s.requireSettledMember(ctx, groupID, memberToRemoveID); err != nil {
+			return err
+		}
+
+		if err := s.userRepo.WithTx(q).Create(ctx, placeholder); err != nil {
+			return apperrors.DatabaseError("creating placeholder user", err)
+		}
+		if err := txGroupRepo.AddMember(ctx, groupID, placeholder.ID); err != nil {
+			return apperrors.DatabaseError("adding placeholder member", err)
+		}
+		if err := s.expenseRepo.WithTx(q).TransferGroupExpenses(ctx, groupID, memberToRemoveID, placeholder.ID); err != nil {
+			return apperrors.DatabaseError("transferring expenses", err)
+		}
+		if s.balanceEventRepo != nil {
+			if err := s.balanceEventRepo.WithTx(q).TransferGroupUser(ctx, groupID, memberToRemoveID, placeholder.ID); err != nil {
+				return apperrors.DatabaseError("transferring balance events", err)
+			}
+		}
+		if err := txGroupRepo.RemoveMember(ctx, groupID, memberToRemoveID); err != nil {
+			return apperrors.DatabaseError("removing member", err)
+		}
+
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityMemberConverted,
+			Message: fmt.Sprintf("%s left the group; their history is kept under a placeholder", member.Name),
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	forgetGroupMemberships(ctx, groupID)
+
+	zap.L().Info("Member converted to placeholder",
+		zap.String("group_id", groupID),
+		zap.String("member_id", memberToRemoveID),
+		zap.String("placeholder_id", placeholder.ID))
+	return placeholder, nil
+}
+
+// lockGroup takes the group lock that expense writes wait on, so balances
+// checked while holding it stay put until the transaction ends.
+func (s *groupService) lockGroup(ctx context.Context, txGroupRepo repository.GroupRepository, groupID string) error {
+	if err := txGroupRepo.LockForUpdate(ctx, groupID); err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.GroupNotFound()
+		}
+		return apperrors.DatabaseError("locking group", err)
+	}
+	return nil
+}
+
+func (s *groupService) requireSettledMember(ctx context.Context, groupID, memberID string) error {
 	balances, err := s.calculateBalances(ctx, groupID)
 	if err != nil {
 		return apperrors.DatabaseError("calculating balances", err)
 	}
 
 	for _, b := range balances {
-		if b.UserID == memberToRemoveID && math.Abs(b.OwedAmount) > BalanceThreshold {
+		if b.UserID == memberID && math.Abs(b.OwedAmount) > BalanceThreshold {
 			return apperrors.CannotRemoveMemberWithBalance(b.OwedAmount)
 		}
 	}
+	return nil
+}
 
-	if err := s.groupRepo.RemoveMember(ctx, groupID, memberToRemoveID); err != nil {
-		return apperrors.DatabaseError("removing member", err)
+func (s *groupService) GetTransactions(ctx context.Context, groupID, userID string, filter models.TransactionFilter) ([]models.Transaction, error) {
+	transactions, err := s.listTransactions(ctx, groupID, userID, filter)
+	if err != nil {
+		return nil, err
 	}
+	return paginateTransactions(transactions, filter.Limit, filter.Offset), nil
+}
 
-	return nil
+// GetTransactionPage is GetTransactions plus section headers when
+// filter.GroupBy is set. Subtotals are computed before pagination.
+func (s *groupService) GetTransactionPage(ctx context.Context, groupID, userID string, filter models.TransactionFilter) (*models.TransactionPage, error) {
+	switch filter.GroupBy {
+	case "", models.TransactionGroupByMonth, models.TransactionGroupByDay:
+	default:
+		return nil, apperrors.InvalidRequestWithDetails("Invalid group_by.", "Allowed values: month, day")
+	}
+	if filter.GroupBy != "" && filter.Sort.Field != "" && filter.Sort.Field != models.TransactionSortDate {
+		return nil, apperrors.InvalidRequest("group_by requires transactions sorted by date.")
+	}
+
+	transactions, err := s.listTransactions(ctx, groupID, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.TransactionPage{
+		Items:    paginateTransactions(transactions, filter.Limit, filter.Offset),
+		Sections: []models.TransactionSection{},
+	}
+	if filter.GroupBy != "" {
+		page.Sections = buildTransactionSections(transactions, filter.GroupBy, filter.Offset, len(page.Items))
+	}
+	return page, nil
 }
 
-func (s *groupService) GetTransactions(ctx context.Context, groupID, userID string) ([]models.Transaction, error) {
+func (s *groupService) listTransactions(ctx context.Context, groupID, userID string, filter models.TransactionFilter) ([]models.Transaction, error) {
 	if err := s.requireMembership(ctx, groupID, userID); err != nil {
 		return nil, err
 	}
 
-	transactions, err := s.expenseRepo.GetTransactionsByGroupID(ctx, groupID)
+	requiredTags, err := normalizeTagNames(filter.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTransactionFilter(filter); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.expenseRepo.GetTransactionsByGroupID(ctx, groupID, filter.Sort)
 	if err != nil {
 		return nil, apperrors.DatabaseError("getting transactions", err)
 	}
 
+	expenseIDs := make([]string, len(transactions))
+	for i := range transactions {
+		expenseIDs[i] = transactions[i].ID
+	}
+	tagsByExpense, err := s.tagRepo.GetTagNamesByExpenseIDs(ctx, expenseIDs)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting transaction tags", err)
+	}
+
+	readStates, err := s.readRepo.GetReadStates(ctx, groupID, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting transaction read states", err)
+	}
+
 	enrichedTransactions := make([]models.Transaction, 0, len(transactions))
 	userCache := make(map[string]*models.User)
 
 	for _, t := range transactions {
+		t.Tags = tagsByExpense[t.ID]
+		if !matchesAllTags(t.Tags, requiredTags) {
+			continue
+		}
+		if filter.EventID != "" && (t.EventID == nil || *t.EventID != filter.EventID) {
+			continue
+		}
+
 		enriched := t
+		enriched.SeenAt = readStates[t.ID].SeenAt
+		enriched.IsNew = readStates[t.ID].IsNew
 
-		if t.Category == models.TransactionCategoryPayment || t.Category == models.TransactionCategoryRepayment {
+		switch t.Category {
+		case models.TransactionCategoryPayment, models.TransactionCategoryRepayment:
 			enriched.Type = "repayment"
-		} else {
+		case models.TransactionCategoryRefund:
+			enriched.Type = "refund"
+		default:
 			enriched.Type = "expense"
 		}
 
@@ -428,39 +1172,175 @@ func (s *groupService) GetTransactions(ctx context.Context, groupID, userID stri
 			}
 		}
 
-		enriched.UserShare = math.Round(userSplitAmount*RoundingFactor) / RoundingFactor
-		enriched.UserIsPayer = userIsPayer
-		enriched.UserIsRecipient = userIsRecipient
+		enriched.UserShare = math.Round(userSplitAmount*RoundingFactor) / RoundingFactor
+		enriched.UserIsPayer = userIsPayer
+		enriched.UserIsRecipient = userIsRecipient
+
+		netAmount := userPaidAmount - userSplitAmount
+		enriched.UserNetAmount = math.Round(netAmount*RoundingFactor) / RoundingFactor
+		enriched.UserIsOwed = netAmount > BalanceThreshold
+		enriched.UserIsLent = netAmount > BalanceThreshold
+
+		for i := range enriched.Splits {
+			splitUser, err := s.getUserWithCache(ctx, enriched.Splits[i].UserID, userCache)
+			if err == nil {
+				enriched.Splits[i].UserName = splitUser.Name
+				enriched.Splits[i].UserEmail = splitUser.Email
+			}
+		}
+
+		enrichedTransactions = append(enrichedTransactions, enriched)
+	}
+
+	if filter.Sort.Field == models.TransactionSortNet {
+		desc := filter.Sort.Order != models.SortOrderAsc
+		sort.SliceStable(enrichedTransactions, func(i, j int) bool {
+			if desc {
+				return enrichedTransactions[i].UserNetAmount > enrichedTransactions[j].UserNetAmount
+			}
+			return enrichedTransactions[i].UserNetAmount < enrichedTransactions[j].UserNetAmount
+		})
+	}
+
+	return enrichedTransactions, nil
+}
+
+func transactionSectionKey(t *models.Transaction, groupBy models.TransactionGroupBy) (string, string) {
+	day, err := time.Parse("2006-01-02", t.Date)
+	if err != nil {
+		day = t.DateISO
+	}
+	if groupBy == models.TransactionGroupByDay {
+		return day.Format("2006-01-02"), day.Format("Mon, 2 Jan 2006")
+	}
+	return day.Format("2006-01"), day.Format("January 2006")
+}
+
+// buildTransactionSections groups the full, sorted transaction list and
+// returns the sections that overlap the page [offset, offset+pageSize),
+// with StartIndex relative to the page.
+func buildTransactionSections(transactions []models.Transaction, groupBy models.TransactionGroupBy, offset, pageSize int) []models.TransactionSection {
+	sections := []models.TransactionSection{}
+	var current *models.TransactionSection
+	var subtotals map[string]*models.TransactionSubtotal
+	var currencies []string
+	start := 0
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		end := start + current.TotalCount
+		pageEnd := offset + pageSize
+		if start < pageEnd && end > offset {
+			current.StartIndex = max(start, offset) - offset
+			current.Count = min(end, pageEnd) - max(start, offset)
+			sort.Strings(currencies)
+			current.Subtotals = make([]models.TransactionSubtotal, 0, len(currencies))
+			for _, c := range currencies {
+				st := subtotals[c]
+				st.TotalSpent = math.Round(st.TotalSpent*RoundingFactor) / RoundingFactor
+				st.YourShare = math.Round(st.YourShare*RoundingFactor) / RoundingFactor
+				current.Subtotals = append(current.Subtotals, *st)
+			}
+			sections = append(sections, *current)
+		}
+		start = end
+	}
+
+	for i := range transactions {
+		t := &transactions[i]
+		key, label := transactionSectionKey(t, groupBy)
+		if current == nil || current.Key != key {
+			flush()
+			current = &models.TransactionSection{Key: key, Label: label}
+			subtotals = make(map[string]*models.TransactionSubtotal)
+			currencies = nil
+		}
+		current.TotalCount++
+
+		sign := 0.0
+		switch t.Category {
+		case models.TransactionCategoryExpense:
+			sign = 1
+		case models.TransactionCategoryRefund:
+			sign = -1
+		}
+		if sign == 0 {
+			continue
+		}
+		st, ok := subtotals[t.Currency]
+		if !ok {
+			st = &models.TransactionSubtotal{Currency: t.Currency}
+			subtotals[t.Currency] = st
+			currencies = append(currencies, t.Currency)
+		}
+		st.TotalSpent += sign * t.TotalAmount
+		st.YourShare += sign * t.UserShare
+	}
+	flush()
+	return sections
+}
 
-		netAmount := userPaidAmount - userSplitAmount
-		enriched.UserNetAmount = math.Round(netAmount*RoundingFactor) / RoundingFactor
-		enriched.UserIsOwed = netAmount > BalanceThreshold
-		enriched.UserIsLent = netAmount > BalanceThreshold
+// GetReceipts pages through the group's receipt images. A zero limit means
+// ReceiptGalleryPageSize.
+func (s *groupService) GetReceipts(ctx context.Context, groupID, userID string, limit, offset int) (*models.ReceiptGalleryPage, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
 
-		for i := range enriched.Splits {
-			splitUser, err := s.getUserWithCache(ctx, enriched.Splits[i].UserID, userCache)
-			if err == nil {
-				enriched.Splits[i].UserName = splitUser.Name
-				enriched.Splits[i].UserEmail = splitUser.Email
-			}
-		}
+	if limit == 0 {
+		limit = ReceiptGalleryPageSize
+	}
+	if limit < 0 || limit > MaxReceiptGalleryPageSize {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("Limit must be between 1 and %d.", MaxReceiptGalleryPageSize))
+	}
+	if offset < 0 {
+		return nil, apperrors.InvalidRequest("Offset cannot be negative.")
+	}
 
-		enrichedTransactions = append(enrichedTransactions, enriched)
+	items, total, err := s.expenseRepo.GetReceiptsByGroupID(ctx, groupID, limit, offset)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting receipts", err)
 	}
+	return &models.ReceiptGalleryPage{Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
 
-	return enrichedTransactions, nil
+func validateTransactionFilter(filter models.TransactionFilter) error {
+	switch filter.Sort.Field {
+	case "", models.TransactionSortDate, models.TransactionSortAmount, models.TransactionSortNet, models.TransactionSortPayer:
+	default:
+		return apperrors.InvalidRequestWithDetails("Invalid transaction sort field.", "Allowed values: date, amount, net, payer")
+	}
+	if err := validateSortOrder(filter.Sort.Order); err != nil {
+		return err
+	}
+	if filter.Limit < 0 || filter.Limit > MaxTransactionPageSize {
+		return apperrors.InvalidRequest(fmt.Sprintf("Limit must be between 1 and %d.", MaxTransactionPageSize))
+	}
+	if filter.Offset < 0 {
+		return apperrors.InvalidRequest("Offset cannot be negative.")
+	}
+	return nil
 }
 
-func (s *groupService) CreateRepayment(ctx context.Context, groupID, payerID, receiverID string, amount float64) (*models.Expense, error) {
-	isMember, err := s.groupRepo.IsMember(ctx, groupID, payerID)
-	if err != nil {
-		return nil, apperrors.DatabaseError("checking membership", err)
+func paginateTransactions(transactions []models.Transaction, limit, offset int) []models.Transaction {
+	if offset >= len(transactions) {
+		return []models.Transaction{}
 	}
-	if !isMember {
-		return nil, apperrors.NotGroupMember()
+	transactions = transactions[offset:]
+	if limit > 0 && limit < len(transactions) {
+		transactions = transactions[:limit]
+	}
+	return transactions
+}
+
+func (s *groupService) CreateRepayment(ctx context.Context, groupID, payerID, receiverID string, amount float64, description string) (*models.Expense, error) {
+	if err := s.requireMembership(ctx, groupID, payerID); err != nil {
+		return nil, err
 	}
 
-	isReceiverMember, err := s.groupRepo.IsMember(ctx, groupID, receiverID)
+	isReceiverMember, err := isGroupMember(ctx, s.groupRepo, groupID, receiverID)
 	if err != nil {
 		return nil, apperrors.DatabaseError("checking receiver membership", err)
 	}
@@ -476,21 +1356,25 @@ func (s *groupService) CreateRepayment(ctx context.Context, groupID, payerID, re
 	if currency == "" {
 		currency = "INR"
 	}
+	if description == "" {
+		description = fmt.Sprintf("Repayment from %s to %s", payerID, receiverID)
+	}
 
 	expenseID := uuid.New().String()
 	payerIDPtr := &payerID
 	expense := &models.Expense{
-		ID:           expenseID,
-		GroupID:      groupID,
-		PaidByUserID: payerIDPtr,
-		TotalAmount:  amount,
-		Currency:     currency,
-		Description:  fmt.Sprintf("Repayment from %s to %s", payerID, receiverID),
-		Type:         models.ExpenseTypeEqual,
-		Category:     models.TransactionCategoryRepayment,
-		DateISO:      time.Now(),
-		Date:         time.Now().Format("2006-01-02"),
-		Time:         time.Now().Format("15:04"),
+		ID:              expenseID,
+		GroupID:         groupID,
+		PaidByUserID:    payerIDPtr,
+		CreatedByUserID: payerIDPtr,
+		TotalAmount:     amount,
+		Currency:        currency,
+		Description:     description,
+		Type:            models.ExpenseTypeEqual,
+		Category:        models.TransactionCategoryRepayment,
+		DateISO:         time.Now(),
+		Date:            time.Now().Format("2006-01-02"),
+		Time:            time.Now().Format("15:04"),
 		Payers: []models.ExpensePayer{
 			{
 				ID:         uuid.New().String(),
@@ -502,41 +1386,45 @@ func (s *groupService) CreateRepayment(ctx context.Context, groupID, payerID, re
 	}
 
 	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		split := &models.ExpenseSplit{
+			ID:        uuid.New().String(),
+			ExpenseID: expenseID,
+			UserID:    receiverID,
+			Amount:    amount,
+		}
+		if err := lockGroupForWrite(ctx, s.groupRepo, q, groupID, expenseParticipants(expense, []models.ExpenseSplit{*split})...); err != nil {
+			return err
+		}
+
 		txRepo := s.expenseRepo.WithTx(q)
 		if err := txRepo.Create(ctx, expense); err != nil {
-			return apperrors.DatabaseError("creating repayment", err)
+			return expenseWriteError("creating repayment", err)
 		}
 
 		for i := range expense.Payers {
 			expense.Payers[i].ID = uuid.New().String()
 			expense.Payers[i].ExpenseID = expenseID
 			if err := txRepo.CreatePayer(ctx, &expense.Payers[i]); err != nil {
-				return apperrors.DatabaseError("creating repayment payer", err)
+				return expenseWriteError("creating repayment payer", err)
 			}
 		}
 
-		split := &models.ExpenseSplit{
-			ID:        uuid.New().String(),
-			ExpenseID: expenseID,
-			UserID:    receiverID,
-			Amount:    amount,
-		}
-
 		if err := txRepo.CreateSplit(ctx, split); err != nil {
-			return apperrors.DatabaseError("creating repayment split", err)
+			return expenseWriteError("creating repayment split", err)
 		}
-		return nil
+		return recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, expenseID, nil)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	checkBalanceAlertsAsync(s.balanceAlertService, groupID)
 	return s.expenseRepo.GetByID(ctx, expenseID)
 }
 
 func (s *groupService) calculateBalances(ctx context.Context, groupID string) ([]models.Balance, error) {
-	balancesByCurrency, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID)
+	balancesByCurrency, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, nil)
 	if err != nil {
 		return nil, apperrors.DatabaseError("getting group member balances", err)
 	}
@@ -562,12 +1450,12 @@ func (s *groupService) GetBalances(ctx context.Context, groupID, userID string)
 		return nil, err
 	}
 
-	balancesByCurrency, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID)
+	balancesByCurrency, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, nil)
 	if err != nil {
 		return nil, apperrors.DatabaseError("getting group member balances", err)
 	}
 
-	settlements, err := s.settlementService.CalculateSettlements(ctx, groupID, userID)
+	settlements, err := s.settlementService.CalculateSettlements(ctx, groupID, userID, nil)
 	if err != nil {
 		return nil, apperrors.InternalError(fmt.Errorf("calculating settlements: %w", err))
 	}
@@ -597,6 +1485,8 @@ func (s *groupService) GetBalances(ctx context.Context, groupID, userID string)
 		}
 	}
 
+	sort.Slice(userBalances, func(i, j int) bool { return userBalances[i].UserID < userBalances[j].UserID })
+
 	totalSpending, err := s.expenseRepo.GetGroupTotalSpend(ctx, groupID)
 	if err != nil {
 		return nil, apperrors.DatabaseError("getting group total spend", err)
@@ -608,17 +1498,17 @@ func (s *groupService) GetBalances(ctx context.Context, groupID, userID string)
 	}, nil
 }
 
-func (s *groupService) GetBalancesEdgeList(ctx context.Context, groupID, userID string) (*models.GroupBalancesEdgeResponse, error) {
+func (s *groupService) GetBalancesEdgeList(ctx context.Context, groupID, userID string, asOf *time.Time) (*models.GroupBalancesEdgeResponse, error) {
 	if err := s.requireMembership(ctx, groupID, userID); err != nil {
 		return nil, err
 	}
 
-	balancesByCurrency, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID)
+	balancesByCurrency, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, asOf)
 	if err != nil {
 		return nil, apperrors.DatabaseError("getting group member balances", err)
 	}
 
-	settlements, err := s.settlementService.CalculateSettlements(ctx, groupID, userID)
+	settlements, err := s.settlementService.CalculateSettlements(ctx, groupID, userID, asOf)
 	if err != nil {
 		return nil, apperrors.InternalError(fmt.Errorf("calculating settlements: %w", err))
 	}
@@ -645,6 +1535,16 @@ func (s *groupService) GetBalancesEdgeList(ctx context.Context, groupID, userID
 		state = models.BalanceStateSettled
 	}
 
+	reminderResponses, err := s.reminderResponseRepo.GetActiveByGroupID(ctx, groupID, time.Now())
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting reminder responses", err)
+	}
+	responsesByPair := make(map[[2]string]*models.ReminderResponse, len(reminderResponses))
+	for i := range reminderResponses {
+		resp := &reminderResponses[i]
+		responsesByPair[[2]string{resp.DebtorID, resp.CreditorID}] = resp
+	}
+
 	debts := make([]models.DebtEdge, 0)
 	userCache := make(map[string]*models.User)
 
@@ -659,7 +1559,7 @@ func (s *groupService) GetBalancesEdgeList(ctx context.Context, groupID, userID
 			return nil, apperrors.DatabaseError("getting to user", err)
 		}
 
-		debts = append(debts, models.DebtEdge{
+		edge := models.DebtEdge{
 			FromUser: models.UserInfo{
 				ID:        fromUser.ID,
 				Name:      fromUser.Name,
@@ -672,7 +1572,13 @@ func (s *groupService) GetBalancesEdgeList(ctx context.Context, groupID, userID
 			},
 			Amount:   settlement.Amount,
 			Currency: settlement.Currency,
-		})
+		}
+		// A promise or snooze is between the two people involved, so only
+		// they see it on the edge.
+		if settlement.FromUserID == userID || settlement.ToUserID == userID {
+			edge.ReminderResponse = responsesByPair[[2]string{settlement.FromUserID, settlement.ToUserID}]
+		}
+		debts = append(debts, edge)
 
 		if settlement.FromUserID == userID {
 			countUserOwes++
@@ -682,7 +1588,7 @@ func (s *groupService) GetBalancesEdgeList(ctx context.Context, groupID, userID
 		}
 	}
 
-	return &models.GroupBalancesEdgeResponse{
+	response := &models.GroupBalancesEdgeResponse{
 		Summary: models.BalanceSummary{
 			UserID:          userID,
 			TotalNet:        roundedBalance,
@@ -693,7 +1599,11 @@ func (s *groupService) GetBalancesEdgeList(ctx context.Context, groupID, userID
 			State:           state,
 		},
 		Debts: debts,
-	}, nil
+	}
+	if asOf != nil {
+		response.AsOf = asOf.Format("2006-01-02")
+	}
+	return response, nil
 }
 
 func (s *groupService) getUserWithCache(ctx context.Context, userID string, cache map[string]*models.User) (*models.User, error) {
@@ -713,20 +1623,19 @@ func (s *groupService) getUserWithCache(ctx context.Context, userID string, cach
 	return user, nil
 }
 
-func (s *groupService) CreateSettlement(ctx context.Context, groupID, requesterID, fromUserID, toUserID string, amount float64) (*models.Expense, error) {
+func (s *groupService) CreateSettlement(ctx context.Context, groupID, requesterID, fromUserID, toUserID string, amount float64, details models.SettlementDetails) (*models.Expense, error) {
 	if amount <= 0 {
 		return nil, apperrors.InvalidAmount("Amount must be greater than zero.")
 	}
-
-	isRequesterMember, err := s.groupRepo.IsMember(ctx, groupID, requesterID)
-	if err != nil {
-		return nil, apperrors.DatabaseError("checking requester membership", err)
+	if details.Method != nil && !details.Method.IsValid() {
+		return nil, apperrors.InvalidRequest("Invalid settlement method. Use one of: CASH, UPI, BANK_TRANSFER, CARD, OTHER.")
 	}
-	if !isRequesterMember {
-		return nil, apperrors.NotGroupMember()
+
+	if err := s.requireMembership(ctx, groupID, requesterID); err != nil {
+		return nil, err
 	}
 
-	isMember, err := s.groupRepo.IsMember(ctx, groupID, fromUserID)
+	isMember, err := isGroupMember(ctx, s.groupRepo, groupID, fromUserID)
 	if err != nil {
 		return nil, apperrors.DatabaseError("checking from user membership", err)
 	}
@@ -734,7 +1643,7 @@ func (s *groupService) CreateSettlement(ctx context.Context, groupID, requesterI
 		return nil, apperrors.Wrap(fmt.Errorf("from user is not a member"), apperrors.NotGroupMember())
 	}
 
-	isToMember, err := s.groupRepo.IsMember(ctx, groupID, toUserID)
+	isToMember, err := isGroupMember(ctx, s.groupRepo, groupID, toUserID)
 	if err != nil {
 		return nil, apperrors.DatabaseError("checking to user membership", err)
 	}
@@ -770,23 +1679,31 @@ func (s *groupService) CreateSettlement(ctx context.Context, groupID, requesterI
 	if currency == "" {
 		currency = "INR"
 	}
+	if err := requireVerifiedForSettlement(ctx, s.emailVerifier, requesterID, amount, currency); err != nil {
+		return nil, err
+	}
 
 	expenseID := uuid.New().String()
 	fromUserIDPtr := &fromUserID
 	description := fmt.Sprintf("Payment from %s to %s", fromUser.Name, toUser.Name)
 
 	expense := &models.Expense{
-		ID:           expenseID,
-		GroupID:      groupID,
-		PaidByUserID: fromUserIDPtr,
-		TotalAmount:  amount,
-		Currency:     currency,
-		Description:  description,
-		Type:         models.ExpenseTypeEqual,
-		Category:     models.TransactionCategoryPayment,
-		DateISO:      time.Now(),
-		Date:         time.Now().Format("2006-01-02"),
-		Time:         time.Now().Format("15:04"),
+		ID:              expenseID,
+		GroupID:         groupID,
+		PaidByUserID:    fromUserIDPtr,
+		CreatedByUserID: &requesterID,
+		TotalAmount:     amount,
+		Currency:        currency,
+		Description:     description,
+		Type:            models.ExpenseTypeEqual,
+		Category:        models.TransactionCategoryPayment,
+		DateISO:         time.Now(),
+		Date:            time.Now().Format("2006-01-02"),
+		Time:            time.Now().Format("15:04"),
+
+		SettlementMethod:    details.Method,
+		SettlementReference: details.Reference,
+		SettlementProofPath: details.ProofPath,
 		Payers: []models.ExpensePayer{
 			{
 				ID:         uuid.New().String(),
@@ -798,33 +1715,407 @@ func (s *groupService) CreateSettlement(ctx context.Context, groupID, requesterI
 	}
 
 	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		split := &models.ExpenseSplit{
+			ID:        uuid.New().String(),
+			ExpenseID: expenseID,
+			UserID:    toUserID,
+			Amount:    amount,
+		}
+		if err := lockGroupForWrite(ctx, s.groupRepo, q, groupID, expenseParticipants(expense, []models.ExpenseSplit{*split})...); err != nil {
+			return err
+		}
+
 		txRepo := s.expenseRepo.WithTx(q)
 		if err := txRepo.Create(ctx, expense); err != nil {
-			return apperrors.DatabaseError("creating payment transaction", err)
+			return expenseWriteError("creating payment transaction", err)
 		}
 
 		for i := range expense.Payers {
 			if err := txRepo.CreatePayer(ctx, &expense.Payers[i]); err != nil {
-				return apperrors.DatabaseError("creating payment payer", err)
+				return expenseWriteError("creating payment payer", err)
 			}
 		}
 
-		split := &models.ExpenseSplit{
-			ID:        uuid.New().String(),
-			ExpenseID: expenseID,
-			UserID:    toUserID,
-			Amount:    amount,
+		if err := txRepo.CreateSplit(ctx, split); err != nil {
+			return expenseWriteError("creating payment split", err)
+		}
+		return recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, expenseID, nil)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	markSeenByActor(ctx, s.readRepo, groupID, requesterID, expenseID)
+	checkBalanceAlertsAsync(s.balanceAlertService, groupID)
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:      models.NotificationEventSettlement,
+		GroupID:    groupID,
+		ExpenseID:  expenseID,
+		ActorID:    requesterID,
+		Template:   notifySettlement,
+		Args:       []interface{}{fromUser.Name, toUser.Name, amount, currency},
+		Recipients: []string{fromUserID, toUserID},
+	})
+
+	return s.expenseRepo.GetByID(ctx, expenseID)
+}
+
+// ReverseSettlement undoes a settlement by recording the opposite payment
+// linked to it, so both stay in the ledger. Anyone the group's edit policy
+// allows to change the settlement may reverse it, and so may its receiver.
+func (s *groupService) ReverseSettlement(ctx context.Context, groupID, userID, expenseID, reason string) (*models.Expense, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	original, err := s.expenseRepo.GetByID(ctx, expenseID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.ExpenseNotFound()
 		}
+		return nil, apperrors.DatabaseError("getting settlement", err)
+	}
+	if original.GroupID != groupID {
+		return nil, apperrors.ExpenseNotFound()
+	}
+	switch original.SettlementStatus {
+	case models.SettlementStatusActive:
+	case models.SettlementStatusReversed:
+		return nil, apperrors.SettlementAlreadyReversed()
+	default:
+		return nil, apperrors.SettlementNotReversible()
+	}
+	if len(original.Payers) == 0 || len(original.Splits) == 0 {
+		return nil, apperrors.SettlementNotReversible()
+	}
 
-		if err := txRepo.CreateSplit(ctx, split); err != nil {
-			return apperrors.DatabaseError("creating payment split", err)
+	fromID, toID := original.Payers[0].UserID, original.Splits[0].UserID
+	if userID != toID {
+		policy, err := s.groupRepo.GetExpenseEditPolicy(ctx, groupID)
+		if err != nil {
+			return nil, apperrors.DatabaseError("getting group expense edit policy", err)
+		}
+		if !canEditExpense(policy, original, userID) {
+			return nil, apperrors.ExpenseEditNotAllowed(string(policy))
+		}
+	}
+
+	reversal := reversalOf(original, userID, strings.TrimSpace(reason), time.Now())
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := lockGroupForWrite(ctx, s.groupRepo, q, groupID, expenseParticipants(reversal, reversal.Splits)...); err != nil {
+			return err
+		}
+
+		txRepo := s.expenseRepo.WithTx(q)
+		if err := txRepo.Create(ctx, reversal); err != nil {
+			return expenseWriteError("creating settlement reversal", err)
+		}
+		for i := range reversal.Payers {
+			if err := txRepo.CreatePayer(ctx, &reversal.Payers[i]); err != nil {
+				return expenseWriteError("creating settlement reversal payer", err)
+			}
+		}
+		for i := range reversal.Splits {
+			if err := txRepo.CreateSplit(ctx, &reversal.Splits[i]); err != nil {
+				return expenseWriteError("creating settlement reversal split", err)
+			}
+		}
+		if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, reversal.ID, nil); err != nil {
+			return err
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivitySettlementReversed,
+			Message: reversal.Description,
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("Settlement reversed",
+		zap.String("group_id", groupID),
+		zap.String("expense_id", expenseID),
+		zap.String("reversal_id", reversal.ID),
+		zap.String("user_id", userID))
+
+	markSeenByActor(ctx, s.readRepo, groupID, userID, reversal.ID)
+	checkBalanceAlertsAsync(s.balanceAlertService, groupID)
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:      models.NotificationEventSettlement,
+		GroupID:    groupID,
+		ExpenseID:  reversal.ID,
+		ActorID:    userID,
+		Template:   notifySettlementReversed,
+		Args:       []interface{}{original.TotalAmount, original.Currency},
+		Recipients: []string{fromID, toID},
+	})
+
+	return s.expenseRepo.GetByID(ctx, reversal.ID)
+}
+
+// reversalOf builds the payment that cancels original: the receiver pays the
+// same amount back, in the same currency and category.
+func reversalOf(original *models.Expense, userID, reason string, now time.Time) *models.Expense {
+	fromID, toID := original.Payers[0].UserID, original.Splits[0].UserID
+	description := fmt.Sprintf("Reversal of %s", original.Description)
+	if reason != "" {
+		description += ": " + reason
+	}
+
+	id := uuid.New().String()
+	return &models.Expense{
+		ID:                id,
+		GroupID:           original.GroupID,
+		PaidByUserID:      &toID,
+		CreatedByUserID:   &userID,
+		TotalAmount:       original.TotalAmount,
+		Currency:          original.Currency,
+		Description:       description,
+		Type:              original.Type,
+		Category:          original.Category,
+		ReversesExpenseID: &original.ID,
+		DateISO:           now,
+		Date:              now.Format("2006-01-02"),
+		Time:              now.Format("15:04"),
+		Payers: []models.ExpensePayer{
+			{ID: uuid.New().String(), ExpenseID: id, UserID: toID, AmountPaid: original.TotalAmount},
+		},
+		Splits: []models.ExpenseSplit{
+			{ID: uuid.New().String(), ExpenseID: id, UserID: fromID, Amount: original.TotalAmount},
+		},
+	}
+}
+
+// CreateCover records payerID covering beneficiaryID. It is checked against
+// the group's expense limits like any other expense; confirmOverLimit
+// overrides them and leaves an activity entry.
+func (s *groupService) CreateCover(ctx context.Context, groupID, requesterID, payerID, beneficiaryID string, amount float64, note string, confirmOverLimit bool) (*models.Expense, error) {
+	if amount <= 0 {
+		return nil, apperrors.InvalidAmount("Amount must be greater than zero.")
+	}
+	if payerID == beneficiaryID {
+		return nil, apperrors.InvalidRequest("Payer and beneficiary must be different members.")
+	}
+
+	if err := s.requireMembership(ctx, groupID, requesterID); err != nil {
+		return nil, err
+	}
+
+	isPayerMember, err := isGroupMember(ctx, s.groupRepo, groupID, payerID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking payer membership", err)
+	}
+	if !isPayerMember {
+		return nil, apperrors.Wrap(fmt.Errorf("payer is not a member"), apperrors.NotGroupMember())
+	}
+
+	isBeneficiaryMember, err := isGroupMember(ctx, s.groupRepo, groupID, beneficiaryID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking beneficiary membership", err)
+	}
+	if !isBeneficiaryMember {
+		return nil, apperrors.Wrap(fmt.Errorf("beneficiary is not a member"), apperrors.NotGroupMember())
+	}
+
+	if err := s.quotaService.CheckExpenses(ctx, requesterID, groupID, 1); err != nil {
+		return nil, err
+	}
+
+	payer, err := s.userRepo.GetByID(ctx, payerID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting payer", err)
+	}
+
+	beneficiary, err := s.userRepo.GetByID(ctx, beneficiaryID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting beneficiary", err)
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group for currency", err)
+	}
+	currency := group.DefaultCurrency
+	if currency == "" {
+		currency = "INR"
+	}
+
+	description := note
+	if description == "" {
+		description = fmt.Sprintf("%s covered %s", payer.Name, beneficiary.Name)
+	}
+
+	amount = math.Round(amount*RoundingFactor) / RoundingFactor
+	expenseID := uuid.New().String()
+	now := time.Now()
+	expense := &models.Expense{
+		ID:              expenseID,
+		GroupID:         groupID,
+		PaidByUserID:    &payerID,
+		CreatedByUserID: &requesterID,
+		TotalAmount:     amount,
+		Currency:        currency,
+		Description:     description,
+		Type:            models.ExpenseTypeExactAmount,
+		Category:        models.TransactionCategoryExpense,
+		DateISO:         now,
+		Date:            now.Format("2006-01-02"),
+		Time:            now.Format("15:04"),
+		Payers: []models.ExpensePayer{
+			{
+				ID:         uuid.New().String(),
+				ExpenseID:  expenseID,
+				UserID:     payerID,
+				AmountPaid: amount,
+			},
+		},
+		ConfirmOverLimit: confirmOverLimit,
+	}
+	split := &models.ExpenseSplit{
+		ID:        uuid.New().String(),
+		ExpenseID: expenseID,
+		UserID:    beneficiaryID,
+		Amount:    amount,
+	}
+	if err := checkExpenseAmountsWith(ctx, s.groupRepo, groupID, expense, []models.ExpenseSplit{*split}); err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		limitActivity, err := checkExpenseLimitsWith(ctx, s.groupRepo, s.expenseRepo, q, requesterID, expense, nil)
+		if err != nil {
+			return err
+		}
+		if err := lockGroupForWrite(ctx, s.groupRepo, q, groupID, expenseParticipants(expense, []models.ExpenseSplit{*split})...); err != nil {
+			return err
+		}
+
+		txRepo := s.expenseRepo.WithTx(q)
+		if err := txRepo.Create(ctx, expense); err != nil {
+			return expenseWriteError("creating cover expense", err)
+		}
+
+		for i := range expense.Payers {
+			if err := txRepo.CreatePayer(ctx, &expense.Payers[i]); err != nil {
+				return expenseWriteError("creating cover payer", err)
+			}
+		}
+
+		if err := txRepo.CreateSplit(ctx, split); err != nil {
+			return expenseWriteError("creating cover split", err)
+		}
+		if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, expenseID, nil); err != nil {
+			return err
+		}
+		return recordLimitActivityWith(ctx, s.activityRepo, q, limitActivity)
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	markSeenByActor(ctx, s.readRepo, groupID, requesterID, expenseID)
+	checkBalanceAlertsAsync(s.balanceAlertService, groupID)
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:     models.NotificationEventNewExpense,
+		GroupID:   groupID,
+		ExpenseID: expenseID,
+		ActorID:   requesterID,
+		Template:  notifyCover,
+		Args:      []interface{}{payer.Name, beneficiary.Name, amount, currency},
+	})
+
 	return s.expenseRepo.GetByID(ctx, expenseID)
 }
+
+func (s *groupService) GetSettlementHistory(ctx context.Context, groupID, userID string) ([]models.SettlementHistoryEntry, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.expenseRepo.GetTransactionsByGroupID(ctx, groupID, models.TransactionSort{})
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting transactions", err)
+	}
+
+	userCache := make(map[string]*models.User)
+	ledger := make(map[string]float64)
+	history := []models.SettlementHistoryEntry{}
+
+	for i := len(transactions) - 1; i >= 0; i-- {
+		t := transactions[i]
+		applyPairwiseDebts(ledger, t.Currency, t.Payers, t.Splits, t.TotalAmount)
+
+		if t.Category != models.TransactionCategoryPayment && t.Category != models.TransactionCategoryRepayment {
+			continue
+		}
+		if len(t.Payers) == 0 || len(t.Splits) == 0 {
+			continue
+		}
+
+		fromID, toID := t.Payers[0].UserID, t.Splits[0].UserID
+		entry := models.SettlementHistoryEntry{
+			ID:               t.ID,
+			Category:         string(t.Category),
+			Date:             t.DateISO,
+			Description:      t.Description,
+			Amount:           t.TotalAmount,
+			Currency:         t.Currency,
+			Method:           t.SettlementMethod,
+			Reference:        t.SettlementReference,
+			ProofPath:        t.SettlementProofPath,
+			CreatedByID:      t.CreatedByUserID,
+			PairBalanceAfter: math.Round(ledger[pairLedgerKey(t.Currency, fromID, toID)]*RoundingFactor) / RoundingFactor,
+			Status:           t.SettlementStatus,
+			ReversesID:       t.ReversesExpenseID,
+			ReversedByID:     t.ReversedByExpenseID,
+		}
+		if fromUser, err := s.getUserWithCache(ctx, fromID, userCache); err == nil {
+			entry.FromUser = fromUser
+		}
+		if toUser, err := s.getUserWithCache(ctx, toID, userCache); err == nil {
+			entry.ToUser = toUser
+		}
+		history = append(history, entry)
+	}
+
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return history, nil
+}
+
+func pairLedgerKey(currency, debtorID, creditorID string) string {
+	return currency + "|" + debtorID + "|" + creditorID
+}
+
+func applyPairwiseDebts(ledger map[string]float64, currency string, payers []models.ExpensePayer, splits []models.ExpenseSplit, total float64) {
+	if total == 0 {
+		return
+	}
+	for _, split := range splits {
+		for _, payer := range payers {
+			if split.UserID == payer.UserID {
+				continue
+			}
+			owed := split.Amount * payer.AmountPaid / total
+			ledger[pairLedgerKey(currency, split.UserID, payer.UserID)] += owed
+			ledger[pairLedgerKey(currency, payer.UserID, split.UserID)] -= owed
+		}
+	}
+}
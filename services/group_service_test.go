@@ -0,0 +1,727 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+)
+
+func TestBuildTransactionSections(t *testing.T) {
+	tx := func(date string, category models.TransactionCategory, amount, share float64) models.Transaction {
+		var t models.Transaction
+		t.Date, t.Category, t.Currency, t.TotalAmount, t.UserShare = date, category, "INR", amount, share
+		return t
+	}
+	transactions := []models.Transaction{
+		tx("2024-03-20", models.TransactionCategoryExpense, 300, 100),
+		tx("2024-03-18", models.TransactionCategoryRefund, 60, 20),
+		tx("2024-03-02", models.TransactionCategoryPayment, 500, 500),
+		tx("2024-02-28", models.TransactionCategoryExpense, 90.5, 45.25),
+		tx("2024-02-10", models.TransactionCategoryExpense, 10, 0),
+	}
+
+	sections := buildTransactionSections(transactions, models.TransactionGroupByMonth, 0, len(transactions))
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(sections))
+	}
+	march := sections[0]
+	if march.Key != "2024-03" || march.Label != "March 2024" || march.StartIndex != 0 || march.Count != 3 {
+		t.Errorf("unexpected March section: %+v", march)
+	}
+	if len(march.Subtotals) != 1 || march.Subtotals[0].TotalSpent != 240 || march.Subtotals[0].YourShare != 80 {
+		t.Errorf("expected refund to reduce and payment to be skipped, got %+v", march.Subtotals)
+	}
+	if sections[1].StartIndex != 3 || sections[1].Subtotals[0].TotalSpent != 100.5 {
+		t.Errorf("unexpected February section: %+v", sections[1])
+	}
+
+	// A page starting mid-section keeps the section's full subtotals.
+	page := buildTransactionSections(transactions, models.TransactionGroupByMonth, 2, 2)
+	if len(page) != 2 || page[0].Count != 1 || page[0].TotalCount != 3 || page[0].Subtotals[0].TotalSpent != 240 {
+		t.Errorf("unexpected sections for page: %+v", page)
+	}
+	if page[1].StartIndex != 1 || page[1].Count != 1 || page[1].TotalCount != 2 {
+		t.Errorf("unexpected second section for page: %+v", page[1])
+	}
+
+	days := buildTransactionSections(transactions, models.TransactionGroupByDay, 0, 1)
+	if len(days) != 1 || days[0].Key != "2024-03-20" || days[0].Label != "Wed, 20 Mar 2024" {
+		t.Errorf("unexpected day sections: %+v", days)
+	}
+}
+
+func TestReversalOfSettlement(t *testing.T) {
+	payer, receiver := "user-a", "user-b"
+	original := &models.Expense{
+		ID:           "settlement-1",
+		GroupID:      "group-1",
+		PaidByUserID: &payer,
+		TotalAmount:  125.5,
+		Currency:     "EUR",
+		Description:  "Payment from A to B",
+		Type:         models.ExpenseTypeEqual,
+		Category:     models.TransactionCategoryPayment,
+		Payers:       []models.ExpensePayer{{UserID: payer, AmountPaid: 125.5}},
+		Splits:       []models.ExpenseSplit{{UserID: receiver, Amount: 125.5}},
+	}
+
+	reversal := reversalOf(original, receiver, "Wrong account", time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC))
+	if reversal.ReversesExpenseID == nil || *reversal.ReversesExpenseID != original.ID {
+		t.Fatalf("reversal not linked to original: %+v", reversal.ReversesExpenseID)
+	}
+	if *reversal.PaidByUserID != receiver || reversal.Payers[0].UserID != receiver || reversal.Splits[0].UserID != payer {
+		t.Errorf("payer and receiver not swapped: %+v", reversal)
+	}
+	if reversal.Category != original.Category || reversal.Currency != "EUR" || reversal.TotalAmount != 125.5 {
+		t.Errorf("unexpected reversal amount or category: %+v", reversal)
+	}
+	if reversal.Description != "Reversal of Payment from A to B: Wrong account" {
+		t.Errorf("unexpected description %q", reversal.Description)
+	}
+	if reversal.Date != "2024-05-01" || reversal.Payers[0].ExpenseID != reversal.ID {
+		t.Errorf("unexpected reversal date or payer link: %+v", reversal)
+	}
+
+	ledger := make(map[string]float64)
+	applyPairwiseDebts(ledger, original.Currency, original.Payers, original.Splits, original.TotalAmount)
+	applyPairwiseDebts(ledger, reversal.Currency, reversal.Payers, reversal.Splits, reversal.TotalAmount)
+	for key, amount := range ledger {
+		if math.Abs(amount) > BalanceThreshold {
+			t.Errorf("ledger %s not restored: %.2f", key, amount)
+		}
+	}
+
+	original.ReversedByExpenseID = &reversal.ID
+	original.ResolveSettlementStatus()
+	reversal.ResolveSettlementStatus()
+	if original.SettlementStatus != models.SettlementStatusReversed || reversal.SettlementStatus != models.SettlementStatusReversal {
+		t.Errorf("unexpected statuses %q and %q", original.SettlementStatus, reversal.SettlementStatus)
+	}
+}
+
+func TestUsageMonth(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	tests := []struct {
+		name     string
+		now      time.Time
+		from, to string
+	}{
+		{"Mid month", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC), "2024-03-01", "2024-04-01"},
+		{"December", time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC), "2024-12-01", "2025-01-01"},
+		{"Local time still in the previous UTC month", time.Date(2024, 4, 1, 2, 0, 0, 0, ist), "2024-03-01", "2024-04-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := usageMonth(tt.now)
+			if got := from.Format("2006-01-02"); got != tt.from {
+				t.Errorf("from = %s, expected %s", got, tt.from)
+			}
+			if got := to.Format("2006-01-02"); got != tt.to {
+				t.Errorf("to = %s, expected %s", got, tt.to)
+			}
+		})
+	}
+}
+
+func TestCreateCoverRejectsBeforeWriting(t *testing.T) {
+	repo := &countingMemberRepo{members: map[string]bool{"g1/alice": true, "g1/bob": true}}
+	s := &groupService{groupRepo: repo, quotaService: NewQuotaService(stubQuotaRepository{}, QuotaLimits{})}
+
+	tests := []struct {
+		name         string
+		requester    string
+		payer        string
+		beneficiary  string
+		amount       float64
+		expectedCode apperrors.ErrorCode
+	}{
+		{name: "Zero Amount", requester: "alice", payer: "alice", beneficiary: "bob", amount: 0, expectedCode: apperrors.CodeInvalidAmount},
+		{name: "Covering Yourself", requester: "alice", payer: "bob", beneficiary: "bob", amount: 10, expectedCode: apperrors.CodeInvalidRequest},
+		{name: "Requester Outside Group", requester: "mallory", payer: "alice", beneficiary: "bob", amount: 10, expectedCode: apperrors.CodeNotGroupMember},
+		{name: "Payer Outside Group", requester: "alice", payer: "mallory", beneficiary: "bob", amount: 10, expectedCode: apperrors.CodeNotGroupMember},
+		{name: "Beneficiary Outside Group", requester: "alice", payer: "alice", beneficiary: "mallory", amount: 10, expectedCode: apperrors.CodeNotGroupMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateCover(context.Background(), "g1", tt.requester, tt.payer, tt.beneficiary, tt.amount, "", false)
+			appErr, ok := apperrors.AsAppError(err)
+			if !ok || appErr.Code != tt.expectedCode {
+				t.Errorf("CreateCover() error = %v, expected %s", err, tt.expectedCode)
+			}
+		})
+	}
+}
+
+func TestSortMembers(t *testing.T) {
+	members := func() []models.User {
+		return []models.User{
+			{ID: "3", Name: "carol", Balance: 10},
+			{ID: "1", Name: "Asha", Balance: -5},
+			{ID: "2", Name: "bob", Balance: 10},
+			{ID: "0", Name: "asha", Balance: 0},
+		}
+	}
+	tests := []struct {
+		name     string
+		sort     models.MemberSort
+		expected []string
+	}{
+		{name: "Unsorted", sort: models.MemberSort{}, expected: []string{"3", "1", "2", "0"}},
+		{name: "Name Ignores Case Then ID", sort: models.MemberSort{Field: models.MemberSortName}, expected: []string{"0", "1", "2", "3"}},
+		{name: "Name Descending", sort: models.MemberSort{Field: models.MemberSortName, Order: models.SortOrderDesc}, expected: []string{"3", "2", "0", "1"}},
+		{name: "Balance Ties By Name", sort: models.MemberSort{Field: models.MemberSortBalance}, expected: []string{"1", "0", "2", "3"}},
+		{name: "Balance Descending Ties Stay Ascending", sort: models.MemberSort{Field: models.MemberSortBalance, Order: models.SortOrderDesc}, expected: []string{"2", "3", "0", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := members()
+			sortMembers(got, tt.sort)
+			var ids []string
+			for _, m := range got {
+				ids = append(ids, m.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("sortMembers() = %v, expected %v", ids, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTransactionFilterAndPagination(t *testing.T) {
+	filters := []struct {
+		name        string
+		filter      models.TransactionFilter
+		expectError bool
+	}{
+		{name: "Defaults", filter: models.TransactionFilter{}},
+		{name: "Full Page", filter: models.TransactionFilter{Sort: models.TransactionSort{Field: models.TransactionSortPayer, Order: models.SortOrderAsc}, Limit: MaxTransactionPageSize}},
+		{name: "Unknown Field", filter: models.TransactionFilter{Sort: models.TransactionSort{Field: "category"}}, expectError: true},
+		{name: "Unknown Order", filter: models.TransactionFilter{Sort: models.TransactionSort{Order: "up"}}, expectError: true},
+		{name: "Limit Too Large", filter: models.TransactionFilter{Limit: MaxTransactionPageSize + 1}, expectError: true},
+		{name: "Negative Offset", filter: models.TransactionFilter{Offset: -1}, expectError: true},
+	}
+	for _, tt := range filters {
+		if err := validateTransactionFilter(tt.filter); (err != nil) != tt.expectError {
+			t.Errorf("validateTransactionFilter(%s) error = %v, expectError %v", tt.name, err, tt.expectError)
+		}
+	}
+
+	transactions := make([]models.Transaction, 5)
+	for i := range transactions {
+		transactions[i].ID = string(rune('a' + i))
+	}
+	pages := []struct {
+		limit, offset int
+		expected      string
+	}{
+		{0, 0, "abcde"},
+		{2, 0, "ab"},
+		{2, 4, "e"},
+		{0, 3, "de"},
+		{2, 5, ""},
+	}
+	for _, tt := range pages {
+		var ids string
+		for _, tr := range paginateTransactions(transactions, tt.limit, tt.offset) {
+			ids += tr.ID
+		}
+		if ids != tt.expected {
+			t.Errorf("paginateTransactions(limit %d, offset %d) = %q, expected %q", tt.limit, tt.offset, ids, tt.expected)
+		}
+	}
+}
+
+func TestConvertMemberToPlaceholderRejectsBeforeWriting(t *testing.T) {
+	repo := &countingMemberRepo{members: map[string]bool{"g1/alice": true, "g1/bob": true, "g1/ghost": true}}
+	users := &fakeUserRepo{users: map[string]*models.User{
+		"bob":   {ID: "bob", Name: "Bob"},
+		"carol": {ID: "carol", Name: "Carol"},
+		"ghost": {ID: "ghost", Name: "Ghost", IsPlaceholder: true},
+	}}
+	// db is left nil: every case must fail before the transaction starts.
+	s := &groupService{groupRepo: repo, userRepo: users}
+
+	tests := []struct {
+		name         string
+		requester    string
+		member       string
+		expectedCode apperrors.ErrorCode
+	}{
+		{name: "Requester Not In Group", requester: "carol", member: "bob", expectedCode: apperrors.CodeNotGroupMember},
+		{name: "Unknown User", requester: "alice", member: "nobody", expectedCode: apperrors.CodeUserNotFound},
+		{name: "Already A Placeholder", requester: "alice", member: "ghost", expectedCode: apperrors.CodeInvalidRequest},
+		{name: "User Not In Group", requester: "alice", member: "carol", expectedCode: apperrors.CodeUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placeholder, err := s.ConvertMemberToPlaceholder(context.Background(), "g1", tt.requester, tt.member)
+			if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+				t.Errorf("ConvertMemberToPlaceholder() error = %v, expected %s", err, tt.expectedCode)
+			}
+			if placeholder != nil {
+				t.Errorf("ConvertMemberToPlaceholder() = %+v, expected no placeholder", placeholder)
+			}
+		})
+	}
+}
+
+type recordingArchiveRepo struct {
+	stubGroupArchiveRepository
+	archived map[string]bool
+	calls    []string
+}
+
+func (r *recordingArchiveRepo) Archive(_ context.Context, groupID, userID string) error {
+	r.calls = append(r.calls, "archive "+groupID+" for "+userID)
+	return nil
+}
+
+func (r *recordingArchiveRepo) GetArchivedGroupIDs(context.Context, string) (map[string]bool, error) {
+	return r.archived, nil
+}
+
+type detailedGroupRepo struct {
+	mockGroupRepo
+	groups []models.Group
+}
+
+func (r *detailedGroupRepo) GetGroupsDetailedByUserID(context.Context, string) ([]models.Group, error) {
+	return r.groups, nil
+}
+
+func TestArchiveRequiresSettledGroup(t *testing.T) {
+	tests := []struct {
+		name         string
+		balances     map[string]map[string]float64
+		expectedCode apperrors.ErrorCode
+	}{
+		{name: "Settled", balances: map[string]map[string]float64{"alice": {"INR": 0.004}, "bob": {"INR": -0.004}}},
+		{name: "Owes In Another Currency", balances: map[string]map[string]float64{"alice": {"INR": 0, "USD": 12}, "bob": {"USD": -12}}, expectedCode: apperrors.CodeOutstandingBalance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := &recordingArchiveRepo{}
+			s := &groupService{groupRepo: &mockGroupRepo{}, expenseRepo: &mockExpenseRepo{balances: tt.balances}, archiveRepo: archive}
+
+			err := s.Archive(context.Background(), "g1", "alice")
+			if tt.expectedCode != "" {
+				if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+					t.Errorf("Archive() error = %v, expected %s", err, tt.expectedCode)
+				}
+				if len(archive.calls) != 0 {
+					t.Errorf("Archive() archived %v, expected nothing", archive.calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Archive() error = %v", err)
+			}
+			if len(archive.calls) != 1 || archive.calls[0] != "archive g1 for alice" {
+				t.Errorf("Archive() calls = %v, expected the group archived for alice only", archive.calls)
+			}
+		})
+	}
+}
+
+func TestGetByUserIDWithBalancesArchived(t *testing.T) {
+	groups := &detailedGroupRepo{groups: []models.Group{
+		{ID: "active", Members: []models.User{{ID: "alice", Balance: -25}}},
+		{ID: "old", Members: []models.User{{ID: "alice"}}},
+	}}
+	s := &groupService{groupRepo: groups, archiveRepo: &recordingArchiveRepo{archived: map[string]bool{"old": true}}}
+
+	tests := []struct {
+		includeArchived bool
+		expectedIDs     []string
+	}{
+		{includeArchived: false, expectedIDs: []string{"active"}},
+		{includeArchived: true, expectedIDs: []string{"active", "old"}},
+	}
+
+	for _, tt := range tests {
+		result, err := s.GetByUserIDWithBalances(context.Background(), "alice", tt.includeArchived)
+		if err != nil {
+			t.Fatalf("GetByUserIDWithBalances() error = %v", err)
+		}
+		var ids []string
+		for _, g := range result {
+			ids = append(ids, g.ID)
+			if g.Archived != (g.ID == "old") {
+				t.Errorf("GetByUserIDWithBalances() %s archived = %v, expected %v", g.ID, g.Archived, g.ID == "old")
+			}
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.expectedIDs, ",") {
+			t.Errorf("GetByUserIDWithBalances(includeArchived=%v) = %v, expected %v", tt.includeArchived, ids, tt.expectedIDs)
+		}
+		if result[0].Summary.State != models.BalanceStateOwes {
+			t.Errorf("GetByUserIDWithBalances() active state = %s, expected %s", result[0].Summary.State, models.BalanceStateOwes)
+		}
+	}
+}
+
+type receiptsExpenseRepo struct {
+	mockExpenseRepo
+	limit, offset int
+}
+
+func (r *receiptsExpenseRepo) GetReceiptsByGroupID(_ context.Context, groupID string, limit, offset int) ([]models.ReceiptGalleryItem, int, error) {
+	r.limit, r.offset = limit, offset
+	return []models.ReceiptGalleryItem{{ExpenseID: "e1", ImagePath: "receipts/e1.jpg"}}, 41, nil
+}
+
+func TestGetReceipts(t *testing.T) {
+	tests := []struct {
+		name          string
+		userID        string
+		limit, offset int
+		expectedLimit int
+		expectedCode  apperrors.ErrorCode
+	}{
+		{name: "Default Page Size", userID: "alice", expectedLimit: ReceiptGalleryPageSize},
+		{name: "Explicit Page", userID: "alice", limit: 10, offset: 40, expectedLimit: 10},
+		{name: "Largest Page", userID: "alice", limit: MaxReceiptGalleryPageSize, expectedLimit: MaxReceiptGalleryPageSize},
+		{name: "Page Too Large", userID: "alice", limit: MaxReceiptGalleryPageSize + 1, expectedCode: apperrors.CodeInvalidRequest},
+		{name: "Negative Limit", userID: "alice", limit: -1, expectedCode: apperrors.CodeInvalidRequest},
+		{name: "Negative Offset", userID: "alice", offset: -1, expectedCode: apperrors.CodeInvalidRequest},
+		{name: "Not A Member", userID: "mallory", expectedCode: apperrors.CodeNotGroupMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expenses := &receiptsExpenseRepo{}
+			s := &groupService{
+				groupRepo:   &countingMemberRepo{members: map[string]bool{"g1/alice": true}},
+				expenseRepo: expenses,
+			}
+
+			page, err := s.GetReceipts(context.Background(), "g1", tt.userID, tt.limit, tt.offset)
+			if tt.expectedCode != "" {
+				if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+					t.Errorf("GetReceipts() error = %v, expected %s", err, tt.expectedCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetReceipts() error = %v", err)
+			}
+			if expenses.limit != tt.expectedLimit || expenses.offset != tt.offset {
+				t.Errorf("GetReceipts() queried limit %d offset %d, expected %d and %d", expenses.limit, expenses.offset, tt.expectedLimit, tt.offset)
+			}
+			if page.Limit != tt.expectedLimit || page.Offset != tt.offset || page.Total != 41 || len(page.Items) != 1 {
+				t.Errorf("GetReceipts() = %+v, expected one item of 41 at limit %d offset %d", page, tt.expectedLimit, tt.offset)
+			}
+		})
+	}
+}
+
+// txExpenseRepo keeps the expenses created through its WithTx views once
+// their transaction commits.
+type txExpenseRepo struct {
+	mockExpenseRepo
+	q        database.Querier
+	expenses map[string]*models.Expense
+}
+
+func (r *txExpenseRepo) WithTx(q database.Querier) repository.ExpenseRepository {
+	return &txExpenseRepo{q: q, expenses: r.expenses}
+}
+
+func (r *txExpenseRepo) Create(_ context.Context, expense *models.Expense) error {
+	inTx(r.q, func() { r.expenses[expense.ID] = expense })
+	return nil
+}
+
+func (r *txExpenseRepo) CreatePayer(context.Context, *models.ExpensePayer) error {
+	return nil
+}
+
+func (r *txExpenseRepo) CreateSplit(context.Context, *models.ExpenseSplit) error {
+	return nil
+}
+
+func (r *txExpenseRepo) GetByID(_ context.Context, id string) (*models.Expense, error) {
+	if expense, ok := r.expenses[id]; ok {
+		return expense, nil
+	}
+	return nil, fmt.Errorf("getting expense: no rows in result set")
+}
+
+type txActivityRepo struct {
+	stubActivityRepository
+	q          database.Querier
+	activities *[]models.GroupActivity
+}
+
+func (r *txActivityRepo) WithTx(q database.Querier) repository.ActivityRepository {
+	return &txActivityRepo{q: q, activities: r.activities}
+}
+
+func (r *txActivityRepo) Create(_ context.Context, activity *models.GroupActivity) error {
+	inTx(r.q, func() { *r.activities = append(*r.activities, *activity) })
+	return nil
+}
+
+func TestCreateCoverChecksGroupLimits(t *testing.T) {
+	maxAmount := 1000.0
+
+	tests := []struct {
+		name             string
+		amount           float64
+		action           models.GroupLimitAction
+		confirmOverLimit bool
+		expectedCode     apperrors.ErrorCode
+		expectedActivity models.GroupActivityAction
+		expectFlagged    bool
+	}{
+		{name: "Within Limit", amount: 250, action: models.GroupLimitActionReject},
+		{name: "Over Limit Rejected", amount: 12000, action: models.GroupLimitActionReject, expectedCode: apperrors.CodeExpenseLimitExceeded},
+		{name: "Over Limit Confirmed", amount: 12000, action: models.GroupLimitActionReject, confirmOverLimit: true, expectedActivity: models.GroupActivityLimitOverride},
+		{name: "Over Limit Flagged", amount: 12000, action: models.GroupLimitActionFlag, expectedActivity: models.GroupActivityLimitFlagged, expectFlagged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var activities []models.GroupActivity
+			expenses := &txExpenseRepo{expenses: map[string]*models.Expense{}}
+			runner := &fakeTxRunner{}
+			s := &groupService{
+				groupRepo: &fixedGroupRepo{
+					mockGroupRepo: mockGroupRepo{limits: &models.GroupLimits{MaxExpenseAmount: &maxAmount, Action: tt.action, Currency: "INR"}},
+					groups:        map[string]*models.Group{"g1": {ID: "g1", DefaultCurrency: "INR"}},
+				},
+				userRepo:     &fakeUserRepo{users: map[string]*models.User{"alice": {ID: "alice", Name: "Alice"}, "bob": {ID: "bob", Name: "Bob"}}},
+				expenseRepo:  expenses,
+				activityRepo: &txActivityRepo{activities: &activities},
+				quotaService: NewQuotaService(stubQuotaRepository{}, QuotaLimits{}),
+				db:           runner,
+			}
+
+			expense, err := s.CreateCover(context.Background(), "g1", "alice", "alice", "bob", tt.amount, "", tt.confirmOverLimit)
+			if tt.expectedCode != "" {
+				if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+					t.Errorf("CreateCover() error = %v, expected %s", err, tt.expectedCode)
+				}
+				if len(expenses.expenses) != 0 || runner.rollbacks != 1 {
+					t.Errorf("CreateCover() saved %d expenses with %d rollbacks, expected nothing saved", len(expenses.expenses), runner.rollbacks)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateCover() error = %v", err)
+			}
+			if expense.LimitFlagged != tt.expectFlagged {
+				t.Errorf("CreateCover() limit_flagged = %v, expected %v", expense.LimitFlagged, tt.expectFlagged)
+			}
+			if tt.expectedActivity == "" {
+				if len(activities) != 0 {
+					t.Errorf("CreateCover() recorded %+v, expected no limit activity", activities)
+				}
+				return
+			}
+			if len(activities) != 1 || activities[0].Action != tt.expectedActivity || activities[0].ExpenseID == nil || *activities[0].ExpenseID != expense.ID {
+				t.Errorf("CreateCover() activities = %+v, expected one %s for the cover", activities, tt.expectedActivity)
+			}
+		})
+	}
+}
+
+type fixedQuotaRepo struct {
+	stubQuotaRepository
+	expenses int
+	exempt   map[string]bool
+}
+
+func (r *fixedQuotaRepo) CountGroupExpenses(context.Context, string) (int, error) {
+	return r.expenses, nil
+}
+
+func (r *fixedQuotaRepo) IsExempt(_ context.Context, userID string) (bool, error) {
+	return r.exempt[userID], nil
+}
+
+func TestCreateCoverChecksExpenseQuota(t *testing.T) {
+	tests := []struct {
+		name         string
+		requester    string
+		expectedCode apperrors.ErrorCode
+	}{
+		{name: "Over Quota", requester: "alice", expectedCode: apperrors.CodeExpenseQuotaExceeded},
+		{name: "Exempt Requester", requester: "bob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expenses := &txExpenseRepo{expenses: map[string]*models.Expense{}}
+			s := &groupService{
+				groupRepo: &fixedGroupRepo{
+					groups: map[string]*models.Group{"g1": {ID: "g1", DefaultCurrency: "INR"}},
+				},
+				userRepo:     &fakeUserRepo{users: map[string]*models.User{"alice": {ID: "alice", Name: "Alice"}, "bob": {ID: "bob", Name: "Bob"}}},
+				expenseRepo:  expenses,
+				activityRepo: &txActivityRepo{activities: &[]models.GroupActivity{}},
+				quotaService: NewQuotaService(&fixedQuotaRepo{expenses: 5, exempt: map[string]bool{"bob": true}}, QuotaLimits{ExpensesPerGroup: 5}),
+				db:           &fakeTxRunner{},
+			}
+
+			_, err := s.CreateCover(context.Background(), "g1", tt.requester, "alice", "bob", 100, "", false)
+			if tt.expectedCode == "" {
+				if err != nil {
+					t.Fatalf("CreateCover() error = %v", err)
+				}
+				if len(expenses.expenses) != 1 {
+					t.Errorf("CreateCover() saved %d expenses, expected 1", len(expenses.expenses))
+				}
+				return
+			}
+			if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+				t.Errorf("CreateCover() error = %v, expected %s", err, tt.expectedCode)
+			}
+			if len(expenses.expenses) != 0 {
+				t.Errorf("CreateCover() saved %d expenses, expected none", len(expenses.expenses))
+			}
+		})
+	}
+}
+
+func TestCreateSettlementRequiresVerifiedEmailAboveLimit(t *testing.T) {
+	verified := true
+	tests := []struct {
+		name            string
+		groupID         string
+		amount          float64
+		requireVerified bool
+		tokenVerified   *bool
+		expectedCode    apperrors.ErrorCode
+	}{
+		{name: "Under INR Limit", groupID: "inr", amount: 800, requireVerified: true},
+		{name: "Over INR Limit", groupID: "inr", amount: 1500, requireVerified: true, expectedCode: apperrors.CodeEmailNotVerified},
+		{name: "Over USD Limit", groupID: "usd", amount: 50, requireVerified: true, expectedCode: apperrors.CodeEmailNotVerified},
+		{name: "Under USD Limit", groupID: "usd", amount: 10, requireVerified: true},
+		{name: "Verified Token", groupID: "usd", amount: 50, requireVerified: true, tokenVerified: &verified},
+		{name: "Verification Disabled", groupID: "inr", amount: 1500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{users: map[string]*models.User{"alice": {ID: "alice", Name: "Alice"}, "bob": {ID: "bob", Name: "Bob"}}}
+			expenses := &txExpenseRepo{expenses: map[string]*models.Expense{}}
+			s := &groupService{
+				groupRepo: &fixedGroupRepo{groups: map[string]*models.Group{
+					"inr": {ID: "inr", DefaultCurrency: "INR"},
+					"usd": {ID: "usd", DefaultCurrency: "USD"},
+				}},
+				userRepo:      users,
+				expenseRepo:   expenses,
+				emailVerifier: &userService{userRepo: users, requireVerified: tt.requireVerified},
+				db:            &fakeTxRunner{},
+			}
+			ctx := context.Background()
+			if tt.tokenVerified != nil {
+				ctx = WithEmailVerified(ctx, *tt.tokenVerified)
+			}
+
+			_, err := s.CreateSettlement(ctx, tt.groupID, "alice", "alice", "bob", tt.amount, models.SettlementDetails{})
+			if tt.expectedCode == "" {
+				if err != nil {
+					t.Fatalf("CreateSettlement() error = %v", err)
+				}
+				return
+			}
+			if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+				t.Errorf("CreateSettlement() error = %v, expected %s", err, tt.expectedCode)
+			}
+			if len(expenses.expenses) != 0 {
+				t.Errorf("CreateSettlement() saved %d transactions, expected none", len(expenses.expenses))
+			}
+		})
+	}
+}
+
+func TestUnverifiedSettlementLimit(t *testing.T) {
+	tests := []struct {
+		currency string
+		expected float64
+	}{
+		{currency: "INR", expected: 1000},
+		{currency: "usd", expected: 12},
+		{currency: "JPY", expected: 1800},
+		{currency: "NZD", expected: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			if got := unverifiedSettlementLimit(tt.currency); got != tt.expected {
+				t.Errorf("unverifiedSettlementLimit(%q) = %v, expected %v", tt.currency, got, tt.expected)
+			}
+		})
+	}
+}
+
+type noReminderResponsesRepo struct {
+	stubReminderResponseRepository
+}
+
+func (r noReminderResponsesRepo) GetActiveByGroupID(context.Context, string, time.Time) ([]models.ReminderResponse, error) {
+	return nil, nil
+}
+
+func TestGetBalancesEdgeListAsOf(t *testing.T) {
+	asOf := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		asOf     *time.Time
+		expected string
+	}{
+		{name: "Echoes As Of", asOf: &asOf, expected: "2024-05-31"},
+		{name: "Current Balances", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &asOfExpenseRepo{mockExpenseRepo: mockExpenseRepo{balances: map[string]map[string]float64{
+				"alice": {"INR": 300},
+				"bob":   {"INR": -300},
+			}}}
+			s := &groupService{
+				groupRepo:            &mockGroupRepo{},
+				userRepo:             &fakeUserRepo{users: map[string]*models.User{"alice": {ID: "alice", Name: "Alice"}, "bob": {ID: "bob", Name: "Bob"}}},
+				expenseRepo:          repo,
+				reminderResponseRepo: noReminderResponsesRepo{},
+				settlementService:    NewSettlementService(repo, &mockGroupRepo{}),
+			}
+
+			balances, err := s.GetBalancesEdgeList(context.Background(), "g1", "alice", tt.asOf)
+			if err != nil {
+				t.Fatalf("GetBalancesEdgeList() error = %v", err)
+			}
+			if balances.AsOf != tt.expected {
+				t.Errorf("GetBalancesEdgeList() as_of = %q, expected %q", balances.AsOf, tt.expected)
+			}
+			for _, queried := range repo.memberAsOf {
+				if queried != tt.asOf {
+					t.Errorf("balances queried as of %v, expected %v", queried, tt.asOf)
+				}
+			}
+			if len(repo.memberAsOf) != 2 {
+				t.Errorf("balances queried %d times, expected 2 (balances and settlements)", len(repo.memberAsOf))
+			}
+			if len(balances.Debts) != 1 || balances.Debts[0].Amount != 300 {
+				t.Errorf("GetBalancesEdgeList() debts = %+v, expected bob owing alice 300", balances.Debts)
+			}
+		})
+	}
+}
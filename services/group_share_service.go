@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type GroupShareService interface {
+	CreateLink(ctx context.Context, groupID, userID string) (*models.GroupShareLink, error)
+	GetLinks(ctx context.Context, groupID, userID string) ([]models.GroupShareLink, error)
+	RevokeLink(ctx context.Context, groupID, linkID, userID string) error
+	GetSharedLedger(ctx context.Context, token string) (*models.SharedLedger, error)
+}
+
+type sharedLedgerExpenses interface {
+	repository.ExpenseReader
+	repository.BalanceQueries
+}
+
+type groupShareService struct {
+	shareRepo    repository.GroupShareLinkRepository
+	groupRepo    repository.GroupRepository
+	expenseRepo  sharedLedgerExpenses
+	activityRepo repository.ActivityRepository
+	db           *database.DB
+}
+
+func NewGroupShareService(shareRepo repository.GroupShareLinkRepository, groupRepo repository.GroupRepository, expenseRepo sharedLedgerExpenses, activityRepo repository.ActivityRepository, db *database.DB) GroupShareService {
+	return &groupShareService{
+		shareRepo:    shareRepo,
+		groupRepo:    groupRepo,
+		expenseRepo:  expenseRepo,
+		activityRepo: activityRepo,
+		db:           db,
+	}
+}
+
+func (s *groupShareService) CreateLink(ctx context.Context, groupID, userID string) (*models.GroupShareLink, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	active, err := s.shareRepo.CountActive(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("counting share links", err)
+	}
+	if active >= MaxGroupShareLinks {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("A group can have at most %d share links. Revoke one first.", MaxGroupShareLinks))
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, apperrors.InternalError(fmt.Errorf("generating share token: %w", err))
+	}
+
+	link := &models.GroupShareLink{
+		ID:        uuid.New().String(),
+		GroupID:   groupID,
+		CreatedBy: &userID,
+	}
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.shareRepo.WithTx(q).Create(ctx, link, hashShareToken(token)); err != nil {
+			return apperrors.DatabaseError("creating share link", err)
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityShareLinkCreated,
+			Message: "Created a read-only share link to the group's transactions",
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("Group share link created",
+		zap.String("share_link_id", link.ID),
+		zap.String("group_id", groupID),
+		zap.String("user_id", userID))
+
+	link.Token = token
+	return link, nil
+}
+
+func (s *groupShareService) GetLinks(ctx context.Context, groupID, userID string) ([]models.GroupShareLink, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+	links, err := s.shareRepo.GetActiveByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting share links", err)
+	}
+	return links, nil
+}
+
+func (s *groupShareService) RevokeLink(ctx context.Context, groupID, linkID, userID string) error {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return err
+	}
+	link, err := s.shareRepo.GetByID(ctx, linkID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.NotFound("Share link")
+		}
+		return apperrors.DatabaseError("getting share link", err)
+	}
+	if link.GroupID != groupID {
+		return apperrors.NotFound("Share link")
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		revoked, err := s.shareRepo.WithTx(q).Revoke(ctx, linkID, userID)
+		if err != nil {
+			return apperrors.DatabaseError("revoking share link", err)
+		}
+		if !revoked {
+			return apperrors.NotFound("Share link")
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityShareLinkRevoked,
+			Message: "Revoked a read-only share link",
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info("Group share link revoked",
+		zap.String("share_link_id", linkID),
+		zap.String("group_id", groupID),
+		zap.String("user_id", userID))
+	return nil
+}
+
+// Unknown and revoked tokens both look like a missing link.
+func (s *groupShareService) GetSharedLedger(ctx context.Context, token string) (*models.SharedLedger, error) {
+	groupID, err := s.shareRepo.ResolveToken(ctx, hashShareToken(token))
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.NotFound("Share link")
+		}
+		return nil, apperrors.DatabaseError("resolving share link", err)
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group", err)
+	}
+	transactions, err := s.expenseRepo.GetTransactionsByGroupID(ctx, groupID, models.TransactionSort{Field: models.TransactionSortDate, Order: models.SortOrderDesc})
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting transactions", err)
+	}
+	totals, err := s.expenseRepo.GetGroupSpendByCurrency(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group totals", err)
+	}
+	balances, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, nil)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting member balances", err)
+	}
+
+	return buildSharedLedger(group, transactions, totals, balances), nil
+}
+
+// The public view has names instead of user IDs, and no emails, avatars,
+// receipts, settlement references or comments.
+func buildSharedLedger(group *models.Group, transactions []models.Transaction, totals []models.CurrencySpend, balances map[string]map[string]float64) *models.SharedLedger {
+	names := memberNamesOf(group.Members)
+	name := func(userID string) string {
+		if n, ok := names[userID]; ok {
+			return n
+		}
+		return "Former member"
+	}
+
+	ledger := &models.SharedLedger{
+		GroupName:       group.Name,
+		DefaultCurrency: groupCurrency(group),
+		Totals:          totals,
+		Members:         []models.SharedLedgerMember{},
+		Transactions:    make([]models.SharedLedgerTransaction, 0, len(transactions)),
+	}
+	if ledger.Totals == nil {
+		ledger.Totals = []models.CurrencySpend{}
+	}
+
+	memberIDs := make([]string, 0, len(group.Members))
+	for _, m := range group.Members {
+		memberIDs = append(memberIDs, m.ID)
+	}
+	var formerIDs []string
+	for userID := range balances {
+		if _, ok := names[userID]; !ok {
+			formerIDs = append(formerIDs, userID)
+		}
+	}
+	sort.Strings(formerIDs)
+
+	for _, userID := range append(memberIDs, formerIDs...) {
+		member := models.SharedLedgerMember{Name: name(userID), Balances: []models.CurrencyAmount{}}
+		for currency, amount := range balances[userID] {
+			if amount == 0 {
+				continue
+			}
+			member.Balances = append(member.Balances, models.CurrencyAmount{Currency: currency, Amount: amount})
+		}
+		sort.Slice(member.Balances, func(i, j int) bool { return member.Balances[i].Currency < member.Balances[j].Currency })
+		if _, isMember := names[userID]; !isMember && len(member.Balances) == 0 {
+			continue
+		}
+		ledger.Members = append(ledger.Members, member)
+	}
+
+	for _, t := range transactions {
+		shared := models.SharedLedgerTransaction{
+			Date:        t.Date,
+			Description: t.Expense.Description,
+			Type:        t.Category,
+			Amount:      t.TotalAmount,
+			Currency:    t.Currency,
+			PaidBy:      make([]models.SharedLedgerShare, 0, len(t.Payers)),
+			SplitAmong:  make([]models.SharedLedgerShare, 0, len(t.Splits)),
+		}
+		for _, p := range t.Payers {
+			shared.PaidBy = append(shared.PaidBy, models.SharedLedgerShare{Name: name(p.UserID), Amount: p.AmountPaid})
+		}
+		if len(shared.PaidBy) == 0 && t.PaidByUserID != nil {
+			shared.PaidBy = append(shared.PaidBy, models.SharedLedgerShare{Name: name(*t.PaidByUserID), Amount: t.TotalAmount})
+		}
+		for _, split := range t.Splits {
+			shared.SplitAmong = append(shared.SplitAmong, models.SharedLedgerShare{Name: name(split.UserID), Amount: split.Amount})
+		}
+		ledger.Transactions = append(ledger.Transactions, shared)
+	}
+	return ledger
+}
+
+// Share tokens are bearer credentials: 256 bits, stored only as a hash.
+func newShareToken() (string, error) {
+	b := make([]byte, ShareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
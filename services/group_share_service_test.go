@@ -0,0 +1,66 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestBuildSharedLedgerHidesPersonalDetails(t *testing.T) {
+	avatar := "https://cdn.example.com/alice.png"
+	payer := "alice"
+	group := &models.Group{
+		Name: "Goa trip",
+		Members: []models.User{
+			{ID: "alice", Name: "Alice", Email: "alice@example.com", AvatarURL: &avatar},
+			{ID: "bob", Name: "Bob", Email: "bob@example.com"},
+		},
+	}
+	transactions := []models.Transaction{{
+		Expense: models.Expense{
+			ID: "e1", PaidByUserID: &payer, TotalAmount: 900, Currency: "INR", Description: "Dinner",
+			Category: models.TransactionCategoryExpense, Date: "2024-03-01",
+			Payers: []models.ExpensePayer{{UserID: "alice", AmountPaid: 900}},
+			Splits: []models.ExpenseSplit{{UserID: "alice", Amount: 300}, {UserID: "bob", Amount: 300}, {UserID: "carol", Amount: 300}},
+		},
+		PaidByUser: &models.User{ID: "alice", Email: "alice@example.com", AvatarURL: &avatar},
+	}}
+	balances := map[string]map[string]float64{
+		"alice": {"INR": 600},
+		"bob":   {"INR": -300},
+		"carol": {"INR": -300},
+		"dave":  {"INR": 0},
+	}
+
+	ledger := buildSharedLedger(group, transactions, nil, balances)
+
+	body, err := json.Marshal(ledger)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, leak := range []string{"example.com", "alice\"", "e1"} {
+		if strings.Contains(string(body), leak) {
+			t.Errorf("shared ledger contains %q: %s", leak, body)
+		}
+	}
+
+	if ledger.DefaultCurrency != "INR" {
+		t.Errorf("DefaultCurrency = %q, want INR", ledger.DefaultCurrency)
+	}
+	var names []string
+	for _, m := range ledger.Members {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, ","); got != "Alice,Bob,Former member" {
+		t.Errorf("members = %s, want Alice,Bob,Former member", got)
+	}
+	splits := ledger.Transactions[0].SplitAmong
+	if len(splits) != 3 || splits[2].Name != "Former member" {
+		t.Errorf("split_among = %+v", splits)
+	}
+	if paid := ledger.Transactions[0].PaidBy; len(paid) != 1 || paid[0].Name != "Alice" || paid[0].Amount != 900 {
+		t.Errorf("paid_by = %+v", paid)
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"strings"
+
+	"unwise-backend/models"
+)
+
+var groupTemplates = []models.GroupTemplate{
+	{
+		ID:               "trip",
+		Name:             "Trip",
+		Description:      "Travel with friends: stays, transport and meals",
+		Type:             models.GroupTypeTrip,
+		Categories:       []string{"stay", "transport", "food", "activities"},
+		PlaceholderSlots: []string{"Traveller 2", "Traveller 3"},
+	},
+	{
+		ID:          "flatmates",
+		Name:        "Flatmates",
+		Description: "Shared home with monthly rent and bills",
+		Type:        models.GroupTypeHome,
+		Categories:  []string{"rent", "utilities", "groceries", "household"},
+		RecurringExpenses: []models.RecurringExpenseStub{
+			{Description: "Rent", Category: "rent", Frequency: models.RecurringFrequencyMonthly},
+			{Description: "Electricity", Category: "utilities", Frequency: models.RecurringFrequencyMonthly},
+			{Description: "Internet", Category: "utilities", Frequency: models.RecurringFrequencyMonthly},
+		},
+		PlaceholderSlots: []string{"Flatmate 2", "Flatmate 3"},
+	},
+	{
+		ID:          "couple",
+		Name:        "Couple",
+		Description: "Everyday shared spending for two",
+		Type:        models.GroupTypeCouple,
+		Categories:  []string{"groceries", "dining", "rent", "utilities"},
+		RecurringExpenses: []models.RecurringExpenseStub{
+			{Description: "Rent", Category: "rent", Frequency: models.RecurringFrequencyMonthly},
+			{Description: "Utilities", Category: "utilities", Frequency: models.RecurringFrequencyMonthly},
+		},
+		PlaceholderSlots: []string{"Partner"},
+	},
+	{
+		ID:               "event",
+		Name:             "Event",
+		Description:      "One-off event such as a party or wedding",
+		Type:             models.GroupTypeOther,
+		Categories:       []string{"venue", "food", "decorations", "gifts"},
+		PlaceholderSlots: []string{"Guest 1", "Guest 2"},
+	},
+}
+
+var localeCurrencies = map[string]string{
+	"IN": "INR", "US": "USD", "GB": "GBP", "CA": "CAD", "AU": "AUD", "NZ": "NZD",
+	"SG": "SGD", "AE": "AED", "JP": "JPY", "CH": "CHF",
+	"DE": "EUR", "FR": "EUR", "ES": "EUR", "IT": "EUR", "NL": "EUR", "IE": "EUR",
+	"PT": "EUR", "AT": "EUR", "BE": "EUR", "FI": "EUR", "GR": "EUR",
+}
+
+func findGroupTemplate(id string) (models.GroupTemplate, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	for _, t := range groupTemplates {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return models.GroupTemplate{}, false
+}
+
+// currencyForLocale maps the region of a BCP 47 locale ("en-IN", "de_DE") to
+// its currency, or "" when the locale has no known region.
+func currencyForLocale(locale string) string {
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) < 2 {
+		return ""
+	}
+	for _, part := range parts[1:] {
+		if currency, ok := localeCurrencies[strings.ToUpper(part)]; ok {
+			return currency
+		}
+	}
+	return ""
+}
@@ -0,0 +1,24 @@
+package services
+
+import "testing"
+
+func TestCurrencyForLocale(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"en-IN", "INR"},
+		{"en_GB", "GBP"},
+		{"de-DE", "EUR"},
+		{"zh-Hant-SG", "SGD"},
+		{"en", ""},
+		{"en-ZZ", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := currencyForLocale(tt.locale); got != tt.expected {
+			t.Errorf("currencyForLocale(%q) = %q, expected %q", tt.locale, got, tt.expected)
+		}
+	}
+}
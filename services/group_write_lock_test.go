@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/repository"
+)
+
+// lockingGroupRepo models the group row lock with a RWMutex. LockForShare
+// takes the read side; the test holds the write side for a member removal
+// and releases it when the removal commits.
+type lockingGroupRepo struct {
+	stubGroupRepository
+	lock    sync.RWMutex
+	mu      sync.Mutex
+	members map[string]bool
+	shared  chan struct{}
+}
+
+func (r *lockingGroupRepo) LockForShare(ctx context.Context, groupID string) error {
+	close(r.shared)
+	r.lock.RLock()
+	return nil
+}
+func (r *lockingGroupRepo) IsMember(ctx context.Context, groupID, userID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.members[userID], nil
+}
+func (r *lockingGroupRepo) WithTx(tx database.Querier) repository.GroupRepository { return r }
+
+func TestSettlementLosesRaceWithMemberRemoval(t *testing.T) {
+	repo := &lockingGroupRepo{members: map[string]bool{"A": true, "B": true}, shared: make(chan struct{})}
+
+	// The removal holds the group lock when the settlement reaches it.
+	repo.lock.Lock()
+	done := make(chan error)
+	go func() {
+		done <- lockGroupForWrite(context.Background(), repo, nil, "g1", "A", "B")
+	}()
+
+	<-repo.shared
+	repo.mu.Lock()
+	delete(repo.members, "B")
+	repo.mu.Unlock()
+	repo.lock.Unlock()
+
+	err := <-done
+	appErr, ok := apperrors.AsAppError(err)
+	if !ok || appErr.Code != apperrors.NotGroupMember().Code {
+		t.Errorf("lockGroupForWrite() error = %v, expected %s once the receiver was removed", err, apperrors.NotGroupMember().Code)
+	}
+}
@@ -2,10 +2,13 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,27 +24,36 @@ import (
 
 type ImportService interface {
 	PreviewSplitwiseCSV(ctx context.Context, groupID, userID string, file io.Reader) (*SplitwisePreviewResult, error)
-	ImportSplitwiseCSV(ctx context.Context, groupID, userID string, file io.Reader, memberMapping map[string]*string) (*SplitwiseImportResult, error)
+	ImportSplitwiseCSV(ctx context.Context, groupID, userID string, file io.Reader, memberMapping map[string]*string, importDuplicates bool) (*SplitwiseImportResult, error)
 }
 
 type importService struct {
-	groupRepo   repository.GroupRepository
-	userRepo    repository.UserRepository
-	expenseRepo repository.ExpenseRepository
-	db          *database.DB
+	groupRepo        repository.GroupRepository
+	userRepo         repository.UserRepository
+	expenseRepo      repository.ExpenseRepository
+	balanceEventRepo repository.BalanceEventRepository
+	importRepo       repository.ImportRepository
+	quotaService     QuotaService
+	db               *database.DB
 }
 
 func NewImportService(
 	groupRepo repository.GroupRepository,
 	userRepo repository.UserRepository,
 	expenseRepo repository.ExpenseRepository,
+	balanceEventRepo repository.BalanceEventRepository,
+	importRepo repository.ImportRepository,
+	quotaService QuotaService,
 	db *database.DB,
 ) ImportService {
 	return &importService{
-		groupRepo:   groupRepo,
-		userRepo:    userRepo,
-		expenseRepo: expenseRepo,
-		db:          db,
+		groupRepo:        groupRepo,
+		userRepo:         userRepo,
+		expenseRepo:      expenseRepo,
+		balanceEventRepo: balanceEventRepo,
+		importRepo:       importRepo,
+		quotaService:     quotaService,
+		db:               db,
 	}
 }
 
@@ -53,20 +65,48 @@ type SplitwisePreviewResult struct {
 	CSVMembers        []string           `json:"csv_members"`
 	GroupMembers      []models.User      `json:"group_members"`
 	SuggestedMappings map[string]*string `json:"suggested_mappings"`
+	RememberedMembers []string           `json:"remembered_members"`
 	ExpenseCount      int                `json:"expense_count"`
 	PaymentCount      int                `json:"payment_count"`
+	DuplicateCount    int                `json:"duplicate_count"`
 	TotalAmount       float64            `json:"total_amount"`
 }
 
 type SplitwiseImportResult struct {
-	Success             bool     `json:"success"`
-	ImportedExpenses    int      `json:"imported_expenses"`
-	ImportedPayments    int      `json:"imported_payments"`
-	CreatedPlaceholders []string `json:"created_placeholders"`
-	Errors              []string `json:"errors,omitempty"`
+	Success             bool              `json:"success"`
+	ImportedExpenses    int               `json:"imported_expenses"`
+	ImportedPayments    int               `json:"imported_payments"`
+	CreatedPlaceholders []string          `json:"created_placeholders"`
+	SkippedDuplicates   int               `json:"skipped_duplicates"`
+	Duplicates          []ImportDuplicate `json:"duplicates,omitempty"`
+	Errors              []string          `json:"errors,omitempty"`
+	RowErrors           []ImportRowError  `json:"row_errors,omitempty"`
+}
+
+// ImportDuplicate is a CSV row an earlier import into the group already
+// brought in. Imported is true when the row was imported again anyway.
+type ImportDuplicate struct {
+	Row               int    `json:"row"`
+	Description       string `json:"description,omitempty"`
+	ExistingExpenseID string `json:"existing_expense_id"`
+	Imported          bool   `json:"imported"`
+}
+
+// ImportRowError describes why one CSV row was not imported. Row is the line
+// number in the file, counting the header as line 1.
+type ImportRowError struct {
+	Row         int    `json:"row"`
+	Description string `json:"description,omitempty"`
+	Error       string `json:"error"`
+}
+
+func (r *SplitwiseImportResult) addRowError(row int, description string, err error) {
+	r.Errors = append(r.Errors, fmt.Sprintf("Row %d: %v", row, err))
+	r.RowErrors = append(r.RowErrors, ImportRowError{Row: row, Description: description, Error: err.Error()})
 }
 
 type SplitwiseRow struct {
+	Line        int
 	Date        time.Time
 	Description string
 	Category    string
@@ -104,6 +144,7 @@ func (s *importService) PreviewSplitwiseCSV(ctx context.Context, groupID, userID
 	expenseCount := 0
 	paymentCount := 0
 	totalAmount := 0.0
+	var fingerprints []string
 
 	for {
 		row, err := reader.Read()
@@ -131,20 +172,45 @@ func (s *importService) PreviewSplitwiseCSV(ctx context.Context, groupID, userID
 			expenseCount++
 			totalAmount += cost
 		}
+
+		if parsed, err := s.parseSplitwiseRow(row, csvMembers); err == nil {
+			fingerprints = append(fingerprints, importFingerprint(*parsed))
+		}
+	}
+
+	existing, err := s.importRepo.GetFingerprints(ctx, groupID, fingerprints)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking for imported rows", err)
+	}
+	duplicateCount := 0
+	for _, fingerprint := range fingerprints {
+		if _, ok := existing[fingerprint]; ok {
+			duplicateCount++
+		}
 	}
 
 	groupMembers, err := s.groupRepo.GetMembers(ctx, groupID)
 	if err != nil {
 		return nil, apperrors.DatabaseError("getting group members", err)
 	}
+	remembered, err := s.rememberedMappings(ctx, groupID, groupMembers)
+	if err != nil {
+		return nil, err
+	}
 
 	suggestedMappings := make(map[string]*string)
+	rememberedMembers := []string{}
 	for _, csvMember := range csvMembers {
 		suggestedMappings[csvMember] = nil
+		if id, ok := remembered[csvMember]; ok {
+			suggestedMappings[csvMember] = &id
+			rememberedMembers = append(rememberedMembers, csvMember)
+			continue
+		}
 
-		csvNameLower := strings.ToLower(strings.TrimSpace(csvMember))
+		csvNameLower := strings.ToLower(NormalizeName(csvMember))
 		for _, gm := range groupMembers {
-			gmNameLower := strings.ToLower(strings.TrimSpace(gm.Name))
+			gmNameLower := strings.ToLower(NormalizeName(gm.Name))
 			if csvNameLower == gmNameLower {
 				id := gm.ID
 				suggestedMappings[csvMember] = &id
@@ -157,13 +223,19 @@ func (s *importService) PreviewSplitwiseCSV(ctx context.Context, groupID, userID
 		CSVMembers:        csvMembers,
 		GroupMembers:      groupMembers,
 		SuggestedMappings: suggestedMappings,
+		RememberedMembers: rememberedMembers,
 		ExpenseCount:      expenseCount,
 		PaymentCount:      paymentCount,
+		DuplicateCount:    duplicateCount,
 		TotalAmount:       totalAmount,
 	}, nil
 }
 
-func (s *importService) ImportSplitwiseCSV(ctx context.Context, groupID, userID string, file io.Reader, memberMapping map[string]*string) (*SplitwiseImportResult, error) {
+// ImportSplitwiseCSV imports a Splitwise export into the group. CSV members
+// missing from memberMapping are mapped as the last import into the group
+// mapped them. Rows an earlier import already brought in are skipped unless
+// importDuplicates is set; either way they are listed in the result.
+func (s *importService) ImportSplitwiseCSV(ctx context.Context, groupID, userID string, file io.Reader, memberMapping map[string]*string, importDuplicates bool) (*SplitwiseImportResult, error) {
 	zap.L().Info("Starting Splitwise CSV import",
 		zap.String("group_id", groupID),
 		zap.String("user_id", userID),
@@ -188,11 +260,18 @@ func (s *importService) ImportSplitwiseCSV(ctx context.Context, groupID, userID
 	for i, name := range csvMembers {
 		csvMembers[i] = strings.TrimSpace(name)
 	}
+	memberMapping, err = s.withRememberedMappings(ctx, groupID, csvMembers, memberMapping)
+	if err != nil {
+		return nil, err
+	}
 	for _, csvMember := range csvMembers {
 		if _, ok := memberMapping[csvMember]; !ok {
 			return nil, apperrors.InvalidRequest(fmt.Sprintf("Member '%s' is not mapped", csvMember))
 		}
 	}
+	if err := s.validateMemberMapping(ctx, groupID, memberMapping); err != nil {
+		return nil, err
+	}
 
 	result := &SplitwiseImportResult{
 		Success:             true,
@@ -201,6 +280,7 @@ func (s *importService) ImportSplitwiseCSV(ctx context.Context, groupID, userID
 	}
 
 	var rows []SplitwiseRow
+	seen := make(map[string]int)
 	rowNum := 1
 	for {
 		record, err := reader.Read()
@@ -209,34 +289,105 @@ func (s *importService) ImportSplitwiseCSV(ctx context.Context, groupID, userID
 		}
 		rowNum++
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Row %d: Failed to parse - %v", rowNum, err))
+			result.addRowError(rowNum, "", fmt.Errorf("Failed to parse - %v", err))
 			continue
 		}
 
 		row, err := s.parseSplitwiseRow(record, csvMembers)
 		if err != nil {
 			if err.Error() != "skip" {
-				result.Errors = append(result.Errors, fmt.Sprintf("Row %d: %v", rowNum, err))
+				result.addRowError(rowNum, "", err)
 			}
 			continue
 		}
+		row.Line = rowNum
+
+		if err := validateSplitwiseRow(*row); err != nil {
+			result.addRowError(rowNum, row.Description, err)
+			continue
+		}
+
+		key := splitwiseRowKey(*row)
+		if first, ok := seen[key]; ok {
+			result.addRowError(rowNum, row.Description, fmt.Errorf("duplicate of row %d", first))
+			continue
+		}
+		seen[key] = rowNum
 
 		rows = append(rows, *row)
 	}
 
+	fingerprints := make([]string, len(rows))
+	for i, row := range rows {
+		fingerprints[i] = importFingerprint(row)
+	}
+	existing, err := s.importRepo.GetFingerprints(ctx, groupID, fingerprints)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking for imported rows", err)
+	}
+	if len(existing) > 0 {
+		fresh := make([]SplitwiseRow, 0, len(rows))
+		for i, row := range rows {
+			if expenseID, ok := existing[fingerprints[i]]; ok {
+				result.Duplicates = append(result.Duplicates, ImportDuplicate{
+					Row:               row.Line,
+					Description:       row.Description,
+					ExistingExpenseID: expenseID,
+					Imported:          importDuplicates,
+				})
+				if !importDuplicates {
+					result.SkippedDuplicates++
+					continue
+				}
+			}
+			fresh = append(fresh, row)
+		}
+		rows = fresh
+	}
+
+	placeholders := 0
+	for _, userIDPtr := range memberMapping {
+		if userIDPtr == nil || *userIDPtr == "" {
+			placeholders++
+		}
+	}
+	if err := s.quotaService.CheckMembers(ctx, userID, groupID, placeholders); err != nil {
+		return nil, err
+	}
+	expenses := 0
+	for _, row := range rows {
+		if !isSplitwisePayment(row) {
+			expenses++
+		}
+	}
+	if err := s.quotaService.CheckExpenses(ctx, userID, groupID, expenses); err != nil {
+		return nil, err
+	}
+
 	err = s.db.WithTx(ctx, func(q database.Querier) error {
 		txGroupRepo := s.groupRepo.WithTx(q)
 		txUserRepo := s.userRepo.WithTx(q)
 		txExpenseRepo := s.expenseRepo.WithTx(q)
+		txImportRepo := s.importRepo.WithTx(q)
 		resolvedMapping := make(map[string]string)
 
+		var mappedIDs []string
+		for _, userIDPtr := range memberMapping {
+			if userIDPtr != nil && *userIDPtr != "" {
+				mappedIDs = append(mappedIDs, *userIDPtr)
+			}
+		}
+		if err := lockGroupForWrite(ctx, s.groupRepo, q, groupID, mappedIDs...); err != nil {
+			return err
+		}
+
 		for csvMember, userIDPtr := range memberMapping {
 			if userIDPtr != nil && *userIDPtr != "" {
 				resolvedMapping[csvMember] = *userIDPtr
 			} else {
 				placeholder := &models.User{
 					ID:            uuid.New().String(),
-					Name:          csvMember,
+					Name:          NormalizeName(csvMember),
 					IsPlaceholder: true,
 				}
 				if err := txUserRepo.Create(ctx, placeholder); err != nil {
@@ -252,23 +403,30 @@ func (s *importService) ImportSplitwiseCSV(ctx context.Context, groupID, userID
 				zap.L().Info("Created placeholder user", zap.String("name", csvMember), zap.String("id", placeholder.ID))
 			}
 		}
+		if err := txImportRepo.SaveMemberMappings(ctx, groupID, resolvedMapping); err != nil {
+			return err
+		}
 
-		for i, row := range rows {
-			if strings.ToLower(row.Category) == "payment" {
-				err := s.importPaymentRow(ctx, txExpenseRepo, groupID, row, resolvedMapping)
+		for _, row := range rows {
+			var expenseID string
+			if isSplitwisePayment(row) {
+				expenseID, err = s.importPaymentRow(ctx, q, txExpenseRepo, groupID, userID, row, resolvedMapping)
 				if err != nil {
-					result.Errors = append(result.Errors, fmt.Sprintf("Row %d: %v", i+2, err))
+					result.addRowError(row.Line, row.Description, err)
 					continue
 				}
 				result.ImportedPayments++
 			} else {
-				err := s.importExpenseRow(ctx, txExpenseRepo, groupID, row, resolvedMapping)
+				expenseID, err = s.importExpenseRow(ctx, q, txExpenseRepo, groupID, userID, row, resolvedMapping)
 				if err != nil {
-					result.Errors = append(result.Errors, fmt.Sprintf("Row %d: %v", i+2, err))
+					result.addRowError(row.Line, row.Description, err)
 					continue
 				}
 				result.ImportedExpenses++
 			}
+			if err := txImportRepo.AddFingerprint(ctx, groupID, importFingerprint(row), expenseID); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -276,6 +434,9 @@ func (s *importService) ImportSplitwiseCSV(ctx context.Context, groupID, userID
 
 	if err != nil {
 		zap.L().Error("Failed to import Splitwise CSV", zap.Error(err))
+		if apperrors.IsAppError(err) {
+			return nil, err
+		}
 		return nil, apperrors.DatabaseError("importing CSV", err)
 	}
 
@@ -283,11 +444,166 @@ func (s *importService) ImportSplitwiseCSV(ctx context.Context, groupID, userID
 		zap.Int("expenses", result.ImportedExpenses),
 		zap.Int("payments", result.ImportedPayments),
 		zap.Int("placeholders", len(result.CreatedPlaceholders)),
+		zap.Int("duplicates", len(result.Duplicates)),
 		zap.Int("errors", len(result.Errors)))
 
 	return result, nil
 }
 
+// validateMemberMapping makes sure every mapped user is already in the group
+// and that no two CSV members are mapped to the same user, which would merge
+// their balances. Unmapped members become placeholders and join the group.
+func (s *importService) validateMemberMapping(ctx context.Context, groupID string, memberMapping map[string]*string) error {
+	members, err := s.groupRepo.GetMembers(ctx, groupID)
+	if err != nil {
+		return apperrors.DatabaseError("getting group members", err)
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, m := range members {
+		isMember[m.ID] = true
+	}
+
+	mappedBy := make(map[string]string)
+	for csvMember, userIDPtr := range memberMapping {
+		if userIDPtr == nil || *userIDPtr == "" {
+			continue
+		}
+		if !isMember[*userIDPtr] {
+			return apperrors.InvalidRequest(fmt.Sprintf("Member '%s' is mapped to a user who is not in this group", csvMember))
+		}
+		if other, ok := mappedBy[*userIDPtr]; ok {
+			return apperrors.InvalidRequest(fmt.Sprintf("Members '%s' and '%s' are mapped to the same user", other, csvMember))
+		}
+		mappedBy[*userIDPtr] = csvMember
+	}
+	return nil
+}
+
+// rememberedMappings returns the users CSV names were mapped to by earlier
+// imports into the group, leaving out anyone no longer among members.
+func (s *importService) rememberedMappings(ctx context.Context, groupID string, members []models.User) (map[string]string, error) {
+	saved, err := s.importRepo.GetMemberMappings(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting saved import mappings", err)
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, m := range members {
+		isMember[m.ID] = true
+	}
+	for csvName, userID := range saved {
+		if !isMember[userID] {
+			delete(saved, csvName)
+		}
+	}
+	return saved, nil
+}
+
+// withRememberedMappings adds the remembered mapping for each CSV member that
+// memberMapping leaves out, unless memberMapping already maps someone else to
+// that user.
+func (s *importService) withRememberedMappings(ctx context.Context, groupID string, csvMembers []string, memberMapping map[string]*string) (map[string]*string, error) {
+	members, err := s.groupRepo.GetMembers(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group members", err)
+	}
+	remembered, err := s.rememberedMappings(ctx, groupID, members)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]*string, len(memberMapping)+len(csvMembers))
+	used := make(map[string]bool)
+	for csvMember, userIDPtr := range memberMapping {
+		merged[csvMember] = userIDPtr
+		if userIDPtr != nil && *userIDPtr != "" {
+			used[*userIDPtr] = true
+		}
+	}
+	for _, csvMember := range csvMembers {
+		if _, ok := merged[csvMember]; ok {
+			continue
+		}
+		id, ok := remembered[csvMember]
+		if !ok || used[id] {
+			continue
+		}
+		merged[csvMember] = &id
+		used[id] = true
+	}
+	return merged, nil
+}
+
+func isSplitwisePayment(row SplitwiseRow) bool {
+	return strings.ToLower(row.Category) == "payment"
+}
+
+// validateSplitwiseRow checks that a row's balances describe a real
+// transaction: they must net to zero, members cannot owe more than the cost,
+// and a payment moves the full cost from exactly one member to another.
+func validateSplitwiseRow(row SplitwiseRow) error {
+	if row.Cost <= 0 {
+		return fmt.Errorf("cost must be greater than 0")
+	}
+
+	var sum, owed, paid float64
+	var payers, receivers int
+	for _, balance := range row.Balances {
+		sum += balance
+		if balance > AmountTolerance {
+			paid += balance
+			payers++
+		} else if balance < -AmountTolerance {
+			owed += math.Abs(balance)
+			receivers++
+		}
+	}
+
+	if payers == 0 && receivers == 0 {
+		return fmt.Errorf("no member has a balance")
+	}
+	if math.Abs(sum) > BalanceThreshold+AmountTolerance {
+		return fmt.Errorf("balances sum to %.2f instead of 0", sum)
+	}
+	if owed > row.Cost+BalanceThreshold {
+		return fmt.Errorf("members owe %.2f, more than the cost of %.2f", owed, row.Cost)
+	}
+
+	if isSplitwisePayment(row) {
+		if payers != 1 || receivers != 1 {
+			return fmt.Errorf("payment must be between exactly two members")
+		}
+		if math.Abs(paid-row.Cost) > BalanceThreshold {
+			return fmt.Errorf("payment of %.2f does not match the cost of %.2f", paid, row.Cost)
+		}
+	}
+	return nil
+}
+
+// splitwiseRowKey identifies rows that would import as the same transaction.
+func splitwiseRowKey(row SplitwiseRow) string {
+	names := make([]string, 0, len(row.Balances))
+	for name := range row.Balances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%s|%.2f|%s", row.Date.Format("2006-01-02"), strings.ToLower(row.Description),
+		strings.ToLower(row.Category), row.Cost, strings.ToUpper(row.Currency))
+	for _, name := range names {
+		fmt.Fprintf(&b, "|%s=%.2f", name, row.Balances[name])
+	}
+	return b.String()
+}
+
+// importFingerprint identifies a row across imports by its date, description
+// and cost, which stay the same between exports of the same Splitwise group.
+func importFingerprint(row SplitwiseRow) string {
+	key := fmt.Sprintf("%s|%s|%.2f", row.Date.Format("2006-01-02"), strings.ToLower(row.Description), row.Cost)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
 func (s *importService) parseSplitwiseRow(record []string, memberNames []string) (*SplitwiseRow, error) {
 	if len(record) < fixedColumnCount {
 		return nil, fmt.Errorf("row has insufficient columns")
@@ -350,7 +666,7 @@ func (s *importService) parseSplitwiseRow(record []string, memberNames []string)
 	}, nil
 }
 
-func (s *importService) importExpenseRow(ctx context.Context, repo repository.ExpenseRepository, groupID string, row SplitwiseRow, memberMapping map[string]string) error {
+func (s *importService) importExpenseRow(ctx context.Context, q database.Querier, repo repository.ExpenseRepository, groupID, importerID string, row SplitwiseRow, memberMapping map[string]string) (string, error) {
 	var payers []models.ExpensePayer
 	var splits []models.ExpenseSplit
 
@@ -418,38 +734,42 @@ func (s *importService) importExpenseRow(ctx context.Context, repo repository.Ex
 	}
 
 	expense := &models.Expense{
-		ID:          expenseID,
-		GroupID:     groupID,
-		TotalAmount: row.Cost,
-		Description: row.Description,
-		Type:        models.ExpenseTypeEqual,
-		Category:    models.TransactionCategoryExpense,
-		DateISO:     row.Date,
-		Date:        row.Date.Format("2006-01-02"),
-		Time:        "12:00",
-		Payers:      payers,
+		ID:              expenseID,
+		GroupID:         groupID,
+		CreatedByUserID: &importerID,
+		TotalAmount:     row.Cost,
+		Description:     row.Description,
+		Type:            models.ExpenseTypeEqual,
+		Category:        models.TransactionCategoryExpense,
+		DateISO:         row.Date,
+		Date:            row.Date.Format("2006-01-02"),
+		Time:            "12:00",
+		Payers:          payers,
 	}
 
 	if err := repo.Create(ctx, expense); err != nil {
-		return fmt.Errorf("creating expense: %w", err)
+		return "", expenseWriteError("creating expense", err)
 	}
 
 	for _, payer := range payers {
 		if err := repo.CreatePayer(ctx, &payer); err != nil {
-			return fmt.Errorf("creating payer: %w", err)
+			return "", expenseWriteError("creating payer", err)
 		}
 	}
 
 	for _, split := range splits {
 		if err := repo.CreateSplit(ctx, &split); err != nil {
-			return fmt.Errorf("creating split: %w", err)
+			return "", expenseWriteError("creating split", err)
 		}
 	}
 
-	return nil
+	if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, expenseID, nil); err != nil {
+		return "", err
+	}
+	return expenseID, nil
 }
 
-func (s *importService) importPaymentRow(ctx context.Context, repo repository.ExpenseRepository, groupID string, row SplitwiseRow, memberMapping map[string]string) error {
+func (s *importService) importPaymentRow(ctx context.Context, q database.Querier, repo repository.ExpenseRepository, groupID, importerID string, row SplitwiseRow, memberMapping map[string]string) (string, error) {
 	expenseID := uuid.New().String()
 
 	var payerID, receiverID string
@@ -468,19 +788,20 @@ func (s *importService) importPaymentRow(ctx context.Context, repo repository.Ex
 	}
 
 	if payerID == "" || receiverID == "" {
-		return fmt.Errorf("could not determine payer and receiver for payment")
+		return "", fmt.Errorf("could not determine payer and receiver for payment")
 	}
 
 	expense := &models.Expense{
-		ID:          expenseID,
-		GroupID:     groupID,
-		TotalAmount: row.Cost,
-		Description: row.Description,
-		Type:        models.ExpenseTypeEqual,
-		Category:    models.TransactionCategoryPayment,
-		DateISO:     row.Date,
-		Date:        row.Date.Format("2006-01-02"),
-		Time:        "12:00",
+		ID:              expenseID,
+		GroupID:         groupID,
+		CreatedByUserID: &importerID,
+		TotalAmount:     row.Cost,
+		Description:     row.Description,
+		Type:            models.ExpenseTypeEqual,
+		Category:        models.TransactionCategoryPayment,
+		DateISO:         row.Date,
+		Date:            row.Date.Format("2006-01-02"),
+		Time:            "12:00",
 	}
 
 	payer := models.ExpensePayer{
@@ -498,16 +819,19 @@ func (s *importService) importPaymentRow(ctx context.Context, repo repository.Ex
 	}
 
 	if err := repo.Create(ctx, expense); err != nil {
-		return fmt.Errorf("creating payment: %w", err)
+		return "", expenseWriteError("creating payment", err)
 	}
 
 	if err := repo.CreatePayer(ctx, &payer); err != nil {
-		return fmt.Errorf("creating payment payer: %w", err)
+		return "", expenseWriteError("creating payment payer", err)
 	}
 
 	if err := repo.CreateSplit(ctx, &split); err != nil {
-		return fmt.Errorf("creating payment split: %w", err)
+		return "", expenseWriteError("creating payment split", err)
 	}
 
-	return nil
+	if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, expenseID, nil); err != nil {
+		return "", err
+	}
+	return expenseID, nil
 }
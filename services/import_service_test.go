@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateSplitwiseRow(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		category string
+		cost     float64
+		balances map[string]float64
+		wantErr  bool
+	}{
+		{"Even split", "General", 30, map[string]float64{"A": 20, "B": -10, "C": -10}, false},
+		{"Rounded thirds", "General", 10, map[string]float64{"A": 6.67, "B": -3.33, "C": -3.34}, false},
+		{"Payer not in split", "General", 20, map[string]float64{"A": 20, "B": -10, "C": -10}, false},
+		{"Balances do not net to zero", "General", 30, map[string]float64{"A": 20, "B": -10, "C": -5}, true},
+		{"Owed exceeds cost", "General", 10, map[string]float64{"A": 20, "B": -10, "C": -10}, true},
+		{"No balances", "General", 10, map[string]float64{"A": 0, "B": 0}, true},
+		{"Zero cost", "General", 0, map[string]float64{"A": 5, "B": -5}, true},
+		{"Payment", "Payment", 25, map[string]float64{"A": 25, "B": -25, "C": 0}, false},
+		{"Payment amount mismatch", "Payment", 25, map[string]float64{"A": 20, "B": -20}, true},
+		{"Payment with three members", "Payment", 20, map[string]float64{"A": 20, "B": -10, "C": -10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := SplitwiseRow{Date: date, Description: "Dinner", Category: tt.category, Cost: tt.cost, Currency: "USD", Balances: tt.balances}
+			err := validateSplitwiseRow(row)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSplitwiseRow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitwiseRowKey(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	row := SplitwiseRow{Line: 2, Date: date, Description: "Dinner", Category: "General", Cost: 30, Currency: "usd", Balances: map[string]float64{"A": 20, "B": -10, "C": -10}}
+
+	same := row
+	same.Line = 7
+	same.Description = "dinner"
+	same.Currency = "USD"
+	same.Balances = map[string]float64{"C": -10, "B": -10, "A": 20}
+	if splitwiseRowKey(row) != splitwiseRowKey(same) {
+		t.Errorf("expected rows differing only in line, case and balance order to match")
+	}
+
+	other := row
+	other.Balances = map[string]float64{"A": -10, "B": 20, "C": -10}
+	if splitwiseRowKey(row) == splitwiseRowKey(other) {
+		t.Errorf("expected rows with a different payer not to match")
+	}
+}
+
+func TestImportFingerprint(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	row := SplitwiseRow{Line: 2, Date: date, Description: "Dinner", Category: "General", Cost: 30, Currency: "USD", Balances: map[string]float64{"A": 20, "B": -10, "C": -10}}
+
+	tests := []struct {
+		name   string
+		change func(r *SplitwiseRow)
+		same   bool
+	}{
+		{"Later export", func(r *SplitwiseRow) { r.Line = 40 }, true},
+		{"Description case", func(r *SplitwiseRow) { r.Description = "DINNER" }, true},
+		{"Members renamed", func(r *SplitwiseRow) { r.Balances = map[string]float64{"Alice": 20, "Bob": -10, "Cara": -10} }, true},
+		{"Cost rounding", func(r *SplitwiseRow) { r.Cost = 30.001 }, true},
+		{"Different date", func(r *SplitwiseRow) { r.Date = date.AddDate(0, 0, 1) }, false},
+		{"Different description", func(r *SplitwiseRow) { r.Description = "Lunch" }, false},
+		{"Different cost", func(r *SplitwiseRow) { r.Cost = 31 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := row
+			tt.change(&other)
+			if got := importFingerprint(row) == importFingerprint(other); got != tt.same {
+				t.Errorf("fingerprints match = %v, expected %v", got, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,571 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultExpenseTemplate    = `{{.Actor}} added '{{.Description}}' {{.Amount}}{{range $i, $o := .Owes}}{{if $i}},{{else}} —{{end}} {{$o.Name}} owes {{$o.Amount}}{{end}}`
+	defaultSettlementTemplate = `{{.Payer}} paid {{.Receiver}} {{.Amount}}`
+)
+
+var (
+	telegramBotTokenPattern = regexp.MustCompile(`^\d+:[A-Za-z0-9_-]+$`)
+	telegramChatIDPattern   = regexp.MustCompile(`^(-?\d+|@[A-Za-z0-9_]{5,})$`)
+)
+
+type IntegrationInput struct {
+	Platform           models.IntegrationPlatform
+	WebhookURL         *string
+	BotToken           *string
+	ChatID             *string
+	NotifyExpenses     *bool
+	NotifySettlements  *bool
+	ExpenseTemplate    *string
+	SettlementTemplate *string
+	Enabled            *bool
+}
+
+type integrationMessage struct {
+	Group       string
+	Actor       string
+	Description string
+	Amount      string
+	Currency    string
+	Payer       string
+	Receiver    string
+	Owes        []integrationShare
+}
+
+type integrationShare struct {
+	Name   string
+	Amount string
+}
+
+type IntegrationService interface {
+	GetGroupIntegrations(ctx context.Context, groupID, userID string) ([]models.GroupIntegration, error)
+	CreateIntegration(ctx context.Context, groupID, userID string, input IntegrationInput) (*models.GroupIntegration, error)
+	UpdateIntegration(ctx context.Context, groupID, integrationID, userID string, input IntegrationInput) (*models.GroupIntegration, error)
+	DeleteIntegration(ctx context.Context, groupID, integrationID, userID string) error
+	GetDeliveries(ctx context.Context, groupID, integrationID, userID string) ([]models.IntegrationDelivery, error)
+	SendTestMessage(ctx context.Context, groupID, integrationID, userID string) error
+	Publish(ctx context.Context, payload NotificationPayload) error
+	RunDeliveryWorker(ctx context.Context)
+}
+
+type integrationService struct {
+	integrationRepo repository.IntegrationRepository
+	groupRepo       repository.GroupRepository
+	expenseRepo     repository.ExpenseReader
+	currencyRepo    repository.CurrencyRepository
+	client          *http.Client
+}
+
+func NewIntegrationService(integrationRepo repository.IntegrationRepository, groupRepo repository.GroupRepository, expenseRepo repository.ExpenseReader, currencyRepo repository.CurrencyRepository) IntegrationService {
+	return &integrationService{
+		integrationRepo: integrationRepo,
+		groupRepo:       groupRepo,
+		expenseRepo:     expenseRepo,
+		currencyRepo:    currencyRepo,
+		client: &http.Client{
+			Timeout: IntegrationRequestTimeout,
+			// A webhook is only checked when it's saved, so a redirect could
+			// send messages to a host that was never validated.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+func (s *integrationService) GetGroupIntegrations(ctx context.Context, groupID, userID string) ([]models.GroupIntegration, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	integrations, err := s.integrationRepo.GetByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group integrations", err)
+	}
+	for i := range integrations {
+		integrations[i].Target = integrationTarget(&integrations[i])
+	}
+	return integrations, nil
+}
+
+func (s *integrationService) CreateIntegration(ctx context.Context, groupID, userID string, input IntegrationInput) (*models.GroupIntegration, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+	if !input.Platform.IsValid() {
+		return nil, apperrors.InvalidRequest("Platform must be one of SLACK, DISCORD or TELEGRAM.")
+	}
+
+	existing, err := s.integrationRepo.GetByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group integrations", err)
+	}
+	if len(existing) >= MaxIntegrationsPerGroup {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("A group can have at most %d integrations.", MaxIntegrationsPerGroup))
+	}
+
+	integration := &models.GroupIntegration{
+		ID:                uuid.New().String(),
+		GroupID:           groupID,
+		Platform:          input.Platform,
+		NotifyExpenses:    true,
+		NotifySettlements: true,
+		Enabled:           true,
+		CreatedBy:         &userID,
+	}
+	if err := applyIntegrationInput(integration, input, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.integrationRepo.Create(ctx, integration); err != nil {
+		return nil, apperrors.DatabaseError("creating group integration", err)
+	}
+
+	zap.L().Info("Group integration created",
+		zap.String("integration_id", integration.ID),
+		zap.String("group_id", groupID),
+		zap.String("platform", string(integration.Platform)))
+
+	integration.Target = integrationTarget(integration)
+	return integration, nil
+}
+
+func (s *integrationService) UpdateIntegration(ctx context.Context, groupID, integrationID, userID string, input IntegrationInput) (*models.GroupIntegration, error) {
+	integration, err := s.getGroupIntegration(ctx, groupID, integrationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyIntegrationInput(integration, input, false); err != nil {
+		return nil, err
+	}
+	if err := s.integrationRepo.Update(ctx, integration); err != nil {
+		return nil, apperrors.DatabaseError("updating group integration", err)
+	}
+
+	integration.Target = integrationTarget(integration)
+	return integration, nil
+}
+
+func (s *integrationService) DeleteIntegration(ctx context.Context, groupID, integrationID, userID string) error {
+	if _, err := s.getGroupIntegration(ctx, groupID, integrationID, userID); err != nil {
+		return err
+	}
+
+	if err := s.integrationRepo.Delete(ctx, integrationID); err != nil {
+		return apperrors.DatabaseError("deleting group integration", err)
+	}
+	return nil
+}
+
+func (s *integrationService) GetDeliveries(ctx context.Context, groupID, integrationID, userID string) ([]models.IntegrationDelivery, error) {
+	if _, err := s.getGroupIntegration(ctx, groupID, integrationID, userID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.integrationRepo.GetRecentDeliveries(ctx, integrationID, IntegrationDeliveriesLimit)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting integration deliveries", err)
+	}
+	return deliveries, nil
+}
+
+func (s *integrationService) SendTestMessage(ctx context.Context, groupID, integrationID, userID string) error {
+	integration, err := s.getGroupIntegration(ctx, groupID, integrationID, userID)
+	if err != nil {
+		return err
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return apperrors.DatabaseError("getting group", err)
+	}
+
+	delivery := &models.IntegrationDelivery{
+		ID:            uuid.New().String(),
+		IntegrationID: integration.ID,
+		Message:       fmt.Sprintf("Unwise is connected to %s. New expenses and settlements will be posted here.", group.Name),
+	}
+	if err := s.integrationRepo.EnqueueDelivery(ctx, delivery); err != nil {
+		return apperrors.DatabaseError("enqueueing test message", err)
+	}
+	return nil
+}
+
+func (s *integrationService) getGroupIntegration(ctx context.Context, groupID, integrationID, userID string) (*models.GroupIntegration, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	integration, err := s.integrationRepo.GetByID(ctx, integrationID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.NotFound("Integration")
+		}
+		return nil, apperrors.DatabaseError("getting group integration", err)
+	}
+	if integration.GroupID != groupID {
+		return nil, apperrors.NotFound("Integration")
+	}
+	return integration, nil
+}
+
+func applyIntegrationInput(integration *models.GroupIntegration, input IntegrationInput, creating bool) error {
+	switch integration.Platform {
+	case models.IntegrationPlatformTelegram:
+		if input.BotToken != nil {
+			token := strings.TrimSpace(*input.BotToken)
+			if !telegramBotTokenPattern.MatchString(token) {
+				return apperrors.InvalidRequest("Invalid Telegram bot token.")
+			}
+			integration.WebhookURL = "https://api.telegram.org/bot" + token + "/sendMessage"
+		} else if creating {
+			return apperrors.MissingRequiredField("bot_token")
+		}
+		if input.ChatID != nil {
+			chatID := strings.TrimSpace(*input.ChatID)
+			if !telegramChatIDPattern.MatchString(chatID) {
+				return apperrors.InvalidRequest("Telegram chat_id must be a numeric ID or an @channel username.")
+			}
+			integration.ChatID = &chatID
+		} else if creating {
+			return apperrors.MissingRequiredField("chat_id")
+		}
+	default:
+		if input.WebhookURL != nil {
+			webhookURL := strings.TrimSpace(*input.WebhookURL)
+			if err := validateWebhookURL(integration.Platform, webhookURL); err != nil {
+				return err
+			}
+			integration.WebhookURL = webhookURL
+		} else if creating {
+			return apperrors.MissingRequiredField("webhook_url")
+		}
+	}
+
+	if input.NotifyExpenses != nil {
+		integration.NotifyExpenses = *input.NotifyExpenses
+	}
+	if input.NotifySettlements != nil {
+		integration.NotifySettlements = *input.NotifySettlements
+	}
+	if input.Enabled != nil {
+		integration.Enabled = *input.Enabled
+	}
+
+	var err error
+	if input.ExpenseTemplate != nil {
+		if integration.ExpenseTemplate, err = normalizeIntegrationTemplate(*input.ExpenseTemplate); err != nil {
+			return err
+		}
+	}
+	if input.SettlementTemplate != nil {
+		if integration.SettlementTemplate, err = normalizeIntegrationTemplate(*input.SettlementTemplate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateWebhookURL(platform models.IntegrationPlatform, raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return apperrors.InvalidRequest("webhook_url must be a valid https URL.")
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	switch platform {
+	case models.IntegrationPlatformSlack:
+		if host != "hooks.slack.com" {
+			return apperrors.InvalidRequest("Slack webhook_url must be an https://hooks.slack.com URL.")
+		}
+	case models.IntegrationPlatformDiscord:
+		validHost := host == "discord.com" || host == "discordapp.com" || strings.HasSuffix(host, ".discord.com")
+		if !validHost || !strings.HasPrefix(parsed.Path, "/api/webhooks/") {
+			return apperrors.InvalidRequest("Discord webhook_url must be an https://discord.com/api/webhooks/ URL.")
+		}
+	}
+	return nil
+}
+
+func normalizeIntegrationTemplate(raw string) (*string, error) {
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		return nil, nil
+	}
+	if len(text) > MaxIntegrationTemplateLength {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("Templates cannot exceed %d characters.", MaxIntegrationTemplateLength))
+	}
+
+	sample := integrationMessage{
+		Group: "Trip", Actor: "Alice", Description: "Dinner", Amount: "₹1,200", Currency: "INR",
+		Payer: "Alice", Receiver: "Bob", Owes: []integrationShare{{Name: "Bob", Amount: "₹300"}},
+	}
+	if _, err := renderIntegrationMessage(text, sample); err != nil {
+		return nil, apperrors.InvalidRequestWithDetails("Invalid message template.", err.Error())
+	}
+	return &text, nil
+}
+
+func renderIntegrationMessage(text string, data integrationMessage) (string, error) {
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func integrationTarget(integration *models.GroupIntegration) string {
+	if integration.Platform == models.IntegrationPlatformTelegram {
+		if integration.ChatID != nil {
+			return "chat " + *integration.ChatID
+		}
+		return ""
+	}
+
+	parsed, err := url.Parse(integration.WebhookURL)
+	if err != nil {
+		return ""
+	}
+	suffix := integration.WebhookURL
+	if len(suffix) > 4 {
+		suffix = suffix[len(suffix)-4:]
+	}
+	return parsed.Host + "/…" + suffix
+}
+
+func (s *integrationService) Publish(ctx context.Context, payload NotificationPayload) error {
+	if payload.ExpenseID == "" || payload.GroupID == "" {
+		return nil
+	}
+	if payload.Event != models.NotificationEventNewExpense && payload.Event != models.NotificationEventSettlement {
+		return nil
+	}
+
+	integrations, err := s.integrationRepo.GetByGroupID(ctx, payload.GroupID)
+	if err != nil {
+		return apperrors.DatabaseError("getting group integrations", err)
+	}
+	var targets []models.GroupIntegration
+	for _, integration := range integrations {
+		if integration.Allows(payload.Event) {
+			targets = append(targets, integration)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	data, err := s.buildIntegrationMessage(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	for _, integration := range targets {
+		text := defaultExpenseTemplate
+		custom := integration.ExpenseTemplate
+		if payload.Event == models.NotificationEventSettlement {
+			text = defaultSettlementTemplate
+			custom = integration.SettlementTemplate
+		}
+		if custom != nil {
+			text = *custom
+		}
+
+		message, err := renderIntegrationMessage(text, data)
+		if err != nil {
+			zap.L().Warn("Failed to render integration template, falling back to the event message",
+				zap.String("integration_id", integration.ID), zap.Error(err))
+			message = payload.Message
+		}
+
+		delivery := &models.IntegrationDelivery{
+			ID:            uuid.New().String(),
+			IntegrationID: integration.ID,
+			ExpenseID:     &payload.ExpenseID,
+			Message:       message,
+			NextAttemptAt: payload.DeliverAt,
+		}
+		if err := s.integrationRepo.EnqueueDelivery(ctx, delivery); err != nil {
+			return apperrors.DatabaseError("enqueueing integration delivery", err)
+		}
+	}
+	return nil
+}
+
+func (s *integrationService) buildIntegrationMessage(ctx context.Context, payload NotificationPayload) (integrationMessage, error) {
+	expense, err := s.expenseRepo.GetByID(ctx, payload.ExpenseID)
+	if err != nil {
+		return integrationMessage{}, apperrors.DatabaseError("getting expense", err)
+	}
+	group, err := s.groupRepo.GetByID(ctx, payload.GroupID)
+	if err != nil {
+		return integrationMessage{}, apperrors.DatabaseError("getting group", err)
+	}
+
+	names := make(map[string]string, len(group.Members))
+	for _, m := range group.Members {
+		names[m.ID] = m.Name
+	}
+
+	symbol := expense.Currency + " "
+	if currency, err := s.currencyRepo.GetByCode(ctx, expense.Currency); err == nil && currency.Symbol != "" {
+		symbol = currency.Symbol
+	}
+
+	data := integrationMessage{
+		Group:       group.Name,
+		Actor:       names[payload.ActorID],
+		Description: expense.Description,
+		Amount:      formatMoney(symbol, expense.TotalAmount),
+		Currency:    expense.Currency,
+	}
+
+	paid := make(map[string]float64, len(expense.Payers))
+	for _, p := range expense.Payers {
+		paid[p.UserID] += p.AmountPaid
+	}
+	if len(expense.Payers) > 0 {
+		data.Payer = names[expense.Payers[0].UserID]
+	} else if expense.PaidByUserID != nil {
+		data.Payer = names[*expense.PaidByUserID]
+	}
+	if len(expense.Splits) > 0 {
+		data.Receiver = names[expense.Splits[0].UserID]
+	}
+
+	for _, split := range expense.Splits {
+		owes := math.Round((split.Amount-paid[split.UserID])*RoundingFactor) / RoundingFactor
+		if owes <= BalanceThreshold {
+			continue
+		}
+		data.Owes = append(data.Owes, integrationShare{Name: names[split.UserID], Amount: formatMoney(symbol, owes)})
+	}
+	return data, nil
+}
+
+func formatMoney(symbol string, amount float64) string {
+	number := strings.TrimSuffix(NumberFormat{Decimal: ".", Thousands: ","}.FormatAmount(math.Abs(amount)), ".00")
+	if amount < 0 && number != "0" {
+		return "-" + symbol + number
+	}
+	return symbol + number
+}
+
+func (s *integrationService) RunDeliveryWorker(ctx context.Context) {
+	zap.L().Info("Integration delivery worker started")
+	ticker := time.NewTicker(IntegrationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("Integration delivery worker stopped")
+			return
+		case <-ticker.C:
+			s.deliverDue(ctx)
+		}
+	}
+}
+
+func (s *integrationService) deliverDue(ctx context.Context) {
+	deliveries, err := s.integrationRepo.ClaimDueDeliveries(ctx, IntegrationDeliveryBatchSize, IntegrationDeliveryLease)
+	if err != nil {
+		zap.L().Error("Failed to claim integration deliveries", zap.Error(err))
+		return
+	}
+
+	for _, delivery := range deliveries {
+		sendErr := s.send(ctx, delivery.Integration, delivery.Message)
+		if sendErr == nil {
+			if err := s.integrationRepo.MarkDeliverySent(ctx, delivery.ID); err != nil {
+				zap.L().Error("Failed to mark integration delivery sent", zap.String("delivery_id", delivery.ID), zap.Error(err))
+			}
+			continue
+		}
+
+		var retryAt *time.Time
+		if delivery.Attempts < IntegrationMaxAttempts {
+			next := time.Now().Add(IntegrationRetryBaseDelay * time.Duration(1<<(delivery.Attempts-1)))
+			retryAt = &next
+		}
+		zap.L().Warn("Integration delivery failed",
+			zap.String("delivery_id", delivery.ID),
+			zap.String("integration_id", delivery.IntegrationID),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Bool("will_retry", retryAt != nil),
+			zap.Error(sendErr))
+		if err := s.integrationRepo.MarkDeliveryFailed(ctx, delivery.ID, sendErr.Error(), retryAt); err != nil {
+			zap.L().Error("Failed to mark integration delivery failed", zap.String("delivery_id", delivery.ID), zap.Error(err))
+		}
+	}
+}
+
+func (s *integrationService) send(ctx context.Context, integration *models.GroupIntegration, message string) error {
+	var body map[string]string
+	switch integration.Platform {
+	case models.IntegrationPlatformSlack:
+		body = map[string]string{"text": message}
+	case models.IntegrationPlatformDiscord:
+		body = map[string]string{"content": message}
+	case models.IntegrationPlatformTelegram:
+		chatID := ""
+		if integration.ChatID != nil {
+			chatID = *integration.ChatID
+		}
+		body = map[string]string{"chat_id": chatID, "text": message}
+	default:
+		return fmt.Errorf("unsupported platform %q", integration.Platform)
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, integration.WebhookURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("posting message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s webhook returned status %d: %s", strings.ToLower(string(integration.Platform)), resp.StatusCode, string(respBody))
+	}
+	return nil
+}
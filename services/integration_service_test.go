@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		symbol   string
+		amount   float64
+		expected string
+	}{
+		{"₹", 1200, "₹1,200"},
+		{"₹", 1234567.5, "₹1,234,567.50"},
+		{"$", 0.5, "$0.50"},
+		{"$", -45.25, "-$45.25"},
+		{"$", -0.001, "$0"},
+		{"USD ", 999.999, "USD 1,000"},
+	}
+
+	for _, tt := range tests {
+		if got := formatMoney(tt.symbol, tt.amount); got != tt.expected {
+			t.Errorf("formatMoney(%q, %v) = %q, expected %q", tt.symbol, tt.amount, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		platform    models.IntegrationPlatform
+		url         string
+		expectError bool
+	}{
+		{name: "Slack", platform: models.IntegrationPlatformSlack, url: "https://hooks.slack.com/services/T0/B0/x"},
+		{name: "Discord", platform: models.IntegrationPlatformDiscord, url: "https://discord.com/api/webhooks/1/abc"},
+		{name: "Discord Subdomain", platform: models.IntegrationPlatformDiscord, url: "https://ptb.discord.com/api/webhooks/1/abc"},
+		{name: "Plain HTTP", platform: models.IntegrationPlatformSlack, url: "http://hooks.slack.com/services/T0/B0/x", expectError: true},
+		{name: "No Host", platform: models.IntegrationPlatformSlack, url: "https:///services/x", expectError: true},
+		{name: "Private IP", platform: models.IntegrationPlatformSlack, url: "https://10.0.0.5/services/x", expectError: true},
+		{name: "Loopback", platform: models.IntegrationPlatformDiscord, url: "https://127.0.0.1/api/webhooks/1/abc", expectError: true},
+		{name: "Userinfo Pointing Elsewhere", platform: models.IntegrationPlatformDiscord, url: "https://discord.com@169.254.169.254/api/webhooks/1/abc", expectError: true},
+		{name: "Lookalike Host", platform: models.IntegrationPlatformSlack, url: "https://hooks.slack.com.example.net/services/x", expectError: true},
+		{name: "Discord Outside Webhooks", platform: models.IntegrationPlatformDiscord, url: "https://discord.com/api/users/1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookURL(tt.platform, tt.url)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateWebhookURL(%q) error = %v, expectError %v", tt.url, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestRenderIntegrationMessage(t *testing.T) {
+	data := integrationMessage{
+		Group: "Trip", Actor: "Alice", Description: "Dinner", Amount: "₹900", Currency: "INR",
+		Payer: "Alice", Receiver: "Bob",
+		Owes: []integrationShare{{Name: "Bob", Amount: "₹300"}, {Name: "Carol", Amount: "₹300"}},
+	}
+	tests := []struct {
+		name        string
+		template    string
+		expected    string
+		expectError bool
+	}{
+		{name: "Default Expense", template: defaultExpenseTemplate, expected: "Alice added 'Dinner' ₹900 — Bob owes ₹300, Carol owes ₹300"},
+		{name: "Default Settlement", template: defaultSettlementTemplate, expected: "Alice paid Bob ₹900"},
+		{name: "Custom", template: "[{{.Group}}] {{.Description}} ({{.Currency}})", expected: "[Trip] Dinner (INR)"},
+		{name: "Unknown Field", template: "{{.Total}}", expectError: true},
+		{name: "Unclosed Action", template: "{{.Actor", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderIntegrationMessage(tt.template, data)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("renderIntegrationMessage() error = %v, expectError %v", err, tt.expectError)
+			}
+			if got != tt.expected {
+				t.Errorf("renderIntegrationMessage() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeIntegrationTemplate(t *testing.T) {
+	trimmed := "{{.Payer}} paid"
+	tests := []struct {
+		name        string
+		raw         string
+		expected    *string
+		expectError bool
+	}{
+		{name: "Blank Means Default", raw: "   "},
+		{name: "Trimmed", raw: " {{.Payer}} paid \n", expected: &trimmed},
+		{name: "Unknown Field", raw: "{{.Nope}}", expectError: true},
+		{name: "Too Long", raw: strings.Repeat("x", MaxIntegrationTemplateLength+1), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeIntegrationTemplate(tt.raw)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("normalizeIntegrationTemplate() error = %v, expectError %v", err, tt.expectError)
+			}
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("normalizeIntegrationTemplate() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/internal", func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	s := NewIntegrationService(nil, nil, nil, nil).(*integrationService)
+	integration := &models.GroupIntegration{Platform: models.IntegrationPlatformSlack, WebhookURL: server.URL + "/hook"}
+	if err := s.send(context.Background(), integration, "hi"); err == nil {
+		t.Error("send() error = nil, expected the redirect to be reported as a failure")
+	}
+	if followed {
+		t.Error("send() followed the redirect")
+	}
+}
+
+type deliveryRecordingIntegrationRepo struct {
+	stubIntegrationRepository
+	due     []models.IntegrationDelivery
+	retryAt map[string]*time.Time
+	sent    []string
+}
+
+func (r *deliveryRecordingIntegrationRepo) ClaimDueDeliveries(context.Context, int, time.Duration) ([]models.IntegrationDelivery, error) {
+	return r.due, nil
+}
+
+func (r *deliveryRecordingIntegrationRepo) MarkDeliverySent(_ context.Context, id string) error {
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+func (r *deliveryRecordingIntegrationRepo) MarkDeliveryFailed(_ context.Context, id, _ string, retryAt *time.Time) error {
+	r.retryAt[id] = retryAt
+	return nil
+}
+
+func TestDeliverDueBacksOffAndGivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "ok") {
+			return
+		}
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	failing := &models.GroupIntegration{Platform: models.IntegrationPlatformDiscord, WebhookURL: server.URL + "/fail"}
+	working := &models.GroupIntegration{Platform: models.IntegrationPlatformDiscord, WebhookURL: server.URL + "/ok"}
+	repo := &deliveryRecordingIntegrationRepo{
+		due: []models.IntegrationDelivery{
+			{ID: "first", Attempts: 1, Integration: failing},
+			{ID: "third", Attempts: 3, Integration: failing},
+			{ID: "last", Attempts: IntegrationMaxAttempts, Integration: failing},
+			{ID: "ok", Attempts: 2, Integration: working},
+		},
+		retryAt: map[string]*time.Time{},
+	}
+	s := NewIntegrationService(repo, nil, nil, nil)
+
+	before := time.Now()
+	s.(*integrationService).deliverDue(context.Background())
+	after := time.Now()
+
+	expectedDelays := map[string]time.Duration{"first": IntegrationRetryBaseDelay, "third": 4 * IntegrationRetryBaseDelay}
+	for id, delay := range expectedDelays {
+		at := repo.retryAt[id]
+		if at == nil || at.Before(before.Add(delay)) || at.After(after.Add(delay)) {
+			t.Errorf("retry for %s = %v, expected %v from now", id, at, delay)
+		}
+	}
+	if at, ok := repo.retryAt["last"]; !ok || at != nil {
+		t.Errorf("retry for last = %v (recorded %v), expected the delivery to fail for good", at, ok)
+	}
+	if len(repo.sent) != 1 || repo.sent[0] != "ok" {
+		t.Errorf("sent = %v, expected [ok]", repo.sent)
+	}
+}
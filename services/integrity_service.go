@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+type IntegrityService interface {
+	GetOrphanReport(ctx context.Context) (*models.OrphanReport, error)
+	PurgeOrphanedPlaceholders(ctx context.Context, dryRun bool) (*models.PlaceholderPurgeResult, error)
+	RebuildGroupBalances(ctx context.Context, groupID string) (*models.GroupBalanceRebuild, error)
+}
+
+type integrityService struct {
+	integrityRepo    repository.IntegrityRepository
+	groupRepo        repository.GroupRepository
+	expenseRepo      repository.BalanceQueries
+	balanceEventRepo repository.BalanceEventRepository
+}
+
+func NewIntegrityService(integrityRepo repository.IntegrityRepository, groupRepo repository.GroupRepository, expenseRepo repository.BalanceQueries, balanceEventRepo repository.BalanceEventRepository) IntegrityService {
+	return &integrityService{
+		integrityRepo:    integrityRepo,
+		groupRepo:        groupRepo,
+		expenseRepo:      expenseRepo,
+		balanceEventRepo: balanceEventRepo,
+	}
+}
+
+func (s *integrityService) GetOrphanReport(ctx context.Context) (*models.OrphanReport, error) {
+	checks, err := s.integrityRepo.CountOrphans(ctx)
+	if err != nil {
+		return nil, apperrors.DatabaseError("counting orphaned rows", err)
+	}
+
+	report := &models.OrphanReport{
+		Checks:      checks,
+		GeneratedAt: time.Now(),
+	}
+	for _, c := range checks {
+		report.TotalOrphans += c.Count
+	}
+	return report, nil
+}
+
+func (s *integrityService) PurgeOrphanedPlaceholders(ctx context.Context, dryRun bool) (*models.PlaceholderPurgeResult, error) {
+	placeholders, err := s.integrityRepo.FindOrphanedPlaceholders(ctx)
+	if err != nil {
+		return nil, apperrors.DatabaseError("finding orphaned placeholders", err)
+	}
+
+	result := &models.PlaceholderPurgeResult{
+		Placeholders: placeholders,
+		DryRun:       dryRun,
+	}
+	if dryRun || len(placeholders) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, len(placeholders))
+	for i, p := range placeholders {
+		ids[i] = p.ID
+	}
+	deleted, err := s.integrityRepo.DeleteOrphanedPlaceholders(ctx, ids)
+	if err != nil {
+		return nil, apperrors.DatabaseError("deleting orphaned placeholders", err)
+	}
+	result.Deleted = deleted
+
+	zap.L().Info("Purged orphaned placeholders", zap.Int("found", len(placeholders)), zap.Int64("deleted", deleted))
+	return result, nil
+}
+
+func (s *integrityService) RebuildGroupBalances(ctx context.Context, groupID string) (*models.GroupBalanceRebuild, error) {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.GroupNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting group", err)
+	}
+
+	balancesByUser, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, nil)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group member balances", err)
+	}
+
+	names := make(map[string]string, len(group.Members))
+	for _, m := range group.Members {
+		names[m.ID] = m.Name
+	}
+
+	result := &models.GroupBalanceRebuild{
+		GroupID:            group.ID,
+		GroupName:          group.Name,
+		Balances:           []models.MemberCurrencyBalance{},
+		CurrencyDrift:      make(map[string]float64),
+		UnbalancedExpenses: []models.UnbalancedExpense{},
+		LedgerMismatches:   []models.LedgerMismatch{},
+		GeneratedAt:        time.Now(),
+	}
+
+	for userID, byCurrency := range balancesByUser {
+		for currency, balance := range byCurrency {
+			result.CurrencyDrift[currency] += balance
+			rounded := math.Round(balance*RoundingFactor) / RoundingFactor
+			if math.Abs(rounded) <= BalanceThreshold {
+				continue
+			}
+			result.Balances = append(result.Balances, models.MemberCurrencyBalance{
+				UserID:   userID,
+				Name:     names[userID],
+				Currency: currency,
+				Balance:  rounded,
+			})
+		}
+	}
+	for currency, drift := range result.CurrencyDrift {
+		result.CurrencyDrift[currency] = math.Round(drift*RoundingFactor) / RoundingFactor
+	}
+	sort.Slice(result.Balances, func(i, j int) bool {
+		if result.Balances[i].Currency != result.Balances[j].Currency {
+			return result.Balances[i].Currency < result.Balances[j].Currency
+		}
+		return result.Balances[i].Balance > result.Balances[j].Balance
+	})
+
+	totals, err := s.integrityRepo.GetExpenseTotals(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting expense totals", err)
+	}
+	for _, t := range totals {
+		total := math.Round(t.TotalAmount*RoundingFactor) / RoundingFactor
+		paid := math.Round(t.PaidTotal*RoundingFactor) / RoundingFactor
+		split := math.Round(t.SplitTotal*RoundingFactor) / RoundingFactor
+		if math.Abs(paid-total) > AmountTolerance || math.Abs(split-total) > AmountTolerance {
+			t.TotalAmount, t.PaidTotal, t.SplitTotal = total, paid, split
+			result.UnbalancedExpenses = append(result.UnbalancedExpenses, t)
+		}
+	}
+
+	ledger, err := s.balanceEventRepo.GetGroupTotals(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting balance event totals", err)
+	}
+	result.LedgerMismatches = findLedgerMismatches(ledger, balancesByUser, names)
+	if len(result.LedgerMismatches) > 0 {
+		zap.L().Warn("Balance ledger disagrees with computed balances",
+			zap.String("group_id", groupID),
+			zap.Int("mismatches", len(result.LedgerMismatches)))
+	}
+
+	return result, nil
+}
+
+func findLedgerMismatches(ledger, computed map[string]map[string]float64, names map[string]string) []models.LedgerMismatch {
+	type key struct{ userID, currency string }
+	seen := make(map[key]bool)
+	for _, balances := range []map[string]map[string]float64{ledger, computed} {
+		for userID, byCurrency := range balances {
+			for currency := range byCurrency {
+				seen[key{userID, currency}] = true
+			}
+		}
+	}
+
+	mismatches := []models.LedgerMismatch{}
+	for k := range seen {
+		m := models.LedgerMismatch{
+			UserID:          k.userID,
+			Name:            names[k.userID],
+			Currency:        k.currency,
+			LedgerBalance:   math.Round(ledger[k.userID][k.currency]*RoundingFactor) / RoundingFactor,
+			ComputedBalance: math.Round(computed[k.userID][k.currency]*RoundingFactor) / RoundingFactor,
+		}
+		m.Drift = math.Round((m.LedgerBalance-m.ComputedBalance)*RoundingFactor) / RoundingFactor
+		if math.Abs(m.Drift) > BalanceThreshold {
+			mismatches = append(mismatches, m)
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].Currency != mismatches[j].Currency {
+			return mismatches[i].Currency < mismatches[j].Currency
+		}
+		return mismatches[i].UserID < mismatches[j].UserID
+	})
+	return mismatches
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+type purgingIntegrityRepo struct {
+	stubIntegrityRepository
+	placeholders []models.User
+	deletedIDs   []string
+	totals       []models.UnbalancedExpense
+}
+
+func (r *purgingIntegrityRepo) FindOrphanedPlaceholders(context.Context) ([]models.User, error) {
+	return r.placeholders, nil
+}
+
+func (r *purgingIntegrityRepo) DeleteOrphanedPlaceholders(_ context.Context, ids []string) (int64, error) {
+	r.deletedIDs = append(r.deletedIDs, ids...)
+	return int64(len(ids)), nil
+}
+
+func (r *purgingIntegrityRepo) GetExpenseTotals(context.Context, string) ([]models.UnbalancedExpense, error) {
+	return r.totals, nil
+}
+
+type fixedGroupRepo struct {
+	mockGroupRepo
+	groups map[string]*models.Group
+}
+
+func (r *fixedGroupRepo) GetByID(_ context.Context, id string) (*models.Group, error) {
+	if group, ok := r.groups[id]; ok {
+		return group, nil
+	}
+	return nil, apperrors.NotFound("group")
+}
+
+type fixedLedgerRepo struct {
+	stubBalanceEventRepository
+	totals map[string]map[string]float64
+}
+
+func (r *fixedLedgerRepo) GetGroupTotals(context.Context, string) (map[string]map[string]float64, error) {
+	return r.totals, nil
+}
+
+func TestPurgeOrphanedPlaceholders(t *testing.T) {
+	placeholders := []models.User{{ID: "p1"}, {ID: "p2"}}
+
+	tests := []struct {
+		name            string
+		dryRun          bool
+		expectedDeleted int64
+	}{
+		{name: "Dry Run Deletes Nothing", dryRun: true, expectedDeleted: 0},
+		{name: "Purge Deletes Every Placeholder Found", dryRun: false, expectedDeleted: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &purgingIntegrityRepo{placeholders: placeholders}
+			s := NewIntegrityService(repo, &mockGroupRepo{}, &mockExpenseRepo{}, &fixedLedgerRepo{})
+
+			result, err := s.PurgeOrphanedPlaceholders(context.Background(), tt.dryRun)
+			if err != nil {
+				t.Fatalf("PurgeOrphanedPlaceholders() error = %v", err)
+			}
+			if result.Deleted != tt.expectedDeleted || int64(len(repo.deletedIDs)) != tt.expectedDeleted {
+				t.Errorf("PurgeOrphanedPlaceholders() deleted = %d (ids %v), expected %d", result.Deleted, repo.deletedIDs, tt.expectedDeleted)
+			}
+			if len(result.Placeholders) != len(placeholders) {
+				t.Errorf("PurgeOrphanedPlaceholders() listed %d placeholders, expected %d", len(result.Placeholders), len(placeholders))
+			}
+		})
+	}
+}
+
+func TestRebuildGroupBalances(t *testing.T) {
+	groupRepo := &fixedGroupRepo{groups: map[string]*models.Group{"g1": {
+		ID:      "g1",
+		Name:    "Flat",
+		Members: []models.User{{ID: "alice", Name: "Alice"}, {ID: "bob", Name: "Bob"}},
+	}}}
+	expenseRepo := &mockExpenseRepo{balances: map[string]map[string]float64{
+		"alice": {"INR": 150.004, "USD": 0.004},
+		"bob":   {"INR": -150, "USD": -0.004},
+	}}
+	integrityRepo := &purgingIntegrityRepo{totals: []models.UnbalancedExpense{
+		{ExpenseID: "balanced", TotalAmount: 100, PaidTotal: 100, SplitTotal: 100.0001},
+		{ExpenseID: "short", TotalAmount: 100, PaidTotal: 100, SplitTotal: 99.5},
+	}}
+	ledgerRepo := &fixedLedgerRepo{totals: map[string]map[string]float64{
+		"alice": {"INR": 150},
+		"bob":   {"INR": -120},
+	}}
+	s := NewIntegrityService(integrityRepo, groupRepo, expenseRepo, ledgerRepo)
+
+	result, err := s.RebuildGroupBalances(context.Background(), "g1")
+	if err != nil {
+		t.Fatalf("RebuildGroupBalances() error = %v", err)
+	}
+
+	if len(result.Balances) != 2 {
+		t.Fatalf("RebuildGroupBalances() balances = %+v, expected the two INR balances only", result.Balances)
+	}
+	if result.Balances[0].UserID != "alice" || result.Balances[0].Name != "Alice" || result.Balances[0].Balance != 150 {
+		t.Errorf("RebuildGroupBalances() first balance = %+v, expected Alice owed 150", result.Balances[0])
+	}
+	if drift := result.CurrencyDrift["INR"]; drift != 0 {
+		t.Errorf("RebuildGroupBalances() INR drift = %v, expected 0", drift)
+	}
+	if len(result.UnbalancedExpenses) != 1 || result.UnbalancedExpenses[0].ExpenseID != "short" {
+		t.Errorf("RebuildGroupBalances() unbalanced = %+v, expected only the short expense", result.UnbalancedExpenses)
+	}
+	if len(result.LedgerMismatches) != 1 || result.LedgerMismatches[0].UserID != "bob" || result.LedgerMismatches[0].Drift != 30 {
+		t.Errorf("RebuildGroupBalances() ledger mismatches = %+v, expected Bob drifting by 30", result.LedgerMismatches)
+	}
+
+	if _, err := s.RebuildGroupBalances(context.Background(), "missing"); err == nil {
+		t.Error("RebuildGroupBalances() on a missing group succeeded, expected an error")
+	} else if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.GroupNotFound().Code {
+		t.Errorf("RebuildGroupBalances() error = %v, expected group not found", err)
+	}
+}
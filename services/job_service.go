@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// JobHandler may run more than once for a job, so it must be idempotent.
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+type JobService interface {
+	Register(jobType string, handler JobHandler)
+	Enqueue(ctx context.Context, jobType string, payload interface{}, runAt time.Time) (*models.Job, error)
+	EnqueueTx(ctx context.Context, q database.Querier, jobType string, payload interface{}, runAt time.Time) (*models.Job, error)
+	GetDeadJobs(ctx context.Context) ([]models.Job, error)
+	RetryDeadJob(ctx context.Context, jobID string) (*models.Job, error)
+	RunWorker(ctx context.Context)
+}
+
+type jobService struct {
+	jobRepo repository.JobRepository
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+func NewJobService(jobRepo repository.JobRepository) JobService {
+	return &jobService{
+		jobRepo:  jobRepo,
+		handlers: make(map[string]JobHandler),
+	}
+}
+
+func (s *jobService) Register(jobType string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = handler
+}
+
+func (s *jobService) handler(jobType string) (JobHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handler, ok := s.handlers[jobType]
+	return handler, ok
+}
+
+func (s *jobService) Enqueue(ctx context.Context, jobType string, payload interface{}, runAt time.Time) (*models.Job, error) {
+	return s.enqueue(ctx, s.jobRepo, jobType, payload, runAt)
+}
+
+// EnqueueTx only creates the job if the caller's transaction commits.
+func (s *jobService) EnqueueTx(ctx context.Context, q database.Querier, jobType string, payload interface{}, runAt time.Time) (*models.Job, error) {
+	return s.enqueue(ctx, s.jobRepo.WithTx(q), jobType, payload, runAt)
+}
+
+func (s *jobService) enqueue(ctx context.Context, repo repository.JobRepository, jobType string, payload interface{}, runAt time.Time) (*models.Job, error) {
+	if _, ok := s.handler(jobType); !ok {
+		return nil, apperrors.InternalError(fmt.Errorf("no handler registered for job type %q", jobType))
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, apperrors.InternalError(fmt.Errorf("encoding %s job payload: %w", jobType, err))
+	}
+
+	job := &models.Job{
+		ID:          uuid.New().String(),
+		Type:        jobType,
+		Payload:     data,
+		MaxAttempts: JobMaxAttempts,
+		RunAt:       runAt,
+	}
+	if err := repo.Enqueue(ctx, job); err != nil {
+		return nil, apperrors.DatabaseError("enqueueing job", err)
+	}
+
+	zap.L().Debug("Job enqueued",
+		zap.String("job_id", job.ID),
+		zap.String("type", jobType),
+		zap.Time("run_at", job.RunAt))
+	return job, nil
+}
+
+func (s *jobService) GetDeadJobs(ctx context.Context) ([]models.Job, error) {
+	jobs, err := s.jobRepo.GetDead(ctx, DeadJobsLimit)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting dead jobs", err)
+	}
+	return jobs, nil
+}
+
+func (s *jobService) RetryDeadJob(ctx context.Context, jobID string) (*models.Job, error) {
+	job, err := s.jobRepo.Requeue(ctx, jobID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.NotFound("Dead job")
+		}
+		return nil, apperrors.DatabaseError("requeueing job", err)
+	}
+	zap.L().Info("Dead job requeued", zap.String("job_id", jobID), zap.String("type", job.Type))
+	return job, nil
+}
+
+func (s *jobService) RunWorker(ctx context.Context) {
+	zap.L().Info("Job workers started", zap.Int("workers", JobWorkers))
+	var wg sync.WaitGroup
+	for i := 0; i < JobWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	wg.Wait()
+	zap.L().Info("Job workers stopped")
+}
+
+func (s *jobService) work(ctx context.Context) {
+	ticker := time.NewTicker(JobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runDue(ctx)
+		}
+	}
+}
+
+func (s *jobService) runDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		jobs, err := s.jobRepo.ClaimDue(ctx, 1, JobLease)
+		if err != nil {
+			zap.L().Error("Failed to claim jobs", zap.Error(err))
+			return
+		}
+		if len(jobs) == 0 {
+			return
+		}
+		s.run(ctx, jobs[0])
+	}
+}
+
+func (s *jobService) run(ctx context.Context, job models.Job) {
+	start := time.Now()
+	var runErr error
+	if handler, ok := s.handler(job.Type); ok {
+		runCtx, cancel := context.WithTimeout(ctx, JobTimeout)
+		runErr = callJobHandler(runCtx, handler, job.Payload)
+		cancel()
+	} else {
+		runErr = fmt.Errorf("no handler registered for job type %q", job.Type)
+		job.Attempts = job.MaxAttempts
+	}
+
+	if runErr == nil {
+		if err := s.jobRepo.MarkSucceeded(ctx, job.ID); err != nil {
+			zap.L().Error("Failed to mark job succeeded", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		zap.L().Info("Job succeeded",
+			zap.String("job_id", job.ID),
+			zap.String("type", job.Type),
+			zap.Int("attempts", job.Attempts),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	var retryAt *time.Time
+	if job.Attempts < job.MaxAttempts {
+		next := time.Now().Add(jobRetryDelay(job.Attempts))
+		retryAt = &next
+	}
+	zap.L().Warn("Job failed",
+		zap.String("job_id", job.ID),
+		zap.String("type", job.Type),
+		zap.Int("attempts", job.Attempts),
+		zap.Bool("will_retry", retryAt != nil),
+		zap.Error(runErr))
+	if err := s.jobRepo.MarkFailed(ctx, job.ID, truncateJobError(runErr.Error()), retryAt); err != nil {
+		zap.L().Error("Failed to mark job failed", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
+
+func callJobHandler(ctx context.Context, handler JobHandler, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+func jobRetryDelay(attempts int) time.Duration {
+	delay := JobRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= JobRetryMaxDelay {
+			return JobRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+func truncateJobError(message string) string {
+	if len(message) <= MaxJobErrorLength {
+		return message
+	}
+	return message[:MaxJobErrorLength]
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{8, JobRetryMaxDelay},
+		{40, JobRetryMaxDelay},
+	}
+
+	for _, tt := range tests {
+		if got := jobRetryDelay(tt.attempts); got != tt.expected {
+			t.Errorf("jobRetryDelay(%d) = %v, expected %v", tt.attempts, got, tt.expected)
+		}
+	}
+}
+
+func TestCallJobHandler(t *testing.T) {
+	failure := errors.New("upstream unavailable")
+	tests := []struct {
+		name        string
+		handler     JobHandler
+		expectError bool
+	}{
+		{name: "Succeeds", handler: func(ctx context.Context, payload json.RawMessage) error { return nil }},
+		{name: "Fails", handler: func(ctx context.Context, payload json.RawMessage) error { return failure }, expectError: true},
+		{name: "Panics", handler: func(ctx context.Context, payload json.RawMessage) error { panic("nil map") }, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := callJobHandler(context.Background(), tt.handler, json.RawMessage(`{}`))
+			if (err != nil) != tt.expectError {
+				t.Errorf("callJobHandler() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
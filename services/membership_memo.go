@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+type membershipMemoKey struct{}
+
+type membershipKey struct {
+	groupID string
+	userID  string
+}
+
+type membershipMemo struct {
+	mu      sync.Mutex
+	entries map[membershipKey]bool
+}
+
+func WithMembershipMemo(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(membershipMemoKey{}).(*membershipMemo); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, membershipMemoKey{}, &membershipMemo{entries: make(map[membershipKey]bool)})
+}
+
+func membershipMemoFrom(ctx context.Context) *membershipMemo {
+	memo, _ := ctx.Value(membershipMemoKey{}).(*membershipMemo)
+	return memo
+}
+
+func (m *membershipMemo) get(groupID, userID string) (bool, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	isMember, ok := m.entries[membershipKey{groupID: groupID, userID: userID}]
+	return isMember, ok
+}
+
+func (m *membershipMemo) set(groupID, userID string, isMember bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[membershipKey{groupID: groupID, userID: userID}] = isMember
+}
+
+func forgetGroupMemberships(ctx context.Context, groupID string) {
+	memo := membershipMemoFrom(ctx)
+	if memo == nil {
+		return
+	}
+	memo.mu.Lock()
+	defer memo.mu.Unlock()
+	for key := range memo.entries {
+		if key.groupID == groupID {
+			delete(memo.entries, key)
+		}
+	}
+}
+
+func forgetAllMemberships(ctx context.Context) {
+	memo := membershipMemoFrom(ctx)
+	if memo == nil {
+		return
+	}
+	memo.mu.Lock()
+	defer memo.mu.Unlock()
+	memo.entries = make(map[membershipKey]bool)
+}
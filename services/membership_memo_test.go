@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+type countingMemberRepo struct {
+	mockGroupRepo
+	members map[string]bool
+	calls   int
+}
+
+func (r *countingMemberRepo) IsMember(_ context.Context, groupID, userID string) (bool, error) {
+	r.calls++
+	return r.members[groupID+"/"+userID], nil
+}
+
+func (r *countingMemberRepo) GetByID(context.Context, string) (*models.Group, error) {
+	return nil, errors.New("stop after the membership checks")
+}
+
+func TestMembershipMemoHits(t *testing.T) {
+	repo := &countingMemberRepo{members: map[string]bool{"g1/alice": true}}
+	ctx := WithMembershipMemo(context.Background())
+
+	for i := 0; i < 3; i++ {
+		if err := RequireGroupMembership(ctx, repo, "g1", "alice"); err != nil {
+			t.Fatalf("RequireGroupMembership() error = %v", err)
+		}
+		err := RequireGroupMembership(ctx, repo, "g1", "mallory")
+		if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeNotGroupMember {
+			t.Fatalf("RequireGroupMembership() error = %v, expected %s", err, apperrors.CodeNotGroupMember)
+		}
+	}
+	if repo.calls != 2 {
+		t.Errorf("IsMember calls = %d, expected 2 (one per user, non-members memoized too)", repo.calls)
+	}
+
+	if err := RequireGroupMembership(context.Background(), repo, "g1", "alice"); err != nil {
+		t.Fatalf("RequireGroupMembership() error = %v", err)
+	}
+	if repo.calls != 3 {
+		t.Errorf("IsMember calls = %d, expected a context without a memo to always query", repo.calls)
+	}
+}
+
+func TestForgetGroupMembershipsInvalidatesOnlyThatGroup(t *testing.T) {
+	repo := &countingMemberRepo{members: map[string]bool{"g1/alice": true, "g2/alice": true}}
+	ctx := WithMembershipMemo(context.Background())
+
+	for _, groupID := range []string{"g1", "g2"} {
+		if err := RequireGroupMembership(ctx, repo, groupID, "alice"); err != nil {
+			t.Fatalf("RequireGroupMembership(%s) error = %v", groupID, err)
+		}
+	}
+
+	repo.members["g1/alice"] = false
+	forgetGroupMemberships(ctx, "g1")
+
+	if err := RequireGroupMembership(ctx, repo, "g1", "alice"); err == nil {
+		t.Error("RequireGroupMembership(g1) = nil after removal, expected NotGroupMember")
+	}
+	if err := RequireGroupMembership(ctx, repo, "g2", "alice"); err != nil {
+		t.Errorf("RequireGroupMembership(g2) error = %v", err)
+	}
+	if repo.calls != 3 {
+		t.Errorf("IsMember calls = %d, expected only g1 to be queried again", repo.calls)
+	}
+
+	forgetAllMemberships(ctx)
+	if err := RequireGroupMembership(ctx, repo, "g2", "alice"); err != nil {
+		t.Errorf("RequireGroupMembership(g2) error = %v", err)
+	}
+	if repo.calls != 4 {
+		t.Errorf("IsMember calls = %d, expected forgetAllMemberships to clear g2", repo.calls)
+	}
+}
+
+func TestCreateRepaymentChecksReceiverThroughMemo(t *testing.T) {
+	repo := &countingMemberRepo{members: map[string]bool{"g1/alice": true, "g1/bob": true}}
+	s := &groupService{groupRepo: repo}
+	ctx := WithMembershipMemo(context.Background())
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.CreateRepayment(ctx, "g1", "alice", "bob", 10, ""); err == nil {
+			t.Fatal("CreateRepayment() error = nil, expected the stubbed GetByID failure")
+		}
+	}
+	if repo.calls != 2 {
+		t.Errorf("IsMember calls = %d, expected payer and receiver to be checked once each", repo.calls)
+	}
+}
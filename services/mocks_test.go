@@ -1,118 +1,150 @@
 package services
 
+//go:generate go run ../scripts/stubgen -src ../repository -import unwise-backend/repository -out repository_stubs_test.go
+//go:generate go run ../scripts/stubgen -src . -interfaces NotificationService,SettlementService -out service_stubs_test.go
+
 import (
 	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
 	"unwise-backend/database"
 	"unwise-backend/models"
 	"unwise-backend/repository"
 )
 
+// errNotStubbed is what the generated stubs return for a method no test
+// double overrides.
+func errNotStubbed(method string) error {
+	return fmt.Errorf("%s is not stubbed in this test", method)
+}
+
+// mockExpenseRepo embeds the generated stub, so it only implements the
+// methods tests rely on. Anything else returns errNotStubbed naming the
+// method.
 type mockExpenseRepo struct {
-	balances map[string]map[string]float64
+	stubExpenseRepository
+	balances     map[string]map[string]float64
+	pairLedgers  []models.PairLedger
+	pairBalances []models.PairBalance
 }
 
-func (m *mockExpenseRepo) GetByID(ctx context.Context, id string) (*models.Expense, error) {
-	return nil, nil
+func (m *mockExpenseRepo) GetGroupMemberBalances(ctx context.Context, groupID string, asOf *time.Time) (map[string]map[string]float64, error) {
+	return m.balances, nil
 }
-func (m *mockExpenseRepo) GetByGroupID(ctx context.Context, groupID string) ([]models.Expense, error) {
-	return nil, nil
+func (m *mockExpenseRepo) CountGroupExpensesSince(ctx context.Context, groupID string, since time.Time) (int, error) {
+	return 0, nil
 }
-func (m *mockExpenseRepo) GetTransactionsByGroupID(ctx context.Context, groupID string) ([]models.Transaction, error) {
-	return nil, nil
+
+func (m *mockExpenseRepo) GetGroupPairBalances(ctx context.Context, groupID string, asOf *time.Time) ([]models.PairBalance, error) {
+	return m.pairBalances, nil
 }
-func (m *mockExpenseRepo) GetRecentTransactionsForUser(ctx context.Context, userID string, limit int) ([]models.Expense, error) {
-	return nil, nil
+
+func (m *mockExpenseRepo) GetPairLedgers(ctx context.Context, groupID, currency string, userIDs []string) ([]models.PairLedger, error) {
+	return m.pairLedgers, nil
 }
-func (m *mockExpenseRepo) GetUserBalanceInGroup(ctx context.Context, groupID, userID string) (float64, error) {
-	return 0, nil
+
+func (m *mockExpenseRepo) WithTx(tx database.Querier) repository.ExpenseRepository { return m }
+
+type mockExpenseChangeRepo struct {
+	stubExpenseChangeRepository
 }
-func (m *mockExpenseRepo) GetUserTotalBalance(ctx context.Context, userID string) ([]models.CurrencyAmount, []models.CurrencyAmount, []models.CurrencyAmount, error) {
-	return nil, nil, nil, nil
+
+type mockGroupRepo struct {
+	stubGroupRepository
+	limits     *models.GroupLimits
+	editPolicy models.ExpenseEditPolicy
+	rounding   int
+	algorithm  models.SettlementAlgorithm
+	members    []models.User
 }
-func (m *mockExpenseRepo) Create(ctx context.Context, expense *models.Expense) error { return nil }
-func (m *mockExpenseRepo) Update(ctx context.Context, expense *models.Expense) error { return nil }
-func (m *mockExpenseRepo) UpdateExplanation(ctx context.Context, id string, explanation string) error {
-	return nil
+
+func (m *mockGroupRepo) IsMember(ctx context.Context, groupID, userID string) (bool, error) {
+	return true, nil
 }
-func (m *mockExpenseRepo) Delete(ctx context.Context, id string) error { return nil }
-func (m *mockExpenseRepo) GetSplits(ctx context.Context, expenseID string) ([]models.ExpenseSplit, error) {
+
+func (m *mockGroupRepo) GetByID(ctx context.Context, id string) (*models.Group, error) {
 	return nil, nil
 }
-func (m *mockExpenseRepo) CreateSplit(ctx context.Context, split *models.ExpenseSplit) error {
-	return nil
+func (m *mockGroupRepo) GetByUserID(ctx context.Context, userID string) ([]models.Group, error) {
+	return nil, nil
 }
-func (m *mockExpenseRepo) DeleteSplits(ctx context.Context, expenseID string) error { return nil }
-func (m *mockExpenseRepo) GetPayers(ctx context.Context, expenseID string) ([]models.ExpensePayer, error) {
+func (m *mockGroupRepo) GetGroupsWithLastActivity(ctx context.Context, userID string) ([]models.DashboardGroup, error) {
 	return nil, nil
 }
-func (m *mockExpenseRepo) CreatePayer(ctx context.Context, payer *models.ExpensePayer) error {
+func (m *mockGroupRepo) Create(ctx context.Context, group *models.Group) error { return nil }
+func (m *mockGroupRepo) Update(ctx context.Context, group *models.Group) error { return nil }
+func (m *mockGroupRepo) UpdateAvatarURL(ctx context.Context, groupID, avatarURL string) error {
 	return nil
 }
-func (m *mockExpenseRepo) DeletePayers(ctx context.Context, expenseID string) error { return nil }
-func (m *mockExpenseRepo) GetReceiptItems(ctx context.Context, expenseID string) ([]models.ReceiptItem, error) {
-	return nil, nil
-}
-func (m *mockExpenseRepo) CreateReceiptItem(ctx context.Context, item *models.ReceiptItem) error {
+func (m *mockGroupRepo) UpdateDefaultCurrency(ctx context.Context, groupID, currency string) error {
 	return nil
 }
-func (m *mockExpenseRepo) GetReceiptItemAssignments(ctx context.Context, receiptItemID string) ([]models.ReceiptItemAssignment, error) {
-	return nil, nil
+func (m *mockGroupRepo) GetLimits(ctx context.Context, groupID string) (*models.GroupLimits, error) {
+	if m.limits != nil {
+		return m.limits, nil
+	}
+	return &models.GroupLimits{Action: models.GroupLimitActionReject}, nil
 }
-func (m *mockExpenseRepo) CreateReceiptItemAssignment(ctx context.Context, assignment *models.ReceiptItemAssignment) error {
+func (m *mockGroupRepo) UpdateLimits(ctx context.Context, groupID string, limits *models.GroupLimits) error {
 	return nil
 }
-func (m *mockExpenseRepo) DeleteReceiptItems(ctx context.Context, expenseID string) error { return nil }
-func (m *mockExpenseRepo) GetSplitsByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]models.ExpenseSplit, error) {
-	return nil, nil
+func (m *mockGroupRepo) GetExpenseEditPolicy(ctx context.Context, groupID string) (models.ExpenseEditPolicy, error) {
+	if m.editPolicy != "" {
+		return m.editPolicy, nil
+	}
+	return models.ExpenseEditPolicyAnyMember, nil
 }
-func (m *mockExpenseRepo) GetPayersByExpenseIDs(ctx context.Context, expenseIDs []string) (map[string][]models.ExpensePayer, error) {
-	return nil, nil
+func (m *mockGroupRepo) UpdateExpenseEditPolicy(ctx context.Context, groupID string, policy models.ExpenseEditPolicy) error {
+	return nil
 }
-func (m *mockExpenseRepo) GetGroupBalancesByUserID(ctx context.Context, userID string, groupIDs []string) (map[string]float64, error) {
-	return nil, nil
+func (m *mockGroupRepo) GetSettlementRounding(ctx context.Context, groupID string) (int, error) {
+	return m.rounding, nil
 }
-func (m *mockExpenseRepo) GetGroupMemberBalances(ctx context.Context, groupID string) (map[string]map[string]float64, error) {
-	return m.balances, nil
+func (m *mockGroupRepo) UpdateSettlementRounding(ctx context.Context, groupID string, increment int) error {
+	return nil
 }
-func (m *mockExpenseRepo) GetGroupTotalSpend(ctx context.Context, groupID string) (float64, error) {
-	return 0, nil
+func (m *mockGroupRepo) GetSettlementAlgorithm(ctx context.Context, groupID string) (models.SettlementAlgorithm, error) {
+	if m.algorithm != "" {
+		return m.algorithm, nil
+	}
+	return models.SettlementAlgorithmMinTransfers, nil
 }
-func (m *mockExpenseRepo) GetPairwiseBalances(ctx context.Context, userID, friendID string, groupIDs []string) (map[string]float64, error) {
-	return nil, nil
+func (m *mockGroupRepo) UpdateSettlementAlgorithm(ctx context.Context, groupID string, algorithm models.SettlementAlgorithm) error {
+	return nil
 }
-func (m *mockExpenseRepo) GetPairwiseBalancesAllFriends(ctx context.Context, userID string) (map[string]map[string]float64, error) {
-	return nil, nil
+func (m *mockGroupRepo) GetDefaultLanguage(ctx context.Context, groupID string) (string, error) {
+	return "en", nil
 }
-func (m *mockExpenseRepo) TransferExpenses(ctx context.Context, fromUserID, toUserID string) error {
+func (m *mockGroupRepo) UpdateDefaultLanguage(ctx context.Context, groupID string, language string) error {
 	return nil
 }
-
-func (m *mockExpenseRepo) WithTx(tx database.Querier) repository.ExpenseRepository { return m }
-
-type mockGroupRepo struct{}
-
-func (m *mockGroupRepo) IsMember(ctx context.Context, groupID, userID string) (bool, error) {
-	return true, nil
+func (m *mockGroupRepo) GetTaxPreset(ctx context.Context, groupID string) (models.TaxPreset, error) {
+	return models.TaxPresetIndiaGST, nil
 }
-
-func (m *mockGroupRepo) GetByID(ctx context.Context, id string) (*models.Group, error) {
-	return nil, nil
+func (m *mockGroupRepo) GetUsage(ctx context.Context, groupID string, from, to time.Time) (*models.GroupUsage, error) {
+	return &models.GroupUsage{GroupID: groupID, PeriodStart: from, PeriodEnd: to}, nil
 }
-func (m *mockGroupRepo) GetByUserID(ctx context.Context, userID string) ([]models.Group, error) {
-	return nil, nil
+func (m *mockGroupRepo) UpdateTaxPreset(ctx context.Context, groupID string, preset models.TaxPreset) error {
+	return nil
 }
-func (m *mockGroupRepo) GetGroupsWithLastActivity(ctx context.Context, userID string) ([]models.DashboardGroup, error) {
+func (m *mockGroupRepo) AddRecurringStub(ctx context.Context, stub *models.RecurringExpenseStub) error {
+	return nil
+}
+func (m *mockGroupRepo) GetRecurringStubs(ctx context.Context, groupID string) ([]models.RecurringExpenseStub, error) {
 	return nil, nil
 }
-func (m *mockGroupRepo) Create(ctx context.Context, group *models.Group) error { return nil }
-func (m *mockGroupRepo) Update(ctx context.Context, group *models.Group) error { return nil }
-func (m *mockGroupRepo) UpdateAvatarURL(ctx context.Context, groupID, avatarURL string) error {
+func (m *mockGroupRepo) Delete(ctx context.Context, id string) error { return nil }
+func (m *mockGroupRepo) IsSandbox(ctx context.Context, groupID string) (bool, error) {
+	return false, nil
+}
+func (m *mockGroupRepo) LockForShare(ctx context.Context, groupID string) error {
 	return nil
 }
-func (m *mockGroupRepo) UpdateDefaultCurrency(ctx context.Context, groupID, currency string) error {
+func (m *mockGroupRepo) LockForUpdate(ctx context.Context, groupID string) error {
 	return nil
 }
-func (m *mockGroupRepo) Delete(ctx context.Context, id string) error { return nil }
 func (m *mockGroupRepo) AddMember(ctx context.Context, groupID, userID string) error {
 	return nil
 }
@@ -120,7 +152,7 @@ func (m *mockGroupRepo) RemoveMember(ctx context.Context, groupID, userID string
 	return nil
 }
 func (m *mockGroupRepo) GetMembers(ctx context.Context, groupID string) ([]models.User, error) {
-	return nil, nil
+	return m.members, nil
 }
 func (m *mockGroupRepo) GetCommonGroups(ctx context.Context, userID1, userID2 string) ([]models.Group, error) {
 	return nil, nil
@@ -129,3 +161,69 @@ func (m *mockGroupRepo) GetGroupsDetailedByUserID(ctx context.Context, userID st
 	return nil, nil
 }
 func (m *mockGroupRepo) WithTx(tx database.Querier) repository.GroupRepository { return m }
+
+type mockSplitPreferenceRepo struct {
+	preference *models.SplitPreference
+}
+
+func (m *mockSplitPreferenceRepo) GetForPair(ctx context.Context, userAID, userBID string) ([]models.SplitPreference, error) {
+	return nil, nil
+}
+func (m *mockSplitPreferenceRepo) Find(ctx context.Context, userAID, userBID, groupID string) (*models.SplitPreference, error) {
+	if m.preference == nil || m.preference.UserAID != userAID || m.preference.UserBID != userBID {
+		return nil, fmt.Errorf("finding split preference: no rows in result set")
+	}
+	return m.preference, nil
+}
+func (m *mockSplitPreferenceRepo) Upsert(ctx context.Context, preference *models.SplitPreference) error {
+	return nil
+}
+func (m *mockSplitPreferenceRepo) Delete(ctx context.Context, userAID, userBID string, groupID *string) (bool, error) {
+	return false, nil
+}
+func (m *mockSplitPreferenceRepo) WithTx(tx database.Querier) repository.SplitPreferenceRepository {
+	return m
+}
+
+// fakeTx is the Querier a fakeTxRunner hands to its callback. Test doubles
+// record their writes through inTx, so a rolled back transaction leaves
+// them untouched.
+type fakeTx struct {
+	database.Querier
+	pending []func()
+}
+
+// inTx applies write when q's transaction commits, or right away when q is
+// not a fakeTx.
+func inTx(q database.Querier, write func()) {
+	if tx, ok := q.(*fakeTx); ok {
+		tx.pending = append(tx.pending, write)
+		return
+	}
+	write()
+}
+
+type fakeTxRunner struct {
+	commits   int
+	rollbacks int
+}
+
+func (r *fakeTxRunner) WithTx(ctx context.Context, fn func(database.Querier) error) error {
+	tx := &fakeTx{}
+	if err := fn(tx); err != nil {
+		r.rollbacks++
+		return err
+	}
+	for _, write := range tx.pending {
+		write()
+	}
+	r.commits++
+	return nil
+}
+
+func TestUnstubbedMethodReturnsError(t *testing.T) {
+	repo := &mockExpenseRepo{}
+	if _, err := repo.GetByID(context.Background(), "e1"); err == nil || !strings.Contains(err.Error(), "ExpenseRepository.GetByID") {
+		t.Errorf("GetByID() error = %v, expected one naming the unstubbed method", err)
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/repository"
+)
+
+// CurrencyFormatService builds formatters for display amounts, so clients
+// don't have to know each currency's symbol and each locale's separators.
+type CurrencyFormatService interface {
+	Formatter(ctx context.Context, locale string) (*MoneyFormatter, error)
+}
+
+type currencyFormatService struct {
+	currencyRepo repository.CurrencyRepository
+}
+
+func NewCurrencyFormatService(currencyRepo repository.CurrencyRepository) CurrencyFormatService {
+	return &currencyFormatService{currencyRepo: currencyRepo}
+}
+
+// Formatter formats amounts for a BCP 47 locale ("en-IN", "de_DE") with the
+// symbols from the currency registry. Unknown locales format like English.
+func (s *currencyFormatService) Formatter(ctx context.Context, locale string) (*MoneyFormatter, error) {
+	currencies, err := s.currencyRepo.GetAll(ctx)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting currencies", err)
+	}
+	symbols := make(map[string]string, len(currencies))
+	for _, c := range currencies {
+		if c.Symbol != "" {
+			symbols[c.Code] = c.Symbol
+		}
+	}
+	return NewMoneyFormatter(symbols, locale), nil
+}
+
+// numberFormat is how a locale writes numbers and where it puts the currency.
+type numberFormat struct {
+	decimal     string
+	thousands   string
+	indian      bool
+	symbolAfter bool
+}
+
+// Languages that write 1.234,50 and 1 234,50, with the symbol after the
+// amount. Any other language writes 1,234.50 with the symbol in front.
+var (
+	decimalCommaLanguages = map[string]bool{"de": true, "es": true, "it": true, "nl": true, "pt": true, "id": true, "tr": true, "da": true}
+	spaceGroupLanguages   = map[string]bool{"fr": true, "pl": true, "cs": true, "sv": true, "nb": true, "fi": true, "ru": true, "uk": true}
+)
+
+// zeroDecimalCurrencies have no minor unit (ISO 4217), e.g. ¥1,200.
+var zeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true, "JPY": true, "KMF": true, "KRW": true,
+	"PYG": true, "RWF": true, "UGX": true, "VND": true, "VUV": true, "XAF": true, "XOF": true, "XPF": true,
+}
+
+func numberFormatForLocale(locale string) numberFormat {
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	language, region := "", ""
+	for i, part := range parts {
+		if i == 0 {
+			language = strings.ToLower(part)
+		} else if len(part) == 2 {
+			region = strings.ToUpper(part)
+		}
+	}
+
+	switch {
+	case region == "IN":
+		return numberFormat{decimal: ".", thousands: ",", indian: true}
+	case region == "CH":
+		return numberFormat{decimal: ".", thousands: "'", symbolAfter: language != "en"}
+	case decimalCommaLanguages[language]:
+		return numberFormat{decimal: ",", thousands: ".", symbolAfter: true}
+	case spaceGroupLanguages[language]:
+		return numberFormat{decimal: ",", thousands: "\u00a0", symbolAfter: true}
+	default:
+		return numberFormat{decimal: ".", thousands: ","}
+	}
+}
+
+// MoneyFormatter turns amounts into display strings such as "₹1,200.00" or
+// "1.200,00 €". Spaces inside an amount are no-break spaces, so it never
+// wraps across lines.
+type MoneyFormatter struct {
+	symbols map[string]string
+	format  numberFormat
+}
+
+func NewMoneyFormatter(symbols map[string]string, locale string) *MoneyFormatter {
+	return &MoneyFormatter{symbols: symbols, format: numberFormatForLocale(locale)}
+}
+
+// Symbol is the currency's symbol from the registry, or its code when it has
+// none.
+func (f *MoneyFormatter) Symbol(currency string) string {
+	if symbol, ok := f.symbols[currency]; ok {
+		return symbol
+	}
+	return currency
+}
+
+// Format writes amount in currency with the locale's separators. Currencies
+// without a registry symbol are written with their code, e.g. "CHF 1,200.00".
+func (f *MoneyFormatter) Format(amount float64, currency string) string {
+	decimals := 2
+	if zeroDecimalCurrencies[currency] {
+		decimals = 0
+	}
+	raw := strconv.FormatFloat(math.Abs(amount), 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(raw, ".")
+
+	number := GroupDigits(intPart, f.format.thousands, f.format.indian)
+	if fracPart != "" {
+		number += f.format.decimal + fracPart
+	}
+
+	symbol, ok := f.symbols[currency]
+	switch {
+	case f.format.symbolAfter:
+		number += "\u00a0" + f.Symbol(currency)
+	case ok:
+		number = symbol + number
+	default:
+		number = currency + "\u00a0" + number
+	}
+
+	if amount < 0 && strings.Trim(raw, "0.") != "" {
+		number = "-" + number
+	}
+	return number
+}
+
+// GroupDigits separates digits into thousands, or for Indian grouping a last
+// group of three and groups of two before it (1,23,45,678).
+func GroupDigits(digits, separator string, indian bool) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	groupSize := 3
+	if indian {
+		groupSize = 2
+	}
+
+	var groups []string
+	for len(head) > groupSize {
+		groups = append([]string{head[len(head)-groupSize:]}, groups...)
+		head = head[:len(head)-groupSize]
+	}
+	groups = append([]string{head}, groups...)
+	groups = append(groups, tail)
+	return strings.Join(groups, separator)
+}
+
+// NumberFormat writes plain amounts with two decimals and no currency, as
+// exports and chat messages show them.
+type NumberFormat struct {
+	Decimal   string
+	Thousands string
+	Indian    bool
+}
+
+func (f NumberFormat) FormatAmount(amount float64) string {
+	raw := strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)
+	intPart, fracPart := raw[:len(raw)-3], raw[len(raw)-2:]
+
+	sign := ""
+	if amount < 0 && raw != "0.00" {
+		sign = "-"
+	}
+	return sign + GroupDigits(intPart, f.Thousands, f.Indian) + f.Decimal + fracPart
+}
@@ -0,0 +1,61 @@
+package services
+
+import "testing"
+
+func TestMoneyFormatterFormat(t *testing.T) {
+	symbols := map[string]string{"INR": "₹", "EUR": "€", "USD": "$", "JPY": "¥"}
+	tests := []struct {
+		locale   string
+		amount   float64
+		currency string
+		want     string
+	}{
+		{"en-IN", 1200, "INR", "₹1,200.00"},
+		{"en-IN", 1234567.5, "INR", "₹12,34,567.50"},
+		{"en-US", 1234567.5, "USD", "$1,234,567.50"},
+		{"", -45.5, "USD", "-$45.50"},
+		{"de-DE", 1234.5, "EUR", "1.234,50\u00a0€"},
+		{"fr_FR", 1234.5, "EUR", "1\u00a0234,50\u00a0€"},
+		{"de-CH", 1234.5, "CHF", "1'234.50\u00a0CHF"},
+		{"en", 1234.5, "CHF", "CHF\u00a01,234.50"},
+		{"en", 120000, "JPY", "¥120,000"},
+		{"en", -0.001, "USD", "$0.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.want, func(t *testing.T) {
+			f := NewMoneyFormatter(symbols, tt.locale)
+			if got := f.Format(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("Format(%v, %s) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+
+	f := NewMoneyFormatter(symbols, "en")
+	if f.Symbol("INR") != "₹" || f.Symbol("CHF") != "CHF" {
+		t.Errorf("Symbol() = %q, %q", f.Symbol("INR"), f.Symbol("CHF"))
+	}
+}
+
+func TestNumberFormatFormatAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		format NumberFormat
+		amount float64
+		want   string
+	}{
+		{"raw", NumberFormat{Decimal: "."}, 1234567.5, "1234567.50"},
+		{"english", NumberFormat{Decimal: ".", Thousands: ","}, 1234567.5, "1,234,567.50"},
+		{"indian", NumberFormat{Decimal: ".", Thousands: ",", Indian: true}, 1234567.5, "12,34,567.50"},
+		{"indian below a lakh", NumberFormat{Decimal: ".", Thousands: ",", Indian: true}, 99999, "99,999.00"},
+		{"german", NumberFormat{Decimal: ",", Thousands: "."}, -1234.5, "-1.234,50"},
+		{"negative zero", NumberFormat{Decimal: ".", Thousands: ","}, -0.004, "0.00"},
+		{"rounds up a group", NumberFormat{Decimal: ".", Thousands: ","}, 999.999, "1,000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.FormatAmount(tt.amount); got != tt.want {
+				t.Errorf("FormatAmount(%v) = %q, want %q", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeName trims a user, placeholder or group name and puts it in
+// Unicode NFC, so "José" typed with a combining accent is stored the same as
+// "José" typed with a precomposed é, and both compare and search alike.
+func NormalizeName(name string) string {
+	return norm.NFC.String(strings.TrimSpace(name))
+}
+
+// NameLength counts the characters a reader sees in name, which is what the
+// name length limits are about. Bytes overcount anything outside ASCII and
+// runes overcount accents and emoji, so combining marks, variation
+// selectors, skin tones and emoji tags count with the character before them,
+// a zero-width joiner merges the characters on either side, and a pair of
+// regional indicators is one flag.
+func NameLength(name string) int {
+	count := 0
+	joining, flagOpen := false, false
+	for _, r := range name {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc),
+			r >= 0x1F3FB && r <= 0x1F3FF,
+			r >= 0xE0020 && r <= 0xE007F:
+			continue
+		case r == '\u200d':
+			joining = true
+			continue
+		case joining:
+			joining = false
+			continue
+		case r >= 0x1F1E6 && r <= 0x1F1FF:
+			flagOpen = !flagOpen
+			if !flagOpen {
+				continue
+			}
+			count++
+			continue
+		}
+		flagOpen = false
+		count++
+	}
+	return count
+}
+
+// ValidNameLength reports whether name is within the group name limits,
+// which also apply to placeholder names.
+func ValidNameLength(name string) bool {
+	n := NameLength(name)
+	return n >= MinGroupNameLength && n <= MaxGroupNameLength
+}
@@ -0,0 +1,49 @@
+package services
+
+import "testing"
+
+func TestNameLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"ASCII", "Goa Trip", 8},
+		{"Precomposed accent", "José", 4},
+		{"Combining accent", "Jose\u0301", 4},
+		{"Devanagari", "नमस्ते", 4},
+		{"Emoji", "Trip 🏖️", 6},
+		{"Skin tone", "👍🏽", 1},
+		{"ZWJ family", "👨‍👩‍👧", 1},
+		{"Flags", "🇮🇳🇩🇪", 2},
+		{"Keycap", "1️⃣", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NameLength(tt.input); got != tt.expected {
+				t.Errorf("NameLength(%q) = %d, expected %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Trims", "  Flat 4B ", "Flat 4B"},
+		{"Composes accents", "Jose\u0301", "José"},
+		{"Already NFC", "José", "José"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.input); got != tt.expected {
+				t.Errorf("NormalizeName(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
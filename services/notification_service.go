@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type NotificationPayload struct {
+	Event      models.NotificationEvent
+	GroupID    string
+	ExpenseID  string
+	ActorID    string
+	Message    string
+	Recipients []string
+	// Template and Args, when set, replace Message with the template
+	// rendered in the group's default language.
+	Template notificationTemplate
+	Args     []interface{}
+	// DeliverAt is set by Dispatch when the group's quiet hours hold the
+	// notification back; zero means deliver now.
+	DeliverAt time.Time
+}
+
+type NotificationService interface {
+	GetGroupSettings(ctx context.Context, groupID, userID string) (*models.GroupNotificationSettings, error)
+	UpdateGroupSettings(ctx context.Context, groupID, userID string, settings *models.GroupNotificationSettings) (*models.GroupNotificationSettings, error)
+	GetNotifications(ctx context.Context, userID string) ([]models.Notification, error)
+	MarkRead(ctx context.Context, notificationID, userID string) error
+	Dispatch(ctx context.Context, payload NotificationPayload) error
+	GetQuietHours(ctx context.Context, groupID, userID string) (*models.GroupQuietHours, error)
+	UpdateQuietHours(ctx context.Context, groupID, userID string, quietHours *models.GroupQuietHours) (*models.GroupQuietHours, error)
+}
+
+type notificationService struct {
+	notificationRepo   repository.NotificationRepository
+	groupRepo          repository.GroupRepository
+	integrationService IntegrationService
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository, groupRepo repository.GroupRepository, integrationService IntegrationService) NotificationService {
+	return &notificationService{
+		notificationRepo:   notificationRepo,
+		groupRepo:          groupRepo,
+		integrationService: integrationService,
+	}
+}
+
+func (s *notificationService) GetGroupSettings(ctx context.Context, groupID, userID string) (*models.GroupNotificationSettings, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.notificationRepo.GetSettings(ctx, groupID, userID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			defaults := models.DefaultGroupNotificationSettings(groupID, userID)
+			return &defaults, nil
+		}
+		return nil, apperrors.DatabaseError("getting notification settings", err)
+	}
+	return settings, nil
+}
+
+func (s *notificationService) UpdateGroupSettings(ctx context.Context, groupID, userID string, settings *models.GroupNotificationSettings) (*models.GroupNotificationSettings, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	settings.GroupID = groupID
+	settings.UserID = userID
+	if err := s.notificationRepo.UpsertSettings(ctx, settings); err != nil {
+		return nil, apperrors.DatabaseError("updating notification settings", err)
+	}
+	return settings, nil
+}
+
+func (s *notificationService) GetNotifications(ctx context.Context, userID string) ([]models.Notification, error) {
+	notifications, err := s.notificationRepo.GetByUserID(ctx, userID, NotificationsLimit)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting notifications", err)
+	}
+	return notifications, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, notificationID, userID string) error {
+	if err := s.notificationRepo.MarkRead(ctx, notificationID, userID); err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.NotFound("Notification")
+		}
+		return apperrors.DatabaseError("marking notification read", err)
+	}
+	return nil
+}
+
+func (s *notificationService) GetQuietHours(ctx context.Context, groupID, userID string) (*models.GroupQuietHours, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	quietHours, err := s.notificationRepo.GetQuietHours(ctx, groupID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return &models.GroupQuietHours{GroupID: groupID, Start: DefaultQuietHoursStart, End: DefaultQuietHoursEnd, TimeZone: "UTC"}, nil
+		}
+		return nil, apperrors.DatabaseError("getting quiet hours", err)
+	}
+	return quietHours, nil
+}
+
+func (s *notificationService) UpdateQuietHours(ctx context.Context, groupID, userID string, quietHours *models.GroupQuietHours) (*models.GroupQuietHours, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := validateQuietHours(quietHours); err != nil {
+		return nil, err
+	}
+	quietHours.GroupID = groupID
+	quietHours.UpdatedBy = &userID
+	if err := s.notificationRepo.UpsertQuietHours(ctx, quietHours); err != nil {
+		return nil, apperrors.DatabaseError("updating quiet hours", err)
+	}
+	zap.L().Info("Updated group quiet hours",
+		zap.String("group_id", groupID),
+		zap.Bool("enabled", quietHours.Enabled),
+		zap.String("start", quietHours.Start),
+		zap.String("end", quietHours.End),
+		zap.String("time_zone", quietHours.TimeZone))
+	return quietHours, nil
+}
+
+func validateQuietHours(q *models.GroupQuietHours) error {
+	q.Start = strings.TrimSpace(q.Start)
+	q.End = strings.TrimSpace(q.End)
+	q.TimeZone = strings.TrimSpace(q.TimeZone)
+	if q.TimeZone == "" {
+		q.TimeZone = "UTC"
+	}
+
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return apperrors.InvalidFieldFormat("start", "HH:MM (24-hour)")
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return apperrors.InvalidFieldFormat("end", "HH:MM (24-hour)")
+	}
+	if start == end {
+		return apperrors.InvalidRequest("Quiet hours must start and end at different times.")
+	}
+	if _, err := time.LoadLocation(q.TimeZone); err != nil {
+		return apperrors.InvalidRequestWithDetails("Unknown time zone.", "Use an IANA time zone name such as Asia/Kolkata or Europe/Berlin.")
+	}
+	return nil
+}
+
+// parseClock turns "HH:MM" into minutes after midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing clock time %q: %w", value, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// quietHoursEnd reports whether now falls inside the quiet hours and, if so,
+// when they end. Wall-clock times are resolved in the configured time zone,
+// so the end follows daylight saving changes.
+func quietHoursEnd(q *models.GroupQuietHours, now time.Time) (time.Time, bool) {
+	if q == nil || !q.Enabled {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(q.End)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	inside := minute >= start && minute < end
+	if start > end {
+		inside = minute >= start || minute < end
+	}
+	if !inside {
+		return time.Time{}, false
+	}
+
+	day := local
+	if minute >= end {
+		day = local.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), end/60, end%60, 0, 0, loc), true
+}
+
+// isSandbox reports whether groupID is a sandbox group. Lookup failures
+// count as a real group, so notifications are not lost.
+func (s *notificationService) isSandbox(ctx context.Context, groupID string) bool {
+	if groupID == "" {
+		return false
+	}
+	sandbox, err := s.groupRepo.IsSandbox(ctx, groupID)
+	if err != nil {
+		zap.L().Warn("Failed to check sandbox flag, notifying as usual", zap.String("group_id", groupID), zap.Error(err))
+		return false
+	}
+	return sandbox
+}
+
+// holdUntil returns when a non-urgent notification for the group may be
+// delivered, or zero to deliver now. Lookup failures never hold anything back.
+func (s *notificationService) holdUntil(ctx context.Context, payload NotificationPayload) time.Time {
+	if payload.GroupID == "" || payload.Event.Urgent() {
+		return time.Time{}
+	}
+	quietHours, err := s.notificationRepo.GetQuietHours(ctx, payload.GroupID)
+	if err != nil {
+		if !apperrors.IsNotFoundError(err) {
+			zap.L().Warn("Failed to load quiet hours, delivering immediately", zap.String("group_id", payload.GroupID), zap.Error(err))
+		}
+		return time.Time{}
+	}
+	end, quiet := quietHoursEnd(quietHours, time.Now())
+	if !quiet {
+		return time.Time{}
+	}
+	return end
+}
+
+// Dispatch delivers payload to the group's integrations and members. Sandbox
+// groups send nothing.
+func (s *notificationService) Dispatch(ctx context.Context, payload NotificationPayload) error {
+	if s.isSandbox(ctx, payload.GroupID) {
+		return nil
+	}
+	payload.DeliverAt = s.holdUntil(ctx, payload)
+	if payload.Template != "" {
+		payload.Message = renderNotification(groupLanguage(ctx, s.groupRepo, payload.GroupID), payload.Template, payload.Args...)
+	}
+
+	if s.integrationService != nil {
+		if err := s.integrationService.Publish(ctx, payload); err != nil {
+			zap.L().Error("Failed to publish to group integrations",
+				zap.String("event", string(payload.Event)),
+				zap.String("group_id", payload.GroupID),
+				zap.Error(err))
+		}
+	}
+
+	recipients := payload.Recipients
+	if len(recipients) == 0 {
+		members, err := s.groupRepo.GetMembers(ctx, payload.GroupID)
+		if err != nil {
+			return apperrors.DatabaseError("getting group members", err)
+		}
+		for _, m := range members {
+			if m.IsPlaceholder {
+				continue
+			}
+			recipients = append(recipients, m.ID)
+		}
+	}
+
+	settings, err := s.notificationRepo.GetSettingsForGroup(ctx, payload.GroupID)
+	if err != nil {
+		return apperrors.DatabaseError("getting notification settings", err)
+	}
+
+	for _, recipientID := range recipients {
+		if recipientID == payload.ActorID {
+			continue
+		}
+		if userSettings, ok := settings[recipientID]; ok && !userSettings.Allows(payload.Event) {
+			continue
+		}
+
+		notification := &models.Notification{
+			ID:        uuid.New().String(),
+			UserID:    recipientID,
+			Event:     payload.Event,
+			Message:   payload.Message,
+			DeliverAt: payload.DeliverAt,
+		}
+		if payload.GroupID != "" {
+			notification.GroupID = &payload.GroupID
+		}
+		if payload.ExpenseID != "" {
+			notification.ExpenseID = &payload.ExpenseID
+		}
+		if payload.ActorID != "" {
+			notification.ActorID = &payload.ActorID
+		}
+
+		if err := s.notificationRepo.Create(ctx, notification); err != nil {
+			return apperrors.DatabaseError("creating notification", err)
+		}
+	}
+	return nil
+}
+
+func dispatchNotificationAsync(notificationService NotificationService, payload NotificationPayload) {
+	if notificationService == nil {
+		return
+	}
+	go func() {
+		if err := notificationService.Dispatch(context.Background(), payload); err != nil {
+			zap.L().Error("Failed to dispatch notification",
+				zap.String("event", string(payload.Event)),
+				zap.String("group_id", payload.GroupID),
+				zap.Error(err))
+		}
+	}()
+}
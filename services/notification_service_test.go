@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestQuietHoursEnd(t *testing.T) {
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	overnight := &models.GroupQuietHours{Enabled: true, Start: "22:00", End: "08:00", TimeZone: "Asia/Kolkata"}
+
+	tests := []struct {
+		name    string
+		quiet   *models.GroupQuietHours
+		now     time.Time
+		wantEnd time.Time
+		wantOK  bool
+	}{
+		{"Before midnight", overnight, time.Date(2024, 3, 1, 23, 30, 0, 0, kolkata), time.Date(2024, 3, 2, 8, 0, 0, 0, kolkata), true},
+		{"After midnight", overnight, time.Date(2024, 3, 2, 6, 0, 0, 0, kolkata), time.Date(2024, 3, 2, 8, 0, 0, 0, kolkata), true},
+		{"Outside", overnight, time.Date(2024, 3, 2, 8, 0, 0, 0, kolkata), time.Time{}, false},
+		{"Evaluated in group time zone", overnight, time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC), time.Date(2024, 3, 2, 8, 0, 0, 0, kolkata), true},
+		{"Disabled", &models.GroupQuietHours{Start: "22:00", End: "08:00", TimeZone: "UTC"}, time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC), time.Time{}, false},
+		{"Same-day window", &models.GroupQuietHours{Enabled: true, Start: "13:00", End: "15:00", TimeZone: "UTC"}, time.Date(2024, 3, 1, 14, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC), true},
+		{"Across a DST change", &models.GroupQuietHours{Enabled: true, Start: "22:00", End: "08:00", TimeZone: "Europe/Berlin"}, time.Date(2024, 3, 30, 23, 0, 0, 0, berlin), time.Date(2024, 3, 31, 8, 0, 0, 0, berlin), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, ok := quietHoursEnd(tt.quiet, tt.now)
+			if ok != tt.wantOK || !end.Equal(tt.wantEnd) {
+				t.Errorf("expected (%v, %v), got (%v, %v)", tt.wantEnd, tt.wantOK, end, ok)
+			}
+		})
+	}
+}
+
+func TestValidateQuietHours(t *testing.T) {
+	valid := &models.GroupQuietHours{Start: " 22:00", End: "07:30"}
+	if err := validateQuietHours(valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valid.TimeZone != "UTC" || valid.Start != "22:00" {
+		t.Errorf("expected normalized quiet hours, got %+v", valid)
+	}
+
+	for _, q := range []models.GroupQuietHours{
+		{Start: "25:00", End: "07:00"},
+		{Start: "10pm", End: "07:00"},
+		{Start: "22:00", End: "22:00"},
+		{Start: "22:00", End: "07:00", TimeZone: "Mars/Olympus"},
+	} {
+		if err := validateQuietHours(&q); err == nil {
+			t.Errorf("expected error for %+v", q)
+		}
+	}
+}
+
+type recordingNotificationRepo struct {
+	stubNotificationRepository
+	settings map[string]models.GroupNotificationSettings
+	created  []*models.Notification
+}
+
+func (r *recordingNotificationRepo) GetQuietHours(context.Context, string) (*models.GroupQuietHours, error) {
+	return nil, errors.New("quiet hours not found")
+}
+
+func (r *recordingNotificationRepo) GetSettingsForGroup(context.Context, string) (map[string]models.GroupNotificationSettings, error) {
+	return r.settings, nil
+}
+
+func (r *recordingNotificationRepo) Create(_ context.Context, n *models.Notification) error {
+	r.created = append(r.created, n)
+	return nil
+}
+
+func TestDispatchRespectsGroupSettings(t *testing.T) {
+	mutedComments := models.DefaultGroupNotificationSettings("g1", "carol")
+	mutedComments.Comments = false
+	muted := models.DefaultGroupNotificationSettings("g1", "dan")
+	muted.Muted = true
+
+	repo := &recordingNotificationRepo{settings: map[string]models.GroupNotificationSettings{"carol": mutedComments, "dan": muted}}
+	groupRepo := &mockGroupRepo{members: []models.User{
+		{ID: "alice"}, {ID: "bob"}, {ID: "carol"}, {ID: "dan"}, {ID: "ph", IsPlaceholder: true},
+	}}
+	s := NewNotificationService(repo, groupRepo, nil)
+
+	tests := []struct {
+		event    models.NotificationEvent
+		expected []string
+	}{
+		{models.NotificationEventNewExpense, []string{"bob", "carol"}},
+		{models.NotificationEventComment, []string{"bob"}},
+	}
+
+	for _, tt := range tests {
+		repo.created = nil
+		err := s.Dispatch(context.Background(), NotificationPayload{Event: tt.event, GroupID: "g1", ExpenseID: "e1", ActorID: "alice", Message: "hi"})
+		if err != nil {
+			t.Fatalf("Dispatch(%s) error = %v", tt.event, err)
+		}
+
+		var recipients []string
+		for _, n := range repo.created {
+			recipients = append(recipients, n.UserID)
+			if n.GroupID == nil || *n.GroupID != "g1" || n.ExpenseID == nil || *n.ExpenseID != "e1" || !n.DeliverAt.IsZero() {
+				t.Errorf("Dispatch(%s) notification = %+v, expected group, expense and immediate delivery", tt.event, n)
+			}
+		}
+		if !reflect.DeepEqual(recipients, tt.expected) {
+			t.Errorf("Dispatch(%s) recipients = %v, expected %v", tt.event, recipients, tt.expected)
+		}
+	}
+}
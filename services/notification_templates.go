@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+
+	apperrors "unwise-backend/errors"
+)
+
+// notificationTemplate names a notification message. Dispatch renders it in
+// the group's default language, so every member reads the same wording.
+type notificationTemplate string
+
+const (
+	notifyNewExpense         notificationTemplate = "new_expense"
+	notifyRefund             notificationTemplate = "refund"
+	notifyComment            notificationTemplate = "comment"
+	notifyReminder           notificationTemplate = "reminder"
+	notifySettlement         notificationTemplate = "settlement"
+	notifySettlementReversed notificationTemplate = "settlement_reversed"
+	notifyCover              notificationTemplate = "cover"
+	notifyExclusionRequested notificationTemplate = "exclusion_requested"
+	notifyExclusionAccepted  notificationTemplate = "exclusion_accepted"
+	notifyExclusionRejected  notificationTemplate = "exclusion_rejected"
+	notifyChangeRequested    notificationTemplate = "change_requested"
+	notifyChangeApplied      notificationTemplate = "change_applied"
+	notifyChangeRejected     notificationTemplate = "change_rejected"
+	notifyBalanceAlert       notificationTemplate = "balance_alert"
+	notifyStandingCreated    notificationTemplate = "standing_repayment_created"
+	notifyStandingRecorded   notificationTemplate = "standing_repayment_recorded"
+	notifyStandingCancelled  notificationTemplate = "standing_repayment_cancelled"
+)
+
+// notificationTemplates holds the format strings per language. Indexed verbs
+// let a translation reorder the arguments.
+var notificationTemplates = map[string]map[notificationTemplate]string{
+	"en": {
+		notifyNewExpense:         "New expense: %s (%.2f %s)",
+		notifyRefund:             "Refund on %s: %.2f %s",
+		notifyComment:            "New comment on %s",
+		notifyReminder:           "%s reminded you that you owe them %s in %s",
+		notifySettlement:         "%s paid %s %.2f %s",
+		notifySettlementReversed: "A settlement of %.2f %s was reversed",
+		notifyCover:              "%s covered %s %.2f %s",
+		notifyExclusionRequested: "%s says they weren't part of %s",
+		notifyExclusionAccepted:  "You were removed from %s",
+		notifyExclusionRejected:  "%s kept you on %s",
+		notifyChangeRequested:    "%s wants to change %s, which you had settled up. Please confirm",
+		notifyChangeApplied:      "Your change to %s was confirmed and applied",
+		notifyChangeRejected:     "%s rejected your change to %s",
+		notifyBalanceAlert:       "You now owe %.2f %s in this group, above your alert at %.2f %[2]s",
+		notifyStandingCreated:    "%s set up a monthly repayment of %.2f %s from %s to %s",
+		notifyStandingRecorded:   "Monthly repayment recorded: %s paid %s %.2f %s",
+		notifyStandingCancelled:  "%s cancelled the monthly repayment of %.2f %s from %s to %s",
+	},
+	"es": {
+		notifyNewExpense:         "Nuevo gasto: %s (%.2f %s)",
+		notifyRefund:             "Reembolso de %s: %.2f %s",
+		notifyComment:            "Nuevo comentario en %s",
+		notifyReminder:           "%s te recordó que le debes %s en %s",
+		notifySettlement:         "%s pagó a %s %.2f %s",
+		notifySettlementReversed: "Se revirtió un pago de %.2f %s",
+		notifyCover:              "%s cubrió a %s %.2f %s",
+		notifyExclusionRequested: "%s dice que no participó en %s",
+		notifyExclusionAccepted:  "Te quitaron de %s",
+		notifyExclusionRejected:  "%s te mantuvo en %s",
+		notifyChangeRequested:    "%s quiere cambiar %s, que ya habías saldado. Confírmalo",
+		notifyChangeApplied:      "Se confirmó y aplicó tu cambio en %s",
+		notifyChangeRejected:     "%s rechazó tu cambio en %s",
+		notifyBalanceAlert:       "Ahora debes %.2f %s en este grupo, por encima de tu alerta de %.2f %[2]s",
+		notifyStandingCreated:    "%s programó un reembolso mensual de %.2f %s de %s a %s",
+		notifyStandingRecorded:   "Reembolso mensual registrado: %s pagó a %s %.2f %s",
+		notifyStandingCancelled:  "%s canceló el reembolso mensual de %.2f %s de %s a %s",
+	},
+	"fr": {
+		notifyNewExpense:         "Nouvelle dépense : %s (%.2f %s)",
+		notifyRefund:             "Remboursement sur %s : %.2f %s",
+		notifyComment:            "Nouveau commentaire sur %s",
+		notifyReminder:           "%s vous rappelle que vous lui devez %s dans %s",
+		notifySettlement:         "%[1]s a payé %.2[3]f %[4]s à %[2]s",
+		notifySettlementReversed: "Un règlement de %.2f %s a été annulé",
+		notifyCover:              "%[1]s a avancé %.2[3]f %[4]s pour %[2]s",
+		notifyExclusionRequested: "%s indique ne pas avoir participé à %s",
+		notifyExclusionAccepted:  "Vous avez été retiré de %s",
+		notifyExclusionRejected:  "%s vous a maintenu dans %s",
+		notifyChangeRequested:    "%s souhaite modifier %s, que vous aviez déjà réglé. Merci de confirmer",
+		notifyChangeApplied:      "Votre modification de %s a été confirmée et appliquée",
+		notifyChangeRejected:     "%s a refusé votre modification de %s",
+		notifyBalanceAlert:       "Vous devez maintenant %.2f %s dans ce groupe, au-delà de votre alerte à %.2f %[2]s",
+		notifyStandingCreated:    "%s a programmé un remboursement mensuel de %.2f %s de %s à %s",
+		notifyStandingRecorded:   "Remboursement mensuel enregistré : %[1]s a payé %.2[3]f %[4]s à %[2]s",
+		notifyStandingCancelled:  "%s a annulé le remboursement mensuel de %.2f %s de %s à %s",
+	},
+	"de": {
+		notifyNewExpense:         "Neue Ausgabe: %s (%.2f %s)",
+		notifyRefund:             "Erstattung für %s: %.2f %s",
+		notifyComment:            "Neuer Kommentar zu %s",
+		notifyReminder:           "%s erinnert dich an deine offene Schuld von %s in %s",
+		notifySettlement:         "%[1]s hat %[2]s %.2[3]f %[4]s gezahlt",
+		notifySettlementReversed: "Eine Zahlung über %.2f %s wurde rückgängig gemacht",
+		notifyCover:              "%[1]s hat %.2[3]f %[4]s für %[2]s übernommen",
+		notifyExclusionRequested: "%s gibt an, nicht an %s beteiligt gewesen zu sein",
+		notifyExclusionAccepted:  "Du wurdest aus %s entfernt",
+		notifyExclusionRejected:  "%s hat dich bei %s belassen",
+		notifyChangeRequested:    "%s möchte %s ändern, das ihr bereits ausgeglichen hattet. Bitte bestätigen",
+		notifyChangeApplied:      "Deine Änderung an %s wurde bestätigt und übernommen",
+		notifyChangeRejected:     "%s hat deine Änderung an %s abgelehnt",
+		notifyBalanceAlert:       "Du schuldest in dieser Gruppe jetzt %.2f %s, mehr als deine Warnschwelle von %.2f %[2]s",
+		notifyStandingCreated:    "%s hat eine monatliche Rückzahlung von %.2f %s von %s an %s eingerichtet",
+		notifyStandingRecorded:   "Monatliche Rückzahlung erfasst: %s hat %s %.2f %s gezahlt",
+		notifyStandingCancelled:  "%s hat die monatliche Rückzahlung von %.2f %s von %s an %s beendet",
+	},
+	"hi": {
+		notifyNewExpense:         "नया खर्च: %s (%.2f %s)",
+		notifyRefund:             "%s पर रिफ़ंड: %.2f %s",
+		notifyComment:            "%s पर नई टिप्पणी",
+		notifyReminder:           "%[1]s ने याद दिलाया कि %[3]s में आप पर उनके %[2]s बकाया हैं",
+		notifySettlement:         "%[1]s ने %[2]s को %.2[3]f %[4]s का भुगतान किया",
+		notifySettlementReversed: "%.2f %s का भुगतान वापस लिया गया",
+		notifyCover:              "%[1]s ने %[2]s के लिए %.2[3]f %[4]s चुकाए",
+		notifyExclusionRequested: "%s का कहना है कि वे %s में शामिल नहीं थे",
+		notifyExclusionAccepted:  "आपको %s से हटा दिया गया",
+		notifyExclusionRejected:  "%s ने आपको %s में बनाए रखा",
+		notifyChangeRequested:    "%s %s को बदलना चाहते हैं, जिसका आप हिसाब कर चुके थे। कृपया पुष्टि करें",
+		notifyChangeApplied:      "%s में आपका बदलाव पुष्टि के बाद लागू हो गया",
+		notifyChangeRejected:     "%s ने %s में आपका बदलाव अस्वीकार कर दिया",
+		notifyBalanceAlert:       "अब आप इस ग्रुप में %.2f %s के देनदार हैं, जो आपकी %.2f %[2]s की सीमा से ज़्यादा है",
+		notifyStandingCreated:    "%s ने %[4]s से %[5]s को %.2[2]f %[3]s का मासिक भुगतान सेट किया",
+		notifyStandingRecorded:   "मासिक भुगतान दर्ज हुआ: %s ने %s को %.2f %s चुकाए",
+		notifyStandingCancelled:  "%s ने %[4]s से %[5]s को %.2[2]f %[3]s का मासिक भुगतान रद्द किया",
+	},
+}
+
+// renderNotification formats template in lang, falling back to English for
+// languages or templates without a translation.
+func renderNotification(lang string, template notificationTemplate, args ...interface{}) string {
+	format, ok := notificationTemplates[lang][template]
+	if !ok {
+		format = notificationTemplates[apperrors.DefaultLanguage][template]
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"unwise-backend/models"
+
+	"github.com/google/generative-ai-go/genai"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+)
+
+const (
+	OCRProviderGemini = "gemini"
+	OCRProviderOpenAI = "openai"
+)
+
+// DefaultOCRProviders is the provider order used when none is configured.
+var DefaultOCRProviders = []string{OCRProviderGemini, OCRProviderOpenAI}
+
+// OCRProvider reads a receipt image and answers the extraction prompt with the
+// receipt as JSON. Providers only fetch text; parseReceiptResponse turns it
+// into a ReceiptParseResult so every provider is read the same way.
+type OCRProvider interface {
+	Name() string
+	Model() string
+	ExtractReceipt(ctx context.Context, image []byte, mimeType, prompt string) (string, error)
+}
+
+// OCRConfig picks the receipt OCR providers. Order lists provider names,
+// primary first; providers without an API key are left out.
+type OCRConfig struct {
+	Order        []string
+	GeminiAPIKey string
+	OpenAIAPIKey string
+	OpenAIModel  string
+}
+
+// NewOCRProviders builds the configured providers in order. An unknown
+// provider name is a configuration error.
+func NewOCRProviders(cfg OCRConfig) ([]OCRProvider, error) {
+	order := cfg.Order
+	if len(order) == 0 {
+		order = DefaultOCRProviders
+	}
+
+	var providers []OCRProvider
+	seen := make(map[string]bool)
+	for _, name := range order {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		switch name {
+		case OCRProviderGemini:
+			if cfg.GeminiAPIKey == "" {
+				zap.L().Warn("Skipping receipt OCR provider without an API key", zap.String("provider", name))
+				continue
+			}
+			provider, err := NewGeminiOCRProvider(cfg.GeminiAPIKey)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, provider)
+		case OCRProviderOpenAI:
+			if cfg.OpenAIAPIKey == "" {
+				zap.L().Warn("Skipping receipt OCR provider without an API key", zap.String("provider", name))
+				continue
+			}
+			providers = append(providers, NewOpenAIOCRProvider(cfg.OpenAIAPIKey, cfg.OpenAIModel))
+		default:
+			return nil, fmt.Errorf("unknown receipt OCR provider %q", name)
+		}
+	}
+	return providers, nil
+}
+
+type geminiOCRProvider struct {
+	client *genai.Client
+}
+
+func NewGeminiOCRProvider(apiKey string) (OCRProvider, error) {
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("creating gemini client: %w", err)
+	}
+	return &geminiOCRProvider{client: client}, nil
+}
+
+func (p *geminiOCRProvider) Name() string  { return OCRProviderGemini }
+func (p *geminiOCRProvider) Model() string { return AIModelName }
+
+func (p *geminiOCRProvider) ExtractReceipt(ctx context.Context, image []byte, mimeType, prompt string) (string, error) {
+	model := p.client.GenerativeModel(AIModelName)
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt), genai.Blob{MIMEType: mimeType, Data: image})
+	if err != nil {
+		return "", fmt.Errorf("generating content: %w", err)
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("no response from gemini")
+	}
+
+	text := ""
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if textPart, ok := part.(genai.Text); ok {
+			text += string(textPart)
+		}
+	}
+	return text, nil
+}
+
+const openAIChatCompletionsURL = "https://api.openai.com/v1/chat/completions"
+
+type openAIOCRProvider struct {
+	apiKey     string
+	model      string
+	url        string
+	httpClient *http.Client
+}
+
+func NewOpenAIOCRProvider(apiKey, model string) OCRProvider {
+	if model == "" {
+		model = OpenAIOCRModelName
+	}
+	return &openAIOCRProvider{
+		apiKey:     apiKey,
+		model:      model,
+		url:        openAIChatCompletionsURL,
+		httpClient: &http.Client{},
+	}
+}
+
+func (p *openAIOCRProvider) Name() string  { return OCRProviderOpenAI }
+func (p *openAIOCRProvider) Model() string { return p.model }
+
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+func (p *openAIOCRProvider) ExtractReceipt(ctx context.Context, image []byte, mimeType, prompt string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":           p.model,
+		"response_format": map[string]string{"type": "json_object"},
+		"messages": []map[string]interface{}{{
+			"role": "user",
+			"content": []openAIContentPart{
+				{Type: "text", Text: prompt},
+				{Type: "image_url", ImageURL: &openAIImageURL{
+					URL: "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(image),
+				}},
+			},
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding openai request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating openai request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling openai: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading openai response: %w", err)
+	}
+
+	var decoded struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &decoded); err != nil {
+		return "", fmt.Errorf("openai returned status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := http.StatusText(resp.StatusCode)
+		if decoded.Error != nil && decoded.Error.Message != "" {
+			message = decoded.Error.Message
+		}
+		return "", fmt.Errorf("openai returned status %d: %s", resp.StatusCode, message)
+	}
+	if len(decoded.Choices) == 0 || decoded.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("no response from openai")
+	}
+	return decoded.Choices[0].Message.Content, nil
+}
+
+// ocrRoute tracks one provider's recent health. After OCRFailureThreshold
+// consecutive failures the provider is passed over until skipUntil.
+type ocrRoute struct {
+	provider  OCRProvider
+	failures  int
+	skipUntil time.Time
+}
+
+// ocrRouter tries providers in their configured order, healthy ones first.
+// Providers that are cooling down are still tried last, so a scan only fails
+// when every provider does.
+type ocrRouter struct {
+	mu     sync.Mutex
+	routes []*ocrRoute
+	now    func() time.Time
+}
+
+func newOCRRouter(providers []OCRProvider) *ocrRouter {
+	routes := make([]*ocrRoute, len(providers))
+	for i, p := range providers {
+		routes[i] = &ocrRoute{provider: p}
+	}
+	return &ocrRouter{routes: routes, now: time.Now}
+}
+
+type ocrScan struct {
+	result *models.ReceiptParseResult
+	text   string
+	model  string
+}
+
+func (r *ocrRouter) scan(ctx context.Context, image []byte, mimeType, prompt string) (*ocrScan, error) {
+	routes := r.order()
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no receipt OCR provider configured")
+	}
+
+	var errs []error
+	for _, route := range routes {
+		providerCtx, cancel := context.WithTimeout(ctx, OCRProviderTimeout)
+		text, err := route.provider.ExtractReceipt(providerCtx, image, mimeType, prompt)
+		cancel()
+
+		var result *models.ReceiptParseResult
+		if err == nil {
+			result, text, err = parseReceiptResponse(text)
+		}
+		if ctx.Err() != nil {
+			// The caller gave up; that says nothing about the provider.
+			return nil, ctx.Err()
+		}
+		r.report(route, err)
+		if err == nil {
+			return &ocrScan{result: result, text: text, model: route.provider.Model()}, nil
+		}
+
+		zap.L().Warn("Receipt OCR provider failed",
+			zap.String("provider", route.provider.Name()),
+			zap.Error(err))
+		errs = append(errs, fmt.Errorf("%s: %w", route.provider.Name(), err))
+	}
+	return nil, fmt.Errorf("all receipt OCR providers failed: %w", errors.Join(errs...))
+}
+
+// order returns the routes to try: healthy providers in configured order,
+// then the ones cooling down, soonest to recover first.
+func (r *ocrRouter) order() []*ocrRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var healthy, cooling []*ocrRoute
+	for _, route := range r.routes {
+		if now.Before(route.skipUntil) {
+			cooling = append(cooling, route)
+		} else {
+			healthy = append(healthy, route)
+		}
+	}
+	sort.SliceStable(cooling, func(i, j int) bool {
+		return cooling[i].skipUntil.Before(cooling[j].skipUntil)
+	})
+	return append(healthy, cooling...)
+}
+
+func (r *ocrRouter) report(route *ocrRoute, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil {
+		route.failures = 0
+		route.skipUntil = time.Time{}
+		return
+	}
+	route.failures++
+	if route.failures >= OCRFailureThreshold {
+		route.skipUntil = r.now().Add(OCRProviderCooldown)
+		zap.L().Warn("Receipt OCR provider marked unhealthy",
+			zap.String("provider", route.provider.Name()),
+			zap.Int("consecutive_failures", route.failures),
+			zap.Time("skip_until", route.skipUntil))
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeOCRProvider struct {
+	name     string
+	response string
+	err      error
+	calls    int
+}
+
+func (p *fakeOCRProvider) Name() string  { return p.name }
+func (p *fakeOCRProvider) Model() string { return p.name + "-model" }
+
+func (p *fakeOCRProvider) ExtractReceipt(ctx context.Context, image []byte, mimeType, prompt string) (string, error) {
+	p.calls++
+	return p.response, p.err
+}
+
+const fakeReceiptJSON = "```json\n{\"items\": [{\"name\": \"Tea\", \"price\": 40}], \"total\": 40, \"currency\": \"inr\"}\n```"
+
+func TestOCRRouterFallsBack(t *testing.T) {
+	primary := &fakeOCRProvider{name: "primary", err: errors.New("unavailable")}
+	fallback := &fakeOCRProvider{name: "fallback", response: fakeReceiptJSON}
+	router := newOCRRouter([]OCRProvider{primary, fallback})
+
+	scan, err := router.scan(context.Background(), nil, "image/jpeg", receiptPrompt)
+	if err != nil {
+		t.Fatalf("scan() error = %v", err)
+	}
+	if scan.model != "fallback-model" {
+		t.Errorf("model = %q, want fallback-model", scan.model)
+	}
+	if len(scan.result.Items) != 1 || scan.result.Total != 40 || scan.result.Currency != "INR" {
+		t.Errorf("unexpected result %+v", scan.result)
+	}
+}
+
+func TestOCRRouterSkipsUnhealthyProvider(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	primary := &fakeOCRProvider{name: "primary", response: "not json"}
+	fallback := &fakeOCRProvider{name: "fallback", response: fakeReceiptJSON}
+	router := newOCRRouter([]OCRProvider{primary, fallback})
+	router.now = func() time.Time { return now }
+
+	for i := 0; i < OCRFailureThreshold+2; i++ {
+		if _, err := router.scan(context.Background(), nil, "image/jpeg", receiptPrompt); err != nil {
+			t.Fatalf("scan() error = %v", err)
+		}
+	}
+	if primary.calls != OCRFailureThreshold {
+		t.Errorf("primary called %d times, want %d before it is skipped", primary.calls, OCRFailureThreshold)
+	}
+
+	// Once the cooldown passes the primary is tried first again.
+	now = now.Add(OCRProviderCooldown)
+	primary.response = fakeReceiptJSON
+	scan, err := router.scan(context.Background(), nil, "image/jpeg", receiptPrompt)
+	if err != nil {
+		t.Fatalf("scan() error = %v", err)
+	}
+	if scan.model != "primary-model" {
+		t.Errorf("model = %q after cooldown, want primary-model", scan.model)
+	}
+}
+
+func TestOCRRouterTriesCoolingProvidersLast(t *testing.T) {
+	primary := &fakeOCRProvider{name: "primary", err: errors.New("unavailable")}
+	router := newOCRRouter([]OCRProvider{primary})
+
+	for i := 0; i < OCRFailureThreshold; i++ {
+		router.scan(context.Background(), nil, "image/jpeg", receiptPrompt)
+	}
+	primary.err = nil
+	primary.response = fakeReceiptJSON
+	if _, err := router.scan(context.Background(), nil, "image/jpeg", receiptPrompt); err != nil {
+		t.Fatalf("scan() error = %v, want the only provider to be tried while cooling down", err)
+	}
+}
+
+func TestOCRRouterAllFail(t *testing.T) {
+	router := newOCRRouter([]OCRProvider{
+		&fakeOCRProvider{name: "primary", err: errors.New("quota exceeded")},
+		&fakeOCRProvider{name: "fallback", err: errors.New("timeout")},
+	})
+	_, err := router.scan(context.Background(), nil, "image/jpeg", receiptPrompt)
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("scan() error = %v, want both provider errors", err)
+	}
+
+	if _, err := newOCRRouter(nil).scan(context.Background(), nil, "image/jpeg", receiptPrompt); err == nil {
+		t.Error("expected an error without providers")
+	}
+}
+
+func TestNewOCRProviders(t *testing.T) {
+	providers, err := NewOCRProviders(OCRConfig{Order: []string{"OpenAI", "gemini", "openai"}, OpenAIAPIKey: "key"})
+	if err != nil {
+		t.Fatalf("NewOCRProviders() error = %v", err)
+	}
+	if len(providers) != 1 || providers[0].Name() != OCRProviderOpenAI || providers[0].Model() != OpenAIOCRModelName {
+		t.Errorf("got %d providers, want only openai (gemini has no key)", len(providers))
+	}
+
+	if _, err := NewOCRProviders(OCRConfig{Order: []string{"tesseract"}}); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func TestOpenAIOCRProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "bad key"}}`))
+			return
+		}
+		var body struct {
+			Messages []struct {
+				Content []openAIContentPart `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Messages) != 1 || len(body.Messages[0].Content) != 2 ||
+			!strings.HasPrefix(body.Messages[0].Content[1].ImageURL.URL, "data:image/png;base64,") {
+			t.Errorf("unexpected request body %+v", body)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": `{"total": 12}`}}},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIOCRProvider("secret", "").(*openAIOCRProvider)
+	provider.url = server.URL
+	text, err := provider.ExtractReceipt(context.Background(), []byte("image"), "image/png", receiptPrompt)
+	if err != nil || text != `{"total": 12}` {
+		t.Errorf("ExtractReceipt() = %q, %v", text, err)
+	}
+
+	provider.apiKey = "wrong"
+	if _, err := provider.ExtractReceipt(context.Background(), []byte("image"), "image/png", receiptPrompt); err == nil || !strings.Contains(err.Error(), "bad key") {
+		t.Errorf("ExtractReceipt() error = %v, want the API error message", err)
+	}
+}
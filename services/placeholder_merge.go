@@ -0,0 +1,188 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"unwise-backend/models"
+)
+
+var placeholderMatchRank = map[models.PlaceholderMatch]int{
+	models.PlaceholderMatchEmail:       0,
+	models.PlaceholderMatchName:        1,
+	models.PlaceholderMatchSimilarName: 2,
+}
+
+// personLikeness reports whether a placeholder and another user look like the
+// same person. Two different emails always mean different people.
+func personLikeness(a, b *models.User) (models.PlaceholderMatch, bool) {
+	if a.Email != "" && b.Email != "" {
+		if strings.EqualFold(strings.TrimSpace(a.Email), strings.TrimSpace(b.Email)) {
+			return models.PlaceholderMatchEmail, true
+		}
+		return "", false
+	}
+
+	nameA, nameB := normalizeClaimName(a.Name), normalizeClaimName(b.Name)
+	if nameA == "" || nameB == "" {
+		return "", false
+	}
+	if nameA == nameB {
+		return models.PlaceholderMatchName, true
+	}
+	if strings.Fields(nameA)[0] == nameB || strings.Fields(nameB)[0] == nameA {
+		return models.PlaceholderMatchSimilarName, true
+	}
+	if utf8.RuneCountInString(nameA) >= 4 && utf8.RuneCountInString(nameB) >= 4 && editDistance(nameA, nameB) <= 1 {
+		return models.PlaceholderMatchSimilarName, true
+	}
+	return "", false
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func weakerMatch(a, b models.PlaceholderMatch) models.PlaceholderMatch {
+	if a == "" || placeholderMatchRank[b] > placeholderMatchRank[a] {
+		return b
+	}
+	return a
+}
+
+// suggestPlaceholderMerges clusters the placeholders among users (everyone
+// sharing a group with callerID) and picks a merge target for each cluster:
+// the one matching real user (the caller wins ties), otherwise the
+// placeholder that is in the most groups. Single placeholders are only
+// suggested when they match a real user.
+func suggestPlaceholderMerges(callerID string, users []models.User, groups map[string][]models.PlaceholderGroup) []models.PlaceholderMergeSuggestion {
+	var placeholders, realUsers []models.User
+	for _, u := range users {
+		if u.IsPlaceholder {
+			placeholders = append(placeholders, u)
+		} else {
+			realUsers = append(realUsers, u)
+		}
+	}
+
+	parent := make([]int, len(placeholders))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	matches := make(map[int]models.PlaceholderMatch)
+	for i := range placeholders {
+		for j := i + 1; j < len(placeholders); j++ {
+			match, ok := personLikeness(&placeholders[i], &placeholders[j])
+			if !ok {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			combined := weakerMatch(weakerMatch(matches[ri], matches[rj]), match)
+			parent[rj] = ri
+			matches[ri] = combined
+		}
+	}
+
+	clusters := make(map[int][]int)
+	var roots []int
+	for i := range placeholders {
+		root := find(i)
+		if _, ok := clusters[root]; !ok {
+			roots = append(roots, root)
+		}
+		clusters[root] = append(clusters[root], i)
+	}
+
+	suggestions := []models.PlaceholderMergeSuggestion{}
+	for _, root := range roots {
+		members := clusters[root]
+		match := matches[root]
+
+		var target *models.User
+		var targetMatch models.PlaceholderMatch
+		candidates := 0
+		for k := range realUsers {
+			var best models.PlaceholderMatch
+			for _, i := range members {
+				if m, ok := personLikeness(&placeholders[i], &realUsers[k]); ok && (best == "" || placeholderMatchRank[m] < placeholderMatchRank[best]) {
+					best = m
+				}
+			}
+			if best == "" {
+				continue
+			}
+			candidates++
+			if target == nil || realUsers[k].ID == callerID {
+				target, targetMatch = &realUsers[k], best
+			}
+		}
+		if candidates > 1 && target.ID != callerID {
+			target = nil
+		}
+
+		if target == nil {
+			if len(members) < 2 {
+				continue
+			}
+			sort.SliceStable(members, func(a, b int) bool {
+				pa, pb := placeholders[members[a]], placeholders[members[b]]
+				if len(groups[pa.ID]) != len(groups[pb.ID]) {
+					return len(groups[pa.ID]) > len(groups[pb.ID])
+				}
+				if !pa.CreatedAt.Equal(pb.CreatedAt) {
+					return pa.CreatedAt.Before(pb.CreatedAt)
+				}
+				return pa.ID < pb.ID
+			})
+			target = &placeholders[members[0]]
+		} else {
+			match = weakerMatch(match, targetMatch)
+		}
+
+		suggestion := models.PlaceholderMergeSuggestion{Target: *target, Match: match}
+		for _, i := range members {
+			p := placeholders[i]
+			pg := groups[p.ID]
+			if pg == nil {
+				pg = []models.PlaceholderGroup{}
+			}
+			suggestion.Placeholders = append(suggestion.Placeholders, models.ClaimablePlaceholder{User: p, Groups: pg})
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		ri, rj := placeholderMatchRank[suggestions[i].Match], placeholderMatchRank[suggestions[j].Match]
+		if ri != rj {
+			return ri < rj
+		}
+		return normalizeClaimName(suggestions[i].Target.Name) < normalizeClaimName(suggestions[j].Target.Name)
+	})
+	return suggestions
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestPersonLikeness(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  models.User
+		match models.PlaceholderMatch
+		ok    bool
+	}{
+		{"Same email", models.User{Name: "Rahul", Email: "R@x.com"}, models.User{Name: "Rahul S", Email: "r@x.com"}, models.PlaceholderMatchEmail, true},
+		{"Different emails", models.User{Name: "Rahul", Email: "a@x.com"}, models.User{Name: "Rahul", Email: "b@x.com"}, "", false},
+		{"Same name", models.User{Name: " Priya  Nair"}, models.User{Name: "priya nair"}, models.PlaceholderMatchName, true},
+		{"First name", models.User{Name: "Priya"}, models.User{Name: "Priya Nair"}, models.PlaceholderMatchSimilarName, true},
+		{"Typo", models.User{Name: "Aniket"}, models.User{Name: "Anikit"}, models.PlaceholderMatchSimilarName, true},
+		{"Short names need exact match", models.User{Name: "Ram"}, models.User{Name: "Rim"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := personLikeness(&tt.a, &tt.b)
+			if ok != tt.ok || match != tt.match {
+				t.Errorf("expected (%s, %v), got (%s, %v)", tt.match, tt.ok, match, ok)
+			}
+		})
+	}
+}
+
+func TestSuggestPlaceholderMerges(t *testing.T) {
+	now := time.Now()
+	users := []models.User{
+		{ID: "me", Name: "Me"},
+		{ID: "p1", Name: "Aniket", IsPlaceholder: true, CreatedAt: now},
+		{ID: "p2", Name: "aniket", IsPlaceholder: true, CreatedAt: now.Add(-time.Hour)},
+		{ID: "p3", Name: "Anikit", IsPlaceholder: true, CreatedAt: now},
+		{ID: "p4", Name: "Zoe", IsPlaceholder: true},
+		{ID: "p5", Name: "Meera", IsPlaceholder: true},
+		{ID: "u1", Name: "Meera Iyer"},
+	}
+	groups := map[string][]models.PlaceholderGroup{
+		"p1": {{GroupID: "g1"}, {GroupID: "g2"}},
+		"p2": {{GroupID: "g3"}},
+		"p3": {{GroupID: "g4"}},
+	}
+
+	suggestions := suggestPlaceholderMerges("me", users, groups)
+	if len(suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %d: %+v", len(suggestions), suggestions)
+	}
+
+	cluster := suggestions[0]
+	if len(cluster.Placeholders) != 3 || cluster.Target.ID != "p1" || cluster.Match != models.PlaceholderMatchSimilarName {
+		t.Errorf("expected Aniket cluster targeting p1 in most groups, got %+v", cluster)
+	}
+
+	toUser := suggestions[1]
+	if toUser.Target.ID != "u1" || len(toUser.Placeholders) != 1 || toUser.Placeholders[0].ID != "p5" {
+		t.Errorf("expected Meera placeholder to merge into the real user, got %+v", toUser)
+	}
+}
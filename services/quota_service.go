@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+)
+
+// QuotaLimits are the soft limits enforced per user and group. A limit of 0
+// disables that check.
+type QuotaLimits struct {
+	GroupsPerUser    int
+	MembersPerGroup  int
+	ExpensesPerGroup int
+}
+
+// QuotaService enforces soft limits on groups, members and expenses. Each
+// check takes the number of items about to be added and fails with a QUOTA_*
+// error when the acting user is not exempt and the limit would be exceeded.
+type QuotaService interface {
+	CheckGroups(ctx context.Context, userID string, adding int) error
+	CheckMembers(ctx context.Context, userID, groupID string, adding int) error
+	CheckExpenses(ctx context.Context, userID, groupID string, adding int) error
+	GetUserLimits(ctx context.Context, userID string) (*models.UserLimits, error)
+	SetExempt(ctx context.Context, userID string, exempt bool) error
+}
+
+type quotaService struct {
+	quotaRepo repository.QuotaRepository
+	limits    QuotaLimits
+}
+
+func NewQuotaService(quotaRepo repository.QuotaRepository, limits QuotaLimits) QuotaService {
+	return &quotaService{
+		quotaRepo: quotaRepo,
+		limits:    limits,
+	}
+}
+
+// exceedsQuota reports whether adding items to used would go over limit.
+func exceedsQuota(used, adding, limit int) bool {
+	return limit > 0 && adding > 0 && used+adding > limit
+}
+
+func (s *quotaService) CheckGroups(ctx context.Context, userID string, adding int) error {
+	return s.check(ctx, userID, adding, s.limits.GroupsPerUser, func() (int, error) {
+		return s.quotaRepo.CountUserGroups(ctx, userID)
+	}, apperrors.GroupQuotaExceeded)
+}
+
+func (s *quotaService) CheckMembers(ctx context.Context, userID, groupID string, adding int) error {
+	return s.check(ctx, userID, adding, s.limits.MembersPerGroup, func() (int, error) {
+		return s.quotaRepo.CountGroupMembers(ctx, groupID)
+	}, apperrors.MemberQuotaExceeded)
+}
+
+func (s *quotaService) CheckExpenses(ctx context.Context, userID, groupID string, adding int) error {
+	return s.check(ctx, userID, adding, s.limits.ExpensesPerGroup, func() (int, error) {
+		return s.quotaRepo.CountGroupExpenses(ctx, groupID)
+	}, apperrors.ExpenseQuotaExceeded)
+}
+
+func (s *quotaService) check(ctx context.Context, userID string, adding, limit int, count func() (int, error), exceeded func(int) *apperrors.AppError) error {
+	if limit <= 0 || adding <= 0 {
+		return nil
+	}
+
+	used, err := count()
+	if err != nil {
+		return apperrors.DatabaseError("counting quota usage", err)
+	}
+	if !exceedsQuota(used, adding, limit) {
+		return nil
+	}
+
+	exempt, err := s.quotaRepo.IsExempt(ctx, userID)
+	if err != nil {
+		return apperrors.DatabaseError("getting quota override", err)
+	}
+	if exempt {
+		return nil
+	}
+	return exceeded(limit)
+}
+
+func (s *quotaService) GetUserLimits(ctx context.Context, userID string) (*models.UserLimits, error) {
+	exempt, err := s.quotaRepo.IsExempt(ctx, userID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting quota override", err)
+	}
+
+	usage, err := s.quotaRepo.GetGroupUsage(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group usage", err)
+	}
+	for i := range usage {
+		usage[i].Members.Limit = s.limits.MembersPerGroup
+		usage[i].Expenses.Limit = s.limits.ExpensesPerGroup
+	}
+
+	return &models.UserLimits{
+		UserID:     userID,
+		Exempt:     exempt,
+		Groups:     models.QuotaUsage{Used: len(usage), Limit: s.limits.GroupsPerUser},
+		GroupUsage: usage,
+	}, nil
+}
+
+func (s *quotaService) SetExempt(ctx context.Context, userID string, exempt bool) error {
+	if err := s.quotaRepo.SetExempt(ctx, userID, exempt); err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.UserNotFound()
+		}
+		return apperrors.DatabaseError("setting quota override", err)
+	}
+	return nil
+}
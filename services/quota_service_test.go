@@ -0,0 +1,28 @@
+package services
+
+import "testing"
+
+func TestExceedsQuota(t *testing.T) {
+	tests := []struct {
+		name     string
+		used     int
+		adding   int
+		limit    int
+		expected bool
+	}{
+		{name: "Under the limit", used: 3, adding: 1, limit: 5, expected: false},
+		{name: "Reaching the limit", used: 4, adding: 1, limit: 5, expected: false},
+		{name: "Over the limit", used: 5, adding: 1, limit: 5, expected: true},
+		{name: "Batch crosses the limit", used: 3, adding: 3, limit: 5, expected: true},
+		{name: "Already over but adding nothing", used: 7, adding: 0, limit: 5, expected: false},
+		{name: "Limit disabled", used: 1000, adding: 10, limit: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exceedsQuota(tt.used, tt.adding, tt.limit); got != tt.expected {
+				t.Errorf("exceedsQuota(%d, %d, %d) = %v, expected %v", tt.used, tt.adding, tt.limit, got, tt.expected)
+			}
+		})
+	}
+}
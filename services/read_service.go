@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+type ReadService interface {
+	MarkRead(ctx context.Context, groupID, userID string, expenseIDs []string) (*models.MarkReadResponse, error)
+	GetExpenseReads(ctx context.Context, expenseID, userID string) ([]models.ExpenseRead, error)
+}
+
+type readService struct {
+	readRepo    repository.ReadRepository
+	expenseRepo repository.ExpenseReader
+	groupRepo   repository.GroupRepository
+}
+
+func NewReadService(readRepo repository.ReadRepository, expenseRepo repository.ExpenseReader, groupRepo repository.GroupRepository) ReadService {
+	return &readService{
+		readRepo:    readRepo,
+		expenseRepo: expenseRepo,
+		groupRepo:   groupRepo,
+	}
+}
+
+func (s *readService) MarkRead(ctx context.Context, groupID, userID string, expenseIDs []string) (*models.MarkReadResponse, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+	if len(expenseIDs) > MaxMarkReadBatchSize {
+		return nil, apperrors.InvalidRequest("Too many transactions in a single request.")
+	}
+
+	marked, err := s.readRepo.MarkSeen(ctx, groupID, userID, expenseIDs)
+	if err != nil {
+		return nil, apperrors.DatabaseError("marking transactions read", err)
+	}
+	return &models.MarkReadResponse{Marked: int(marked)}, nil
+}
+
+func (s *readService) GetExpenseReads(ctx context.Context, expenseID, userID string) ([]models.ExpenseRead, error) {
+	expense, err := s.expenseRepo.GetByID(ctx, expenseID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.ExpenseNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting expense", err)
+	}
+	if err := RequireGroupMembership(ctx, s.groupRepo, expense.GroupID, userID); err != nil {
+		return nil, err
+	}
+
+	reads, err := s.readRepo.GetByExpenseID(ctx, expenseID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting expense reads", err)
+	}
+	return reads, nil
+}
+
+func markSeenByActor(ctx context.Context, readRepo repository.ReadRepository, groupID, userID, expenseID string) {
+	if readRepo == nil {
+		return
+	}
+	if _, err := readRepo.MarkSeen(ctx, groupID, userID, []string{expenseID}); err != nil {
+		zap.L().Warn("Failed to mark transaction seen for creator",
+			zap.String("expense_id", expenseID),
+			zap.String("user_id", userID),
+			zap.Error(err))
+	}
+}
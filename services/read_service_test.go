@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+type recordingReadRepo struct {
+	stubReadRepository
+	marked []string
+	reads  []models.ExpenseRead
+}
+
+func (r *recordingReadRepo) MarkSeen(_ context.Context, groupID, userID string, expenseIDs []string) (int64, error) {
+	r.marked = append(r.marked, expenseIDs...)
+	return int64(len(expenseIDs)), nil
+}
+
+func (r *recordingReadRepo) GetByExpenseID(context.Context, string) ([]models.ExpenseRead, error) {
+	return r.reads, nil
+}
+
+type expenseByIDReader struct {
+	stubExpenseReader
+	expenses map[string]*models.Expense
+}
+
+func (r expenseByIDReader) GetByID(_ context.Context, id string) (*models.Expense, error) {
+	if e, ok := r.expenses[id]; ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("getting expense: no rows in result set")
+}
+
+func TestMarkRead(t *testing.T) {
+	tooMany := make([]string, MaxMarkReadBatchSize+1)
+	tests := []struct {
+		name         string
+		userID       string
+		expenseIDs   []string
+		expectedCode apperrors.ErrorCode
+		expected     int
+	}{
+		{name: "Marks Batch", userID: "alice", expenseIDs: []string{"e1", "e2"}, expected: 2},
+		{name: "Outside Group", userID: "mallory", expenseIDs: []string{"e1"}, expectedCode: apperrors.CodeNotGroupMember},
+		{name: "Batch Too Large", userID: "alice", expenseIDs: tooMany, expectedCode: apperrors.CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingReadRepo{}
+			s := NewReadService(repo, nil, &countingMemberRepo{members: map[string]bool{"g1/alice": true}})
+
+			resp, err := s.MarkRead(context.Background(), "g1", tt.userID, tt.expenseIDs)
+			if tt.expectedCode != "" {
+				appErr, ok := apperrors.AsAppError(err)
+				if !ok || appErr.Code != tt.expectedCode {
+					t.Errorf("MarkRead() error = %v, expected %s", err, tt.expectedCode)
+				}
+				if len(repo.marked) != 0 {
+					t.Errorf("MarkRead() marked %d transactions after rejecting the request", len(repo.marked))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MarkRead() error = %v", err)
+			}
+			if resp.Marked != tt.expected || !reflect.DeepEqual(repo.marked, tt.expenseIDs) {
+				t.Errorf("MarkRead() marked %d (%v), expected %d", resp.Marked, repo.marked, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetExpenseReadsChecksExpenseGroup(t *testing.T) {
+	reads := []models.ExpenseRead{{UserID: "bob"}}
+	s := NewReadService(
+		&recordingReadRepo{reads: reads},
+		expenseByIDReader{expenses: map[string]*models.Expense{"e1": {ID: "e1", GroupID: "g1"}}},
+		&countingMemberRepo{members: map[string]bool{"g1/alice": true, "g2/mallory": true}},
+	)
+
+	got, err := s.GetExpenseReads(context.Background(), "e1", "alice")
+	if err != nil || !reflect.DeepEqual(got, reads) {
+		t.Errorf("GetExpenseReads() = %v, %v, expected %v", got, err, reads)
+	}
+
+	_, err = s.GetExpenseReads(context.Background(), "e1", "mallory")
+	if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeNotGroupMember {
+		t.Errorf("GetExpenseReads() by another group's member error = %v, expected %s", err, apperrors.CodeNotGroupMember)
+	}
+
+	_, err = s.GetExpenseReads(context.Background(), "missing", "alice")
+	if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.ExpenseNotFound().Code {
+		t.Errorf("GetExpenseReads() of a missing expense error = %v, expected not found", err)
+	}
+}
+
+type visibilityReadRepo struct {
+	stubReadRepository
+	visibility map[string]models.ExpenseVisibility
+}
+
+func (r *visibilityReadRepo) GetVisibility(context.Context, string) (map[string]models.ExpenseVisibility, error) {
+	return r.visibility, nil
+}
+
+type singleExpenseRepo struct {
+	mockExpenseRepo
+	expense *models.Expense
+}
+
+func (r *singleExpenseRepo) GetByID(context.Context, string) (*models.Expense, error) {
+	return r.expense, nil
+}
+
+type noTagsRepo struct {
+	stubTagRepository
+}
+
+func (noTagsRepo) GetTagNamesByExpenseIDs(context.Context, []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func TestGetByIDSplitVisibility(t *testing.T) {
+	notified := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	seen := notified.Add(2 * time.Hour)
+	expense := &models.Expense{ID: "e1", GroupID: "g1", Splits: []models.ExpenseSplit{
+		{UserID: "alice"}, {UserID: "bob"}, {UserID: "carol"},
+	}}
+	s := &expenseService{
+		expenseRepo: &singleExpenseRepo{expense: expense},
+		groupRepo:   &mockGroupRepo{},
+		tagRepo:     noTagsRepo{},
+		readRepo: &visibilityReadRepo{visibility: map[string]models.ExpenseVisibility{
+			"alice": {FirstNotifiedAt: &notified, FirstSeenAt: &seen},
+			"bob":   {FirstNotifiedAt: &notified},
+			"dave":  {FirstSeenAt: &seen},
+		}},
+	}
+
+	got, err := s.GetByID(context.Background(), "e1", "alice")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+
+	tests := []struct {
+		userID           string
+		expectedNotified *time.Time
+		expectedSeen     *time.Time
+	}{
+		{"alice", &notified, &seen},
+		{"bob", &notified, nil},
+		{"carol", nil, nil},
+	}
+	for i, tt := range tests {
+		split := got.Splits[i]
+		if !reflect.DeepEqual(split.FirstNotifiedAt, tt.expectedNotified) || !reflect.DeepEqual(split.FirstSeenAt, tt.expectedSeen) {
+			t.Errorf("GetByID() %s notified = %v, seen = %v, expected %v and %v", tt.userID, split.FirstNotifiedAt, split.FirstSeenAt, tt.expectedNotified, tt.expectedSeen)
+		}
+	}
+}
@@ -6,35 +6,27 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
 
 	"unwise-backend/models"
-
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
 )
 
 type ReceiptService interface {
-	ParseReceipt(ctx context.Context, imageData io.Reader) (*models.ReceiptParseResult, error)
+	ParseReceipt(ctx context.Context, userID string, groupID *string, imageData io.Reader) (*models.ReceiptParseResult, error)
 }
 
 type receiptService struct {
-	client *genai.Client
+	router       *ocrRouter
+	auditService AIAuditService
 }
 
-func NewReceiptService(apiKey string) (ReceiptService, error) {
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return nil, fmt.Errorf("creating gemini client: %w", err)
-	}
-
-	return &receiptService{client: client}, nil
+// NewReceiptService scans receipts with the given OCR providers, trying them
+// in order and skipping any that keep failing.
+func NewReceiptService(providers []OCRProvider, auditService AIAuditService) ReceiptService {
+	return &receiptService{router: newOCRRouter(providers), auditService: auditService}
 }
 
-func (s *receiptService) ParseReceipt(ctx context.Context, imageData io.Reader) (*models.ReceiptParseResult, error) {
-	model := s.client.GenerativeModel("gemini-2.0-flash")
-
-	systemPrompt := `Extract all items and the financial summary from this receipt.
+const receiptPrompt = `Extract all items and the financial summary from this receipt.
 
 CRITICAL: Determine if the item prices shown INCLUDE tax or are PRE-TAX amounts:
 - If (sum of item prices) ≈ Total: prices ALREADY INCLUDE tax → set "prices_include_tax": true
@@ -43,6 +35,8 @@ CRITICAL: Determine if the item prices shown INCLUDE tax or are PRE-TAX amounts:
 
 Pay special attention to Indian tax structures like CGST, SGST, GST, Service Charge, and CESS.
 
+Identify the currency the receipt is priced in from currency symbols or codes (₹, Rs, $, €, £, AED, etc.).
+Ambiguous symbols like "$" need locale cues: the address, phone number format, tax names (GST/GSTIN, VAT, MwSt, TVA, sales tax) and the language of the receipt.
 Return ONLY valid JSON in this format:
 {
   "items": [{ "name": "string", "price": number }],
@@ -52,48 +46,89 @@ Return ONLY valid JSON in this format:
   "sgst": number,
   "service_charge": number,
   "total": number,
-  "prices_include_tax": boolean
+  "prices_include_tax": boolean,
+  "currency": "string",
+  "locale": "string"
 }
 
 Rules:
 - "tax" should be the sum of all taxes (CGST + SGST + CESS, etc.) if listed separately.
 - If any field is not on the receipt, set its value to 0.
 - "prices_include_tax" is REQUIRED - analyze the receipt carefully to determine this.
+- "currency" is the ISO 4217 code (e.g. "INR", "USD", "EUR"). Use "" if you cannot tell.
+- "locale" is the BCP 47 locale of the merchant's country (e.g. "en-IN", "de-DE"). Use "" if you cannot tell.
 - Do not include markdown formatting, code blocks, or additional text. Only return raw JSON.`
 
-	prompt := genai.Text(systemPrompt)
-
+// ParseReceipt reads a receipt image. groupID is the group the receipt was
+// scanned for, if any, so the scan counts toward that group's usage.
+func (s *receiptService) ParseReceipt(ctx context.Context, userID string, groupID *string, imageData io.Reader) (*models.ReceiptParseResult, error) {
 	imageBytes, err := io.ReadAll(imageData)
 	if err != nil {
 		return nil, fmt.Errorf("reading image data: %w", err)
 	}
 
-	imagePart := genai.ImageData("image/jpeg", imageBytes)
-
-	resp, err := model.GenerateContent(ctx, prompt, imagePart)
+	scan, err := s.router.scan(ctx, imageBytes, "image/jpeg", receiptPrompt)
 	if err != nil {
-		log.Printf("[ReceiptService.ParseReceipt] Gemini API call failed: %v", err)
-		return nil, fmt.Errorf("generating content: %w", err)
+		log.Printf("[ReceiptService.ParseReceipt] Receipt scan failed: %v", err)
+		return nil, err
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response from gemini")
+	result := scan.result
+	output := &models.AIOutput{
+		Kind:     models.AIOutputReceiptScan,
+		UserID:   &userID,
+		GroupID:  groupID,
+		Model:    scan.model,
+		Prompt:   receiptPrompt,
+		Response: scan.text,
 	}
-
-	text := ""
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if textPart, ok := part.(genai.Text); ok {
-			text += string(textPart)
-		}
+	if err := s.auditService.Record(ctx, output, nil); err != nil {
+		log.Printf("[ReceiptService.ParseReceipt] Failed to record receipt scan output: %v", err)
+	} else {
+		result.OutputID = output.ID
 	}
+	return result, nil
+}
 
+// parseReceiptResponse converts a provider's JSON answer into a parse result.
+// Every provider is asked for the same shape, so this is the one place that
+// cleans up formatting, fills in missing lists and settles the currency.
+func parseReceiptResponse(text string) (*models.ReceiptParseResult, string, error) {
 	text = cleanJSONResponse(text)
 
 	var result models.ReceiptParseResult
 	if err := json.Unmarshal([]byte(text), &result); err != nil {
-		return nil, fmt.Errorf("parsing gemini response: %w", err)
+		return nil, text, fmt.Errorf("parsing receipt response: %w", err)
+	}
+	if result.Items == nil {
+		result.Items = []models.ReceiptItemData{}
+	}
+	resolveReceiptCurrency(&result)
+	return &result, text, nil
+}
+
+// resolveReceiptCurrency keeps the currency read off the receipt when it looks
+// like an ISO 4217 code and otherwise falls back to the currency of the
+// detected locale.
+func resolveReceiptCurrency(result *models.ReceiptParseResult) {
+	currency := strings.ToUpper(strings.TrimSpace(result.Currency))
+	result.Currency = ""
+	result.CurrencySource = ""
+	if len(currency) == 3 && strings.IndexFunc(currency, func(r rune) bool { return r < 'A' || r > 'Z' }) == -1 {
+		result.Currency = currency
+		result.CurrencySource = models.ReceiptCurrencyFromReceipt
+		return
+	}
+	if currency = currencyForLocale(strings.TrimSpace(result.Locale)); currency != "" {
+		result.Currency = currency
+		result.CurrencySource = models.ReceiptCurrencyFromLocale
 	}
-	return &result, nil
+}
+
+// ReceiptCurrencyMismatch reports whether a detected receipt currency differs
+// from the group's default, so the client can warn before creating the expense.
+func ReceiptCurrencyMismatch(detected, groupCurrency string) bool {
+	return detected != "" && groupCurrency != "" && !strings.EqualFold(detected, groupCurrency)
 }
 
 func cleanJSONResponse(text string) string {
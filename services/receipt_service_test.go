@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestResolveReceiptCurrency(t *testing.T) {
+	tests := []struct {
+		currency       string
+		locale         string
+		expected       string
+		expectedSource string
+	}{
+		{"inr", "", "INR", models.ReceiptCurrencyFromReceipt},
+		{" EUR ", "en-IN", "EUR", models.ReceiptCurrencyFromReceipt},
+		{"₹", "en-IN", "INR", models.ReceiptCurrencyFromLocale},
+		{"", "de-DE", "EUR", models.ReceiptCurrencyFromLocale},
+		{"US$", "en", "", ""},
+		{"", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		result := models.ReceiptParseResult{Currency: tt.currency, Locale: tt.locale}
+		resolveReceiptCurrency(&result)
+		if result.Currency != tt.expected || result.CurrencySource != tt.expectedSource {
+			t.Errorf("resolveReceiptCurrency(%q, %q) = (%q, %q), expected (%q, %q)",
+				tt.currency, tt.locale, result.Currency, result.CurrencySource, tt.expected, tt.expectedSource)
+		}
+	}
+}
+
+func TestReceiptCurrencyMismatch(t *testing.T) {
+	if !ReceiptCurrencyMismatch("USD", "INR") {
+		t.Error("expected USD and INR to mismatch")
+	}
+	if ReceiptCurrencyMismatch("INR", "inr") {
+		t.Error("expected codes to compare case-insensitively")
+	}
+	if ReceiptCurrencyMismatch("", "INR") {
+		t.Error("expected no mismatch when nothing was detected")
+	}
+}
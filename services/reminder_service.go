@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+type ReminderService interface {
+	RemindAllDebtors(ctx context.Context, userID string) (*models.RemindAllResponse, error)
+	RespondToReminders(ctx context.Context, groupID, debtorID, creditorID string, req models.ReminderResponseRequest) (*models.ReminderResponse, error)
+	WithdrawReminderResponse(ctx context.Context, groupID, debtorID, creditorID string) error
+	GetReminderResponses(ctx context.Context, groupID, userID string) ([]models.ReminderResponse, error)
+}
+
+type reminderService struct {
+	userRepo            repository.UserRepository
+	groupRepo           repository.GroupRepository
+	notificationRepo    repository.NotificationRepository
+	responseRepo        repository.ReminderResponseRepository
+	settlementService   SettlementService
+	notificationService NotificationService
+}
+
+func NewReminderService(userRepo repository.UserRepository, groupRepo repository.GroupRepository, notificationRepo repository.NotificationRepository, responseRepo repository.ReminderResponseRepository, settlementService SettlementService, notificationService NotificationService) ReminderService {
+	return &reminderService{
+		userRepo:            userRepo,
+		groupRepo:           groupRepo,
+		notificationRepo:    notificationRepo,
+		responseRepo:        responseRepo,
+		settlementService:   settlementService,
+		notificationService: notificationService,
+	}
+}
+
+type debtorReminder struct {
+	user  models.User
+	debts []models.ReminderGroupDebt
+}
+
+func (s *reminderService) RemindAllDebtors(ctx context.Context, userID string) (*models.RemindAllResponse, error) {
+	creditor, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting user", err)
+	}
+
+	debtors, err := s.collectDebtors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lastReminders, err := s.notificationRepo.GetLastRemindersByActor(ctx, userID, now.Add(-ReminderCooldown))
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting recent reminders", err)
+	}
+
+	responses, err := s.responseRepo.GetActiveByCreditorID(ctx, userID, now)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting reminder responses", err)
+	}
+	heldUntil := make(map[[2]string]time.Time, len(responses))
+	for _, resp := range responses {
+		heldUntil[[2]string{resp.GroupID, resp.DebtorID}] = resp.HeldUntil
+	}
+
+	response := &models.RemindAllResponse{
+		Reminded: []models.ReminderOutcome{},
+		Skipped:  []models.ReminderOutcome{},
+	}
+
+	for _, debtor := range debtors {
+		outcome := models.ReminderOutcome{
+			UserInfo: models.UserInfo{ID: debtor.user.ID, Name: debtor.user.Name, AvatarURL: debtor.user.AvatarURL},
+			Debts:    debtor.debts,
+		}
+
+		if debtor.user.IsPlaceholder {
+			outcome.Reason = models.ReminderSkipPlaceholder
+			response.Skipped = append(response.Skipped, outcome)
+			continue
+		}
+
+		if sentAt, ok := lastReminders[debtor.user.ID]; ok {
+			nextAllowed := sentAt.Add(ReminderCooldown)
+			outcome.Reason = models.ReminderSkipCooldown
+			outcome.LastRemindedAt = &sentAt
+			outcome.NextAllowedAt = &nextAllowed
+			response.Skipped = append(response.Skipped, outcome)
+			continue
+		}
+
+		sent, failed := 0, 0
+		var heldTill *time.Time
+		for _, debt := range debtor.debts {
+			if until, ok := heldUntil[[2]string{debt.GroupID, debtor.user.ID}]; ok {
+				if heldTill == nil || until.Before(*heldTill) {
+					heldTill = &until
+				}
+				continue
+			}
+
+			allowed, err := s.remindersAllowed(ctx, debt.GroupID, debtor.user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				continue
+			}
+
+			payload := NotificationPayload{
+				Event:      models.NotificationEventReminder,
+				GroupID:    debt.GroupID,
+				ActorID:    userID,
+				Template:   notifyReminder,
+				Args:       []interface{}{creditor.Name, formatMoney(debt.Currency+" ", debt.Amount), debt.GroupName},
+				Recipients: []string{debtor.user.ID},
+			}
+			if err := s.notificationService.Dispatch(ctx, payload); err != nil {
+				zap.L().Error("Failed to send reminder",
+					zap.String("actor_id", userID),
+					zap.String("debtor_id", debtor.user.ID),
+					zap.String("group_id", debt.GroupID),
+					zap.Error(err))
+				failed++
+				continue
+			}
+			sent++
+		}
+
+		switch {
+		case sent > 0:
+			outcome.LastRemindedAt = &now
+			response.Reminded = append(response.Reminded, outcome)
+		case failed > 0:
+			outcome.Reason = models.ReminderSkipFailed
+			response.Skipped = append(response.Skipped, outcome)
+		case heldTill != nil:
+			outcome.Reason = models.ReminderSkipResponded
+			outcome.NextAllowedAt = heldTill
+			response.Skipped = append(response.Skipped, outcome)
+		default:
+			outcome.Reason = models.ReminderSkipRemindersDisabled
+			response.Skipped = append(response.Skipped, outcome)
+		}
+	}
+
+	zap.L().Info("Sent bulk reminders",
+		zap.String("user_id", userID),
+		zap.Int("reminded", len(response.Reminded)),
+		zap.Int("skipped", len(response.Skipped)))
+
+	return response, nil
+}
+
+func (s *reminderService) collectDebtors(ctx context.Context, userID string) ([]*debtorReminder, error) {
+	groups, err := s.groupRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting user groups", err)
+	}
+	groups = nonSandboxGroups(groups)
+
+	byID := make(map[string]*debtorReminder)
+	for _, group := range groups {
+		settlements, err := s.settlementService.CalculateSettlements(ctx, group.ID, userID, nil)
+		if err != nil {
+			zap.L().Warn("Failed to calculate settlements for group", zap.String("group_id", group.ID), zap.Error(err))
+			continue
+		}
+
+		owed := make(map[string]map[string]float64)
+		for _, settlement := range settlements {
+			if settlement.ToUserID != userID || settlement.FromUserID == userID {
+				continue
+			}
+			if owed[settlement.FromUserID] == nil {
+				owed[settlement.FromUserID] = make(map[string]float64)
+			}
+			owed[settlement.FromUserID][settlement.Currency] += settlement.Amount
+		}
+
+		for _, member := range group.Members {
+			for currency, amount := range owed[member.ID] {
+				amount = math.Round(amount*RoundingFactor) / RoundingFactor
+				if amount <= BalanceThreshold {
+					continue
+				}
+				debtor, ok := byID[member.ID]
+				if !ok {
+					debtor = &debtorReminder{user: member}
+					byID[member.ID] = debtor
+				}
+				debtor.debts = append(debtor.debts, models.ReminderGroupDebt{
+					GroupID:   group.ID,
+					GroupName: group.Name,
+					Currency:  currency,
+					Amount:    amount,
+				})
+			}
+		}
+	}
+
+	debtors := make([]*debtorReminder, 0, len(byID))
+	for _, debtor := range byID {
+		sort.Slice(debtor.debts, func(i, j int) bool {
+			if debtor.debts[i].GroupName != debtor.debts[j].GroupName {
+				return debtor.debts[i].GroupName < debtor.debts[j].GroupName
+			}
+			return debtor.debts[i].Currency < debtor.debts[j].Currency
+		})
+		debtors = append(debtors, debtor)
+	}
+	sort.Slice(debtors, func(i, j int) bool {
+		nameI, nameJ := strings.ToLower(debtors[i].user.Name), strings.ToLower(debtors[j].user.Name)
+		if nameI != nameJ {
+			return nameI < nameJ
+		}
+		return debtors[i].user.ID < debtors[j].user.ID
+	})
+	return debtors, nil
+}
+
+func (s *reminderService) remindersAllowed(ctx context.Context, groupID, userID string) (bool, error) {
+	settings, err := s.notificationRepo.GetSettings(ctx, groupID, userID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return true, nil
+		}
+		return false, apperrors.DatabaseError("getting notification settings", err)
+	}
+	return settings.Allows(models.NotificationEventReminder), nil
+}
+
+// RespondToReminders records the debtor's answer to the creditor's reminders
+// in a group. Responding again replaces the earlier answer, so a debtor can
+// move a promised date or turn a snooze into a promise.
+func (s *reminderService) RespondToReminders(ctx context.Context, groupID, debtorID, creditorID string, req models.ReminderResponseRequest) (*models.ReminderResponse, error) {
+	if debtorID == creditorID {
+		return nil, apperrors.CannotAddSelf("respond to reminders from")
+	}
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, debtorID); err != nil {
+		return nil, err
+	}
+	isMember, err := isGroupMember(ctx, s.groupRepo, groupID, creditorID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking membership", err)
+	}
+	if !isMember {
+		return nil, apperrors.NotFound("Group member")
+	}
+
+	now := time.Now()
+	payBy, heldUntil, err := reminderHold(req, now)
+	if err != nil {
+		return nil, err
+	}
+	note := strings.TrimSpace(req.Note)
+	if len(note) > MaxDescriptionLength {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("Note must be at most %d characters.", MaxDescriptionLength))
+	}
+
+	settlements, err := s.settlementService.CalculateSettlements(ctx, groupID, debtorID, nil)
+	if err != nil {
+		return nil, apperrors.InternalError(fmt.Errorf("calculating settlements: %w", err))
+	}
+	owes := false
+	for _, settlement := range settlements {
+		if settlement.FromUserID == debtorID && settlement.ToUserID == creditorID && settlement.Amount > BalanceThreshold {
+			owes = true
+			break
+		}
+	}
+	if !owes {
+		return nil, apperrors.InvalidRequest("You don't owe this member anything in this group.")
+	}
+
+	response := &models.ReminderResponse{
+		GroupID:    groupID,
+		DebtorID:   debtorID,
+		CreditorID: creditorID,
+		Status:     req.Status,
+		PayBy:      payBy,
+		HeldUntil:  heldUntil,
+	}
+	if note != "" {
+		response.Note = &note
+	}
+	if err := s.responseRepo.Upsert(ctx, response); err != nil {
+		return nil, apperrors.DatabaseError("saving reminder response", err)
+	}
+
+	zap.L().Info("Reminder response recorded",
+		zap.String("group_id", groupID),
+		zap.String("debtor_id", debtorID),
+		zap.String("creditor_id", creditorID),
+		zap.String("status", string(req.Status)),
+		zap.Time("held_until", heldUntil))
+
+	return response, nil
+}
+
+// WithdrawReminderResponse removes the debtor's response so the creditor can
+// remind them again straight away.
+func (s *reminderService) WithdrawReminderResponse(ctx context.Context, groupID, debtorID, creditorID string) error {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, debtorID); err != nil {
+		return err
+	}
+	deleted, err := s.responseRepo.Delete(ctx, groupID, debtorID, creditorID)
+	if err != nil {
+		return apperrors.DatabaseError("deleting reminder response", err)
+	}
+	if !deleted {
+		return apperrors.NotFound("Reminder response")
+	}
+	return nil
+}
+
+// GetReminderResponses returns the active responses in a group that the user
+// gave or received.
+func (s *reminderService) GetReminderResponses(ctx context.Context, groupID, userID string) ([]models.ReminderResponse, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+	responses, err := s.responseRepo.GetActiveByGroupID(ctx, groupID, time.Now())
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting reminder responses", err)
+	}
+	mine := make([]models.ReminderResponse, 0, len(responses))
+	for _, resp := range responses {
+		if resp.DebtorID == userID || resp.CreditorID == userID {
+			mine = append(mine, resp)
+		}
+	}
+	return mine, nil
+}
+
+// reminderHold works out how long a response holds off the creditor's
+// reminders. A promise holds them until the end of the promised day (UTC), after
+// which reminders resume if the debt is still open; a snooze holds them for
+// the given number of days.
+func reminderHold(req models.ReminderResponseRequest, now time.Time) (*string, time.Time, error) {
+	switch req.Status {
+	case models.ReminderResponsePromised:
+		if req.PayBy == "" {
+			return nil, time.Time{}, apperrors.MissingRequiredField("pay_by")
+		}
+		date, err := time.Parse("2006-01-02", req.PayBy)
+		if err != nil {
+			return nil, time.Time{}, apperrors.InvalidFieldFormat("pay_by", "YYYY-MM-DD")
+		}
+		today := now.UTC().Truncate(24 * time.Hour)
+		if date.Before(today) {
+			return nil, time.Time{}, apperrors.InvalidRequest("pay_by cannot be in the past.")
+		}
+		if date.After(today.AddDate(0, 0, MaxReminderPromiseDays)) {
+			return nil, time.Time{}, apperrors.InvalidRequest(fmt.Sprintf("pay_by can be at most %d days away.", MaxReminderPromiseDays))
+		}
+		payBy := date.Format("2006-01-02")
+		return &payBy, date.AddDate(0, 0, 1), nil
+	case models.ReminderResponseSnoozed:
+		if req.SnoozeDays < 1 || req.SnoozeDays > MaxReminderSnoozeDays {
+			return nil, time.Time{}, apperrors.InvalidRequest(fmt.Sprintf("snooze_days must be between 1 and %d.", MaxReminderSnoozeDays))
+		}
+		return nil, now.AddDate(0, 0, req.SnoozeDays), nil
+	default:
+		return nil, time.Time{}, apperrors.InvalidRequest("status must be PROMISED or SNOOZED.")
+	}
+}
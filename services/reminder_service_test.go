@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestReminderHold(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		req           models.ReminderResponseRequest
+		expectedPayBy string
+		expectedUntil time.Time
+		wantErr       bool
+	}{
+		{
+			name:          "Promise holds through the promised day",
+			req:           models.ReminderResponseRequest{Status: models.ReminderResponsePromised, PayBy: "2024-03-15"},
+			expectedPayBy: "2024-03-15",
+			expectedUntil: time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Promise to pay today",
+			req:           models.ReminderResponseRequest{Status: models.ReminderResponsePromised, PayBy: "2024-03-10"},
+			expectedPayBy: "2024-03-10",
+			expectedUntil: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Snooze counts from now",
+			req:           models.ReminderResponseRequest{Status: models.ReminderResponseSnoozed, SnoozeDays: 3},
+			expectedUntil: now.AddDate(0, 0, 3),
+		},
+		{name: "Promise without a date", req: models.ReminderResponseRequest{Status: models.ReminderResponsePromised}, wantErr: true},
+		{name: "Promise with a bad date", req: models.ReminderResponseRequest{Status: models.ReminderResponsePromised, PayBy: "15/03/2024"}, wantErr: true},
+		{name: "Promise in the past", req: models.ReminderResponseRequest{Status: models.ReminderResponsePromised, PayBy: "2024-03-09"}, wantErr: true},
+		{name: "Promise too far away", req: models.ReminderResponseRequest{Status: models.ReminderResponsePromised, PayBy: "2024-06-01"}, wantErr: true},
+		{name: "Snooze of zero days", req: models.ReminderResponseRequest{Status: models.ReminderResponseSnoozed}, wantErr: true},
+		{name: "Snooze too long", req: models.ReminderResponseRequest{Status: models.ReminderResponseSnoozed, SnoozeDays: MaxReminderSnoozeDays + 1}, wantErr: true},
+		{name: "Unknown status", req: models.ReminderResponseRequest{Status: "IGNORED"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payBy, until, err := reminderHold(tt.req, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reminderHold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !until.Equal(tt.expectedUntil) {
+				t.Errorf("held until %v, expected %v", until, tt.expectedUntil)
+			}
+			got := ""
+			if payBy != nil {
+				got = *payBy
+			}
+			if got != tt.expectedPayBy {
+				t.Errorf("pay_by = %q, expected %q", got, tt.expectedPayBy)
+			}
+		})
+	}
+}
+
+type reminderGroupRepo struct {
+	mockGroupRepo
+	groups []models.Group
+}
+
+func (r *reminderGroupRepo) GetByUserID(context.Context, string) ([]models.Group, error) {
+	return r.groups, nil
+}
+
+type fixedSettlements struct {
+	stubSettlementService
+	byGroup map[string][]models.Settlement
+}
+
+func (s fixedSettlements) CalculateSettlements(_ context.Context, groupID, _ string, _ *time.Time) ([]models.Settlement, error) {
+	return s.byGroup[groupID], nil
+}
+
+type reminderNotificationRepo struct {
+	stubNotificationRepository
+	lastReminded map[string]time.Time
+	settings     map[string]*models.GroupNotificationSettings
+}
+
+func (r reminderNotificationRepo) GetLastRemindersByActor(context.Context, string, time.Time) (map[string]time.Time, error) {
+	return r.lastReminded, nil
+}
+
+func (r reminderNotificationRepo) GetSettings(_ context.Context, _, userID string) (*models.GroupNotificationSettings, error) {
+	if settings, ok := r.settings[userID]; ok {
+		return settings, nil
+	}
+	return nil, fmt.Errorf("getting notification settings: no rows in result set")
+}
+
+type noReminderResponses struct {
+	stubReminderResponseRepository
+}
+
+func (noReminderResponses) GetActiveByCreditorID(context.Context, string, time.Time) ([]models.ReminderResponse, error) {
+	return nil, nil
+}
+
+type recordingDispatcher struct {
+	stubNotificationService
+	payloads []NotificationPayload
+}
+
+func (d *recordingDispatcher) Dispatch(_ context.Context, payload NotificationPayload) error {
+	d.payloads = append(d.payloads, payload)
+	return nil
+}
+
+func TestRemindAllDebtors(t *testing.T) {
+	members := []models.User{
+		{ID: "me", Name: "Me"}, {ID: "bob", Name: "Bob"}, {ID: "carol", Name: "Carol"},
+		{ID: "dan", Name: "Dan"}, {ID: "pat", Name: "Pat", IsPlaceholder: true}, {ID: "erin", Name: "Erin"},
+	}
+	groups := &reminderGroupRepo{groups: []models.Group{
+		{ID: "g1", Name: "Trip", Members: members},
+		{ID: "sandbox", Name: "Practice", Members: members, IsSandbox: true},
+	}}
+	settlements := fixedSettlements{byGroup: map[string][]models.Settlement{
+		"g1": {
+			{FromUserID: "bob", ToUserID: "me", Amount: 50, Currency: "INR"},
+			{FromUserID: "bob", ToUserID: "me", Amount: 0.001, Currency: "USD"},
+			{FromUserID: "carol", ToUserID: "me", Amount: 20, Currency: "INR"},
+			{FromUserID: "dan", ToUserID: "me", Amount: 5, Currency: "INR"},
+			{FromUserID: "pat", ToUserID: "me", Amount: 10, Currency: "INR"},
+			{FromUserID: "me", ToUserID: "erin", Amount: 30, Currency: "INR"},
+		},
+		"sandbox": {{FromUserID: "erin", ToUserID: "me", Amount: 99, Currency: "INR"}},
+	}}
+	remindedAt := time.Now().Add(-time.Hour)
+	noReminders := models.DefaultGroupNotificationSettings("g1", "dan")
+	noReminders.Reminders = false
+	notifications := reminderNotificationRepo{
+		lastReminded: map[string]time.Time{"carol": remindedAt},
+		settings:     map[string]*models.GroupNotificationSettings{"dan": &noReminders},
+	}
+	dispatcher := &recordingDispatcher{}
+	users := &fakeUserRepo{users: map[string]*models.User{"me": {ID: "me", Name: "Me"}}}
+
+	s := NewReminderService(users, groups, notifications, noReminderResponses{}, settlements, dispatcher)
+	resp, err := s.RemindAllDebtors(context.Background(), "me")
+	if err != nil {
+		t.Fatalf("RemindAllDebtors() error = %v", err)
+	}
+
+	if len(resp.Reminded) != 1 || resp.Reminded[0].ID != "bob" || len(resp.Reminded[0].Debts) != 1 || resp.Reminded[0].Debts[0].Amount != 50 {
+		t.Errorf("Reminded = %+v, expected only bob's 50 INR", resp.Reminded)
+	}
+	expectedSkips := []struct {
+		id     string
+		reason models.ReminderSkipReason
+	}{
+		{"carol", models.ReminderSkipCooldown},
+		{"dan", models.ReminderSkipRemindersDisabled},
+		{"pat", models.ReminderSkipPlaceholder},
+	}
+	if len(resp.Skipped) != len(expectedSkips) {
+		t.Fatalf("Skipped = %+v, expected %d entries", resp.Skipped, len(expectedSkips))
+	}
+	for i, expected := range expectedSkips {
+		if got := resp.Skipped[i]; got.ID != expected.id || got.Reason != expected.reason {
+			t.Errorf("Skipped[%d] = %s (%s), expected %s (%s)", i, got.ID, got.Reason, expected.id, expected.reason)
+		}
+	}
+	if next := resp.Skipped[0].NextAllowedAt; next == nil || !next.Equal(remindedAt.Add(ReminderCooldown)) {
+		t.Errorf("carol's NextAllowedAt = %v, expected the end of the cooldown", next)
+	}
+
+	if len(dispatcher.payloads) != 1 || dispatcher.payloads[0].Recipients[0] != "bob" || dispatcher.payloads[0].GroupID != "g1" {
+		t.Errorf("dispatched = %+v, expected one reminder to bob in g1", dispatcher.payloads)
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+)
+
+// reportSettings loads the calendar userID's reports use, falling back to
+// the defaults (Monday weeks, calendar months) for users without a row.
+func reportSettings(ctx context.Context, userRepo repository.UserRepository, userID string) (models.ReportSettings, error) {
+	settings, err := userRepo.GetReportSettings(ctx, userID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return models.DefaultReportSettings(), nil
+		}
+		return models.ReportSettings{}, apperrors.DatabaseError("getting report settings", err)
+	}
+	return *settings, nil
+}
+
+// periodStart returns midnight UTC on the first day of the week or month
+// containing t, by the user's week start and fiscal month start day.
+func periodStart(settings models.ReportSettings, granularity string, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case "week":
+		first := time.Monday
+		if settings.WeekStart == models.WeekStartSunday {
+			first = time.Sunday
+		}
+		return day.AddDate(0, 0, -((int(day.Weekday()) - int(first) + 7) % 7))
+	case "month":
+		startDay := settings.FiscalMonthStartDay
+		if startDay < 1 {
+			startDay = 1
+		}
+		start := time.Date(t.Year(), t.Month(), startDay, 0, 0, 0, 0, time.UTC)
+		if day.Before(start) {
+			start = start.AddDate(0, -1, 0)
+		}
+		return start
+	default:
+		return day
+	}
+}
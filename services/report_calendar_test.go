@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestPeriodStart(t *testing.T) {
+	monday := models.DefaultReportSettings()
+	sunday := models.ReportSettings{WeekStart: models.WeekStartSunday, FiscalMonthStartDay: 1}
+	salary := models.ReportSettings{WeekStart: models.WeekStartMonday, FiscalMonthStartDay: 25}
+
+	tests := []struct {
+		name        string
+		settings    models.ReportSettings
+		granularity string
+		at          time.Time
+		want        string
+	}{
+		{name: "Monday week from Wednesday", settings: monday, granularity: "week", at: time.Date(2024, 5, 15, 22, 30, 0, 0, time.UTC), want: "2024-05-13"},
+		{name: "Monday week from Sunday", settings: monday, granularity: "week", at: time.Date(2024, 5, 19, 8, 0, 0, 0, time.UTC), want: "2024-05-13"},
+		{name: "Sunday week from Sunday", settings: sunday, granularity: "week", at: time.Date(2024, 5, 19, 8, 0, 0, 0, time.UTC), want: "2024-05-19"},
+		{name: "Sunday week from Saturday", settings: sunday, granularity: "week", at: time.Date(2024, 5, 18, 8, 0, 0, 0, time.UTC), want: "2024-05-12"},
+		{name: "Calendar month", settings: monday, granularity: "month", at: time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC), want: "2024-05-01"},
+		{name: "Fiscal month on its first day", settings: salary, granularity: "month", at: time.Date(2024, 5, 25, 0, 0, 0, 0, time.UTC), want: "2024-05-25"},
+		{name: "Fiscal month before the start day", settings: salary, granularity: "month", at: time.Date(2024, 5, 24, 12, 0, 0, 0, time.UTC), want: "2024-04-25"},
+		{name: "Fiscal month across the new year", settings: salary, granularity: "month", at: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), want: "2023-12-25"},
+		{name: "Other time zones use the UTC day", settings: monday, granularity: "day", at: time.Date(2024, 5, 1, 1, 0, 0, 0, time.FixedZone("IST", 5*3600+1800)), want: "2024-04-30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := periodStart(tt.settings, tt.granularity, tt.at).Format("2006-01-02"); got != tt.want {
+				t.Errorf("periodStart(%s) = %s, want %s", tt.granularity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodOffsetDays(t *testing.T) {
+	settings := models.ReportSettings{WeekStart: models.WeekStartSunday, FiscalMonthStartDay: 25}
+	for granularity, want := range map[string]int{"day": 0, "week": -1, "month": 24} {
+		if got := settings.PeriodOffsetDays(granularity); got != want {
+			t.Errorf("PeriodOffsetDays(%q) = %d, want %d", granularity, got, want)
+		}
+	}
+	if got := models.DefaultReportSettings().PeriodOffsetDays("month"); got != 0 {
+		t.Errorf("default PeriodOffsetDays(month) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,1125 @@
+// Code generated by scripts/stubgen; DO NOT EDIT.
+
+package services
+
+import (
+	"context"
+	"time"
+	"unwise-backend/database"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+)
+
+// stubAIAuditRepository implements repository.AIAuditRepository; every method is unstubbed.
+type stubAIAuditRepository struct{}
+
+func (s stubAIAuditRepository) CreateOutput(context.Context, *models.AIOutput) (r0 error) {
+	return errNotStubbed("AIAuditRepository.CreateOutput")
+}
+func (s stubAIAuditRepository) GetOutputByID(context.Context, string) (r0 *models.AIOutput, r1 error) {
+	return r0, errNotStubbed("AIAuditRepository.GetOutputByID")
+}
+func (s stubAIAuditRepository) GetLatestOutputForExpense(context.Context, string, models.AIOutputKind) (r0 *models.AIOutput, r1 error) {
+	return r0, errNotStubbed("AIAuditRepository.GetLatestOutputForExpense")
+}
+func (s stubAIAuditRepository) UpsertFeedback(context.Context, *models.AIFeedback) (r0 error) {
+	return errNotStubbed("AIAuditRepository.UpsertFeedback")
+}
+func (s stubAIAuditRepository) GetStats(context.Context) (r0 []models.AIOutputStats, r1 error) {
+	return r0, errNotStubbed("AIAuditRepository.GetStats")
+}
+func (s stubAIAuditRepository) WithTx(database.Querier) (r0 repository.AIAuditRepository) { return s }
+
+// stubActivityRepository implements repository.ActivityRepository; every method is unstubbed.
+type stubActivityRepository struct{}
+
+func (s stubActivityRepository) Create(context.Context, *models.GroupActivity) (r0 error) {
+	return errNotStubbed("ActivityRepository.Create")
+}
+func (s stubActivityRepository) GetByGroupID(context.Context, string, int) (r0 []models.GroupActivity, r1 error) {
+	return r0, errNotStubbed("ActivityRepository.GetByGroupID")
+}
+func (s stubActivityRepository) WithTx(database.Querier) (r0 repository.ActivityRepository) { return s }
+
+// stubAnnouncementRepository implements repository.AnnouncementRepository; every method is unstubbed.
+type stubAnnouncementRepository struct{}
+
+func (s stubAnnouncementRepository) Create(context.Context, *models.Announcement) (r0 error) {
+	return errNotStubbed("AnnouncementRepository.Create")
+}
+func (s stubAnnouncementRepository) GetAll(context.Context) (r0 []models.Announcement, r1 error) {
+	return r0, errNotStubbed("AnnouncementRepository.GetAll")
+}
+func (s stubAnnouncementRepository) GetActiveForUser(context.Context, string) (r0 []models.Announcement, r1 error) {
+	return r0, errNotStubbed("AnnouncementRepository.GetActiveForUser")
+}
+func (s stubAnnouncementRepository) Delete(context.Context, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("AnnouncementRepository.Delete")
+}
+func (s stubAnnouncementRepository) MarkRead(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("AnnouncementRepository.MarkRead")
+}
+func (s stubAnnouncementRepository) MarkAllRead(context.Context, string) (r0 error) {
+	return errNotStubbed("AnnouncementRepository.MarkAllRead")
+}
+func (s stubAnnouncementRepository) WithTx(database.Querier) (r0 repository.AnnouncementRepository) {
+	return s
+}
+
+// stubBalanceAlertRepository implements repository.BalanceAlertRepository; every method is unstubbed.
+type stubBalanceAlertRepository struct{}
+
+func (s stubBalanceAlertRepository) GetSubscribers(context.Context, string) (r0 []models.BalanceAlertSubscriber, r1 error) {
+	return r0, errNotStubbed("BalanceAlertRepository.GetSubscribers")
+}
+func (s stubBalanceAlertRepository) MarkAlerted(context.Context, string, string, float64) (r0 bool, r1 error) {
+	return r0, errNotStubbed("BalanceAlertRepository.MarkAlerted")
+}
+func (s stubBalanceAlertRepository) Clear(context.Context, string, string) (r0 error) {
+	return errNotStubbed("BalanceAlertRepository.Clear")
+}
+func (s stubBalanceAlertRepository) WithTx(database.Querier) (r0 repository.BalanceAlertRepository) {
+	return s
+}
+
+// stubBalanceEventRepository implements repository.BalanceEventRepository; every method is unstubbed.
+type stubBalanceEventRepository struct{}
+
+func (s stubBalanceEventRepository) GetExpenseContributions(context.Context, string) (r0 []models.BalanceEvent, r1 error) {
+	return r0, errNotStubbed("BalanceEventRepository.GetExpenseContributions")
+}
+func (s stubBalanceEventRepository) Append(context.Context, []models.BalanceEvent) (r0 error) {
+	return errNotStubbed("BalanceEventRepository.Append")
+}
+func (s stubBalanceEventRepository) GetByMember(context.Context, string, string) (r0 []models.BalanceEvent, r1 error) {
+	return r0, errNotStubbed("BalanceEventRepository.GetByMember")
+}
+func (s stubBalanceEventRepository) GetGroupTotals(context.Context, string) (r0 map[string]map[string]float64, r1 error) {
+	return r0, errNotStubbed("BalanceEventRepository.GetGroupTotals")
+}
+func (s stubBalanceEventRepository) TransferUser(context.Context, string, string) (r0 error) {
+	return errNotStubbed("BalanceEventRepository.TransferUser")
+}
+func (s stubBalanceEventRepository) TransferGroupUser(context.Context, string, string, string) (r0 error) {
+	return errNotStubbed("BalanceEventRepository.TransferGroupUser")
+}
+func (s stubBalanceEventRepository) WithTx(database.Querier) (r0 repository.BalanceEventRepository) {
+	return s
+}
+
+// stubBalanceMetricsRepository implements repository.BalanceMetricsRepository; every method is unstubbed.
+type stubBalanceMetricsRepository struct{}
+
+func (s stubBalanceMetricsRepository) TryVerifyLock(context.Context) (r0 bool, r1 error) {
+	return r0, errNotStubbed("BalanceMetricsRepository.TryVerifyLock")
+}
+func (s stubBalanceMetricsRepository) FindDrift(context.Context) (r0 []models.BalanceMetricDrift, r1 error) {
+	return r0, errNotStubbed("BalanceMetricsRepository.FindDrift")
+}
+func (s stubBalanceMetricsRepository) Adjust(context.Context, string, string, string, float64) (r0 error) {
+	return errNotStubbed("BalanceMetricsRepository.Adjust")
+}
+func (s stubBalanceMetricsRepository) WithTx(database.Querier) (r0 repository.BalanceMetricsRepository) {
+	return s
+}
+
+// stubBalanceQueries implements repository.BalanceQueries; every method is unstubbed.
+type stubBalanceQueries struct{}
+
+func (s stubBalanceQueries) GetUserBalanceInGroup(context.Context, string, string) (r0 float64, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetUserBalanceInGroup")
+}
+func (s stubBalanceQueries) GetUserTotalBalance(context.Context, string) (r0 []models.CurrencyAmount, r1 []models.CurrencyAmount, r2 []models.CurrencyAmount, r3 error) {
+	return r0, r1, r2, errNotStubbed("BalanceQueries.GetUserTotalBalance")
+}
+func (s stubBalanceQueries) GetGroupBalancesByUserID(context.Context, string, []string) (r0 map[string]float64, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetGroupBalancesByUserID")
+}
+func (s stubBalanceQueries) GetGroupMemberBalances(context.Context, string, *time.Time) (r0 map[string]map[string]float64, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetGroupMemberBalances")
+}
+func (s stubBalanceQueries) GetGroupTotalSpend(context.Context, string) (r0 float64, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetGroupTotalSpend")
+}
+func (s stubBalanceQueries) GetGroupSpendByCurrency(context.Context, string) (r0 []models.CurrencySpend, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetGroupSpendByCurrency")
+}
+func (s stubBalanceQueries) GetPairwiseBalances(context.Context, string, string, []string) (r0 map[string]float64, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetPairwiseBalances")
+}
+func (s stubBalanceQueries) GetPairwiseBalancesAllFriends(context.Context, string) (r0 map[string]map[string]float64, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetPairwiseBalancesAllFriends")
+}
+func (s stubBalanceQueries) GetPairLedgers(context.Context, string, string, []string) (r0 []models.PairLedger, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetPairLedgers")
+}
+func (s stubBalanceQueries) GetGroupPairBalances(context.Context, string, *time.Time) (r0 []models.PairBalance, r1 error) {
+	return r0, errNotStubbed("BalanceQueries.GetGroupPairBalances")
+}
+
+// stubCommentRepository implements repository.CommentRepository; every method is unstubbed.
+type stubCommentRepository struct{}
+
+func (s stubCommentRepository) CreateComment(context.Context, *models.Comment) (r0 error) {
+	return errNotStubbed("CommentRepository.CreateComment")
+}
+func (s stubCommentRepository) GetCommentsByExpenseID(context.Context, string) (r0 []models.Comment, r1 error) {
+	return r0, errNotStubbed("CommentRepository.GetCommentsByExpenseID")
+}
+func (s stubCommentRepository) DeleteComment(context.Context, string) (r0 error) {
+	return errNotStubbed("CommentRepository.DeleteComment")
+}
+func (s stubCommentRepository) AddReaction(context.Context, *models.CommentReaction) (r0 error) {
+	return errNotStubbed("CommentRepository.AddReaction")
+}
+func (s stubCommentRepository) RemoveReaction(context.Context, string, string, string) (r0 error) {
+	return errNotStubbed("CommentRepository.RemoveReaction")
+}
+func (s stubCommentRepository) GetCommentByID(context.Context, string) (r0 *models.Comment, r1 error) {
+	return r0, errNotStubbed("CommentRepository.GetCommentByID")
+}
+func (s stubCommentRepository) WithTx(database.Querier) (r0 repository.CommentRepository) { return s }
+
+// stubCredentialRepository implements repository.CredentialRepository; every method is unstubbed.
+type stubCredentialRepository struct{}
+
+func (s stubCredentialRepository) GetPasswordHash(context.Context, string) (r0 string, r1 error) {
+	return r0, errNotStubbed("CredentialRepository.GetPasswordHash")
+}
+func (s stubCredentialRepository) SetPasswordHash(context.Context, string, string) (r0 error) {
+	return errNotStubbed("CredentialRepository.SetPasswordHash")
+}
+func (s stubCredentialRepository) WithTx(database.Querier) (r0 repository.CredentialRepository) {
+	return s
+}
+
+// stubCurrencyRepository implements repository.CurrencyRepository; every method is unstubbed.
+type stubCurrencyRepository struct{}
+
+func (s stubCurrencyRepository) GetAll(context.Context) (r0 []models.Currency, r1 error) {
+	return r0, errNotStubbed("CurrencyRepository.GetAll")
+}
+func (s stubCurrencyRepository) GetByCode(context.Context, string) (r0 *models.Currency, r1 error) {
+	return r0, errNotStubbed("CurrencyRepository.GetByCode")
+}
+func (s stubCurrencyRepository) WithTx(database.Querier) (r0 repository.CurrencyRepository) { return s }
+
+// stubEventRepository implements repository.EventRepository; every method is unstubbed.
+type stubEventRepository struct{}
+
+func (s stubEventRepository) Create(context.Context, *models.GroupEvent) (r0 error) {
+	return errNotStubbed("EventRepository.Create")
+}
+func (s stubEventRepository) GetByID(context.Context, string) (r0 *models.GroupEvent, r1 error) {
+	return r0, errNotStubbed("EventRepository.GetByID")
+}
+func (s stubEventRepository) GetByGroupID(context.Context, string) (r0 []models.GroupEvent, r1 error) {
+	return r0, errNotStubbed("EventRepository.GetByGroupID")
+}
+func (s stubEventRepository) GetTotalsByGroupID(context.Context, string) (r0 []models.EventTotal, r1 error) {
+	return r0, errNotStubbed("EventRepository.GetTotalsByGroupID")
+}
+func (s stubEventRepository) GetMemberShares(context.Context, string) (r0 []models.EventMemberShare, r1 error) {
+	return r0, errNotStubbed("EventRepository.GetMemberShares")
+}
+func (s stubEventRepository) Delete(context.Context, string) (r0 error) {
+	return errNotStubbed("EventRepository.Delete")
+}
+func (s stubEventRepository) WithTx(database.Querier) (r0 repository.EventRepository) { return s }
+
+// stubExpenseChangeRepository implements repository.ExpenseChangeRepository; every method is unstubbed.
+type stubExpenseChangeRepository struct{}
+
+func (s stubExpenseChangeRepository) Create(context.Context, *models.PendingExpenseChange) (r0 error) {
+	return errNotStubbed("ExpenseChangeRepository.Create")
+}
+func (s stubExpenseChangeRepository) GetByIDForUpdate(context.Context, string) (r0 *models.PendingExpenseChange, r1 error) {
+	return r0, errNotStubbed("ExpenseChangeRepository.GetByIDForUpdate")
+}
+func (s stubExpenseChangeRepository) GetAwaitingUser(context.Context, string) (r0 []models.PendingExpenseChange, r1 error) {
+	return r0, errNotStubbed("ExpenseChangeRepository.GetAwaitingUser")
+}
+func (s stubExpenseChangeRepository) Respond(context.Context, string, string, models.ExpenseChangeConfirmationStatus) (r0 error) {
+	return errNotStubbed("ExpenseChangeRepository.Respond")
+}
+func (s stubExpenseChangeRepository) Resolve(context.Context, string, models.ExpenseChangeStatus) (r0 bool, r1 error) {
+	return r0, errNotStubbed("ExpenseChangeRepository.Resolve")
+}
+func (s stubExpenseChangeRepository) SupersedePending(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseChangeRepository.SupersedePending")
+}
+func (s stubExpenseChangeRepository) WithTx(database.Querier) (r0 repository.ExpenseChangeRepository) {
+	return s
+}
+
+// stubExpenseReader implements repository.ExpenseReader; every method is unstubbed.
+type stubExpenseReader struct{}
+
+func (s stubExpenseReader) GetByID(context.Context, string) (r0 *models.Expense, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetByID")
+}
+func (s stubExpenseReader) GetByGroupID(context.Context, string) (r0 []models.Expense, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetByGroupID")
+}
+func (s stubExpenseReader) GetTransactionsByGroupID(context.Context, string, models.TransactionSort) (r0 []models.Transaction, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetTransactionsByGroupID")
+}
+func (s stubExpenseReader) GetRecentTransactionsForUser(context.Context, string, int) (r0 []models.Expense, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetRecentTransactionsForUser")
+}
+func (s stubExpenseReader) GetDashboardVersion(context.Context, string) (r0 string, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetDashboardVersion")
+}
+func (s stubExpenseReader) GetSplits(context.Context, string) (r0 []models.ExpenseSplit, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetSplits")
+}
+func (s stubExpenseReader) GetPayers(context.Context, string) (r0 []models.ExpensePayer, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetPayers")
+}
+func (s stubExpenseReader) GetSplitsByExpenseIDs(context.Context, []string) (r0 map[string][]models.ExpenseSplit, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetSplitsByExpenseIDs")
+}
+func (s stubExpenseReader) GetPayersByExpenseIDs(context.Context, []string) (r0 map[string][]models.ExpensePayer, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetPayersByExpenseIDs")
+}
+func (s stubExpenseReader) GetRefundedAmount(context.Context, string) (r0 float64, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetRefundedAmount")
+}
+func (s stubExpenseReader) HasSettledExpense(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.HasSettledExpense")
+}
+func (s stubExpenseReader) GetSharedTransactions(context.Context, string, string, []string) (r0 []models.SharedTransaction, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetSharedTransactions")
+}
+func (s stubExpenseReader) GetSharedBalanceChanges(context.Context, string, string, []string, string, int) (r0 []models.BalanceHistoryBucket, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetSharedBalanceChanges")
+}
+func (s stubExpenseReader) CountGroupExpensesSince(context.Context, string, time.Time) (r0 int, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.CountGroupExpensesSince")
+}
+func (s stubExpenseReader) GetGroupSpendingBetween(context.Context, string, time.Time, time.Time) (r0 []models.Expense, r1 error) {
+	return r0, errNotStubbed("ExpenseReader.GetGroupSpendingBetween")
+}
+
+// stubExpenseRepository implements repository.ExpenseRepository; every method is unstubbed.
+type stubExpenseRepository struct{}
+
+func (s stubExpenseRepository) GetByID(context.Context, string) (r0 *models.Expense, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetByID")
+}
+func (s stubExpenseRepository) GetByGroupID(context.Context, string) (r0 []models.Expense, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetByGroupID")
+}
+func (s stubExpenseRepository) GetTransactionsByGroupID(context.Context, string, models.TransactionSort) (r0 []models.Transaction, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetTransactionsByGroupID")
+}
+func (s stubExpenseRepository) GetRecentTransactionsForUser(context.Context, string, int) (r0 []models.Expense, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetRecentTransactionsForUser")
+}
+func (s stubExpenseRepository) GetDashboardVersion(context.Context, string) (r0 string, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetDashboardVersion")
+}
+func (s stubExpenseRepository) GetSplits(context.Context, string) (r0 []models.ExpenseSplit, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetSplits")
+}
+func (s stubExpenseRepository) GetPayers(context.Context, string) (r0 []models.ExpensePayer, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetPayers")
+}
+func (s stubExpenseRepository) GetSplitsByExpenseIDs(context.Context, []string) (r0 map[string][]models.ExpenseSplit, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetSplitsByExpenseIDs")
+}
+func (s stubExpenseRepository) GetPayersByExpenseIDs(context.Context, []string) (r0 map[string][]models.ExpensePayer, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetPayersByExpenseIDs")
+}
+func (s stubExpenseRepository) GetRefundedAmount(context.Context, string) (r0 float64, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetRefundedAmount")
+}
+func (s stubExpenseRepository) HasSettledExpense(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.HasSettledExpense")
+}
+func (s stubExpenseRepository) GetSharedTransactions(context.Context, string, string, []string) (r0 []models.SharedTransaction, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetSharedTransactions")
+}
+func (s stubExpenseRepository) GetSharedBalanceChanges(context.Context, string, string, []string, string, int) (r0 []models.BalanceHistoryBucket, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetSharedBalanceChanges")
+}
+func (s stubExpenseRepository) CountGroupExpensesSince(context.Context, string, time.Time) (r0 int, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.CountGroupExpensesSince")
+}
+func (s stubExpenseRepository) GetGroupSpendingBetween(context.Context, string, time.Time, time.Time) (r0 []models.Expense, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetGroupSpendingBetween")
+}
+func (s stubExpenseRepository) Create(context.Context, *models.Expense) (r0 error) {
+	return errNotStubbed("ExpenseRepository.Create")
+}
+func (s stubExpenseRepository) Update(context.Context, *models.Expense) (r0 error) {
+	return errNotStubbed("ExpenseRepository.Update")
+}
+func (s stubExpenseRepository) LockForUpdate(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.LockForUpdate")
+}
+func (s stubExpenseRepository) UpdateExplanation(context.Context, string, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.UpdateExplanation")
+}
+func (s stubExpenseRepository) ClearExplanation(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.ClearExplanation")
+}
+func (s stubExpenseRepository) ClearGroupExplanations(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.ClearGroupExplanations")
+}
+func (s stubExpenseRepository) SetSplitsLocked(context.Context, string, bool) (r0 error) {
+	return errNotStubbed("ExpenseRepository.SetSplitsLocked")
+}
+func (s stubExpenseRepository) Delete(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.Delete")
+}
+func (s stubExpenseRepository) TransferExpenses(context.Context, string, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.TransferExpenses")
+}
+func (s stubExpenseRepository) TransferGroupExpenses(context.Context, string, string, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.TransferGroupExpenses")
+}
+func (s stubExpenseRepository) CreateSplit(context.Context, *models.ExpenseSplit) (r0 error) {
+	return errNotStubbed("ExpenseRepository.CreateSplit")
+}
+func (s stubExpenseRepository) DeleteSplits(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.DeleteSplits")
+}
+func (s stubExpenseRepository) SetSplitExclusion(context.Context, string, string, *models.SplitExclusionStatus, *string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.SetSplitExclusion")
+}
+func (s stubExpenseRepository) ExcludeFromSplit(context.Context, string, string, []models.ExpenseSplit) (r0 error) {
+	return errNotStubbed("ExpenseRepository.ExcludeFromSplit")
+}
+func (s stubExpenseRepository) CreatePayer(context.Context, *models.ExpensePayer) (r0 error) {
+	return errNotStubbed("ExpenseRepository.CreatePayer")
+}
+func (s stubExpenseRepository) DeletePayers(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.DeletePayers")
+}
+func (s stubExpenseRepository) GetUserBalanceInGroup(context.Context, string, string) (r0 float64, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetUserBalanceInGroup")
+}
+func (s stubExpenseRepository) GetUserTotalBalance(context.Context, string) (r0 []models.CurrencyAmount, r1 []models.CurrencyAmount, r2 []models.CurrencyAmount, r3 error) {
+	return r0, r1, r2, errNotStubbed("ExpenseRepository.GetUserTotalBalance")
+}
+func (s stubExpenseRepository) GetGroupBalancesByUserID(context.Context, string, []string) (r0 map[string]float64, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetGroupBalancesByUserID")
+}
+func (s stubExpenseRepository) GetGroupMemberBalances(context.Context, string, *time.Time) (r0 map[string]map[string]float64, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetGroupMemberBalances")
+}
+func (s stubExpenseRepository) GetGroupTotalSpend(context.Context, string) (r0 float64, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetGroupTotalSpend")
+}
+func (s stubExpenseRepository) GetGroupSpendByCurrency(context.Context, string) (r0 []models.CurrencySpend, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetGroupSpendByCurrency")
+}
+func (s stubExpenseRepository) GetPairwiseBalances(context.Context, string, string, []string) (r0 map[string]float64, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetPairwiseBalances")
+}
+func (s stubExpenseRepository) GetPairwiseBalancesAllFriends(context.Context, string) (r0 map[string]map[string]float64, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetPairwiseBalancesAllFriends")
+}
+func (s stubExpenseRepository) GetPairLedgers(context.Context, string, string, []string) (r0 []models.PairLedger, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetPairLedgers")
+}
+func (s stubExpenseRepository) GetGroupPairBalances(context.Context, string, *time.Time) (r0 []models.PairBalance, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetGroupPairBalances")
+}
+func (s stubExpenseRepository) GetReceiptItems(context.Context, string) (r0 []models.ReceiptItem, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetReceiptItems")
+}
+func (s stubExpenseRepository) GetReceiptItemsByExpenseIDs(context.Context, []string) (r0 map[string][]models.ReceiptItem, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetReceiptItemsByExpenseIDs")
+}
+func (s stubExpenseRepository) CreateReceiptItem(context.Context, *models.ReceiptItem) (r0 error) {
+	return errNotStubbed("ExpenseRepository.CreateReceiptItem")
+}
+func (s stubExpenseRepository) GetReceiptItemAssignments(context.Context, string) (r0 []models.ReceiptItemAssignment, r1 error) {
+	return r0, errNotStubbed("ExpenseRepository.GetReceiptItemAssignments")
+}
+func (s stubExpenseRepository) CreateReceiptItemAssignment(context.Context, *models.ReceiptItemAssignment) (r0 error) {
+	return errNotStubbed("ExpenseRepository.CreateReceiptItemAssignment")
+}
+func (s stubExpenseRepository) DeleteReceiptItems(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseRepository.DeleteReceiptItems")
+}
+func (s stubExpenseRepository) GetReceiptsByGroupID(context.Context, string, int, int) (r0 []models.ReceiptGalleryItem, r1 int, r2 error) {
+	return r0, r1, errNotStubbed("ExpenseRepository.GetReceiptsByGroupID")
+}
+func (s stubExpenseRepository) WithTx(database.Querier) (r0 repository.ExpenseRepository) { return s }
+
+// stubExpenseWriter implements repository.ExpenseWriter; every method is unstubbed.
+type stubExpenseWriter struct{}
+
+func (s stubExpenseWriter) Create(context.Context, *models.Expense) (r0 error) {
+	return errNotStubbed("ExpenseWriter.Create")
+}
+func (s stubExpenseWriter) Update(context.Context, *models.Expense) (r0 error) {
+	return errNotStubbed("ExpenseWriter.Update")
+}
+func (s stubExpenseWriter) LockForUpdate(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseWriter.LockForUpdate")
+}
+func (s stubExpenseWriter) UpdateExplanation(context.Context, string, string) (r0 error) {
+	return errNotStubbed("ExpenseWriter.UpdateExplanation")
+}
+func (s stubExpenseWriter) ClearExplanation(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseWriter.ClearExplanation")
+}
+func (s stubExpenseWriter) ClearGroupExplanations(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseWriter.ClearGroupExplanations")
+}
+func (s stubExpenseWriter) SetSplitsLocked(context.Context, string, bool) (r0 error) {
+	return errNotStubbed("ExpenseWriter.SetSplitsLocked")
+}
+func (s stubExpenseWriter) Delete(context.Context, string) (r0 error) {
+	return errNotStubbed("ExpenseWriter.Delete")
+}
+func (s stubExpenseWriter) TransferExpenses(context.Context, string, string) (r0 error) {
+	return errNotStubbed("ExpenseWriter.TransferExpenses")
+}
+func (s stubExpenseWriter) TransferGroupExpenses(context.Context, string, string, string) (r0 error) {
+	return errNotStubbed("ExpenseWriter.TransferGroupExpenses")
+}
+
+// stubFriendRepository implements repository.FriendRepository; every method is unstubbed.
+type stubFriendRepository struct{}
+
+func (s stubFriendRepository) Add(context.Context, string, string) (r0 error) {
+	return errNotStubbed("FriendRepository.Add")
+}
+func (s stubFriendRepository) Remove(context.Context, string, string) (r0 error) {
+	return errNotStubbed("FriendRepository.Remove")
+}
+func (s stubFriendRepository) List(context.Context, string) (r0 []models.User, r1 error) {
+	return r0, errNotStubbed("FriendRepository.List")
+}
+func (s stubFriendRepository) IsFriend(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("FriendRepository.IsFriend")
+}
+func (s stubFriendRepository) WithTx(database.Querier) (r0 repository.FriendRepository) { return s }
+
+// stubGroupArchiveRepository implements repository.GroupArchiveRepository; every method is unstubbed.
+type stubGroupArchiveRepository struct{}
+
+func (s stubGroupArchiveRepository) Archive(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupArchiveRepository.Archive")
+}
+func (s stubGroupArchiveRepository) Unarchive(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupArchiveRepository.Unarchive")
+}
+func (s stubGroupArchiveRepository) DismissSuggestion(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupArchiveRepository.DismissSuggestion")
+}
+func (s stubGroupArchiveRepository) GetArchivedGroupIDs(context.Context, string) (r0 map[string]bool, r1 error) {
+	return r0, errNotStubbed("GroupArchiveRepository.GetArchivedGroupIDs")
+}
+func (s stubGroupArchiveRepository) GetSuggestions(context.Context, string, time.Time) (r0 []models.ArchiveSuggestion, r1 error) {
+	return r0, errNotStubbed("GroupArchiveRepository.GetSuggestions")
+}
+func (s stubGroupArchiveRepository) WithTx(database.Querier) (r0 repository.GroupArchiveRepository) {
+	return s
+}
+
+// stubGroupInviteRepository implements repository.GroupInviteRepository; every method is unstubbed.
+type stubGroupInviteRepository struct{}
+
+func (s stubGroupInviteRepository) Create(context.Context, string, string, string) (r0 *models.GroupInvite, r1 error) {
+	return r0, errNotStubbed("GroupInviteRepository.Create")
+}
+func (s stubGroupInviteRepository) GetPendingByEmailForUpdate(context.Context, string) (r0 []models.GroupInvite, r1 error) {
+	return r0, errNotStubbed("GroupInviteRepository.GetPendingByEmailForUpdate")
+}
+func (s stubGroupInviteRepository) MarkAccepted(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupInviteRepository.MarkAccepted")
+}
+func (s stubGroupInviteRepository) WithTx(database.Querier) (r0 repository.GroupInviteRepository) {
+	return s
+}
+
+// stubGroupRepository implements repository.GroupRepository; every method is unstubbed.
+type stubGroupRepository struct{}
+
+func (s stubGroupRepository) GetByID(context.Context, string) (r0 *models.Group, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetByID")
+}
+func (s stubGroupRepository) GetByUserID(context.Context, string) (r0 []models.Group, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetByUserID")
+}
+func (s stubGroupRepository) GetGroupsWithLastActivity(context.Context, string) (r0 []models.DashboardGroup, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetGroupsWithLastActivity")
+}
+func (s stubGroupRepository) Create(context.Context, *models.Group) (r0 error) {
+	return errNotStubbed("GroupRepository.Create")
+}
+func (s stubGroupRepository) Update(context.Context, *models.Group) (r0 error) {
+	return errNotStubbed("GroupRepository.Update")
+}
+func (s stubGroupRepository) UpdateAvatarURL(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupRepository.UpdateAvatarURL")
+}
+func (s stubGroupRepository) UpdateDefaultCurrency(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupRepository.UpdateDefaultCurrency")
+}
+func (s stubGroupRepository) GetLimits(context.Context, string) (r0 *models.GroupLimits, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetLimits")
+}
+func (s stubGroupRepository) UpdateLimits(context.Context, string, *models.GroupLimits) (r0 error) {
+	return errNotStubbed("GroupRepository.UpdateLimits")
+}
+func (s stubGroupRepository) GetExpenseEditPolicy(context.Context, string) (r0 models.ExpenseEditPolicy, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetExpenseEditPolicy")
+}
+func (s stubGroupRepository) UpdateExpenseEditPolicy(context.Context, string, models.ExpenseEditPolicy) (r0 error) {
+	return errNotStubbed("GroupRepository.UpdateExpenseEditPolicy")
+}
+func (s stubGroupRepository) GetSettlementRounding(context.Context, string) (r0 int, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetSettlementRounding")
+}
+func (s stubGroupRepository) UpdateSettlementRounding(context.Context, string, int) (r0 error) {
+	return errNotStubbed("GroupRepository.UpdateSettlementRounding")
+}
+func (s stubGroupRepository) GetSettlementAlgorithm(context.Context, string) (r0 models.SettlementAlgorithm, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetSettlementAlgorithm")
+}
+func (s stubGroupRepository) UpdateSettlementAlgorithm(context.Context, string, models.SettlementAlgorithm) (r0 error) {
+	return errNotStubbed("GroupRepository.UpdateSettlementAlgorithm")
+}
+func (s stubGroupRepository) GetDefaultLanguage(context.Context, string) (r0 string, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetDefaultLanguage")
+}
+func (s stubGroupRepository) UpdateDefaultLanguage(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupRepository.UpdateDefaultLanguage")
+}
+func (s stubGroupRepository) GetTaxPreset(context.Context, string) (r0 models.TaxPreset, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetTaxPreset")
+}
+func (s stubGroupRepository) IsSandbox(context.Context, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("GroupRepository.IsSandbox")
+}
+func (s stubGroupRepository) UpdateTaxPreset(context.Context, string, models.TaxPreset) (r0 error) {
+	return errNotStubbed("GroupRepository.UpdateTaxPreset")
+}
+func (s stubGroupRepository) GetUsage(context.Context, string, time.Time, time.Time) (r0 *models.GroupUsage, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetUsage")
+}
+func (s stubGroupRepository) AddRecurringStub(context.Context, *models.RecurringExpenseStub) (r0 error) {
+	return errNotStubbed("GroupRepository.AddRecurringStub")
+}
+func (s stubGroupRepository) GetRecurringStubs(context.Context, string) (r0 []models.RecurringExpenseStub, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetRecurringStubs")
+}
+func (s stubGroupRepository) Delete(context.Context, string) (r0 error) {
+	return errNotStubbed("GroupRepository.Delete")
+}
+func (s stubGroupRepository) LockForShare(context.Context, string) (r0 error) {
+	return errNotStubbed("GroupRepository.LockForShare")
+}
+func (s stubGroupRepository) LockForUpdate(context.Context, string) (r0 error) {
+	return errNotStubbed("GroupRepository.LockForUpdate")
+}
+func (s stubGroupRepository) AddMember(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupRepository.AddMember")
+}
+func (s stubGroupRepository) RemoveMember(context.Context, string, string) (r0 error) {
+	return errNotStubbed("GroupRepository.RemoveMember")
+}
+func (s stubGroupRepository) GetMembers(context.Context, string) (r0 []models.User, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetMembers")
+}
+func (s stubGroupRepository) IsMember(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("GroupRepository.IsMember")
+}
+func (s stubGroupRepository) GetCommonGroups(context.Context, string, string) (r0 []models.Group, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetCommonGroups")
+}
+func (s stubGroupRepository) GetGroupsDetailedByUserID(context.Context, string) (r0 []models.Group, r1 error) {
+	return r0, errNotStubbed("GroupRepository.GetGroupsDetailedByUserID")
+}
+func (s stubGroupRepository) WithTx(database.Querier) (r0 repository.GroupRepository) { return s }
+
+// stubGroupShareLinkRepository implements repository.GroupShareLinkRepository; every method is unstubbed.
+type stubGroupShareLinkRepository struct{}
+
+func (s stubGroupShareLinkRepository) Create(context.Context, *models.GroupShareLink, string) (r0 error) {
+	return errNotStubbed("GroupShareLinkRepository.Create")
+}
+func (s stubGroupShareLinkRepository) GetByID(context.Context, string) (r0 *models.GroupShareLink, r1 error) {
+	return r0, errNotStubbed("GroupShareLinkRepository.GetByID")
+}
+func (s stubGroupShareLinkRepository) GetActiveByGroupID(context.Context, string) (r0 []models.GroupShareLink, r1 error) {
+	return r0, errNotStubbed("GroupShareLinkRepository.GetActiveByGroupID")
+}
+func (s stubGroupShareLinkRepository) CountActive(context.Context, string) (r0 int, r1 error) {
+	return r0, errNotStubbed("GroupShareLinkRepository.CountActive")
+}
+func (s stubGroupShareLinkRepository) Revoke(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("GroupShareLinkRepository.Revoke")
+}
+func (s stubGroupShareLinkRepository) ResolveToken(context.Context, string) (r0 string, r1 error) {
+	return r0, errNotStubbed("GroupShareLinkRepository.ResolveToken")
+}
+func (s stubGroupShareLinkRepository) WithTx(database.Querier) (r0 repository.GroupShareLinkRepository) {
+	return s
+}
+
+// stubImportRepository implements repository.ImportRepository; every method is unstubbed.
+type stubImportRepository struct{}
+
+func (s stubImportRepository) GetMemberMappings(context.Context, string) (r0 map[string]string, r1 error) {
+	return r0, errNotStubbed("ImportRepository.GetMemberMappings")
+}
+func (s stubImportRepository) SaveMemberMappings(context.Context, string, map[string]string) (r0 error) {
+	return errNotStubbed("ImportRepository.SaveMemberMappings")
+}
+func (s stubImportRepository) GetFingerprints(context.Context, string, []string) (r0 map[string]string, r1 error) {
+	return r0, errNotStubbed("ImportRepository.GetFingerprints")
+}
+func (s stubImportRepository) AddFingerprint(context.Context, string, string, string) (r0 error) {
+	return errNotStubbed("ImportRepository.AddFingerprint")
+}
+func (s stubImportRepository) WithTx(database.Querier) (r0 repository.ImportRepository) { return s }
+
+// stubIntegrationRepository implements repository.IntegrationRepository; every method is unstubbed.
+type stubIntegrationRepository struct{}
+
+func (s stubIntegrationRepository) GetByGroupID(context.Context, string) (r0 []models.GroupIntegration, r1 error) {
+	return r0, errNotStubbed("IntegrationRepository.GetByGroupID")
+}
+func (s stubIntegrationRepository) GetByID(context.Context, string) (r0 *models.GroupIntegration, r1 error) {
+	return r0, errNotStubbed("IntegrationRepository.GetByID")
+}
+func (s stubIntegrationRepository) Create(context.Context, *models.GroupIntegration) (r0 error) {
+	return errNotStubbed("IntegrationRepository.Create")
+}
+func (s stubIntegrationRepository) Update(context.Context, *models.GroupIntegration) (r0 error) {
+	return errNotStubbed("IntegrationRepository.Update")
+}
+func (s stubIntegrationRepository) Delete(context.Context, string) (r0 error) {
+	return errNotStubbed("IntegrationRepository.Delete")
+}
+func (s stubIntegrationRepository) EnqueueDelivery(context.Context, *models.IntegrationDelivery) (r0 error) {
+	return errNotStubbed("IntegrationRepository.EnqueueDelivery")
+}
+func (s stubIntegrationRepository) ClaimDueDeliveries(context.Context, int, time.Duration) (r0 []models.IntegrationDelivery, r1 error) {
+	return r0, errNotStubbed("IntegrationRepository.ClaimDueDeliveries")
+}
+func (s stubIntegrationRepository) MarkDeliverySent(context.Context, string) (r0 error) {
+	return errNotStubbed("IntegrationRepository.MarkDeliverySent")
+}
+func (s stubIntegrationRepository) MarkDeliveryFailed(context.Context, string, string, *time.Time) (r0 error) {
+	return errNotStubbed("IntegrationRepository.MarkDeliveryFailed")
+}
+func (s stubIntegrationRepository) GetRecentDeliveries(context.Context, string, int) (r0 []models.IntegrationDelivery, r1 error) {
+	return r0, errNotStubbed("IntegrationRepository.GetRecentDeliveries")
+}
+func (s stubIntegrationRepository) WithTx(database.Querier) (r0 repository.IntegrationRepository) {
+	return s
+}
+
+// stubIntegrityRepository implements repository.IntegrityRepository; every method is unstubbed.
+type stubIntegrityRepository struct{}
+
+func (s stubIntegrityRepository) CountOrphans(context.Context) (r0 []models.OrphanCheck, r1 error) {
+	return r0, errNotStubbed("IntegrityRepository.CountOrphans")
+}
+func (s stubIntegrityRepository) FindOrphanedPlaceholders(context.Context) (r0 []models.User, r1 error) {
+	return r0, errNotStubbed("IntegrityRepository.FindOrphanedPlaceholders")
+}
+func (s stubIntegrityRepository) DeleteOrphanedPlaceholders(context.Context, []string) (r0 int64, r1 error) {
+	return r0, errNotStubbed("IntegrityRepository.DeleteOrphanedPlaceholders")
+}
+func (s stubIntegrityRepository) GetExpenseTotals(context.Context, string) (r0 []models.UnbalancedExpense, r1 error) {
+	return r0, errNotStubbed("IntegrityRepository.GetExpenseTotals")
+}
+func (s stubIntegrityRepository) WithTx(database.Querier) (r0 repository.IntegrityRepository) {
+	return s
+}
+
+// stubJobRepository implements repository.JobRepository; every method is unstubbed.
+type stubJobRepository struct{}
+
+func (s stubJobRepository) Enqueue(context.Context, *models.Job) (r0 error) {
+	return errNotStubbed("JobRepository.Enqueue")
+}
+func (s stubJobRepository) ClaimDue(context.Context, int, time.Duration) (r0 []models.Job, r1 error) {
+	return r0, errNotStubbed("JobRepository.ClaimDue")
+}
+func (s stubJobRepository) MarkSucceeded(context.Context, string) (r0 error) {
+	return errNotStubbed("JobRepository.MarkSucceeded")
+}
+func (s stubJobRepository) MarkFailed(context.Context, string, string, *time.Time) (r0 error) {
+	return errNotStubbed("JobRepository.MarkFailed")
+}
+func (s stubJobRepository) GetDead(context.Context, int) (r0 []models.Job, r1 error) {
+	return r0, errNotStubbed("JobRepository.GetDead")
+}
+func (s stubJobRepository) Requeue(context.Context, string) (r0 *models.Job, r1 error) {
+	return r0, errNotStubbed("JobRepository.Requeue")
+}
+func (s stubJobRepository) WithTx(database.Querier) (r0 repository.JobRepository) { return s }
+
+// stubNotificationRepository implements repository.NotificationRepository; every method is unstubbed.
+type stubNotificationRepository struct{}
+
+func (s stubNotificationRepository) Create(context.Context, *models.Notification) (r0 error) {
+	return errNotStubbed("NotificationRepository.Create")
+}
+func (s stubNotificationRepository) GetByUserID(context.Context, string, int) (r0 []models.Notification, r1 error) {
+	return r0, errNotStubbed("NotificationRepository.GetByUserID")
+}
+func (s stubNotificationRepository) MarkRead(context.Context, string, string) (r0 error) {
+	return errNotStubbed("NotificationRepository.MarkRead")
+}
+func (s stubNotificationRepository) GetSettings(context.Context, string, string) (r0 *models.GroupNotificationSettings, r1 error) {
+	return r0, errNotStubbed("NotificationRepository.GetSettings")
+}
+func (s stubNotificationRepository) GetSettingsForGroup(context.Context, string) (r0 map[string]models.GroupNotificationSettings, r1 error) {
+	return r0, errNotStubbed("NotificationRepository.GetSettingsForGroup")
+}
+func (s stubNotificationRepository) UpsertSettings(context.Context, *models.GroupNotificationSettings) (r0 error) {
+	return errNotStubbed("NotificationRepository.UpsertSettings")
+}
+func (s stubNotificationRepository) GetQuietHours(context.Context, string) (r0 *models.GroupQuietHours, r1 error) {
+	return r0, errNotStubbed("NotificationRepository.GetQuietHours")
+}
+func (s stubNotificationRepository) UpsertQuietHours(context.Context, *models.GroupQuietHours) (r0 error) {
+	return errNotStubbed("NotificationRepository.UpsertQuietHours")
+}
+func (s stubNotificationRepository) GetLastRemindersByActor(context.Context, string, time.Time) (r0 map[string]time.Time, r1 error) {
+	return r0, errNotStubbed("NotificationRepository.GetLastRemindersByActor")
+}
+func (s stubNotificationRepository) WithTx(database.Querier) (r0 repository.NotificationRepository) {
+	return s
+}
+
+// stubPlaceholderClaimRepository implements repository.PlaceholderClaimRepository; every method is unstubbed.
+type stubPlaceholderClaimRepository struct{}
+
+func (s stubPlaceholderClaimRepository) Create(context.Context, string, string) (r0 *models.PlaceholderClaimRequest, r1 error) {
+	return r0, errNotStubbed("PlaceholderClaimRepository.Create")
+}
+func (s stubPlaceholderClaimRepository) GetByIDForUpdate(context.Context, string) (r0 *models.PlaceholderClaimRequest, r1 error) {
+	return r0, errNotStubbed("PlaceholderClaimRepository.GetByIDForUpdate")
+}
+func (s stubPlaceholderClaimRepository) GetPending(context.Context) (r0 []models.PlaceholderClaimRequest, r1 error) {
+	return r0, errNotStubbed("PlaceholderClaimRepository.GetPending")
+}
+func (s stubPlaceholderClaimRepository) Decide(context.Context, string, string, models.PlaceholderClaimStatus) (r0 error) {
+	return errNotStubbed("PlaceholderClaimRepository.Decide")
+}
+func (s stubPlaceholderClaimRepository) RejectPendingForPlaceholder(context.Context, string, string) (r0 error) {
+	return errNotStubbed("PlaceholderClaimRepository.RejectPendingForPlaceholder")
+}
+func (s stubPlaceholderClaimRepository) WithTx(database.Querier) (r0 repository.PlaceholderClaimRepository) {
+	return s
+}
+
+// stubQuotaRepository implements repository.QuotaRepository; every method is unstubbed.
+type stubQuotaRepository struct{}
+
+func (s stubQuotaRepository) CountUserGroups(context.Context, string) (r0 int, r1 error) {
+	return r0, errNotStubbed("QuotaRepository.CountUserGroups")
+}
+func (s stubQuotaRepository) CountGroupMembers(context.Context, string) (r0 int, r1 error) {
+	return r0, errNotStubbed("QuotaRepository.CountGroupMembers")
+}
+func (s stubQuotaRepository) CountGroupExpenses(context.Context, string) (r0 int, r1 error) {
+	return r0, errNotStubbed("QuotaRepository.CountGroupExpenses")
+}
+func (s stubQuotaRepository) GetGroupUsage(context.Context, string) (r0 []models.GroupQuotaUsage, r1 error) {
+	return r0, errNotStubbed("QuotaRepository.GetGroupUsage")
+}
+func (s stubQuotaRepository) IsExempt(context.Context, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("QuotaRepository.IsExempt")
+}
+func (s stubQuotaRepository) SetExempt(context.Context, string, bool) (r0 error) {
+	return errNotStubbed("QuotaRepository.SetExempt")
+}
+func (s stubQuotaRepository) WithTx(database.Querier) (r0 repository.QuotaRepository) { return s }
+
+// stubReadRepository implements repository.ReadRepository; every method is unstubbed.
+type stubReadRepository struct{}
+
+func (s stubReadRepository) MarkSeen(context.Context, string, string, []string) (r0 int64, r1 error) {
+	return r0, errNotStubbed("ReadRepository.MarkSeen")
+}
+func (s stubReadRepository) GetReadStates(context.Context, string, string) (r0 map[string]models.ReadState, r1 error) {
+	return r0, errNotStubbed("ReadRepository.GetReadStates")
+}
+func (s stubReadRepository) GetUnreadCounts(context.Context, string, []string) (r0 map[string]int, r1 error) {
+	return r0, errNotStubbed("ReadRepository.GetUnreadCounts")
+}
+func (s stubReadRepository) GetByExpenseID(context.Context, string) (r0 []models.ExpenseRead, r1 error) {
+	return r0, errNotStubbed("ReadRepository.GetByExpenseID")
+}
+func (s stubReadRepository) GetVisibility(context.Context, string) (r0 map[string]models.ExpenseVisibility, r1 error) {
+	return r0, errNotStubbed("ReadRepository.GetVisibility")
+}
+func (s stubReadRepository) WithTx(database.Querier) (r0 repository.ReadRepository) { return s }
+
+// stubReceiptStore implements repository.ReceiptStore; every method is unstubbed.
+type stubReceiptStore struct{}
+
+func (s stubReceiptStore) GetReceiptItems(context.Context, string) (r0 []models.ReceiptItem, r1 error) {
+	return r0, errNotStubbed("ReceiptStore.GetReceiptItems")
+}
+func (s stubReceiptStore) GetReceiptItemsByExpenseIDs(context.Context, []string) (r0 map[string][]models.ReceiptItem, r1 error) {
+	return r0, errNotStubbed("ReceiptStore.GetReceiptItemsByExpenseIDs")
+}
+func (s stubReceiptStore) CreateReceiptItem(context.Context, *models.ReceiptItem) (r0 error) {
+	return errNotStubbed("ReceiptStore.CreateReceiptItem")
+}
+func (s stubReceiptStore) GetReceiptItemAssignments(context.Context, string) (r0 []models.ReceiptItemAssignment, r1 error) {
+	return r0, errNotStubbed("ReceiptStore.GetReceiptItemAssignments")
+}
+func (s stubReceiptStore) CreateReceiptItemAssignment(context.Context, *models.ReceiptItemAssignment) (r0 error) {
+	return errNotStubbed("ReceiptStore.CreateReceiptItemAssignment")
+}
+func (s stubReceiptStore) DeleteReceiptItems(context.Context, string) (r0 error) {
+	return errNotStubbed("ReceiptStore.DeleteReceiptItems")
+}
+func (s stubReceiptStore) GetReceiptsByGroupID(context.Context, string, int, int) (r0 []models.ReceiptGalleryItem, r1 int, r2 error) {
+	return r0, r1, errNotStubbed("ReceiptStore.GetReceiptsByGroupID")
+}
+
+// stubReminderResponseRepository implements repository.ReminderResponseRepository; every method is unstubbed.
+type stubReminderResponseRepository struct{}
+
+func (s stubReminderResponseRepository) Upsert(context.Context, *models.ReminderResponse) (r0 error) {
+	return errNotStubbed("ReminderResponseRepository.Upsert")
+}
+func (s stubReminderResponseRepository) Delete(context.Context, string, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("ReminderResponseRepository.Delete")
+}
+func (s stubReminderResponseRepository) GetActiveByGroupID(context.Context, string, time.Time) (r0 []models.ReminderResponse, r1 error) {
+	return r0, errNotStubbed("ReminderResponseRepository.GetActiveByGroupID")
+}
+func (s stubReminderResponseRepository) GetActiveByCreditorID(context.Context, string, time.Time) (r0 []models.ReminderResponse, r1 error) {
+	return r0, errNotStubbed("ReminderResponseRepository.GetActiveByCreditorID")
+}
+func (s stubReminderResponseRepository) WithTx(database.Querier) (r0 repository.ReminderResponseRepository) {
+	return s
+}
+
+// stubRetentionRepository implements repository.RetentionRepository; every method is unstubbed.
+type stubRetentionRepository struct{}
+
+func (s stubRetentionRepository) GetPolicy(context.Context, string) (r0 *models.GroupRetentionPolicy, r1 error) {
+	return r0, errNotStubbed("RetentionRepository.GetPolicy")
+}
+func (s stubRetentionRepository) UpsertPolicy(context.Context, *models.GroupRetentionPolicy) (r0 error) {
+	return errNotStubbed("RetentionRepository.UpsertPolicy")
+}
+func (s stubRetentionRepository) DeletePolicy(context.Context, string) (r0 error) {
+	return errNotStubbed("RetentionRepository.DeletePolicy")
+}
+func (s stubRetentionRepository) ClaimDuePolicies(context.Context, int, time.Duration) (r0 []models.GroupRetentionPolicy, r1 error) {
+	return r0, errNotStubbed("RetentionRepository.ClaimDuePolicies")
+}
+func (s stubRetentionRepository) MarkPolicyRun(context.Context, string, time.Time, *string, time.Time) (r0 error) {
+	return errNotStubbed("RetentionRepository.MarkPolicyRun")
+}
+func (s stubRetentionRepository) GetExpiredExpenseIDs(context.Context, string, time.Time, models.RetentionAction) (r0 []string, r1 error) {
+	return r0, errNotStubbed("RetentionRepository.GetExpiredExpenseIDs")
+}
+func (s stubRetentionRepository) DeleteExpenses(context.Context, []string) (r0 error) {
+	return errNotStubbed("RetentionRepository.DeleteExpenses")
+}
+func (s stubRetentionRepository) AnonymizeExpenses(context.Context, []string, string) (r0 error) {
+	return errNotStubbed("RetentionRepository.AnonymizeExpenses")
+}
+func (s stubRetentionRepository) WithTx(database.Querier) (r0 repository.RetentionRepository) {
+	return s
+}
+
+// stubSplitPreferenceRepository implements repository.SplitPreferenceRepository; every method is unstubbed.
+type stubSplitPreferenceRepository struct{}
+
+func (s stubSplitPreferenceRepository) GetForPair(context.Context, string, string) (r0 []models.SplitPreference, r1 error) {
+	return r0, errNotStubbed("SplitPreferenceRepository.GetForPair")
+}
+func (s stubSplitPreferenceRepository) Find(context.Context, string, string, string) (r0 *models.SplitPreference, r1 error) {
+	return r0, errNotStubbed("SplitPreferenceRepository.Find")
+}
+func (s stubSplitPreferenceRepository) Upsert(context.Context, *models.SplitPreference) (r0 error) {
+	return errNotStubbed("SplitPreferenceRepository.Upsert")
+}
+func (s stubSplitPreferenceRepository) Delete(context.Context, string, string, *string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("SplitPreferenceRepository.Delete")
+}
+func (s stubSplitPreferenceRepository) WithTx(database.Querier) (r0 repository.SplitPreferenceRepository) {
+	return s
+}
+
+// stubSplitWriter implements repository.SplitWriter; every method is unstubbed.
+type stubSplitWriter struct{}
+
+func (s stubSplitWriter) CreateSplit(context.Context, *models.ExpenseSplit) (r0 error) {
+	return errNotStubbed("SplitWriter.CreateSplit")
+}
+func (s stubSplitWriter) DeleteSplits(context.Context, string) (r0 error) {
+	return errNotStubbed("SplitWriter.DeleteSplits")
+}
+func (s stubSplitWriter) SetSplitExclusion(context.Context, string, string, *models.SplitExclusionStatus, *string) (r0 error) {
+	return errNotStubbed("SplitWriter.SetSplitExclusion")
+}
+func (s stubSplitWriter) ExcludeFromSplit(context.Context, string, string, []models.ExpenseSplit) (r0 error) {
+	return errNotStubbed("SplitWriter.ExcludeFromSplit")
+}
+func (s stubSplitWriter) CreatePayer(context.Context, *models.ExpensePayer) (r0 error) {
+	return errNotStubbed("SplitWriter.CreatePayer")
+}
+func (s stubSplitWriter) DeletePayers(context.Context, string) (r0 error) {
+	return errNotStubbed("SplitWriter.DeletePayers")
+}
+
+// stubStandingRepaymentRepository implements repository.StandingRepaymentRepository; every method is unstubbed.
+type stubStandingRepaymentRepository struct{}
+
+func (s stubStandingRepaymentRepository) Create(context.Context, *models.StandingRepayment) (r0 error) {
+	return errNotStubbed("StandingRepaymentRepository.Create")
+}
+func (s stubStandingRepaymentRepository) GetByID(context.Context, string) (r0 *models.StandingRepayment, r1 error) {
+	return r0, errNotStubbed("StandingRepaymentRepository.GetByID")
+}
+func (s stubStandingRepaymentRepository) GetActiveByGroupID(context.Context, string) (r0 []models.StandingRepayment, r1 error) {
+	return r0, errNotStubbed("StandingRepaymentRepository.GetActiveByGroupID")
+}
+func (s stubStandingRepaymentRepository) Cancel(context.Context, string, *string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("StandingRepaymentRepository.Cancel")
+}
+func (s stubStandingRepaymentRepository) ClaimDue(context.Context, int, time.Duration) (r0 []models.StandingRepayment, r1 error) {
+	return r0, errNotStubbed("StandingRepaymentRepository.ClaimDue")
+}
+func (s stubStandingRepaymentRepository) MarkRun(context.Context, string, time.Time, string, time.Time) (r0 error) {
+	return errNotStubbed("StandingRepaymentRepository.MarkRun")
+}
+func (s stubStandingRepaymentRepository) WithTx(database.Querier) (r0 repository.StandingRepaymentRepository) {
+	return s
+}
+
+// stubStatsRepository implements repository.StatsRepository; every method is unstubbed.
+type stubStatsRepository struct{}
+
+func (s stubStatsRepository) GetExpenseTotals(context.Context, string, string) (r0 int, r1 float64, r2 error) {
+	return r0, r1, errNotStubbed("StatsRepository.GetExpenseTotals")
+}
+func (s stubStatsRepository) GetBiggestExpense(context.Context, string, string) (r0 *models.FunStatExpense, r1 error) {
+	return r0, errNotStubbed("StatsRepository.GetBiggestExpense")
+}
+func (s stubStatsRepository) GetMemberPaymentStats(context.Context, string, string) (r0 []models.FunStatMember, r1 error) {
+	return r0, errNotStubbed("StatsRepository.GetMemberPaymentStats")
+}
+func (s stubStatsRepository) GetLongestQuietStreak(context.Context, string, string) (r0 *models.FunStatStreak, r1 error) {
+	return r0, errNotStubbed("StatsRepository.GetLongestQuietStreak")
+}
+func (s stubStatsRepository) GetSpendingHeatmap(context.Context, string, string) (r0 []models.HeatmapCell, r1 []models.HeatmapMember, r2 error) {
+	return r0, r1, errNotStubbed("StatsRepository.GetSpendingHeatmap")
+}
+func (s stubStatsRepository) GetLeaderboard(context.Context, string, string, *time.Time) (r0 []models.LeaderboardEntry, r1 error) {
+	return r0, errNotStubbed("StatsRepository.GetLeaderboard")
+}
+func (s stubStatsRepository) WithTx(database.Querier) (r0 repository.StatsRepository) { return s }
+
+// stubTagRepository implements repository.TagRepository; every method is unstubbed.
+type stubTagRepository struct{}
+
+func (s stubTagRepository) GetByGroupID(context.Context, string) (r0 []models.Tag, r1 error) {
+	return r0, errNotStubbed("TagRepository.GetByGroupID")
+}
+func (s stubTagRepository) GetByID(context.Context, string) (r0 *models.Tag, r1 error) {
+	return r0, errNotStubbed("TagRepository.GetByID")
+}
+func (s stubTagRepository) EnsureTags(context.Context, string, []string) (r0 []models.Tag, r1 error) {
+	return r0, errNotStubbed("TagRepository.EnsureTags")
+}
+func (s stubTagRepository) SetExpenseTags(context.Context, string, []string) (r0 error) {
+	return errNotStubbed("TagRepository.SetExpenseTags")
+}
+func (s stubTagRepository) GetTagNamesByExpenseIDs(context.Context, []string) (r0 map[string][]string, r1 error) {
+	return r0, errNotStubbed("TagRepository.GetTagNamesByExpenseIDs")
+}
+func (s stubTagRepository) GetTotalsByGroupID(context.Context, string) (r0 []models.TagTotal, r1 error) {
+	return r0, errNotStubbed("TagRepository.GetTotalsByGroupID")
+}
+func (s stubTagRepository) Delete(context.Context, string) (r0 error) {
+	return errNotStubbed("TagRepository.Delete")
+}
+func (s stubTagRepository) WithTx(database.Querier) (r0 repository.TagRepository) { return s }
+
+// stubUserRepository implements repository.UserRepository; every method is unstubbed.
+type stubUserRepository struct{}
+
+func (s stubUserRepository) GetByID(context.Context, string) (r0 *models.User, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetByID")
+}
+func (s stubUserRepository) GetByEmail(context.Context, string) (r0 *models.User, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetByEmail")
+}
+func (s stubUserRepository) Create(context.Context, *models.User) (r0 error) {
+	return errNotStubbed("UserRepository.Create")
+}
+func (s stubUserRepository) Update(context.Context, *models.User) (r0 error) {
+	return errNotStubbed("UserRepository.Update")
+}
+func (s stubUserRepository) UpdateAvatarURL(context.Context, string, string) (r0 error) {
+	return errNotStubbed("UserRepository.UpdateAvatarURL")
+}
+func (s stubUserRepository) Delete(context.Context, string) (r0 error) {
+	return errNotStubbed("UserRepository.Delete")
+}
+func (s stubUserRepository) Search(context.Context, string, string) (r0 []models.UserSearchMatch, r1 error) {
+	return r0, errNotStubbed("UserRepository.Search")
+}
+func (s stubUserRepository) MatchEmailHashes(context.Context, string, []string) (r0 []models.ContactSuggestion, r1 error) {
+	return r0, errNotStubbed("UserRepository.MatchEmailHashes")
+}
+func (s stubUserRepository) GetPrivacySettings(context.Context, string) (r0 *models.PrivacySettings, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetPrivacySettings")
+}
+func (s stubUserRepository) UpdatePrivacySettings(context.Context, string, *models.PrivacySettings) (r0 error) {
+	return errNotStubbed("UserRepository.UpdatePrivacySettings")
+}
+func (s stubUserRepository) GetReportSettings(context.Context, string) (r0 *models.ReportSettings, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetReportSettings")
+}
+func (s stubUserRepository) UpdateReportSettings(context.Context, string, *models.ReportSettings) (r0 error) {
+	return errNotStubbed("UserRepository.UpdateReportSettings")
+}
+func (s stubUserRepository) GetBalanceAlertSettings(context.Context, string) (r0 *models.BalanceAlertSettings, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetBalanceAlertSettings")
+}
+func (s stubUserRepository) UpdateBalanceAlertSettings(context.Context, string, *models.BalanceAlertSettings) (r0 error) {
+	return errNotStubbed("UserRepository.UpdateBalanceAlertSettings")
+}
+func (s stubUserRepository) GetUnclaimedPlaceholders(context.Context, string, string) (r0 []models.User, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetUnclaimedPlaceholders")
+}
+func (s stubUserRepository) GetPlaceholderGroups(context.Context, []string) (r0 map[string][]models.PlaceholderGroup, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetPlaceholderGroups")
+}
+func (s stubUserRepository) GetByIDForUpdate(context.Context, string) (r0 *models.User, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetByIDForUpdate")
+}
+func (s stubUserRepository) ClaimPlaceholder(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("UserRepository.ClaimPlaceholder")
+}
+func (s stubUserRepository) GetUsersSharingGroups(context.Context, string) (r0 []models.User, r1 error) {
+	return r0, errNotStubbed("UserRepository.GetUsersSharingGroups")
+}
+func (s stubUserRepository) SharesTransactions(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("UserRepository.SharesTransactions")
+}
+func (s stubUserRepository) MergePlaceholder(context.Context, string, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("UserRepository.MergePlaceholder")
+}
+func (s stubUserRepository) UpdateEmailVerified(context.Context, string, bool) (r0 error) {
+	return errNotStubbed("UserRepository.UpdateEmailVerified")
+}
+func (s stubUserRepository) IsDeleted(context.Context, string) (r0 bool, r1 error) {
+	return r0, errNotStubbed("UserRepository.IsDeleted")
+}
+func (s stubUserRepository) WithTx(database.Querier) (r0 repository.UserRepository) { return s }
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+	"unwise-backend/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type RetentionService interface {
+	GetPolicy(ctx context.Context, groupID, userID string) (*models.GroupRetentionPolicy, error)
+	UpdatePolicy(ctx context.Context, groupID, userID string, retainYears int, action models.RetentionAction) (*models.GroupRetentionPolicy, error)
+	RunWorker(ctx context.Context)
+}
+
+type retentionService struct {
+	retentionRepo    repository.RetentionRepository
+	groupRepo        repository.GroupRepository
+	expenseRepo      repository.ExpenseRepository
+	activityRepo     repository.ActivityRepository
+	balanceEventRepo repository.BalanceEventRepository
+	storage          storage.Storage
+	archiveBucket    string
+	db               *database.DB
+}
+
+func NewRetentionService(retentionRepo repository.RetentionRepository, groupRepo repository.GroupRepository, expenseRepo repository.ExpenseRepository, activityRepo repository.ActivityRepository, balanceEventRepo repository.BalanceEventRepository, storageService storage.Storage, archiveBucket string, db *database.DB) RetentionService {
+	return &retentionService{
+		retentionRepo:    retentionRepo,
+		groupRepo:        groupRepo,
+		expenseRepo:      expenseRepo,
+		activityRepo:     activityRepo,
+		balanceEventRepo: balanceEventRepo,
+		storage:          storageService,
+		archiveBucket:    archiveBucket,
+		db:               db,
+	}
+}
+
+func (s *retentionService) GetPolicy(ctx context.Context, groupID, userID string) (*models.GroupRetentionPolicy, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.retentionRepo.GetPolicy(ctx, groupID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return &models.GroupRetentionPolicy{GroupID: groupID}, nil
+		}
+		return nil, apperrors.DatabaseError("getting retention policy", err)
+	}
+	return policy, nil
+}
+
+// retainYears 0 turns retention off.
+func (s *retentionService) UpdatePolicy(ctx context.Context, groupID, userID string, retainYears int, action models.RetentionAction) (*models.GroupRetentionPolicy, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	policy := &models.GroupRetentionPolicy{GroupID: groupID}
+	message := "Data retention turned off; transactions are kept indefinitely"
+	if retainYears != 0 {
+		if retainYears < MinRetentionYears || retainYears > MaxRetentionYears {
+			return nil, apperrors.InvalidRequest(fmt.Sprintf("retain_years must be between %d and %d, or 0 to keep everything.", MinRetentionYears, MaxRetentionYears))
+		}
+		if !action.IsValid() {
+			return nil, apperrors.InvalidRequest("action must be DELETE or ANONYMIZE.")
+		}
+		policy.RetainYears = retainYears
+		policy.Action = action
+		policy.UpdatedBy = &userID
+		verb := "anonymized"
+		if action == models.RetentionActionDelete {
+			verb = "deleted"
+		}
+		message = fmt.Sprintf("Transactions older than %d years will be archived and %s", retainYears, verb)
+	}
+
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		if policy.RetainYears == 0 {
+			if err := s.retentionRepo.WithTx(q).DeletePolicy(ctx, groupID); err != nil {
+				return apperrors.DatabaseError("deleting retention policy", err)
+			}
+		} else if err := s.retentionRepo.WithTx(q).UpsertPolicy(ctx, policy); err != nil {
+			return apperrors.DatabaseError("updating retention policy", err)
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityRetentionUpdated,
+			Message: message,
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("Group retention policy updated",
+		zap.String("group_id", groupID),
+		zap.String("user_id", userID),
+		zap.Int("retain_years", policy.RetainYears),
+		zap.String("action", string(policy.Action)))
+	return policy, nil
+}
+
+func (s *retentionService) RunWorker(ctx context.Context) {
+	zap.L().Info("Retention worker started")
+	ticker := time.NewTicker(RetentionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("Retention worker stopped")
+			return
+		case <-ticker.C:
+			s.applyDue(ctx)
+		}
+	}
+}
+
+func (s *retentionService) applyDue(ctx context.Context) {
+	policies, err := s.retentionRepo.ClaimDuePolicies(ctx, RetentionBatchSize, RetentionRunInterval)
+	if err != nil {
+		zap.L().Error("Failed to claim retention policies", zap.Error(err))
+		return
+	}
+
+	for _, policy := range policies {
+		if err := s.apply(ctx, policy); err != nil {
+			zap.L().Error("Failed to apply retention policy",
+				zap.String("group_id", policy.GroupID),
+				zap.Int("retain_years", policy.RetainYears),
+				zap.String("action", string(policy.Action)),
+				zap.Error(err))
+		}
+	}
+}
+
+type retentionArchive struct {
+	GroupID      string                 `json:"group_id"`
+	Action       models.RetentionAction `json:"action"`
+	Cutoff       string                 `json:"cutoff"`
+	GeneratedAt  time.Time              `json:"generated_at"`
+	Transactions []models.Transaction   `json:"transactions"`
+}
+
+// Nothing is removed unless the archive was uploaded.
+func (s *retentionService) apply(ctx context.Context, policy models.GroupRetentionPolicy) error {
+	now := time.Now().UTC()
+	cutoff := retentionCutoff(now, policy.RetainYears)
+	groupID := policy.GroupID
+
+	ids, err := s.retentionRepo.GetExpiredExpenseIDs(ctx, groupID, cutoff, policy.Action)
+	if err != nil {
+		return fmt.Errorf("getting expired transactions: %w", err)
+	}
+	if len(ids) == 0 {
+		return s.retentionRepo.MarkPolicyRun(ctx, groupID, now, nil, now.Add(RetentionRunInterval))
+	}
+
+	expired := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		expired[id] = true
+	}
+	all, err := s.expenseRepo.GetTransactionsByGroupID(ctx, groupID, models.TransactionSort{Field: models.TransactionSortDate, Order: models.SortOrderAsc})
+	if err != nil {
+		return fmt.Errorf("getting transactions to archive: %w", err)
+	}
+	archive := retentionArchive{GroupID: groupID, Action: policy.Action, Cutoff: cutoff.Format("2006-01-02"), GeneratedAt: now}
+	for _, t := range all {
+		if expired[t.ID] {
+			archive.Transactions = append(archive.Transactions, t)
+		}
+	}
+
+	body, err := json.Marshal(archive)
+	if err != nil {
+		return fmt.Errorf("encoding retention archive: %w", err)
+	}
+	archivePath := fmt.Sprintf("retention/%s/%s.json", groupID, now.Format("20060102_150405"))
+	if _, err := s.storage.Upload(ctx, s.archiveBucket, archivePath, bytes.NewReader(body), "application/json"); err != nil {
+		return fmt.Errorf("uploading retention archive: %w", err)
+	}
+
+	message := fmt.Sprintf("Anonymized %d transactions dated before %s", len(ids), archive.Cutoff)
+	if policy.Action == models.RetentionActionDelete {
+		message = fmt.Sprintf("Deleted %d transactions dated before %s; balances were carried forward", len(ids), archive.Cutoff)
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		if policy.Action == models.RetentionActionDelete {
+			if err := s.deleteExpired(ctx, q, groupID, ids, cutoff); err != nil {
+				return err
+			}
+		} else if err := s.retentionRepo.WithTx(q).AnonymizeExpenses(ctx, ids, RetentionAnonymizedDescription); err != nil {
+			return apperrors.DatabaseError("anonymizing expired transactions", err)
+		}
+
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			Action:  models.GroupActivityRetentionApplied,
+			Message: message,
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		if err := s.retentionRepo.WithTx(q).MarkPolicyRun(ctx, groupID, now, &archivePath, now.Add(RetentionRunInterval)); err != nil {
+			return apperrors.DatabaseError("marking retention run", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range archive.Transactions {
+		if t.ReceiptImagePath == nil || *t.ReceiptImagePath == "" {
+			continue
+		}
+		if err := s.storage.Delete(ctx, s.archiveBucket, *t.ReceiptImagePath); err != nil {
+			zap.L().Warn("Failed to delete receipt of expired transaction", zap.String("expense_id", t.ID), zap.Error(err))
+		}
+	}
+
+	zap.L().Info("Retention policy applied",
+		zap.String("group_id", groupID),
+		zap.String("action", string(policy.Action)),
+		zap.Int("transactions", len(ids)),
+		zap.String("archive_path", archivePath))
+	return nil
+}
+
+func (s *retentionService) deleteExpired(ctx context.Context, q database.Querier, groupID string, ids []string, cutoff time.Time) error {
+	// Balances of members who have since left are carried forward too, so
+	// only the group is locked, without re-checking membership.
+	if err := lockGroupForWrite(ctx, s.groupRepo, q, groupID); err != nil {
+		return err
+	}
+
+	var contributions, events []models.BalanceEvent
+	for _, id := range ids {
+		before, err := snapshotBalanceContributions(ctx, s.balanceEventRepo, q, id)
+		if err != nil {
+			return err
+		}
+		contributions = append(contributions, before...)
+		events = append(events, diffBalanceEvents(before, nil, models.BalanceEventTransactionDeleted)...)
+	}
+
+	if err := s.retentionRepo.WithTx(q).DeleteExpenses(ctx, ids); err != nil {
+		return apperrors.DatabaseError("deleting expired transactions", err)
+	}
+	if s.balanceEventRepo != nil {
+		if err := s.balanceEventRepo.WithTx(q).Append(ctx, events); err != nil {
+			return apperrors.DatabaseError("recording balance events", err)
+		}
+	}
+
+	txRepo := s.expenseRepo.WithTx(q)
+	for _, expense := range carryForwardExpenses(groupID, contributions, cutoff) {
+		if err := txRepo.Create(ctx, expense); err != nil {
+			return expenseWriteError("creating carried forward balance", err)
+		}
+		for i := range expense.Payers {
+			if err := txRepo.CreatePayer(ctx, &expense.Payers[i]); err != nil {
+				return expenseWriteError("creating carried forward balance payer", err)
+			}
+		}
+		for i := range expense.Splits {
+			if err := txRepo.CreateSplit(ctx, &expense.Splits[i]); err != nil {
+				return expenseWriteError("creating carried forward balance split", err)
+			}
+		}
+		if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionCreated, expense.ID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func retentionCutoff(now time.Time, retainYears int) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year()-retainYears, now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Deleted transactions are replaced by one expense per currency that
+// reproduces each member's balance. Rounding leftovers go to the largest entry
+// so the expense still balances.
+func carryForwardExpenses(groupID string, contributions []models.BalanceEvent, cutoff time.Time) []*models.Expense {
+	nets := make(map[string]map[string]float64)
+	for _, c := range contributions {
+		if nets[c.Currency] == nil {
+			nets[c.Currency] = make(map[string]float64)
+		}
+		nets[c.Currency][c.UserID] += c.Delta
+	}
+
+	currencies := make([]string, 0, len(nets))
+	for currency := range nets {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	type share struct {
+		userID string
+		cents  int64
+	}
+	var expenses []*models.Expense
+	for _, currency := range currencies {
+		var owed, owing []share
+		var owedTotal, owingTotal int64
+		for userID, net := range nets[currency] {
+			cents := int64(math.Round(net * RoundingFactor))
+			switch {
+			case cents > 0:
+				owed = append(owed, share{userID, cents})
+				owedTotal += cents
+			case cents < 0:
+				owing = append(owing, share{userID, -cents})
+				owingTotal -= cents
+			}
+		}
+		if len(owed) == 0 || len(owing) == 0 {
+			continue
+		}
+		byAmount := func(shares []share) {
+			sort.Slice(shares, func(i, j int) bool {
+				if shares[i].cents != shares[j].cents {
+					return shares[i].cents > shares[j].cents
+				}
+				return shares[i].userID < shares[j].userID
+			})
+		}
+		byAmount(owed)
+		byAmount(owing)
+		if owedTotal > owingTotal {
+			owed[0].cents -= owedTotal - owingTotal
+			owedTotal = owingTotal
+		} else {
+			owing[0].cents -= owingTotal - owedTotal
+		}
+
+		id := uuid.New().String()
+		total := float64(owedTotal) / RoundingFactor
+		expense := &models.Expense{
+			ID:           id,
+			GroupID:      groupID,
+			PaidByUserID: &owed[0].userID,
+			TotalAmount:  total,
+			Currency:     currency,
+			Description:  fmt.Sprintf("Balance carried forward from before %s", cutoff.Format("2006-01-02")),
+			Type:         models.ExpenseTypeExactAmount,
+			Category:     models.TransactionCategoryExpense,
+			DateISO:      cutoff,
+			Date:         cutoff.Format("2006-01-02"),
+			Time:         "00:00",
+		}
+		for _, o := range owed {
+			if o.cents <= 0 {
+				continue
+			}
+			expense.Payers = append(expense.Payers, models.ExpensePayer{ID: uuid.New().String(), ExpenseID: id, UserID: o.userID, AmountPaid: float64(o.cents) / RoundingFactor})
+		}
+		for _, o := range owing {
+			if o.cents <= 0 {
+				continue
+			}
+			expense.Splits = append(expense.Splits, models.ExpenseSplit{ID: uuid.New().String(), ExpenseID: id, UserID: o.userID, Amount: float64(o.cents) / RoundingFactor})
+		}
+		expenses = append(expenses, expense)
+	}
+	return expenses
+}
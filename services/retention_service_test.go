@@ -0,0 +1,78 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestRetentionCutoff(t *testing.T) {
+	now := time.Date(2026, 3, 14, 18, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	got := retentionCutoff(now, 7)
+	expected := time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(expected) {
+		t.Errorf("retentionCutoff() = %v, expected %v", got, expected)
+	}
+}
+
+func TestCarryForwardExpenses(t *testing.T) {
+	cutoff := time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC)
+	contributions := []models.BalanceEvent{
+		// a paid 90 for a three-way dinner
+		{UserID: "a", Currency: "INR", Delta: 60},
+		{UserID: "b", Currency: "INR", Delta: -30},
+		{UserID: "c", Currency: "INR", Delta: -30},
+		// b settled 30 with a
+		{UserID: "b", Currency: "INR", Delta: 30},
+		{UserID: "a", Currency: "INR", Delta: -30},
+		// c paid 10 USD split with a, one third each way
+		{UserID: "c", Currency: "USD", Delta: 6.67},
+		{UserID: "a", Currency: "USD", Delta: -3.33},
+		{UserID: "b", Currency: "USD", Delta: -3.33},
+		// a fully settled EUR history leaves nothing to carry
+		{UserID: "a", Currency: "EUR", Delta: 5},
+		{UserID: "b", Currency: "EUR", Delta: -5},
+		{UserID: "b", Currency: "EUR", Delta: 5},
+		{UserID: "a", Currency: "EUR", Delta: -5},
+	}
+
+	expenses := carryForwardExpenses("g", contributions, cutoff)
+	if len(expenses) != 2 {
+		t.Fatalf("expected 2 carried forward expenses, got %d", len(expenses))
+	}
+
+	expected := map[string]map[string]float64{
+		"INR": {"a": 30, "c": -30},
+		"USD": {"c": 6.66, "a": -3.33, "b": -3.33},
+	}
+	for _, e := range expenses {
+		if !e.DateISO.Equal(cutoff) || e.GroupID != "g" {
+			t.Errorf("%s: expected group g dated %v, got %s dated %v", e.Currency, cutoff, e.GroupID, e.DateISO)
+		}
+		paid, owed := 0.0, 0.0
+		nets := make(map[string]float64)
+		for _, p := range e.Payers {
+			paid += p.AmountPaid
+			nets[p.UserID] += p.AmountPaid
+		}
+		for _, s := range e.Splits {
+			owed += s.Amount
+			nets[s.UserID] -= s.Amount
+		}
+		if math.Abs(paid-e.TotalAmount) > AmountTolerance || math.Abs(owed-e.TotalAmount) > AmountTolerance {
+			t.Errorf("%s: payers %.2f and splits %.2f should both equal total %.2f", e.Currency, paid, owed, e.TotalAmount)
+		}
+		want := expected[e.Currency]
+		if len(nets) != len(want) {
+			t.Errorf("%s: nets = %v, expected %v", e.Currency, nets, want)
+			continue
+		}
+		for userID, amount := range want {
+			if math.Abs(nets[userID]-amount) > AmountTolerance {
+				t.Errorf("%s: net for %s = %.2f, expected %.2f", e.Currency, userID, nets[userID], amount)
+			}
+		}
+	}
+}
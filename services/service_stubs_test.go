@@ -0,0 +1,44 @@
+// Code generated by scripts/stubgen; DO NOT EDIT.
+
+package services
+
+import (
+	"context"
+	"time"
+	"unwise-backend/models"
+)
+
+// stubNotificationService implements NotificationService; every method is unstubbed.
+type stubNotificationService struct{}
+
+func (s stubNotificationService) GetGroupSettings(context.Context, string, string) (r0 *models.GroupNotificationSettings, r1 error) {
+	return r0, errNotStubbed("NotificationService.GetGroupSettings")
+}
+func (s stubNotificationService) UpdateGroupSettings(context.Context, string, string, *models.GroupNotificationSettings) (r0 *models.GroupNotificationSettings, r1 error) {
+	return r0, errNotStubbed("NotificationService.UpdateGroupSettings")
+}
+func (s stubNotificationService) GetNotifications(context.Context, string) (r0 []models.Notification, r1 error) {
+	return r0, errNotStubbed("NotificationService.GetNotifications")
+}
+func (s stubNotificationService) MarkRead(context.Context, string, string) (r0 error) {
+	return errNotStubbed("NotificationService.MarkRead")
+}
+func (s stubNotificationService) Dispatch(context.Context, NotificationPayload) (r0 error) {
+	return errNotStubbed("NotificationService.Dispatch")
+}
+func (s stubNotificationService) GetQuietHours(context.Context, string, string) (r0 *models.GroupQuietHours, r1 error) {
+	return r0, errNotStubbed("NotificationService.GetQuietHours")
+}
+func (s stubNotificationService) UpdateQuietHours(context.Context, string, string, *models.GroupQuietHours) (r0 *models.GroupQuietHours, r1 error) {
+	return r0, errNotStubbed("NotificationService.UpdateQuietHours")
+}
+
+// stubSettlementService implements SettlementService; every method is unstubbed.
+type stubSettlementService struct{}
+
+func (s stubSettlementService) CalculateSettlements(context.Context, string, string, *time.Time) (r0 []models.Settlement, r1 error) {
+	return r0, errNotStubbed("SettlementService.CalculateSettlements")
+}
+func (s stubSettlementService) CalculateSettlementsWith(context.Context, string, string, *time.Time, models.SettlementAlgorithm) (r0 []models.Settlement, r1 error) {
+	return r0, errNotStubbed("SettlementService.CalculateSettlementsWith")
+}
@@ -4,6 +4,8 @@ import (
 	"container/heap"
 	"context"
 	"math"
+	"sort"
+	"time"
 
 	apperrors "unwise-backend/errors"
 	"unwise-backend/models"
@@ -11,15 +13,16 @@ import (
 )
 
 type SettlementService interface {
-	CalculateSettlements(ctx context.Context, groupID, userID string) ([]models.Settlement, error)
+	CalculateSettlements(ctx context.Context, groupID, userID string, asOf *time.Time) ([]models.Settlement, error)
+	CalculateSettlementsWith(ctx context.Context, groupID, userID string, asOf *time.Time, algorithm models.SettlementAlgorithm) ([]models.Settlement, error)
 }
 
 type settlementService struct {
-	expenseRepo repository.ExpenseRepository
+	expenseRepo repository.BalanceQueries
 	groupRepo   repository.GroupRepository
 }
 
-func NewSettlementService(expenseRepo repository.ExpenseRepository, groupRepo repository.GroupRepository) SettlementService {
+func NewSettlementService(expenseRepo repository.BalanceQueries, groupRepo repository.GroupRepository) SettlementService {
 	return &settlementService{
 		expenseRepo: expenseRepo,
 		groupRepo:   groupRepo,
@@ -35,11 +38,18 @@ type personBalance struct {
 	balance float64
 }
 
+// balanceHeap pops the largest balance first and, between equal balances,
+// the smallest user ID, so ties never depend on map order.
 type balanceHeap []personBalance
 
-func (h balanceHeap) Len() int           { return len(h) }
-func (h balanceHeap) Less(i, j int) bool { return h[i].balance > h[j].balance }
-func (h balanceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h balanceHeap) Len() int { return len(h) }
+func (h balanceHeap) Less(i, j int) bool {
+	if h[i].balance != h[j].balance {
+		return h[i].balance > h[j].balance
+	}
+	return h[i].userID < h[j].userID
+}
+func (h balanceHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
 func (h *balanceHeap) Push(x interface{}) {
 	*h = append(*h, x.(personBalance))
 }
@@ -51,12 +61,50 @@ func (h *balanceHeap) Pop() interface{} {
 	return x
 }
 
-func (s *settlementService) CalculateSettlements(ctx context.Context, groupID, userID string) ([]models.Settlement, error) {
+// CalculateSettlements suggests who should pay whom, per currency, using the
+// group's settlement algorithm.
+func (s *settlementService) CalculateSettlements(ctx context.Context, groupID, userID string, asOf *time.Time) ([]models.Settlement, error) {
+	return s.CalculateSettlementsWith(ctx, groupID, userID, asOf, "")
+}
+
+// CalculateSettlementsWith suggests who should pay whom, per currency, using
+// algorithm, or the group's algorithm when it is empty. MIN_TRANSFERS
+// repeatedly matches the largest creditor with the largest debtor; PAIRWISE
+// settles each pair's own debt. The result is the same on every call for the
+// same balances:
+//   - equal balances are matched in user ID order
+//   - settlements are sorted by currency, then largest amount first, then by
+//     payer and receiver ID
+func (s *settlementService) CalculateSettlementsWith(ctx context.Context, groupID, userID string, asOf *time.Time, algorithm models.SettlementAlgorithm) ([]models.Settlement, error) {
 	if err := s.requireMembership(ctx, groupID, userID); err != nil {
 		return nil, err
 	}
 
-	balancesByCurrency, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID)
+	if algorithm == "" {
+		var err error
+		if algorithm, err = s.groupRepo.GetSettlementAlgorithm(ctx, groupID); err != nil {
+			return nil, apperrors.DatabaseError("getting group settlement algorithm", err)
+		}
+	} else if err := ValidateSettlementAlgorithm(algorithm); err != nil {
+		return nil, err
+	}
+
+	increment, err := s.groupRepo.GetSettlementRounding(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group settlement rounding", err)
+	}
+
+	if algorithm == models.SettlementAlgorithmPairwise {
+		pairs, err := s.expenseRepo.GetGroupPairBalances(ctx, groupID, asOf)
+		if err != nil {
+			return nil, apperrors.DatabaseError("getting group pair balances", err)
+		}
+		settlements := pairwiseSettlements(pairs, increment)
+		sortSettlements(settlements)
+		return settlements, nil
+	}
+
+	balancesByCurrency, err := s.expenseRepo.GetGroupMemberBalances(ctx, groupID, asOf)
 	if err != nil {
 		return nil, apperrors.DatabaseError("getting group member balances", err)
 	}
@@ -70,16 +118,116 @@ func (s *settlementService) CalculateSettlements(ctx context.Context, groupID, u
 		}
 	}
 
-	var allSettlements []models.Settlement
+	currencies := make([]string, 0, len(currencyBalances))
+	for currency := range currencyBalances {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
 
-	for currency, userBalances := range currencyBalances {
+	var allSettlements []models.Settlement
+	for _, currency := range currencies {
+		userBalances := currencyBalances[currency]
+		if increment > 0 {
+			userBalances = roundBalancesToIncrement(userBalances, increment)
+		}
 		settlements := s.calculateSettlementsForCurrency(userBalances, currency)
 		allSettlements = append(allSettlements, settlements...)
 	}
 
+	sortSettlements(allSettlements)
 	return allSettlements, nil
 }
 
+// ValidateSettlementAlgorithm rejects anything but the known algorithms.
+func ValidateSettlementAlgorithm(algorithm models.SettlementAlgorithm) error {
+	switch algorithm {
+	case models.SettlementAlgorithmMinTransfers, models.SettlementAlgorithmPairwise:
+		return nil
+	}
+	return apperrors.InvalidRequest("settlement_algorithm must be MIN_TRANSFERS or PAIRWISE.")
+}
+
+// pairwiseSettlements turns each pair's debt into one payment from whoever
+// owes to the other. With a rounding increment each payment is rounded to
+// the nearest multiple, and payments that round to nothing are dropped; as
+// with MIN_TRANSFERS, what is rounded off stays owed.
+func pairwiseSettlements(pairs []models.PairBalance, increment int) []models.Settlement {
+	settlements := []models.Settlement{}
+	for _, p := range pairs {
+		from, to, amount := p.UserB, p.UserA, p.Net
+		if amount < 0 {
+			from, to, amount = p.UserA, p.UserB, -amount
+		}
+		if increment > 0 {
+			amount = math.Round(amount/float64(increment)) * float64(increment)
+		}
+		amount = math.Round(amount*RoundingFactor) / RoundingFactor
+		if amount <= BalanceThreshold {
+			continue
+		}
+		settlements = append(settlements, models.Settlement{
+			FromUserID: from,
+			ToUserID:   to,
+			Amount:     amount,
+			Currency:   p.Currency,
+		})
+	}
+	return settlements
+}
+
+func sortSettlements(settlements []models.Settlement) {
+	sort.Slice(settlements, func(i, j int) bool {
+		a, b := settlements[i], settlements[j]
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		if a.Amount != b.Amount {
+			return a.Amount > b.Amount
+		}
+		if a.FromUserID != b.FromUserID {
+			return a.FromUserID < b.FromUserID
+		}
+		return a.ToUserID < b.ToUserID
+	})
+}
+
+// roundBalancesToIncrement rounds each balance to a multiple of increment
+// while keeping the total: everyone is rounded down, then those with the
+// largest remainders are rounded up. The stored balances stay exact, so what
+// is rounded off here is carried forward into later suggestions.
+func roundBalancesToIncrement(balances map[string]float64, increment int) map[string]float64 {
+	step := int64(increment) * int64(RoundingFactor)
+	type remainder struct {
+		userID string
+		cents  int64
+	}
+
+	rounded := make(map[string]float64, len(balances))
+	remainders := make([]remainder, 0, len(balances))
+	var totalCents, roundedCents int64
+	for uID, balance := range balances {
+		cents := int64(math.Round(balance * RoundingFactor))
+		floor := cents - ((cents%step)+step)%step
+		totalCents += cents
+		roundedCents += floor
+		rounded[uID] = float64(floor) / RoundingFactor
+		remainders = append(remainders, remainder{userID: uID, cents: cents - floor})
+	}
+
+	sort.Slice(remainders, func(i, j int) bool {
+		if remainders[i].cents != remainders[j].cents {
+			return remainders[i].cents > remainders[j].cents
+		}
+		return remainders[i].userID < remainders[j].userID
+	})
+	target := int64(math.Round(float64(totalCents)/float64(step))) * step
+	for i := 0; roundedCents < target && i < len(remainders); i++ {
+		rounded[remainders[i].userID] += float64(step) / RoundingFactor
+		roundedCents += step
+	}
+	return rounded
+}
+
 func (s *settlementService) calculateSettlementsForCurrency(balances map[string]float64, currency string) []models.Settlement {
 	creditorHeap := &balanceHeap{}
 	debtorHeap := &balanceHeap{}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"testing"
+	"time"
 	"unwise-backend/models"
 )
 
@@ -11,6 +12,7 @@ func TestCalculateSettlements(t *testing.T) {
 	tests := []struct {
 		name     string
 		balances map[string]map[string]float64 
+		rounding int
 		expected []models.Settlement
 	}{
 		{
@@ -54,16 +56,38 @@ func TestCalculateSettlements(t *testing.T) {
 				{FromUserID: "B", ToUserID: "A", Amount: 50.00, Currency: "USD"},
 			},
 		},
+		{
+			name: "Rounded to nearest 10",
+			balances: map[string]map[string]float64{
+				"A": {"INR": 1234.56},
+				"B": {"INR": -567.89},
+				"C": {"INR": -666.67},
+			},
+			rounding: 10,
+			expected: []models.Settlement{
+				{FromUserID: "C", ToUserID: "A", Amount: 670.00, Currency: "INR"},
+				{FromUserID: "B", ToUserID: "A", Amount: 570.00, Currency: "INR"},
+			},
+		},
+		{
+			name: "Residual below rounding carried forward",
+			balances: map[string]map[string]float64{
+				"A": {"INR": 4.30},
+				"B": {"INR": -4.30},
+			},
+			rounding: 10,
+			expected: []models.Settlement{},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockExpenseRepo{balances: tt.balances}
-			groupRepo := &mockGroupRepo{}
+			groupRepo := &mockGroupRepo{rounding: tt.rounding}
 
 			s := NewSettlementService(repo, groupRepo)
 
-			settlements, err := s.CalculateSettlements(context.Background(), "group1", "user1")
+			settlements, err := s.CalculateSettlements(context.Background(), "group1", "user1", nil)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -97,3 +121,188 @@ func TestCalculateSettlements(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateSettlementsIsDeterministic(t *testing.T) {
+	// Equal balances everywhere, so any map-order dependence would show up as
+	// a different pairing or order across runs.
+	balances := map[string]map[string]float64{
+		"E": {"USD": 20, "INR": -50},
+		"D": {"USD": 20, "INR": -50},
+		"C": {"USD": -20, "INR": -50},
+		"B": {"USD": -20, "INR": 100},
+		"A": {"INR": 50},
+	}
+	expected := []models.Settlement{
+		{FromUserID: "C", ToUserID: "B", Amount: 50, Currency: "INR"},
+		{FromUserID: "D", ToUserID: "A", Amount: 50, Currency: "INR"},
+		{FromUserID: "E", ToUserID: "B", Amount: 50, Currency: "INR"},
+		{FromUserID: "B", ToUserID: "D", Amount: 20, Currency: "USD"},
+		{FromUserID: "C", ToUserID: "E", Amount: 20, Currency: "USD"},
+	}
+
+	s := NewSettlementService(&mockExpenseRepo{balances: balances}, &mockGroupRepo{})
+	for run := 0; run < 50; run++ {
+		settlements, err := s.CalculateSettlements(context.Background(), "group1", "user1", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(settlements) != len(expected) {
+			t.Fatalf("run %d: got %d settlements, want %d: %+v", run, len(settlements), len(expected), settlements)
+		}
+		for i := range expected {
+			if settlements[i] != expected[i] {
+				t.Fatalf("run %d: settlement %d = %+v, want %+v", run, i, settlements[i], expected[i])
+			}
+		}
+	}
+}
+
+func TestCalculateSettlementsPairwise(t *testing.T) {
+	// A covered B and B covered C: minimal transfers would have C pay A
+	// directly, while pairwise keeps both debts.
+	balances := map[string]map[string]float64{
+		"A": {"INR": 100},
+		"B": {"INR": 0},
+		"C": {"INR": -100},
+	}
+	pairs := []models.PairBalance{
+		{Currency: "INR", UserA: "A", UserB: "B", Net: 100},
+		{Currency: "INR", UserA: "B", UserB: "C", Net: 100},
+	}
+
+	tests := []struct {
+		name      string
+		group     models.SettlementAlgorithm
+		requested models.SettlementAlgorithm
+		rounding  int
+		expected  []models.Settlement
+		wantErr   bool
+	}{
+		{
+			name:     "Group default",
+			expected: []models.Settlement{{FromUserID: "C", ToUserID: "A", Amount: 100, Currency: "INR"}},
+		},
+		{
+			name:  "Group set to pairwise",
+			group: models.SettlementAlgorithmPairwise,
+			expected: []models.Settlement{
+				{FromUserID: "B", ToUserID: "A", Amount: 100, Currency: "INR"},
+				{FromUserID: "C", ToUserID: "B", Amount: 100, Currency: "INR"},
+			},
+		},
+		{
+			name:      "Request overrides group",
+			group:     models.SettlementAlgorithmPairwise,
+			requested: models.SettlementAlgorithmMinTransfers,
+			expected:  []models.Settlement{{FromUserID: "C", ToUserID: "A", Amount: 100, Currency: "INR"}},
+		},
+		{
+			name:      "Unknown algorithm",
+			requested: "FEWEST_PEOPLE",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSettlementService(&mockExpenseRepo{balances: balances, pairBalances: pairs}, &mockGroupRepo{algorithm: tt.group, rounding: tt.rounding})
+			settlements, err := s.CalculateSettlementsWith(context.Background(), "group1", "user1", nil, tt.requested)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(settlements) != len(tt.expected) {
+				t.Fatalf("got %d settlements, want %d: %+v", len(settlements), len(tt.expected), settlements)
+			}
+			for i := range tt.expected {
+				if settlements[i] != tt.expected[i] {
+					t.Errorf("settlement %d = %+v, want %+v", i, settlements[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPairwiseSettlements(t *testing.T) {
+	tests := []struct {
+		name      string
+		pair      models.PairBalance
+		increment int
+		expected  []models.Settlement
+	}{
+		{"B owes A", models.PairBalance{Currency: "EUR", UserA: "A", UserB: "B", Net: 12.345}, 0, []models.Settlement{{FromUserID: "B", ToUserID: "A", Amount: 12.35, Currency: "EUR"}}},
+		{"A owes B", models.PairBalance{Currency: "EUR", UserA: "A", UserB: "B", Net: -40}, 0, []models.Settlement{{FromUserID: "A", ToUserID: "B", Amount: 40, Currency: "EUR"}}},
+		{"Rounded to increment", models.PairBalance{Currency: "INR", UserA: "A", UserB: "B", Net: 276}, 10, []models.Settlement{{FromUserID: "B", ToUserID: "A", Amount: 280, Currency: "INR"}}},
+		{"Rounds to nothing", models.PairBalance{Currency: "INR", UserA: "A", UserB: "B", Net: 4}, 10, []models.Settlement{}},
+		{"Even", models.PairBalance{Currency: "INR", UserA: "A", UserB: "B", Net: 0.001}, 0, []models.Settlement{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pairwiseSettlements([]models.PairBalance{tt.pair}, tt.increment)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("pairwiseSettlements() = %+v, expected %+v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("pairwiseSettlements()[%d] = %+v, expected %+v", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+// asOfExpenseRepo records the as_of each balance query was asked for.
+type asOfExpenseRepo struct {
+	mockExpenseRepo
+	memberAsOf []*time.Time
+	pairAsOf   []*time.Time
+}
+
+func (m *asOfExpenseRepo) GetGroupMemberBalances(ctx context.Context, groupID string, asOf *time.Time) (map[string]map[string]float64, error) {
+	m.memberAsOf = append(m.memberAsOf, asOf)
+	return m.mockExpenseRepo.GetGroupMemberBalances(ctx, groupID, asOf)
+}
+
+func (m *asOfExpenseRepo) GetGroupPairBalances(ctx context.Context, groupID string, asOf *time.Time) ([]models.PairBalance, error) {
+	m.pairAsOf = append(m.pairAsOf, asOf)
+	return m.mockExpenseRepo.GetGroupPairBalances(ctx, groupID, asOf)
+}
+
+func TestCalculateSettlementsPassesAsOf(t *testing.T) {
+	asOf := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		algorithm   models.SettlementAlgorithm
+		asOf        *time.Time
+		expectPairs bool
+	}{
+		{name: "Min Transfers", algorithm: models.SettlementAlgorithmMinTransfers, asOf: &asOf},
+		{name: "Pairwise", algorithm: models.SettlementAlgorithmPairwise, asOf: &asOf, expectPairs: true},
+		{name: "Without As Of", algorithm: models.SettlementAlgorithmMinTransfers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &asOfExpenseRepo{mockExpenseRepo: mockExpenseRepo{balances: map[string]map[string]float64{}}}
+			s := NewSettlementService(repo, &mockGroupRepo{})
+
+			if _, err := s.CalculateSettlementsWith(context.Background(), "group1", "user1", tt.asOf, tt.algorithm); err != nil {
+				t.Fatalf("CalculateSettlementsWith() error = %v", err)
+			}
+			queried, other := repo.memberAsOf, repo.pairAsOf
+			if tt.expectPairs {
+				queried, other = repo.pairAsOf, repo.memberAsOf
+			}
+			if len(queried) != 1 || queried[0] != tt.asOf || len(other) != 0 {
+				t.Errorf("balances queried as of %v (other query %v), expected once as of %v", queried, other, tt.asOf)
+			}
+		})
+	}
+}
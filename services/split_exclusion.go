@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+
+	"go.uber.org/zap"
+)
+
+// RequestExclusion flags userID's split of an expense as "not for me". The
+// flag waits for the expense's creator, who is notified, to accept or reject
+// it. Flagging a split that is already pending is a no-op.
+func (s *expenseService) RequestExclusion(ctx context.Context, expenseID, userID, reason string) (*models.Expense, error) {
+	expense, err := s.getExpenseForExclusion(ctx, expenseID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	split := findSplit(expense.Splits, userID)
+	if split == nil {
+		return nil, apperrors.InvalidRequest("You are not part of this expense's split.")
+	}
+	if len(expense.Splits) == 1 {
+		return nil, apperrors.InvalidRequest("You are the only participant in this expense. Ask whoever added it to edit or delete it.")
+	}
+	if expense.LockedSplits {
+		return nil, apperrors.ExpenseSplitsLocked()
+	}
+	if split.ExclusionStatus != nil {
+		switch *split.ExclusionStatus {
+		case models.SplitExclusionPending:
+			return s.GetByID(ctx, expenseID, userID)
+		case models.SplitExclusionRejected:
+			return nil, apperrors.Conflict("Your exclusion request for this expense was already rejected.")
+		}
+	}
+
+	var reasonPtr *string
+	if reason = strings.TrimSpace(reason); reason != "" {
+		reasonPtr = &reason
+	}
+	name := s.memberName(ctx, expense.GroupID, userID)
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		status := models.SplitExclusionPending
+		if err := s.expenseRepo.WithTx(q).SetSplitExclusion(ctx, expenseID, userID, &status, reasonPtr); err != nil {
+			return apperrors.DatabaseError("flagging split", err)
+		}
+		return s.recordExpenseActivity(ctx, q, expense, userID, models.GroupActivityExclusionRequested,
+			fmt.Sprintf("%s says they weren't part of '%s'", name, expense.Description))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("Split exclusion requested", zap.String("expense_id", expenseID), zap.String("user_id", userID))
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:      models.NotificationEventExclusion,
+		GroupID:    expense.GroupID,
+		ExpenseID:  expenseID,
+		ActorID:    userID,
+		Template:   notifyExclusionRequested,
+		Args:       []interface{}{name, expense.Description},
+		Recipients: exclusionResolvers(expense),
+	})
+	return s.GetByID(ctx, expenseID, userID)
+}
+
+// ResolveExclusion accepts or rejects participantID's pending exclusion.
+// Accepting removes their split and re-splits the expense among the remaining
+// participants; rejecting keeps the split and stops them flagging it again
+// until the splits are edited.
+func (s *expenseService) ResolveExclusion(ctx context.Context, expenseID, userID, participantID string, accept bool) (*models.Expense, error) {
+	expense, err := s.getExpenseForExclusion(ctx, expenseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.admins[userID] && !canEditExpense(models.ExpenseEditPolicyCreator, expense, userID) {
+		return nil, apperrors.ExclusionResolveNotAllowed()
+	}
+
+	split := findSplit(expense.Splits, participantID)
+	if split == nil || split.ExclusionStatus == nil || *split.ExclusionStatus != models.SplitExclusionPending {
+		return nil, apperrors.NotFound("Exclusion request")
+	}
+
+	var remaining []models.ExpenseSplit
+	if accept {
+		if expense.LockedSplits {
+			return nil, apperrors.ExpenseSplitsLocked()
+		}
+		if len(expense.ReceiptItems) > 0 {
+			return nil, apperrors.InvalidRequest("Itemized expenses must be edited to take someone off their items.")
+		}
+		refunded, err := s.expenseRepo.GetRefundedAmount(ctx, expenseID)
+		if err != nil {
+			return nil, apperrors.DatabaseError("getting refunded amount", err)
+		}
+		if refunded > 0 {
+			return nil, apperrors.InvalidRequest("Expenses with refunds must be edited to remove a participant.")
+		}
+		remaining, err = resplitWithout(expense, participantID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	participantName := s.memberName(ctx, expense.GroupID, participantID)
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		txRepo := s.expenseRepo.WithTx(q)
+		if !accept {
+			status := models.SplitExclusionRejected
+			if err := txRepo.SetSplitExclusion(ctx, expenseID, participantID, &status, split.ExclusionReason); err != nil {
+				return apperrors.DatabaseError("rejecting split exclusion", err)
+			}
+			return s.recordExpenseActivity(ctx, q, expense, userID, models.GroupActivityExclusionRejected,
+				fmt.Sprintf("%s stays on '%s'", participantName, expense.Description))
+		}
+
+		before, err := snapshotBalanceContributions(ctx, s.balanceEventRepo, q, expenseID)
+		if err != nil {
+			return err
+		}
+		if err := txRepo.ExcludeFromSplit(ctx, expenseID, participantID, remaining); err != nil {
+			return apperrors.DatabaseError("re-splitting expense", err)
+		}
+		if err := recordBalanceEvents(ctx, s.balanceEventRepo, q, models.BalanceEventTransactionUpdated, expenseID, before); err != nil {
+			return err
+		}
+		return s.recordExpenseActivity(ctx, q, expense, userID, models.GroupActivityExclusionAccepted,
+			fmt.Sprintf("%s was removed from '%s'", participantName, expense.Description))
+	})
+	if err != nil {
+		zap.L().Error("Failed to resolve split exclusion", zap.String("expense_id", expenseID), zap.Error(err))
+		return nil, err
+	}
+
+	zap.L().Info("Split exclusion resolved",
+		zap.String("expense_id", expenseID),
+		zap.String("participant_id", participantID),
+		zap.Bool("accepted", accept))
+	if accept {
+		checkBalanceAlertsAsync(s.balanceAlertService, expense.GroupID)
+	}
+
+	payload := NotificationPayload{
+		Event:      models.NotificationEventExclusion,
+		GroupID:    expense.GroupID,
+		ExpenseID:  expenseID,
+		ActorID:    userID,
+		Template:   notifyExclusionAccepted,
+		Args:       []interface{}{expense.Description},
+		Recipients: []string{participantID},
+	}
+	if !accept {
+		payload.Template = notifyExclusionRejected
+		payload.Args = []interface{}{s.memberName(ctx, expense.GroupID, userID), expense.Description}
+	}
+	dispatchNotificationAsync(s.notificationService, payload)
+	return s.GetByID(ctx, expenseID, userID)
+}
+
+func (s *expenseService) getExpenseForExclusion(ctx context.Context, expenseID, userID string) (*models.Expense, error) {
+	expense, err := s.expenseRepo.GetByID(ctx, expenseID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.ExpenseNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting expense", err)
+	}
+	if err := RequireGroupMembership(ctx, s.groupRepo, expense.GroupID, userID); err != nil {
+		return nil, err
+	}
+	if expense.Category != models.TransactionCategoryExpense {
+		return nil, apperrors.InvalidRequest("Only expenses can be flagged, not settlements or refunds.")
+	}
+	return expense, nil
+}
+
+// memberName looks up a member's name for activity and notification text,
+// falling back to "Someone" when the lookup fails.
+func (s *expenseService) memberName(ctx context.Context, groupID, userID string) string {
+	return nameOf(s.memberNames(ctx, groupID), userID)
+}
+
+// exclusionResolvers are notified of new exclusion requests: the expense's
+// creator or, for expenses recorded before creators were tracked, its payers.
+func exclusionResolvers(expense *models.Expense) []string {
+	if expense.CreatedByUserID != nil {
+		return []string{*expense.CreatedByUserID}
+	}
+	var payers []string
+	for _, p := range expense.Payers {
+		payers = append(payers, p.UserID)
+	}
+	if len(payers) == 0 && expense.PaidByUserID != nil {
+		payers = append(payers, *expense.PaidByUserID)
+	}
+	return payers
+}
+
+func findSplit(splits []models.ExpenseSplit, userID string) *models.ExpenseSplit {
+	for i := range splits {
+		if splits[i].UserID == userID {
+			return &splits[i]
+		}
+	}
+	return nil
+}
+
+// resplitWithout splits the expense's total among every participant except
+// userID. Equal splits stay equal; percentage and exact splits keep the
+// remaining participants' proportions, scaled up to cover the whole total.
+func resplitWithout(expense *models.Expense, userID string) ([]models.ExpenseSplit, error) {
+	var remaining []models.ExpenseSplit
+	for _, split := range expense.Splits {
+		if split.UserID != userID {
+			remaining = append(remaining, split)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil, apperrors.InvalidRequest("An expense needs at least one other participant.")
+	}
+
+	ids := make([]string, len(remaining))
+	shares := make([]float64, len(remaining))
+	sum := 0.0
+	for i, split := range remaining {
+		ids[i] = split.UserID
+		shares[i] = split.Amount
+		if expense.Type == models.ExpenseTypePercentage && split.Percentage != nil {
+			shares[i] = *split.Percentage
+		}
+		sum += shares[i]
+	}
+	if expense.Type == models.ExpenseTypeEqual || sum <= AmountTolerance {
+		return equalSplits(expense.TotalAmount, ids), nil
+	}
+
+	amounts := distributeProportionally(shares, sum, expense.TotalAmount)
+	var percentages []float64
+	if expense.Type == models.ExpenseTypePercentage {
+		percentages = distributeProportionally(shares, sum, 100)
+	}
+	result := make([]models.ExpenseSplit, len(remaining))
+	for i, split := range remaining {
+		result[i] = models.ExpenseSplit{UserID: split.UserID, Amount: amounts[i]}
+		if percentages != nil {
+			result[i].Percentage = &percentages[i]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestResplitWithout(t *testing.T) {
+	pct := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name            string
+		expense         models.Expense
+		exclude         string
+		wantAmounts     map[string]float64
+		wantPercentages map[string]float64
+		wantErr         bool
+	}{
+		{
+			name: "equal stays equal",
+			expense: models.Expense{Type: models.ExpenseTypeEqual, TotalAmount: 100, Splits: []models.ExpenseSplit{
+				{UserID: "a", Amount: 33.34}, {UserID: "b", Amount: 33.33}, {UserID: "c", Amount: 33.33},
+			}},
+			exclude:     "c",
+			wantAmounts: map[string]float64{"a": 50, "b": 50},
+		},
+		{
+			name: "exact amounts keep proportions",
+			expense: models.Expense{Type: models.ExpenseTypeExactAmount, TotalAmount: 100, Splits: []models.ExpenseSplit{
+				{UserID: "a", Amount: 60}, {UserID: "b", Amount: 20}, {UserID: "c", Amount: 20},
+			}},
+			exclude:     "c",
+			wantAmounts: map[string]float64{"a": 75, "b": 25},
+		},
+		{
+			name: "percentages scaled to 100",
+			expense: models.Expense{Type: models.ExpenseTypePercentage, TotalAmount: 90, Splits: []models.ExpenseSplit{
+				{UserID: "a", Amount: 45, Percentage: pct(50)}, {UserID: "b", Amount: 22.5, Percentage: pct(25)}, {UserID: "c", Amount: 22.5, Percentage: pct(25)},
+			}},
+			exclude:         "a",
+			wantAmounts:     map[string]float64{"b": 45, "c": 45},
+			wantPercentages: map[string]float64{"b": 50, "c": 50},
+		},
+		{
+			name: "remaining zero shares split equally",
+			expense: models.Expense{Type: models.ExpenseTypeExactAmount, TotalAmount: 10, Splits: []models.ExpenseSplit{
+				{UserID: "a", Amount: 10}, {UserID: "b", Amount: 0}, {UserID: "c", Amount: 0},
+			}},
+			exclude:     "a",
+			wantAmounts: map[string]float64{"b": 5, "c": 5},
+		},
+		{
+			name: "no one left",
+			expense: models.Expense{Type: models.ExpenseTypeEqual, TotalAmount: 10, Splits: []models.ExpenseSplit{
+				{UserID: "a", Amount: 10},
+			}},
+			exclude: "a",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splits, err := resplitWithout(&tt.expense, tt.exclude)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resplitWithout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(splits) != len(tt.wantAmounts) {
+				t.Fatalf("got %d splits, want %d", len(splits), len(tt.wantAmounts))
+			}
+			total := 0.0
+			for _, split := range splits {
+				if want, ok := tt.wantAmounts[split.UserID]; !ok || math.Abs(split.Amount-want) > AmountTolerance {
+					t.Errorf("split for %q = %v, want %v", split.UserID, split.Amount, want)
+				}
+				if want, ok := tt.wantPercentages[split.UserID]; ok && (split.Percentage == nil || *split.Percentage != want) {
+					t.Errorf("percentage for %q = %v, want %v", split.UserID, split.Percentage, want)
+				}
+				total += split.Amount
+			}
+			if math.Abs(total-tt.expense.TotalAmount) > AmountTolerance {
+				t.Errorf("splits add up to %v, want %v", total, tt.expense.TotalAmount)
+			}
+		})
+	}
+}
+
+func TestExclusionResolvers(t *testing.T) {
+	creator, payer := "creator", "payer"
+
+	withCreator := &models.Expense{CreatedByUserID: &creator, Payers: []models.ExpensePayer{{UserID: payer}}}
+	if got := exclusionResolvers(withCreator); len(got) != 1 || got[0] != creator {
+		t.Errorf("exclusionResolvers() = %v, want the creator", got)
+	}
+
+	legacy := &models.Expense{PaidByUserID: &payer}
+	if got := exclusionResolvers(legacy); len(got) != 1 || got[0] != payer {
+		t.Errorf("exclusionResolvers() = %v, want the payer", got)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+)
+
+type SplitPreferenceService interface {
+	GetPreferences(ctx context.Context, userID, friendID string) ([]models.SplitPreference, error)
+	SetPreference(ctx context.Context, userID, friendID string, groupID *string, userPercentage float64) (*models.SplitPreference, error)
+	DeletePreference(ctx context.Context, userID, friendID string, groupID *string) error
+}
+
+type splitPreferenceService struct {
+	splitPreferenceRepo repository.SplitPreferenceRepository
+	friendRepo          repository.FriendRepository
+	groupRepo           repository.GroupRepository
+	userRepo            repository.UserRepository
+}
+
+func NewSplitPreferenceService(splitPreferenceRepo repository.SplitPreferenceRepository, friendRepo repository.FriendRepository, groupRepo repository.GroupRepository, userRepo repository.UserRepository) SplitPreferenceService {
+	return &splitPreferenceService{
+		splitPreferenceRepo: splitPreferenceRepo,
+		friendRepo:          friendRepo,
+		groupRepo:           groupRepo,
+		userRepo:            userRepo,
+	}
+}
+
+func (s *splitPreferenceService) GetPreferences(ctx context.Context, userID, friendID string) ([]models.SplitPreference, error) {
+	if err := s.requirePair(ctx, userID, friendID, nil); err != nil {
+		return nil, err
+	}
+
+	userAID, userBID := canonicalPair(userID, friendID)
+	preferences, err := s.splitPreferenceRepo.GetForPair(ctx, userAID, userBID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting split preferences", err)
+	}
+	for i := range preferences {
+		orientSplitPreference(&preferences[i], userID)
+	}
+	return preferences, nil
+}
+
+func (s *splitPreferenceService) SetPreference(ctx context.Context, userID, friendID string, groupID *string, userPercentage float64) (*models.SplitPreference, error) {
+	if userPercentage < 0 || userPercentage > 100 || math.IsNaN(userPercentage) {
+		return nil, apperrors.InvalidRequest("Percentage must be between 0 and 100.")
+	}
+	if err := s.requirePair(ctx, userID, friendID, groupID); err != nil {
+		return nil, err
+	}
+
+	userPercentage = math.Round(userPercentage*RoundingFactor) / RoundingFactor
+	userAID, userBID := canonicalPair(userID, friendID)
+	preference := &models.SplitPreference{
+		ID:              uuid.New().String(),
+		UserAID:         userAID,
+		UserBID:         userBID,
+		GroupID:         groupID,
+		UserAPercentage: userPercentage,
+		UpdatedBy:       &userID,
+	}
+	if userAID != userID {
+		preference.UserAPercentage = math.Round((100-userPercentage)*RoundingFactor) / RoundingFactor
+	}
+
+	if err := s.splitPreferenceRepo.Upsert(ctx, preference); err != nil {
+		return nil, apperrors.DatabaseError("saving split preference", err)
+	}
+	orientSplitPreference(preference, userID)
+	return preference, nil
+}
+
+func (s *splitPreferenceService) DeletePreference(ctx context.Context, userID, friendID string, groupID *string) error {
+	if err := s.requirePair(ctx, userID, friendID, groupID); err != nil {
+		return err
+	}
+
+	userAID, userBID := canonicalPair(userID, friendID)
+	deleted, err := s.splitPreferenceRepo.Delete(ctx, userAID, userBID, groupID)
+	if err != nil {
+		return apperrors.DatabaseError("deleting split preference", err)
+	}
+	if !deleted {
+		return apperrors.NotFound("Split preference")
+	}
+	return nil
+}
+
+func (s *splitPreferenceService) requirePair(ctx context.Context, userID, friendID string, groupID *string) error {
+	if userID == friendID {
+		return apperrors.InvalidRequest("You cannot save a split preference with yourself.")
+	}
+	if _, err := s.userRepo.GetByID(ctx, friendID); err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.UserNotFound()
+		}
+		return apperrors.DatabaseError("getting friend", err)
+	}
+
+	if groupID != nil {
+		if err := RequireGroupMembership(ctx, s.groupRepo, *groupID, userID); err != nil {
+			return err
+		}
+		return RequireGroupMembership(ctx, s.groupRepo, *groupID, friendID)
+	}
+
+	isFriend, err := s.friendRepo.IsFriend(ctx, userID, friendID)
+	if err != nil {
+		return apperrors.DatabaseError("checking friendship", err)
+	}
+	if isFriend {
+		return nil
+	}
+	common, err := s.groupRepo.GetCommonGroups(ctx, userID, friendID)
+	if err != nil {
+		return apperrors.DatabaseError("getting common groups", err)
+	}
+	if len(common) == 0 {
+		return apperrors.InvalidRequest("Split preferences can only be saved with friends or fellow group members.")
+	}
+	return nil
+}
+
+func canonicalPair(userID, otherID string) (string, string) {
+	if userID < otherID {
+		return userID, otherID
+	}
+	return otherID, userID
+}
+
+func orientSplitPreference(p *models.SplitPreference, userID string) {
+	p.FriendID = p.UserBID
+	if userID == p.UserBID {
+		p.FriendID = p.UserAID
+	}
+	p.UserPercentage = p.PercentageFor(userID)
+	p.FriendPercentage = math.Round((100-p.UserPercentage)*RoundingFactor) / RoundingFactor
+}
+
+func deriveRatioSplits(total float64, userAID string, userAPercentage float64, userBID string) []models.ExpenseSplit {
+	userBPercentage := math.Round((100-userAPercentage)*RoundingFactor) / RoundingFactor
+	amountA := math.Round(total*userAPercentage/100*RoundingFactor) / RoundingFactor
+	amountB := math.Round((total-amountA)*RoundingFactor) / RoundingFactor
+
+	return []models.ExpenseSplit{
+		{UserID: userAID, Amount: amountA, Percentage: &userAPercentage},
+		{UserID: userBID, Amount: amountB, Percentage: &userBPercentage},
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// The worker records each month's REPAYMENT through the group service, so the
+// balance ledger and balance alerts see it like any other repayment.
+type StandingRepaymentService interface {
+	Create(ctx context.Context, groupID, userID string, standing *models.StandingRepayment) (*models.StandingRepayment, error)
+	GetByGroupID(ctx context.Context, groupID, userID string) ([]models.StandingRepayment, error)
+	Cancel(ctx context.Context, groupID, standingID, userID string) error
+	RunWorker(ctx context.Context)
+}
+
+type standingRepaymentService struct {
+	standingRepo        repository.StandingRepaymentRepository
+	groupRepo           repository.GroupRepository
+	groupService        GroupService
+	notificationService NotificationService
+}
+
+func NewStandingRepaymentService(standingRepo repository.StandingRepaymentRepository, groupRepo repository.GroupRepository, groupService GroupService, notificationService NotificationService) StandingRepaymentService {
+	return &standingRepaymentService{
+		standingRepo:        standingRepo,
+		groupRepo:           groupRepo,
+		groupService:        groupService,
+		notificationService: notificationService,
+	}
+}
+
+// The first repayment is recorded on the next DayOfMonth, today included.
+func (s *standingRepaymentService) Create(ctx context.Context, groupID, userID string, standing *models.StandingRepayment) (*models.StandingRepayment, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+	if standing.PayerID == standing.ReceiverID {
+		return nil, apperrors.CannotSettleToSelf()
+	}
+	if userID != standing.PayerID && userID != standing.ReceiverID {
+		return nil, apperrors.InvalidRequest("You can only set up a standing repayment that you pay or receive.")
+	}
+	other := standing.PayerID
+	if userID == standing.PayerID {
+		other = standing.ReceiverID
+	}
+	isMember, err := isGroupMember(ctx, s.groupRepo, groupID, other)
+	if err != nil {
+		return nil, apperrors.DatabaseError("checking membership", err)
+	}
+	if !isMember {
+		return nil, apperrors.NotGroupMember()
+	}
+
+	standing.Amount = math.Round(standing.Amount*RoundingFactor) / RoundingFactor
+	if standing.Amount <= 0 {
+		return nil, apperrors.InvalidAmount("Amount must be greater than zero.")
+	}
+	if standing.DayOfMonth < 1 || standing.DayOfMonth > MaxStandingRepaymentDay {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("day_of_month must be between 1 and %d.", MaxStandingRepaymentDay))
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting group", err)
+	}
+
+	standing.ID = uuid.New().String()
+	standing.GroupID = groupID
+	standing.CreatedBy = &userID
+	standing.NextRunAt = nextStandingRepaymentRun(time.Now(), standing.DayOfMonth)
+	if err := s.standingRepo.Create(ctx, standing); err != nil {
+		return nil, apperrors.DatabaseError("creating standing repayment", err)
+	}
+
+	zap.L().Info("Standing repayment created",
+		zap.String("standing_repayment_id", standing.ID),
+		zap.String("group_id", groupID),
+		zap.Float64("amount", standing.Amount),
+		zap.Int("day_of_month", standing.DayOfMonth))
+
+	names := memberNamesOf(group.Members)
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:      models.NotificationEventSettlement,
+		GroupID:    groupID,
+		ActorID:    userID,
+		Template:   notifyStandingCreated,
+		Args:       []interface{}{nameOf(names, userID), standing.Amount, groupCurrency(group), nameOf(names, standing.PayerID), nameOf(names, standing.ReceiverID)},
+		Recipients: []string{other},
+	})
+	return standing, nil
+}
+
+func (s *standingRepaymentService) GetByGroupID(ctx context.Context, groupID, userID string) ([]models.StandingRepayment, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+	standing, err := s.standingRepo.GetActiveByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting standing repayments", err)
+	}
+	return standing, nil
+}
+
+func (s *standingRepaymentService) Cancel(ctx context.Context, groupID, standingID, userID string) error {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return err
+	}
+	standing, err := s.standingRepo.GetByID(ctx, standingID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.NotFound("Standing repayment")
+		}
+		return apperrors.DatabaseError("getting standing repayment", err)
+	}
+	if standing.GroupID != groupID || standing.CancelledAt != nil {
+		return apperrors.NotFound("Standing repayment")
+	}
+	if userID != standing.PayerID && userID != standing.ReceiverID {
+		return apperrors.InvalidRequest("Only the payer or receiver can cancel a standing repayment.")
+	}
+
+	cancelled, err := s.standingRepo.Cancel(ctx, standingID, &userID)
+	if err != nil {
+		return apperrors.DatabaseError("cancelling standing repayment", err)
+	}
+	if !cancelled {
+		return apperrors.NotFound("Standing repayment")
+	}
+
+	zap.L().Info("Standing repayment cancelled",
+		zap.String("standing_repayment_id", standingID),
+		zap.String("user_id", userID))
+
+	s.notify(ctx, standing, userID, notifyStandingCancelled, func(names map[string]string, currency string) []interface{} {
+		return []interface{}{nameOf(names, userID), standing.Amount, currency, nameOf(names, standing.PayerID), nameOf(names, standing.ReceiverID)}
+	})
+	return nil
+}
+
+func (s *standingRepaymentService) RunWorker(ctx context.Context) {
+	zap.L().Info("Standing repayment worker started")
+	ticker := time.NewTicker(StandingRepaymentPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("Standing repayment worker stopped")
+			return
+		case <-ticker.C:
+			s.runDue(ctx)
+		}
+	}
+}
+
+func (s *standingRepaymentService) runDue(ctx context.Context) {
+	due, err := s.standingRepo.ClaimDue(ctx, StandingRepaymentBatchSize, StandingRepaymentLease)
+	if err != nil {
+		zap.L().Error("Failed to claim standing repayments", zap.Error(err))
+		return
+	}
+
+	for i := range due {
+		if err := s.run(ctx, &due[i]); err != nil {
+			zap.L().Error("Failed to record standing repayment",
+				zap.String("standing_repayment_id", due[i].ID),
+				zap.String("group_id", due[i].GroupID),
+				zap.Error(err))
+		}
+	}
+}
+
+// A standing repayment whose payer or receiver has left the group is cancelled
+// instead; other failures are retried once the claim lease runs out.
+func (s *standingRepaymentService) run(ctx context.Context, standing *models.StandingRepayment) error {
+	expense, err := s.groupService.CreateRepayment(ctx, standing.GroupID, standing.PayerID, standing.ReceiverID, standing.Amount, StandingRepaymentDescription)
+	if err != nil {
+		if appErr, ok := apperrors.AsAppError(err); ok && appErr.Code == apperrors.CodeNotGroupMember {
+			zap.L().Warn("Cancelling standing repayment for a member who left the group",
+				zap.String("standing_repayment_id", standing.ID))
+			_, cancelErr := s.standingRepo.Cancel(ctx, standing.ID, nil)
+			return cancelErr
+		}
+		return err
+	}
+
+	now := time.Now()
+	next := nextStandingRepaymentRun(now.AddDate(0, 0, 1), standing.DayOfMonth)
+	if err := s.standingRepo.MarkRun(ctx, standing.ID, now, expense.ID, next); err != nil {
+		return err
+	}
+
+	zap.L().Info("Standing repayment recorded",
+		zap.String("standing_repayment_id", standing.ID),
+		zap.String("expense_id", expense.ID),
+		zap.Time("next_run_at", next))
+
+	s.notify(ctx, standing, "", notifyStandingRecorded, func(names map[string]string, currency string) []interface{} {
+		return []interface{}{nameOf(names, standing.PayerID), nameOf(names, standing.ReceiverID), standing.Amount, expense.Currency}
+	})
+	return nil
+}
+
+func (s *standingRepaymentService) notify(ctx context.Context, standing *models.StandingRepayment, actorID string, template notificationTemplate, args func(names map[string]string, currency string) []interface{}) {
+	group, err := s.groupRepo.GetByID(ctx, standing.GroupID)
+	if err != nil {
+		zap.L().Warn("Failed to get group for standing repayment notification",
+			zap.String("group_id", standing.GroupID),
+			zap.Error(err))
+		return
+	}
+	dispatchNotificationAsync(s.notificationService, NotificationPayload{
+		Event:      models.NotificationEventSettlement,
+		GroupID:    standing.GroupID,
+		ActorID:    actorID,
+		Template:   template,
+		Args:       args(memberNamesOf(group.Members), groupCurrency(group)),
+		Recipients: []string{standing.PayerID, standing.ReceiverID},
+	})
+}
+
+func nextStandingRepaymentRun(from time.Time, day int) time.Time {
+	from = from.UTC()
+	today := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	run := time.Date(today.Year(), today.Month(), day, 0, 0, 0, 0, time.UTC)
+	if run.Before(today) {
+		run = run.AddDate(0, 1, 0)
+	}
+	return run
+}
+
+func groupCurrency(group *models.Group) string {
+	if group.DefaultCurrency == "" {
+		return "INR"
+	}
+	return group.DefaultCurrency
+}
+
+func memberNamesOf(members []models.User) map[string]string {
+	names := make(map[string]string, len(members))
+	for _, m := range members {
+		names[m.ID] = m.Name
+	}
+	return names
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextStandingRepaymentRun(t *testing.T) {
+	tests := []struct {
+		name string
+		from time.Time
+		day  int
+		want time.Time
+	}{
+		{"later this month", time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC), 15, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"today", time.Date(2024, 3, 15, 15, 0, 0, 0, time.UTC), 15, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"already passed", time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC), 15, time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)},
+		{"across the year", time.Date(2024, 12, 29, 9, 0, 0, 0, time.UTC), 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"february", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), 28, time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{"other time zone", time.Date(2024, 3, 16, 1, 0, 0, 0, time.FixedZone("IST", 5*3600+1800)), 15, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextStandingRepaymentRun(tt.from, tt.day); !got.Equal(tt.want) {
+				t.Errorf("nextStandingRepaymentRun(%v, %d) = %v, want %v", tt.from, tt.day, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+type StatsService interface {
+	GetFunStats(ctx context.Context, groupID, userID string) (*models.GroupFunStats, error)
+	GetHeatmap(ctx context.Context, groupID, userID string) (*models.GroupHeatmap, error)
+	GetLeaderboard(ctx context.Context, groupID, userID, period string) (*models.GroupLeaderboard, error)
+}
+
+type funStatsCacheEntry struct {
+	day        string
+	computedAt time.Time
+	stats      *models.GroupFunStats
+}
+
+type heatmapCacheEntry struct {
+	computedAt time.Time
+	expiresAt  time.Time
+	heatmap    *models.GroupHeatmap
+}
+
+type statsService struct {
+	groupRepo repository.GroupRepository
+	statsRepo repository.StatsRepository
+	userRepo  repository.UserRepository
+
+	cacheMu sync.Mutex
+	cache   map[string]funStatsCacheEntry
+
+	heatmapMu    sync.Mutex
+	heatmapCache map[string]heatmapCacheEntry
+}
+
+func NewStatsService(groupRepo repository.GroupRepository, statsRepo repository.StatsRepository, userRepo repository.UserRepository) StatsService {
+	return &statsService{
+		groupRepo: groupRepo,
+		statsRepo: statsRepo,
+		userRepo:  userRepo,
+		cache:     make(map[string]funStatsCacheEntry),
+
+		heatmapCache: make(map[string]heatmapCacheEntry),
+	}
+}
+
+func (s *statsService) GetFunStats(ctx context.Context, groupID, userID string) (*models.GroupFunStats, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	day := start.UTC().Format("2006-01-02")
+	if cached := s.getCached(ctx, groupID, day); cached != nil {
+		zap.L().Debug("Serving cached fun stats", zap.String("group_id", groupID))
+		return cached, nil
+	}
+
+	currency, err := s.statsCurrency(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, total, err := s.statsRepo.GetExpenseTotals(ctx, groupID, currency)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting expense totals", err)
+	}
+	biggest, err := s.statsRepo.GetBiggestExpense(ctx, groupID, currency)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting biggest expense", err)
+	}
+	members, err := s.statsRepo.GetMemberPaymentStats(ctx, groupID, currency)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting member payment stats", err)
+	}
+	streak, err := s.statsRepo.GetLongestQuietStreak(ctx, groupID, currency)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting longest quiet streak", err)
+	}
+
+	stats := &models.GroupFunStats{
+		GroupID:            groupID,
+		Currency:           currency,
+		ExpenseCount:       count,
+		TotalSpend:         math.Round(total*RoundingFactor) / RoundingFactor,
+		BiggestExpense:     biggest,
+		LongestQuietStreak: streak,
+		GeneratedAt:        time.Now(),
+	}
+	stats.MostFrequentPayer, stats.MostLikelyToForgetWallet = pickPayerStats(members)
+
+	s.putCached(groupID, day, start, stats)
+	return stats, nil
+}
+
+// statsCurrency is the currency stats are computed in: the group's default.
+func (s *statsService) statsCurrency(ctx context.Context, groupID string) (string, error) {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return "", apperrors.DatabaseError("getting group", err)
+	}
+	if group.DefaultCurrency == "" {
+		return "INR", nil
+	}
+	return group.DefaultCurrency, nil
+}
+
+func (s *statsService) GetHeatmap(ctx context.Context, groupID, userID string) (*models.GroupHeatmap, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := reportSettings(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if cached := s.getCachedHeatmap(ctx, groupID, now); cached != nil {
+		zap.L().Debug("Serving cached spending heatmap", zap.String("group_id", groupID))
+		return startWeekOn(cached, settings.WeekStart), nil
+	}
+
+	currency, err := s.statsCurrency(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	cells, members, err := s.statsRepo.GetSpendingHeatmap(ctx, groupID, currency)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting spending heatmap", err)
+	}
+
+	heatmap := &models.GroupHeatmap{
+		GroupID:     groupID,
+		Currency:    currency,
+		Cells:       fillHeatmapGrid(cells),
+		Members:     rankHeatmapMembers(members),
+		GeneratedAt: now,
+	}
+	s.putCachedHeatmap(groupID, now, heatmap)
+	return startWeekOn(heatmap, settings.WeekStart), nil
+}
+
+// startWeekOn returns the heatmap with its cells reordered to begin on the
+// user's first day of the week. The cached heatmap itself is never changed.
+func startWeekOn(heatmap *models.GroupHeatmap, weekStart models.WeekStart) *models.GroupHeatmap {
+	if weekStart != models.WeekStartSunday || len(heatmap.Cells) != 7*24 {
+		return heatmap
+	}
+	rotated := *heatmap
+	rotated.Cells = append(append([]models.HeatmapCell{}, heatmap.Cells[6*24:]...), heatmap.Cells[:6*24]...)
+	return &rotated
+}
+
+// fillHeatmapGrid returns all 7x24 cells, Monday 00:00 first, with zeros for
+// the hours that had no spending so clients can draw the grid directly.
+func fillHeatmapGrid(cells []models.HeatmapCell) []models.HeatmapCell {
+	grid := make([]models.HeatmapCell, 7*24)
+	for i := range grid {
+		grid[i] = models.HeatmapCell{DayOfWeek: i/24 + 1, Hour: i % 24}
+	}
+	for _, c := range cells {
+		if c.DayOfWeek < 1 || c.DayOfWeek > 7 || c.Hour < 0 || c.Hour > 23 {
+			continue
+		}
+		i := (c.DayOfWeek-1)*24 + c.Hour
+		grid[i].Count += c.Count
+		grid[i].Total = math.Round((grid[i].Total+c.Total)*RoundingFactor) / RoundingFactor
+	}
+	return grid
+}
+
+// rankHeatmapMembers orders members by how many expenses they paid for, then
+// by amount.
+func rankHeatmapMembers(members []models.HeatmapMember) []models.HeatmapMember {
+	for i := range members {
+		members[i].Total = math.Round(members[i].Total*RoundingFactor) / RoundingFactor
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].Count != members[j].Count {
+			return members[i].Count > members[j].Count
+		}
+		return members[i].Total > members[j].Total
+	})
+	return members
+}
+
+// leaderboardPeriods are the periods a leaderboard can cover.
+var leaderboardPeriods = map[string]bool{"week": true, "month": true, "all": true}
+
+func (s *statsService) GetLeaderboard(ctx context.Context, groupID, userID, period string) (*models.GroupLeaderboard, error) {
+	period = strings.ToLower(strings.TrimSpace(period))
+	if period == "" {
+		period = "month"
+	}
+	if !leaderboardPeriods[period] {
+		return nil, apperrors.InvalidRequest("period must be one of: week, month, all.")
+	}
+
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+	currency, err := s.statsCurrency(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := reportSettings(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	since := leaderboardSince(period, now, settings)
+	entries, err := s.statsRepo.GetLeaderboard(ctx, groupID, currency, since)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting leaderboard", err)
+	}
+	for i := range entries {
+		e := &entries[i]
+		e.Paid = math.Round(e.Paid*RoundingFactor) / RoundingFactor
+		e.Consumed = math.Round(e.Consumed*RoundingFactor) / RoundingFactor
+		e.Net = math.Round((e.Paid-e.Consumed)*RoundingFactor) / RoundingFactor
+	}
+
+	return &models.GroupLeaderboard{
+		GroupID:     groupID,
+		Currency:    currency,
+		Period:      period,
+		From:        since,
+		Members:     entries,
+		GeneratedAt: now,
+	}, nil
+}
+
+// leaderboardSince is the start of the user's current week or month, or nil
+// for all time.
+func leaderboardSince(period string, now time.Time, settings models.ReportSettings) *time.Time {
+	if period == "all" {
+		return nil
+	}
+	since := periodStart(settings, period, now)
+	return &since
+}
+
+// pickPayerStats picks the member who paid most often and the member who paid
+// the smallest fraction of their own share. Members with no share are left
+// out of the second pick; they were never in a position to forget a wallet.
+func pickPayerStats(members []models.FunStatMember) (mostFrequent, forgetful *models.FunStatMember) {
+	for i := range members {
+		m := &members[i]
+		if m.AmountOwed > BalanceThreshold {
+			m.PayerRatio = math.Round(m.AmountPaid/m.AmountOwed*RoundingFactor) / RoundingFactor
+		}
+
+		if m.TimesPaid > 0 && (mostFrequent == nil || m.TimesPaid > mostFrequent.TimesPaid ||
+			(m.TimesPaid == mostFrequent.TimesPaid && m.AmountPaid > mostFrequent.AmountPaid)) {
+			mostFrequent = m
+		}
+
+		if m.AmountOwed <= BalanceThreshold {
+			continue
+		}
+		if forgetful == nil || m.PayerRatio < forgetful.PayerRatio ||
+			(m.PayerRatio == forgetful.PayerRatio && m.AmountOwed > forgetful.AmountOwed) {
+			forgetful = m
+		}
+	}
+	return mostFrequent, forgetful
+}
+
+// getCached returns the group's stats for day, unless the request carries a
+// consistency token from a write made after they were computed.
+func (s *statsService) getCached(ctx context.Context, groupID, day string) *models.GroupFunStats {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	entry, ok := s.cache[groupID]
+	if !ok || entry.day != day || !database.FreshEnough(ctx, entry.computedAt) {
+		return nil
+	}
+	return entry.stats
+}
+
+func (s *statsService) putCached(groupID, day string, computedAt time.Time, stats *models.GroupFunStats) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	if len(s.cache) >= FunStatsCacheMaxEntries {
+		for id, entry := range s.cache {
+			if entry.day != day {
+				delete(s.cache, id)
+			}
+		}
+	}
+	if len(s.cache) >= FunStatsCacheMaxEntries {
+		return
+	}
+	s.cache[groupID] = funStatsCacheEntry{day: day, computedAt: computedAt, stats: stats}
+}
+
+func (s *statsService) getCachedHeatmap(ctx context.Context, groupID string, now time.Time) *models.GroupHeatmap {
+	s.heatmapMu.Lock()
+	defer s.heatmapMu.Unlock()
+
+	entry, ok := s.heatmapCache[groupID]
+	if !ok || !now.Before(entry.expiresAt) || !database.FreshEnough(ctx, entry.computedAt) {
+		return nil
+	}
+	return entry.heatmap
+}
+
+func (s *statsService) putCachedHeatmap(groupID string, now time.Time, heatmap *models.GroupHeatmap) {
+	s.heatmapMu.Lock()
+	defer s.heatmapMu.Unlock()
+
+	if len(s.heatmapCache) >= HeatmapCacheMaxEntries {
+		for id, entry := range s.heatmapCache {
+			if !now.Before(entry.expiresAt) {
+				delete(s.heatmapCache, id)
+			}
+		}
+	}
+	if len(s.heatmapCache) >= HeatmapCacheMaxEntries {
+		return
+	}
+	s.heatmapCache[groupID] = heatmapCacheEntry{computedAt: now, expiresAt: now.Add(HeatmapCacheTTL), heatmap: heatmap}
+}
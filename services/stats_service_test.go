@@ -0,0 +1,173 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"unwise-backend/models"
+)
+
+func TestPickPayerStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		members       []models.FunStatMember
+		wantFrequent  string
+		wantForgetful string
+	}{
+		{
+			name:          "no expenses",
+			members:       []models.FunStatMember{{UserID: "a"}, {UserID: "b"}},
+			wantFrequent:  "",
+			wantForgetful: "",
+		},
+		{
+			name: "frequent payer and lowest ratio differ",
+			members: []models.FunStatMember{
+				{UserID: "a", TimesPaid: 5, AmountPaid: 300, AmountOwed: 200},
+				{UserID: "b", TimesPaid: 1, AmountPaid: 100, AmountOwed: 150},
+				{UserID: "c", TimesPaid: 0, AmountPaid: 0, AmountOwed: 50},
+			},
+			wantFrequent:  "a",
+			wantForgetful: "c",
+		},
+		{
+			name: "ties broken by amount",
+			members: []models.FunStatMember{
+				{UserID: "a", TimesPaid: 2, AmountPaid: 40, AmountOwed: 100},
+				{UserID: "b", TimesPaid: 2, AmountPaid: 80, AmountOwed: 200},
+			},
+			wantFrequent:  "b",
+			wantForgetful: "b",
+		},
+		{
+			name: "members without a share are skipped",
+			members: []models.FunStatMember{
+				{UserID: "a", TimesPaid: 1, AmountPaid: 90, AmountOwed: 45},
+				{UserID: "b"},
+				{UserID: "c", TimesPaid: 1, AmountPaid: 10, AmountOwed: 55},
+			},
+			wantFrequent:  "a",
+			wantForgetful: "c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frequent, forgetful := pickPayerStats(tt.members)
+			if got := idOf(frequent); got != tt.wantFrequent {
+				t.Errorf("most frequent payer = %q, want %q", got, tt.wantFrequent)
+			}
+			if got := idOf(forgetful); got != tt.wantForgetful {
+				t.Errorf("most likely to forget wallet = %q, want %q", got, tt.wantForgetful)
+			}
+		})
+	}
+}
+
+func idOf(m *models.FunStatMember) string {
+	if m == nil {
+		return ""
+	}
+	return m.UserID
+}
+
+func TestFillHeatmapGrid(t *testing.T) {
+	grid := fillHeatmapGrid([]models.HeatmapCell{
+		{DayOfWeek: 1, Hour: 0, Count: 2, Total: 10.005},
+		{DayOfWeek: 5, Hour: 20, Count: 3, Total: 450},
+		{DayOfWeek: 7, Hour: 23, Count: 1, Total: 99.99},
+		{DayOfWeek: 0, Hour: 12, Count: 9, Total: 1000},
+	})
+
+	if len(grid) != 168 {
+		t.Fatalf("len(grid) = %d, want 168", len(grid))
+	}
+	tests := []struct {
+		index     int
+		dayOfWeek int
+		hour      int
+		count     int
+		total     float64
+	}{
+		{index: 0, dayOfWeek: 1, hour: 0, count: 2, total: 10.01},
+		{index: 1, dayOfWeek: 1, hour: 1},
+		{index: 4*24 + 20, dayOfWeek: 5, hour: 20, count: 3, total: 450},
+		{index: 167, dayOfWeek: 7, hour: 23, count: 1, total: 99.99},
+	}
+	for _, tt := range tests {
+		c := grid[tt.index]
+		if c.DayOfWeek != tt.dayOfWeek || c.Hour != tt.hour || c.Count != tt.count || c.Total != tt.total {
+			t.Errorf("grid[%d] = %+v, want day %d hour %d count %d total %v", tt.index, c, tt.dayOfWeek, tt.hour, tt.count, tt.total)
+		}
+	}
+
+	count := 0
+	for _, c := range grid {
+		count += c.Count
+	}
+	if count != 6 {
+		t.Errorf("grid holds %d expenses, want 6 (out-of-range cells dropped)", count)
+	}
+}
+
+func TestRankHeatmapMembers(t *testing.T) {
+	members := rankHeatmapMembers([]models.HeatmapMember{
+		{UserID: "a", Count: 1, Total: 500},
+		{UserID: "b", Count: 4, Total: 120.456},
+		{UserID: "c", Count: 4, Total: 300},
+	})
+
+	want := []string{"c", "b", "a"}
+	for i, id := range want {
+		if members[i].UserID != id {
+			t.Errorf("members[%d] = %q, want %q", i, members[i].UserID, id)
+		}
+	}
+	if members[1].Total != 120.46 {
+		t.Errorf("members[1].Total = %v, want 120.46", members[1].Total)
+	}
+}
+
+func TestLeaderboardSince(t *testing.T) {
+	// A Wednesday evening in UTC.
+	now := time.Date(2024, 5, 15, 22, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period string
+		want   string
+	}{
+		{period: "week", want: "2024-05-13"},
+		{period: "month", want: "2024-05-01"},
+		{period: "all", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			got := ""
+			if since := leaderboardSince(tt.period, now, models.DefaultReportSettings()); since != nil {
+				got = since.Format("2006-01-02")
+			}
+			if got != tt.want {
+				t.Errorf("leaderboardSince(%q) = %q, want %q", tt.period, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartWeekOn(t *testing.T) {
+	heatmap := &models.GroupHeatmap{Cells: fillHeatmapGrid(nil)}
+
+	if got := startWeekOn(heatmap, models.WeekStartMonday); got != heatmap {
+		t.Error("Monday weeks should return the heatmap unchanged")
+	}
+
+	rotated := startWeekOn(heatmap, models.WeekStartSunday)
+	if first := rotated.Cells[0]; first.DayOfWeek != 7 || first.Hour != 0 {
+		t.Errorf("first cell = day %d hour %d, want Sunday 00:00", first.DayOfWeek, first.Hour)
+	}
+	if last := rotated.Cells[len(rotated.Cells)-1]; last.DayOfWeek != 6 || last.Hour != 23 {
+		t.Errorf("last cell = day %d hour %d, want Saturday 23:00", last.DayOfWeek, last.Hour)
+	}
+	if heatmap.Cells[0].DayOfWeek != 1 {
+		t.Error("rotating changed the cached heatmap")
+	}
+}
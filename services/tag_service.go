@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+)
+
+type TagService interface {
+	GetGroupTags(ctx context.Context, groupID, userID string) (*models.GroupTagsResponse, error)
+	DeleteTag(ctx context.Context, groupID, tagID, userID string) error
+}
+
+type tagService struct {
+	tagRepo   repository.TagRepository
+	groupRepo repository.GroupRepository
+}
+
+func NewTagService(tagRepo repository.TagRepository, groupRepo repository.GroupRepository) TagService {
+	return &tagService{
+		tagRepo:   tagRepo,
+		groupRepo: groupRepo,
+	}
+}
+
+func (s *tagService) GetGroupTags(ctx context.Context, groupID, userID string) (*models.GroupTagsResponse, error) {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagRepo.GetByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting tags", err)
+	}
+
+	totals, err := s.tagRepo.GetTotalsByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting tag totals", err)
+	}
+	for i := range totals {
+		totals[i].Total = math.Round(totals[i].Total*RoundingFactor) / RoundingFactor
+	}
+
+	return &models.GroupTagsResponse{Tags: tags, Totals: totals}, nil
+}
+
+func (s *tagService) DeleteTag(ctx context.Context, groupID, tagID, userID string) error {
+	if err := RequireGroupMembership(ctx, s.groupRepo, groupID, userID); err != nil {
+		return err
+	}
+
+	tag, err := s.tagRepo.GetByID(ctx, tagID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return apperrors.NotFound("Tag")
+		}
+		return apperrors.DatabaseError("getting tag", err)
+	}
+	if tag.GroupID != groupID {
+		return apperrors.NotFound("Tag")
+	}
+
+	if err := s.tagRepo.Delete(ctx, tagID); err != nil {
+		return apperrors.DatabaseError("deleting tag", err)
+	}
+	return nil
+}
+
+func normalizeTagNames(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+		if name == "" || seen[name] {
+			continue
+		}
+		if len([]rune(name)) > MaxTagLength {
+			return nil, apperrors.InvalidRequest(fmt.Sprintf("Tag '%s' is too long. Tags can be at most %d characters.", name, MaxTagLength))
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	if len(normalized) > MaxTagsPerExpense {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("An expense can have at most %d tags.", MaxTagsPerExpense))
+	}
+	return normalized, nil
+}
+
+func matchesAllTags(expenseTags, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	have := make(map[string]bool, len(expenseTags))
+	for _, t := range expenseTags {
+		have[t] = true
+	}
+	for _, t := range required {
+		if !have[t] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+)
+
+func TestNormalizeTagNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []string
+		expected    []string
+		expectError bool
+	}{
+		{name: "Lowercased And Spaces Collapsed", input: []string{"  Road   Trip ", "FOOD"}, expected: []string{"road trip", "food"}},
+		{name: "Duplicates And Blanks Dropped", input: []string{"food", " Food", "", "   "}, expected: []string{"food"}},
+		{name: "Length Counts Runes", input: []string{strings.Repeat("é", MaxTagLength)}, expected: []string{strings.Repeat("é", MaxTagLength)}},
+		{name: "Too Long", input: []string{strings.Repeat("a", MaxTagLength+1)}, expectError: true},
+		{name: "Too Many", input: numberedTags(MaxTagsPerExpense + 1), expectError: true},
+		{name: "Duplicates Do Not Count Toward Limit", input: append(numberedTags(MaxTagsPerExpense), "tag 0"), expected: numberedTags(MaxTagsPerExpense)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTagNames(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("normalizeTagNames() error = %v, expectError %v", err, tt.expectError)
+			}
+			if !tt.expectError && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("normalizeTagNames() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func numberedTags(n int) []string {
+	tags := make([]string, n)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag %d", i)
+	}
+	return tags
+}
+
+func TestMatchesAllTags(t *testing.T) {
+	tests := []struct {
+		tags     []string
+		required []string
+		expected bool
+	}{
+		{[]string{"food"}, nil, true},
+		{nil, []string{"food"}, false},
+		{[]string{"food", "trip"}, []string{"trip", "food"}, true},
+		{[]string{"food"}, []string{"food", "trip"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesAllTags(tt.tags, tt.required); got != tt.expected {
+			t.Errorf("matchesAllTags(%v, %v) = %v, expected %v", tt.tags, tt.required, got, tt.expected)
+		}
+	}
+}
+
+type deletingTagRepo struct {
+	stubTagRepository
+	tags    map[string]*models.Tag
+	deleted []string
+}
+
+func (r *deletingTagRepo) GetByID(_ context.Context, id string) (*models.Tag, error) {
+	if tag, ok := r.tags[id]; ok {
+		return tag, nil
+	}
+	return nil, fmt.Errorf("getting tag: no rows in result set")
+}
+
+func (r *deletingTagRepo) Delete(_ context.Context, id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func TestDeleteTagOnlyInItsGroup(t *testing.T) {
+	repo := &deletingTagRepo{tags: map[string]*models.Tag{"t1": {ID: "t1", GroupID: "g1"}}}
+	s := NewTagService(repo, &mockGroupRepo{})
+
+	err := s.DeleteTag(context.Background(), "g2", "t1", "alice")
+	if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.NotFound("Tag").Code {
+		t.Errorf("DeleteTag() from another group error = %v, expected not found", err)
+	}
+	if err := s.DeleteTag(context.Background(), "g1", "t1", "alice"); err != nil {
+		t.Fatalf("DeleteTag() error = %v", err)
+	}
+	if !reflect.DeepEqual(repo.deleted, []string{"t1"}) {
+		t.Errorf("deleted = %v, expected [t1]", repo.deleted)
+	}
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// taxPresets are the tax setups a group can use. Components are listed in
+// the order clients should show them.
+var taxPresets = []models.TaxPresetInfo{
+	{
+		ID:   models.TaxPresetIndiaGST,
+		Name: "India GST",
+		Components: []models.TaxComponent{
+			{Key: "cgst", Label: "CGST"},
+			{Key: "sgst", Label: "SGST"},
+			{Key: "igst", Label: "IGST"},
+			{Key: "cess", Label: "Cess"},
+		},
+	},
+	{
+		ID:         models.TaxPresetUSSalesTax,
+		Name:       "US sales tax",
+		Components: []models.TaxComponent{{Key: "sales_tax", Label: "Sales tax"}},
+	},
+	{
+		ID:         models.TaxPresetEUVAT,
+		Name:       "EU VAT",
+		Components: []models.TaxComponent{{Key: "vat", Label: "VAT"}},
+	},
+	{
+		ID:         models.TaxPresetGeneric,
+		Name:       "Generic tax",
+		Components: []models.TaxComponent{{Key: "tax", Label: "Tax"}},
+	},
+}
+
+func findTaxPreset(id models.TaxPreset) (models.TaxPresetInfo, bool) {
+	for _, p := range taxPresets {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return models.TaxPresetInfo{}, false
+}
+
+// taxPresetForCurrency picks the preset a group in currency most likely
+// wants, as migration 059 did for existing groups.
+func taxPresetForCurrency(currency string) models.TaxPreset {
+	switch strings.ToUpper(currency) {
+	case "INR":
+		return models.TaxPresetIndiaGST
+	case "USD":
+		return models.TaxPresetUSSalesTax
+	case "EUR":
+		return models.TaxPresetEUVAT
+	default:
+		return models.TaxPresetGeneric
+	}
+}
+
+// taxComponentsFromTotals splits the flat tax fields into preset's
+// components, for clients and receipts that only send totals. Under India
+// GST, tax not covered by CGST and SGST is IGST on an interstate bill and
+// cess otherwise.
+func taxComponentsFromTotals(preset models.TaxPreset, tax, cgst, sgst float64) map[string]float64 {
+	components := make(map[string]float64)
+	if preset == models.TaxPresetIndiaGST {
+		if cgst > 0 {
+			components["cgst"] = roundCents(cgst)
+		}
+		if sgst > 0 {
+			components["sgst"] = roundCents(sgst)
+		}
+		if rest := roundCents(tax - cgst - sgst); rest > 0 {
+			if cgst+sgst == 0 {
+				components["igst"] = rest
+			} else {
+				components["cess"] = rest
+			}
+		}
+	} else if info, ok := findTaxPreset(preset); ok {
+		total := tax
+		if total == 0 {
+			total = cgst + sgst
+		}
+		if total = roundCents(total); total > 0 {
+			components[info.Components[0].Key] = total
+		}
+	}
+	if len(components) == 0 {
+		return nil
+	}
+	return components
+}
+
+// ReceiptTaxComponents maps a parsed receipt's taxes onto a preset: the
+// group's when the scan is for a group, otherwise the one the receipt's
+// currency suggests.
+func ReceiptTaxComponents(result *models.ReceiptParseResult, groupPreset models.TaxPreset) (models.TaxPreset, map[string]float64) {
+	preset := groupPreset
+	if preset == "" {
+		preset = taxPresetForCurrency(result.Currency)
+	}
+	return preset, taxComponentsFromTotals(preset, result.Tax, result.CGST, result.SGST)
+}
+
+// applyTaxPreset checks an expense's taxes against its group's preset and
+// fills in both shapes: tax_components, and the flat tax, cgst and sgst
+// columns older clients read. Explicit components win; otherwise they are
+// derived from the flat fields. CGST and SGST are kept only under India GST.
+func applyTaxPreset(preset models.TaxPreset, expense *models.Expense) error {
+	info, ok := findTaxPreset(preset)
+	if !ok {
+		info, _ = findTaxPreset(models.TaxPresetIndiaGST)
+	}
+	if expense.Tax < 0 || expense.CGST < 0 || expense.SGST < 0 {
+		return apperrors.InvalidRequest("Taxes can't be negative.")
+	}
+
+	if len(expense.TaxComponents) == 0 {
+		expense.TaxComponents = taxComponentsFromTotals(info.ID, expense.Tax, expense.CGST, expense.SGST)
+	} else {
+		allowed := make(map[string]bool, len(info.Components))
+		keys := make([]string, len(info.Components))
+		for i, c := range info.Components {
+			allowed[c.Key] = true
+			keys[i] = c.Key
+		}
+		sum := 0.0
+		for key, amount := range expense.TaxComponents {
+			if !allowed[key] {
+				return apperrors.InvalidRequest(fmt.Sprintf("tax_components for %s can only use: %s.", info.Name, strings.Join(keys, ", ")))
+			}
+			if amount < 0 {
+				return apperrors.InvalidRequest("Taxes can't be negative.")
+			}
+			expense.TaxComponents[key] = roundCents(amount)
+			sum += amount
+		}
+		sum = roundCents(sum)
+		if expense.Tax == 0 {
+			expense.Tax = sum
+		} else if math.Abs(roundCents(expense.Tax)-sum) > AmountTolerance {
+			return apperrors.InvalidRequest(fmt.Sprintf("tax (%.2f) must equal the sum of tax_components (%.2f).", expense.Tax, sum))
+		}
+	}
+
+	if info.ID == models.TaxPresetIndiaGST {
+		expense.CGST = expense.TaxComponents["cgst"]
+		expense.SGST = expense.TaxComponents["sgst"]
+	} else {
+		expense.CGST, expense.SGST = 0, 0
+	}
+	if expense.Tax == 0 {
+		for _, amount := range expense.TaxComponents {
+			expense.Tax += amount
+		}
+		expense.Tax = roundCents(expense.Tax)
+	}
+	return nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*RoundingFactor) / RoundingFactor
+}
+
+// groupTaxPreset returns the group's tax preset, falling back to India GST,
+// the column default, when it cannot be read.
+func groupTaxPreset(ctx context.Context, groupRepo repository.GroupRepository, groupID string) models.TaxPreset {
+	preset, err := groupRepo.GetTaxPreset(ctx, groupID)
+	if err != nil {
+		zap.L().Warn("Failed to get group tax preset, using the default", zap.String("group_id", groupID), zap.Error(err))
+		return models.TaxPresetIndiaGST
+	}
+	return preset
+}
+
+func (s *groupService) GetTaxPresets(ctx context.Context) []models.TaxPresetInfo {
+	return taxPresets
+}
+
+// UpdateTaxPreset sets which tax fields the group's expenses use. Existing
+// expenses keep their tax_components; the preset applies as they are edited.
+func (s *groupService) UpdateTaxPreset(ctx context.Context, groupID, userID string, preset models.TaxPreset) (*models.Group, error) {
+	if err := s.requireMembership(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	preset = models.TaxPreset(strings.ToUpper(strings.TrimSpace(string(preset))))
+	if preset == "" {
+		return nil, apperrors.MissingRequiredField("tax_preset")
+	}
+	info, ok := findTaxPreset(preset)
+	if !ok {
+		ids := make([]string, len(taxPresets))
+		for i, p := range taxPresets {
+			ids[i] = string(p.ID)
+		}
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("tax_preset must be one of: %s.", strings.Join(ids, ", ")))
+	}
+
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		if err := s.groupRepo.WithTx(q).UpdateTaxPreset(ctx, groupID, preset); err != nil {
+			return apperrors.DatabaseError("updating group tax preset", err)
+		}
+		activity := &models.GroupActivity{
+			ID:      uuid.New().String(),
+			GroupID: groupID,
+			ActorID: &userID,
+			Action:  models.GroupActivityTaxPresetUpdated,
+			Message: fmt.Sprintf("Tax preset set to %s", info.Name),
+		}
+		if err := s.activityRepo.WithTx(q).Create(ctx, activity); err != nil {
+			return apperrors.DatabaseError("recording group activity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetByID(ctx, groupID)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"unwise-backend/models"
+)
+
+func TestTaxComponentsFromTotals(t *testing.T) {
+	tests := []struct {
+		name            string
+		preset          models.TaxPreset
+		tax, cgst, sgst float64
+		expected        map[string]float64
+	}{
+		{name: "No tax", preset: models.TaxPresetIndiaGST},
+		{name: "GST split", preset: models.TaxPresetIndiaGST, tax: 18, cgst: 9, sgst: 9, expected: map[string]float64{"cgst": 9, "sgst": 9}},
+		{name: "GST with cess", preset: models.TaxPresetIndiaGST, tax: 20, cgst: 9, sgst: 9, expected: map[string]float64{"cgst": 9, "sgst": 9, "cess": 2}},
+		{name: "Interstate GST", preset: models.TaxPresetIndiaGST, tax: 18, expected: map[string]float64{"igst": 18}},
+		{name: "Sales tax", preset: models.TaxPresetUSSalesTax, tax: 8.25, expected: map[string]float64{"sales_tax": 8.25}},
+		{name: "VAT from legacy fields", preset: models.TaxPresetEUVAT, cgst: 5, sgst: 5, expected: map[string]float64{"vat": 10}},
+		{name: "Generic", preset: models.TaxPresetGeneric, tax: 3.333, expected: map[string]float64{"tax": 3.33}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := taxComponentsFromTotals(tt.preset, tt.tax, tt.cgst, tt.sgst)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("taxComponentsFromTotals() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyTaxPreset(t *testing.T) {
+	tests := []struct {
+		name        string
+		preset      models.TaxPreset
+		expense     models.Expense
+		expected    models.Expense
+		expectError bool
+	}{
+		{
+			name:     "GST components fill legacy columns",
+			preset:   models.TaxPresetIndiaGST,
+			expense:  models.Expense{TaxComponents: map[string]float64{"cgst": 9, "sgst": 9, "cess": 1}},
+			expected: models.Expense{Tax: 19, CGST: 9, SGST: 9, TaxComponents: map[string]float64{"cgst": 9, "sgst": 9, "cess": 1}},
+		},
+		{
+			name:     "Legacy GST fields become components",
+			preset:   models.TaxPresetIndiaGST,
+			expense:  models.Expense{CGST: 9, SGST: 9},
+			expected: models.Expense{Tax: 18, CGST: 9, SGST: 9, TaxComponents: map[string]float64{"cgst": 9, "sgst": 9}},
+		},
+		{
+			name:     "VAT clears legacy GST fields",
+			preset:   models.TaxPresetEUVAT,
+			expense:  models.Expense{Tax: 21, CGST: 10.5, SGST: 10.5},
+			expected: models.Expense{Tax: 21, TaxComponents: map[string]float64{"vat": 21}},
+		},
+		{
+			name:     "Matching tax total",
+			preset:   models.TaxPresetUSSalesTax,
+			expense:  models.Expense{Tax: 8.25, TaxComponents: map[string]float64{"sales_tax": 8.25}},
+			expected: models.Expense{Tax: 8.25, TaxComponents: map[string]float64{"sales_tax": 8.25}},
+		},
+		{
+			name:        "Component outside preset",
+			preset:      models.TaxPresetUSSalesTax,
+			expense:     models.Expense{TaxComponents: map[string]float64{"cgst": 5}},
+			expectError: true,
+		},
+		{
+			name:        "Tax total mismatch",
+			preset:      models.TaxPresetEUVAT,
+			expense:     models.Expense{Tax: 20, TaxComponents: map[string]float64{"vat": 21}},
+			expectError: true,
+		},
+		{
+			name:        "Negative component",
+			preset:      models.TaxPresetGeneric,
+			expense:     models.Expense{TaxComponents: map[string]float64{"tax": -1}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expense := tt.expense
+			err := applyTaxPreset(tt.preset, &expense)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(expense, tt.expected) {
+				t.Errorf("applyTaxPreset() = %+v, expected %+v", expense, tt.expected)
+			}
+		})
+	}
+}
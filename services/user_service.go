@@ -1,45 +1,72 @@
 package services
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"math"
 	"strings"
-	"time"
 
+	"unwise-backend/database"
 	apperrors "unwise-backend/errors"
 	"unwise-backend/models"
 	"unwise-backend/repository"
+	"unwise-backend/supabase"
 
 	"go.uber.org/zap"
 )
 
 type UserService interface {
 	DeleteAccount(ctx context.Context, userID string) error
-	EnsureUser(ctx context.Context, userID, email, name string) (*models.User, error)
+	GetDeletionBlockers(ctx context.Context, userID string) (*models.DeletionBlockers, error)
+	EnsureUser(ctx context.Context, userID, email, name string, emailVerified *bool) (*models.User, error)
+	Bootstrap(ctx context.Context, userID, email, name string, emailVerified *bool) (*models.BootstrapResponse, error)
+	RefreshEmailVerified(ctx context.Context, userID string, verified bool) error
+	RequireVerifiedEmail(ctx context.Context, userID string, emailVerified *bool, action string) error
 	UpdateAvatar(ctx context.Context, userID, avatarURL string) (*models.User, error)
 	GetUser(ctx context.Context, userID string) (*models.User, error)
-	GetClaimablePlaceholders(ctx context.Context, userID string) ([]models.User, error)
-	ClaimPlaceholder(ctx context.Context, userID, placeholderID string) error
+	GetPrivacySettings(ctx context.Context, userID string) (*models.PrivacySettings, error)
+	UpdatePrivacySettings(ctx context.Context, userID string, settings *models.PrivacySettings) (*models.PrivacySettings, error)
+	GetReportSettings(ctx context.Context, userID string) (*models.ReportSettings, error)
+	UpdateReportSettings(ctx context.Context, userID string, settings *models.ReportSettings) (*models.ReportSettings, error)
+	GetBalanceAlertSettings(ctx context.Context, userID string) (*models.BalanceAlertSettings, error)
+	UpdateBalanceAlertSettings(ctx context.Context, userID string, settings *models.BalanceAlertSettings) (*models.BalanceAlertSettings, error)
+	GetClaimablePlaceholders(ctx context.Context, userID string) ([]models.ClaimablePlaceholder, error)
+	ClaimPlaceholder(ctx context.Context, userID, placeholderID string) (*models.PlaceholderClaimRequest, error)
 	AssignPlaceholder(ctx context.Context, placeholderID, targetUserID string) error
+	GetPendingPlaceholderClaims(ctx context.Context) ([]models.PlaceholderClaimRequest, error)
+	ApprovePlaceholderClaim(ctx context.Context, adminID, requestID string) error
+	RejectPlaceholderClaim(ctx context.Context, adminID, requestID string) error
+	GetPlaceholderSuggestions(ctx context.Context, userID string) ([]models.PlaceholderMergeSuggestion, error)
+	MergePlaceholders(ctx context.Context, userID string, placeholderIDs []string, targetID string) (*models.PlaceholderMergeResult, error)
 }
 
 type userService struct {
-	userRepo       repository.UserRepository
-	expenseRepo    repository.ExpenseRepository
-	supabaseURL    string
-	serviceRoleKey string
+	userRepo          repository.UserRepository
+	expenseRepo       repository.ExpenseRepository
+	claimRepo         repository.PlaceholderClaimRepository
+	groupRepo         repository.GroupRepository
+	inviteRepo        repository.GroupInviteRepository
+	balanceEventRepo  repository.BalanceEventRepository
+	settlementService SettlementService
+	db                database.TxRunner
+	authAdmin         supabase.AdminClient
+	claimPolicy       string
+	requireVerified   bool
 }
 
-func NewUserService(userRepo repository.UserRepository, expenseRepo repository.ExpenseRepository, supabaseURL, serviceRoleKey string) UserService {
+func NewUserService(userRepo repository.UserRepository, expenseRepo repository.ExpenseRepository, claimRepo repository.PlaceholderClaimRepository, groupRepo repository.GroupRepository, inviteRepo repository.GroupInviteRepository, balanceEventRepo repository.BalanceEventRepository, settlementService SettlementService, db *database.DB, authAdmin supabase.AdminClient, claimPolicy string, requireVerifiedEmail bool) UserService {
 	return &userService{
-		userRepo:       userRepo,
-		expenseRepo:    expenseRepo,
-		supabaseURL:    supabaseURL,
-		serviceRoleKey: serviceRoleKey,
+		userRepo:          userRepo,
+		expenseRepo:       expenseRepo,
+		claimRepo:         claimRepo,
+		groupRepo:         groupRepo,
+		inviteRepo:        inviteRepo,
+		balanceEventRepo:  balanceEventRepo,
+		settlementService: settlementService,
+		db:                db,
+		authAdmin:         authAdmin,
+		claimPolicy:       claimPolicy,
+		requireVerified:   requireVerifiedEmail,
 	}
 }
 
@@ -64,9 +91,9 @@ func (s *userService) UpdateAvatar(ctx context.Context, userID, avatarURL string
 		return nil, apperrors.DatabaseError("updating user avatar", err)
 	}
 
-	if s.supabaseURL != "" && s.serviceRoleKey != "" {
+	if s.authAdmin != nil {
 		go func() {
-			err := s.updateSupabaseMetadata(userID, avatarURL)
+			err := s.authAdmin.UpdateUserMetadata(context.Background(), userID, map[string]interface{}{"avatar_url": avatarURL})
 			if err != nil {
 				zap.L().Error("Failed to update Supabase metadata", zap.String("user_id", userID), zap.Error(err))
 			} else {
@@ -78,41 +105,98 @@ func (s *userService) UpdateAvatar(ctx context.Context, userID, avatarURL string
 	return s.userRepo.GetByID(ctx, userID)
 }
 
-func (s *userService) updateSupabaseMetadata(userID, avatarURL string) error {
-	url := fmt.Sprintf("%s/auth/v1/admin/users/%s", strings.TrimSuffix(s.supabaseURL, "/"), userID)
+func (s *userService) GetPrivacySettings(ctx context.Context, userID string) (*models.PrivacySettings, error) {
+	settings, err := s.userRepo.GetPrivacySettings(ctx, userID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting privacy settings", err)
+	}
+	return settings, nil
+}
 
-	data := map[string]interface{}{
-		"user_metadata": map[string]interface{}{
-			"avatar_url": avatarURL,
-		},
+func (s *userService) UpdatePrivacySettings(ctx context.Context, userID string, settings *models.PrivacySettings) (*models.PrivacySettings, error) {
+	settings.Discoverability = models.Discoverability(strings.ToUpper(strings.TrimSpace(string(settings.Discoverability))))
+	switch settings.Discoverability {
+	case models.DiscoverabilityName, models.DiscoverabilityEmail, models.DiscoverabilityNone:
+	default:
+		return nil, apperrors.InvalidRequestWithDetails("Invalid discoverability.", "Allowed values: NAME, EMAIL, NONE")
 	}
 
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return err
+	if err := s.userRepo.UpdatePrivacySettings(ctx, userID, settings); err != nil {
+		return nil, apperrors.DatabaseError("updating privacy settings", err)
 	}
+	zap.L().Info("Updated privacy settings", zap.String("user_id", userID), zap.String("discoverability", string(settings.Discoverability)))
+	return s.GetPrivacySettings(ctx, userID)
+}
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+func (s *userService) GetReportSettings(ctx context.Context, userID string) (*models.ReportSettings, error) {
+	settings, err := s.userRepo.GetReportSettings(ctx, userID)
 	if err != nil {
-		return err
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting report settings", err)
+	}
+	return settings, nil
+}
+
+func (s *userService) UpdateReportSettings(ctx context.Context, userID string, settings *models.ReportSettings) (*models.ReportSettings, error) {
+	settings.WeekStart = models.WeekStart(strings.ToUpper(strings.TrimSpace(string(settings.WeekStart))))
+	switch settings.WeekStart {
+	case models.WeekStartMonday, models.WeekStartSunday:
+	default:
+		return nil, apperrors.InvalidRequestWithDetails("Invalid week_start.", "Allowed values: MONDAY, SUNDAY")
+	}
+	if settings.FiscalMonthStartDay < 1 || settings.FiscalMonthStartDay > MaxFiscalMonthStartDay {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("fiscal_month_start_day must be between 1 and %d.", MaxFiscalMonthStartDay))
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+s.serviceRoleKey)
+	if err := s.userRepo.UpdateReportSettings(ctx, userID, settings); err != nil {
+		return nil, apperrors.DatabaseError("updating report settings", err)
+	}
+	zap.L().Info("Updated report settings",
+		zap.String("user_id", userID),
+		zap.String("week_start", string(settings.WeekStart)),
+		zap.Int("fiscal_month_start_day", settings.FiscalMonthStartDay))
+	return s.GetReportSettings(ctx, userID)
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+func (s *userService) GetBalanceAlertSettings(ctx context.Context, userID string) (*models.BalanceAlertSettings, error) {
+	settings, err := s.userRepo.GetBalanceAlertSettings(ctx, userID)
 	if err != nil {
-		return err
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.UserNotFound()
+		}
+		return nil, apperrors.DatabaseError("getting balance alert settings", err)
 	}
-	defer resp.Body.Close()
+	return settings, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("supabase admin api returned status %d: %s", resp.StatusCode, string(body))
+func (s *userService) UpdateBalanceAlertSettings(ctx context.Context, userID string, settings *models.BalanceAlertSettings) (*models.BalanceAlertSettings, error) {
+	if settings.Threshold == nil {
+		settings.Currency = nil
+	} else {
+		threshold := math.Round(*settings.Threshold*RoundingFactor) / RoundingFactor
+		if threshold <= 0 {
+			return nil, apperrors.InvalidAmount("The alert threshold must be greater than zero.")
+		}
+		if settings.Currency == nil || len(strings.TrimSpace(*settings.Currency)) != 3 {
+			return nil, apperrors.InvalidRequest("Currency code must be 3 characters when a threshold is set.")
+		}
+		currency := strings.ToUpper(strings.TrimSpace(*settings.Currency))
+		settings.Threshold = &threshold
+		settings.Currency = &currency
 	}
 
-	return nil
+	if err := s.userRepo.UpdateBalanceAlertSettings(ctx, userID, settings); err != nil {
+		return nil, apperrors.DatabaseError("updating balance alert settings", err)
+	}
+	zap.L().Info("Updated balance alert settings",
+		zap.String("user_id", userID),
+		zap.Bool("enabled", settings.Threshold != nil))
+	return s.GetBalanceAlertSettings(ctx, userID)
 }
 
 func (s *userService) DeleteAccount(ctx context.Context, userID string) error {
@@ -128,7 +212,12 @@ func (s *userService) DeleteAccount(ctx context.Context, userID string) error {
 		zap.L().Warn("Account deletion rejected: active balance",
 			zap.String("user_id", userID),
 			zap.Int("num_currencies_with_balance", len(totalBalances)))
-		return apperrors.CannotDeleteAccountWithBalance()
+		blockers, err := s.GetDeletionBlockers(ctx, userID)
+		if err != nil {
+			zap.L().Warn("Failed to list deletion blockers", zap.String("user_id", userID), zap.Error(err))
+			return apperrors.CannotDeleteAccountWithBalance("")
+		}
+		return apperrors.CannotDeleteAccountWithBalance(summarizeDeletionBlockers(blockers))
 	}
 
 	if err := s.userRepo.Delete(ctx, userID); err != nil {
@@ -136,22 +225,43 @@ func (s *userService) DeleteAccount(ctx context.Context, userID string) error {
 		return apperrors.DatabaseError("deleting user account", err)
 	}
 
+	// The data is already gone; a leftover auth user only means the next
+	// sign-in starts from an empty account, so this is logged, not returned.
+	if s.authAdmin != nil {
+		if err := s.authAdmin.DeleteUser(ctx, userID); err != nil && !supabase.IsNotFound(err) {
+			zap.L().Error("Failed to delete Supabase auth user", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
+
 	zap.L().Info("Account deleted successfully", zap.String("user_id", userID))
 	return nil
 }
 
-func (s *userService) EnsureUser(ctx context.Context, userID, email, name string) (*models.User, error) {
+// EnsureUser creates the user's record on first sight. emailVerified is the
+// token's verification flag, or nil when the token did not carry one; when
+// set it refreshes the stored flag.
+func (s *userService) EnsureUser(ctx context.Context, userID, email, name string, emailVerified *bool) (*models.User, error) {
 	zap.L().Debug("Ensuring user record exists", zap.String("user_id", userID), zap.String("email", email))
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err == nil {
+		if user.DeletedAt != nil {
+			return nil, apperrors.AccountDeleted()
+		}
+		if emailVerified != nil && (user.EmailVerified == nil || *user.EmailVerified != *emailVerified) {
+			if err := s.RefreshEmailVerified(ctx, userID, *emailVerified); err != nil {
+				return nil, err
+			}
+			user.EmailVerified = emailVerified
+		}
 		return user, nil
 	}
 
 	zap.L().Info("User record not found, creating new record", zap.String("user_id", userID), zap.String("email", email))
 	newUser := &models.User{
-		ID:    userID,
-		Email: email,
-		Name:  name,
+		ID:            userID,
+		Email:         email,
+		Name:          NormalizeName(name),
+		EmailVerified: emailVerified,
 	}
 	if newUser.Name == "" {
 		newUser.Name = email
@@ -166,93 +276,638 @@ func (s *userService) EnsureUser(ctx context.Context, userID, email, name string
 	return newUser, nil
 }
 
-func (s *userService) GetClaimablePlaceholders(ctx context.Context, userID string) ([]models.User, error) {
+func (s *userService) RefreshEmailVerified(ctx context.Context, userID string, verified bool) error {
+	if err := s.userRepo.UpdateEmailVerified(ctx, userID, verified); err != nil {
+		zap.L().Error("Failed to refresh email verification", zap.String("user_id", userID), zap.Error(err))
+		return apperrors.DatabaseError("updating email verification", err)
+	}
+	return nil
+}
+
+// RequireVerifiedEmail rejects action unless userID's email is verified.
+// The token's flag wins over the stored one, since it is the freshest, and
+// is stored on the way. Nothing is enforced when verification is disabled.
+func (s *userService) RequireVerifiedEmail(ctx context.Context, userID string, emailVerified *bool, action string) error {
+	if !s.requireVerified {
+		return nil
+	}
+
+	verified, err := s.isEmailVerified(ctx, userID, emailVerified)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		zap.L().Info("Action blocked until email is verified", zap.String("user_id", userID), zap.String("action", action))
+		return apperrors.EmailNotVerified(action)
+	}
+	return nil
+}
+
+func (s *userService) isEmailVerified(ctx context.Context, userID string, emailVerified *bool) (bool, error) {
+	if emailVerified != nil {
+		if err := s.RefreshEmailVerified(ctx, userID, *emailVerified); err != nil {
+			zap.L().Warn("Continuing with unsaved email verification", zap.String("user_id", userID), zap.Error(err))
+		}
+		return *emailVerified, nil
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil && !apperrors.IsNotFoundError(err) {
+		return false, apperrors.DatabaseError("getting user", err)
+	}
+	return user != nil && user.EmailVerified != nil && *user.EmailVerified, nil
+}
+
+// EmailVerifier gates actions on a verified email. UserService implements it.
+type EmailVerifier interface {
+	RequireVerifiedEmail(ctx context.Context, userID string, emailVerified *bool, action string) error
+}
+
+type emailVerifiedKey struct{}
+
+// WithEmailVerified records the token's email_verified claim, so services can
+// enforce verification without handlers passing it through every call.
+func WithEmailVerified(ctx context.Context, verified bool) context.Context {
+	return context.WithValue(ctx, emailVerifiedKey{}, verified)
+}
+
+func emailVerifiedFrom(ctx context.Context) *bool {
+	verified, ok := ctx.Value(emailVerifiedKey{}).(bool)
+	if !ok {
+		return nil
+	}
+	return &verified
+}
+
+// unverifiedSettlementLimit is the UnverifiedSettlementLimits figure for
+// currency.
+func unverifiedSettlementLimit(currency string) float64 {
+	if limit, ok := UnverifiedSettlementLimits[strings.ToUpper(currency)]; ok {
+		return limit
+	}
+	return UnverifiedSettlementLimits["INR"]
+}
+
+// requireVerifiedForSettlement rejects a settlement of amount in currency
+// above the unverified limit unless userID's email is verified.
+func requireVerifiedForSettlement(ctx context.Context, verifier EmailVerifier, userID string, amount float64, currency string) error {
+	limit := unverifiedSettlementLimit(currency)
+	if verifier == nil || amount <= limit {
+		return nil
+	}
+	return verifier.RequireVerifiedEmail(ctx, userID, emailVerifiedFrom(ctx), fmt.Sprintf("settlements above %.2f %s", limit, strings.ToUpper(currency)))
+}
+
+func (s *userService) Bootstrap(ctx context.Context, userID, email, name string, emailVerified *bool) (*models.BootstrapResponse, error) {
+	user, err := s.EnsureUser(ctx, userID, email, name, emailVerified)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.BootstrapResponse{
+		User:                user,
+		ClaimedPlaceholders: []models.User{},
+		PendingClaims:       []models.PlaceholderClaimRequest{},
+		Invitations:         []models.GroupInvite{},
+	}
+
+	// An email match only proves who someone is once the address is
+	// verified, whatever REQUIRE_VERIFIED_EMAIL says; otherwise anyone could
+	// sign up with another person's address and take over their
+	// placeholders or join the groups they were invited to.
+	verified := false
+	if strings.TrimSpace(user.Email) != "" {
+		if verified, err = s.isEmailVerified(ctx, userID, emailVerified); err != nil {
+			return nil, err
+		}
+		if !verified {
+			zap.L().Info("Skipping email-matched placeholders and invites until email is verified", zap.String("user_id", userID))
+		}
+	}
+
+	if verified {
+		placeholders, err := s.userRepo.GetUnclaimedPlaceholders(ctx, userID, user.Email)
+		if err != nil {
+			return nil, apperrors.DatabaseError("getting unclaimed placeholders", err)
+		}
+		for _, p := range placeholders {
+			if strings.TrimSpace(p.Email) == "" || !strings.EqualFold(strings.TrimSpace(p.Email), strings.TrimSpace(user.Email)) {
+				continue
+			}
+			req, err := s.requestClaim(ctx, p.ID, userID)
+			if err != nil {
+				if appErr, ok := apperrors.AsAppError(err); ok && appErr.Type == apperrors.ErrorTypeConflict {
+					zap.L().Info("Placeholder claimed concurrently during bootstrap", zap.String("placeholder_id", p.ID))
+					continue
+				}
+				return nil, err
+			}
+			if req != nil {
+				response.PendingClaims = append(response.PendingClaims, *req)
+				continue
+			}
+			response.ClaimedPlaceholders = append(response.ClaimedPlaceholders, p)
+		}
+
+	}
+	if verified {
+		invites, err := s.acceptPendingInvites(ctx, userID, user.Email)
+		if err != nil {
+			return nil, err
+		}
+		response.Invitations = append(response.Invitations, invites...)
+	}
+
+	claimable, err := s.GetClaimablePlaceholders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response.ClaimablePlaceholders = claimable
+
+	groups, err := s.groupRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting user groups", err)
+	}
+	response.SuggestSampleGroup = len(groups) == 0
+
+	zap.L().Info("User bootstrapped",
+		zap.String("user_id", userID),
+		zap.Int("claimed_placeholders", len(response.ClaimedPlaceholders)),
+		zap.Int("pending_claims", len(response.PendingClaims)),
+		zap.Int("invitations", len(response.Invitations)))
+	return response, nil
+}
+
+// acceptPendingInvites joins userID to every group that invited email. The
+// caller must have checked that userID owns email.
+func (s *userService) acceptPendingInvites(ctx context.Context, userID, email string) ([]models.GroupInvite, error) {
+	var accepted []models.GroupInvite
+	err := s.db.WithTx(ctx, func(q database.Querier) error {
+		inviteRepo := s.inviteRepo.WithTx(q)
+		groupRepo := s.groupRepo.WithTx(q)
+
+		invites, err := inviteRepo.GetPendingByEmailForUpdate(ctx, email)
+		if err != nil {
+			return apperrors.DatabaseError("getting pending group invites", err)
+		}
+		for _, invite := range invites {
+			if err := groupRepo.AddMember(ctx, invite.GroupID, userID); err != nil && !apperrors.IsDuplicateError(err) {
+				return apperrors.DatabaseError("adding invited member", err)
+			}
+			if err := inviteRepo.MarkAccepted(ctx, invite.ID, userID); err != nil {
+				return apperrors.DatabaseError("accepting group invite", err)
+			}
+			forgetGroupMemberships(ctx, invite.GroupID)
+			accepted = append(accepted, invite)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return accepted, nil
+}
+
+func (s *userService) GetClaimablePlaceholders(ctx context.Context, userID string) ([]models.ClaimablePlaceholder, error) {
 	zap.L().Debug("Getting claimable placeholders", zap.String("user_id", userID))
 
-	placeholders, err := s.userRepo.GetUnclaimedPlaceholders(ctx)
+	claimer, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting user", err)
+	}
+	verifiedEmail := ""
+	if claimer.EmailVerified != nil && *claimer.EmailVerified {
+		verifiedEmail = strings.TrimSpace(claimer.Email)
+	}
+
+	placeholders, err := s.userRepo.GetUnclaimedPlaceholders(ctx, userID, verifiedEmail)
 	if err != nil {
 		zap.L().Error("Failed to get unclaimed placeholders", zap.Error(err))
 		return nil, apperrors.DatabaseError("getting unclaimed placeholders", err)
 	}
 
-	zap.L().Debug("Found claimable placeholders", zap.Int("count", len(placeholders)))
-	return placeholders, nil
+	if s.claimPolicy == PlaceholderClaimPolicyMatch {
+		matching := placeholders[:0]
+		for _, p := range placeholders {
+			if placeholderMatchesUser(&p, claimer) {
+				matching = append(matching, p)
+			}
+		}
+		placeholders = matching
+	}
+
+	placeholderIDs := make([]string, len(placeholders))
+	for i, p := range placeholders {
+		placeholderIDs[i] = p.ID
+	}
+	groupsByPlaceholder, err := s.userRepo.GetPlaceholderGroups(ctx, placeholderIDs)
+	if err != nil {
+		zap.L().Error("Failed to get placeholder groups", zap.Error(err))
+		return nil, apperrors.DatabaseError("getting placeholder groups", err)
+	}
+
+	myGroups, err := s.groupRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting user groups", err)
+	}
+	mine := make(map[string]bool, len(myGroups))
+	for _, g := range myGroups {
+		mine[g.ID] = true
+	}
+
+	result := make([]models.ClaimablePlaceholder, 0, len(placeholders))
+	for _, p := range placeholders {
+		// A placeholder with the caller's verified email is theirs, so all of
+		// its groups are shown; otherwise only the groups they share.
+		ownEmail := verifiedEmail != "" && strings.EqualFold(strings.TrimSpace(p.Email), verifiedEmail)
+		groups := []models.PlaceholderGroup{}
+		for _, g := range groupsByPlaceholder[p.ID] {
+			if !ownEmail && !mine[g.GroupID] {
+				continue
+			}
+			for j := range g.Balances {
+				g.Balances[j].Amount = math.Round(g.Balances[j].Amount*RoundingFactor) / RoundingFactor
+			}
+			groups = append(groups, g)
+		}
+		result = append(result, models.ClaimablePlaceholder{User: p, Groups: groups})
+	}
+
+	zap.L().Debug("Found claimable placeholders", zap.Int("count", len(result)))
+	return result, nil
 }
 
-func (s *userService) ClaimPlaceholder(ctx context.Context, userID, placeholderID string) error {
+func (s *userService) ClaimPlaceholder(ctx context.Context, userID, placeholderID string) (*models.PlaceholderClaimRequest, error) {
 	zap.L().Info("Claiming placeholder", zap.String("user_id", userID), zap.String("placeholder_id", placeholderID))
+	return s.requestClaim(ctx, placeholderID, userID)
+}
+
+// AssignPlaceholder lets an admin hand a placeholder to a registered user
+// directly, so the claim policy does not apply.
+func (s *userService) AssignPlaceholder(ctx context.Context, placeholderID, targetUserID string) error {
+	zap.L().Info("Assigning placeholder", zap.String("placeholder_id", placeholderID), zap.String("target_user_id", targetUserID))
+
+	if _, err := s.getClaimer(ctx, placeholderID, targetUserID); err != nil {
+		return err
+	}
+	if err := s.db.WithTx(ctx, func(q database.Querier) error {
+		return s.claimInTx(ctx, q, placeholderID, targetUserID)
+	}); err != nil {
+		return err
+	}
+
+	zap.L().Info("Placeholder assigned successfully",
+		zap.String("placeholder_id", placeholderID),
+		zap.String("target_user_id", targetUserID))
+	return nil
+}
 
+func (s *userService) requestClaim(ctx context.Context, placeholderID, claimerID string) (*models.PlaceholderClaimRequest, error) {
 	placeholder, err := s.userRepo.GetByID(ctx, placeholderID)
 	if err != nil {
 		if apperrors.IsNotFoundError(err) {
-			return apperrors.UserNotFound()
+			return nil, apperrors.UserNotFound()
 		}
-		return apperrors.DatabaseError("getting placeholder", err)
+		return nil, apperrors.DatabaseError("getting placeholder", err)
 	}
-
 	if !placeholder.IsPlaceholder {
-		return apperrors.InvalidRequest("User is not a placeholder")
+		return nil, apperrors.InvalidRequest("User is not a placeholder")
 	}
 	if placeholder.ClaimedBy != nil {
-		return apperrors.InvalidRequest("Placeholder has already been claimed")
+		return nil, apperrors.Conflict("Placeholder has already been claimed")
 	}
 
-	if err := s.userRepo.ClaimPlaceholder(ctx, placeholderID, userID); err != nil {
-		zap.L().Error("Failed to claim placeholder", zap.String("placeholder_id", placeholderID), zap.Error(err))
-		return apperrors.DatabaseError("claiming placeholder", err)
+	claimer, err := s.getClaimer(ctx, placeholderID, claimerID)
+	if err != nil {
+		return nil, err
 	}
-	if err := s.expenseRepo.TransferExpenses(ctx, placeholderID, userID); err != nil {
-		zap.L().Error("Failed to transfer expenses", zap.String("from", placeholderID), zap.String("to", userID), zap.Error(err))
-		return apperrors.DatabaseError("transferring expenses", err)
+
+	switch s.claimPolicy {
+	case PlaceholderClaimPolicyMatch:
+		if !placeholderMatchesUser(placeholder, claimer) {
+			return nil, apperrors.InvalidRequest("Placeholder name or email does not match this account")
+		}
+	case PlaceholderClaimPolicyApproval:
+		req, err := s.claimRepo.Create(ctx, placeholderID, claimerID)
+		if err != nil {
+			zap.L().Error("Failed to create placeholder claim request", zap.String("placeholder_id", placeholderID), zap.Error(err))
+			return nil, apperrors.DatabaseError("creating claim request", err)
+		}
+		zap.L().Info("Placeholder claim awaiting approval",
+			zap.String("request_id", req.ID),
+			zap.String("placeholder_id", placeholderID),
+			zap.String("user_id", claimerID))
+		return req, nil
+	}
+
+	if err := s.db.WithTx(ctx, func(q database.Querier) error {
+		return s.claimInTx(ctx, q, placeholderID, claimerID)
+	}); err != nil {
+		return nil, err
 	}
 
 	zap.L().Info("Placeholder claimed successfully",
-		zap.String("user_id", userID),
+		zap.String("user_id", claimerID),
 		zap.String("placeholder_id", placeholderID),
 		zap.String("placeholder_name", placeholder.Name))
 
-	return nil
+	return nil, nil
 }
 
-func (s *userService) AssignPlaceholder(ctx context.Context, placeholderID, targetUserID string) error {
-	zap.L().Info("Assigning placeholder", zap.String("placeholder_id", placeholderID), zap.String("target_user_id", targetUserID))
+// getClaimer loads the registered user a placeholder is being handed to.
+func (s *userService) getClaimer(ctx context.Context, placeholderID, claimerID string) (*models.User, error) {
+	if placeholderID == claimerID {
+		return nil, apperrors.InvalidRequest("A placeholder cannot claim itself")
+	}
+	claimer, err := s.userRepo.GetByID(ctx, claimerID)
+	if err != nil {
+		if apperrors.IsNotFoundError(err) {
+			return nil, apperrors.InvalidRequest("Target user not found")
+		}
+		return nil, apperrors.DatabaseError("getting target user", err)
+	}
+	if claimer.IsPlaceholder {
+		return nil, apperrors.InvalidRequest("Placeholders cannot be claimed by another placeholder")
+	}
+	return claimer, nil
+}
 
-	placeholder, err := s.userRepo.GetByID(ctx, placeholderID)
+func (s *userService) claimInTx(ctx context.Context, q database.Querier, placeholderID, claimerID string) error {
+	userRepo := s.userRepo.WithTx(q)
+
+	placeholder, err := userRepo.GetByIDForUpdate(ctx, placeholderID)
 	if err != nil {
 		if apperrors.IsNotFoundError(err) {
 			return apperrors.UserNotFound()
 		}
-		return apperrors.DatabaseError("getting placeholder", err)
+		return apperrors.DatabaseError("locking placeholder", err)
 	}
-
 	if !placeholder.IsPlaceholder {
 		return apperrors.InvalidRequest("User is not a placeholder")
 	}
 	if placeholder.ClaimedBy != nil {
-		return apperrors.InvalidRequest("Placeholder has already been claimed")
+		return apperrors.Conflict("Placeholder has already been claimed")
 	}
 
-	_, err = s.userRepo.GetByID(ctx, targetUserID)
+	claimed, err := userRepo.ClaimPlaceholder(ctx, placeholderID, claimerID)
+	if err != nil {
+		zap.L().Error("Failed to claim placeholder", zap.String("placeholder_id", placeholderID), zap.Error(err))
+		return apperrors.DatabaseError("claiming placeholder", err)
+	}
+	if !claimed {
+		return apperrors.Conflict("Placeholder has already been claimed")
+	}
+
+	if err := s.expenseRepo.WithTx(q).TransferExpenses(ctx, placeholderID, claimerID); err != nil {
+		zap.L().Error("Failed to transfer expenses", zap.String("from", placeholderID), zap.String("to", claimerID), zap.Error(err))
+		return apperrors.DatabaseError("transferring expenses", err)
+	}
+	if s.balanceEventRepo != nil {
+		if err := s.balanceEventRepo.WithTx(q).TransferUser(ctx, placeholderID, claimerID); err != nil {
+			return apperrors.DatabaseError("transferring balance events", err)
+		}
+	}
+	forgetAllMemberships(ctx)
+	return nil
+}
+
+func (s *userService) GetPendingPlaceholderClaims(ctx context.Context) ([]models.PlaceholderClaimRequest, error) {
+	requests, err := s.claimRepo.GetPending(ctx)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting pending claim requests", err)
+	}
+	return requests, nil
+}
+
+func (s *userService) ApprovePlaceholderClaim(ctx context.Context, adminID, requestID string) error {
+	zap.L().Info("Approving placeholder claim", zap.String("admin_id", adminID), zap.String("request_id", requestID))
+
+	return s.db.WithTx(ctx, func(q database.Querier) error {
+		claimRepo := s.claimRepo.WithTx(q)
+
+		req, err := s.getPendingClaim(ctx, claimRepo, requestID)
+		if err != nil {
+			return err
+		}
+		if err := s.claimInTx(ctx, q, req.PlaceholderID, req.UserID); err != nil {
+			return err
+		}
+		if err := claimRepo.Decide(ctx, req.ID, adminID, models.PlaceholderClaimApproved); err != nil {
+			return apperrors.DatabaseError("approving claim request", err)
+		}
+		if err := claimRepo.RejectPendingForPlaceholder(ctx, req.PlaceholderID, adminID); err != nil {
+			return apperrors.DatabaseError("rejecting competing claim requests", err)
+		}
+		return nil
+	})
+}
+
+func (s *userService) RejectPlaceholderClaim(ctx context.Context, adminID, requestID string) error {
+	zap.L().Info("Rejecting placeholder claim", zap.String("admin_id", adminID), zap.String("request_id", requestID))
+
+	return s.db.WithTx(ctx, func(q database.Querier) error {
+		claimRepo := s.claimRepo.WithTx(q)
+
+		req, err := s.getPendingClaim(ctx, claimRepo, requestID)
+		if err != nil {
+			return err
+		}
+		if err := claimRepo.Decide(ctx, req.ID, adminID, models.PlaceholderClaimRejected); err != nil {
+			return apperrors.DatabaseError("rejecting claim request", err)
+		}
+		return nil
+	})
+}
+
+func (s *userService) getPendingClaim(ctx context.Context, claimRepo repository.PlaceholderClaimRepository, requestID string) (*models.PlaceholderClaimRequest, error) {
+	req, err := claimRepo.GetByIDForUpdate(ctx, requestID)
 	if err != nil {
 		if apperrors.IsNotFoundError(err) {
-			return apperrors.InvalidRequest("Target user not found")
+			return nil, apperrors.NotFound("Claim request")
 		}
-		return apperrors.DatabaseError("getting target user", err)
+		return nil, apperrors.DatabaseError("getting claim request", err)
 	}
+	if req.Status != models.PlaceholderClaimPending {
+		return nil, apperrors.Conflict("Claim request has already been decided")
+	}
+	return req, nil
+}
 
-	if err := s.userRepo.ClaimPlaceholder(ctx, placeholderID, targetUserID); err != nil {
-		zap.L().Error("Failed to assign placeholder", zap.String("placeholder_id", placeholderID), zap.Error(err))
-		return apperrors.DatabaseError("assigning placeholder", err)
+func (s *userService) GetPlaceholderSuggestions(ctx context.Context, userID string) ([]models.PlaceholderMergeSuggestion, error) {
+	users, err := s.userRepo.GetUsersSharingGroups(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting users sharing groups", err)
 	}
-	if err := s.expenseRepo.TransferExpenses(ctx, placeholderID, targetUserID); err != nil {
-		zap.L().Error("Failed to transfer expenses", zap.String("from", placeholderID), zap.String("to", targetUserID), zap.Error(err))
-		return apperrors.DatabaseError("transferring expenses", err)
+
+	var placeholderIDs []string
+	for _, u := range users {
+		if u.IsPlaceholder {
+			placeholderIDs = append(placeholderIDs, u.ID)
+		}
+	}
+	groupsByPlaceholder, err := s.userRepo.GetPlaceholderGroups(ctx, placeholderIDs)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting placeholder groups", err)
 	}
 
-	zap.L().Info("Placeholder assigned successfully",
-		zap.String("placeholder_id", placeholderID),
-		zap.String("target_user_id", targetUserID),
-		zap.String("placeholder_name", placeholder.Name))
+	// Only show the caller groups they belong to.
+	myGroups, err := s.groupRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting user groups", err)
+	}
+	mine := make(map[string]bool, len(myGroups))
+	for _, g := range myGroups {
+		mine[g.ID] = true
+	}
+	for id, groups := range groupsByPlaceholder {
+		visible := []models.PlaceholderGroup{}
+		for _, g := range groups {
+			if !mine[g.GroupID] {
+				continue
+			}
+			for j := range g.Balances {
+				g.Balances[j].Amount = math.Round(g.Balances[j].Amount*RoundingFactor) / RoundingFactor
+			}
+			visible = append(visible, g)
+		}
+		groupsByPlaceholder[id] = visible
+	}
 
-	return nil
+	suggestions := suggestPlaceholderMerges(userID, users, groupsByPlaceholder)
+	zap.L().Debug("Built placeholder merge suggestions", zap.String("user_id", userID), zap.Int("count", len(suggestions)))
+	return suggestions, nil
+}
+
+// MergePlaceholders consolidates placeholders from the caller's groups into
+// targetID. A placeholder target absorbs their memberships and transactions
+// in one transaction; a registered target goes through the claim policy, so
+// under PLACEHOLDER_CLAIM_POLICY=approval the merges come back as pending claims.
+func (s *userService) MergePlaceholders(ctx context.Context, userID string, placeholderIDs []string, targetID string) (*models.PlaceholderMergeResult, error) {
+	zap.L().Info("Merging placeholders",
+		zap.String("user_id", userID),
+		zap.Strings("placeholder_ids", placeholderIDs),
+		zap.String("target_id", targetID))
+
+	seen := map[string]bool{targetID: true}
+	var sources []string
+	for _, id := range placeholderIDs {
+		if !seen[id] {
+			seen[id] = true
+			sources = append(sources, id)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, apperrors.InvalidRequest("Provide at least one placeholder other than the target.")
+	}
+	if len(sources) > MaxPlaceholdersPerMerge {
+		return nil, apperrors.InvalidRequest(fmt.Sprintf("At most %d placeholders can be merged at once.", MaxPlaceholdersPerMerge))
+	}
+
+	users, err := s.userRepo.GetUsersSharingGroups(ctx, userID)
+	if err != nil {
+		return nil, apperrors.DatabaseError("getting users sharing groups", err)
+	}
+	byID := make(map[string]models.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	target, ok := byID[targetID]
+	if !ok {
+		return nil, apperrors.UserNotFound()
+	}
+	for _, id := range sources {
+		if p, ok := byID[id]; !ok || !p.IsPlaceholder {
+			return nil, apperrors.InvalidRequestWithDetails("Only unclaimed placeholders from your groups can be merged.", id)
+		}
+		shared, err := s.userRepo.SharesTransactions(ctx, id, targetID)
+		if err != nil {
+			return nil, apperrors.DatabaseError("checking shared transactions", err)
+		}
+		if shared {
+			return nil, apperrors.Conflict(fmt.Sprintf("%s and %s appear in the same transaction, so they are different people.", byID[id].Name, target.Name))
+		}
+	}
+
+	result := &models.PlaceholderMergeResult{
+		Target:        target,
+		Merged:        []string{},
+		PendingClaims: []models.PlaceholderClaimRequest{},
+	}
+
+	if !target.IsPlaceholder {
+		for _, id := range sources {
+			req, err := s.requestClaim(ctx, id, targetID)
+			if err != nil {
+				return nil, err
+			}
+			if req != nil {
+				result.PendingClaims = append(result.PendingClaims, *req)
+			} else {
+				result.Merged = append(result.Merged, id)
+			}
+		}
+		return result, nil
+	}
+
+	err = s.db.WithTx(ctx, func(q database.Querier) error {
+		userRepo := s.userRepo.WithTx(q)
+		locked, err := userRepo.GetByIDForUpdate(ctx, targetID)
+		if err != nil {
+			return apperrors.DatabaseError("locking merge target", err)
+		}
+		if locked.ClaimedBy != nil {
+			return apperrors.Conflict("Target placeholder has already been claimed")
+		}
+
+		for _, id := range sources {
+			if _, err := userRepo.GetByIDForUpdate(ctx, id); err != nil {
+				return apperrors.DatabaseError("locking placeholder", err)
+			}
+			merged, err := userRepo.MergePlaceholder(ctx, id, targetID)
+			if err != nil {
+				return apperrors.DatabaseError("merging placeholder", err)
+			}
+			if !merged {
+				return apperrors.Conflict("Placeholder has already been claimed")
+			}
+			if err := s.expenseRepo.WithTx(q).TransferExpenses(ctx, id, targetID); err != nil {
+				return apperrors.DatabaseError("transferring expenses", err)
+			}
+			if s.balanceEventRepo != nil {
+				if err := s.balanceEventRepo.WithTx(q).TransferUser(ctx, id, targetID); err != nil {
+					return apperrors.DatabaseError("transferring balance events", err)
+				}
+			}
+		}
+		forgetAllMemberships(ctx)
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("Failed to merge placeholders", zap.String("target_id", targetID), zap.Error(err))
+		return nil, err
+	}
+
+	result.Merged = sources
+	zap.L().Info("Placeholders merged", zap.String("target_id", targetID), zap.Int("count", len(sources)))
+	return result, nil
+}
+
+func placeholderMatchesUser(placeholder, user *models.User) bool {
+	if placeholder.Email != "" && user.Email != "" {
+		return strings.EqualFold(strings.TrimSpace(placeholder.Email), strings.TrimSpace(user.Email))
+	}
+
+	placeholderName := normalizeClaimName(placeholder.Name)
+	userName := normalizeClaimName(user.Name)
+	if placeholderName == "" || userName == "" {
+		return false
+	}
+	if placeholderName == userName {
+		return true
+	}
+	return strings.Fields(userName)[0] == placeholderName
+}
+
+func normalizeClaimName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(NormalizeName(name))), " ")
 }
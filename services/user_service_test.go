@@ -0,0 +1,600 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"unwise-backend/database"
+	apperrors "unwise-backend/errors"
+	"unwise-backend/models"
+	"unwise-backend/repository"
+)
+
+type fakeUserRepo struct {
+	stubUserRepository
+	users        map[string]*models.User
+	placeholders []models.User
+	groups       map[string][]models.PlaceholderGroup
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("getting user by id: no rows in result set")
+}
+func (r *fakeUserRepo) UpdateEmailVerified(ctx context.Context, userID string, verified bool) error {
+	return nil
+}
+func (r *fakeUserRepo) GetUnclaimedPlaceholders(ctx context.Context, viewerID, verifiedEmail string) ([]models.User, error) {
+	return r.placeholders, nil
+}
+func (r *fakeUserRepo) GetPlaceholderGroups(ctx context.Context, placeholderIDs []string) (map[string][]models.PlaceholderGroup, error) {
+	if r.groups == nil {
+		return map[string][]models.PlaceholderGroup{}, nil
+	}
+	return r.groups, nil
+}
+func (r *fakeUserRepo) WithTx(tx database.Querier) repository.UserRepository { return r }
+
+type recordingInviteRepo struct {
+	stubGroupInviteRepository
+	lookups int
+}
+
+func (r *recordingInviteRepo) GetPendingByEmailForUpdate(ctx context.Context, email string) ([]models.GroupInvite, error) {
+	r.lookups++
+	return []models.GroupInvite{{ID: "i1", GroupID: "g1", Email: email}}, nil
+}
+func (r *recordingInviteRepo) WithTx(tx database.Querier) repository.GroupInviteRepository {
+	return r
+}
+
+func TestBootstrapSkipsEmailMatchesUntilVerified(t *testing.T) {
+	unverified := false
+	tests := []struct {
+		name          string
+		stored        *bool
+		token         *bool
+		requireVerify bool
+	}{
+		{name: "Token says unverified", token: &unverified, requireVerify: true},
+		{name: "Nothing says verified", requireVerify: true},
+		{name: "Verification not required", stored: &unverified, requireVerify: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{
+				users: map[string]*models.User{
+					"u1": {ID: "u1", Email: "asha@example.com", Name: "Asha", EmailVerified: tt.stored},
+				},
+				placeholders: []models.User{{ID: "p1", Name: "Asha", Email: "asha@example.com", IsPlaceholder: true}},
+			}
+			invites := &recordingInviteRepo{}
+			s := NewUserService(users, nil, nil, &mockGroupRepo{}, invites, nil, nil, nil, nil, PlaceholderClaimPolicyOpen, tt.requireVerify)
+
+			response, err := s.Bootstrap(context.Background(), "u1", "asha@example.com", "Asha", tt.token)
+			if err != nil {
+				t.Fatalf("Bootstrap() error = %v", err)
+			}
+			if invites.lookups != 0 || len(response.Invitations) != 0 {
+				t.Errorf("accepted %d invites after %d lookups, expected none for an unverified email", len(response.Invitations), invites.lookups)
+			}
+			if len(response.ClaimedPlaceholders) != 0 || len(response.PendingClaims) != 0 {
+				t.Errorf("claimed %d placeholders, expected none for an unverified email", len(response.ClaimedPlaceholders)+len(response.PendingClaims))
+			}
+		})
+	}
+}
+
+func TestEnsureUserRejectsDeletedAccount(t *testing.T) {
+	deletedAt := time.Now()
+	users := &fakeUserRepo{
+		users: map[string]*models.User{
+			"u1": {ID: "u1", Name: "Deleted User", DeletedAt: &deletedAt},
+		},
+	}
+	s := NewUserService(users, nil, nil, &mockGroupRepo{}, nil, nil, nil, nil, nil, PlaceholderClaimPolicyOpen, false)
+
+	user, err := s.EnsureUser(context.Background(), "u1", "asha@example.com", "Asha", nil)
+	if user != nil {
+		t.Errorf("EnsureUser() = %v, expected nil for a deleted account", user)
+	}
+	if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Code != apperrors.CodeAccountDeleted {
+		t.Errorf("EnsureUser() error = %v, expected %s", err, apperrors.CodeAccountDeleted)
+	}
+}
+
+type memberOfGroupRepo struct {
+	mockGroupRepo
+	groups []models.Group
+}
+
+func (r *memberOfGroupRepo) GetByUserID(ctx context.Context, userID string) ([]models.Group, error) {
+	return r.groups, nil
+}
+
+func TestGetClaimablePlaceholdersShowsOnlySharedGroups(t *testing.T) {
+	verified := true
+	users := &fakeUserRepo{
+		users: map[string]*models.User{
+			"u1": {ID: "u1", Email: "asha@example.com", Name: "Asha", EmailVerified: &verified},
+		},
+		placeholders: []models.User{
+			{ID: "p1", Name: "Asha", Email: "ASHA@example.com", IsPlaceholder: true},
+			{ID: "p2", Name: "Ravi", IsPlaceholder: true},
+		},
+		groups: map[string][]models.PlaceholderGroup{
+			"p1": {{GroupID: "g1", GroupName: "Flat"}, {GroupID: "g9", GroupName: "Old trip"}},
+			"p2": {{GroupID: "g1", GroupName: "Flat"}, {GroupID: "g8", GroupName: "Ravi's office"}},
+		},
+	}
+	groups := &memberOfGroupRepo{groups: []models.Group{{ID: "g1"}}}
+	s := NewUserService(users, nil, nil, groups, nil, nil, nil, nil, nil, PlaceholderClaimPolicyOpen, false)
+
+	claimable, err := s.GetClaimablePlaceholders(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("GetClaimablePlaceholders() error = %v", err)
+	}
+
+	expected := map[string][]string{"p1": {"g1", "g9"}, "p2": {"g1"}}
+	if len(claimable) != len(expected) {
+		t.Fatalf("GetClaimablePlaceholders() returned %d placeholders, expected %d", len(claimable), len(expected))
+	}
+	for _, c := range claimable {
+		var got []string
+		for _, g := range c.Groups {
+			got = append(got, g.GroupID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(expected[c.User.ID]) {
+			t.Errorf("groups of %s = %v, expected %v", c.User.ID, got, expected[c.User.ID])
+		}
+	}
+}
+
+// claimUserRepo claims placeholders through its WithTx views, so a claim
+// only sticks once the transaction commits. raced makes ClaimPlaceholder
+// report the row as already taken, as when a concurrent claim commits first.
+type claimUserRepo struct {
+	fakeUserRepo
+	q      database.Querier
+	raced  bool
+	claims int
+}
+
+func (r *claimUserRepo) WithTx(q database.Querier) repository.UserRepository {
+	return &claimUserRepo{fakeUserRepo: r.fakeUserRepo, q: q, raced: r.raced}
+}
+
+func (r *claimUserRepo) GetByIDForUpdate(ctx context.Context, id string) (*models.User, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *claimUserRepo) ClaimPlaceholder(_ context.Context, placeholderID, claimerID string) (bool, error) {
+	placeholder := r.users[placeholderID]
+	if r.raced || placeholder.ClaimedBy != nil {
+		return false, nil
+	}
+	inTx(r.q, func() { placeholder.ClaimedBy = &claimerID })
+	return true, nil
+}
+
+type claimTransferRepo struct {
+	stubExpenseRepository
+	q         database.Querier
+	err       error
+	transfers *[]string
+}
+
+func (r *claimTransferRepo) WithTx(q database.Querier) repository.ExpenseRepository {
+	return &claimTransferRepo{q: q, err: r.err, transfers: r.transfers}
+}
+
+func (r *claimTransferRepo) TransferExpenses(_ context.Context, from, to string) error {
+	if r.err != nil {
+		return r.err
+	}
+	inTx(r.q, func() { *r.transfers = append(*r.transfers, "expenses "+from+"->"+to) })
+	return nil
+}
+
+type claimLedgerRepo struct {
+	stubBalanceEventRepository
+	q         database.Querier
+	err       error
+	transfers *[]string
+}
+
+func (r *claimLedgerRepo) WithTx(q database.Querier) repository.BalanceEventRepository {
+	return &claimLedgerRepo{q: q, err: r.err, transfers: r.transfers}
+}
+
+func (r *claimLedgerRepo) TransferUser(_ context.Context, from, to string) error {
+	if r.err != nil {
+		return r.err
+	}
+	inTx(r.q, func() { *r.transfers = append(*r.transfers, "ledger "+from+"->"+to) })
+	return nil
+}
+
+type fakeClaimRepo struct {
+	stubPlaceholderClaimRepository
+	q        database.Querier
+	requests map[string]*models.PlaceholderClaimRequest
+}
+
+func (r *fakeClaimRepo) WithTx(q database.Querier) repository.PlaceholderClaimRepository {
+	return &fakeClaimRepo{q: q, requests: r.requests}
+}
+
+func (r *fakeClaimRepo) Create(_ context.Context, placeholderID, userID string) (*models.PlaceholderClaimRequest, error) {
+	req := &models.PlaceholderClaimRequest{
+		ID:            fmt.Sprintf("req-%d", len(r.requests)+1),
+		PlaceholderID: placeholderID,
+		UserID:        userID,
+		Status:        models.PlaceholderClaimPending,
+	}
+	r.requests[req.ID] = req
+	return req, nil
+}
+
+func (r *fakeClaimRepo) GetByIDForUpdate(_ context.Context, requestID string) (*models.PlaceholderClaimRequest, error) {
+	req, ok := r.requests[requestID]
+	if !ok {
+		return nil, fmt.Errorf("getting claim request: no rows in result set")
+	}
+	copied := *req
+	return &copied, nil
+}
+
+func (r *fakeClaimRepo) Decide(_ context.Context, requestID, adminID string, status models.PlaceholderClaimStatus) error {
+	inTx(r.q, func() {
+		r.requests[requestID].Status = status
+		r.requests[requestID].DecidedBy = &adminID
+	})
+	return nil
+}
+
+func (r *fakeClaimRepo) RejectPendingForPlaceholder(_ context.Context, placeholderID, adminID string) error {
+	inTx(r.q, func() {
+		for _, req := range r.requests {
+			if req.PlaceholderID == placeholderID && req.Status == models.PlaceholderClaimPending {
+				req.Status = models.PlaceholderClaimRejected
+				req.DecidedBy = &adminID
+			}
+		}
+	})
+	return nil
+}
+
+type claimFixture struct {
+	service   *userService
+	users     *claimUserRepo
+	claims    *fakeClaimRepo
+	runner    *fakeTxRunner
+	transfers []string
+}
+
+func newClaimFixture(policy string) *claimFixture {
+	f := &claimFixture{
+		users: &claimUserRepo{fakeUserRepo: fakeUserRepo{users: map[string]*models.User{
+			"ph":    {ID: "ph", Name: "Priya", Email: "priya@example.com", IsPlaceholder: true},
+			"priya": {ID: "priya", Name: "Priya Nair", Email: "priya@example.com"},
+			"rahul": {ID: "rahul", Name: "Rahul", Email: "rahul@example.com"},
+		}}},
+		claims: &fakeClaimRepo{requests: map[string]*models.PlaceholderClaimRequest{}},
+		runner: &fakeTxRunner{},
+	}
+	f.service = &userService{
+		userRepo:         f.users,
+		expenseRepo:      &claimTransferRepo{transfers: &f.transfers},
+		claimRepo:        f.claims,
+		balanceEventRepo: &claimLedgerRepo{transfers: &f.transfers},
+		db:               f.runner,
+		claimPolicy:      policy,
+	}
+	return f
+}
+
+func (f *claimFixture) claimedBy() string {
+	if claimedBy := f.users.users["ph"].ClaimedBy; claimedBy != nil {
+		return *claimedBy
+	}
+	return ""
+}
+
+func TestClaimPlaceholderConflicts(t *testing.T) {
+	t.Run("Second Claim", func(t *testing.T) {
+		f := newClaimFixture(PlaceholderClaimPolicyOpen)
+		if _, err := f.service.ClaimPlaceholder(context.Background(), "priya", "ph"); err != nil {
+			t.Fatalf("ClaimPlaceholder() error = %v", err)
+		}
+
+		_, err := f.service.ClaimPlaceholder(context.Background(), "rahul", "ph")
+		if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeConflict {
+			t.Errorf("ClaimPlaceholder() error = %v, expected %s", err, apperrors.CodeConflict)
+		}
+		if got := f.claimedBy(); got != "priya" {
+			t.Errorf("placeholder claimed by %q, expected priya", got)
+		}
+		if len(f.transfers) != 2 {
+			t.Errorf("transfers = %v, expected only the first claim's", f.transfers)
+		}
+	})
+
+	t.Run("Lost Race", func(t *testing.T) {
+		f := newClaimFixture(PlaceholderClaimPolicyOpen)
+		f.users.raced = true
+
+		_, err := f.service.ClaimPlaceholder(context.Background(), "priya", "ph")
+		if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeConflict {
+			t.Errorf("ClaimPlaceholder() error = %v, expected %s", err, apperrors.CodeConflict)
+		}
+		if len(f.transfers) != 0 || f.runner.rollbacks != 1 {
+			t.Errorf("transfers = %v with %d rollbacks, expected nothing moved", f.transfers, f.runner.rollbacks)
+		}
+	})
+}
+
+func TestClaimPlaceholderRollsBackFailedTransfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		expenseErr error
+		ledgerErr  error
+	}{
+		{name: "Expense Transfer Fails", expenseErr: fmt.Errorf("connection reset")},
+		{name: "Ledger Transfer Fails", ledgerErr: fmt.Errorf("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newClaimFixture(PlaceholderClaimPolicyOpen)
+			f.service.expenseRepo = &claimTransferRepo{err: tt.expenseErr, transfers: &f.transfers}
+			f.service.balanceEventRepo = &claimLedgerRepo{err: tt.ledgerErr, transfers: &f.transfers}
+
+			_, err := f.service.ClaimPlaceholder(context.Background(), "priya", "ph")
+			if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeDatabaseError {
+				t.Errorf("ClaimPlaceholder() error = %v, expected %s", err, apperrors.CodeDatabaseError)
+			}
+			if got := f.claimedBy(); got != "" {
+				t.Errorf("placeholder claimed by %q, expected the claim rolled back", got)
+			}
+			if len(f.transfers) != 0 || f.runner.rollbacks != 1 {
+				t.Errorf("transfers = %v with %d rollbacks, expected nothing moved", f.transfers, f.runner.rollbacks)
+			}
+		})
+	}
+}
+
+func TestClaimPlaceholderMatchPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		claimer      string
+		expectedCode apperrors.ErrorCode
+	}{
+		{name: "Matching Claimer", claimer: "priya"},
+		{name: "Different Person", claimer: "rahul", expectedCode: apperrors.CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newClaimFixture(PlaceholderClaimPolicyMatch)
+
+			_, err := f.service.ClaimPlaceholder(context.Background(), tt.claimer, "ph")
+			if tt.expectedCode == "" {
+				if err != nil {
+					t.Fatalf("ClaimPlaceholder() error = %v", err)
+				}
+				if got := f.claimedBy(); got != tt.claimer {
+					t.Errorf("placeholder claimed by %q, expected %s", got, tt.claimer)
+				}
+				return
+			}
+			if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != tt.expectedCode {
+				t.Errorf("ClaimPlaceholder() error = %v, expected %s", err, tt.expectedCode)
+			}
+			if got := f.claimedBy(); got != "" || f.runner.commits+f.runner.rollbacks != 0 {
+				t.Errorf("placeholder claimed by %q, expected the claim rejected before any transaction", got)
+			}
+		})
+	}
+}
+
+func TestClaimPlaceholderApprovalPolicy(t *testing.T) {
+	t.Run("Approve", func(t *testing.T) {
+		f := newClaimFixture(PlaceholderClaimPolicyApproval)
+		req, err := f.service.ClaimPlaceholder(context.Background(), "rahul", "ph")
+		if err != nil {
+			t.Fatalf("ClaimPlaceholder() error = %v", err)
+		}
+		if req == nil || req.Status != models.PlaceholderClaimPending {
+			t.Fatalf("ClaimPlaceholder() = %+v, expected a pending request", req)
+		}
+		if got := f.claimedBy(); got != "" || len(f.transfers) != 0 {
+			t.Fatalf("placeholder claimed by %q with transfers %v before approval", got, f.transfers)
+		}
+		competing, err := f.service.ClaimPlaceholder(context.Background(), "priya", "ph")
+		if err != nil {
+			t.Fatalf("ClaimPlaceholder() error = %v", err)
+		}
+
+		if err := f.service.ApprovePlaceholderClaim(context.Background(), "admin", req.ID); err != nil {
+			t.Fatalf("ApprovePlaceholderClaim() error = %v", err)
+		}
+		if got := f.claimedBy(); got != "rahul" || len(f.transfers) != 2 {
+			t.Errorf("placeholder claimed by %q with transfers %v, expected rahul with both transfers", got, f.transfers)
+		}
+		if status := f.claims.requests[req.ID].Status; status != models.PlaceholderClaimApproved {
+			t.Errorf("request status = %s, expected %s", status, models.PlaceholderClaimApproved)
+		}
+		if status := f.claims.requests[competing.ID].Status; status != models.PlaceholderClaimRejected {
+			t.Errorf("competing request status = %s, expected %s", status, models.PlaceholderClaimRejected)
+		}
+
+		for name, decide := range map[string]func(context.Context, string, string) error{
+			"ApprovePlaceholderClaim": f.service.ApprovePlaceholderClaim,
+			"RejectPlaceholderClaim":  f.service.RejectPlaceholderClaim,
+		} {
+			err := decide(context.Background(), "admin", req.ID)
+			if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeConflict {
+				t.Errorf("%s() on a decided request error = %v, expected %s", name, err, apperrors.CodeConflict)
+			}
+		}
+		if len(f.transfers) != 2 {
+			t.Errorf("transfers = %v, expected the claim applied once", f.transfers)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		f := newClaimFixture(PlaceholderClaimPolicyApproval)
+		req, err := f.service.ClaimPlaceholder(context.Background(), "rahul", "ph")
+		if err != nil {
+			t.Fatalf("ClaimPlaceholder() error = %v", err)
+		}
+
+		if err := f.service.RejectPlaceholderClaim(context.Background(), "admin", req.ID); err != nil {
+			t.Fatalf("RejectPlaceholderClaim() error = %v", err)
+		}
+		if status := f.claims.requests[req.ID].Status; status != models.PlaceholderClaimRejected {
+			t.Errorf("request status = %s, expected %s", status, models.PlaceholderClaimRejected)
+		}
+
+		for name, decide := range map[string]func(context.Context, string, string) error{
+			"ApprovePlaceholderClaim": f.service.ApprovePlaceholderClaim,
+			"RejectPlaceholderClaim":  f.service.RejectPlaceholderClaim,
+		} {
+			err := decide(context.Background(), "admin", req.ID)
+			if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeConflict {
+				t.Errorf("%s() on a decided request error = %v, expected %s", name, err, apperrors.CodeConflict)
+			}
+		}
+		if got := f.claimedBy(); got != "" || len(f.transfers) != 0 {
+			t.Errorf("placeholder claimed by %q with transfers %v, expected a rejected claim to move nothing", got, f.transfers)
+		}
+	})
+}
+
+func TestAssignPlaceholderSkipsClaimPolicy(t *testing.T) {
+	for _, policy := range []string{PlaceholderClaimPolicyMatch, PlaceholderClaimPolicyApproval} {
+		t.Run(policy, func(t *testing.T) {
+			f := newClaimFixture(policy)
+
+			if err := f.service.AssignPlaceholder(context.Background(), "ph", "rahul"); err != nil {
+				t.Fatalf("AssignPlaceholder() error = %v", err)
+			}
+			if got := f.claimedBy(); got != "rahul" || len(f.transfers) != 2 {
+				t.Errorf("placeholder claimed by %q with transfers %v, expected rahul with both transfers", got, f.transfers)
+			}
+			if len(f.claims.requests) != 0 {
+				t.Errorf("claim requests = %v, expected none", f.claims.requests)
+			}
+		})
+	}
+}
+
+// verificationUserRepo stores UpdateEmailVerified on the user, so tests can
+// see the token's flag being kept for later requests.
+type verificationUserRepo struct {
+	fakeUserRepo
+	updates int
+}
+
+func (r *verificationUserRepo) UpdateEmailVerified(ctx context.Context, userID string, verified bool) error {
+	r.updates++
+	r.users[userID].EmailVerified = &verified
+	return nil
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	verified, unverified := true, false
+	tests := []struct {
+		name            string
+		requireVerified bool
+		stored          *bool
+		token           *bool
+		missingUser     bool
+		expectErr       bool
+		expectStored    *bool
+	}{
+		{name: "Verification Disabled", stored: &unverified, token: &unverified, expectStored: &unverified},
+		{name: "Verified Token", requireVerified: true, stored: &unverified, token: &verified, expectStored: &verified},
+		{name: "Unverified Token Wins", requireVerified: true, stored: &verified, token: &unverified, expectErr: true, expectStored: &unverified},
+		{name: "Missing Claim Uses Stored Verified", requireVerified: true, stored: &verified, expectStored: &verified},
+		{name: "Missing Claim Uses Stored Unverified", requireVerified: true, stored: &unverified, expectErr: true, expectStored: &unverified},
+		{name: "Missing Claim Nothing Stored", requireVerified: true, expectErr: true},
+		{name: "Missing Claim Unknown User", requireVerified: true, missingUser: true, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &verificationUserRepo{fakeUserRepo: fakeUserRepo{users: map[string]*models.User{}}}
+			if !tt.missingUser {
+				users.users["u1"] = &models.User{ID: "u1", EmailVerified: tt.stored}
+			}
+			s := &userService{userRepo: users, requireVerified: tt.requireVerified}
+
+			err := s.RequireVerifiedEmail(context.Background(), "u1", tt.token, "exporting data")
+			if tt.expectErr {
+				if appErr, ok := apperrors.AsAppError(err); !ok || appErr.Code != apperrors.CodeEmailNotVerified {
+					t.Errorf("RequireVerifiedEmail() error = %v, expected %s", err, apperrors.CodeEmailNotVerified)
+				}
+			} else if err != nil {
+				t.Errorf("RequireVerifiedEmail() error = %v, expected nil", err)
+			}
+			if tt.missingUser {
+				return
+			}
+			if got := users.users["u1"].EmailVerified; (got == nil) != (tt.expectStored == nil) || (got != nil && *got != *tt.expectStored) {
+				t.Errorf("stored email_verified = %v, expected %v", got, tt.expectStored)
+			}
+		})
+	}
+}
+
+func TestEnsureUserKeepsStoredEmailVerified(t *testing.T) {
+	verified, unverified := true, false
+	tests := []struct {
+		name          string
+		stored        *bool
+		token         *bool
+		expected      *bool
+		expectUpdates int
+	}{
+		{name: "Missing Claim Keeps Stored", stored: &verified, expected: &verified},
+		{name: "Same Claim Skips Update", stored: &verified, token: &verified, expected: &verified},
+		{name: "Changed Claim Updates", stored: &unverified, token: &verified, expected: &verified, expectUpdates: 1},
+		{name: "First Claim Updates", token: &unverified, expected: &unverified, expectUpdates: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &verificationUserRepo{fakeUserRepo: fakeUserRepo{users: map[string]*models.User{
+				"u1": {ID: "u1", Name: "Asha", EmailVerified: tt.stored},
+			}}}
+			s := &userService{userRepo: users, requireVerified: true}
+
+			user, err := s.EnsureUser(context.Background(), "u1", "asha@example.com", "Asha", tt.token)
+			if err != nil {
+				t.Fatalf("EnsureUser() error = %v", err)
+			}
+			if user.EmailVerified == nil || *user.EmailVerified != *tt.expected {
+				t.Errorf("EnsureUser() email_verified = %v, expected %v", user.EmailVerified, *tt.expected)
+			}
+			if users.updates != tt.expectUpdates {
+				t.Errorf("UpdateEmailVerified() called %d times, expected %d", users.updates, tt.expectUpdates)
+			}
+
+			// A later request without the claim falls back to what was stored.
+			err = s.RequireVerifiedEmail(context.Background(), "u1", nil, "exporting data")
+			if *tt.expected && err != nil {
+				t.Errorf("RequireVerifiedEmail() error = %v, expected nil", err)
+			}
+			if appErr, ok := apperrors.AsAppError(err); !*tt.expected && (!ok || appErr.Code != apperrors.CodeEmailNotVerified) {
+				t.Errorf("RequireVerifiedEmail() error = %v, expected %s", err, apperrors.CodeEmailNotVerified)
+			}
+		})
+	}
+}
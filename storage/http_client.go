@@ -3,6 +3,7 @@ package storage
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -38,6 +39,23 @@ func createDeleteRequest(url, apiKey string) (*http.Request, error) {
 	return req, nil
 }
 
+func createSignRequest(url, apiKey string, expiresIn int) (*http.Request, error) {
+	body, err := json.Marshal(map[string]int{"expiresIn": expiresIn})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling sign request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
 func executeRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
 	client := &http.Client{
 		Timeout: 30 * time.Second,
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -15,6 +16,7 @@ type Storage interface {
 	Upload(ctx context.Context, bucket string, filename string, file io.Reader, contentType string) (string, error)
 	Delete(ctx context.Context, bucket string, filename string) error
 	GetURL(ctx context.Context, bucket string, filename string) (string, error)
+	CreateSignedURL(ctx context.Context, bucket string, path string, expiresIn time.Duration) (string, error)
 }
 
 type SupabaseStorage struct {
@@ -39,13 +41,7 @@ func (s *SupabaseStorage) Upload(ctx context.Context, bucket string, filename st
 		log.Printf("[SupabaseStorage.Upload] Generated filename: %s", filename)
 	}
 
-	baseURL := strings.TrimSuffix(s.baseURL, "/")
-	var url string
-	if strings.HasSuffix(baseURL, "/storage/v1") {
-		url = fmt.Sprintf("%s/object/%s/%s", baseURL, bucket, filename)
-	} else {
-		url = fmt.Sprintf("%s/storage/v1/object/%s/%s", baseURL, bucket, filename)
-	}
+	url := s.objectURL("object", bucket, filename)
 	log.Printf("[SupabaseStorage.Upload] Upload URL: %s", url)
 
 	req, err := createUploadRequest(url, s.apiKey, file, contentType)
@@ -76,13 +72,7 @@ func (s *SupabaseStorage) Upload(ctx context.Context, bucket string, filename st
 }
 
 func (s *SupabaseStorage) Delete(ctx context.Context, bucket string, filename string) error {
-	baseURL := strings.TrimSuffix(s.baseURL, "/")
-	var url string
-	if strings.HasSuffix(baseURL, "/storage/v1") {
-		url = fmt.Sprintf("%s/object/%s/%s", baseURL, bucket, filename)
-	} else {
-		url = fmt.Sprintf("%s/storage/v1/object/%s/%s", baseURL, bucket, filename)
-	}
+	url := s.objectURL("object", bucket, filename)
 
 	req, err := createDeleteRequest(url, s.apiKey)
 	if err != nil {
@@ -106,3 +96,58 @@ func (s *SupabaseStorage) GetURL(ctx context.Context, bucket string, filename st
 	publicURL := strings.TrimSuffix(s.publicURL, "/")
 	return fmt.Sprintf("%s/storage/v1/object/public/%s/%s", publicURL, bucket, filename), nil
 }
+
+func (s *SupabaseStorage) CreateSignedURL(ctx context.Context, bucket string, path string, expiresIn time.Duration) (string, error) {
+	url := s.objectURL("object/sign", bucket, path)
+
+	req, err := createSignRequest(url, s.apiKey, int(expiresIn.Seconds()))
+	if err != nil {
+		return "", fmt.Errorf("creating sign request: %w", err)
+	}
+
+	resp, err := executeRequest(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("executing sign request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("sign failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result struct {
+		SignedURL string `json:"signedURL"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decoding sign response: %w", err)
+	}
+	if result.SignedURL == "" {
+		return "", fmt.Errorf("sign response did not include a URL")
+	}
+
+	publicURL := strings.TrimSuffix(s.publicURL, "/")
+	return fmt.Sprintf("%s/storage/v1%s", publicURL, result.SignedURL), nil
+}
+
+func (s *SupabaseStorage) objectURL(prefix, bucket, path string) string {
+	baseURL := strings.TrimSuffix(s.baseURL, "/")
+	if strings.HasSuffix(baseURL, "/storage/v1") {
+		return fmt.Sprintf("%s/%s/%s/%s", baseURL, prefix, bucket, path)
+	}
+	return fmt.Sprintf("%s/storage/v1/%s/%s/%s", baseURL, prefix, bucket, path)
+}
+
+func ObjectPath(bucket, value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, "?"); i >= 0 {
+		value = value[:i]
+	}
+	for _, marker := range []string{"/object/public/", "/object/sign/", "/object/"} {
+		prefix := marker + bucket + "/"
+		if i := strings.Index(value, prefix); i >= 0 {
+			return value[i+len(prefix):]
+		}
+	}
+	return strings.TrimPrefix(value, "/")
+}
@@ -0,0 +1,268 @@
+package supabase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultMaxRetries  = 3
+	DefaultBaseBackoff = 200 * time.Millisecond
+	maxBackoff         = 5 * time.Second
+)
+
+// AdminClient wraps the Supabase Auth admin API, which needs the service
+// role key and must only be used server-side.
+type AdminClient interface {
+	CreateUser(ctx context.Context, params CreateUserParams) (*AuthUser, error)
+	UpdateUserMetadata(ctx context.Context, userID string, metadata map[string]interface{}) error
+	DeleteUser(ctx context.Context, userID string) error
+}
+
+type AdminConfig struct {
+	URL            string
+	ServiceRoleKey string
+	// Timeout bounds a single attempt, not the whole call with retries.
+	Timeout time.Duration
+	// MaxRetries of zero uses DefaultMaxRetries; negative disables retries.
+	MaxRetries  int
+	BaseBackoff time.Duration
+	HTTPClient  *http.Client
+}
+
+type CreateUserParams struct {
+	ID           string                 `json:"id,omitempty"`
+	Email        string                 `json:"email"`
+	Password     string                 `json:"password,omitempty"`
+	EmailConfirm bool                   `json:"email_confirm"`
+	UserMetadata map[string]interface{} `json:"user_metadata,omitempty"`
+}
+
+type AuthUser struct {
+	ID           string                 `json:"id"`
+	Email        string                 `json:"email"`
+	UserMetadata map[string]interface{} `json:"user_metadata"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// APIError is a non-2xx response from the admin API.
+type APIError struct {
+	Operation  string
+	StatusCode int
+	Code       string
+	Message    string
+
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("supabase %s: status %d (%s): %s", e.Operation, e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("supabase %s: status %d: %s", e.Operation, e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the same request may succeed later.
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsAlreadyExists reports whether a create failed because the user exists,
+// which also happens when a retried create had succeeded the first time.
+func IsAlreadyExists(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusConflict || apiErr.Code == "email_exists" || apiErr.Code == "user_already_exists"
+}
+
+type adminClient struct {
+	baseURL     string
+	key         string
+	maxRetries  int
+	baseBackoff time.Duration
+	httpClient  *http.Client
+}
+
+func NewAdminClient(cfg AdminConfig) (AdminClient, error) {
+	if cfg.URL == "" || cfg.ServiceRoleKey == "" {
+		return nil, fmt.Errorf("supabase admin client needs SUPABASE_URL and SUPABASE_SERVICE_ROLE_KEY")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	} else if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = DefaultBaseBackoff
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &adminClient{
+		baseURL:     strings.TrimSuffix(cfg.URL, "/") + "/auth/v1/admin",
+		key:         cfg.ServiceRoleKey,
+		maxRetries:  cfg.MaxRetries,
+		baseBackoff: cfg.BaseBackoff,
+		httpClient:  httpClient,
+	}, nil
+}
+
+func (c *adminClient) CreateUser(ctx context.Context, params CreateUserParams) (*AuthUser, error) {
+	var user AuthUser
+	if err := c.do(ctx, "create user", http.MethodPost, "/users", params, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (c *adminClient) UpdateUserMetadata(ctx context.Context, userID string, metadata map[string]interface{}) error {
+	body := map[string]interface{}{"user_metadata": metadata}
+	return c.do(ctx, "update user metadata", http.MethodPut, "/users/"+userID, body, nil)
+}
+
+func (c *adminClient) DeleteUser(ctx context.Context, userID string) error {
+	return c.do(ctx, "delete user", http.MethodDelete, "/users/"+userID, nil, nil)
+}
+
+// do sends the request, retrying transport errors, 429s and 5xx responses
+// with exponential backoff and jitter. A Retry-After header overrides the
+// computed delay.
+func (c *adminClient) do(ctx context.Context, operation, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("supabase %s: marshaling request: %w", operation, err)
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff(attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("supabase %s: %w (last error: %v)", operation, ctx.Err(), lastErr)
+			case <-time.After(delay):
+			}
+		}
+
+		retryable, err := c.attempt(ctx, operation, method, path, payload, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable || ctx.Err() != nil {
+			return err
+		}
+	}
+	return lastErr
+}
+
+func (c *adminClient) attempt(ctx context.Context, operation, method, path string, payload []byte, out interface{}) (bool, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return false, fmt.Errorf("supabase %s: creating request: %w", operation, err)
+	}
+	req.Header.Set("apikey", c.key)
+	req.Header.Set("Authorization", "Bearer "+c.key)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("supabase %s: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := parseAPIError(operation, resp, respBody)
+		apiErr.retryAfter = retryAfter(resp)
+		return apiErr.Retryable(), apiErr
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return false, fmt.Errorf("supabase %s: decoding response: %w", operation, err)
+		}
+	}
+	return false, nil
+}
+
+func (c *adminClient) backoff(attempt int, lastErr error) time.Duration {
+	var apiErr *APIError
+	if errors.As(lastErr, &apiErr) && apiErr.retryAfter > 0 {
+		return min(apiErr.retryAfter, maxBackoff)
+	}
+	delay := c.baseBackoff << (attempt - 1)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func parseAPIError(operation string, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{Operation: operation, StatusCode: resp.StatusCode}
+	var parsed struct {
+		Code             interface{} `json:"code"`
+		ErrorCode        string      `json:"error_code"`
+		Msg              string      `json:"msg"`
+		Message          string      `json:"message"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiErr.Code = parsed.ErrorCode
+		if code, ok := parsed.Code.(string); ok && apiErr.Code == "" {
+			apiErr.Code = code
+		}
+		for _, msg := range []string{parsed.Msg, parsed.Message, parsed.ErrorDescription, parsed.Error} {
+			if msg != "" {
+				apiErr.Message = msg
+				break
+			}
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,128 @@
+package supabase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *adminClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewAdminClient(AdminConfig{
+		URL:            server.URL + "/",
+		ServiceRoleKey: "service-key",
+		BaseBackoff:    time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewAdminClient() error = %v", err)
+	}
+	return client.(*adminClient)
+}
+
+func TestCreateUserRetriesTransientFailures(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/auth/v1/admin/users" || r.Header.Get("Authorization") != "Bearer service-key" || r.Header.Get("apikey") != "service-key" {
+			t.Errorf("request = %s %s with headers %v, expected an authenticated POST to the admin users endpoint", r.Method, r.URL.Path, r.Header)
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":"u1","email":"asha@example.com"}`))
+	})
+
+	user, err := client.CreateUser(context.Background(), CreateUserParams{Email: "asha@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.ID != "u1" || calls != 3 {
+		t.Errorf("CreateUser() = %+v after %d calls, expected u1 after 3", user, calls)
+	}
+}
+
+func TestAdminClientErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		maxRetries      int
+		expectedCalls   int
+		expectedCode    string
+		expectedMessage string
+		alreadyExists   bool
+		notFound        bool
+	}{
+		{name: "Client Error Not Retried", status: http.StatusBadRequest, body: `{"msg":"Password too short"}`, expectedCalls: 1, expectedMessage: "Password too short"},
+		{name: "Existing Email", status: http.StatusUnprocessableEntity, body: `{"code":422,"error_code":"email_exists","msg":"A user with this email address has already been registered"}`, expectedCalls: 1, expectedCode: "email_exists", alreadyExists: true},
+		{name: "Conflict", status: http.StatusConflict, body: `{"code":"user_already_exists"}`, expectedCalls: 1, expectedCode: "user_already_exists", alreadyExists: true},
+		{name: "Missing User", status: http.StatusNotFound, body: ``, expectedCalls: 1, expectedMessage: "Not Found", notFound: true},
+		{name: "Server Errors Until Retries Run Out", status: http.StatusBadGateway, body: `upstream down`, maxRetries: 2, expectedCalls: 3, expectedMessage: "upstream down"},
+		{name: "Retries Disabled", status: http.StatusTooManyRequests, body: `{}`, maxRetries: -1, expectedCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			if tt.maxRetries != 0 {
+				client.maxRetries = max(tt.maxRetries, 0)
+			}
+
+			err := client.DeleteUser(context.Background(), "u1")
+			apiErr, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("DeleteUser() error = %v, expected an *APIError", err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("DeleteUser() made %d calls, expected %d", calls, tt.expectedCalls)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code != tt.expectedCode {
+				t.Errorf("DeleteUser() error = %+v, expected status %d and code %q", apiErr, tt.status, tt.expectedCode)
+			}
+			if tt.expectedMessage != "" && apiErr.Message != tt.expectedMessage {
+				t.Errorf("DeleteUser() message = %q, expected %q", apiErr.Message, tt.expectedMessage)
+			}
+			if IsAlreadyExists(err) != tt.alreadyExists || IsNotFound(err) != tt.notFound {
+				t.Errorf("IsAlreadyExists() = %v, IsNotFound() = %v, expected %v and %v", IsAlreadyExists(err), IsNotFound(err), tt.alreadyExists, tt.notFound)
+			}
+		})
+	}
+}
+
+func TestAdminClientBackoff(t *testing.T) {
+	client := &adminClient{baseBackoff: 200 * time.Millisecond}
+
+	for attempt, ceiling := range map[int]time.Duration{1: 200 * time.Millisecond, 3: 800 * time.Millisecond, 10: maxBackoff} {
+		for i := 0; i < 20; i++ {
+			if delay := client.backoff(attempt, nil); delay < ceiling/2 || delay > ceiling {
+				t.Fatalf("backoff(%d) = %v, expected between %v and %v", attempt, delay, ceiling/2, ceiling)
+			}
+		}
+	}
+
+	limited := &APIError{StatusCode: http.StatusTooManyRequests, retryAfter: 2 * time.Second}
+	if delay := client.backoff(1, limited); delay != 2*time.Second {
+		t.Errorf("backoff() with Retry-After = %v, expected 2s", delay)
+	}
+	limited.retryAfter = time.Minute
+	if delay := client.backoff(1, limited); delay != maxBackoff {
+		t.Errorf("backoff() with a long Retry-After = %v, expected it capped at %v", delay, maxBackoff)
+	}
+}
+
+func TestNewAdminClientRequiresCredentials(t *testing.T) {
+	if _, err := NewAdminClient(AdminConfig{URL: "https://example.supabase.co"}); err == nil {
+		t.Error("NewAdminClient() without a service role key succeeded, expected an error")
+	}
+}